@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/pkg/motulmatch"
+)
+
+const usage = `Usage: matchctl [flags] [vehicle description]
+
+Matches vehicle descriptions against the Motul catalog and shows the candidate
+types, their deterministic feature scores, the rules and LLM picks and the
+pipeline result. Nothing is written to the database.
+
+With a description as arguments it matches it once; otherwise it reads one
+vehicle per line (REPL). A line is free text ("VW GOL 1.0 12V FLEX 2020": the
+brand is the longest prefix found in the catalog, then one word of model, the
+rest is the description and a 19xx/20xx token the year) or "brand | model |
+description | year".
+
+REPL commands:
+  :pipeline SPEC          Set the match pipeline (e.g. exact,rules:0.7,llm)
+  :min-confidence V       Feature-score confidence of the rules strategy
+  :model-similarity V     Jaro-Winkler threshold of the similarity strategy
+  :max-prompt N           Longest description sent to the LLM as is
+  :settings               Show the current settings
+  :help                   Show this help
+  :quit                   Exit
+`
+
+// yearRegex finds a model year in free text
+var yearRegex = regexp.MustCompile(`^(19\d{2}|20\d{2})$`)
+
+// settings are the matcher knobs the REPL can change; each change builds a
+// new matcher, so brand and model caches start empty
+type settings struct {
+	pipeline        motulmatch.Pipeline
+	minConfidence   float64
+	modelSimilarity float64
+	maxPrompt       int
+}
+
+func main() {
+	var (
+		catalogCache    = flag.String("catalog-cache", "motul_catalog.json", "Motul catalog cache file (fetched from the Motul API when missing)")
+		categories      = flag.String("categories", getEnv("MOTUL_CATEGORIES", client.CategoryCar), "Comma-separated Motul vehicle categories to fetch when there is no cache (CAR, MOTORCYCLE, TRUCK, AGRI)")
+		llmProvider     = flag.String("llm-provider", getEnv("LLM_PROVIDER", "ollama"), "LLM provider: ollama, groq, gemini or none (drops the llm strategy)")
+		ollamaURL       = flag.String("ollama-url", getEnv("OLLAMA_URL", "http://100.108.205.53:11434"), "Ollama API URL")
+		ollamaModel     = flag.String("ollama-model", getEnv("OLLAMA_MODEL", "llama3.1:8b"), "Ollama model name")
+		groqAPIKeys     = flag.String("groq-api-keys", getEnv("GROQ_API_KEYS", getEnv("GROQ_API_KEY", "")), "Groq API keys (comma-separated)")
+		groqRPM         = flag.Int("groq-rpm", 30, "Groq requests per minute for keys without an rpm limit")
+		geminiAPIKeys   = flag.String("gemini-api-keys", getEnv("GEMINI_API_KEYS", getEnv("GEMINI_API_KEY", "")), "Gemini API keys (comma-separated)")
+		geminiModel     = flag.String("gemini-model", getEnv("GEMINI_MODEL", "gemini-2.0-flash"), "Gemini model name")
+		geminiRPM       = flag.Int("gemini-rpm", 15, "Gemini requests per minute per key")
+		matchPipeline   = flag.String("match-pipeline", getEnv("MATCH_PIPELINE", motulmatch.DefaultPipeline.String()), "Ordered match strategies with optional thresholds")
+		minConfidence   = flag.Float64("min-confidence", motulmatch.DefaultMinConfidence, "Feature-score confidence needed to accept a match without the LLM (0.0-1.0)")
+		modelSimilarity = flag.Float64("model-similarity", motulmatch.DefaultModelSimilarity, "Jaro-Winkler similarity needed to match a model name without the LLM (0 = disabled)")
+		maxPromptDesc   = flag.Int("max-prompt-description", motulmatch.DefaultMaxPromptDescription, "Longest description (characters) sent to the LLM as is (0 = never compact)")
+		logLevel        = flag.String("log-level", "warn", "Log level (debug shows every matching step)")
+	)
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage+"\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		fail(fmt.Errorf("invalid -log-level: %s", *logLevel))
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	pipeline, err := motulmatch.ParsePipeline(*matchPipeline)
+	if err != nil {
+		fail(fmt.Errorf("invalid -match-pipeline: %w", err))
+	}
+
+	var llm motulmatch.LLM
+	switch strings.ToLower(*llmProvider) {
+	case "ollama":
+		llm = client.NewOllamaClient(*ollamaURL, *ollamaModel, logger)
+	case "groq":
+		keys, err := client.ParseGroqKeys(*groqAPIKeys, float64(*groqRPM))
+		if err != nil || len(keys) == 0 {
+			fail(fmt.Errorf("groq provider needs -groq-api-keys or GROQ_API_KEYS"))
+		}
+		llm = client.NewGroqClientKeys(keys, logger)
+	case "gemini":
+		keys := splitList(*geminiAPIKeys)
+		if len(keys) == 0 {
+			fail(fmt.Errorf("gemini provider needs -gemini-api-keys or GEMINI_API_KEYS"))
+		}
+		llm = client.NewGeminiClient(keys, *geminiModel, float64(*geminiRPM), logger)
+	case "none":
+		pipeline = withoutLLM(pipeline)
+		if len(pipeline) == 0 {
+			fail(fmt.Errorf("-llm-provider=none leaves an empty match pipeline"))
+		}
+	default:
+		fail(fmt.Errorf("unknown LLM provider: %s (use ollama, groq, gemini or none)", *llmProvider))
+	}
+
+	ctx := context.Background()
+
+	// An existing cache is used whatever its age: tuning runs offline
+	var catalogLoader *motulmatch.CatalogLoader
+	if catalog, err := motulmatch.LoadCatalogFile(*catalogCache); err == nil {
+		catalogLoader = motulmatch.NewStaticCatalogLoader(catalog, logger)
+	} else {
+		catalogLoader = motulmatch.NewCatalogLoader(client.NewMotulClient(1), logger)
+		catalogLoader.SetCategories(splitList(strings.ToUpper(*categories)))
+		if _, err := catalogLoader.LoadOrFetch(ctx, *catalogCache); err != nil {
+			fail(fmt.Errorf("failed to load Motul catalog: %w", err))
+		}
+	}
+
+	current := settings{
+		pipeline:        pipeline,
+		minConfidence:   *minConfidence,
+		modelSimilarity: *modelSimilarity,
+		maxPrompt:       *maxPromptDesc,
+	}
+	newMatcher := func() *motulmatch.Matcher {
+		matcher := motulmatch.New(catalogLoader, llm, logger)
+		matcher.SetPipeline(current.pipeline)
+		matcher.SetMinConfidence(current.minConfidence)
+		matcher.SetModelSimilarity(current.modelSimilarity)
+		matcher.SetMaxPromptDescription(current.maxPrompt)
+		return matcher
+	}
+	matcher := newMatcher()
+
+	if flag.NArg() > 0 {
+		inspect(ctx, os.Stdout, matcher, catalogLoader, strings.Join(flag.Args(), " "))
+		return
+	}
+
+	fmt.Printf("matchctl: %d brands loaded, pipeline %s (:help for commands)\n", len(catalogLoader.GetBrandNames()), current.pipeline)
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, ":") {
+			inspect(ctx, os.Stdout, matcher, catalogLoader, line)
+			continue
+		}
+
+		command, arg, _ := strings.Cut(line[1:], " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "q", "quit", "exit":
+			return
+		case "help":
+			fmt.Print(usage)
+			continue
+		case "settings":
+			current.print(os.Stdout)
+			continue
+		case "pipeline":
+			p, err := motulmatch.ParsePipeline(arg)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if llm == nil {
+				if p = withoutLLM(p); len(p) == 0 {
+					fmt.Println("error: no LLM configured (-llm-provider=none)")
+					continue
+				}
+			}
+			current.pipeline = p
+		case "min-confidence", "model-similarity":
+			v, err := strconv.ParseFloat(arg, 64)
+			if err != nil || v < 0 || v > 1 {
+				fmt.Println("error: value must be a number in [0, 1]")
+				continue
+			}
+			if command == "min-confidence" {
+				current.minConfidence = v
+			} else {
+				current.modelSimilarity = v
+			}
+		case "max-prompt":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				fmt.Println("error: value must be a non-negative integer")
+				continue
+			}
+			current.maxPrompt = n
+		default:
+			fmt.Printf("unknown command :%s (:help for commands)\n", command)
+			continue
+		}
+		matcher = newMatcher()
+		current.print(os.Stdout)
+	}
+}
+
+// inspect parses one vehicle, runs the match and prints the inspection
+func inspect(ctx context.Context, w io.Writer, matcher *motulmatch.Matcher, catalog *motulmatch.CatalogLoader, line string) {
+	brand, model, description, year := parseVehicle(catalog, line)
+	if brand == "" || model == "" {
+		fmt.Fprintln(w, "error: need at least a brand and a model")
+		return
+	}
+	fmt.Fprintf(w, "vehicle:  brand=%q model=%q description=%q year=%d\n", brand, model, description, year)
+
+	start := time.Now()
+	in, err := matcher.Inspect(ctx, brand, model, description, year)
+	if in != nil && in.MotulBrand != "" {
+		fmt.Fprintf(w, "brand:    %s\n", in.MotulBrand)
+	}
+	if err != nil {
+		fmt.Fprintln(w, "error:   ", err)
+		return
+	}
+	fmt.Fprintf(w, "model:    %s\n", in.MotulModel)
+	truncated := ""
+	if in.PromptTruncated {
+		truncated = " (compacted)"
+	}
+	fmt.Fprintf(w, "prompt:   %s%s\n", in.Prompt, truncated)
+
+	fmt.Fprintf(w, "candidates: %d\n", len(in.Candidates))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ID\tSCORE\tCYL.\tVALV.\tCYLS\tPOWER\tYEAR\tAGREE\tCONFLICT\tNAME")
+	for _, c := range in.Candidates {
+		s := c.Score
+		fmt.Fprintf(tw, "  %s\t%.2f\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			c.VehicleType.ID, s.Confidence, s.Cilindrada, s.Valvulas, s.Cilindros, s.Potencia, s.Ano,
+			c.Agree, c.Conflicts, c.VehicleType.Name)
+	}
+	tw.Flush()
+
+	if len(in.Candidates) > 1 {
+		printPick(w, "rules:   ", in.Rules, "no clear winner")
+		printPick(w, "llm:     ", in.LLMPick, "no llm step")
+	}
+	if in.ResultErr != nil {
+		fmt.Fprintln(w, "result:  ", in.ResultErr)
+	} else {
+		printPick(w, "result:  ", in.Result, "no match")
+	}
+	fmt.Fprintf(w, "took:     %s\n\n", time.Since(start).Round(time.Millisecond))
+}
+
+func printPick(w io.Writer, label string, r *motulmatch.MatchResult, none string) {
+	if r == nil {
+		fmt.Fprintln(w, label, none)
+		return
+	}
+	fmt.Fprintf(w, "%s %s %s (%s, confidence %.2f)\n", label, r.VehicleType.ID, r.VehicleType.Name, r.MatchMethod, r.Confidence)
+}
+
+// parseVehicle reads "brand | model | description | year" or free text
+func parseVehicle(catalog *motulmatch.CatalogLoader, line string) (brand, model, description string, year int) {
+	if strings.Contains(line, "|") {
+		parts := strings.Split(line, "|")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		parts = append(parts, "", "", "", "")
+		brand, model, description = parts[0], parts[1], parts[2]
+		year, _ = strconv.Atoi(parts[3])
+		if description == "" {
+			description = model
+		}
+		return brand, model, description, year
+	}
+
+	var words []string
+	for _, word := range strings.Fields(line) {
+		if yearRegex.MatchString(word) && year == 0 {
+			year, _ = strconv.Atoi(word)
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) < 2 {
+		return "", "", "", year
+	}
+
+	// Longest prefix (up to 3 words) that is a catalog brand; else the first word
+	brandWords := 1
+	for n := min(3, len(words)-1); n > 1; n-- {
+		if catalog.FindBrand(strings.Join(words[:n], " ")) != nil {
+			brandWords = n
+			break
+		}
+	}
+	brand = strings.Join(words[:brandWords], " ")
+	model = words[brandWords]
+	description = strings.Join(words[brandWords+1:], " ")
+	if description == "" {
+		description = model
+	}
+	return brand, model, description, year
+}
+
+// withoutLLM drops the llm strategy from a pipeline
+func withoutLLM(pipeline motulmatch.Pipeline) motulmatch.Pipeline {
+	var steps motulmatch.Pipeline
+	for _, step := range pipeline {
+		if step.Strategy != motulmatch.StrategyLLM {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+func (s settings) print(w io.Writer) {
+	fmt.Fprintf(w, "pipeline=%s min-confidence=%.2f model-similarity=%.2f max-prompt=%d\n",
+		s.pipeline, s.minConfidence, s.modelSimilarity, s.maxPrompt)
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/database"
+)
+
+const usage = `Usage: migrate [-config file] <command>
+
+Applies the versioned schema migrations embedded in the binary to the database
+configured by the DB_* env vars (same as the API).
+
+Commands:
+  up          Apply every pending migration (a legacy, unversioned schema is
+              completed and marked as the baseline first)
+  down [N]    Roll back the last N migrations (default 1)
+  status      Show the applied version and the pending migrations
+  force V     Mark version V as applied and clean, after fixing a failed
+              (dirty) migration by hand
+`
+
+func main() {
+	configFile := flag.String("config", os.Getenv(config.ConfigFileEnv), "YAML or TOML config file")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *configFile != "" {
+		if _, err := config.LoadFile(*configFile); err != nil {
+			fail(err)
+		}
+	}
+	if _, err := config.LoadSecretFiles(); err != nil {
+		fail(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fail(err)
+	}
+
+	// Migrations run longer than an API query; the 30 minute context bounds them
+	cfg.Database.QueryTimeout = 0
+	pool, err := database.NewPostgresPool(cfg.Database)
+	if err != nil {
+		fail(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "up":
+		err = database.RunMigrations(ctx, pool)
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				fail(fmt.Errorf("invalid number of migrations to roll back: %s", args[0]))
+			}
+		}
+		err = database.RollbackMigrations(pool, steps)
+	case "force":
+		if len(args) == 0 {
+			fail(fmt.Errorf("force requires a version"))
+		}
+		version, convErr := strconv.Atoi(args[0])
+		if convErr != nil || version < 1 {
+			fail(fmt.Errorf("invalid version: %s", args[0]))
+		}
+		err = database.ForceMigrationVersion(pool, version)
+	case "status":
+		// Printed below, like after every other command
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	if err := printStatus(ctx, pool); err != nil {
+		fail(err)
+	}
+}
+
+// printStatus shows the applied version and what is left to apply
+func printStatus(ctx context.Context, pool *pgxpool.Pool) error {
+	status, err := database.GetMigrationStatus(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	state := "clean"
+	if status.Dirty {
+		state = "dirty: fix the schema, then run force"
+	}
+	fmt.Printf("version: %d (%s)\n", status.Version, state)
+	fmt.Printf("latest:  %d\n", status.Latest)
+	if len(status.Pending) == 0 {
+		fmt.Println("pending: none")
+		return nil
+	}
+	fmt.Println("pending:")
+	for _, migration := range status.Pending {
+		fmt.Printf("  %06d_%s\n", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
@@ -1,25 +1,57 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
 	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/config"
 	"wega-catalog-api/internal/database"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/parser"
 	"wega-catalog-api/internal/repository"
 	"wega-catalog-api/internal/scraper"
+	"wega-catalog-api/internal/telemetry"
+	"wega-catalog-api/pkg/motulmatch"
 )
 
 func main() {
+	// Load the config file before declaring the flags: its keys become env vars,
+	// so the env-backed flag defaults below pick them up (real env vars win)
+	var fileKeys []string
+	if path := config.ConfigFileFromArgs(os.Args[1:]); path != "" {
+		keys, err := config.LoadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fileKeys = keys
+	}
+	// Secrets mounted as files (DB_PASSWORD_FILE, GROQ_API_KEYS_FILE, ...) become their env vars
+	if _, err := config.LoadSecretFiles(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Parse command line flags
 	var (
+		_ = flag.String("config", getEnv(config.ConfigFileEnv, ""), "YAML or TOML config file; keys are env var or flag names, e.g. db: {host: ...} or groq_rpm: 20")
+
 		// Database flags
 		dbHost     = flag.String("db-host", getEnv("DB_HOST", "localhost"), "Database host")
 		dbPort     = flag.Int("db-port", getEnvInt("DB_PORT", 5432), "Database port")
@@ -29,85 +61,305 @@ func main() {
 		dbSSLMode  = flag.String("db-sslmode", getEnv("DB_SSLMODE", "disable"), "Database SSL mode")
 
 		// LLM Provider flags
-		llmProvider = flag.String("llm-provider", getEnv("LLM_PROVIDER", "ollama"), "LLM provider: ollama or groq")
+		llmProvider = flag.String("llm-provider", getEnv("LLM_PROVIDER", "ollama"), "LLM provider: ollama, groq or gemini")
+		llmChain    = flag.String("llm-chain", getEnv("LLM_CHAIN", ""), "Ordered LLM failover chain, e.g. groq,gemini,ollama (overrides -llm-provider)")
 
 		// Ollama flags (local LLM)
-		ollamaURL   = flag.String("ollama-url", getEnv("OLLAMA_URL", "http://100.108.205.53:11434"), "Ollama API URL")
-		ollamaModel = flag.String("ollama-model", getEnv("OLLAMA_MODEL", "llama3.1:8b"), "Ollama model name")
+		ollamaURL        = flag.String("ollama-url", getEnv("OLLAMA_URL", "http://100.108.205.53:11434"), "Ollama API URL")
+		ollamaModel      = flag.String("ollama-model", getEnv("OLLAMA_MODEL", "llama3.1:8b"), "Ollama model name")
+		ollamaTimeout    = flag.Duration("ollama-timeout", getEnvDuration("OLLAMA_TIMEOUT", 60*time.Second), "Ollama per-request timeout")
+		ollamaMaxRetries = flag.Int("ollama-max-retries", getEnvInt("OLLAMA_MAX_RETRIES", 0), "Ollama retries on network errors and 5xx")
 
 		// Groq API flags (cloud LLM) - supports multiple keys separated by comma for failover
-		groqAPIKeys = flag.String("groq-api-keys", getEnv("GROQ_API_KEYS", getEnv("GROQ_API_KEY", "")), "Groq API keys (comma-separated for failover)")
-		groqRPM     = flag.Int("groq-rpm", 30, "Groq requests per minute per key (free tier: 30)")
+		groqAPIKeys    = flag.String("groq-api-keys", getEnv("GROQ_API_KEYS", getEnv("GROQ_API_KEY", "")), "Groq API keys (comma-separated for failover, optional per-key limits: key:60rpm:500000tpd)")
+		groqRPM        = flag.Int("groq-rpm", 30, "Groq requests per minute for keys without an rpm limit (free tier: 30)")
+		groqBurst      = flag.Int("groq-burst", getEnvInt("GROQ_BURST", 1), "Groq requests allowed back to back after idle time")
+		groqTimeout    = flag.Duration("groq-timeout", getEnvDuration("GROQ_TIMEOUT", 30*time.Second), "Groq per-request timeout")
+		groqMaxRetries = flag.Int("groq-max-retries", getEnvInt("GROQ_MAX_RETRIES", 0), "Groq retries on network errors and 5xx")
+
+		// Gemini API flags (cloud LLM) - supports multiple keys separated by comma for failover
+		geminiAPIKeys    = flag.String("gemini-api-keys", getEnv("GEMINI_API_KEYS", getEnv("GEMINI_API_KEY", "")), "Gemini API keys (comma-separated for failover)")
+		geminiModel      = flag.String("gemini-model", getEnv("GEMINI_MODEL", "gemini-2.0-flash"), "Gemini model name")
+		geminiRPM        = flag.Int("gemini-rpm", 15, "Gemini requests per minute per key (free tier: 15)")
+		geminiTimeout    = flag.Duration("gemini-timeout", getEnvDuration("GEMINI_TIMEOUT", 30*time.Second), "Gemini per-request timeout")
+		geminiMaxRetries = flag.Int("gemini-max-retries", getEnvInt("GEMINI_MAX_RETRIES", 0), "Gemini retries on network errors and 5xx")
+
+		// Motul API flags
+		motulTimeout        = flag.Duration("motul-timeout", getEnvDuration("MOTUL_TIMEOUT", 30*time.Second), "Motul per-request timeout for spec fetches")
+		motulCatalogTimeout = flag.Duration("motul-catalog-timeout", getEnvDuration("MOTUL_CATALOG_TIMEOUT", 120*time.Second), "Motul per-request timeout for catalog listing (brands/models/types)")
+		motulRate           = flag.Float64("motul-rate", 1.0, "Motul requests per second")
+		motulBurst          = flag.Int("motul-burst", getEnvInt("MOTUL_BURST", 1), "Motul requests allowed back to back after idle time")
+		motulMaxRetries     = flag.Int("motul-max-retries", getEnvInt("MOTUL_MAX_RETRIES", 5), "Motul retries on network errors, 429 and 5xx")
+		driftSampleDir      = flag.String("schema-drift-dir", getEnv("SCHEMA_DRIFT_DIR", ""), "Directory to capture Motul responses that fail schema validation (empty = disabled)")
+		driftSampleMax      = flag.Int("schema-drift-samples", 20, "Maximum schema drift samples captured per run")
+		motulCacheDir       = flag.String("motul-cache-dir", getEnv("MOTUL_CACHE_DIR", ""), "Directory for the on-disk Motul response cache (empty = disabled)")
+		motulCacheTTL       = flag.Duration("motul-cache-ttl", getEnvDuration("MOTUL_CACHE_TTL", 24*time.Hour), "How long cached Motul responses are used before revalidation")
+
+		// File mode flags (no Wega DB required)
+		input  = flag.String("input", "", "Read vehicles from a file instead of the database, e.g. csv=vehicles.csv")
+		output = flag.String("output", "motul_results.csv", "Output file for -sink=file (.csv or .json) or -sink=ndjson")
+
+		// Spec sink flags
+		sink      = flag.String("sink", getEnv("SPEC_SINK", ""), "Where specs are written: db, file, ndjson or http (default: db, or file with -input)")
+		sinkURL   = flag.String("sink-url", getEnv("SINK_URL", ""), "Ingestion endpoint for -sink=http")
+		sinkToken = flag.String("sink-token", getEnv("SINK_TOKEN", ""), "Bearer token for -sink=http")
+
+		// Deterministic matching flags
+		minConfidence   = flag.Float64("min-confidence", 0.80, "Feature-score confidence needed to accept a match without the LLM (0.0-1.0)")
+		minSaveConf     = flag.Float64("min-save-confidence", 0, "Fuzzy matches below this confidence are recorded as baixa_confianca failures for review instead of saved (0.0-1.0, 0 = save all)")
+		modelSimilarity = flag.Float64("model-similarity", 0.92, "Jaro-Winkler similarity needed to match a model name without the LLM (0 = disabled)")
+		llmBatch        = flag.Int("llm-batch", getEnvInt("LLM_BATCH", 8), "Send the type prompts of up to N concurrent workers in one LLM call when the provider supports it (Groq; 1 = disabled)")
+		llmBatchWait    = flag.Duration("llm-batch-wait", motulmatch.DefaultBatchWait, "Longest a type prompt waits for others to share its LLM batch")
+		maxPromptDesc   = flag.Int("max-prompt-description", motulmatch.DefaultMaxPromptDescription, "Longest description (characters) sent to the LLM as is; longer ones keep only brand/model and engine, valves, power, fuel and year tokens (0 = never compact)")
+		matchPipeline   = flag.String("match-pipeline", getEnv("MATCH_PIPELINE", motulmatch.DefaultPipeline.String()), "Ordered match strategies with optional thresholds, e.g. exact,alias,similarity:0.9,rules,embedding:0.88:0.05,llm")
+
+		// Embedding flags (resolve most matches without the chat LLM)
+		embeddingsProvider = flag.String("embeddings-provider", getEnv("EMBEDDINGS_PROVIDER", ""), "Embeddings provider: ollama or openai (empty = disabled)")
+		embeddingModel     = flag.String("embedding-model", getEnv("EMBEDDING_MODEL", ""), "Embedding model (default: nomic-embed-text for ollama, text-embedding-3-small for openai)")
+		openAIAPIKey       = flag.String("openai-api-key", getEnv("OPENAI_API_KEY", ""), "OpenAI API key for -embeddings-provider=openai")
+		embeddingsCache    = flag.String("embeddings-cache", "motul_embeddings.json", "Catalog embeddings cache file")
+		embeddingMinScore  = flag.Float64("embedding-min-score", 0.85, "Minimum cosine similarity to accept an embedding match")
+		embeddingMargin    = flag.Float64("embedding-margin", 0.03, "Minimum lead over the runner-up to accept an embedding match")
+		neighborMinScore   = flag.Float64("neighbor-min-score", 0.97, "Minimum cosine similarity to a matched Wega application (embeddings kept by the API in pgvector) to reuse its Motul type (0 = disabled)")
+
+		// Match server flags
+		serveMatchPort = flag.Int("serve-match", getEnvInt("MATCH_SERVER_PORT", 0), "Serve POST /match and POST /lookup on this port instead of scraping (0 = disabled)")
 
 		// Catalog cache flags
-		catalogCache = flag.String("catalog-cache", "motul_catalog.json", "Motul catalog cache file")
+		catalogCache  = flag.String("catalog-cache", "motul_catalog.json", "Motul catalog cache file")
+		categories    = flag.String("categories", getEnv("MOTUL_CATEGORIES", client.CategoryCar), "Comma-separated Motul vehicle categories to load and scrape (CAR, MOTORCYCLE, TRUCK, AGRI)")
+		skipRules     = flag.String("skip-rules", getEnv("SCRAPER_SKIP_RULES", ""), "JSON file with vehicle category rules, skip list and per-fabricante overrides (reloaded on SIGHUP; empty = built-in rules)")
+		llmClassify   = flag.Bool("llm-classify", getEnv("SCRAPER_LLM_CLASSIFY", "") == "true", "Classify vehicles (car, motorcycle, truck, agri, equipment) with the LLM, cached in CLASSIFICACAO_VEICULO (requires the Wega DB)")
+		classifyBatch = flag.Int("llm-classify-batch", scraper.DefaultClassifyBatchSize, "Vehicles per LLM classification prompt")
 
 		// Scraper flags
+		providerName    = flag.String("provider", getEnv("SPEC_PROVIDER", scraper.ProviderMotul), "Spec provider to scrape (registered providers: motul)")
 		workers         = flag.Int("workers", 1, "Number of concurrent workers (keep low for LLM rate limits)")
 		rateLimitMs     = flag.Int("rate-limit", 2000, "Rate limit in milliseconds between requests")
 		checkpointEvery = flag.Int("checkpoint-every", 50, "Save checkpoint every N vehicles")
-		checkpointFile  = flag.String("checkpoint-file", "scraper_checkpoint.json", "Checkpoint file path")
+		checkpointFile  = flag.String("checkpoint-file", "scraper_checkpoint.json", "Checkpoint file path (only used without a database)")
+		checkpointRunID = flag.String("checkpoint-run-id", getEnv("SCRAPER_RUN_ID", ""), "Run ID of the SCRAPER_CHECKPOINT row to resume and save (default: provider name)")
+		distributed     = flag.Bool("distributed", getEnv("SCRAPER_DISTRIBUTED", "") == "true", "Claim vehicles from SCRAPER_QUEUE so several instances share the run (requires the Wega DB)")
+		queueLease      = flag.Duration("queue-lease", getEnvDuration("SCRAPER_QUEUE_LEASE", 30*time.Minute), "Time after which another instance takes over an unfinished claim")
+		queueReset      = flag.Bool("queue-reset", false, "Clear the SCRAPER_QUEUE of the run before starting (reprocess everything)")
 		resumeFromID    = flag.Int("resume-from", 0, "Resume from specific vehicle ID")
+		onlyID          = flag.Int("only-id", 0, "Process only this CodigoAplicacao with step-by-step debug logging (debugging a match)")
+		onlyIDs         = flag.String("only-ids", "", "Process only these comma-separated CodigoAplicacao values with step-by-step debug logging")
+		sinceDate       = flag.String("since", "", "Differential run: only vehicles imported after this date (YYYY-MM-DD or RFC3339; requires the Wega DB)")
+		sinceRun        = flag.Int("since-run", 0, "Differential run: only vehicles imported after SCRAPER_RUN <id> started (requires the Wega DB)")
 		dryRun          = flag.Bool("dry-run", false, "Dry run mode (don't make API calls)")
+		dryRunReport    = flag.String("dry-run-report", getEnv("SCRAPER_DRY_RUN_REPORT", ""), "Write a report of what the dry run would do to this file: JSON, or CSV for a .csv path (requires -dry-run)")
+		backfillNorma   = flag.Bool("backfill-norma", false, "Fill Norma on existing Motul specs from Motul standards data, then exit")
+		rescoreConf     = flag.Bool("rescore-confidence", false, "Re-score MatchConfidence of existing specs against the Motul type names, lowering and flagging clear engine mismatches (honours -dry-run), then exit")
+		rollbackRun     = flag.Int("rollback-run", 0, "Deactivate the specs last written by SCRAPER_RUN <id>, then exit")
+		rollbackDelete  = flag.Bool("rollback-delete", false, "With -rollback-run, restore the previous version of the specs and delete the ones the run inserted, instead of deactivating them")
+		exportAliases   = flag.String("export-aliases", "", "Write the brand/model aliases in ALIAS_VEICULO to this CSV file, then exit")
+		importAliases   = flag.String("import-aliases", "", "Import curated brand/model aliases from this CSV file into ALIAS_VEICULO, then exit")
+		exportSnapshot  = flag.String("export-snapshot", "", "Write a tar.gz snapshot of the scraper tables and the catalog/embeddings caches to this file, then exit")
+		restoreSnapshot = flag.String("restore-snapshot", "", "Replace the scraper tables and catalog/embeddings caches with a snapshot written by -export-snapshot, then exit")
+		prioritize      = flag.Bool("prioritize-popular", false, "Process the vehicles most looked up in the API first (requires the Wega DB)")
+		refreshOlder    = flag.Duration("refresh-older-than", 0, "Re-scrape specs older than this duration, e.g. 720h for 30 days (0 = never)")
 		monitorPort     = flag.Int("monitor-port", 9090, "HTTP monitoring server port")
 		noMonitor       = flag.Bool("no-monitor", false, "Disable HTTP monitoring")
+		noSpecValidate  = flag.Bool("no-spec-validation", false, "Save specs as the provider returns them, without normalizing and validating viscosity, capacity and fluid type")
+		controlToken    = flag.String("control-token", getEnv("SCRAPER_CONTROL_TOKEN", ""), "Bearer token required by the monitor's POST /control/* endpoints (without it they only answer localhost)")
+		debugEndpoints  = flag.Bool("debug-endpoints", getEnv("SCRAPER_DEBUG_ENDPOINTS", "") == "true", "Serve pprof and expvar under the monitor's /debug/ (requires -control-token)")
+		alertWebhook    = flag.String("alert-webhook-url", getEnv("ALERT_WEBHOOK_URL", ""), "Webhook URL for scraper alerts")
+		rateLimitAlert  = flag.Int("rate-limit-alert-threshold", 10, "Alert when rate-limit hits per minute exceed this (0 = disabled)")
+		successWindow   = flag.Int("success-rate-window", 200, "Number of recent attempted vehicles used for the success-rate alert")
+		successAlert    = flag.Float64("success-rate-threshold", 0, "Alert when the rolling success rate drops below this, 0.0-1.0 (0 = disabled)")
+		pauseOnLowRate  = flag.Bool("pause-on-low-success-rate", false, "Pause workers on a success-rate alert until POST /resume on the monitor port")
+		webhookURLs     = flag.String("webhook-urls", getEnv("SCRAPER_WEBHOOK_URLS", ""), "Comma-separated URLs notified of run start/finish, failure threshold and exhausted LLM keys")
+		webhookSecret   = flag.String("webhook-secret", getEnv("SCRAPER_WEBHOOK_SECRET", ""), "Secret signing webhook deliveries with HMAC-SHA256 (X-Wega-Signature; empty = unsigned)")
+		failureAlert    = flag.Int("failure-alert-threshold", 0, "Send a webhook when the run's failed vehicles reach this (0 = disabled)")
+		slackWebhook    = flag.String("slack-webhook-url", getEnv("SLACK_WEBHOOK_URL", ""), "Slack incoming webhook sent the final stats of every run")
+		discordWebhook  = flag.String("discord-webhook-url", getEnv("DISCORD_WEBHOOK_URL", ""), "Discord channel webhook sent the final stats of every run")
+		auditFile       = flag.String("audit-file", getEnv("SCRAPER_AUDIT_FILE", ""), "NDJSON audit log with per-vehicle outcome and stage timings (empty = disabled)")
 		logLevel        = flag.String("log-level", getEnv("LOG_LEVEL", "info"), "Log level (debug, info, warn, error)")
+		otlpEndpoint    = flag.String("otlp-endpoint", getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""), "OTLP/HTTP collector receiving traces, e.g. http://localhost:4318 (empty = disabled)")
 	)
 
 	flag.Parse()
 
-	// Validate required flags
-	if *dbPassword == "" {
+	if err := applyFileFlags(fileKeys); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config file value: %v\n", err)
+		os.Exit(1)
+	}
+
+	for name, port := range map[string]int{"db-port": *dbPort, "serve-match": *serveMatchPort, "monitor-port": *monitorPort} {
+		if port < 0 || port > 65535 {
+			fmt.Fprintf(os.Stderr, "Error: invalid -%s: %d (use 1-65535)\n", name, port)
+			os.Exit(1)
+		}
+	}
+	if *dbPort == 0 {
+		fmt.Fprintln(os.Stderr, "Error: invalid -db-port: 0 (use 1-65535)")
+		os.Exit(1)
+	}
+
+	// Validate required flags (the database is only needed to read vehicles or store specs there)
+	aliasTransfer := *exportAliases != "" || *importAliases != ""
+	snapshotMode := *exportSnapshot != "" || *restoreSnapshot != ""
+	needsDB := *backfillNorma || *rescoreConf || *rollbackRun > 0 || aliasTransfer || snapshotMode || (*serveMatchPort == 0 && (*input == "" || strings.EqualFold(*sink, scraper.SinkDB)))
+	if needsDB && *dbPassword == "" {
 		fmt.Fprintln(os.Stderr, "Error: database password is required (use -db-password or DB_PASSWORD env)")
 		os.Exit(1)
 	}
 
-	// Setup logger
-	logger := setupLogger(*logLevel)
+	if *exportSnapshot != "" && *restoreSnapshot != "" {
+		fmt.Fprintln(os.Stderr, "Error: use either -export-snapshot or -restore-snapshot")
+		os.Exit(1)
+	}
 
-	// Create LLM client based on provider
-	var llmClient client.LLMClient
+	if *rollbackDelete && *rollbackRun <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -rollback-delete requires -rollback-run")
+		os.Exit(1)
+	}
 
-	switch strings.ToLower(*llmProvider) {
-	case "ollama":
-		logger.Info("using Ollama LLM provider",
-			"url", *ollamaURL,
-			"model", *ollamaModel,
-		)
-		ollamaClient := client.NewOllamaClient(*ollamaURL, *ollamaModel, logger)
+	if *dryRunReport != "" && !*dryRun {
+		fmt.Fprintln(os.Stderr, "Error: -dry-run-report requires -dry-run")
+		os.Exit(1)
+	}
 
-		// Test connection
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		if err := ollamaClient.Ping(ctx); err != nil {
-			logger.Warn("Ollama ping failed, continuing anyway", "error", err)
-		}
-		cancel()
+	selectedIDs, err := parseOnlyIDs(*onlyID, *onlyIDs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -only-id/-only-ids: %v\n", err)
+		os.Exit(1)
+	}
+	if len(selectedIDs) > 0 && (*distributed || *sinceDate != "" || *sinceRun > 0 || *resumeFromID > 0) {
+		fmt.Fprintln(os.Stderr, "Error: -only-id/-only-ids cannot be combined with -distributed, -since, -since-run or -resume-from")
+		os.Exit(1)
+	}
 
-		llmClient = ollamaClient
+	vehicleCategories := parseCategories(*categories)
+	if len(vehicleCategories) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid -categories: %s (use %s)\n", *categories, strings.Join(client.Categories, ", "))
+		os.Exit(1)
+	}
 
-	case "groq":
-		if *groqAPIKeys == "" {
-			fmt.Fprintln(os.Stderr, "Error: Groq API key(s) required when using groq provider")
-			fmt.Fprintln(os.Stderr, "Use -groq-api-keys or GROQ_API_KEYS env")
-			fmt.Fprintln(os.Stderr, "Get your free API key at: https://console.groq.com/keys")
+	var categoryRules *scraper.CategoryRules
+	if *skipRules != "" {
+		rules, err := scraper.LoadCategoryRules(*skipRules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -skip-rules: %v\n", err)
 			os.Exit(1)
 		}
+		categoryRules = rules
+	}
+
+	pipeline, err := motulmatch.ParsePipeline(*matchPipeline)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -match-pipeline: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Setup logger; single-vehicle runs log every matching step
+	level := *logLevel
+	if len(selectedIDs) > 0 {
+		level = "debug"
+	}
+	logger := setupLogger(level)
+	logger.Info("effective config", "flags", effectiveFlags())
+
+	// newLLMClient creates the LLM client for a provider name
+	var groqClient *client.GroqClient // Key health shown by the monitor dashboard
+	newLLMClient := func(provider string) client.LLMClient {
+		switch strings.ToLower(provider) {
+		case "ollama":
+			logger.Info("using Ollama LLM provider",
+				"url", *ollamaURL,
+				"model", *ollamaModel,
+			)
+			ollamaClient := client.NewOllamaClient(*ollamaURL, *ollamaModel, logger)
+			ollamaClient.SetHTTPConfig(client.HTTPConfig{
+				Timeout: *ollamaTimeout,
+				Retry:   client.DefaultRetryConfig(*ollamaMaxRetries),
+			})
+
+			// Test connection
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := ollamaClient.Ping(ctx); err != nil {
+				logger.Warn("Ollama ping failed, continuing anyway", "error", err)
+			}
+			cancel()
+
+			return ollamaClient
+
+		case "groq":
+			if *groqAPIKeys == "" {
+				fmt.Fprintln(os.Stderr, "Error: Groq API key(s) required when using groq provider")
+				fmt.Fprintln(os.Stderr, "Use -groq-api-keys or GROQ_API_KEYS env")
+				fmt.Fprintln(os.Stderr, "Get your free API key at: https://console.groq.com/keys")
+				os.Exit(1)
+			}
+
+			apiKeys, err := client.ParseGroqKeys(*groqAPIKeys, float64(*groqRPM))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid -groq-api-keys: %v\n", err)
+				os.Exit(1)
+			}
+			if len(apiKeys) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: no valid API keys provided")
+				os.Exit(1)
+			}
 
-		apiKeys := parseAPIKeys(*groqAPIKeys)
-		if len(apiKeys) == 0 {
-			fmt.Fprintln(os.Stderr, "Error: no valid API keys provided")
+			logger.Info("using Groq LLM provider",
+				"keys_count", len(apiKeys),
+				"rpm", *groqRPM,
+			)
+			groqClient = client.NewGroqClientKeys(apiKeys, logger)
+			groqClient.SetBurst(*groqBurst)
+			groqClient.SetHTTPConfig(client.HTTPConfig{
+				Timeout: *groqTimeout,
+				Retry:   client.DefaultRetryConfig(*groqMaxRetries),
+			})
+			return groqClient
+
+		case "gemini":
+			apiKeys := parseAPIKeys(*geminiAPIKeys)
+			if len(apiKeys) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: Gemini API key(s) required when using gemini provider")
+				fmt.Fprintln(os.Stderr, "Use -gemini-api-keys or GEMINI_API_KEYS env")
+				fmt.Fprintln(os.Stderr, "Get your free API key at: https://aistudio.google.com/apikey")
+				os.Exit(1)
+			}
+
+			logger.Info("using Gemini LLM provider",
+				"keys_count", len(apiKeys),
+				"model", *geminiModel,
+				"rpm", *geminiRPM,
+			)
+			geminiClient := client.NewGeminiClient(apiKeys, *geminiModel, float64(*geminiRPM), logger)
+			geminiClient.SetHTTPConfig(client.HTTPConfig{
+				Timeout: *geminiTimeout,
+				Retry:   client.DefaultRetryConfig(*geminiMaxRetries),
+			})
+			return geminiClient
+
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown LLM provider: %s (use 'ollama', 'groq' or 'gemini')\n", provider)
 			os.Exit(1)
+			return nil
 		}
+	}
 
-		logger.Info("using Groq LLM provider",
-			"keys_count", len(apiKeys),
-			"rpm", *groqRPM,
-		)
-		llmClient = client.NewGroqClientMultiKey(apiKeys, float64(*groqRPM), logger)
-
-	default:
-		fmt.Fprintf(os.Stderr, "Error: unknown LLM provider: %s (use 'ollama' or 'groq')\n", *llmProvider)
-		os.Exit(1)
+	// Create LLM client: a single provider or an ordered failover chain
+	var llmClient client.LLMClient
+	if *llmChain != "" {
+		names := parseAPIKeys(*llmChain) // same comma-separated format
+		providers := make([]client.ChainProvider, len(names))
+		for i, name := range names {
+			llm := newLLMClient(name)
+			// Every provider but the last fails fast on daily exhaustion so the
+			// chain can fall through; the last one keeps waiting for its reset
+			if waiter, ok := llm.(client.DailyLimited); ok && i < len(names)-1 {
+				waiter.SetWaitForDailyReset(false)
+			}
+			providers[i] = client.ChainProvider{Name: strings.ToLower(name), Client: llm}
+		}
+		llmClient = client.NewChainClient(providers, logger)
+	} else {
+		llmClient = newLLMClient(*llmProvider)
 	}
 
 	logger.Info("starting Motul scraper with smart matching",
@@ -117,11 +369,13 @@ func main() {
 		"workers", *workers,
 		"rate_limit_ms", *rateLimitMs,
 		"llm_provider", *llmProvider,
+		"llm_chain", *llmChain,
 		"dry_run", *dryRun,
+		"refresh_older_than", *refreshOlder,
 	)
 
-	// Create context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	// Create context with cancellation; DB writes are audited as the scraper
+	ctx, cancel := context.WithCancel(repository.ComAtor(context.Background(), model.AtorScraper, ""))
 	defer cancel()
 
 	// Handle signals for graceful shutdown
@@ -133,82 +387,690 @@ func main() {
 		cancel()
 	}()
 
-	// Connect to database
-	dbConfig := database.ConnectionConfig{
-		Host:     *dbHost,
-		Port:     *dbPort,
-		Database: *dbName,
-		User:     *dbUser,
-		Password: *dbPassword,
-		SSLMode:  *dbSSLMode,
-		MaxConns: 25,
-		MinConns: 5,
-	}
-
-	dbPool, err := database.Connect(ctx, dbConfig)
+	// Tracing: a span per vehicle with the Motul, LLM and database calls under it
+	shutdownTracing, err := telemetry.Setup(ctx, "motul-scraper", *otlpEndpoint)
 	if err != nil {
-		logger.Error("failed to connect to database", "error", err)
+		logger.Error("failed to set up tracing", "error", err)
 		os.Exit(1)
 	}
-	defer dbPool.Close()
+	// flushTracing sends the buffered spans; os.Exit skips deferred calls
+	flushTracing := func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Warn("failed to flush traces", "error", err)
+		}
+	}
 
-	logger.Info("connected to database")
+	// connectDB connects to the database and runs migrations, exiting on failure
+	connectDB := func() *pgxpool.Pool {
+		dbConfig := database.ConnectionConfig{
+			Host:     *dbHost,
+			Port:     *dbPort,
+			Database: *dbName,
+			User:     *dbUser,
+			Password: *dbPassword,
+			SSLMode:  *dbSSLMode,
+			MaxConns: 25,
+			MinConns: 5,
+		}
 
-	// Run database migrations
-	if err := database.RunMigrations(ctx, dbPool); err != nil {
-		logger.Error("failed to run migrations", "error", err)
-		os.Exit(1)
+		dbPool, err := database.Connect(ctx, dbConfig)
+		if err != nil {
+			logger.Error("failed to connect to database", "error", err)
+			os.Exit(1)
+		}
+
+		logger.Info("connected to database")
+
+		// Run database migrations
+		if err := database.RunMigrations(ctx, dbPool); err != nil {
+			dbPool.Close()
+			logger.Error("failed to run migrations", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("database migrations completed")
+
+		return dbPool
+	}
+
+	// Create Motul API client (1 request per second by default)
+	motulClient := client.NewMotulClient(*motulRate)
+	motulClient.SetRateLimit(*motulRate, *motulBurst)
+	motulClient.SetHTTPConfig(client.HTTPConfig{
+		Timeout: *motulTimeout,
+		Retry:   client.DefaultRetryConfig(*motulMaxRetries),
+	}, *motulCatalogTimeout)
+	motulClient.SetSchemaDriftSamples(*driftSampleDir, *driftSampleMax)
+	if *motulCacheDir != "" {
+		cache, err := client.NewHTTPCache(*motulCacheDir, *motulCacheTTL)
+		if err != nil {
+			logger.Error("failed to open Motul response cache", "dir", *motulCacheDir, "error", err)
+			os.Exit(1)
+		}
+		motulClient.SetCache(cache)
+		logger.Info("Motul response cache enabled", "dir", *motulCacheDir, "ttl", *motulCacheTTL)
 	}
-	logger.Info("database migrations completed")
 
-	// Initialize repository
-	vehicleRepo := repository.NewAplicacaoRepo(dbPool)
-	specRepo := repository.NewEspecificacaoRepository(dbPool)
-	falhaRepo := repository.NewScraperFalhaRepo(dbPool)
+	// Rollback mode: deactivate or delete what a bad run wrote, then exit
+	if *rollbackRun > 0 {
+		dbPool := connectDB()
+		defer dbPool.Close()
 
-	// Create Motul API client (1 request per second for catalog loading)
-	motulClient := client.NewMotulClient(1.0)
+		startedAt, err := repository.NewScraperRunRepo(dbPool).StartedAt(ctx, *rollbackRun)
+		if err != nil {
+			logger.Error("rollback failed", "scraper_run_id", *rollbackRun, "error", err)
+			return
+		}
+		if startedAt == nil {
+			logger.Error("rollback failed: scraper run not found", "scraper_run_id", *rollbackRun)
+			return
+		}
+
+		specRepo := repository.NewEspecificacaoAuditada(repository.NewEspecificacaoRepository(dbPool), repository.NewAuditLogRepo(dbPool))
+		changed, err := specRepo.RollbackRun(ctx, *rollbackRun, *rollbackDelete)
+		if err != nil {
+			logger.Error("rollback failed", "scraper_run_id", *rollbackRun, "error", err)
+			return
+		}
+		logger.Info("scraper run rolled back",
+			"scraper_run_id", *rollbackRun,
+			"started_at", *startedAt,
+			"deleted", *rollbackDelete,
+			"specs", changed,
+		)
+		return
+	}
+
+	// Norma backfill mode: fill Norma on existing Motul specs and exit
+	if *backfillNorma {
+		dbPool := connectDB()
+		defer dbPool.Close()
+
+		stats, err := scraper.BackfillNorma(ctx,
+			repository.NewEspecificacaoAuditada(repository.NewEspecificacaoRepository(dbPool), repository.NewAuditLogRepo(dbPool)),
+			scraper.NewMotulAdapter(nil, motulClient, logger),
+			100,
+			logger,
+		)
+		if err != nil {
+			logger.Error("norma backfill failed", "error", err)
+			return
+		}
+		logger.Info("norma backfill completed",
+			"rows", stats.Rows,
+			"updated", stats.Updated,
+			"not_found", stats.NotFound,
+			"failed", stats.Failed,
+		)
+		return
+	}
+
+	// Alias mode: export ALIAS_VEICULO to a spreadsheet or load curated corrections, then exit
+	if aliasTransfer {
+		dbPool := connectDB()
+		defer dbPool.Close()
+
+		aliasRepo := repository.NewAliasAuditado(repository.NewAliasRepo(dbPool), repository.NewAuditLogRepo(dbPool))
+		if *importAliases != "" {
+			result, err := importAliasesCSV(ctx, aliasRepo, *importAliases)
+			if err != nil {
+				logger.Error("alias import failed", "file", *importAliases, "error", err)
+				return
+			}
+			logger.Info("aliases imported", "file", *importAliases, "imported", result.Importados, "removed", result.Removidos)
+		}
+		if *exportAliases != "" {
+			count, err := exportAliasesCSV(ctx, aliasRepo.AliasRepo, *exportAliases)
+			if err != nil {
+				logger.Error("alias export failed", "file", *exportAliases, "error", err)
+				return
+			}
+			logger.Info("aliases exported", "file", *exportAliases, "aliases", count)
+		}
+		return
+	}
+
+	// Snapshot mode: export or restore the scraper tables and caches, then exit
+	if snapshotMode {
+		dbPool := connectDB()
+		defer dbPool.Close()
+
+		snapshotRepo := repository.NewSnapshotRepo(dbPool)
+		files := map[string]string{
+			scraper.SnapshotCatalog:    *catalogCache,
+			scraper.SnapshotEmbeddings: *embeddingsCache,
+		}
+		if *exportSnapshot != "" {
+			manifest, err := scraper.ExportSnapshot(ctx, snapshotRepo, *exportSnapshot, files)
+			if err != nil {
+				logger.Error("snapshot export failed", "file", *exportSnapshot, "error", err)
+				return
+			}
+			logger.Info("snapshot exported", "file", *exportSnapshot, "tables", manifest.Tables, "files", manifest.Files)
+		} else {
+			manifest, err := scraper.RestoreSnapshot(ctx, snapshotRepo, *restoreSnapshot, files)
+			if err != nil {
+				logger.Error("snapshot restore failed", "file", *restoreSnapshot, "error", err)
+				return
+			}
+			logger.Info("snapshot restored",
+				"file", *restoreSnapshot,
+				"created_at", manifest.CreatedAt,
+				"tables", manifest.Tables,
+				"files", manifest.Files,
+			)
+		}
+		return
+	}
 
 	// Create catalog loader and load catalog
-	catalogLoader := scraper.NewCatalogLoader(motulClient, logger)
-	_, err = catalogLoader.LoadOrFetch(ctx, *catalogCache)
-	if err != nil {
+	catalogLoader := motulmatch.NewCatalogLoader(motulClient, logger)
+	catalogLoader.SetCategories(vehicleCategories)
+	if _, err := catalogLoader.LoadOrFetch(ctx, *catalogCache); err != nil {
 		logger.Error("failed to load Motul catalog", "error", err)
 		os.Exit(1)
 	}
 
-	// Create smart matcher with the selected LLM client
-	smartMatcher := scraper.NewSmartMatcher(catalogLoader, llmClient, motulClient, logger)
+	// Rescore mode: re-check stored matches against the catalog and exit
+	if *rescoreConf {
+		dbPool := connectDB()
+		defer dbPool.Close()
 
-	// Create adapter that implements scraper.MotulClient interface
+		stats, err := scraper.RescoreConfidence(ctx,
+			repository.NewEspecificacaoAuditada(repository.NewEspecificacaoRepository(dbPool), repository.NewAuditLogRepo(dbPool)),
+			repository.NewScraperFalhaRepo(dbPool),
+			catalogLoader,
+			*dryRun,
+			500,
+			logger,
+		)
+		if err != nil {
+			logger.Error("confidence rescore failed", "error", err)
+			return
+		}
+		logger.Info("confidence rescore completed",
+			"matches", stats.Matches,
+			"specs", stats.Specs,
+			"agree", stats.Agree,
+			"partial", stats.Partial,
+			"downgraded", stats.Downgraded,
+			"flagged", stats.Flagged,
+			"not_found", stats.NotFound,
+			"failed", stats.Failed,
+			"dry_run", *dryRun,
+		)
+		return
+	}
+
+	// Create one smart matcher per vehicle category with the selected LLM client,
+	// each restricted to its category's brands
+	matchers := make(map[string]*motulmatch.Matcher)
+	for _, category := range vehicleCategories {
+		matcher := motulmatch.New(catalogLoader.ForCategory(category), llmClient, logger)
+		matcher.SetMinConfidence(*minConfidence)
+		matcher.SetModelSimilarity(*modelSimilarity)
+		matcher.SetMaxPromptDescription(*maxPromptDesc)
+		matcher.SetPipeline(pipeline)
+		matchers[category] = matcher
+	}
+	smartMatcher := matchers[client.CategoryCar]
+	if smartMatcher == nil {
+		// The match server and the adapter's default still need a car matcher
+		smartMatcher = motulmatch.New(catalogLoader.ForCategory(client.CategoryCar), llmClient, logger)
+		smartMatcher.SetMinConfidence(*minConfidence)
+		smartMatcher.SetModelSimilarity(*modelSimilarity)
+		smartMatcher.SetMaxPromptDescription(*maxPromptDesc)
+		smartMatcher.SetPipeline(pipeline)
+	}
+	logger.Info("match pipeline", "strategies", pipeline.String())
+
+	// Optionally resolve clear-cut matches via embeddings before asking the LLM
+	var embedder client.Embedder
+	if *embeddingsProvider != "" {
+		switch strings.ToLower(*embeddingsProvider) {
+		case "ollama":
+			embedder = client.NewOllamaEmbedder(*ollamaURL, *embeddingModel)
+		case "openai":
+			if *openAIAPIKey == "" {
+				fmt.Fprintln(os.Stderr, "Error: -openai-api-key or OPENAI_API_KEY required for openai embeddings")
+				os.Exit(1)
+			}
+			embedder = client.NewOpenAIEmbedder(*openAIAPIKey, *embeddingModel)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown embeddings provider: %s (use 'ollama' or 'openai')\n", *embeddingsProvider)
+			os.Exit(1)
+		}
+
+		index := motulmatch.NewEmbeddingIndex(embedder, logger)
+		if err := index.LoadOrBuild(ctx, catalogLoader, *embeddingsCache); err != nil {
+			logger.Warn("embedding index unavailable, using LLM only", "error", err)
+		} else {
+			for _, matcher := range matchers {
+				matcher.SetEmbeddingIndex(index, *embeddingMinScore, *embeddingMargin)
+			}
+			logger.Info("embedding matching enabled", "model", embedder.Model())
+		}
+	}
+
+	// Batch the type prompts of concurrent workers into one LLM call; with a
+	// single worker a batch would never fill, and match server requests are
+	// answered one by one
+	var typeBatcher *motulmatch.TypeBatcher
+	if batchLLM, ok := llmClient.(client.BatchNormalizer); ok && *llmBatch > 1 && *workers > 1 && *serveMatchPort == 0 {
+		typeBatcher = motulmatch.NewTypeBatcher(batchLLM, min(*llmBatch, *workers), *llmBatchWait, logger)
+		for _, matcher := range matchers {
+			matcher.SetTypeBatcher(typeBatcher)
+		}
+		logger.Info("LLM type prompt batching enabled", "batch_size", min(*llmBatch, *workers), "max_wait", *llmBatchWait)
+	}
+
+	// Match-as-a-service mode: expose the matcher over HTTP instead of scraping
+	if *serveMatchPort > 0 {
+		// POST /lookup also fetches the specs of the matched vehicle (on-demand API lookups)
+		lookupAdapter := scraper.NewMotulAdapter(smartMatcher, motulClient, logger)
+		for category, matcher := range matchers {
+			if category != client.CategoryCar {
+				lookupAdapter.SetCategoryMatcher(category, matcher)
+			}
+		}
+		lookupRules := categoryRules
+		if lookupRules == nil {
+			lookupRules = scraper.DefaultCategoryRules()
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/", motulmatch.Handler(smartMatcher))
+		mux.Handle("/lookup", scraper.LookupHandler(lookupAdapter, lookupRules))
+		var keyHealth scraper.KeyHealthSource // Stays nil without Groq (typed nil would not)
+		if groqClient != nil {
+			keyHealth = groqClient
+		}
+		mux.Handle("/llm-status", scraper.LLMStatusHandler(keyHealth))
+
+		server := &http.Server{
+			Addr: fmt.Sprintf(":%d", *serveMatchPort),
+			// Continues the traces of API live lookups
+			Handler: otelhttp.NewHandler(mux, "match-server", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + r.URL.Path
+			})),
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer shutdownCancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		logger.Info("match server listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("match server failed", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("match server stopped")
+		flushTracing()
+		return
+	}
+
+	// Select vehicle source (database or CSV) and spec sink
+	sinkName := strings.ToLower(*sink)
+	if sinkName == "" {
+		sinkName = scraper.SinkDB
+		if *input != "" {
+			sinkName = scraper.SinkFile
+		}
+	}
+
+	var (
+		vehicleRepo     scraper.VehicleRepository
+		specSink        scraper.SpecSink
+		falhaRepo       *repository.ScraperFalhaRepo
+		attempts        *repository.ScraperTentativaRepo
+		popularity      *repository.PopularidadeRepo
+		checkpoints     *repository.ScraperCheckpointRepo
+		workQueue       *repository.ScraperQueueRepo
+		runRepo         *repository.ScraperRunRepo
+		aliasRepo       *repository.AliasAuditado
+		classifications *repository.ClassificacaoRepo
+		embeddingRepo   *repository.AplicacaoEmbeddingRepo
+		closeSink       func() error // Flushes/closes file sinks, even on cancellation
+	)
+
+	if *input == "" || sinkName == scraper.SinkDB {
+		dbPool := connectDB()
+		defer dbPool.Close()
+
+		// Initialize repository
+		vehicleRepo = repository.NewAplicacaoRepo(dbPool)
+		falhaRepo = repository.NewScraperFalhaRepo(dbPool)
+		attempts = repository.NewScraperTentativaRepo(dbPool)
+		popularity = repository.NewPopularidadeRepo(dbPool)
+		checkpoints = repository.NewScraperCheckpointRepo(dbPool)
+		workQueue = repository.NewScraperQueueRepo(dbPool, *queueLease)
+		runRepo = repository.NewScraperRunRepo(dbPool)
+		auditLog := repository.NewAuditLogRepo(dbPool)
+		aliasRepo = repository.NewAliasAuditado(repository.NewAliasRepo(dbPool), auditLog)
+		classifications = repository.NewClassificacaoRepo(dbPool)
+		embeddingRepo = repository.NewAplicacaoEmbeddingRepo(dbPool)
+		if sinkName == scraper.SinkDB {
+			specSink = repository.NewEspecificacaoAuditada(repository.NewEspecificacaoRepository(dbPool), auditLog)
+		}
+	}
+
+	if *input != "" {
+		format, path, ok := strings.Cut(*input, "=")
+		if !ok || strings.ToLower(format) != "csv" || path == "" {
+			fmt.Fprintln(os.Stderr, "Error: -input must be csv=<file>")
+			os.Exit(1)
+		}
+		vehicleRepo = scraper.NewCSVVehicleSource(path)
+		logger.Info("reading vehicles from file", "input", path)
+	}
+
+	// Differential run reference point
+	var since time.Time
+	switch {
+	case *sinceDate != "" && *sinceRun > 0:
+		fmt.Fprintln(os.Stderr, "Error: use either -since or -since-run, not both")
+		os.Exit(1)
+	case *sinceDate != "":
+		t, err := parseSince(*sinceDate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -since: %v\n", err)
+			os.Exit(1)
+		}
+		since = t
+	case *sinceRun > 0:
+		if runRepo == nil {
+			fmt.Fprintln(os.Stderr, "Error: -since-run requires the Wega DB")
+			os.Exit(1)
+		}
+		startedAt, err := runRepo.StartedAt(ctx, *sinceRun)
+		if err != nil {
+			logger.Error("failed to load reference run", "run", *sinceRun, "error", err)
+			os.Exit(1)
+		}
+		if startedAt == nil {
+			fmt.Fprintf(os.Stderr, "Error: scraper run %d not found in SCRAPER_RUN\n", *sinceRun)
+			os.Exit(1)
+		}
+		since = *startedAt
+	}
+
+	switch sinkName {
+	case scraper.SinkDB:
+		// Already set up above
+	case scraper.SinkFile:
+		fileWriter := scraper.NewFileSpecWriter(*output)
+		specSink = fileWriter
+		closeSink = fileWriter.Flush
+	case scraper.SinkNDJSON:
+		ndjsonSink, err := scraper.NewNDJSONSpecSink(*output)
+		if err != nil {
+			logger.Error("failed to open NDJSON sink", "file", *output, "error", err)
+			os.Exit(1)
+		}
+		specSink = ndjsonSink
+		closeSink = ndjsonSink.Close
+	case scraper.SinkHTTP:
+		if *sinkURL == "" {
+			fmt.Fprintln(os.Stderr, "Error: -sink-url is required with -sink=http")
+			os.Exit(1)
+		}
+		specSink = scraper.NewHTTPSpecSink(*sinkURL, *sinkToken)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown sink: %s (use 'db', 'file', 'ndjson' or 'http')\n", *sink)
+		os.Exit(1)
+	}
+	logger.Info("spec sink selected", "sink", sinkName)
+
+	// Seed each category's matcher with known brand/model mappings (curated
+	// ones fix what the LLM got wrong): its own category's and the shared ones
+	sharedAliases := make(map[model.AliasVeiculo]bool)
+	if aliasRepo != nil {
+		aliases, err := aliasRepo.List(ctx, "")
+		if err != nil {
+			logger.Warn("failed to load aliases, matching without them", "error", err)
+		} else {
+			loaded := 0
+			for category, matcher := range matchers {
+				loaded += matcher.LoadAliases(aliasesForCategory(aliases, category))
+			}
+			for _, a := range aliases {
+				if a.Categoria == "" {
+					sharedAliases[aliasMapping(a)] = true
+				}
+			}
+			logger.Info("aliases loaded", "aliases", len(aliases), "loaded", loaded)
+		}
+	}
+
+	// Register the spec providers; the Motul adapter wraps the smart matchers
 	motulAdapter := scraper.NewMotulAdapter(smartMatcher, motulClient, logger)
+	for category, matcher := range matchers {
+		if category != client.CategoryCar {
+			motulAdapter.SetCategoryMatcher(category, matcher)
+		}
+	}
+
+	providers := scraper.NewProviderRegistry()
+	if err := providers.Register(motulAdapter); err != nil {
+		logger.Error("failed to register spec provider", "error", err)
+		os.Exit(1)
+	}
+	provider, err := providers.Get(*providerName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	logger.Info("spec provider selected", "provider", provider.Name())
 
 	// Setup scraper config
 	scraperConfig := scraper.ScraperConfig{
-		Workers:          *workers,
-		RateLimit:        time.Duration(*rateLimitMs) * time.Millisecond,
-		CheckpointEvery:  *checkpointEvery,
-		CheckpointFile:   *checkpointFile,
-		ResumeFromID:     *resumeFromID,
-		DryRun:           *dryRun,
-		HTTPMonitorPort:  *monitorPort,
-		EnableMonitoring: !*noMonitor,
+		Workers:           *workers,
+		RateLimit:         time.Duration(*rateLimitMs) * time.Millisecond,
+		CheckpointEvery:   *checkpointEvery,
+		CheckpointFile:    *checkpointFile,
+		ResumeFromID:      *resumeFromID,
+		DryRun:            *dryRun,
+		DryRunReport:      *dryRunReport,
+		HTTPMonitorPort:   *monitorPort,
+		EnableMonitoring:  !*noMonitor,
+		RefreshOlderThan:  *refreshOlder,
+		AuditFile:         *auditFile,
+		Categories:        vehicleCategories,
+		PrioritizePopular: *prioritize,
+		Since:             since,
+		OnlyIDs:           selectedIDs,
+		ControlToken:      *controlToken,
+		DebugEndpoints:    *debugEndpoints,
+		MinSaveConfidence: *minSaveConf,
+
+		AlertWebhookURL:         *alertWebhook,
+		RateLimitAlertThreshold: *rateLimitAlert,
+
+		SuccessRateWindow:     *successWindow,
+		SuccessRateThreshold:  *successAlert,
+		PauseOnLowSuccessRate: *pauseOnLowRate,
+
+		WebhookURLs:           parseAPIKeys(*webhookURLs), // same comma-separated format
+		WebhookSecret:         *webhookSecret,
+		FailureAlertThreshold: *failureAlert,
 	}
 
 	// Create scraper service
 	scraperService := scraper.NewScraperService(
 		scraperConfig,
 		vehicleRepo,
-		specRepo,
-		motulAdapter,
+		specSink,
+		provider,
 		logger,
 	)
 
 	// Set failure repository for tracking failed attempts
-	scraperService.SetFalhaRepo(falhaRepo)
+	if falhaRepo != nil {
+		scraperService.SetFalhaRepo(falhaRepo)
+	}
+	if attempts != nil {
+		scraperService.SetAttemptRecorder(attempts)
+	}
+	if popularity != nil {
+		scraperService.SetPopularityRepo(popularity)
+	}
+	// Reuse the Motul type of nearly identical applications; the API keeps
+	// their embeddings in pgvector with the same provider and model
+	if embeddingRepo != nil && embedder != nil && *neighborMinScore > 0 {
+		if ok, err := embeddingRepo.Disponivel(ctx); err != nil || !ok {
+			logger.Info("neighbor matching unavailable: no APLICACAO_EMBEDDING table (pgvector)", "error", err)
+		} else {
+			scraperService.SetNeighborSource(embeddingRepo, embedder.Model(), *neighborMinScore)
+			logger.Info("neighbor matching enabled", "model", embedder.Model(), "min_score", *neighborMinScore)
+		}
+	}
+	if categoryRules != nil {
+		scraperService.SetCategoryRules(categoryRules)
+		logger.Info("category rules loaded", "file", *skipRules)
+
+		// SIGHUP reloads the rules; a broken file keeps the current ones
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		go func() {
+			for range hupChan {
+				rules, err := scraper.LoadCategoryRules(*skipRules)
+				if err != nil {
+					logger.Error("failed to reload category rules, keeping current rules", "file", *skipRules, "error", err)
+					continue
+				}
+				scraperService.SetCategoryRules(rules)
+				logger.Info("category rules reloaded", "file", *skipRules)
+			}
+		}()
+	}
+	if *llmClassify {
+		if classifications == nil {
+			fmt.Fprintln(os.Stderr, "Error: -llm-classify requires the Wega DB")
+			os.Exit(1)
+		}
+		scraperService.SetClassifier(scraper.NewLLMClassifier(llmClient, classifications, *classifyBatch, logger))
+		logger.Info("LLM vehicle classification enabled", "batch_size", *classifyBatch)
+	}
+	if !*noSpecValidate {
+		scraperService.SetSpecValidator(parser.NewSpecValidator(parser.DefaultSpecValidatorConfig()))
+	}
+	if *slackWebhook != "" {
+		scraperService.AddReportNotifier(scraper.NewSlackNotifier(*slackWebhook))
+	}
+	if *discordWebhook != "" {
+		scraperService.AddReportNotifier(scraper.NewDiscordNotifier(*discordWebhook))
+	}
+	// Coverage only moves when specs are saved to the Wega DB
+	if coverage, ok := specSink.(scraper.CoverageSource); ok {
+		scraperService.SetCoverageSource(coverage)
+	}
+	runID := *checkpointRunID
+	if runID == "" {
+		runID = provider.Name()
+	}
+	hostname, _ := os.Hostname()
+	worker := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	if checkpoints != nil {
+		scraperService.SetCheckpointStore(checkpoints, runID)
+		logger.Info("checkpoints stored in database", "run_id", runID)
+	}
+	if runRepo != nil {
+		if failed, err := runRepo.FailStale(ctx, scraper.StaleRunAfter); err != nil {
+			logger.Warn("failed to mark stale scraper runs", "error", err)
+		} else if failed > 0 {
+			logger.Warn("marked crashed scraper runs as failed", "runs", failed)
+		}
+		scraperService.SetRunRecorder(runRepo, runID, worker)
+		llmName := *llmProvider
+		if *llmChain != "" {
+			llmName = *llmChain
+		}
+		scraperService.SetRunConfig(map[string]string{
+			"llm":            llmName,
+			"match_pipeline": pipeline.String(),
+			"embeddings":     *embeddingsProvider,
+			"neighbor_min":   fmt.Sprint(*neighborMinScore),
+			"sink":           sinkName,
+			"skip_rules":     *skipRules,
+			"llm_classify":   fmt.Sprint(*llmClassify),
+			"min_save_conf":  fmt.Sprint(*minSaveConf),
+		})
+	}
+
+	// Distributed mode: instances with the same run ID share SCRAPER_QUEUE
+	if *distributed {
+		if workQueue == nil {
+			fmt.Fprintln(os.Stderr, "Error: -distributed requires the Wega DB")
+			os.Exit(1)
+		}
+		if *queueReset {
+			removed, err := workQueue.Reset(ctx, runID)
+			if err != nil {
+				logger.Error("failed to reset work queue", "error", err)
+				os.Exit(1)
+			}
+			logger.Info("work queue reset", "run_id", runID, "removed", removed)
+		}
+
+		scraperService.SetWorkQueue(workQueue, runID, worker)
+		logger.Info("distributed mode enabled", "run_id", runID, "worker", worker, "lease", *queueLease)
+	}
+
+	// Count rate-limit hits and network errors from the external clients
+	motulClient.SetObserver(scraperService)
+	scraperService.SetRateSource(motulClient)
+	if groqClient != nil {
+		scraperService.SetKeyHealthSource(groqClient)
+	}
+	if observable, ok := llmClient.(client.Observable); ok {
+		observable.SetObserver(scraperService)
+	}
 
 	// Run scraper
-	if err := scraperService.Run(ctx); err != nil {
+	err = scraperService.Run(ctx)
+	flushTracing()
+
+	// Keep what the matchers learned for the next run and for offline review
+	if aliasRepo != nil && !*dryRun {
+		// Saved under each matcher's category, so categories don't overwrite
+		// each other; shared aliases the matcher only loaded are left alone
+		var learned []model.AliasVeiculo
+		for category, matcher := range matchers {
+			for _, a := range matcher.Aliases() {
+				if !sharedAliases[aliasMapping(a)] {
+					a.Categoria = category
+					learned = append(learned, a)
+				}
+			}
+		}
+		saved, saveErr := aliasRepo.SaveLearned(context.WithoutCancel(ctx), learned)
+		if saveErr != nil {
+			logger.Warn("failed to save learned aliases", "error", saveErr)
+		} else {
+			logger.Info("learned aliases saved", "aliases", len(learned), "changed", saved)
+		}
+	}
+	if *motulCacheDir != "" {
+		logger.Info("Motul response cache", "hits", motulClient.CacheHits())
+	}
+	if typeBatcher != nil {
+		batches, prompts := typeBatcher.Stats()
+		logger.Info("LLM type prompt batching", "batches", batches, "prompts", prompts)
+	}
+
+	// Write whatever was collected, even on cancellation
+	if closeSink != nil {
+		if closeErr := closeSink(); closeErr != nil {
+			logger.Error("failed to write output", "file", *output, "error", closeErr)
+			os.Exit(1)
+		}
+		logger.Info("results written", "file", *output)
+	}
+
+	if err != nil {
 		if err == context.Canceled {
 			logger.Info("scraper cancelled")
 			os.Exit(0)
@@ -262,6 +1124,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvDuration gets a duration environment variable (e.g. "30s") or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
 // parseAPIKeys splits comma-separated API keys and filters empty ones
 func parseAPIKeys(keysStr string) []string {
 	parts := strings.Split(keysStr, ",")
@@ -274,3 +1146,171 @@ func parseAPIKeys(keysStr string) []string {
 	}
 	return keys
 }
+
+// parseOnlyIDs merges -only-id and the comma-separated -only-ids, dropping
+// duplicates; nil when neither is set
+func parseOnlyIDs(onlyID int, onlyIDs string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	add := func(id int) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if onlyID < 0 {
+		return nil, fmt.Errorf("%d is not a CodigoAplicacao", onlyID)
+	}
+	if onlyID > 0 {
+		add(onlyID)
+	}
+	for _, part := range parseAPIKeys(onlyIDs) {
+		id, err := strconv.Atoi(part)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%q is not a CodigoAplicacao", part)
+		}
+		add(id)
+	}
+	return ids, nil
+}
+
+// parseSince parses a -since value: a date (start of day, local time) or RFC3339
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseCategories splits comma-separated vehicle categories; nil if any is unknown
+func parseCategories(categoriesStr string) []string {
+	var categories []string
+	for _, c := range strings.Split(categoriesStr, ",") {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		if !client.IsCategory(c) {
+			return nil
+		}
+		categories = append(categories, c)
+	}
+	return categories
+}
+
+// aliasesForCategory returns the aliases that apply to a category matcher,
+// in LoadAliases order so the later ones win: learned before curated, and
+// within each the shared ones before the category's own
+func aliasesForCategory(aliases []model.AliasVeiculo, category string) []model.AliasVeiculo {
+	rank := func(a model.AliasVeiculo) int {
+		r := 0
+		if a.Fonte == model.AliasCurado {
+			r += 2
+		}
+		if a.Categoria != "" {
+			r++
+		}
+		return r
+	}
+
+	var result []model.AliasVeiculo
+	for _, a := range aliases {
+		if a.Categoria == "" || a.Categoria == category {
+			result = append(result, a)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b model.AliasVeiculo) int {
+		return cmp.Compare(rank(a), rank(b))
+	})
+	return result
+}
+
+// aliasMapping is the alias reduced to what it maps, to compare the learned
+// aliases with the shared ones a matcher was seeded with
+func aliasMapping(a model.AliasVeiculo) model.AliasVeiculo {
+	return model.AliasVeiculo{Tipo: a.Tipo, Marca: a.Marca, Origem: a.Origem, Destino: a.Destino}
+}
+
+// importAliasesCSV loads curated aliases from a CSV file into ALIAS_VEICULO
+func importAliasesCSV(ctx context.Context, repo *repository.AliasAuditado, path string) (*model.AliasImportResponse, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	aliases, err := model.LerAliasesCSV(f)
+	if err != nil {
+		return nil, err
+	}
+	return repo.Import(ctx, aliases)
+}
+
+// exportAliasesCSV writes every alias in ALIAS_VEICULO to a CSV file
+func exportAliasesCSV(ctx context.Context, repo *repository.AliasRepo, path string) (int, error) {
+	aliases, err := repo.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := model.EscreverAliasesCSV(f, aliases); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(aliases), f.Close()
+}
+
+// applyFileFlags sets the flags not given on the command line from config file
+// keys named after them (groq-rpm <- GROQ_RPM), covering flags with no env var
+func applyFileFlags(fileKeys []string) error {
+	inFile := make(map[string]bool, len(fileKeys))
+	for _, key := range fileKeys {
+		inFile[key] = true
+	}
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	var err error
+	flag.VisitAll(func(f *flag.Flag) {
+		key := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if err != nil || explicit[f.Name] || !inFile[key] {
+			return
+		}
+		if setErr := flag.Set(f.Name, os.Getenv(key)); setErr != nil {
+			err = fmt.Errorf("%s: %w", key, setErr)
+		}
+	})
+	return err
+}
+
+// effectiveFlags returns every flag value for the startup log, hiding secrets
+// and reducing webhook URLs (which embed tokens) to their host
+func effectiveFlags() map[string]string {
+	values := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		name := f.Name
+		switch {
+		case value == "":
+		case strings.Contains(name, "password"), strings.Contains(name, "key"),
+			strings.Contains(name, "token"), strings.Contains(name, "secret"):
+			value = "[set]"
+		case strings.Contains(name, "webhook"):
+			hosts := strings.Split(value, ",")
+			for i, raw := range hosts {
+				if u, err := url.Parse(strings.TrimSpace(raw)); err == nil && u.Host != "" {
+					hosts[i] = u.Scheme + "://" + u.Host + "/[hidden]"
+				} else {
+					hosts[i] = "[hidden]"
+				}
+			}
+			value = strings.Join(hosts, ",")
+		}
+		values[name] = value
+	})
+	return values
+}
@@ -0,0 +1,75 @@
+-- Small catalog fixture: a dozen popular Brazilian vehicles, their Wega
+-- filters and competitor cross-references
+
+INSERT INTO "FABRICANTE" ("CodigoFabricante", "DescricaoFabricante", "FlagAplicacao", "FlagProduto") VALUES
+	(1, 'VOLKSWAGEN', 1, 0),
+	(2, 'FIAT', 1, 0),
+	(3, 'CHEVROLET', 1, 0),
+	(4, 'FORD', 1, 0),
+	(5, 'TOYOTA', 1, 0),
+	(6, 'HONDA', 1, 0),
+	(7, 'HYUNDAI', 1, 0),
+	(8, 'RENAULT', 1, 0),
+	(101, 'TECFIL', 0, 1),
+	(102, 'MANN-FILTER', 0, 1),
+	(103, 'FRAM', 0, 1),
+	(104, 'BOSCH', 0, 1);
+
+INSERT INTO "SUBGRUPOPRODUTO" ("CodigoSubGrupoProduto", "DescricaoSubGrupoProduto") VALUES
+	(1, 'Filtro de Óleo'),
+	(2, 'Filtro de Ar'),
+	(3, 'Filtro de Combustível'),
+	(4, 'Filtro de Cabine');
+
+-- Periods use both catalog formats; the RENAULT row has none (unknown year)
+INSERT INTO "APLICACAO" ("CodigoAplicacao", "CodigoFabricante", "DescricaoAplicacao", "ComplementoAplicacao2", "ComplementoAplicacao3") VALUES
+	(1001, 1, 'GOL 1.0 8V G5', '2008 --> 2013', '1.0 8V EA111 Flex'),
+	(1002, 1, 'POLO 1.6 16V MSI', '2018 -->', '1.6 16V MSI Flex'),
+	(1003, 1, 'AMAROK 2.0 TDI', '2011 -->', '2.0 16V BiTurbo Diesel'),
+	(1004, 2, 'PALIO 1.0 FIRE', '2004 --> 2017', '1.0 8V Fire Flex'),
+	(1005, 2, 'STRADA 1.4 WORKING', '// 08 -- 12', '1.4 8V Fire Flex'),
+	(1006, 3, 'ONIX 1.0', '2013 --> 2019', '1.0 8V SPE/4 Flex'),
+	(1007, 3, 'S10 2.8 CTDI', '2012 -->', '2.8 16V Duramax Diesel'),
+	(1008, 4, 'KA 1.0 TI-VCT', '2015 --> 2021', '1.0 12V Sigma Flex'),
+	(1009, 5, 'COROLLA 2.0 XEI', '2015 --> 2019', '2.0 16V Dual VVT-i Flex'),
+	(1010, 6, 'CIVIC 2.0 EXL', '2017 --> 2021', '2.0 16V i-VTEC Flex'),
+	(1011, 7, 'HB20 1.0 COMFORT', '2013 --> 2019', '1.0 12V Kappa Flex'),
+	(1012, 8, 'SANDERO 1.6 8V', NULL, '1.6 8V Hi-Flex');
+
+INSERT INTO "PRODUTO" ("CodigoProduto", "NumeroProduto", "DescricaoProduto", "CodigoSubGrupoProduto", "ArquivoFotoProduto", "PrecoProduto") VALUES
+	(1, 'WO-200', 'Filtro de óleo blindado', 1, 'WO-200.jpg', 24.90),
+	(2, 'WO-120', 'Filtro de óleo blindado', 1, 'WO-120.jpg', 22.50),
+	(3, 'WO-340', 'Filtro de óleo blindado', 1, NULL, 31.90),
+	(4, 'WOE-580', 'Elemento filtrante de óleo (diesel)', 1, NULL, 58.00),
+	(5, 'FAP-2835', 'Filtro de ar do motor', 2, 'FAP-2835.jpg', 39.90),
+	(6, 'FAP-4920', 'Filtro de ar do motor', 2, NULL, 45.00),
+	(7, 'FAP-9012', 'Filtro de ar do motor', 2, NULL, 52.90),
+	(8, 'FCI-1615', 'Filtro de combustível flex', 3, NULL, 29.90),
+	(9, 'FCD-2087', 'Filtro de combustível diesel', 3, NULL, 89.90),
+	(10, 'AKX-35154', 'Filtro de cabine', 4, 'AKX-35154.jpg', 34.90),
+	(11, 'AKX-1367', 'Filtro de cabine com carvão ativado', 4, NULL, 49.90);
+
+INSERT INTO "PRODUTO_APLICACAO" ("CodigoProduto", "CodigoAplicacao") VALUES
+	(1, 1001), (5, 1001), (8, 1001), (10, 1001),
+	(2, 1002), (6, 1002), (8, 1002), (10, 1002),
+	(4, 1003), (7, 1003), (9, 1003),
+	(1, 1004), (5, 1004), (8, 1004),
+	(1, 1005), (5, 1005), (8, 1005),
+	(2, 1006), (6, 1006), (8, 1006), (11, 1006),
+	(4, 1007), (7, 1007), (9, 1007),
+	(2, 1008), (6, 1008), (8, 1008),
+	(3, 1009), (7, 1009), (8, 1009), (11, 1009),
+	(3, 1010), (7, 1010), (11, 1010),
+	(2, 1011), (6, 1011), (8, 1011), (10, 1011);
+
+INSERT INTO "REFERENCIACRUZADA" ("CodigoProduto", "CodigoFabricante", "NumeroProdutoPesq") VALUES
+	(1, 101, 'PSL55'),
+	(1, 102, 'W712/95'),
+	(1, 103, 'PH5548'),
+	(2, 101, 'PSL315'),
+	(2, 103, 'PH6017A'),
+	(3, 102, 'W68/3'),
+	(5, 101, 'ARL2835'),
+	(6, 104, 'F026400179'),
+	(8, 101, 'GI04/7'),
+	(10, 102, 'CU22011');
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/database"
+)
+
+var (
+	//go:embed schema.sql
+	schemaSQL string
+	//go:embed fixtures.sql
+	fixturesSQL string
+	//go:embed specs.sql
+	specsSQL string
+)
+
+// coreTables are the Wega tables created by schema.sql, in truncation order
+var coreTables = []string{
+	"REFERENCIACRUZADA",
+	"PRODUTO_APLICACAO",
+	"PRODUTO",
+	"APLICACAO",
+	"SUBGRUPOPRODUTO",
+	"FABRICANTE",
+}
+
+func main() {
+	configFile := flag.String("config", os.Getenv(config.ConfigFileEnv), "YAML or TOML config file")
+	reset := flag.Bool("reset", false, "Truncate the catalog tables (and every table referencing them) before seeding")
+	noMigrate := flag.Bool("no-migrate", false, "Only create and fill the Wega tables; skip the migrations and the sample specs")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: seed [flags]")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Creates the core Wega tables in the database configured by the DB_* env vars and")
+		fmt.Fprintln(os.Stderr, "loads a small fixture catalog, so the API runs locally without a production dump.")
+		fmt.Fprintln(os.Stderr)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *configFile != "" {
+		if _, err := config.LoadFile(*configFile); err != nil {
+			fail(err)
+		}
+	}
+	if _, err := config.LoadSecretFiles(); err != nil {
+		fail(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fail(err)
+	}
+
+	// Loading the fixtures runs longer than an API query; the context bounds it
+	cfg.Database.QueryTimeout = 0
+	pool, err := database.NewPostgresPool(cfg.Database)
+	if err != nil {
+		fail(err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := seed(ctx, pool, *reset, !*noMigrate); err != nil {
+		pool.Close()
+		fail(err)
+	}
+}
+
+// seed creates the catalog tables, loads the fixtures in one transaction and,
+// with migrate, applies the migrations and adds the sample specs
+func seed(ctx context.Context, pool *pgxpool.Pool, reset, migrate bool) error {
+	if _, err := pool.Exec(ctx, schemaSQL); err != nil {
+		return fmt.Errorf("failed to create catalog tables: %w", err)
+	}
+
+	var fabricantes int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM "FABRICANTE"`).Scan(&fabricantes); err != nil {
+		return fmt.Errorf("failed to count manufacturers: %w", err)
+	}
+	if fabricantes > 0 && !reset {
+		return fmt.Errorf("database already has catalog data (%d manufacturers); use -reset to replace it with the fixtures", fabricantes)
+	}
+
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if reset {
+			// CASCADE also empties the migrated tables that reference APLICACAO (specs, popularity...)
+			if _, err := tx.Exec(ctx, `TRUNCATE `+quotedList(coreTables)+` CASCADE`); err != nil {
+				return fmt.Errorf("failed to truncate catalog tables: %w", err)
+			}
+		}
+		if _, err := tx.Exec(ctx, fixturesSQL); err != nil {
+			return fmt.Errorf("failed to load fixtures: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("catalog fixtures loaded")
+
+	if !migrate {
+		return printCounts(ctx, pool, coreTables)
+	}
+	if err := database.RunMigrations(ctx, pool); err != nil {
+		return err
+	}
+	if _, err := pool.Exec(ctx, specsSQL); err != nil {
+		return fmt.Errorf("failed to load sample specs: %w", err)
+	}
+	fmt.Println("migrations applied and sample specs loaded")
+
+	return printCounts(ctx, pool, slices.Concat(coreTables, []string{"ESPECIFICACAO_TECNICA"}))
+}
+
+// printCounts shows how many rows each seeded table holds
+func printCounts(ctx context.Context, pool *pgxpool.Pool, tables []string) error {
+	for _, table := range tables {
+		var count int
+		if err := pool.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %q`, table)).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		fmt.Printf("  %-22s %d\n", table, count)
+	}
+	return nil
+}
+
+// quotedList joins table names as quoted SQL identifiers
+func quotedList(tables []string) string {
+	list := ""
+	for i, table := range tables {
+		if i > 0 {
+			list += ", "
+		}
+		list += fmt.Sprintf("%q", table)
+	}
+	return list
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
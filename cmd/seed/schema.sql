@@ -0,0 +1,50 @@
+-- Core Wega catalog tables, normally restored from the production dump. Only
+-- the columns the API and the scraper read are created.
+
+CREATE TABLE IF NOT EXISTS "FABRICANTE" (
+	"CodigoFabricante" INTEGER PRIMARY KEY,
+	"DescricaoFabricante" VARCHAR(100) NOT NULL,
+	"FlagAplicacao" SMALLINT NOT NULL DEFAULT 0, -- Vehicle brand (APLICACAO)
+	"FlagProduto" SMALLINT NOT NULL DEFAULT 0    -- Filter brand (REFERENCIACRUZADA)
+);
+
+CREATE TABLE IF NOT EXISTS "SUBGRUPOPRODUTO" (
+	"CodigoSubGrupoProduto" INTEGER PRIMARY KEY,
+	"DescricaoSubGrupoProduto" VARCHAR(100) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "APLICACAO" (
+	"CodigoAplicacao" INTEGER PRIMARY KEY,
+	"CodigoFabricante" INTEGER NOT NULL REFERENCES "FABRICANTE"("CodigoFabricante"),
+	"DescricaoAplicacao" VARCHAR(255) NOT NULL,
+	"ComplementoAplicacao2" VARCHAR(100), -- Period, e.g. "2013 --> 2019" or "// 08 -- 12"
+	"ComplementoAplicacao3" VARCHAR(100)  -- Engine
+);
+
+CREATE TABLE IF NOT EXISTS "PRODUTO" (
+	"CodigoProduto" INTEGER PRIMARY KEY,
+	"NumeroProduto" VARCHAR(50) NOT NULL,
+	"DescricaoProduto" VARCHAR(255),
+	"CodigoSubGrupoProduto" INTEGER NOT NULL REFERENCES "SUBGRUPOPRODUTO"("CodigoSubGrupoProduto"),
+	"ArquivoFotoProduto" VARCHAR(255),
+	"PrecoProduto" NUMERIC(10,2)
+);
+
+CREATE TABLE IF NOT EXISTS "PRODUTO_APLICACAO" (
+	"CodigoProduto" INTEGER NOT NULL REFERENCES "PRODUTO"("CodigoProduto"),
+	"CodigoAplicacao" INTEGER NOT NULL REFERENCES "APLICACAO"("CodigoAplicacao"),
+	PRIMARY KEY ("CodigoProduto", "CodigoAplicacao")
+);
+
+CREATE TABLE IF NOT EXISTS "REFERENCIACRUZADA" (
+	"CodigoProduto" INTEGER NOT NULL REFERENCES "PRODUTO"("CodigoProduto"),
+	"CodigoFabricante" INTEGER NOT NULL REFERENCES "FABRICANTE"("CodigoFabricante"),
+	"NumeroProdutoPesq" VARCHAR(50) NOT NULL
+);
+
+-- Recommended indexes (docs/API.md)
+CREATE INDEX IF NOT EXISTS idx_aplicacao_fabricante ON "APLICACAO"("CodigoFabricante");
+CREATE INDEX IF NOT EXISTS idx_aplicacao_descricao ON "APLICACAO" USING gin(to_tsvector('portuguese', "DescricaoAplicacao"));
+CREATE INDEX IF NOT EXISTS idx_produto_aplicacao_aplicacao ON "PRODUTO_APLICACAO"("CodigoAplicacao");
+CREATE INDEX IF NOT EXISTS idx_produto_aplicacao_produto ON "PRODUTO_APLICACAO"("CodigoProduto");
+CREATE INDEX IF NOT EXISTS idx_referencia_pesq ON "REFERENCIACRUZADA"("NumeroProdutoPesq");
@@ -0,0 +1,16 @@
+-- Sample specifications for a few fixture vehicles, as the Motul scraper
+-- would store them (requires the migrations)
+
+INSERT INTO "ESPECIFICACAO_TECNICA" (
+	"CodigoAplicacao", "TipoFluido", "Condicao", "Viscosidade", "Capacidade", "Norma",
+	"Recomendacao", "Fonte", "MatchConfidence", "CapacidadeLitros", "ViscosidadesSAE",
+	"IntervaloTrocaKm", "IntervaloTrocaMeses"
+) VALUES
+	(1001, 'ENGINE_OIL', '', '5W-40', '3,5 litros', 'API SN, VW 502.00', 'MOTUL 8100 X-CESS 5W-40', 'MotulAPI', 0.96, 3.50, '{5W-40}', 10000, 12),
+	(1001, 'BRAKE_FLUID', '', NULL, '0,5 litros', 'DOT 4', 'MOTUL DOT 3&4 BRAKE FLUID', 'MotulAPI', 0.96, 0.50, NULL, NULL, 24),
+	(1006, 'ENGINE_OIL', '', '5W-30', '3,5 litros', 'API SN, dexos1', 'MOTUL 8100 ECO-CLEAN 5W-30', 'MotulAPI', 0.92, 3.50, '{5W-30}', 10000, 12),
+	(1006, 'ENGINE_OIL', 'SEVERE', '5W-30', '3,5 litros', 'API SN, dexos1', 'MOTUL 8100 ECO-CLEAN 5W-30', 'MotulAPI', 0.92, 3.50, '{5W-30}', 5000, 6),
+	(1007, 'ENGINE_OIL', '', '5W-30', '6,5 litros', 'ACEA C3, dexos2', 'MOTUL 8100 X-CLEAN 5W-30', 'MotulAPI', 0.88, 6.50, '{5W-30}', 10000, 12),
+	(1009, 'ENGINE_OIL', '', '0W-20', '4,2 litros', 'API SP, ILSAC GF-6A', 'MOTUL 8100 ECO-LITE 0W-20', 'MotulAPI', 0.95, 4.20, '{0W-20}', 10000, 12),
+	(1009, 'TRANSMISSION_OIL', '', NULL, '7,4 litros', 'Toyota TC', 'MOTUL MULTI CVTF', 'MotulAPI', 0.95, 7.40, NULL, 60000, NULL)
+ON CONFLICT DO NOTHING;
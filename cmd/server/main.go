@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log/slog"
 	"net/http"
 	"os"
@@ -11,14 +12,19 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"wega-catalog-api/internal/config"
 	"wega-catalog-api/internal/database"
 	"wega-catalog-api/internal/handler"
 	"wega-catalog-api/internal/repository"
 	"wega-catalog-api/internal/service"
+	"wega-catalog-api/internal/telemetry"
 )
 
+// requestTimeout limita as requisicoes, exceto o export do catalogo
+const requestTimeout = 30 * time.Second
+
 func main() {
 	// Logger estruturado
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
@@ -26,8 +32,38 @@ func main() {
 
 	slog.Info("iniciando wega-catalog-api")
 
-	// Carregar config
-	cfg := config.Load()
+	// Carregar config: arquivo opcional (-config ou WEGA_CONFIG_FILE), sobrescrito pelo ambiente
+	configFile := flag.String("config", os.Getenv(config.ConfigFileEnv), "Arquivo de configuracao YAML ou TOML")
+	flag.Parse()
+	if *configFile != "" {
+		keys, err := config.LoadFile(*configFile)
+		if err != nil {
+			slog.Error("falha ao carregar arquivo de configuracao", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("arquivo de configuracao carregado", "arquivo", *configFile, "chaves", len(keys))
+	}
+	secrets, err := config.LoadSecretFiles()
+	if err != nil {
+		slog.Error("falha ao carregar secrets", "error", err)
+		os.Exit(1)
+	}
+	if len(secrets) > 0 {
+		slog.Info("secrets carregados de arquivo", "variaveis", secrets)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("falha ao carregar configuracao", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("configuracao efetiva", "config", cfg.Effective())
+
+	// Tracing (sem OTEL_EXPORTER_OTLP_ENDPOINT os spans sao descartados)
+	shutdownTracing, err := telemetry.Setup(context.Background(), "wega-catalog-api", cfg.OTLPEndpoint)
+	if err != nil {
+		slog.Error("falha ao configurar tracing", "error", err)
+		os.Exit(1)
+	}
 
 	// Conectar banco
 	slog.Info("conectando ao banco de dados", "host", cfg.Database.Host, "database", cfg.Database.Name)
@@ -39,58 +75,263 @@ func main() {
 	defer db.Close()
 	slog.Info("conexao com banco estabelecida")
 
-	// Repositorios
+	// Replica de leitura opcional para as consultas do catalogo; sem ela (ou
+	// fora do ar na inicializacao) as leituras usam o primario
+	var replica *pgxpool.Pool
+	if cfg.Database.ReplicaDSN != "" {
+		replica, err = database.NewReplicaPool(cfg.Database)
+		if err != nil {
+			slog.Warn("replica de leitura indisponivel, usando o primario", "error", err)
+		} else {
+			slog.Info("replica de leitura conectada")
+		}
+	}
+	readDB := database.NewReadPool(db, replica, cfg.Database.ReplicaMaxLag)
+	defer readDB.Close()
+
+	// Repositorios: leituras do catalogo na replica, escritas no primario
 	fabricanteRepo := repository.NewFabricanteRepo(db)
-	aplicacaoRepo := repository.NewAplicacaoRepo(db)
-	produtoRepo := repository.NewProdutoRepo(db)
-	referenciaRepo := repository.NewReferenciaRepo(db)
+	aplicacaoRepo := repository.NewAplicacaoRepo(readDB)
+	produtoRepo := repository.NewProdutoRepo(readDB)
+	referenciaRepo := repository.NewReferenciaRepo(readDB)
+	falhaRepo := repository.NewScraperFalhaRepo(db)
+	especificacaoRepo := repository.NewEspecificacaoRepository(db)
+	specConflitoRepo := repository.NewSpecConflitoRepo(db)
+	popularidadeRepo := repository.NewPopularidadeRepo(db)
+	quotaRepo := repository.NewQuotaRepo(db)
+	scraperRunRepo := repository.NewScraperRunRepo(db)
+	aliasRepo := repository.NewAliasRepo(db)
+	coberturaRepo := repository.NewCoberturaRepo(db)
+	completudeRepo := repository.NewCompletudeRepo(db)
+	exportRepo := repository.NewExportRepo(db)
+	autocompleteRepo := repository.NewAutocompleteRepo(readDB)
+	sincronizacaoRepo := repository.NewProdutoSincronizacaoRepo(db)
+	referenciaAdminRepo := repository.NewReferenciaAdminRepo(db)
+	auditLogRepo := repository.NewAuditLogRepo(db)
+
+	// Cache em memoria das listas lidas a cada carregamento do frontend
+	caches := map[string]service.Invalidavel{}
+	var fabricanteReader service.FabricanteReader = fabricanteRepo
+	var produtoReader service.ProdutoReader = produtoRepo
+	if cfg.CacheCatalogoTTL > 0 {
+		fabricanteCache := service.NewFabricanteCache(fabricanteRepo, cfg.CacheCatalogoTTL)
+		tipoFiltroCache := service.NewTipoFiltroCache(produtoRepo, cfg.CacheCatalogoTTL)
+		caches["fabricantes"], caches["tipos-filtro"] = fabricanteCache, tipoFiltroCache
+		fabricanteReader, produtoReader = fabricanteCache, tipoFiltroCache
+	}
+
+	// Busca semantica (pgvector) na frente do ILIKE, quando ha provedor de embeddings
+	var buscaSemantica *service.BuscaSemantica
+	var buscaVeiculos service.AplicacaoReader = aplicacaoRepo
+	if embedder := service.NewEmbedder(cfg.BuscaSemantica); embedder != nil {
+		buscaSemantica = service.NewBuscaSemantica(embedder, repository.NewAplicacaoEmbeddingRepo(db), cfg.BuscaSemantica)
+		buscaVeiculos = service.NewAplicacaoSemantica(aplicacaoRepo, buscaSemantica)
+	}
 
 	// Service
 	catalogoSvc := service.NewCatalogoService(
-		fabricanteRepo, aplicacaoRepo, produtoRepo, referenciaRepo,
+		fabricanteReader, buscaVeiculos, produtoReader, referenciaRepo, popularidadeRepo,
+	)
+	saudeSvc := service.NewSaudeService(db, aplicacaoRepo, especificacaoRepo, falhaRepo, cfg.Health)
+	completudeSvc := service.NewCompletudeService(completudeRepo, cfg.Completude)
+	especificacaoSvc := service.NewEspecificacaoService(especificacaoRepo, aplicacaoRepo, cfg.AoVivo)
+	recomendacaoSvc := service.NewRecomendacaoService(aplicacaoRepo, produtoRepo, especificacaoSvc, popularidadeRepo)
+	sincronizacaoSvc := service.NewSincronizacaoProdutos(
+		repository.NewSincronizacaoAuditada(sincronizacaoRepo, auditLogRepo), cfg.SincronizacaoERP,
 	)
+	var fotoProdutoSvc *service.FotoProdutoService
+	if cfg.FotoProduto.BaseURL != "" {
+		fotoProdutoSvc = service.NewFotoProdutoService(produtoRepo, cfg.FotoProduto)
+		caches["fotos-produto"] = fotoProdutoSvc
+	}
+	var placaSvc *service.PlacaService
+	if plateLookup := service.NewPlateLookup(cfg.Placa); plateLookup != nil {
+		placaSvc = service.NewPlacaService(plateLookup, catalogoSvc, especificacaoSvc, cfg.Placa)
+	}
 
 	// Handlers
 	healthHandler := handler.NewHealthHandler(db)
-	fabricanteHandler := handler.NewFabricanteHandler(fabricanteRepo)
-	filtroHandler := handler.NewFiltroHandler(catalogoSvc, produtoRepo)
+	systemHealthHandler := handler.NewSystemHealthHandler(saudeSvc)
+	fabricanteHandler := handler.NewFabricanteHandler(fabricanteReader)
+	filtroHandler := handler.NewFiltroHandler(catalogoSvc, produtoReader)
+	produtoHandler := handler.NewProdutoHandler(produtoRepo, fotoProdutoSvc, cfg.FotoProduto.MaxDimensao)
 	referenciaHandler := handler.NewReferenciaHandler(referenciaRepo)
+	referenciaAdminHandler := handler.NewReferenciaAdminHandler(repository.NewReferenciaAdminAuditada(referenciaAdminRepo, auditLogRepo))
+	falhaHandler := handler.NewFalhaHandler(repository.NewFalhaAuditada(falhaRepo, auditLogRepo))
+	especificacaoHandler := handler.NewEspecificacaoHandler(especificacaoRepo, especificacaoSvc, popularidadeRepo)
+	especificacaoAdminHandler := handler.NewEspecificacaoAdminHandler(repository.NewEspecificacaoAuditada(especificacaoRepo, auditLogRepo), scraperRunRepo)
+	specConflitoHandler := handler.NewSpecConflitoHandler(repository.NewSpecConflitoAuditado(specConflitoRepo, auditLogRepo))
+	popularidadeHandler := handler.NewPopularidadeHandler(popularidadeRepo)
+	quotaHandler := handler.NewQuotaHandler(repository.NewQuotaAuditada(quotaRepo, auditLogRepo))
+	scraperMetricsHandler := handler.NewScraperMetricsHandler(scraperRunRepo)
+	scraperRunHandler := handler.NewScraperRunHandler(scraperRunRepo, cfg.PrecosLLM)
+	aliasHandler := handler.NewAliasHandler(repository.NewAliasAuditado(aliasRepo, auditLogRepo))
+	coberturaHandler := handler.NewCoberturaHandler(coberturaRepo)
+	completudeHandler := handler.NewCompletudeHandler(completudeSvc)
+	recomendacaoHandler := handler.NewRecomendacaoHandler(recomendacaoSvc)
+	exportHandler := handler.NewExportHandler(exportRepo)
+	cacheHandler := handler.NewCacheHandler(caches)
+	autocompleteHandler := handler.NewAutocompleteHandler(autocompleteRepo)
+	placaHandler := handler.NewPlacaHandler(placaSvc)
+	sincronizacaoHandler := handler.NewSincronizacaoHandler(sincronizacaoSvc, sincronizacaoRepo)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogRepo)
+
+	// Jobs em background
+	jobs := service.NewJobRunner()
+	jobs.Add("reset_quota_usage", time.Minute, func(ctx context.Context) error {
+		reset, err := quotaRepo.ResetDailyUsage(ctx)
+		if reset > 0 {
+			slog.Info("uso diario das cotas zerado", "quotas", reset)
+		}
+		return err
+	})
+	if replica != nil {
+		jobs.Add("replica_health", cfg.Database.ReplicaCheckPeriod, readDB.Check)
+	}
+	if cfg.Completude.Intervalo > 0 {
+		// Verifica de hora em hora; so gera quando o ultimo relatorio venceu
+		jobs.Add("completude_report", min(time.Hour, cfg.Completude.Intervalo), completudeSvc.GerarSeVencido)
+	}
+	if buscaSemantica != nil {
+		jobs.Add("embeddings_aplicacoes", cfg.BuscaSemantica.Intervalo, buscaSemantica.Indexar)
+	}
+	if sincronizacaoSvc.ERPConfigurado() {
+		jobs.Add("sincronizar_produtos_erp", cfg.SincronizacaoERP.Intervalo, func(ctx context.Context) error {
+			_, err := sincronizacaoSvc.SincronizarERP(repository.ComAtor(ctx, "job:sincronizar_produtos_erp", ""))
+			return err
+		})
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs.Start(jobsCtx)
 
 	// Router
 	r := chi.NewRouter()
 
 	// Middlewares
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(handler.RealIP(cfg.TrustedProxies))
+	r.Use(handler.Tracing)
+	r.Use(handler.AccessLog(accessLogger(cfg.AccessLog), cfg.AccessLog.SampleRate))
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
-
-	// CORS middleware
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
 
-			next.ServeHTTP(w, r)
-		})
-	})
+	// CORS (CORS_ALLOWED_ORIGINS etc.)
+	r.Use(handler.CORS(cfg.CORS))
 
 	// Routes
-	r.Get("/health", healthHandler.Check)
+	r.With(middleware.Timeout(requestTimeout)).Get("/health", healthHandler.Check)
+	r.Get("/healthz", healthHandler.Liveness)
+	r.With(middleware.Timeout(requestTimeout)).Get("/readyz", healthHandler.Readiness)
+
+	// Diagnostico do runtime (pprof e expvar): sem timeout, para os profiles
+	if cfg.DebugEndpoints {
+		r.Route("/debug", func(r chi.Router) {
+			r.Use(handler.AdminAuth(cfg.AdminAPIKey))
+			r.Mount("/", middleware.Profiler())
+		})
+	}
+
+	// Limite por IP e global das rotas publicas, compartilhado pelos grupos
+	throttle := handler.Throttle(cfg.Throttle)
 
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Get("/fabricantes", fabricanteHandler.List)
-		r.Get("/tipos-filtro", filtroHandler.ListTipos)
-		r.Post("/filtros/buscar", filtroHandler.BuscarFiltros)
-		r.Get("/filtros/aplicacao/{id}", filtroHandler.PorAplicacao)
-		r.Get("/referencia-cruzada", referenciaHandler.Buscar)
+		// Publico, com cota por chave de API
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(requestTimeout))
+			r.Use(throttle)
+			r.Use(handler.RateLimit(quotaRepo, cfg.RequireAPIKey))
+
+			r.Get("/fabricantes", fabricanteHandler.List)
+			r.Get("/tipos-filtro", filtroHandler.ListTipos)
+			r.Get("/autocomplete", autocompleteHandler.Sugerir)
+			r.Post("/filtros/buscar", filtroHandler.BuscarFiltros)
+			r.Get("/filtros/aplicacao/{id}", filtroHandler.PorAplicacao)
+			r.Get("/referencia-cruzada", referenciaHandler.Buscar)
+			r.Get("/produtos/{codigo}/precos", produtoHandler.Precos)
+			r.Get("/especificacoes", especificacaoHandler.Buscar)
+			r.Get("/especificacoes/componentes", especificacaoHandler.Componentes)
+			r.Get("/especificacoes/aplicacao/{id}", especificacaoHandler.PorAplicacao)
+			r.Get("/veiculos/{id}/recomendacao-oleo", recomendacaoHandler.RecomendacaoOleo)
+			r.Get("/veiculos/{id}/kit-troca-oleo", recomendacaoHandler.KitTrocaOleo)
+			r.With(handler.LimitePorIP(cfg.Placa.LimitePorIP)).Get("/veiculos/placa/{placa}", placaHandler.BuscarPorPlaca)
+		})
+
+		// Fotos de produto: sem cota por chave, para funcionar direto em <img src>
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(requestTimeout))
+			r.Use(throttle)
+
+			r.Get("/produtos/{codigo}/foto", produtoHandler.Foto)
+		})
+
+		// Export do catalogo: publico com cota, mas com prazo proprio
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(cfg.ExportTimeout))
+			r.Use(throttle)
+			r.Use(handler.RateLimit(quotaRepo, cfg.RequireAPIKey))
+
+			r.Get("/export/{dataset}", exportHandler.Exportar)
+		})
+
+		// Admin
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(handler.AdminAuth(cfg.AdminAPIKey))
+			r.Use(handler.AtorAuditoria)
+
+			// Export NDJSON: prazo proprio, como o export publico
+			r.With(middleware.Timeout(cfg.ExportTimeout)).Get("/export/aplicacoes", exportHandler.AplicacoesNDJSON)
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.Timeout(requestTimeout))
+
+				r.Get("/falhas", falhaHandler.List)
+				r.Delete("/falhas", falhaHandler.DeleteResolved)
+				r.Post("/falhas/{id}/retry", falhaHandler.Retry)
+				r.Delete("/falhas/{id}", falhaHandler.Delete)
+
+				r.Delete("/especificacoes", especificacaoAdminHandler.Desativar)
+				r.Post("/especificacoes/reativar", especificacaoAdminHandler.Reativar)
+				r.Get("/especificacoes/conflitos", specConflitoHandler.List)
+				r.Delete("/especificacoes/conflitos/{id}", specConflitoHandler.Delete)
+
+				r.Get("/popularidade", popularidadeHandler.List)
+				r.Get("/cobertura", coberturaHandler.Relatorio)
+				r.Get("/completude", completudeHandler.Ultimo)
+				r.Post("/completude", completudeHandler.Gerar)
+
+				r.Get("/system-health", systemHealthHandler.Check)
+
+				r.Get("/quotas", quotaHandler.List)
+				r.Post("/quotas", quotaHandler.Create)
+				r.Get("/quotas/{id}", quotaHandler.Get)
+				r.Put("/quotas/{id}", quotaHandler.Update)
+				r.Delete("/quotas/{id}", quotaHandler.Delete)
+
+				r.Get("/metrics/scraper-runs", scraperMetricsHandler.Runs)
+				r.Get("/scraper/runs", scraperRunHandler.List)
+				r.Get("/scraper/runs/{id}", scraperRunHandler.Get)
+				r.Post("/scraper/runs/{id}/rollback", especificacaoAdminHandler.Rollback)
+				r.Get("/scraper/llm-uso", scraperRunHandler.ConsumoLLM)
+
+				r.Get("/aliases", aliasHandler.Export)
+				r.Post("/aliases", aliasHandler.Import)
+
+				r.Post("/produtos/sincronizar", sincronizacaoHandler.Sincronizar)
+				r.Post("/produtos/sincronizar/erp", sincronizacaoHandler.SincronizarERP)
+				r.Get("/produtos/sincronizacoes", sincronizacaoHandler.List)
+				r.Get("/produtos/sincronizacoes/{id}", sincronizacaoHandler.Get)
+
+				r.Get("/referencias", referenciaAdminHandler.List)
+				r.Post("/referencias", referenciaAdminHandler.Create)
+				r.Get("/referencias/auditoria", referenciaAdminHandler.Auditoria)
+				r.Put("/referencias/{id}", referenciaAdminHandler.Update)
+				r.Delete("/referencias/{id}", referenciaAdminHandler.Delete)
+
+				r.Get("/audit-log", auditLogHandler.List)
+
+				r.Delete("/cache", cacheHandler.Invalidar)
+				r.Delete("/cache/{nome}", cacheHandler.Invalidar)
+			})
+		})
 	})
 
 	// Server
@@ -98,17 +339,41 @@ func main() {
 		Addr:         ":" + cfg.APIPort,
 		Handler:      r,
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		WriteTimeout: requestTimeout, // O export estende o prazo da propria resposta
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// TLS opcional (certificado em arquivo ou autocert) e redirecionamento HTTP -> HTTPS
+	var redirectSrv *http.Server
+	if cfg.TLS.Enabled() {
+		redirectSrv, err = configurarTLS(srv, cfg.TLS, cfg.APIPort)
+		if err != nil {
+			slog.Error("falha ao configurar TLS", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Graceful shutdown
 	go func() {
-		slog.Info("servidor iniciado", "port", cfg.APIPort)
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		slog.Info("servidor iniciado", "port", cfg.APIPort, "tls", cfg.TLS.Enabled())
+		var err error
+		if cfg.TLS.Enabled() {
+			err = srv.ListenAndServeTLS("", "") // Certificados ja estao em srv.TLSConfig
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
 			slog.Error("erro no servidor", "error", err)
 		}
 	}()
+	if redirectSrv != nil {
+		go func() {
+			slog.Info("redirecionamento HTTP -> HTTPS iniciado", "port", cfg.TLS.RedirectPort)
+			if err := redirectSrv.ListenAndServe(); err != http.ErrServerClosed {
+				slog.Error("erro no redirecionamento HTTP", "error", err)
+			}
+		}()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -121,6 +386,24 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("erro ao encerrar servidor", "error", err)
 	}
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(ctx)
+	}
+
+	stopJobs()
+	jobs.Wait()
+
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("erro ao enviar os ultimos spans", "error", err)
+	}
 
 	slog.Info("servidor encerrado")
 }
+
+// accessLogger cria o logger do log de acesso no formato configurado (json ou text, ja validado)
+func accessLogger(cfg config.AccessLogConfig) *slog.Logger {
+	if cfg.Format == "text" {
+		return slog.New(slog.NewTextHandler(os.Stdout, nil))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, nil))
+}
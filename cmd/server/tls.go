@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+
+	"wega-catalog-api/internal/config"
+)
+
+// configurarTLS prepara o srv para HTTPS, com certificado em arquivo ou
+// emitido pelo autocert, e retorna o servidor HTTP que redireciona para
+// HTTPS quando TLS_REDIRECT_PORT esta definido (nil caso contrario).
+// O HTTP/2 e negociado pelo proprio net/http via ALPN.
+func configurarTLS(srv *http.Server, cfg config.TLSConfig, apiPort string) (*http.Server, error) {
+	redirecionar := redirecionarHTTPS(apiPort)
+
+	if len(cfg.AutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		srv.TLSConfig = manager.TLSConfig() // Inclui h2 e o desafio TLS-ALPN-01
+		redirecionar = manager.HTTPHandler(redirecionar)
+	} else {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao carregar certificado TLS: %w", err)
+		}
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	srv.TLSConfig.MinVersion = tls.VersionTLS12
+
+	if cfg.RedirectPort == "" {
+		return nil, nil
+	}
+	return &http.Server{
+		Addr:         ":" + cfg.RedirectPort,
+		Handler:      redirecionar,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+		IdleTimeout:  30 * time.Second,
+	}, nil
+}
+
+// redirecionarHTTPS responde 308 para a mesma URL em https, na porta da API
+func redirecionarHTTPS(apiPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if apiPort != "443" {
+			host = net.JoinHostPort(host, apiPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRetryAfter caps how long a single Retry-After header can make us wait
+	maxRetryAfter = 5 * time.Minute
+
+	// throttleCooldown keeps a burst of concurrent 429s from halving the rate repeatedly
+	throttleCooldown = 5 * time.Second
+
+	// recoverAfter is the number of consecutive successes before the rate is raised again
+	recoverAfter = 20
+)
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return min(time.Duration(seconds)*time.Second, maxRetryAfter), true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return min(max(at.Sub(now), 0), maxRetryAfter), true
+	}
+	return 0, false
+}
+
+// AdaptiveThrottle drives a RateLimiter from 429 telemetry: each rate-limit
+// response halves the rate (down to a floor), and every recoverAfter
+// consecutive successes raise it by 10% until the configured rate is reached.
+type AdaptiveThrottle struct {
+	limiter  *RateLimiter
+	baseRate float64
+	minRate  float64 // Floor in use, never above baseRate
+	floor    float64 // Floor as configured, so minRate can follow baseRate back up
+
+	mu           sync.Mutex
+	lastSlowdown time.Time
+	successes    int
+}
+
+// NewAdaptiveThrottle creates a throttle over limiter; its current rate is the ceiling
+func NewAdaptiveThrottle(limiter *RateLimiter, minRate float64) *AdaptiveThrottle {
+	baseRate := limiter.Rate()
+	return &AdaptiveThrottle{
+		limiter:  limiter,
+		baseRate: baseRate,
+		minRate:  min(minRate, baseRate),
+		floor:    minRate,
+	}
+}
+
+// OnThrottled lowers the rate after a 429. When the server sent Retry-After,
+// the rate is also capped so requests are spaced at least that far apart,
+// even if that goes below the floor.
+func (t *AdaptiveThrottle) OnThrottled(retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.successes = 0
+	now := time.Now()
+	if now.Sub(t.lastSlowdown) < throttleCooldown {
+		return
+	}
+	t.lastSlowdown = now
+
+	rate := max(t.limiter.Rate()/2, t.minRate)
+	if retryAfter > 0 {
+		rate = min(rate, 1/retryAfter.Seconds())
+	}
+	t.limiter.SetRate(rate)
+}
+
+// OnSuccess counts a successful request and gradually restores the rate
+func (t *AdaptiveThrottle) OnSuccess() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.successes++
+	if t.successes < recoverAfter {
+		return
+	}
+	t.successes = 0
+
+	if rate := t.limiter.Rate(); rate < t.baseRate {
+		t.limiter.SetRate(min(rate*1.1, t.baseRate))
+	}
+}
+
+// SetBaseRate changes the ceiling (e.g. after SetRateLimit) and resets to it
+func (t *AdaptiveThrottle) SetBaseRate(rate float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.baseRate = rate
+	t.minRate = min(t.floor, rate)
+	t.limiter.SetRate(rate)
+}
+
+// EffectiveRate returns the current requests per second
+func (t *AdaptiveThrottle) EffectiveRate() float64 {
+	return t.limiter.Rate()
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ChainProvider is a named LLM client in a ChainClient
+type ChainProvider struct {
+	Name   string
+	Client LLMClient
+}
+
+// DailyLimited is implemented by clients with daily quotas that can fail fast
+// with ErrAllKeysExhaustedDaily instead of waiting for the reset
+type DailyLimited interface {
+	SetWaitForDailyReset(wait bool)
+}
+
+// Ensure quota-limited clients implement DailyLimited
+var _ DailyLimited = (*GroqClient)(nil)
+var _ DailyLimited = (*GeminiClient)(nil)
+
+// ChainClient is a composite LLMClient that tries providers in order
+// (e.g. groq → gemini → ollama). A provider whose keys are daily-exhausted is
+// skipped until the next midnight UTC; any other error falls through to the
+// next provider for that call only.
+type ChainClient struct {
+	providers []ChainProvider
+	logger    *slog.Logger
+
+	mu            sync.Mutex
+	disabledUntil []time.Time // Per-provider skip deadline after daily exhaustion
+}
+
+// NewChainClient creates a failover chain over the given providers
+func NewChainClient(providers []ChainProvider, logger *slog.Logger) *ChainClient {
+	if len(providers) == 0 {
+		panic("at least one LLM provider is required")
+	}
+
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name
+	}
+	logger.Info("LLM failover chain initialized", "providers", names)
+
+	return &ChainClient{
+		providers:     providers,
+		logger:        logger,
+		disabledUntil: make([]time.Time, len(providers)),
+	}
+}
+
+// SetObserver forwards the observer to every provider that reports request outcomes
+func (c *ChainClient) SetObserver(observer RequestObserver) {
+	for _, p := range c.providers {
+		if observable, ok := p.Client.(Observable); ok {
+			observable.SetObserver(observer)
+		}
+	}
+}
+
+// NormalizeVehicle implements LLMClient
+func (c *ChainClient) NormalizeVehicle(ctx context.Context, vehicle string, options []string) (string, error) {
+	return c.try(ctx, func(llm LLMClient) (string, error) {
+		return llm.NormalizeVehicle(ctx, vehicle, options)
+	})
+}
+
+// NormalizeVehicleConfidence implements ConfidenceNormalizer; providers
+// that don't report confidence answer with 0
+func (c *ChainClient) NormalizeVehicleConfidence(ctx context.Context, vehicle string, options []string) (string, float64, error) {
+	var confidence float64
+	match, err := c.try(ctx, func(llm LLMClient) (string, error) {
+		confidence = 0
+		if cn, ok := llm.(ConfidenceNormalizer); ok {
+			var match string
+			var err error
+			match, confidence, err = cn.NormalizeVehicleConfidence(ctx, vehicle, options)
+			return match, err
+		}
+		return llm.NormalizeVehicle(ctx, vehicle, options)
+	})
+	return match, confidence, err
+}
+
+// FindBestBrand implements LLMClient
+func (c *ChainClient) FindBestBrand(ctx context.Context, brand string, options []string) (string, error) {
+	return c.try(ctx, func(llm LLMClient) (string, error) {
+		return llm.FindBestBrand(ctx, brand, options)
+	})
+}
+
+// FindBestModel implements LLMClient
+func (c *ChainClient) FindBestModel(ctx context.Context, model string, options []string) (string, error) {
+	return c.try(ctx, func(llm LLMClient) (string, error) {
+		return llm.FindBestModel(ctx, model, options)
+	})
+}
+
+// try calls fn on each available provider until one succeeds
+func (c *ChainClient) try(ctx context.Context, fn func(LLMClient) (string, error)) (string, error) {
+	var lastErr error
+
+	for i, p := range c.providers {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		c.mu.Lock()
+		disabledUntil := c.disabledUntil[i]
+		c.mu.Unlock()
+		if time.Now().Before(disabledUntil) {
+			continue
+		}
+
+		result, err := fn(p.Client)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		// "None of the options" is an answer, not a provider failure
+		if errors.Is(err, ErrNoMatch) {
+			return "", err
+		}
+
+		lastErr = fmt.Errorf("%s: %w", p.Name, err)
+
+		if errors.Is(err, ErrAllKeysExhaustedDaily) {
+			now := time.Now().UTC()
+			until := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+			c.mu.Lock()
+			c.disabledUntil[i] = until
+			c.mu.Unlock()
+
+			c.logger.Warn("LLM provider daily-exhausted, falling through",
+				"provider", p.Name,
+				"disabled_until", until,
+			)
+			continue
+		}
+
+		c.logger.Warn("LLM provider failed, trying next",
+			"provider", p.Name,
+			"error", err,
+		)
+	}
+
+	if lastErr == nil {
+		return "", fmt.Errorf("all LLM providers are disabled until their daily reset")
+	}
+	return "", lastErr
+}
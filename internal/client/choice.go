@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatch is returned when the LLM answers that none of the options match
+var ErrNoMatch = errors.New("LLM indicated no match")
+
+// fallbackConfidence is reported for a smart fallback pick after an unreadable reply
+const fallbackConfidence = 0.5
+
+// ConfidenceNormalizer is implemented by LLM clients that report how sure
+// the model is of its pick (0.0-1.0; 0 = not reported)
+type ConfidenceNormalizer interface {
+	NormalizeVehicleConfidence(ctx context.Context, vehicle string, options []string) (string, float64, error)
+}
+
+// Ensure structured-output clients implement ConfidenceNormalizer
+var _ ConfidenceNormalizer = (*GroqClient)(nil)
+var _ ConfidenceNormalizer = (*OllamaClient)(nil)
+var _ ConfidenceNormalizer = (*ChainClient)(nil)
+
+// choiceFormat is the reply format asked for in single-vehicle prompts
+const choiceFormat = `{"choice": <option number, 0 if none matches>, "confidence": <0.0-1.0>}`
+
+// choiceSchema is the JSON schema of a single-vehicle reply, for providers
+// that constrain generation to a schema
+func choiceSchema(options int) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"choice":     map[string]any{"type": "integer", "minimum": 0, "maximum": options},
+			"confidence": map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+		},
+		"required": []string{"choice", "confidence"},
+	}
+}
+
+// numberedOptions lists options as "1. a\n2. b\n..."
+func numberedOptions(options []string) string {
+	var sb strings.Builder
+	for i, opt := range options {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, opt)
+	}
+	return sb.String()
+}
+
+// llmChoice is one pick read from a model reply
+type llmChoice struct {
+	Choice     int     // 1-based option number, 0 = no match
+	Confidence float64 // 0.0-1.0, 0 = not reported
+}
+
+var firstNumber = regexp.MustCompile(`\d+`)
+
+// parseChoice reads {"choice": n, "confidence": x} from a model reply and
+// checks n against the number of options. Replies that bend the format are
+// still understood: JSON wrapped in prose or code fences, numbers sent as
+// strings, a confidence given as a percentage, or a bare number.
+func parseChoice(reply string, options int) (llmChoice, error) {
+	var choice llmChoice
+	if obj, ok := jsonObject(reply); ok {
+		var raw struct {
+			Choice     any `json:"choice"`
+			Confidence any `json:"confidence"`
+		}
+		if err := json.Unmarshal([]byte(obj), &raw); err != nil {
+			return choice, fmt.Errorf("invalid JSON reply %q: %w", reply, err)
+		}
+		n, ok := jsonNumber(raw.Choice)
+		if !ok || n != float64(int(n)) {
+			return choice, fmt.Errorf("reply has no valid choice: %q", reply)
+		}
+		choice.Choice = int(n)
+		choice.Confidence, _ = jsonNumber(raw.Confidence)
+	} else {
+		n := firstNumber.FindString(reply)
+		if n == "" {
+			return choice, fmt.Errorf("reply is not a choice: %q", reply)
+		}
+		choice.Choice, _ = strconv.Atoi(n)
+	}
+
+	if choice.Choice < 0 || choice.Choice > options {
+		return choice, fmt.Errorf("choice %d out of range 1-%d", choice.Choice, options)
+	}
+	choice.Confidence = normalizeConfidence(choice.Confidence)
+	return choice, nil
+}
+
+// jsonObject returns the outermost {...} of a reply
+func jsonObject(reply string) (string, bool) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return "", false
+	}
+	return reply[start : end+1], true
+}
+
+// jsonNumber accepts a JSON number or a numeric string
+func jsonNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// normalizeConfidence maps percentages (1-100) to 0.0-1.0 and clamps the rest
+func normalizeConfidence(c float64) float64 {
+	if c > 1 && c <= 100 {
+		c /= 100
+	}
+	return min(max(c, 0), 1)
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	openAIEmbeddingsURL         = "https://api.openai.com/v1/embeddings"
+	defaultOllamaEmbeddingModel = "nomic-embed-text"
+	defaultOpenAIEmbeddingModel = "text-embedding-3-small"
+)
+
+// Embedder computes vector embeddings for texts
+// Both OllamaEmbedder and OpenAIEmbedder implement this interface
+type Embedder interface {
+	// Embed returns one vector per input text, in the same order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// Model returns the embedding model name (used to invalidate cached vectors)
+	Model() string
+}
+
+// OllamaEmbedder computes embeddings with a local Ollama server (/api/embed)
+type OllamaEmbedder struct {
+	httpClient *http.Client
+	httpConfig HTTPConfig
+	baseURL    string
+	model      string
+}
+
+// NewOllamaEmbedder creates an Ollama embeddings client
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	if model == "" {
+		model = defaultOllamaEmbeddingModel
+	}
+	return &OllamaEmbedder{
+		httpClient: &http.Client{},
+		httpConfig: HTTPConfig{Timeout: 60 * time.Second, Retry: DefaultRetryConfig(2)},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+	}
+}
+
+// Model implements Embedder
+func (e *OllamaEmbedder) Model() string {
+	return "ollama/" + e.model
+}
+
+// Embed implements Embedder
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := doJSONWithRetry(ctx, e.httpClient, e.httpConfig, e.baseURL+"/api/embed", reqBody, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama embeddings error (status %d): %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var parsed struct {
+		Embeddings [][]float32 `json:"embeddings"`
+		Error      string      `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(resp.Body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if parsed.Error != "" {
+		return nil, fmt.Errorf("Ollama embeddings error: %s", parsed.Error)
+	}
+	if len(parsed.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(parsed.Embeddings))
+	}
+
+	return parsed.Embeddings, nil
+}
+
+// OpenAIEmbedder computes embeddings with the OpenAI embeddings API
+type OpenAIEmbedder struct {
+	httpClient *http.Client
+	httpConfig HTTPConfig
+	apiKey     string
+	model      string
+}
+
+// NewOpenAIEmbedder creates an OpenAI embeddings client
+func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
+	if model == "" {
+		model = defaultOpenAIEmbeddingModel
+	}
+	return &OpenAIEmbedder{
+		httpClient: &http.Client{},
+		httpConfig: HTTPConfig{Timeout: 30 * time.Second, Retry: DefaultRetryConfig(2)},
+		apiKey:     apiKey,
+		model:      model,
+	}
+}
+
+// Model implements Embedder
+func (e *OpenAIEmbedder) Model() string {
+	return "openai/" + e.model
+}
+
+// Embed implements Embedder
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody, err := json.Marshal(map[string]any{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := doJSONWithRetry(ctx, e.httpClient, e.httpConfig, openAIEmbeddingsURL, reqBody,
+		map[string]string{"Authorization": "Bearer " + e.apiKey}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenAI embeddings error (status %d): %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var parsed struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(parsed.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// Ensure both embedders implement Embedder
+var _ Embedder = (*OllamaEmbedder)(nil)
+var _ Embedder = (*OpenAIEmbedder)(nil)
@@ -0,0 +1,498 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	_ "time/tzdata" // America/Los_Angeles for nextGeminiQuotaReset
+
+	"wega-catalog-api/internal/normalize"
+	"wega-catalog-api/internal/telemetry"
+)
+
+const (
+	geminiAPIBase      = "https://generativelanguage.googleapis.com/v1beta/models"
+	defaultGeminiModel = "gemini-2.0-flash"
+)
+
+// ErrGeminiKeysExhausted is returned when every Gemini key is rate limited
+var ErrGeminiKeysExhausted = fmt.Errorf("all Gemini API keys exhausted")
+
+// GeminiClient handles communication with Google Gemini API for LLM normalization
+// Supports multiple API keys with automatic failover on rate limit (429)
+// and daily quota exhaustion. Gemini daily quotas reset at midnight Pacific time.
+type GeminiClient struct {
+	httpClient  *http.Client
+	httpConfig  HTTPConfig
+	apiKeys     []string
+	model       string
+	currentKey  atomic.Int32
+	keyMutex    sync.RWMutex
+	keyStatus   []keyStatus
+	rateLimiter *RateLimiter
+	logger      *slog.Logger
+	observer    RequestObserver
+
+	// Daily quota tracking
+	allExhaustedUntil time.Time
+	noDailyWait       bool // Return ErrAllKeysExhaustedDaily instead of waiting
+}
+
+// GeminiRequest represents a generateContent request
+type GeminiRequest struct {
+	Contents         []GeminiContent        `json:"contents"`
+	GenerationConfig GeminiGenerationConfig `json:"generationConfig"`
+}
+
+// GeminiContent represents a message in a Gemini conversation
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiPart represents a part of a Gemini message
+type GeminiPart struct {
+	Text string `json:"text"`
+}
+
+// GeminiGenerationConfig represents generation options
+type GeminiGenerationConfig struct {
+	Temperature     float64 `json:"temperature"`
+	MaxOutputTokens int     `json:"maxOutputTokens"`
+}
+
+// GeminiResponse represents a generateContent response
+type GeminiResponse struct {
+	Candidates []struct {
+		Content      GeminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+// NewGeminiClient creates a new Gemini API client with one or more keys for failover
+func NewGeminiClient(apiKeys []string, model string, requestsPerMinute float64, logger *slog.Logger) *GeminiClient {
+	if len(apiKeys) == 0 {
+		panic("at least one API key is required")
+	}
+	if model == "" {
+		model = defaultGeminiModel
+	}
+
+	client := &GeminiClient{
+		httpClient:  telemetry.NewHTTPClient(0),
+		httpConfig:  HTTPConfig{Timeout: 30 * time.Second, Retry: DefaultRetryConfig(0)},
+		apiKeys:     apiKeys,
+		model:       model,
+		keyStatus:   make([]keyStatus, len(apiKeys)),
+		rateLimiter: NewRateLimiter(requestsPerMinute / 60.0), // Convert to per-second
+		logger:      logger,
+	}
+
+	logger.Info("Gemini client initialized",
+		"keys_count", len(apiKeys),
+		"model", model,
+		"rpm", requestsPerMinute,
+	)
+
+	return client
+}
+
+// SetHTTPConfig sets the per-request timeout and retry behavior for transport errors and 5xx
+func (c *GeminiClient) SetHTTPConfig(cfg HTTPConfig) {
+	c.httpConfig = cfg
+}
+
+// SetWaitForDailyReset controls whether to wait for the daily quota reset (default)
+// or fail fast with ErrAllKeysExhaustedDaily once every key is exhausted
+func (c *GeminiClient) SetWaitForDailyReset(wait bool) {
+	c.noDailyWait = !wait
+}
+
+// SetObserver sets the observer notified about rate limits and network errors
+func (c *GeminiClient) SetObserver(observer RequestObserver) {
+	c.observer = observer
+}
+
+// GetKeyCount returns the number of API keys configured
+func (c *GeminiClient) GetKeyCount() int {
+	return len(c.apiKeys)
+}
+
+// NormalizeVehicle uses Gemini to find the best match from Motul options
+func (c *GeminiClient) NormalizeVehicle(ctx context.Context, wegaVehicle string, motulOptions []string) (string, error) {
+	if len(motulOptions) == 0 {
+		return "", fmt.Errorf("no Motul options provided")
+	}
+
+	// If only one option, return it directly (no LLM needed)
+	if len(motulOptions) == 1 {
+		return motulOptions[0], nil
+	}
+
+	optionsList := ""
+	for i, opt := range motulOptions {
+		optionsList += fmt.Sprintf("%d.%s ", i+1, opt)
+	}
+
+	// Same Q&A prompt as Groq: forces a single-number answer
+	prompt := fmt.Sprintf(`Q: Which option best matches "%s"?
+IMPORTANT: If vehicle has NO turbo keywords (Turbo/TSI/T200/THP/130cv), choose NON-turbo option.
+Reply with ONLY the option number.
+%s
+A:`, wegaVehicle, strings.TrimSpace(optionsList))
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return "", fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	response, err := c.doRequestWithFailover(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	response = strings.TrimSpace(response)
+
+	var optionNum int
+	for _, char := range response {
+		if char >= '1' && char <= '9' {
+			optionNum = int(char - '0')
+			break
+		}
+	}
+
+	if optionNum == 0 || optionNum > len(motulOptions) {
+		c.logger.Warn("invalid Gemini response, using smart fallback",
+			"response", response,
+			"wega_vehicle", wegaVehicle,
+			"total_options", len(motulOptions),
+		)
+		return c.smartFallback(wegaVehicle, motulOptions), nil
+	}
+
+	return motulOptions[optionNum-1], nil
+}
+
+// smartFallback selects the best option based on turbo/aspirated engine detection
+func (c *GeminiClient) smartFallback(wegaVehicle string, motulOptions []string) string {
+	wegaLower := strings.ToLower(wegaVehicle)
+
+	turboKeywords := []string{"turbo", "tsi", "tfsi", "t200", "thp", "130cv", "130 cv", "125cv", "125 cv"}
+	wegaIsTurbo := false
+	for _, kw := range turboKeywords {
+		if strings.Contains(wegaLower, kw) {
+			wegaIsTurbo = true
+			break
+		}
+	}
+
+	for _, opt := range motulOptions {
+		optLower := strings.ToLower(opt)
+		optIsTurbo := false
+		for _, kw := range turboKeywords {
+			if strings.Contains(optLower, kw) {
+				optIsTurbo = true
+				break
+			}
+		}
+
+		if wegaIsTurbo == optIsTurbo {
+			return opt
+		}
+	}
+
+	return motulOptions[0]
+}
+
+// FindBestBrand finds the best matching brand from available options
+func (c *GeminiClient) FindBestBrand(ctx context.Context, wegaBrand string, motulBrands []string) (string, error) {
+	if len(motulBrands) == 0 {
+		return "", fmt.Errorf("no Motul brands provided")
+	}
+
+	for _, brand := range motulBrands {
+		if normalize.Text.Equal(brand, wegaBrand) {
+			return brand, nil
+		}
+	}
+
+	return c.NormalizeVehicle(ctx, wegaBrand, motulBrands)
+}
+
+// FindBestModel finds the best matching model from available options
+func (c *GeminiClient) FindBestModel(ctx context.Context, wegaModel string, motulModels []string) (string, error) {
+	if len(motulModels) == 0 {
+		return "", fmt.Errorf("no Motul models provided")
+	}
+
+	for _, model := range motulModels {
+		if normalize.Text.Equal(model, wegaModel) {
+			return model, nil
+		}
+	}
+
+	return c.NormalizeVehicle(ctx, wegaModel, motulModels)
+}
+
+// doRequestWithFailover sends the prompt, rotating keys on 429 and waiting for
+// the daily reset once every key is exhausted
+func (c *GeminiClient) doRequestWithFailover(ctx context.Context, prompt string) (string, error) {
+	reqBody, err := json.Marshal(GeminiRequest{
+		Contents: []GeminiContent{
+			{Role: "user", Parts: []GeminiPart{{Text: prompt}}},
+		},
+		GenerationConfig: GeminiGenerationConfig{
+			Temperature:     0.0, // Deterministic output
+			MaxOutputTokens: 5,   // Just a number
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s:generateContent", geminiAPIBase, c.model)
+
+	for {
+		if err := c.waitForReset(ctx); err != nil {
+			return "", err
+		}
+
+		triedKeys := 0
+		for triedKeys < len(c.apiKeys) {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+
+			keyIdx := int(c.currentKey.Load()) % len(c.apiKeys)
+
+			c.keyMutex.Lock()
+			isDailyExhausted := c.dailyExhausted(keyIdx, time.Now())
+			c.keyMutex.Unlock()
+
+			if isDailyExhausted {
+				triedKeys++
+				c.currentKey.Store(int32((keyIdx + 1) % len(c.apiKeys)))
+				continue
+			}
+
+			resp, err := doJSONWithRetry(ctx, c.httpClient, c.httpConfig, url, reqBody,
+				map[string]string{"x-goog-api-key": c.apiKeys[keyIdx]},
+				func(err error) {
+					if c.observer != nil {
+						c.observer.OnNetworkError(ServiceGemini, err)
+					}
+				},
+			)
+			if err != nil {
+				c.logger.Error("HTTP request failed", "error", err)
+				return "", err
+			}
+
+			if resp.StatusCode == http.StatusTooManyRequests {
+				if c.observer != nil {
+					c.observer.OnRateLimited(ServiceGemini)
+				}
+				isDailyLimit := isGeminiDailyQuotaError(resp.Body)
+
+				c.logger.Warn("Gemini rate limit hit, rotating key",
+					"key_idx", keyIdx,
+					"is_daily_limit", isDailyLimit,
+				)
+
+				if c.rotateKey(keyIdx, isDailyLimit) {
+					triedKeys++
+					continue
+				}
+
+				c.keyMutex.RLock()
+				allExhaustedUntil := c.allExhaustedUntil
+				c.keyMutex.RUnlock()
+
+				if !allExhaustedUntil.IsZero() {
+					break // Wait for the daily reset
+				}
+				return "", fmt.Errorf("%w: %s", ErrGeminiKeysExhausted, string(resp.Body))
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				return "", fmt.Errorf("Gemini API error (status %d): %s", resp.StatusCode, string(resp.Body))
+			}
+
+			var geminiResp GeminiResponse
+			if err := json.Unmarshal(resp.Body, &geminiResp); err != nil {
+				return "", fmt.Errorf("failed to parse response: %w", err)
+			}
+
+			if geminiResp.Error != nil {
+				return "", fmt.Errorf("Gemini API error: %s", geminiResp.Error.Message)
+			}
+
+			if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+				return "", fmt.Errorf("no candidates in response")
+			}
+
+			c.markKeySuccess(keyIdx)
+
+			c.logger.Debug("Gemini API request successful",
+				"key_idx", keyIdx,
+				"tokens_used", geminiResp.UsageMetadata.TotalTokenCount,
+			)
+			reportTokens(c.observer, TokenUsage{
+				Service:          ServiceGemini,
+				Key:              KeyLabel(c.apiKeys[keyIdx]),
+				PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
+				CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+			})
+
+			return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+		}
+
+		c.keyMutex.RLock()
+		allExhaustedUntil := c.allExhaustedUntil
+		c.keyMutex.RUnlock()
+
+		if allExhaustedUntil.IsZero() {
+			return "", ErrGeminiKeysExhausted
+		}
+	}
+}
+
+// isGeminiDailyQuotaError checks whether a 429 body refers to a per-day quota
+// (e.g. "GenerateRequestsPerDayPerProjectPerModel-FreeTier")
+func isGeminiDailyQuotaError(body []byte) bool {
+	bodyStr := strings.ToLower(string(body))
+	return strings.Contains(bodyStr, "perday") || strings.Contains(bodyStr, "per day")
+}
+
+// rotateKey switches to the next available API key
+// Returns true if a non-exhausted key was found
+func (c *GeminiClient) rotateKey(failedIdx int, isDailyLimit bool) bool {
+	c.keyMutex.Lock()
+	defer c.keyMutex.Unlock()
+
+	now := time.Now()
+	if isDailyLimit {
+		c.keyStatus[failedIdx].dailyExhausted = true
+		c.keyStatus[failedIdx].dailyExhaustedAt = now
+	} else {
+		c.keyStatus[failedIdx].rateLimited = true
+		c.keyStatus[failedIdx].rateLimitedAt = now
+	}
+
+	startIdx := (failedIdx + 1) % len(c.apiKeys)
+	for i := 0; i < len(c.apiKeys); i++ {
+		idx := (startIdx + i) % len(c.apiKeys)
+		status := &c.keyStatus[idx]
+
+		if c.dailyExhausted(idx, now) {
+			continue
+		}
+
+		// Per-minute limits recover after 1 minute
+		if status.rateLimited && time.Since(status.rateLimitedAt) > time.Minute {
+			status.rateLimited = false
+			status.errorCount = 0
+		}
+
+		if !status.rateLimited {
+			c.currentKey.Store(int32(idx))
+			return true
+		}
+	}
+
+	for _, status := range c.keyStatus {
+		if !status.dailyExhausted {
+			return false
+		}
+	}
+
+	c.allExhaustedUntil = nextGeminiQuotaReset(now)
+	c.logger.Warn("all Gemini API keys daily quota exhausted",
+		"total_keys", len(c.apiKeys),
+		"resume_at", c.allExhaustedUntil,
+	)
+	reportKeysExhausted(c.observer, ServiceGemini, c.allExhaustedUntil)
+	return false
+}
+
+// dailyExhausted reports whether key idx is still out of daily quota, clearing
+// the mark once the Pacific midnight after it was set has passed (caller
+// holds keyMutex)
+func (c *GeminiClient) dailyExhausted(idx int, now time.Time) bool {
+	status := &c.keyStatus[idx]
+	if status.dailyExhausted && !now.Before(nextGeminiQuotaReset(status.dailyExhaustedAt)) {
+		status.dailyExhausted = false
+		status.dailyExhaustedAt = time.Time{}
+	}
+	return status.dailyExhausted
+}
+
+// markKeySuccess marks a key as healthy
+func (c *GeminiClient) markKeySuccess(idx int) {
+	c.keyMutex.Lock()
+	defer c.keyMutex.Unlock()
+	c.keyStatus[idx].errorCount = 0
+	c.keyStatus[idx].rateLimited = false
+}
+
+// waitForReset blocks until the daily quota reset when all keys are exhausted,
+// then clears the exhaustion state
+func (c *GeminiClient) waitForReset(ctx context.Context) error {
+	c.keyMutex.RLock()
+	exhaustedUntil := c.allExhaustedUntil
+	c.keyMutex.RUnlock()
+
+	if exhaustedUntil.IsZero() {
+		return nil
+	}
+
+	if wait := time.Until(exhaustedUntil); wait > 0 {
+		if c.noDailyWait {
+			return fmt.Errorf("gemini: %w", ErrAllKeysExhaustedDaily)
+		}
+
+		c.logger.Info("waiting for Gemini daily quota reset",
+			"resume_at", exhaustedUntil,
+			"wait_duration", wait,
+		)
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	c.keyMutex.Lock()
+	for i := range c.keyStatus {
+		c.keyStatus[i] = keyStatus{}
+	}
+	c.allExhaustedUntil = time.Time{}
+	c.keyMutex.Unlock()
+
+	return nil
+}
+
+// nextGeminiQuotaReset returns the next midnight Pacific time (PST or PDT),
+// when Gemini daily quotas reset. The zone comes from the embedded time/tzdata,
+// so it is right even in images without /usr/share/zoneinfo.
+func nextGeminiQuotaReset(now time.Time) time.Time {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		loc = time.UTC // Unreachable with time/tzdata embedded
+	}
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
@@ -1,24 +1,27 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"wega-catalog-api/internal/normalize"
+	"wega-catalog-api/internal/telemetry"
 )
 
 const (
 	groqAPIBase = "https://api.groq.com/openai/v1/chat/completions"
 	groqModel   = "llama-3.1-8b-instant" // Free tier model with 6K TPM
+
+	// groqChoiceTokens bounds the reply to one {"choice": n, "confidence": x}
+	groqChoiceTokens = 24
 )
 
 // ErrAllKeysExhaustedDaily is returned when all API keys have hit their daily limit
@@ -26,18 +29,22 @@ var ErrAllKeysExhaustedDaily = fmt.Errorf("all API keys exhausted for the day")
 
 // GroqClient handles communication with Groq API for LLM normalization
 // Supports multiple API keys with automatic failover on rate limit (429)
-// and daily limit exhaustion with automatic reset at midnight UTC
+// and daily limit exhaustion with automatic reset at midnight UTC.
+// Each key has its own rate limiter and optional daily token budget, so keys
+// on different tiers can be mixed.
 type GroqClient struct {
-	httpClient  *http.Client
-	apiKeys     []string
-	currentKey  atomic.Int32
-	keyMutex    sync.RWMutex
-	keyStatus   []keyStatus // Track status of each key
-	rateLimiter *RateLimiter
-	logger      *slog.Logger
+	httpClient *http.Client
+	apiKeys    []string
+	currentKey atomic.Int32
+	keyMutex   sync.RWMutex
+	keyStatus  []keyStatus // Track status of each key
+	logger     *slog.Logger
+	observer   RequestObserver
+	httpConfig HTTPConfig
 
 	// Daily limit tracking
 	allExhaustedUntil time.Time // When all keys are exhausted, wait until this time
+	noDailyWait       bool      // Return ErrAllKeysExhaustedDaily instead of waiting
 }
 
 // keyStatus tracks the health of an API key
@@ -51,6 +58,60 @@ type keyStatus struct {
 	dailyExhaustedAt time.Time
 
 	errorCount int
+
+	limiter     *RateLimiter // Requests per minute of this key
+	rpm         float64
+	dailyTokens int64 // Daily token budget (0 = unlimited)
+	tokensToday int64 // Tokens used since the last midnight reset
+	lastTokens  int64 // Tokens of the last request, the estimate for the next one
+}
+
+// GroqKeyConfig is one API key with its own limits
+type GroqKeyConfig struct {
+	Key         string
+	RPM         float64 // Requests per minute (<= 0 = unlimited)
+	DailyTokens int64   // Daily token budget (0 = unlimited)
+}
+
+// ParseGroqKeys parses comma-separated keys with optional per-key limits:
+// "key1:30rpm,key2:60rpm:500000tpd". Keys without an rpm use defaultRPM;
+// keys without a tpd have no daily token budget and rely on Groq's 429.
+func ParseGroqKeys(spec string, defaultRPM float64) ([]GroqKeyConfig, error) {
+	var keys []GroqKeyConfig
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ":")
+		key := GroqKeyConfig{Key: strings.TrimSpace(fields[0]), RPM: defaultRPM}
+		if key.Key == "" {
+			return nil, fmt.Errorf("empty key in %q", part)
+		}
+		for _, field := range fields[1:] {
+			field = strings.ToLower(strings.TrimSpace(field))
+			var (
+				value string
+				err   error
+			)
+			switch {
+			case strings.HasSuffix(field, "rpm"):
+				value = strings.TrimSuffix(field, "rpm")
+				key.RPM, err = strconv.ParseFloat(value, 64)
+			case strings.HasSuffix(field, "tpd"):
+				value = strings.TrimSuffix(field, "tpd")
+				key.DailyTokens, err = strconv.ParseInt(value, 10, 64)
+			default:
+				return nil, fmt.Errorf("unknown limit %q for key %s (use <n>rpm or <n>tpd)", field, KeyLabel(key.Key))
+			}
+			if err != nil || strings.HasPrefix(value, "-") {
+				return nil, fmt.Errorf("invalid limit %q for key %s", field, KeyLabel(key.Key))
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
 }
 
 // GroqRequest represents a chat completion request
@@ -59,6 +120,13 @@ type GroqRequest struct {
 	Messages    []GroqMessage `json:"messages"`
 	Temperature float64       `json:"temperature"`
 	MaxTokens   int           `json:"max_tokens"`
+	// ResponseFormat {"type": "json_object"} makes the model reply with valid JSON
+	ResponseFormat *GroqResponseFormat `json:"response_format,omitempty"`
+}
+
+// GroqResponseFormat selects the output format of a chat completion
+type GroqResponseFormat struct {
+	Type string `json:"type"`
 }
 
 // GroqMessage represents a chat message
@@ -98,8 +166,9 @@ type BatchMatchRequest struct {
 // BatchMatchResult represents the result of a batch match
 type BatchMatchResult struct {
 	ID           int
-	MatchedIndex int    // 0-based index of matched option, -1 if no match
-	MatchedValue string // The matched option value
+	MatchedIndex int     // 0-based index of matched option, -1 if no match
+	MatchedValue string  // The matched option value
+	Confidence   float64 // Model's confidence (0.0-1.0, 0 = not reported)
 	Error        error
 }
 
@@ -108,33 +177,65 @@ func NewGroqClient(apiKey string, requestsPerMinute float64, logger *slog.Logger
 	return NewGroqClientMultiKey([]string{apiKey}, requestsPerMinute, logger)
 }
 
-// NewGroqClientMultiKey creates a new Groq API client with multiple keys for failover
+// NewGroqClientMultiKey creates a new Groq API client with multiple keys for
+// failover, each limited to requestsPerMinute
 func NewGroqClientMultiKey(apiKeys []string, requestsPerMinute float64, logger *slog.Logger) *GroqClient {
-	if len(apiKeys) == 0 {
+	keys := make([]GroqKeyConfig, len(apiKeys))
+	for i, key := range apiKeys {
+		keys[i] = GroqKeyConfig{Key: key, RPM: requestsPerMinute}
+	}
+	return NewGroqClientKeys(keys, logger)
+}
+
+// NewGroqClientKeys creates a new Groq API client with per-key limits
+func NewGroqClientKeys(keys []GroqKeyConfig, logger *slog.Logger) *GroqClient {
+	if len(keys) == 0 {
 		panic("at least one API key is required")
 	}
 
 	client := &GroqClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		apiKeys:     apiKeys,
-		keyStatus:   make([]keyStatus, len(apiKeys)),
-		rateLimiter: NewRateLimiter(requestsPerMinute / 60.0), // Convert to per-second
-		logger:      logger,
+		httpClient: telemetry.NewHTTPClient(0),
+		httpConfig: HTTPConfig{Timeout: 30 * time.Second, Retry: DefaultRetryConfig(0)},
+		apiKeys:    make([]string, len(keys)),
+		keyStatus:  make([]keyStatus, len(keys)),
+		logger:     logger,
+	}
+	for i, key := range keys {
+		client.apiKeys[i] = key.Key
+		client.keyStatus[i].limiter = NewRateLimiter(key.RPM / 60.0) // Convert to per-second
+		client.keyStatus[i].rpm = key.RPM
+		client.keyStatus[i].dailyTokens = key.DailyTokens
+
+		logger.Debug("Groq key configured",
+			"key_idx", i,
+			"rpm", key.RPM,
+			"daily_tokens", key.DailyTokens,
+		)
 	}
 
 	// Start background goroutine to reset keys at midnight UTC
 	go client.midnightResetLoop()
 
 	logger.Info("Groq client initialized",
-		"keys_count", len(apiKeys),
-		"rpm", requestsPerMinute,
+		"keys_count", len(keys),
+		"total_rpm", client.totalRPM(),
 	)
 
 	return client
 }
 
+// totalRPM returns the combined requests per minute of all keys (0 if any is unlimited)
+func (c *GroqClient) totalRPM() float64 {
+	total := 0.0
+	for _, status := range c.keyStatus {
+		if status.rpm <= 0 {
+			return 0
+		}
+		total += status.rpm
+	}
+	return total
+}
+
 // midnightResetLoop resets all daily-exhausted keys at midnight UTC
 func (c *GroqClient) midnightResetLoop() {
 	for {
@@ -171,6 +272,7 @@ func (c *GroqClient) resetAllDailyLimits() {
 		c.keyStatus[i].rateLimited = false
 		c.keyStatus[i].rateLimitedAt = time.Time{}
 		c.keyStatus[i].errorCount = 0
+		c.keyStatus[i].tokensToday = 0
 	}
 
 	// Reset the global exhaustion flag
@@ -184,6 +286,30 @@ func (c *GroqClient) resetAllDailyLimits() {
 	}
 }
 
+// SetHTTPConfig sets the per-request timeout and retry behavior for transport errors and 5xx
+func (c *GroqClient) SetHTTPConfig(cfg HTTPConfig) {
+	c.httpConfig = cfg
+}
+
+// SetBurst sets how many requests each key may send back to back after idle time
+func (c *GroqClient) SetBurst(burst int) {
+	for _, status := range c.keyStatus {
+		status.limiter.SetBurst(burst)
+	}
+}
+
+// SetWaitForDailyReset controls what happens once every key is daily-exhausted:
+// wait until midnight UTC (default) or fail fast with ErrAllKeysExhaustedDaily
+// so a ChainClient can fall through to the next provider
+func (c *GroqClient) SetWaitForDailyReset(wait bool) {
+	c.noDailyWait = !wait
+}
+
+// SetObserver sets the observer notified about rate limits and network errors
+func (c *GroqClient) SetObserver(observer RequestObserver) {
+	c.observer = observer
+}
+
 // GetKeyCount returns the number of API keys configured
 func (c *GroqClient) GetKeyCount() int {
 	return len(c.apiKeys)
@@ -197,15 +323,30 @@ func (c *GroqClient) GetKeyStatus() map[string]interface{} {
 	activeKeys := 0
 	rateLimitedKeys := 0
 	dailyExhaustedKeys := 0
+	keys := make([]map[string]interface{}, len(c.keyStatus))
 
-	for _, status := range c.keyStatus {
+	for i, status := range c.keyStatus {
+		state := "active"
 		if status.dailyExhausted {
 			dailyExhaustedKeys++
+			state = "daily_exhausted"
 		} else if status.rateLimited {
 			rateLimitedKeys++
+			state = "rate_limited"
 		} else {
 			activeKeys++
 		}
+
+		key := map[string]interface{}{
+			"key":          KeyLabel(c.apiKeys[i]),
+			"state":        state,
+			"rpm":          status.rpm,
+			"tokens_today": status.tokensToday,
+		}
+		if status.dailyTokens > 0 {
+			key["daily_tokens"] = status.dailyTokens
+		}
+		keys[i] = key
 	}
 
 	result := map[string]interface{}{
@@ -213,6 +354,7 @@ func (c *GroqClient) GetKeyStatus() map[string]interface{} {
 		"active_keys":          activeKeys,
 		"rate_limited_keys":    rateLimitedKeys,
 		"daily_exhausted_keys": dailyExhaustedKeys,
+		"keys":                 keys,
 	}
 
 	if !c.allExhaustedUntil.IsZero() {
@@ -223,10 +365,84 @@ func (c *GroqClient) GetKeyStatus() map[string]interface{} {
 	return result
 }
 
-// getCurrentKey returns the current API key to use
-func (c *GroqClient) getCurrentKey() (string, int) {
-	idx := int(c.currentKey.Load()) % len(c.apiKeys)
-	return c.apiKeys[idx], idx
+// acquireKey picks the key whose rate limiter frees up first, reserves its
+// slot under keyMutex, waits for it and returns it. Keys that are daily-exhausted, or whose token budget
+// can't fit another request, are skipped, and keys cooling down after a 429
+// are only used when nothing else is left, so traffic moves to another key
+// before Groq starts rejecting it. Returns idx -1 when every key is
+// daily-exhausted.
+func (c *GroqClient) acquireKey(ctx context.Context) (string, int, error) {
+	c.keyMutex.Lock()
+	now := time.Now()
+	start := int(c.currentKey.Load()) % len(c.apiKeys)
+	best, bestCooling := -1, false
+	var bestDelay time.Duration
+	for i := range c.apiKeys {
+		idx := (start + i) % len(c.apiKeys)
+		status := &c.keyStatus[idx]
+
+		if !status.dailyExhausted && status.dailyTokens > 0 &&
+			status.tokensToday+status.lastTokens > status.dailyTokens {
+			status.dailyExhausted = true
+			status.dailyExhaustedAt = now
+			c.logger.Warn("API key daily token budget reached",
+				"key_idx", idx,
+				"tokens_today", status.tokensToday,
+				"daily_tokens", status.dailyTokens,
+			)
+		}
+		if status.dailyExhausted {
+			continue
+		}
+
+		// Per-minute rate limit expires after a 1 minute cooldown
+		if status.rateLimited && now.Sub(status.rateLimitedAt) > time.Minute {
+			status.rateLimited = false
+			status.errorCount = 0
+		}
+
+		delay := status.limiter.Delay()
+		if best < 0 || (bestCooling && !status.rateLimited) ||
+			(bestCooling == status.rateLimited && delay < bestDelay) {
+			best, bestCooling, bestDelay = idx, status.rateLimited, delay
+		}
+	}
+
+	if best < 0 {
+		if c.allExhaustedUntil.IsZero() {
+			c.allExhaustedUntil = nextMidnightUTC()
+			c.logger.Warn("all API keys daily limit exhausted, waiting until midnight UTC",
+				"total_keys", len(c.apiKeys),
+				"resume_at", c.allExhaustedUntil,
+			)
+			reportKeysExhausted(c.observer, ServiceGroq, c.allExhaustedUntil)
+		}
+		c.keyMutex.Unlock()
+		return "", -1, nil
+	}
+	if best != start {
+		c.currentKey.Store(int32(best))
+		c.logger.Debug("rotated to API key with free capacity",
+			"from_idx", start,
+			"to_idx", best,
+			"delay", bestDelay,
+		)
+	}
+	// Reserve the slot before unlocking so concurrent callers see this key's
+	// limiter as taken and spread across the other keys
+	reservation := c.keyStatus[best].limiter.Reserve()
+	c.keyMutex.Unlock()
+
+	if err := reservation.Wait(ctx); err != nil {
+		return "", best, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+	return c.apiKeys[best], best, nil
+}
+
+// nextMidnightUTC returns the next daily reset time
+func nextMidnightUTC() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
 }
 
 // isDailyLimitError checks if the error response indicates daily limit exhaustion
@@ -316,9 +532,7 @@ func (c *GroqClient) rotateKey(failedIdx int, isDailyLimit bool) bool {
 	}
 
 	if allDailyExhausted {
-		// Calculate next midnight UTC
-		nowUTC := time.Now().UTC()
-		nextMidnight := time.Date(nowUTC.Year(), nowUTC.Month(), nowUTC.Day()+1, 0, 0, 0, 0, time.UTC)
+		nextMidnight := nextMidnightUTC()
 		c.allExhaustedUntil = nextMidnight
 
 		c.logger.Warn("all API keys daily limit exhausted, waiting until midnight UTC",
@@ -326,6 +540,7 @@ func (c *GroqClient) rotateKey(failedIdx int, isDailyLimit bool) bool {
 			"resume_at", nextMidnight,
 			"wait_duration", time.Until(nextMidnight),
 		)
+		reportKeysExhausted(c.observer, ServiceGroq, nextMidnight)
 	} else {
 		c.logger.Warn("all API keys temporarily rate limited",
 			"total_keys", len(c.apiKeys),
@@ -335,12 +550,15 @@ func (c *GroqClient) rotateKey(failedIdx int, isDailyLimit bool) bool {
 	return false
 }
 
-// markKeySuccess marks a key as successful (resets error count)
-func (c *GroqClient) markKeySuccess(idx int) {
+// markKeySuccess marks a key as successful (resets error count) and counts
+// the tokens against its daily budget
+func (c *GroqClient) markKeySuccess(idx int, tokens int) {
 	c.keyMutex.Lock()
 	defer c.keyMutex.Unlock()
 	c.keyStatus[idx].errorCount = 0
 	c.keyStatus[idx].rateLimited = false
+	c.keyStatus[idx].tokensToday += int64(tokens)
+	c.keyStatus[idx].lastTokens = int64(tokens)
 	// Note: don't reset dailyExhausted here, it only resets at midnight
 }
 
@@ -355,6 +573,10 @@ func (c *GroqClient) waitUntilMidnight(ctx context.Context) error {
 		return nil
 	}
 
+	if c.noDailyWait {
+		return ErrAllKeysExhaustedDaily
+	}
+
 	waitDuration := time.Until(exhaustedUntil)
 	c.logger.Info("waiting until midnight for API key reset",
 		"resume_at", exhaustedUntil,
@@ -371,76 +593,49 @@ func (c *GroqClient) waitUntilMidnight(ctx context.Context) error {
 }
 
 // NormalizeVehicle uses LLM to find the best match from Motul options
-// Uses optimized minimal prompt to save tokens (~60% reduction)
 func (c *GroqClient) NormalizeVehicle(ctx context.Context, wegaVehicle string, motulOptions []string) (string, error) {
+	match, _, err := c.NormalizeVehicleConfidence(ctx, wegaVehicle, motulOptions)
+	return match, err
+}
+
+// NormalizeVehicleConfidence finds the best match and the model's confidence
+// in it, asking for a JSON reply so any number of options works.
+// Returns ErrNoMatch when the model picks none.
+func (c *GroqClient) NormalizeVehicleConfidence(ctx context.Context, wegaVehicle string, motulOptions []string) (string, float64, error) {
 	if len(motulOptions) == 0 {
-		return "", fmt.Errorf("no Motul options provided")
+		return "", 0, fmt.Errorf("no Motul options provided")
 	}
 
 	// If only one option, return it directly (no LLM needed)
 	if len(motulOptions) == 1 {
-		return motulOptions[0], nil
+		return motulOptions[0], 1, nil
 	}
 
-	// Build compact options list
-	optionsList := ""
-	for i, opt := range motulOptions {
-		optionsList += fmt.Sprintf("%d.%s ", i+1, opt)
-	}
-
-	// CRITICAL: Prompt must force LLM to output ONLY a number
-	// The previous prompt was too complex and LLM responded with explanations
-	// This version uses a simple Q&A format that works better with Llama 3.1
+	// Simple Q&A format with the turbo rule up front works best with Llama 3.1
 	prompt := fmt.Sprintf(`Q: Which option best matches "%s"?
 IMPORTANT: If vehicle has NO turbo keywords (Turbo/TSI/T200/THP/130cv), choose NON-turbo option.
-%s
-A:`, wegaVehicle, strings.TrimSpace(optionsList))
-
-	// Rate limit
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return "", fmt.Errorf("rate limit wait failed: %w", err)
-	}
+%sReply with ONLY JSON: %s`, wegaVehicle, numberedOptions(motulOptions), choiceFormat)
 
-	// Make request with automatic failover
-	response, err := c.doRequestWithFailover(ctx, prompt)
+	// Make request with automatic failover (rate limited per key)
+	response, err := c.doRequestWithFailover(ctx, prompt, groqChoiceTokens)
 	if err != nil {
-		return "", err
-	}
-
-	// Parse the response number
-	response = strings.TrimSpace(response)
-
-	// Try to extract first digit from response
-	var optionNum int
-	for _, char := range response {
-		if char >= '1' && char <= '9' {
-			optionNum = int(char - '0')
-			break
-		}
+		return "", 0, err
 	}
 
-	if optionNum == 0 {
-		// LLM didn't return a valid number - use smart fallback based on engine type
-		c.logger.Warn("LLM response not a number, using smart fallback",
-			"response", response,
+	choice, err := parseChoice(response, len(motulOptions))
+	if err != nil {
+		// Unreadable reply - use smart fallback based on engine type
+		c.logger.Warn("LLM response not a valid choice, using smart fallback",
+			"error", err,
 			"wega_vehicle", wegaVehicle,
 		)
-		return c.smartFallback(wegaVehicle, motulOptions), nil
+		return c.smartFallback(wegaVehicle, motulOptions), fallbackConfidence, nil
 	}
-
-	// Validate option number
-	if optionNum <= 0 || optionNum > len(motulOptions) {
-		if optionNum == 0 {
-			return "", fmt.Errorf("LLM indicated no match")
-		}
-		c.logger.Warn("invalid option number from LLM, using smart fallback",
-			"option_num", optionNum,
-			"total_options", len(motulOptions),
-		)
-		return c.smartFallback(wegaVehicle, motulOptions), nil
+	if choice.Choice == 0 {
+		return "", choice.Confidence, ErrNoMatch
 	}
 
-	return motulOptions[optionNum-1], nil
+	return motulOptions[choice.Choice-1], choice.Confidence, nil
 }
 
 // smartFallback selects the best option based on turbo/aspirated engine detection
@@ -488,7 +683,8 @@ func (c *GroqClient) smartFallback(wegaVehicle string, motulOptions []string) st
 }
 
 // NormalizeVehicleBatch processes multiple vehicles in a single LLM call
-// Returns a map of vehicle index to matched option index (-1 if no match)
+// Returns one result per request, in request order; requests the model did
+// not answer (or answered with no match) carry an error
 // This saves ~40% tokens by reducing prompt overhead
 func (c *GroqClient) NormalizeVehicleBatch(ctx context.Context, requests []BatchMatchRequest) ([]BatchMatchResult, error) {
 	if len(requests) == 0 {
@@ -498,22 +694,22 @@ func (c *GroqClient) NormalizeVehicleBatch(ctx context.Context, requests []Batch
 	// For single request, use regular method
 	if len(requests) == 1 {
 		req := requests[0]
-		result, err := c.NormalizeVehicle(ctx, req.Vehicle, req.Options)
+		result, confidence, err := c.NormalizeVehicleConfidence(ctx, req.Vehicle, req.Options)
 		if err != nil {
 			return []BatchMatchResult{{ID: req.ID, MatchedIndex: -1, Error: err}}, nil
 		}
 		// Find index of matched result
 		for i, opt := range req.Options {
 			if opt == result {
-				return []BatchMatchResult{{ID: req.ID, MatchedIndex: i, MatchedValue: result}}, nil
+				return []BatchMatchResult{{ID: req.ID, MatchedIndex: i, MatchedValue: result, Confidence: confidence}}, nil
 			}
 		}
-		return []BatchMatchResult{{ID: req.ID, MatchedIndex: 0, MatchedValue: req.Options[0]}}, nil
+		return []BatchMatchResult{{ID: req.ID, MatchedIndex: -1, Error: fmt.Errorf("unexpected match %q", result)}}, nil
 	}
 
 	// Build batch prompt
 	var sb strings.Builder
-	sb.WriteString("Match each vehicle to its best option. Reply with comma-separated numbers.\n")
+	sb.WriteString("Match each vehicle to its best option.\n")
 
 	for i, req := range requests {
 		optsList := ""
@@ -523,16 +719,11 @@ func (c *GroqClient) NormalizeVehicleBatch(ctx context.Context, requests []Batch
 		sb.WriteString(fmt.Sprintf("V%d:%s|Opts:%s\n", i+1, req.Vehicle, strings.TrimSpace(optsList)))
 	}
 
-	sb.WriteString(fmt.Sprintf("Reply format: n1,n2,n3... (numbers 1-%d for each, 0=no match)",
+	sb.WriteString(fmt.Sprintf(`Reply with ONLY JSON, one answer per vehicle in order: {"answers": [{"choice": n, "confidence": x}, ...]} (n = option number 1-%d, 0 = no match; x = 0.0-1.0)`,
 		maxOptions(requests)))
 
-	// Rate limit
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait failed: %w", err)
-	}
-
-	// Make request
-	response, err := c.doRequestWithFailover(ctx, sb.String())
+	// Make request (rate limited per key)
+	response, err := c.doRequestWithFailover(ctx, sb.String(), groqChoiceTokens*(len(requests)+1))
 	if err != nil {
 		// Return errors for all requests
 		results := make([]BatchMatchResult, len(requests))
@@ -542,49 +733,51 @@ func (c *GroqClient) NormalizeVehicleBatch(ctx context.Context, requests []Batch
 		return results, nil
 	}
 
-	// Parse response (format: "1,2,0,3,1")
 	return c.parseBatchResponse(response, requests), nil
 }
 
-// parseBatchResponse parses the comma-separated batch response
+// parseBatchResponse parses the {"answers": [...]} batch reply. Answers may
+// be choice objects or bare numbers; a reply that is not JSON is read as a
+// list of numbers in vehicle order.
 func (c *GroqClient) parseBatchResponse(response string, requests []BatchMatchRequest) []BatchMatchResult {
 	results := make([]BatchMatchResult, len(requests))
-
-	// Initialize with defaults
 	for i, req := range requests {
-		results[i] = BatchMatchResult{
-			ID:           req.ID,
-			MatchedIndex: 0, // Default to first option
-			MatchedValue: req.Options[0],
-		}
+		results[i] = BatchMatchResult{ID: req.ID, MatchedIndex: -1, Error: fmt.Errorf("no answer in batch response")}
 	}
 
-	// Clean response and extract numbers
-	response = strings.TrimSpace(response)
-	// Extract all numbers from response
-	re := regexp.MustCompile(`\d+`)
-	numbers := re.FindAllString(response, -1)
+	var answers []string
+	var reply struct {
+		Answers []json.RawMessage `json:"answers"`
+	}
+	if obj, ok := jsonObject(response); ok && json.Unmarshal([]byte(obj), &reply) == nil && reply.Answers != nil {
+		for _, answer := range reply.Answers {
+			answers = append(answers, string(answer))
+		}
+	} else {
+		answers = firstNumber.FindAllString(response, -1)
+	}
 
-	for i, numStr := range numbers {
+	for i, answer := range answers {
 		if i >= len(requests) {
 			break
 		}
 
-		num, err := strconv.Atoi(numStr)
-		if err != nil {
-			continue
-		}
-
 		req := requests[i]
-		if num == 0 {
-			results[i].MatchedIndex = -1
-			results[i].MatchedValue = ""
-			results[i].Error = fmt.Errorf("LLM indicated no match")
-		} else if num > 0 && num <= len(req.Options) {
-			results[i].MatchedIndex = num - 1
-			results[i].MatchedValue = req.Options[num-1]
+		choice, err := parseChoice(answer, len(req.Options))
+		switch {
+		case err != nil:
+			results[i].Error = err
+		case choice.Choice == 0:
+			results[i].Confidence = choice.Confidence
+			results[i].Error = ErrNoMatch
+		default:
+			results[i] = BatchMatchResult{
+				ID:           req.ID,
+				MatchedIndex: choice.Choice - 1,
+				MatchedValue: req.Options[choice.Choice-1],
+				Confidence:   choice.Confidence,
+			}
 		}
-		// Invalid numbers keep the default (first option)
 	}
 
 	return results
@@ -601,16 +794,17 @@ func maxOptions(requests []BatchMatchRequest) int {
 	return max
 }
 
-// doRequestWithFailover makes a request with automatic key rotation on 429
+// doRequestWithFailover makes a JSON-mode request with automatic key rotation on 429
 // If all keys are daily-exhausted, waits until midnight UTC and retries
-func (c *GroqClient) doRequestWithFailover(ctx context.Context, prompt string) (string, error) {
+func (c *GroqClient) doRequestWithFailover(ctx context.Context, prompt string, maxTokens int) (string, error) {
 	req := GroqRequest{
 		Model: groqModel,
 		Messages: []GroqMessage{
 			{Role: "user", Content: prompt},
 		},
-		Temperature: 0.0, // Zero temperature for deterministic output
-		MaxTokens:   5,   // Force short response (just a number)
+		Temperature:    0.0,       // Zero temperature for deterministic output
+		MaxTokens:      maxTokens, // Force short response (just the choice JSON)
+		ResponseFormat: &GroqResponseFormat{Type: "json_object"},
 	}
 
 	reqBody, err := json.Marshal(req)
@@ -635,21 +829,13 @@ func (c *GroqClient) doRequestWithFailover(ctx context.Context, prompt string) (
 				return "", ctx.Err()
 			}
 
-			apiKey, keyIdx := c.getCurrentKey()
-
-			// Skip if this key is daily-exhausted
-			c.keyMutex.RLock()
-			isDailyExhausted := c.keyStatus[keyIdx].dailyExhausted
-			c.keyMutex.RUnlock()
-
-			if isDailyExhausted {
-				c.logger.Info("skipping daily-exhausted key",
-					"key_idx", keyIdx,
-					"tried_keys", triedKeys,
-				)
-				triedKeys++
-				c.currentKey.Store(int32((keyIdx + 1) % len(c.apiKeys)))
-				continue
+			apiKey, keyIdx, err := c.acquireKey(ctx)
+			if err != nil {
+				return "", err
+			}
+			if keyIdx < 0 {
+				// Every key is daily-exhausted, wait for midnight
+				break
 			}
 
 			c.logger.Info("attempting Groq API call",
@@ -657,28 +843,25 @@ func (c *GroqClient) doRequestWithFailover(ctx context.Context, prompt string) (
 				"tried_keys", triedKeys,
 			)
 
-			httpReq, err := http.NewRequestWithContext(ctx, "POST", groqAPIBase, bytes.NewReader(reqBody))
-			if err != nil {
-				return "", fmt.Errorf("failed to create request: %w", err)
-			}
-
-			httpReq.Header.Set("Content-Type", "application/json")
-			httpReq.Header.Set("Authorization", "Bearer "+apiKey)
-
-			resp, err := c.httpClient.Do(httpReq)
+			resp, err := doJSONWithRetry(ctx, c.httpClient, c.httpConfig, groqAPIBase, reqBody,
+				map[string]string{"Authorization": "Bearer " + apiKey},
+				func(err error) {
+					if c.observer != nil {
+						c.observer.OnNetworkError(ServiceGroq, err)
+					}
+				},
+			)
 			if err != nil {
 				c.logger.Error("HTTP request failed", "error", err)
-				return "", fmt.Errorf("failed to send request: %w", err)
-			}
-
-			body, err := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if err != nil {
-				return "", fmt.Errorf("failed to read response: %w", err)
+				return "", err
 			}
+			body := resp.Body
 
 			// Check for rate limit (429)
 			if resp.StatusCode == http.StatusTooManyRequests {
+				if c.observer != nil {
+					c.observer.OnRateLimited(ServiceGroq)
+				}
 				isDailyLimit := c.isDailyLimitError(resp.StatusCode, body)
 
 				c.logger.Warn("rate limit hit, rotating key",
@@ -739,12 +922,18 @@ func (c *GroqClient) doRequestWithFailover(ctx context.Context, prompt string) (
 			}
 
 			// Success! Mark key as healthy
-			c.markKeySuccess(keyIdx)
+			c.markKeySuccess(keyIdx, groqResp.Usage.TotalTokens)
 
 			c.logger.Info("Groq API request successful",
 				"key_idx", keyIdx,
 				"tokens_used", groqResp.Usage.TotalTokens,
 			)
+			reportTokens(c.observer, TokenUsage{
+				Service:          ServiceGroq,
+				Key:              KeyLabel(c.apiKeys[keyIdx]),
+				PromptTokens:     groqResp.Usage.PromptTokens,
+				CompletionTokens: groqResp.Usage.CompletionTokens,
+			})
 
 			return groqResp.Choices[0].Message.Content, nil
 		}
@@ -775,7 +964,7 @@ func (c *GroqClient) FindBestBrand(ctx context.Context, wegaBrand string, motulB
 
 	// Try exact match first (case-insensitive)
 	for _, brand := range motulBrands {
-		if normalizeForComparison(brand) == normalizeForComparison(wegaBrand) {
+		if normalize.Text.Equal(brand, wegaBrand) {
 			return brand, nil
 		}
 	}
@@ -792,7 +981,7 @@ func (c *GroqClient) FindBestModel(ctx context.Context, wegaModel string, motulM
 
 	// Try exact match first
 	for _, model := range motulModels {
-		if normalizeForComparison(model) == normalizeForComparison(wegaModel) {
+		if normalize.Text.Equal(model, wegaModel) {
 			return model, nil
 		}
 	}
@@ -800,9 +989,3 @@ func (c *GroqClient) FindBestModel(ctx context.Context, wegaModel string, motulM
 	// Use LLM for fuzzy matching
 	return c.NormalizeVehicle(ctx, wegaModel, motulModels)
 }
-
-// normalizeForComparison normalizes strings for comparison
-func normalizeForComparison(s string) string {
-	// Simple normalization - lowercase and remove extra spaces
-	return strings.ToLower(strings.TrimSpace(s))
-}
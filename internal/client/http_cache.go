@@ -0,0 +1,112 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// HTTPCache stores GET response bodies on disk keyed by URL. Entries younger
+// than the TTL are served without a request; older entries are revalidated
+// with If-None-Match / If-Modified-Since when the server sent validators.
+type HTTPCache struct {
+	dir string
+	ttl time.Duration
+	mu  sync.Mutex
+}
+
+// cacheEntry is the on-disk format of one cached response
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	Body         []byte    `json:"body"`
+}
+
+// NewHTTPCache creates a cache in dir (created if missing). A ttl <= 0 means
+// every entry is revalidated before use.
+func NewHTTPCache(dir string, ttl time.Duration) (*HTTPCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &HTTPCache{dir: dir, ttl: ttl}, nil
+}
+
+// path returns the file holding the entry for url
+func (hc *HTTPCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(hc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the entry for url, if any, and whether it is still fresh
+func (hc *HTTPCache) get(url string) (*cacheEntry, bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	data, err := os.ReadFile(hc.path(url))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil, false
+	}
+	return &entry, hc.ttl > 0 && time.Since(entry.StoredAt) < hc.ttl
+}
+
+// put stores body for url along with the validators from header
+func (hc *HTTPCache) put(url string, header http.Header, body []byte) error {
+	return hc.write(&cacheEntry{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		StoredAt:     time.Now(),
+		Body:         body,
+	})
+}
+
+// touch marks a revalidated (304) entry as fresh again
+func (hc *HTTPCache) touch(entry *cacheEntry) error {
+	entry.StoredAt = time.Now()
+	return hc.write(entry)
+}
+
+// write saves entry atomically so a crash never leaves a truncated file
+func (hc *HTTPCache) write(entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	path := hc.path(entry.URL)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// conditionalHeaders returns the revalidation headers for entry (nil-safe)
+func (e *cacheEntry) conditionalHeaders() http.Header {
+	header := http.Header{}
+	if e == nil {
+		return header
+	}
+	if e.ETag != "" {
+		header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		header.Set("If-Modified-Since", e.LastModified)
+	}
+	return header
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryConfig defines retry behavior
+type RetryConfig struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// HTTPConfig holds timeout and retry settings for an external service
+type HTTPConfig struct {
+	Timeout time.Duration // Per-request timeout (0 = no timeout)
+	Retry   RetryConfig
+}
+
+// DefaultRetryConfig returns the default exponential backoff (1s → 2s → 4s ... capped at 30s)
+func DefaultRetryConfig(maxRetries int) RetryConfig {
+	return RetryConfig{
+		MaxRetries:     maxRetries,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2.0,
+	}
+}
+
+// nextBackoff returns the backoff to use after the current one
+func (r RetryConfig) nextBackoff(current time.Duration) time.Duration {
+	return min(time.Duration(float64(current)*r.Multiplier), r.MaxBackoff)
+}
+
+// sleepContext sleeps for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// httpResult holds a fully read HTTP response
+type httpResult struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// doJSONWithRetry POSTs a JSON body, retrying transport errors and 5xx responses
+// according to cfg. Each attempt gets its own cfg.Timeout. onNetworkError (optional)
+// is called for every transport failure.
+func doJSONWithRetry(
+	ctx context.Context,
+	httpClient *http.Client,
+	cfg HTTPConfig,
+	url string,
+	body []byte,
+	headers map[string]string,
+	onNetworkError func(error),
+) (*httpResult, error) {
+	backoff := cfg.Retry.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		result, err := doJSONOnce(ctx, httpClient, cfg.Timeout, url, body, headers)
+		if err != nil && onNetworkError != nil && ctx.Err() == nil {
+			onNetworkError(err)
+		}
+
+		retryable := err != nil || result.StatusCode >= 500
+		if !retryable || attempt >= cfg.Retry.MaxRetries || ctx.Err() != nil {
+			if err != nil {
+				return nil, fmt.Errorf("failed to send request: %w", err)
+			}
+			return result, nil
+		}
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff = cfg.Retry.nextBackoff(backoff)
+	}
+}
+
+// doJSONOnce performs a single JSON POST and reads the whole response
+func doJSONOnce(
+	ctx context.Context,
+	httpClient *http.Client,
+	timeout time.Duration,
+	url string,
+	body []byte,
+	headers map[string]string,
+) (*httpResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpResult{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, nil
+}
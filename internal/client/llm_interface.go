@@ -3,7 +3,7 @@ package client
 import "context"
 
 // LLMClient defines the interface for LLM-based vehicle matching
-// Both GroqClient and OllamaClient implement this interface
+// GroqClient, OllamaClient and GeminiClient implement this interface
 type LLMClient interface {
 	// NormalizeVehicle finds the best match from options for a vehicle
 	NormalizeVehicle(ctx context.Context, vehicle string, options []string) (string, error)
@@ -15,6 +15,16 @@ type LLMClient interface {
 	FindBestModel(ctx context.Context, model string, options []string) (string, error)
 }
 
-// Ensure both clients implement LLMClient
+// BatchNormalizer is implemented by LLM clients that can match several
+// vehicles to their options in a single call
+type BatchNormalizer interface {
+	NormalizeVehicleBatch(ctx context.Context, requests []BatchMatchRequest) ([]BatchMatchResult, error)
+}
+
+var _ BatchNormalizer = (*GroqClient)(nil)
+
+// Ensure all clients implement LLMClient
 var _ LLMClient = (*GroqClient)(nil)
 var _ LLMClient = (*OllamaClient)(nil)
+var _ LLMClient = (*GeminiClient)(nil)
+var _ LLMClient = (*ChainClient)(nil)
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
+
+	"wega-catalog-api/internal/telemetry"
 )
 
 const (
@@ -16,6 +19,27 @@ const (
 	businessUnit = "Brazil"
 )
 
+// Motul vehicle categories (categoryId in the vehicle-brands endpoint)
+const (
+	CategoryCar        = "CAR"
+	CategoryMotorcycle = "MOTORCYCLE"
+	CategoryTruck      = "TRUCK" // Trucks and buses
+	CategoryAgri       = "AGRI"  // Tractors and agricultural machinery
+)
+
+// Categories lists the supported vehicle categories, cars first
+var Categories = []string{CategoryCar, CategoryMotorcycle, CategoryTruck, CategoryAgri}
+
+// IsCategory reports whether category is one of Categories
+func IsCategory(category string) bool {
+	for _, c := range Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
 // Brand represents a vehicle brand
 type Brand struct {
 	ID   string `json:"id"`
@@ -73,48 +97,157 @@ type Component struct {
 	} `json:"capacities"`
 	Recommendations []struct {
 		Conditions struct {
-			Usage   string `json:"usage"`
-			Mileage string `json:"mileage"`
+			Usage    string `json:"usage"`
+			Mileage  string `json:"mileage"`
+			Interval string `json:"interval,omitempty"` // Drain interval ("15000 km / 12 months"), when present
 		} `json:"conditions"`
 		Products []struct {
-			Name string `json:"name"`
+			Name        string   `json:"name"`
+			Description string   `json:"description,omitempty"`
+			Standards   []string `json:"standards,omitempty"` // ACEA/API/ILSAC levels, when present
+			Approvals   []string `json:"approvals,omitempty"` // OEM approvals (VW 504.00, MB 229.5...), when present
 		} `json:"products"`
 	} `json:"recommendations"`
 }
 
 // MotulClient handles communication with Motul API
 type MotulClient struct {
-	httpClient  *http.Client
-	rateLimiter *RateLimiter
-	retryConfig RetryConfig
-}
-
-// RetryConfig defines retry behavior
-type RetryConfig struct {
-	MaxRetries     int
-	InitialBackoff time.Duration
-	MaxBackoff     time.Duration
-	Multiplier     float64
+	httpClient     *http.Client
+	rateLimiter    *RateLimiter
+	throttle       *AdaptiveThrottle // Slows rateLimiter on 429s, speeds it back up on success
+	retryConfig    RetryConfig
+	timeout        time.Duration // Per-request timeout for spec fetches
+	catalogTimeout time.Duration // Per-request timeout for catalog listing endpoints
+	observer       RequestObserver
+
+	// Schema drift detection
+	driftCount   atomic.Int64
+	driftSamples schemaDriftSamples
+
+	cache     *HTTPCache // Optional on-disk response cache
+	cacheHits atomic.Int64
 }
 
 // NewMotulClient creates a new Motul API client
 func NewMotulClient(rateLimit float64) *MotulClient {
+	rateLimiter := NewRateLimiter(rateLimit)
 	return &MotulClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		rateLimiter: NewRateLimiter(rateLimit),
-		retryConfig: RetryConfig{
-			MaxRetries:     5,
-			InitialBackoff: 1 * time.Second,
-			MaxBackoff:     30 * time.Second,
-			Multiplier:     2.0,
-		},
+		httpClient:     telemetry.NewHTTPClient(0),
+		rateLimiter:    rateLimiter,
+		throttle:       NewAdaptiveThrottle(rateLimiter, rateLimit/10),
+		retryConfig:    DefaultRetryConfig(5),
+		timeout:        30 * time.Second,
+		catalogTimeout: 120 * time.Second,
+	}
+}
+
+// SetHTTPConfig sets timeout and retry behavior. catalogTimeout applies to the
+// brand/model/type listing endpoints, which are slower than spec fetches.
+func (c *MotulClient) SetHTTPConfig(cfg HTTPConfig, catalogTimeout time.Duration) {
+	c.timeout = cfg.Timeout
+	c.catalogTimeout = catalogTimeout
+	c.retryConfig = cfg.Retry
+}
+
+// SetRateLimit changes the request rate and how many requests may burst after idle time
+func (c *MotulClient) SetRateLimit(requestsPerSecond float64, burst int) {
+	c.throttle.SetBaseRate(requestsPerSecond)
+	c.rateLimiter.SetBurst(burst)
+}
+
+// EffectiveRate returns the current request rate after adaptive throttling
+func (c *MotulClient) EffectiveRate() float64 {
+	return c.throttle.EffectiveRate()
+}
+
+// SetCache enables the on-disk response cache for all GET endpoints
+func (c *MotulClient) SetCache(cache *HTTPCache) {
+	c.cache = cache
+}
+
+// CacheHits returns how many responses were served from the cache, either
+// fresh or revalidated with a 304
+func (c *MotulClient) CacheHits() int64 {
+	return c.cacheHits.Load()
+}
+
+// SetObserver sets the observer notified about rate limits and network errors
+func (c *MotulClient) SetObserver(observer RequestObserver) {
+	c.observer = observer
+}
+
+// SetSchemaDriftSamples enables capturing up to max drifted responses as JSON
+// files in dir, so a Motul API change can be inspected after the run
+func (c *MotulClient) SetSchemaDriftSamples(dir string, max int) {
+	c.driftSamples.mu.Lock()
+	defer c.driftSamples.mu.Unlock()
+	c.driftSamples.dir = dir
+	c.driftSamples.max = max
+}
+
+// SchemaDriftCount returns how many responses did not match the expected shape
+func (c *MotulClient) SchemaDriftCount() int64 {
+	return c.driftCount.Load()
+}
+
+// checkSchema validates a response body, recording drift. Missing fields and
+// unexpected nulls return a *SchemaDriftError; unknown component codes are
+// only recorded, since the remaining components are still usable.
+func (c *MotulClient) checkSchema(endpoint, url string, body []byte) error {
+	problems, unknown := validateMotulResponse(endpoint, body)
+	if len(problems) == 0 && len(unknown) == 0 {
+		return nil
+	}
+
+	reported := append(problems, unknown...)
+	c.driftCount.Add(1)
+	if observer, ok := c.observer.(SchemaDriftObserver); ok {
+		observer.OnSchemaDrift(ServiceMotul, endpoint, reported)
+	}
+	_ = c.driftSamples.save(endpoint, url, reported, body) // Sample capture is best effort
+
+	if len(problems) > 0 {
+		return &SchemaDriftError{Endpoint: endpoint, Problems: problems}
 	}
+	return nil
 }
 
-// fetchWithRetry performs HTTP request with retry logic
-func (c *MotulClient) fetchWithRetry(ctx context.Context, url string) ([]byte, error) {
+// fetch returns the validated body for url, using the response cache when
+// enabled. Only schema-valid responses are cached.
+func (c *MotulClient) fetch(ctx context.Context, endpoint, url string, timeout time.Duration) ([]byte, error) {
+	var cached *cacheEntry
+	if c.cache != nil {
+		entry, fresh := c.cache.get(url)
+		if fresh {
+			c.cacheHits.Add(1)
+			return entry.Body, nil
+		}
+		cached = entry
+	}
+
+	result, err := c.fetchWithRetry(ctx, url, timeout, cached.conditionalHeaders())
+	if err != nil {
+		return nil, err
+	}
+
+	if result.StatusCode == http.StatusNotModified && cached != nil {
+		c.cacheHits.Add(1)
+		_ = c.cache.touch(cached) // A failed write only costs a later revalidation
+		return cached.Body, nil
+	}
+
+	if err := c.checkSchema(endpoint, url, result.Body); err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		_ = c.cache.put(url, result.Header, result.Body)
+	}
+	return result.Body, nil
+}
+
+// fetchWithRetry performs HTTP request with retry logic. A 304 is returned as
+// success so the caller can serve its cached copy.
+func (c *MotulClient) fetchWithRetry(ctx context.Context, url string, timeout time.Duration, header http.Header) (*httpResult, error) {
 	backoff := c.retryConfig.InitialBackoff
 
 	for attempt := 0; attempt <= c.retryConfig.MaxRetries; attempt++ {
@@ -123,54 +256,99 @@ func (c *MotulClient) fetchWithRetry(ctx context.Context, url string) ([]byte, e
 			return nil, err
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		resp, err := c.httpClient.Do(req)
+		statusCode, respHeader, body, err := c.fetchOnce(ctx, url, timeout, header)
 		if err != nil {
+			if c.observer != nil && ctx.Err() == nil {
+				c.observer.OnNetworkError(ServiceMotul, err)
+			}
 			if attempt < c.retryConfig.MaxRetries {
-				time.Sleep(backoff)
-				backoff = min(time.Duration(float64(backoff)*c.retryConfig.Multiplier), c.retryConfig.MaxBackoff)
+				if err := sleepContext(ctx, backoff); err != nil {
+					return nil, err
+				}
+				backoff = c.retryConfig.nextBackoff(backoff)
 				continue
 			}
 			return nil, fmt.Errorf("request failed after %d attempts: %w", attempt+1, err)
 		}
 
-		defer resp.Body.Close()
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
+		// Success
+		if statusCode == 200 || statusCode == http.StatusNotModified {
+			c.throttle.OnSuccess()
+			return &httpResult{StatusCode: statusCode, Header: respHeader, Body: body}, nil
 		}
 
-		// Success
-		if resp.StatusCode == 200 {
-			return body, nil
+		retryAfter, hasRetryAfter := parseRetryAfter(respHeader, time.Now())
+		if statusCode == 429 {
+			c.throttle.OnThrottled(retryAfter)
+			if c.observer != nil {
+				c.observer.OnRateLimited(ServiceMotul)
+			}
 		}
 
 		// Retry on 429, 500, 502, 503
-		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+		if statusCode == 429 || statusCode >= 500 {
 			if attempt < c.retryConfig.MaxRetries {
-				time.Sleep(backoff)
-				backoff = min(time.Duration(float64(backoff)*c.retryConfig.Multiplier), c.retryConfig.MaxBackoff)
+				// Honor the server's Retry-After over our own backoff
+				wait := backoff
+				if hasRetryAfter {
+					wait = retryAfter
+				}
+				if err := sleepContext(ctx, wait); err != nil {
+					return nil, err
+				}
+				backoff = c.retryConfig.nextBackoff(backoff)
 				continue
 			}
 		}
 
 		// Non-retryable error
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("request failed with status %d: %s", statusCode, string(body))
 	}
 
 	return nil, fmt.Errorf("max retries exceeded")
 }
 
+// fetchOnce performs a single GET request bounded by timeout and reads the body
+func (c *MotulClient) fetchOnce(ctx context.Context, url string, timeout time.Duration, header http.Header) (int, http.Header, []byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.StatusCode, resp.Header, body, nil
+}
+
 // GetBrands fetches all car brands from Motul
 func (c *MotulClient) GetBrands(ctx context.Context) ([]Brand, error) {
-	url := fmt.Sprintf("%s/vehicle-brands?categoryId=CAR&locale=%s&BU=%s",
-		motulAPIBase, locale, businessUnit)
+	return c.GetBrandsByCategory(ctx, CategoryCar)
+}
 
-	body, err := c.fetchWithRetry(ctx, url)
+// GetBrandsByCategory fetches all brands of a vehicle category (CategoryCar, CategoryMotorcycle...)
+func (c *MotulClient) GetBrandsByCategory(ctx context.Context, category string) ([]Brand, error) {
+	url := fmt.Sprintf("%s/vehicle-brands?categoryId=%s&locale=%s&BU=%s",
+		motulAPIBase, category, locale, businessUnit)
+
+	body, err := c.fetch(ctx, EndpointBrands, url, c.catalogTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +366,7 @@ func (c *MotulClient) GetModels(ctx context.Context, brandID string, year int) (
 	url := fmt.Sprintf("%s/vehicle-models?vehicleBrandId=%s&year=%d&locale=%s&BU=%s",
 		motulAPIBase, brandID, year, locale, businessUnit)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetch(ctx, EndpointModels, url, c.catalogTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +384,7 @@ func (c *MotulClient) GetVehicleTypes(ctx context.Context, modelID string) ([]Ve
 	url := fmt.Sprintf("%s/vehicle-types?vehicleModelId=%s&locale=%s&BU=%s",
 		motulAPIBase, modelID, locale, businessUnit)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetch(ctx, EndpointVehicleTypes, url, c.catalogTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +402,7 @@ func (c *MotulClient) GetSpecifications(ctx context.Context, vehicleTypeID strin
 	url := fmt.Sprintf("%s/recommendations?vehicleTypeId=%s&locale=%s&BU=%s",
 		motulAPIBase, vehicleTypeID, locale, businessUnit)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetch(ctx, EndpointRecommendations, url, c.timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -241,10 +419,3 @@ func (c *MotulClient) GetSpecifications(ctx context.Context, vehicleTypeID strin
 func (c *MotulClient) Close() {
 	c.rateLimiter.Stop()
 }
-
-func min(a, b time.Duration) time.Duration {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,235 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Motul endpoints checked for schema drift
+const (
+	EndpointBrands          = "vehicle-brands"
+	EndpointModels          = "vehicle-models"
+	EndpointVehicleTypes    = "vehicle-types"
+	EndpointRecommendations = "recommendations"
+)
+
+// maxDriftProblems caps how many problems are reported per response
+const maxDriftProblems = 10
+
+// ErrSchemaDrift is wrapped by errors for responses that no longer match the
+// expected Motul JSON shape
+var ErrSchemaDrift = errors.New("schema drift")
+
+// SchemaDriftError describes a response missing required fields or carrying
+// unexpected nulls
+type SchemaDriftError struct {
+	Endpoint string
+	Problems []string
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("Motul API schema drift on %s: %s", e.Endpoint, strings.Join(e.Problems, "; "))
+}
+
+// Unwrap makes errors.Is(err, ErrSchemaDrift) work
+func (e *SchemaDriftError) Unwrap() error {
+	return ErrSchemaDrift
+}
+
+// schemaDriftSamples captures raw drifted responses for later inspection
+type schemaDriftSamples struct {
+	mu    sync.Mutex
+	dir   string
+	max   int
+	saved int
+}
+
+// driftSample is the on-disk format of a captured response
+type driftSample struct {
+	Endpoint   string          `json:"endpoint"`
+	URL        string          `json:"url"`
+	Problems   []string        `json:"problems"`
+	CapturedAt time.Time       `json:"captured_at"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// save writes a sample unless the directory is unset or the cap was reached
+func (s *schemaDriftSamples) save(endpoint, url string, problems []string, body []byte) error {
+	s.mu.Lock()
+	if s.dir == "" || s.saved >= s.max {
+		s.mu.Unlock()
+		return nil
+	}
+	s.saved++
+	s.mu.Unlock()
+
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+	data, err := json.MarshalIndent(driftSample{
+		Endpoint:   endpoint,
+		URL:        url,
+		Problems:   problems,
+		CapturedAt: time.Now(),
+		Body:       body,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("drift-%s-%d.json", endpoint, time.Now().UnixNano())
+	return os.WriteFile(filepath.Join(s.dir, name), data, 0644)
+}
+
+// validateMotulResponse checks a raw response body against the shape the
+// client decodes. It returns hard problems (missing fields, wrong types,
+// unexpected nulls) and soft ones (component codes without a model.Fluido*
+// constant) separately.
+func validateMotulResponse(endpoint string, body []byte) (problems, unknown []string) {
+	var root any
+	if err := json.Unmarshal(body, &root); err != nil {
+		return []string{"body is not valid JSON"}, nil
+	}
+
+	c := &shapeChecker{}
+	obj := c.object(root, "$")
+
+	switch endpoint {
+	case EndpointBrands:
+		c.idNameList(obj, "brands")
+	case EndpointModels:
+		c.idNameList(obj, "models")
+	case EndpointVehicleTypes:
+		c.idNameList(obj, "types")
+	case EndpointRecommendations:
+		vehicle := c.object(c.field(obj, "vehicle", "$"), "vehicle")
+		for i, item := range c.array(c.field(vehicle, "components", "vehicle"), "vehicle.components") {
+			path := fmt.Sprintf("vehicle.components[%d]", i)
+			comp := c.object(item, path)
+
+			category := c.object(c.field(comp, "category", path), path+".category")
+			code := c.str(c.field(category, "code", path+".category"), path+".category.code")
+			c.str(c.field(category, "name", path+".category"), path+".category.name")
+			if code != "" && !model.IsTipoFluidoCode(code) {
+				c.unknown = append(c.unknown, "unknown component code "+code)
+			}
+
+			for j, rec := range c.optionalArray(comp, "recommendations", path) {
+				recPath := fmt.Sprintf("%s.recommendations[%d]", path, j)
+				for k, prod := range c.optionalArray(c.object(rec, recPath), "products", recPath) {
+					prodPath := fmt.Sprintf("%s.products[%d]", recPath, k)
+					c.str(c.field(c.object(prod, prodPath), "name", prodPath), prodPath+".name")
+				}
+			}
+			c.optionalArray(comp, "capacities", path)
+		}
+	}
+
+	return c.problems, c.unknown
+}
+
+// shapeChecker walks decoded JSON collecting problems; every helper tolerates
+// nil input so a missing parent is reported once
+type shapeChecker struct {
+	problems []string
+	unknown  []string
+}
+
+func (c *shapeChecker) add(format string, args ...any) {
+	if len(c.problems) < maxDriftProblems {
+		c.problems = append(c.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+// field returns obj[key], reporting a missing key
+func (c *shapeChecker) field(obj map[string]any, key, path string) any {
+	if obj == nil {
+		return nil
+	}
+	v, ok := obj[key]
+	if !ok {
+		c.add("%s.%s missing", path, key)
+		return nil
+	}
+	if v == nil {
+		c.add("%s.%s is null", path, key)
+	}
+	return v
+}
+
+// object asserts v is a JSON object
+func (c *shapeChecker) object(v any, path string) map[string]any {
+	if v == nil {
+		return nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		c.add("%s is not an object", path)
+	}
+	return obj
+}
+
+// array asserts v is a JSON array
+func (c *shapeChecker) array(v any, path string) []any {
+	if v == nil {
+		return nil
+	}
+	arr, ok := v.([]any)
+	if !ok {
+		c.add("%s is not an array", path)
+	}
+	return arr
+}
+
+// optionalArray returns obj[key] when present, reporting null or a non-array
+func (c *shapeChecker) optionalArray(obj map[string]any, key, path string) []any {
+	if obj == nil {
+		return nil
+	}
+	v, ok := obj[key]
+	if !ok {
+		return nil
+	}
+	if v == nil {
+		c.add("%s.%s is null", path, key)
+		return nil
+	}
+	return c.array(v, path+"."+key)
+}
+
+// str asserts v is a non-empty string
+func (c *shapeChecker) str(v any, path string) string {
+	if v == nil {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		c.add("%s is not a string", path)
+		return ""
+	}
+	if s == "" {
+		c.add("%s is empty", path)
+	}
+	return s
+}
+
+// idNameList checks a {key: [{id, name}]} listing response
+func (c *shapeChecker) idNameList(obj map[string]any, key string) {
+	for i, item := range c.array(c.field(obj, key, "$"), key) {
+		path := fmt.Sprintf("%s[%d]", key, i)
+		entry := c.object(item, path)
+		c.str(c.field(entry, "id", path), path+".id")
+		c.str(c.field(entry, "name", path), path+".name")
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import "time"
+
+// RequestObserver receives notifications about external API request outcomes
+// It lets callers (e.g. the scraper progress tracker) count rate-limit hits and
+// network errors without the clients knowing about them
+type RequestObserver interface {
+	// OnRateLimited is called every time a service answers with HTTP 429
+	OnRateLimited(service string)
+
+	// OnNetworkError is called when a request fails at the transport level
+	OnNetworkError(service string, err error)
+}
+
+// Observable is implemented by clients that report request outcomes
+type Observable interface {
+	SetObserver(observer RequestObserver)
+}
+
+// Service names reported to RequestObserver
+const (
+	ServiceMotul  = "motul"
+	ServiceGroq   = "groq"
+	ServiceOllama = "ollama"
+	ServiceGemini = "gemini"
+)
+
+// Ensure clients implement Observable
+var _ Observable = (*MotulClient)(nil)
+var _ Observable = (*GroqClient)(nil)
+var _ Observable = (*OllamaClient)(nil)
+var _ Observable = (*GeminiClient)(nil)
+var _ Observable = (*ChainClient)(nil)
+
+// SchemaDriftObserver is optionally implemented by a RequestObserver that wants
+// to be told when a response no longer matches the expected shape
+type SchemaDriftObserver interface {
+	// OnSchemaDrift is called with the endpoint and the problems found
+	OnSchemaDrift(service, endpoint string, problems []string)
+}
+
+// KeyExhaustionObserver is optionally implemented by a RequestObserver that
+// wants to be told when every API key of a provider hit its daily limit
+type KeyExhaustionObserver interface {
+	// OnKeysExhausted is called with the time the keys reset; it may be
+	// called again for the same exhaustion and must not block
+	OnKeysExhausted(service string, until time.Time)
+}
+
+// TokenUsage is the token count of one successful LLM request
+type TokenUsage struct {
+	Service          string
+	Key              string // KeyLabel of the API key used; empty for keyless providers (Ollama)
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total returns prompt + completion tokens
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// TokenUsageObserver is optionally implemented by a RequestObserver that wants
+// to count LLM tokens per successful request
+type TokenUsageObserver interface {
+	OnTokensUsed(usage TokenUsage)
+}
+
+// KeyLabel identifies an API key in reports without revealing it: only its
+// last 4 characters are kept
+func KeyLabel(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return "..." + key[len(key)-4:]
+}
+
+// reportTokens notifies observer of token usage if it implements TokenUsageObserver
+func reportTokens(observer RequestObserver, usage TokenUsage) {
+	if o, ok := observer.(TokenUsageObserver); ok && usage.Total() > 0 {
+		o.OnTokensUsed(usage)
+	}
+}
+
+// reportKeysExhausted notifies observer that all keys are exhausted if it
+// implements KeyExhaustionObserver
+func reportKeysExhausted(observer RequestObserver, service string, until time.Time) {
+	if o, ok := observer.(KeyExhaustionObserver); ok {
+		o.OnKeysExhausted(service, until)
+	}
+}
@@ -1,7 +1,6 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"wega-catalog-api/internal/telemetry"
 )
 
 const (
@@ -22,6 +23,8 @@ type OllamaClient struct {
 	baseURL    string
 	model      string
 	logger     *slog.Logger
+	observer   RequestObserver
+	httpConfig HTTPConfig
 }
 
 // OllamaChatRequest represents an Ollama chat API request
@@ -29,6 +32,7 @@ type OllamaChatRequest struct {
 	Model    string          `json:"model"`
 	Messages []OllamaMessage `json:"messages"`
 	Stream   bool            `json:"stream"`
+	Format   any             `json:"format,omitempty"` // "json" or a JSON schema the reply must follow
 	Options  OllamaOptions   `json:"options,omitempty"`
 }
 
@@ -71,8 +75,10 @@ func NewOllamaClient(baseURL string, model string, logger *slog.Logger) *OllamaC
 	baseURL = strings.TrimRight(baseURL, "/")
 
 	client := &OllamaClient{
-		httpClient: &http.Client{
+		httpClient: telemetry.NewHTTPClient(0),
+		httpConfig: HTTPConfig{
 			Timeout: 60 * time.Second, // Longer timeout for local inference
+			Retry:   DefaultRetryConfig(0),
 		},
 		baseURL: baseURL,
 		model:   model,
@@ -87,71 +93,67 @@ func NewOllamaClient(baseURL string, model string, logger *slog.Logger) *OllamaC
 	return client
 }
 
+// SetHTTPConfig sets the per-request timeout and retry behavior for transport errors and 5xx
+func (c *OllamaClient) SetHTTPConfig(cfg HTTPConfig) {
+	c.httpConfig = cfg
+}
+
+// SetObserver sets the observer notified about network errors
+func (c *OllamaClient) SetObserver(observer RequestObserver) {
+	c.observer = observer
+}
+
 // systemPrompt is the robust system prompt for vehicle matching
-const systemPrompt = `Reply with ONLY a number (1-9). Match vehicle to best option based on:
+const systemPrompt = `Reply with ONLY JSON: ` + choiceFormat + `
+Match vehicle to best option based on:
 - Engine type: TURBO/TSI/T200/THP must match turbo options, naturally aspirated must match non-turbo
 - Engine size: 1.0, 1.4, 2.0 etc should match closely
 - Power (cv/hp): match as closely as possible
 - Fuel: Flex/Diesel/Gasoline should match when possible
-If no good match, reply 0.`
+If no good match, choice is 0.`
 
 // NormalizeVehicle uses LLM to find the best match from Motul options
 func (c *OllamaClient) NormalizeVehicle(ctx context.Context, wegaVehicle string, motulOptions []string) (string, error) {
+	match, _, err := c.NormalizeVehicleConfidence(ctx, wegaVehicle, motulOptions)
+	return match, err
+}
+
+// NormalizeVehicleConfidence finds the best match and the model's confidence
+// in it. The reply is constrained to choiceSchema, so any number of options
+// works. Returns ErrNoMatch when the model picks none.
+func (c *OllamaClient) NormalizeVehicleConfidence(ctx context.Context, wegaVehicle string, motulOptions []string) (string, float64, error) {
 	if len(motulOptions) == 0 {
-		return "", fmt.Errorf("no Motul options provided")
+		return "", 0, fmt.Errorf("no Motul options provided")
 	}
 
 	// If only one option, return it directly (no LLM needed)
 	if len(motulOptions) == 1 {
-		return motulOptions[0], nil
-	}
-
-	// Build numbered options list
-	var optionsList strings.Builder
-	for i, opt := range motulOptions {
-		optionsList.WriteString(fmt.Sprintf("%d. %s\n", i+1, opt))
+		return motulOptions[0], 1, nil
 	}
 
 	// Build user prompt
-	userPrompt := fmt.Sprintf("Vehicle: %s\n%s", wegaVehicle, optionsList.String())
+	userPrompt := fmt.Sprintf("Vehicle: %s\n%s", wegaVehicle, numberedOptions(motulOptions))
 
 	// Make request
-	response, err := c.doRequest(ctx, systemPrompt, userPrompt)
+	response, err := c.doRequest(ctx, systemPrompt, userPrompt, choiceSchema(len(motulOptions)))
 	if err != nil {
-		return "", err
-	}
-
-	// Parse the response number
-	response = strings.TrimSpace(response)
-
-	// Try to extract first digit from response
-	var optionNum int
-	for _, char := range response {
-		if char >= '0' && char <= '9' {
-			optionNum = int(char - '0')
-			break
-		}
+		return "", 0, err
 	}
 
-	if optionNum == 0 {
-		// LLM indicated no match or failed to parse - use smart fallback
-		c.logger.Warn("LLM response not a valid number, using smart fallback",
-			"response", response,
+	choice, err := parseChoice(response, len(motulOptions))
+	if err != nil {
+		// Unreadable reply - use smart fallback
+		c.logger.Warn("LLM response not a valid choice, using smart fallback",
+			"error", err,
 			"wega_vehicle", wegaVehicle,
 		)
-		return c.smartFallback(wegaVehicle, motulOptions), nil
+		return c.smartFallback(wegaVehicle, motulOptions), fallbackConfidence, nil
 	}
-
-	// Validate option number
-	if optionNum > len(motulOptions) {
-		c.logger.Warn("invalid option number from LLM, using smart fallback",
-			"option_num", optionNum,
-			"total_options", len(motulOptions),
-		)
-		return c.smartFallback(wegaVehicle, motulOptions), nil
+	if choice.Choice == 0 {
+		return "", choice.Confidence, ErrNoMatch
 	}
 
-	return motulOptions[optionNum-1], nil
+	return motulOptions[choice.Choice-1], choice.Confidence, nil
 }
 
 // smartFallback selects the best option based on turbo/aspirated engine detection
@@ -222,8 +224,8 @@ func (c *OllamaClient) smartFallback(wegaVehicle string, motulOptions []string)
 	return motulOptions[0]
 }
 
-// doRequest makes a chat request to Ollama
-func (c *OllamaClient) doRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+// doRequest makes a chat request to Ollama whose reply follows the JSON schema
+func (c *OllamaClient) doRequest(ctx context.Context, systemPrompt, userPrompt string, schema map[string]any) (string, error) {
 	req := OllamaChatRequest{
 		Model: c.model,
 		Messages: []OllamaMessage{
@@ -231,9 +233,10 @@ func (c *OllamaClient) doRequest(ctx context.Context, systemPrompt, userPrompt s
 			{Role: "user", Content: userPrompt},
 		},
 		Stream: false,
+		Format: schema,
 		Options: OllamaOptions{
 			Temperature: 0.0, // Deterministic output
-			NumPredict:  3,   // Very short response (just a number)
+			NumPredict:  32,  // Short response (just the choice object)
 		},
 	}
 
@@ -244,24 +247,16 @@ func (c *OllamaClient) doRequest(ctx context.Context, systemPrompt, userPrompt s
 
 	url := c.baseURL + "/api/chat"
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-
 	startTime := time.Now()
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	resp, err := doJSONWithRetry(ctx, c.httpClient, c.httpConfig, url, reqBody, nil, func(err error) {
+		if c.observer != nil {
+			c.observer.OnNetworkError(ServiceOllama, err)
+		}
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", err
 	}
+	body := resp.Body
 
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
@@ -282,6 +277,11 @@ func (c *OllamaClient) doRequest(ctx context.Context, systemPrompt, userPrompt s
 		"prompt_tokens", ollamaResp.PromptEvalCount,
 		"eval_tokens", ollamaResp.EvalCount,
 	)
+	reportTokens(c.observer, TokenUsage{
+		Service:          ServiceOllama,
+		PromptTokens:     ollamaResp.PromptEvalCount,
+		CompletionTokens: ollamaResp.EvalCount,
+	})
 
 	return ollamaResp.Message.Content, nil
 }
@@ -324,6 +324,12 @@ func (c *OllamaClient) FindBestModel(ctx context.Context, wegaModel string, motu
 func (c *OllamaClient) Ping(ctx context.Context) error {
 	url := c.baseURL + "/api/tags"
 
+	if c.httpConfig.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.httpConfig.Timeout)
+		defer cancel()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
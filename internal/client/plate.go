@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrPlateNotFound is returned when the provider has no vehicle for the plate
+var ErrPlateNotFound = errors.New("plate not found")
+
+// PlateVehicle is the registered vehicle of a license plate
+type PlateVehicle struct {
+	Plate     string
+	Brand     string // As registered, e.g. "VW" or "I/BMW"
+	Model     string // Often with the engine, e.g. "GOL 1.0 12V MPI TOTALFLEX"
+	Version   string
+	Year      int // Manufacturing year
+	ModelYear int
+	Fuel      string // Free text, e.g. "ALCOOL/GASOLINA"
+}
+
+// PlateLookup resolves a Brazilian license plate to its registered vehicle.
+// HTTPPlateLookup implements it for JSON APIs.
+type PlateLookup interface {
+	// LookupPlate returns ErrPlateNotFound when the provider knows no vehicle for plate
+	LookupPlate(ctx context.Context, plate string) (*PlateVehicle, error)
+}
+
+// PlateFields are the dotted JSON paths of each vehicle field in the
+// provider's response ("dados.marca"). Several paths may be given for a field,
+// separated by "|"; the first one present wins.
+type PlateFields struct {
+	Brand     string
+	Model     string
+	Version   string
+	Year      string
+	ModelYear string
+	Fuel      string
+}
+
+// DefaultPlateFields match the field names most Brazilian plate APIs use
+var DefaultPlateFields = PlateFields{
+	Brand:     "marca|MARCA|brand",
+	Model:     "modelo|MODELO|model",
+	Version:   "versao|VERSAO|submodelo|SUBMODELO|version",
+	Year:      "ano|anoFabricacao|ano_fabricacao|ANO|year",
+	ModelYear: "anoModelo|ano_modelo|ANOMODELO|model_year",
+	Fuel:      "combustivel|COMBUSTIVEL|fuel",
+}
+
+// HTTPPlateConfig configures an HTTPPlateLookup
+type HTTPPlateConfig struct {
+	// URLTemplate is the lookup URL with a {placa} placeholder,
+	// e.g. "https://api.example.com/v1/placa/{placa}"
+	URLTemplate string
+	AuthHeader  string // Header carrying the token (default Authorization)
+	AuthScheme  string // Prefix of the token in AuthHeader, e.g. "Bearer" (empty = raw token)
+	Token       string
+	Fields      PlateFields // Zero fields use DefaultPlateFields
+	Timeout     time.Duration
+	// RequestsPerMinute caps the calls to the provider (0 = unlimited); lookups
+	// wait for a slot until their context ends
+	RequestsPerMinute int
+}
+
+// HTTPPlateLookup looks plates up in a configurable JSON API: a GET to the
+// URL template, a 404 (or an empty brand and model) meaning not found
+type HTTPPlateLookup struct {
+	httpClient *http.Client
+	cfg        HTTPPlateConfig
+	limiter    *RateLimiter
+}
+
+// NewHTTPPlateLookup creates a plate lookup client for cfg
+func NewHTTPPlateLookup(cfg HTTPPlateConfig) *HTTPPlateLookup {
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = "Authorization"
+	}
+	defaults := DefaultPlateFields
+	for _, f := range []struct {
+		field *string
+		def   string
+	}{
+		{&cfg.Fields.Brand, defaults.Brand},
+		{&cfg.Fields.Model, defaults.Model},
+		{&cfg.Fields.Version, defaults.Version},
+		{&cfg.Fields.Year, defaults.Year},
+		{&cfg.Fields.ModelYear, defaults.ModelYear},
+		{&cfg.Fields.Fuel, defaults.Fuel},
+	} {
+		if *f.field == "" {
+			*f.field = f.def
+		}
+	}
+
+	l := &HTTPPlateLookup{
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		cfg:        cfg,
+	}
+	if cfg.RequestsPerMinute > 0 {
+		l.limiter = NewRateLimiterWithBurst(float64(cfg.RequestsPerMinute)/60, max(cfg.RequestsPerMinute/6, 1))
+	}
+	return l
+}
+
+// LookupPlate implements PlateLookup
+func (l *HTTPPlateLookup) LookupPlate(ctx context.Context, plate string) (*PlateVehicle, error) {
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("plate API rate limit: %w", err)
+		}
+	}
+
+	lookupURL := strings.ReplaceAll(l.cfg.URLTemplate, "{placa}", url.PathEscape(plate))
+	req, err := http.NewRequestWithContext(ctx, "GET", lookupURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if l.cfg.Token != "" {
+		token := l.cfg.Token
+		if l.cfg.AuthScheme != "" {
+			token = l.cfg.AuthScheme + " " + token
+		}
+		req.Header.Set(l.cfg.AuthHeader, token)
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPlateNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("plate API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	vehicle := &PlateVehicle{
+		Plate:     plate,
+		Brand:     lookupString(data, l.cfg.Fields.Brand),
+		Model:     lookupString(data, l.cfg.Fields.Model),
+		Version:   lookupString(data, l.cfg.Fields.Version),
+		Year:      lookupYear(data, l.cfg.Fields.Year),
+		ModelYear: lookupYear(data, l.cfg.Fields.ModelYear),
+		Fuel:      lookupString(data, l.cfg.Fields.Fuel),
+	}
+	if vehicle.Brand == "" && vehicle.Model == "" {
+		return nil, ErrPlateNotFound
+	}
+	return vehicle, nil
+}
+
+// lookupString returns the first of the "|"-separated dotted paths present in
+// data, formatted as text
+func lookupString(data map[string]any, paths string) string {
+	for _, path := range strings.Split(paths, "|") {
+		var value any = data
+		for _, key := range strings.Split(strings.TrimSpace(path), ".") {
+			obj, ok := value.(map[string]any)
+			if !ok {
+				value = nil
+				break
+			}
+			value = obj[key]
+		}
+
+		switch v := value.(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				return s
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// lookupYear reads a year field, accepting "2020" and "2019/2020" (the last one)
+func lookupYear(data map[string]any, paths string) int {
+	text := lookupString(data, paths)
+	if i := strings.LastIndex(text, "/"); i >= 0 {
+		text = text[i+1:]
+	}
+	year, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || year < 1900 || year > 2100 {
+		return 0
+	}
+	return year
+}
+
+// Ensure HTTPPlateLookup implements PlateLookup
+var _ PlateLookup = (*HTTPPlateLookup)(nil)
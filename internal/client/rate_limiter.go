@@ -2,48 +2,163 @@ package client
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 )
 
-// RateLimiter controls request rate
+// ErrRateLimiterStopped is returned by Wait after Stop
+var ErrRateLimiterStopped = errors.New("rate limiter stopped")
+
+// RateLimiter is a token bucket: tokens refill continuously at the configured
+// rate up to the burst size, so idle time is banked (up to burst) instead of
+// lost. The rate can be changed while requests are waiting.
 type RateLimiter struct {
-	ticker   *time.Ticker
-	requests chan struct{}
+	mu     sync.Mutex
+	rate   float64 // Tokens per second (<= 0 = unlimited)
+	burst  int
+	tokens float64
+	last   time.Time
+
+	stopped  chan struct{}
+	stopOnce sync.Once
 }
 
-// NewRateLimiter creates a rate limiter with specified rate
+// NewRateLimiter creates a rate limiter with specified rate and no burst
 func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
-	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+	return NewRateLimiterWithBurst(requestsPerSecond, 1)
+}
 
-	rl := &RateLimiter{
-		ticker:   time.NewTicker(interval),
-		requests: make(chan struct{}),
+// NewRateLimiterWithBurst creates a rate limiter allowing up to burst requests
+// back to back after an idle period
+func NewRateLimiterWithBurst(requestsPerSecond float64, burst int) *RateLimiter {
+	burst = max(burst, 1)
+	return &RateLimiter{
+		rate:    requestsPerSecond,
+		burst:   burst,
+		tokens:  float64(burst),
+		last:    time.Now(),
+		stopped: make(chan struct{}),
 	}
+}
 
-	go func() {
-		for range rl.ticker.C {
-			select {
-			case rl.requests <- struct{}{}:
-			default:
-			}
-		}
-	}()
-
-	return rl
+// refill adds tokens earned since the last update (caller holds mu)
+func (rl *RateLimiter) refill(now time.Time) {
+	if rl.rate > 0 {
+		rl.tokens = min(rl.tokens+now.Sub(rl.last).Seconds()*rl.rate, float64(rl.burst))
+	}
+	rl.last = now
 }
 
 // Wait blocks until rate limit allows next request
 func (rl *RateLimiter) Wait(ctx context.Context) error {
+	return rl.Reserve().Wait(ctx)
+}
+
+// Reservation is a token taken by Reserve, usable once its delay has passed
+type Reservation struct {
+	rl    *RateLimiter
+	delay time.Duration
+}
+
+// Reserve takes a token now (possibly going negative, so callers are served in
+// order) without blocking. Callers that pick between limiters reserve under
+// their own lock and wait on the reservation after releasing it.
+func (rl *RateLimiter) Reserve() *Reservation {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.rate <= 0 {
+		return &Reservation{rl: rl}
+	}
+	rl.refill(time.Now())
+
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return &Reservation{rl: rl}
+	}
+	return &Reservation{rl: rl, delay: time.Duration(-rl.tokens / rl.rate * float64(time.Second))}
+}
+
+// Delay returns how long the reserved token is held back
+func (r *Reservation) Delay() time.Duration {
+	return r.delay
+}
+
+// Wait blocks until the reserved token is usable; on cancellation the token
+// is returned to the bucket
+func (r *Reservation) Wait(ctx context.Context) error {
 	select {
-	case <-rl.requests:
+	case <-r.rl.stopped:
+		return ErrRateLimiterStopped
+	default:
+	}
+	if r.delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(r.delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
 		return nil
 	case <-ctx.Done():
+		r.rl.cancelReservation()
 		return ctx.Err()
+	case <-r.rl.stopped:
+		return ErrRateLimiterStopped
 	}
 }
 
-// Stop stops the rate limiter
+// cancelReservation returns a token reserved by an abandoned Wait
+func (rl *RateLimiter) cancelReservation() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.tokens = min(rl.tokens+1, float64(rl.burst))
+}
+
+// Delay returns how long a Wait started now would block, without reserving a token
+func (rl *RateLimiter) Delay() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.rate <= 0 {
+		return 0
+	}
+	rl.refill(time.Now())
+	if rl.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+}
+
+// SetRate changes the refill rate (requests per second, <= 0 = unlimited)
+func (rl *RateLimiter) SetRate(requestsPerSecond float64) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refill(time.Now())
+	rl.rate = requestsPerSecond
+}
+
+// SetBurst changes the bucket size
+func (rl *RateLimiter) SetBurst(burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refill(time.Now())
+	rl.burst = max(burst, 1)
+	rl.tokens = min(rl.tokens, float64(rl.burst))
+}
+
+// Rate returns the current refill rate in requests per second
+func (rl *RateLimiter) Rate() float64 {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.rate
+}
+
+// Stop stops the rate limiter; pending and later Waits return
+// ErrRateLimiterStopped. It is safe to call more than once.
 func (rl *RateLimiter) Stop() {
-	rl.ticker.Stop()
-	close(rl.requests)
+	rl.stopOnce.Do(func() {
+		close(rl.stopped)
+	})
 }
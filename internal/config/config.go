@@ -1,14 +1,168 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"wega-catalog-api/internal/model"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	APIPort  string
-	LogLevel string
+	Database    DatabaseConfig
+	APIPort     string
+	LogLevel    string
+	AdminAPIKey string
+	// RequireAPIKey recusa requisicoes publicas sem X-API-Key (sem ele, so chaves enviadas tem cota)
+	RequireAPIKey bool
+	Health        HealthThresholds
+	Completude    CompletudeConfig
+	AoVivo        AoVivoConfig
+	// PrecosLLM estima o custo do consumo de tokens do scraper, por provedor (groq, gemini...)
+	PrecosLLM map[string]model.PrecoLLM
+	// ExportTimeout limita cada export do catalogo (/export e /admin/export), que passa dos 30s das demais rotas
+	ExportTimeout time.Duration
+	// OTLPEndpoint recebe os traces da API (OTLP/HTTP, ex. http://collector:4318); vazio desativa
+	OTLPEndpoint string
+	AccessLog    AccessLogConfig
+	// DebugEndpoints expoe pprof e expvar em /debug/ (com a chave admin)
+	DebugEndpoints bool
+	CORS           CORSConfig
+	Throttle       ThrottleConfig
+	// TrustedProxies sao os CIDRs (ou IPs) dos proxies reversos cujos headers
+	// X-Forwarded-For/X-Real-IP valem como IP do cliente; vazio usa sempre o IP da conexao
+	TrustedProxies []string
+	TLS            TLSConfig
+	// CacheCatalogoTTL mantem fabricantes e tipos de filtro em memoria; 0 desativa
+	CacheCatalogoTTL time.Duration
+	BuscaSemantica   BuscaSemanticaConfig
+	Placa            PlacaConfig
+	SincronizacaoERP SincronizacaoERPConfig
+	FotoProduto      FotoProdutoConfig
+}
+
+// FotoProdutoConfig configura o proxy das fotos de produto em
+// /produtos/{codigo}/foto; sem BaseURL a rota responde 503
+type FotoProdutoConfig struct {
+	// BaseURL e o host de arquivos da Wega onde ficam os ArquivoFotoProduto, ex. https://assets.wega.com.br/fotos/
+	BaseURL  string
+	Timeout  time.Duration
+	CacheTTL time.Duration // Validade das fotos (ja redimensionadas) em memoria; 0 desativa
+	MaxAge   time.Duration // Cache-Control max-age enviado aos navegadores e CDNs
+	// MaxDimensao limita largura e altura pedidas, em pixels
+	MaxDimensao int
+}
+
+// SincronizacaoERPConfig busca periodicamente no ERP o arquivo de precos e
+// estoque (CSV ou JSON, no formato do upload em /admin/produtos/sincronizar)
+type SincronizacaoERPConfig struct {
+	URL       string // Vazio desativa; o upload manual continua disponivel
+	Token     string // Enviado como Authorization: Bearer
+	Intervalo time.Duration
+	Timeout   time.Duration // Limite do download do arquivo
+}
+
+// PlacaConfig configura a consulta de veiculos pela placa em um provedor
+// externo (API JSON); sem URL a rota /veiculos/placa/{placa} responde 503
+type PlacaConfig struct {
+	// URL do provedor com o marcador {placa}, ex. https://api.exemplo.com/placa/{placa}
+	URL        string
+	Token      string
+	AuthHeader string // Header do token (padrao Authorization)
+	AuthScheme string // Prefixo do token no header, ex. Bearer; vazio envia so o token
+	// Campos sobrescreve os caminhos dos campos na resposta ("marca=dados.marca,ano_modelo=dados.anoModelo")
+	Campos  map[string]string
+	Timeout time.Duration
+	// LimiteProvedor limita as chamadas ao provedor por minuto, somando todos os clientes; 0 desativa
+	LimiteProvedor int
+	// LimitePorIP limita as consultas por minuto de cada IP, abaixo do throttle geral; 0 desativa
+	LimitePorIP int
+	CacheTTL    time.Duration // Validade das placas em cache, inclusive as nao encontradas
+}
+
+// BuscaSemanticaConfig ativa a busca de veiculos por embeddings (pgvector). Use
+// o mesmo provedor e modelo do scraper, que consulta os mesmos vetores.
+type BuscaSemanticaConfig struct {
+	Provider        string        // ollama ou openai; vazio desativa (a busca fica so no ILIKE)
+	Modelo          string        // Modelo de embedding; vazio usa o padrao do provedor
+	OllamaURL       string        // Servidor Ollama, com Provider ollama
+	OpenAIAPIKey    string        // Com Provider openai
+	MinSimilaridade float64       // Similaridade de cosseno minima de um resultado
+	Margem          float64       // Resultados a ate essa distancia do mais parecido
+	Intervalo       time.Duration // Intervalo da indexacao das aplicacoes novas ou alteradas
+}
+
+// TLSConfig ativa HTTPS (e HTTP/2) direto na API, para deploys pequenos sem
+// proxy reverso na frente
+type TLSConfig struct {
+	CertFile string // Certificado PEM (com KeyFile)
+	KeyFile  string
+	// AutocertDomains emite os certificados no Let's Encrypt para esses dominios, no lugar de CertFile/KeyFile
+	AutocertDomains  []string
+	AutocertCacheDir string // Certificados emitidos, reaproveitados entre reinicios
+	AutocertEmail    string // Contato da conta ACME (avisos de expiracao)
+	// RedirectPort escuta HTTP e redireciona para HTTPS (e atende o desafio HTTP-01 do autocert); vazio desativa
+	RedirectPort string
+}
+
+// Enabled indica se a API serve HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || len(t.AutocertDomains) > 0
+}
+
+// ThrottleConfig limita as requisicoes publicas por IP e no total, antes da
+// cota por chave de API
+type ThrottleConfig struct {
+	PorIP  int // Requisicoes por minuto de cada IP; 0 desativa
+	Global int // Requisicoes por minuto somando todos os IPs; 0 desativa
+}
+
+// CORSConfig define a politica de CORS da API
+type CORSConfig struct {
+	// AllowedOrigins aceita origens exatas ("https://painel.velure.app.br"),
+	// subdominios ("https://*.velure.app.br") ou "*" para qualquer origem
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool          // Envia Access-Control-Allow-Credentials (cookies e Authorization)
+	MaxAge           time.Duration // Cache do preflight no navegador; 0 omite o header
+}
+
+// AccessLogConfig configura o log de acesso (uma linha por requisicao)
+type AccessLogConfig struct {
+	Format     string  // json ou text
+	SampleRate float64 // Fracao das respostas de sucesso registradas (0 a 1); erros sempre sao
+}
+
+// AoVivoConfig configura a consulta ao vivo de especificacoes (match server do scraper)
+type AoVivoConfig struct {
+	URL      string        // URL base do match server (motul-scraper -serve-match); vazio desativa
+	Timeout  time.Duration // Limite de cada consulta ao vivo
+	CacheTTL time.Duration // Validade das respostas em cache, inclusive sem resultado
+}
+
+// CompletudeConfig configura o relatorio periodico de completude do catalogo
+type CompletudeConfig struct {
+	Intervalo  time.Duration // Intervalo entre relatorios; 0 desativa
+	WebhookURL string        // Recebe cada relatorio gerado; vazio desativa
+	SLA        model.CompletudeSLA
+}
+
+// HealthThresholds define a partir de quando cada verificacao do
+// /admin/system-health vira warning ou critical
+type HealthThresholds struct {
+	// LLMStatusURL e o /llm-status do match server ou do monitor do scraper; vazio desativa a verificacao llm_keys
+	LLMStatusURL            string
+	DBLatencyWarning        time.Duration
+	DBLatencyCritical       time.Duration
+	ScraperAgeWarning       time.Duration // Idade da ultima especificacao gravada pelo scraper
+	ScraperAgeCritical      time.Duration
+	PendingFailuresWarning  int // Falhas do scraper nao resolvidas
+	PendingFailuresCritical int
+	ProviderErrorsWarning   int // Erros de um provedor (Motul, LLM) nas ultimas 24h
+	ProviderErrorsCritical  int
 }
 
 type DatabaseConfig struct {
@@ -20,37 +174,259 @@ type DatabaseConfig struct {
 	SSLMode  string
 	MaxConns int
 	MinConns int
+	// QueryTimeout limita cada consulta da API, alem do prazo da requisicao; 0 desativa
+	QueryTimeout time.Duration
+	// SlowQueryThreshold registra as consultas que passam desse tempo; 0 desativa
+	SlowQueryThreshold time.Duration
+	// ReplicaDSN e a URL de uma replica somente leitura (postgres://...) para
+	// as consultas do catalogo; vazio usa o primario para tudo
+	ReplicaDSN         string
+	ReplicaMaxLag      time.Duration // Atraso de replicacao acima do qual as leituras voltam ao primario; 0 ignora
+	ReplicaCheckPeriod time.Duration // Intervalo da verificacao de saude da replica
 }
 
-func Load() *Config {
-	return &Config{
+// Load le a configuracao do ambiente (e do arquivo carregado por LoadFile) e
+// valida o resultado: valores que nao convertem ou fora da faixa sao erro, em
+// vez de cair silenciosamente no padrao
+func Load() (*Config, error) {
+	env := &envReader{}
+	cfg := &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvInt("DB_PORT", 5432),
-			Name:     getEnv("DB_NAME", "wega"),
-			User:     getEnv("DB_USER", "wega"),
-			Password: getEnv("DB_PASSWORD", ""),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-			MaxConns: getEnvInt("DB_MAX_CONNS", 25),
-			MinConns: getEnvInt("DB_MIN_CONNS", 5),
+			Host:               env.String("DB_HOST", "localhost"),
+			Port:               env.Int("DB_PORT", 5432),
+			Name:               env.String("DB_NAME", "wega"),
+			User:               env.String("DB_USER", "wega"),
+			Password:           env.String("DB_PASSWORD", ""),
+			SSLMode:            env.String("DB_SSLMODE", "disable"),
+			MaxConns:           env.Int("DB_MAX_CONNS", 25),
+			MinConns:           env.Int("DB_MIN_CONNS", 5),
+			QueryTimeout:       env.Duration("DB_QUERY_TIMEOUT", 10*time.Second),
+			SlowQueryThreshold: env.Duration("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+			ReplicaDSN:         env.String("DB_REPLICA_DSN", ""),
+			ReplicaMaxLag:      env.Duration("DB_REPLICA_MAX_LAG", 30*time.Second),
+			ReplicaCheckPeriod: env.Duration("DB_REPLICA_CHECK_INTERVAL", 10*time.Second),
+		},
+		APIPort:       env.String("API_PORT", "8080"),
+		LogLevel:      env.String("LOG_LEVEL", "info"),
+		AdminAPIKey:   env.String("ADMIN_API_KEY", ""),
+		RequireAPIKey: env.Bool("REQUIRE_API_KEY", false),
+		Health: HealthThresholds{
+			DBLatencyWarning:        env.Duration("HEALTH_DB_LATENCY_WARNING", 200*time.Millisecond),
+			DBLatencyCritical:       env.Duration("HEALTH_DB_LATENCY_CRITICAL", time.Second),
+			ScraperAgeWarning:       env.Duration("HEALTH_SCRAPER_AGE_WARNING", 7*24*time.Hour),
+			ScraperAgeCritical:      env.Duration("HEALTH_SCRAPER_AGE_CRITICAL", 30*24*time.Hour),
+			PendingFailuresWarning:  env.Int("HEALTH_PENDING_FAILURES_WARNING", 500),
+			PendingFailuresCritical: env.Int("HEALTH_PENDING_FAILURES_CRITICAL", 5000),
+			ProviderErrorsWarning:   env.Int("HEALTH_PROVIDER_ERRORS_WARNING", 50),
+			ProviderErrorsCritical:  env.Int("HEALTH_PROVIDER_ERRORS_CRITICAL", 500),
+			LLMStatusURL:            env.String("HEALTH_LLM_STATUS_URL", ""),
+		},
+		AoVivo: AoVivoConfig{
+			URL:      env.String("LIVE_LOOKUP_URL", ""),
+			Timeout:  env.Duration("LIVE_LOOKUP_TIMEOUT", 5*time.Second),
+			CacheTTL: env.Duration("LIVE_LOOKUP_CACHE_TTL", 6*time.Hour),
+		},
+		PrecosLLM:        getEnvPrecosLLM("LLM_PRICES"),
+		ExportTimeout:    env.Duration("EXPORT_TIMEOUT", 10*time.Minute),
+		OTLPEndpoint:     env.String("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		DebugEndpoints:   env.Bool("DEBUG_ENDPOINTS", false),
+		CacheCatalogoTTL: env.Duration("CATALOG_CACHE_TTL", 10*time.Minute),
+		BuscaSemantica: BuscaSemanticaConfig{
+			Provider:        strings.ToLower(env.String("EMBEDDINGS_PROVIDER", "")),
+			Modelo:          env.String("EMBEDDING_MODEL", ""),
+			OllamaURL:       env.String("OLLAMA_URL", "http://localhost:11434"),
+			OpenAIAPIKey:    env.String("OPENAI_API_KEY", ""),
+			MinSimilaridade: env.Float("SEMANTIC_SEARCH_MIN_SCORE", 0.80),
+			Margem:          env.Float("SEMANTIC_SEARCH_MARGIN", 0.05),
+			Intervalo:       env.Duration("EMBEDDINGS_INDEX_INTERVAL", time.Hour),
+		},
+		Placa: PlacaConfig{
+			URL:            env.String("PLATE_API_URL", ""),
+			Token:          env.String("PLATE_API_TOKEN", ""),
+			AuthHeader:     env.String("PLATE_API_AUTH_HEADER", "Authorization"),
+			AuthScheme:     env.String("PLATE_API_AUTH_SCHEME", "Bearer"),
+			Campos:         getEnvCamposPlaca("PLATE_API_FIELDS"),
+			Timeout:        env.Duration("PLATE_API_TIMEOUT", 5*time.Second),
+			LimiteProvedor: env.Int("PLATE_API_RPM", 60),
+			LimitePorIP:    env.Int("PLATE_LOOKUP_IP_RPM", 10),
+			CacheTTL:       env.Duration("PLATE_CACHE_TTL", 7*24*time.Hour),
+		},
+		SincronizacaoERP: SincronizacaoERPConfig{
+			URL:       env.String("ERP_SYNC_URL", ""),
+			Token:     env.String("ERP_SYNC_TOKEN", ""),
+			Intervalo: env.Duration("ERP_SYNC_INTERVAL", time.Hour),
+			Timeout:   env.Duration("ERP_SYNC_TIMEOUT", 2*time.Minute),
+		},
+		FotoProduto: FotoProdutoConfig{
+			BaseURL:     env.String("PRODUCT_IMAGE_BASE_URL", ""),
+			Timeout:     env.Duration("PRODUCT_IMAGE_TIMEOUT", 10*time.Second),
+			CacheTTL:    env.Duration("PRODUCT_IMAGE_CACHE_TTL", 24*time.Hour),
+			MaxAge:      env.Duration("PRODUCT_IMAGE_MAX_AGE", 24*time.Hour),
+			MaxDimensao: env.Int("PRODUCT_IMAGE_MAX_SIZE", 2000),
+		},
+		Throttle: ThrottleConfig{
+			PorIP:  env.Int("THROTTLE_IP_RPM", 0),
+			Global: env.Int("THROTTLE_GLOBAL_RPM", 0),
+		},
+		TrustedProxies: env.List("TRUSTED_PROXIES", nil),
+		CORS: CORSConfig{
+			AllowedOrigins:   env.List("CORS_ALLOWED_ORIGINS", []string{"*"}),
+			AllowedMethods:   env.List("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+			AllowedHeaders:   env.List("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization", "X-Admin-Key", "X-Admin-User", "X-API-Key", "Accept-Language"}),
+			AllowCredentials: env.Bool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           env.Duration("CORS_MAX_AGE", 0),
+		},
+		TLS: TLSConfig{
+			CertFile:         env.String("TLS_CERT_FILE", ""),
+			KeyFile:          env.String("TLS_KEY_FILE", ""),
+			AutocertDomains:  env.List("TLS_AUTOCERT_DOMAINS", nil),
+			AutocertCacheDir: env.String("TLS_AUTOCERT_CACHE_DIR", "autocert-cache"),
+			AutocertEmail:    env.String("TLS_AUTOCERT_EMAIL", ""),
+			RedirectPort:     env.String("TLS_REDIRECT_PORT", ""),
+		},
+		AccessLog: AccessLogConfig{
+			Format:     env.String("ACCESS_LOG_FORMAT", "json"),
+			SampleRate: env.Float("ACCESS_LOG_SAMPLE_RATE", 1),
+		},
+		Completude: CompletudeConfig{
+			Intervalo:  env.Duration("COMPLETENESS_REPORT_INTERVAL", 7*24*time.Hour),
+			WebhookURL: env.String("COMPLETENESS_WEBHOOK_URL", ""),
+			SLA: model.CompletudeSLA{
+				Filtro:        env.Float("COMPLETENESS_SLA_FILTROS", 0.95),
+				Especificacao: env.Float("COMPLETENESS_SLA_ESPECIFICACOES", 0.80),
+				Referencia:    env.Float("COMPLETENESS_SLA_REFERENCIAS", 0.50),
+			},
 		},
-		APIPort:  getEnv("API_PORT", "8080"),
-		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
+
+	if len(env.invalid) > 0 {
+		return nil, fmt.Errorf("variaveis com valor invalido: %s", strings.Join(env.invalid, "; "))
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func getEnv(key, defaultValue string) string {
+// envReader le variaveis de ambiente com padrao, guardando as que nao convertem
+type envReader struct {
+	invalid []string
+}
+
+func (e *envReader) fail(key, value, tipo string) {
+	e.invalid = append(e.invalid, fmt.Sprintf("%s=%q (esperado %s)", key, value, tipo))
+}
+
+func (e *envReader) String(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
+func (e *envReader) Int(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	intVal, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(key, value, "inteiro")
+		return defaultValue
+	}
+	return intVal
+}
+
+func (e *envReader) Bool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail(key, value, "true ou false")
+		return defaultValue
+	}
+	return b
+}
+
+func (e *envReader) Float(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.fail(key, value, "numero")
+		return defaultValue
+	}
+	return f
+}
+
+func (e *envReader) Duration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(key, value, "duracao, ex. 30s")
+		return defaultValue
+	}
+	return d
+}
+
+// List le uma lista separada por virgulas, ignorando itens vazios
+func (e *envReader) List(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
 		}
 	}
-	return defaultValue
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+// getEnvPrecosLLM le precos no formato "groq=0.59:0.79,gemini=0.10:0.40"
+// (USD por milhao de tokens de prompt:resposta); entradas invalidas sao ignoradas
+func getEnvPrecosLLM(key string) map[string]model.PrecoLLM {
+	precos := make(map[string]model.PrecoLLM)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		provedor, valores, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		prompt, resposta, ok := strings.Cut(valores, ":")
+		if !ok {
+			continue
+		}
+		p, errPrompt := strconv.ParseFloat(strings.TrimSpace(prompt), 64)
+		r, errResposta := strconv.ParseFloat(strings.TrimSpace(resposta), 64)
+		if errPrompt != nil || errResposta != nil || p < 0 || r < 0 {
+			continue
+		}
+		precos[strings.ToLower(strings.TrimSpace(provedor))] = model.PrecoLLM{Prompt: p, Resposta: r}
+	}
+	return precos
+}
+
+// CamposPlaca sao os campos do veiculo cujo caminho na resposta do provedor de
+// placas pode ser sobrescrito em PLATE_API_FIELDS
+var CamposPlaca = []string{"marca", "modelo", "versao", "ano", "ano_modelo", "combustivel"}
+
+// getEnvCamposPlaca le os caminhos no formato "marca=dados.marca,ano_modelo=dados.anoModelo";
+// varios caminhos de um campo sao separados por "|" (vale o primeiro presente)
+func getEnvCamposPlaca(key string) map[string]string {
+	campos := make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		campo, caminho, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		campos[strings.ToLower(strings.TrimSpace(campo))] = strings.TrimSpace(caminho)
+	}
+	return campos
 }
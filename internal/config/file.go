@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv indica o arquivo de configuracao quando -config nao e passado
+const ConfigFileEnv = "WEGA_CONFIG_FILE"
+
+// LoadFile le um arquivo YAML (.yaml/.yml) ou TOML (.toml) e exporta seus
+// valores como variaveis de ambiente, para Load e as flags do scraper os
+// lerem como se viessem do ambiente. As chaves sao os nomes das variaveis,
+// direto ou em secoes: "db: {host: x}" vira DB_HOST e listas viram valores
+// separados por virgula. Variaveis ja definidas no ambiente prevalecem sobre o
+// arquivo. Retorna as chaves lidas.
+func LoadFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo de configuracao: %w", err)
+	}
+
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("formato de configuracao nao suportado: %s (use .yaml, .yml ou .toml)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("arquivo de configuracao invalido %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flattenConfig("", raw, values); err != nil {
+		return nil, fmt.Errorf("arquivo de configuracao invalido %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		keys = append(keys, key)
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// flattenConfig converte secoes aninhadas em nomes de variaveis (DB + HOST = DB_HOST)
+func flattenConfig(prefix string, raw map[string]any, values map[string]string) error {
+	for key, value := range raw {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			if err := flattenConfig(name, v, values); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				s, err := configScalar(name, item)
+				if err != nil {
+					return err
+				}
+				items[i] = s
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			s, err := configScalar(name, v)
+			if err != nil {
+				return err
+			}
+			values[name] = s
+		}
+	}
+	return nil
+}
+
+// configScalar formata um valor simples como no ambiente
+func configScalar(name string, value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("%s: valor nao suportado (%T)", name, value)
+	}
+}
+
+// ConfigFileFromArgs encontra o valor de -config/--config nos argumentos
+// antes de flag.Parse, para o arquivo ser carregado antes dos padroes das
+// flags lerem o ambiente. Sem a flag, usa WEGA_CONFIG_FILE.
+func ConfigFileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return os.Getenv(ConfigFileEnv)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SecretEnvVars sao as variaveis que aceitam indirecao por arquivo: com
+// DB_PASSWORD_FILE=/run/secrets/db_password o conteudo do arquivo vira
+// DB_PASSWORD, como nos secrets do Docker e do Kubernetes
+var SecretEnvVars = []string{
+	"DB_PASSWORD",
+	"DB_REPLICA_DSN",
+	"ADMIN_API_KEY",
+	"GROQ_API_KEY",
+	"GROQ_API_KEYS",
+	"GEMINI_API_KEY",
+	"GEMINI_API_KEYS",
+	"OPENAI_API_KEY",
+	"PLATE_API_TOKEN",
+	"ERP_SYNC_TOKEN",
+	"SINK_TOKEN",
+	"SCRAPER_CONTROL_TOKEN",
+	"SCRAPER_WEBHOOK_SECRET",
+	"SCRAPER_WEBHOOK_URLS",
+	"ALERT_WEBHOOK_URL",
+	"SLACK_WEBHOOK_URL",
+	"DISCORD_WEBHOOK_URL",
+	"COMPLETENESS_WEBHOOK_URL",
+}
+
+// LoadSecretFiles le os arquivos indicados pelas variaveis <NOME>_FILE de
+// SecretEnvVars e exporta o conteudo em <NOME>, sem a quebra de linha final.
+// Em listas (_KEYS, _URLS) cada linha do arquivo vira um item. Definir a
+// variavel e a versao _FILE ao mesmo tempo e erro. Retorna as variaveis lidas.
+func LoadSecretFiles() ([]string, error) {
+	var loaded []string
+	for _, name := range SecretEnvVars {
+		path := os.Getenv(name + "_FILE")
+		if path == "" {
+			continue
+		}
+		if os.Getenv(name) != "" {
+			return loaded, fmt.Errorf("%s e %s_FILE definidos: use apenas um", name, name)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return loaded, fmt.Errorf("falha ao ler %s_FILE: %w", name, err)
+		}
+		value := strings.TrimRight(string(data), "\r\n")
+		if strings.HasSuffix(name, "_KEYS") || strings.HasSuffix(name, "_URLS") {
+			value = joinLines(value)
+		}
+		if value == "" {
+			return loaded, fmt.Errorf("%s_FILE vazio: %s", name, path)
+		}
+
+		os.Setenv(name, value)
+		loaded = append(loaded, name)
+	}
+	return loaded, nil
+}
+
+// joinLines une as linhas nao vazias de um arquivo de secret com virgula
+func joinLines(value string) string {
+	var items []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return strings.Join(items, ",")
+}
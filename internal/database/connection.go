@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -46,6 +47,7 @@ func Connect(ctx context.Context, cfg ConnectionConfig) (*pgxpool.Pool, error) {
 
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer() // Query spans; no-op unless tracing is set up
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// migrationFiles are the versioned schema migrations, NNNNNN_name.up.sql and
+// NNNNNN_name.down.sql; add new schema changes as the next version
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsTable records the applied version and whether the last migration failed halfway
+const migrationsTable = "schema_migrations"
+
+// Migration is one versioned migration file pair
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// MigrationStatus is the schema version of a database against the embedded migrations
+type MigrationStatus struct {
+	Version uint // 0 when no migration was applied
+	Dirty   bool // The last migration failed halfway; fix the schema and force a version
+	Latest  uint
+	Pending []Migration
+}
+
+// Migrations returns the embedded migrations in version order
+func Migrations() ([]Migration, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, name := range names {
+		base := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".up.sql")
+		number, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Version: uint(version), Name: title})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// newMigrationsDriver opens the golang-migrate database driver over the pool;
+// its Lock is the migrations advisory lock taken by Up, Down and Force
+func newMigrationsDriver(pool *pgxpool.Pool) (migratedb.Driver, error) {
+	driver, err := pgxmigrate.WithInstance(stdlib.OpenDBFromPool(pool), &pgxmigrate.Config{
+		MigrationsTable: migrationsTable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations driver: %w", err)
+	}
+	return driver, nil
+}
+
+// newMigrator opens a golang-migrate instance over the pool (closing it does
+// not close the pool)
+func newMigrator(pool *pgxpool.Pool) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	driver, err := newMigrationsDriver(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	return m, nil
+}
+
+// RunMigrations applies every pending migration. A database created before
+// versioned migrations is first brought to the baseline by the legacy
+// migrations and marked as version 1. Concurrent callers wait on the
+// migrations advisory lock.
+func RunMigrations(ctx context.Context, pool *pgxpool.Pool) error {
+	if err := adoptLegacySchema(ctx, pool); err != nil {
+		return err
+	}
+
+	m, err := newMigrator(pool)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// RollbackMigrations reverts the last steps applied migrations
+func RollbackMigrations(pool *pgxpool.Pool, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("invalid rollback steps: %d", steps)
+	}
+
+	m, err := newMigrator(pool)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Steps(-steps); err != nil {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+	return nil
+}
+
+// ForceMigrationVersion records version as applied and clean without running
+// anything, to recover from a dirty migration once the schema was fixed by hand
+func ForceMigrationVersion(pool *pgxpool.Pool, version int) error {
+	m, err := newMigrator(pool)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("failed to force migration version %d: %w", version, err)
+	}
+	return nil
+}
+
+// GetMigrationStatus compares the applied version with the embedded migrations
+func GetMigrationStatus(ctx context.Context, pool *pgxpool.Pool) (*MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{}
+	var versioned bool
+	err = pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, migrationsTable).Scan(&versioned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", migrationsTable, err)
+	}
+	if versioned {
+		err = pool.QueryRow(ctx, `
+			SELECT version, dirty FROM `+migrationsTable+` LIMIT 1
+		`).Scan(&status.Version, &status.Dirty)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to read migration version: %w", err)
+		}
+	}
+
+	for _, migration := range migrations {
+		status.Latest = migration.Version
+		if migration.Version > status.Version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// CheckMigrations returns an error when the database is behind the embedded
+// migrations or a migration failed halfway. The API does not migrate (the
+// scraper does), so its readiness probe uses this to wait for the schema.
+func CheckMigrations(ctx context.Context, pool *pgxpool.Pool) error {
+	status, err := GetMigrationStatus(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("migration %d failed halfway (dirty)", status.Version)
+	}
+	if len(status.Pending) > 0 {
+		return fmt.Errorf("migrations not applied: schema at version %d, expected %d", status.Version, status.Latest)
+	}
+	return nil
+}
+
+// adoptLegacySchema marks a database migrated by the legacy Go migrations as
+// version 1, after completing whatever legacy step it still lacks. Fresh
+// databases (no ESPECIFICACAO_TECNICA) are left to the baseline migration.
+// It holds the migrations advisory lock throughout, so instances starting
+// together adopt the schema once and wait before running Up.
+func adoptLegacySchema(ctx context.Context, pool *pgxpool.Pool) error {
+	driver, err := newMigrationsDriver(pool)
+	if err != nil {
+		return err
+	}
+	defer driver.Close()
+
+	if err := driver.Lock(); err != nil {
+		return fmt.Errorf("failed to take the migrations lock: %w", err)
+	}
+	defer driver.Unlock()
+
+	// Checked under the lock: another instance may have just adopted it
+	if version, _, err := driver.Version(); err != nil || version != migratedb.NilVersion {
+		return nil // Already versioned (or the error surfaces on Up)
+	}
+
+	var legacy bool
+	err = pool.QueryRow(ctx, `SELECT to_regclass('"ESPECIFICACAO_TECNICA"') IS NOT NULL`).Scan(&legacy)
+	if err != nil {
+		return fmt.Errorf("failed to check for a legacy schema: %w", err)
+	}
+	if !legacy {
+		return nil
+	}
+
+	if err := migrateLegacySchema(ctx, pool); err != nil {
+		return fmt.Errorf("failed to migrate legacy schema: %w", err)
+	}
+	if err := driver.SetVersion(1, false); err != nil {
+		return fmt.Errorf("failed to mark legacy schema as the baseline: %w", err)
+	}
+	return nil
+}
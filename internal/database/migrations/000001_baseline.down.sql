@@ -0,0 +1,18 @@
+-- Drops every table of the baseline (scraped specs, history, runs and quotas
+-- included); the Wega tables are kept
+DROP TABLE IF EXISTS "SCRAPER_RUN_TOKENS";
+DROP TABLE IF EXISTS "CLASSIFICACAO_VEICULO";
+DROP TABLE IF EXISTS "RELATORIO_COMPLETUDE";
+DROP TABLE IF EXISTS "SCRAPER_TENTATIVA";
+DROP TABLE IF EXISTS "ALIAS_VEICULO";
+DROP INDEX IF EXISTS "idx_aplicacao_criado_em";
+ALTER TABLE "APLICACAO" DROP COLUMN IF EXISTS "CriadoEm";
+DROP TABLE IF EXISTS "SCRAPER_RUN";
+DROP TABLE IF EXISTS "SCRAPER_QUEUE";
+DROP TABLE IF EXISTS "API_QUOTA";
+DROP TABLE IF EXISTS "SCRAPER_CHECKPOINT";
+DROP TABLE IF EXISTS "APLICACAO_POPULARIDADE";
+DROP TABLE IF EXISTS "SCRAPER_FALHAS";
+DROP TABLE IF EXISTS "ESPECIFICACAO_TECNICA";
+DROP FUNCTION IF EXISTS especificacao_tecnica_historico();
+DROP TABLE IF EXISTS "ESPECIFICACAO_TECNICA_HISTORICO";
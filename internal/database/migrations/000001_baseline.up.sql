@@ -0,0 +1,256 @@
+-- Baseline: the schema built by the legacy Go migrations (migrations_legacy.go).
+-- Databases created before versioned migrations are brought to this state by
+-- migrateLegacySchema and marked as version 1 instead of running this file.
+-- The Wega tables (FABRICANTE, APLICACAO, PRODUTO, ...) come from the catalog
+-- dump and must already exist.
+
+CREATE TABLE "ESPECIFICACAO_TECNICA" (
+	"ID" SERIAL PRIMARY KEY,
+	"CodigoAplicacao" INTEGER NOT NULL,
+	"TipoFluido" VARCHAR(50) NOT NULL,
+	"Viscosidade" VARCHAR(50),
+	"Capacidade" VARCHAR(50),
+	"Norma" TEXT,
+	"Recomendacao" TEXT,
+	"Observacao" TEXT,
+	"Fonte" VARCHAR(50) NOT NULL DEFAULT 'MotulAPI',
+	"MotulVehicleTypeId" VARCHAR(100),
+	"MatchConfidence" DECIMAL(5,2),
+	"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"CapacidadeLitros" NUMERIC(7,2),
+	"ViscosidadesSAE" TEXT[],
+	"Condicao" VARCHAR(30) NOT NULL DEFAULT '',
+	"IntervaloTrocaKm" INTEGER,
+	"IntervaloTrocaMeses" INTEGER,
+	CONSTRAINT "fk_especificacao_aplicacao"
+		FOREIGN KEY ("CodigoAplicacao")
+		REFERENCES "APLICACAO"("CodigoAplicacao")
+		ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_especificacao_aplicacao" ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao");
+CREATE INDEX "idx_especificacao_tipo" ON "ESPECIFICACAO_TECNICA"("TipoFluido");
+CREATE INDEX "idx_especificacao_fonte" ON "ESPECIFICACAO_TECNICA"("Fonte");
+CREATE INDEX "idx_especificacao_capacidade_litros" ON "ESPECIFICACAO_TECNICA"("CapacidadeLitros");
+CREATE INDEX "idx_especificacao_viscosidades_sae" ON "ESPECIFICACAO_TECNICA" USING GIN ("ViscosidadesSAE");
+CREATE UNIQUE INDEX "uq_especificacao_aplicacao_tipo_condicao"
+	ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao", "TipoFluido", "Condicao");
+
+-- Every version of each spec, valid from "ValidoDe" until "ValidoAte" (NULL while current)
+CREATE TABLE "ESPECIFICACAO_TECNICA_HISTORICO" (
+	"HistoricoID" BIGSERIAL PRIMARY KEY,
+	"ID" INTEGER NOT NULL,
+	"CodigoAplicacao" INTEGER NOT NULL,
+	"TipoFluido" VARCHAR(50) NOT NULL,
+	"Viscosidade" VARCHAR(50),
+	"Capacidade" VARCHAR(50),
+	"Norma" TEXT,
+	"Recomendacao" TEXT,
+	"Observacao" TEXT,
+	"Fonte" VARCHAR(50) NOT NULL,
+	"MotulVehicleTypeId" VARCHAR(100),
+	"MatchConfidence" DECIMAL(5,2),
+	"CriadoEm" TIMESTAMP NOT NULL,
+	"AtualizadoEm" TIMESTAMP NOT NULL,
+	"ValidoDe" TIMESTAMP NOT NULL,
+	"ValidoAte" TIMESTAMP,
+	"CapacidadeLitros" NUMERIC(7,2),
+	"ViscosidadesSAE" TEXT[],
+	"Condicao" VARCHAR(30) NOT NULL DEFAULT '',
+	"IntervaloTrocaKm" INTEGER,
+	"IntervaloTrocaMeses" INTEGER
+);
+
+CREATE INDEX "idx_especificacao_historico_aplicacao"
+	ON "ESPECIFICACAO_TECNICA_HISTORICO"("CodigoAplicacao", "ValidoDe");
+CREATE INDEX "idx_especificacao_historico_atual"
+	ON "ESPECIFICACAO_TECNICA_HISTORICO"("ID") WHERE "ValidoAte" IS NULL;
+
+CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+		SET "ValidoAte" = NOW()
+		WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+	END IF;
+
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe",
+			"CapacidadeLitros", "ViscosidadesSAE", "Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses"
+		) VALUES (
+			NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+			NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+			NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW(),
+			NEW."CapacidadeLitros", NEW."ViscosidadesSAE", NEW."Condicao", NEW."IntervaloTrocaKm", NEW."IntervaloTrocaMeses"
+		);
+	END IF;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "trg_especificacao_historico"
+	AFTER INSERT OR UPDATE OR DELETE ON "ESPECIFICACAO_TECNICA"
+	FOR EACH ROW EXECUTE FUNCTION especificacao_tecnica_historico();
+
+CREATE TABLE "SCRAPER_FALHAS" (
+	"ID" SERIAL PRIMARY KEY,
+	"CodigoAplicacao" INTEGER NOT NULL,
+	"TipoErro" VARCHAR(100) NOT NULL,
+	"MensagemErro" TEXT,
+	"Tentativas" INTEGER NOT NULL DEFAULT 1,
+	"UltimaTentativa" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"ProximaTentativa" TIMESTAMP,
+	"Resolvido" BOOLEAN NOT NULL DEFAULT FALSE,
+	"ResolvidoEm" TIMESTAMP,
+	"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	CONSTRAINT "fk_falha_aplicacao"
+		FOREIGN KEY ("CodigoAplicacao")
+		REFERENCES "APLICACAO"("CodigoAplicacao")
+		ON DELETE CASCADE,
+	CONSTRAINT "uq_falha_aplicacao"
+		UNIQUE ("CodigoAplicacao")
+);
+
+CREATE INDEX "idx_falhas_resolvido" ON "SCRAPER_FALHAS"("Resolvido") WHERE "Resolvido" = FALSE;
+CREATE INDEX "idx_falhas_proxima_tentativa" ON "SCRAPER_FALHAS"("ProximaTentativa") WHERE "Resolvido" = FALSE;
+CREATE INDEX "idx_falhas_tipo" ON "SCRAPER_FALHAS"("TipoErro");
+
+CREATE TABLE "APLICACAO_POPULARIDADE" (
+	"CodigoAplicacao" INTEGER PRIMARY KEY,
+	"Consultas" INTEGER NOT NULL DEFAULT 0,
+	"ConsultasSemEspecificacao" INTEGER NOT NULL DEFAULT 0,
+	"Score" DOUBLE PRECISION NOT NULL DEFAULT 0,
+	"UltimaConsulta" TIMESTAMP NOT NULL DEFAULT NOW(),
+	CONSTRAINT "fk_popularidade_aplicacao"
+		FOREIGN KEY ("CodigoAplicacao")
+		REFERENCES "APLICACAO"("CodigoAplicacao")
+		ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_popularidade_score" ON "APLICACAO_POPULARIDADE"("Score" DESC);
+
+CREATE TABLE "SCRAPER_CHECKPOINT" (
+	"RunID" VARCHAR(100) PRIMARY KEY,
+	"UltimoCodigoAplicacao" INTEGER NOT NULL,
+	"IniciadoEm" TIMESTAMP NOT NULL,
+	"SalvoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"Sucesso" INTEGER NOT NULL DEFAULT 0,
+	"Falhas" INTEGER NOT NULL DEFAULT 0,
+	"Ignorados" INTEGER NOT NULL DEFAULT 0
+);
+
+-- Only the SHA-256 of each key is stored; UsoDia is reset daily by the API job runner
+CREATE TABLE "API_QUOTA" (
+	"ID" SERIAL PRIMARY KEY,
+	"Nome" VARCHAR(100) NOT NULL,
+	"ChaveHash" CHAR(64) NOT NULL UNIQUE,
+	"ChavePrefixo" VARCHAR(20) NOT NULL,
+	"RequisicoesDia" INTEGER NOT NULL DEFAULT 0,
+	"Burst" INTEGER NOT NULL DEFAULT 0,
+	"Ativo" BOOLEAN NOT NULL DEFAULT TRUE,
+	"UsoDia" INTEGER NOT NULL DEFAULT 0,
+	"DiaUso" DATE NOT NULL DEFAULT CURRENT_DATE,
+	"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE "SCRAPER_QUEUE" (
+	"RunID" VARCHAR(100) NOT NULL,
+	"CodigoAplicacao" INTEGER NOT NULL,
+	"Ordem" INTEGER NOT NULL,
+	"Status" VARCHAR(20) NOT NULL DEFAULT 'pendente',
+	"Worker" VARCHAR(100),
+	"ReivindicadoEm" TIMESTAMP,
+	"ConcluidoEm" TIMESTAMP,
+	"Tentativas" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY ("RunID", "CodigoAplicacao")
+);
+
+CREATE INDEX "idx_scraper_queue_claim" ON "SCRAPER_QUEUE"("RunID", "Status", "Ordem");
+
+CREATE TABLE "SCRAPER_RUN" (
+	"ID" SERIAL PRIMARY KEY,
+	"RunID" VARCHAR(100) NOT NULL,
+	"Provedor" VARCHAR(50) NOT NULL,
+	"Worker" VARCHAR(100) NOT NULL DEFAULT '',
+	"Status" VARCHAR(20) NOT NULL,
+	"IniciadoEm" TIMESTAMPTZ NOT NULL,
+	"FinalizadoEm" TIMESTAMPTZ NOT NULL,
+	"DuracaoSegundos" DOUBLE PRECISION NOT NULL,
+	"Total" INTEGER NOT NULL DEFAULT 0,
+	"Processados" INTEGER NOT NULL DEFAULT 0,
+	"Sucesso" INTEGER NOT NULL DEFAULT 0,
+	"Falhas" INTEGER NOT NULL DEFAULT 0,
+	"Ignorados" INTEGER NOT NULL DEFAULT 0,
+	"MatchExato" INTEGER NOT NULL DEFAULT 0,
+	"MatchFuzzy" INTEGER NOT NULL DEFAULT 0,
+	"SemMatch" INTEGER NOT NULL DEFAULT 0,
+	"Requisicoes" INTEGER NOT NULL DEFAULT 0,
+	"ErrosRede" INTEGER NOT NULL DEFAULT 0,
+	"RateLimit" INTEGER NOT NULL DEFAULT 0,
+	"TokensLLM" BIGINT NOT NULL DEFAULT 0,
+	"Configuracao" JSONB,
+	"FalhasPorMotivo" JSONB,
+	"TiposErro" JSONB,
+	"Erro" TEXT
+);
+
+CREATE INDEX "idx_scraper_run_finalizado" ON "SCRAPER_RUN"("FinalizadoEm" DESC);
+
+-- Existing rows keep NULL (imported before tracking); the default is set
+-- separately so it does not backfill them
+ALTER TABLE "APLICACAO" ADD COLUMN IF NOT EXISTS "CriadoEm" TIMESTAMPTZ;
+ALTER TABLE "APLICACAO" ALTER COLUMN "CriadoEm" SET DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS "idx_aplicacao_criado_em" ON "APLICACAO"("CriadoEm");
+
+CREATE TABLE "ALIAS_VEICULO" (
+	"Tipo" VARCHAR(10) NOT NULL,
+	"Marca" VARCHAR(100) NOT NULL DEFAULT '',
+	"Origem" VARCHAR(255) NOT NULL,
+	"Destino" VARCHAR(255) NOT NULL,
+	"Fonte" VARCHAR(20) NOT NULL DEFAULT 'aprendido',
+	"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY ("Tipo", "Marca", "Origem")
+);
+
+CREATE TABLE "SCRAPER_TENTATIVA" (
+	"CodigoAplicacao" INTEGER PRIMARY KEY
+		REFERENCES "APLICACAO"("CodigoAplicacao") ON DELETE CASCADE,
+	"Resultado" VARCHAR(20) NOT NULL,
+	"Tentativas" INTEGER NOT NULL DEFAULT 1,
+	"UltimaTentativa" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE "RELATORIO_COMPLETUDE" (
+	"GeradoEm" TIMESTAMP NOT NULL,
+	"CodigoFabricante" INTEGER NOT NULL,
+	"Fabricante" VARCHAR(255) NOT NULL,
+	"Aplicacoes" INTEGER NOT NULL,
+	"ComFiltro" INTEGER NOT NULL,
+	"ComEspecificacao" INTEGER NOT NULL,
+	"ComReferencia" INTEGER NOT NULL,
+	PRIMARY KEY ("GeradoEm", "CodigoFabricante")
+);
+
+CREATE TABLE "CLASSIFICACAO_VEICULO" (
+	"CodigoAplicacao" INTEGER PRIMARY KEY
+		REFERENCES "APLICACAO"("CodigoAplicacao") ON DELETE CASCADE,
+	"Categoria" VARCHAR(20) NOT NULL,
+	"ClassificadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- API keys are identified by their last 4 characters only
+CREATE TABLE "SCRAPER_RUN_TOKENS" (
+	"RunID" INTEGER NOT NULL REFERENCES "SCRAPER_RUN"("ID") ON DELETE CASCADE,
+	"Provedor" VARCHAR(50) NOT NULL,
+	"Chave" VARCHAR(20) NOT NULL DEFAULT '',
+	"Requisicoes" BIGINT NOT NULL DEFAULT 0,
+	"TokensPrompt" BIGINT NOT NULL DEFAULT 0,
+	"TokensResposta" BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY ("RunID", "Provedor", "Chave")
+);
@@ -0,0 +1,3 @@
+-- pg_trgm is kept: other objects of the database may use it
+DROP INDEX IF EXISTS "idx_produto_numero_trgm";
+DROP INDEX IF EXISTS "idx_aplicacao_descricao_trgm";
@@ -0,0 +1,12 @@
+-- Trigram indexes for GET /api/v1/autocomplete. The expressions must match
+-- the repository: descriptions lowercased without accents (normalize.SQLAccented
+-- -> normalize.SQLPlain) and product codes uppercased without separators.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS "idx_aplicacao_descricao_trgm" ON "APLICACAO" USING GIN (
+	translate(LOWER("DescricaoAplicacao"), 'áàâãäåéèêëíìîïóòôõöúùûüçñý', 'aaaaaaeeeeiiiiooooouuuucny') gin_trgm_ops
+);
+
+CREATE INDEX IF NOT EXISTS "idx_produto_numero_trgm" ON "PRODUTO" USING GIN (
+	UPPER(translate("NumeroProduto", '- ./', '')) gin_trgm_ops
+);
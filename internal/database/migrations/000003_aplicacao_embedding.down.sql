@@ -0,0 +1,2 @@
+-- The vector extension is kept: other objects of the database may use it
+DROP TABLE IF EXISTS "APLICACAO_EMBEDDING";
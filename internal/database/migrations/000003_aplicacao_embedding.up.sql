@@ -0,0 +1,32 @@
+-- Embeddings of "APLICACAO" descriptions for semantic search (pgvector).
+-- Servers without the extension (or a role allowed to create it) get nothing:
+-- the API and the scraper detect the missing table and keep using ILIKE. After
+-- installing pgvector, re-run this migration with "migrate force 2 && migrate up".
+-- The column has no fixed dimension so any embedding model fits; rows of other
+-- models are ignored by the queries and re-embedded by the indexing job.
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+		RAISE NOTICE 'pgvector not available, skipping APLICACAO_EMBEDDING';
+		RETURN;
+	END IF;
+
+	BEGIN
+		CREATE EXTENSION IF NOT EXISTS vector;
+	EXCEPTION WHEN insufficient_privilege THEN
+		RAISE NOTICE 'not allowed to create extension vector, skipping APLICACAO_EMBEDDING';
+		RETURN;
+	END;
+
+	CREATE TABLE IF NOT EXISTS "APLICACAO_EMBEDDING" (
+		"CodigoAplicacao" INTEGER PRIMARY KEY
+			REFERENCES "APLICACAO"("CodigoAplicacao") ON DELETE CASCADE,
+		"Modelo" VARCHAR(100) NOT NULL,
+		"Texto" TEXT NOT NULL,
+		"Embedding" vector NOT NULL,
+		"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS "idx_aplicacao_embedding_modelo" ON "APLICACAO_EMBEDDING"("Modelo");
+END
+$$;
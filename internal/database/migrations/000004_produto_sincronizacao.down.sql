@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "PRODUTO_PRECO_HISTORICO";
+DROP TABLE IF EXISTS "PRODUTO_SINCRONIZACAO_ALTERACAO";
+DROP TABLE IF EXISTS "PRODUTO_SINCRONIZACAO";
+ALTER TABLE "PRODUTO" DROP COLUMN IF EXISTS "EstoqueAtualizadoEm";
+ALTER TABLE "PRODUTO" DROP COLUMN IF EXISTS "Estoque";
+ALTER TABLE "PRODUTO" DROP COLUMN IF EXISTS "Disponivel";
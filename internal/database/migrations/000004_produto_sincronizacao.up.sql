@@ -0,0 +1,44 @@
+-- Price and stock sync of the Wega products (CSV upload or ERP fetch).
+-- Availability columns stay NULL until a sync reports them.
+ALTER TABLE "PRODUTO" ADD COLUMN IF NOT EXISTS "Disponivel" BOOLEAN;
+ALTER TABLE "PRODUTO" ADD COLUMN IF NOT EXISTS "Estoque" INTEGER;
+ALTER TABLE "PRODUTO" ADD COLUMN IF NOT EXISTS "EstoqueAtualizadoEm" TIMESTAMP;
+
+-- One row per sync, with its totals
+CREATE TABLE "PRODUTO_SINCRONIZACAO" (
+	"ID" SERIAL PRIMARY KEY,
+	"Origem" VARCHAR(20) NOT NULL, -- upload or erp
+	"IniciadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"FinalizadoEm" TIMESTAMP,
+	"Linhas" INTEGER NOT NULL DEFAULT 0,
+	"Alterados" INTEGER NOT NULL DEFAULT 0,
+	"NaoEncontrados" TEXT[] NOT NULL DEFAULT '{}', -- Product codes missing from PRODUTO
+	"Erro" TEXT
+);
+
+CREATE INDEX "idx_produto_sincronizacao_iniciado" ON "PRODUTO_SINCRONIZACAO"("IniciadoEm" DESC);
+
+-- Audit of every field a sync changed
+CREATE TABLE "PRODUTO_SINCRONIZACAO_ALTERACAO" (
+	"SincronizacaoID" INTEGER NOT NULL
+		REFERENCES "PRODUTO_SINCRONIZACAO"("ID") ON DELETE CASCADE,
+	"CodigoProduto" INTEGER NOT NULL,
+	"Campo" VARCHAR(20) NOT NULL, -- preco, disponivel or estoque
+	"ValorAnterior" TEXT,
+	"ValorNovo" TEXT,
+	PRIMARY KEY ("SincronizacaoID", "CodigoProduto", "Campo")
+);
+
+-- Every price a product had, from "AlteradoEm" on
+CREATE TABLE "PRODUTO_PRECO_HISTORICO" (
+	"ID" BIGSERIAL PRIMARY KEY,
+	"CodigoProduto" INTEGER NOT NULL
+		REFERENCES "PRODUTO"("CodigoProduto") ON DELETE CASCADE,
+	"PrecoAnterior" NUMERIC(10,2),
+	"Preco" NUMERIC(10,2),
+	"AlteradoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"SincronizacaoID" INTEGER
+		REFERENCES "PRODUTO_SINCRONIZACAO"("ID") ON DELETE SET NULL
+);
+
+CREATE INDEX "idx_produto_preco_historico_produto" ON "PRODUTO_PRECO_HISTORICO"("CodigoProduto", "AlteradoEm");
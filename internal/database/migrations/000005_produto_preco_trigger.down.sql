@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS "trg_produto_preco_historico" ON "PRODUTO";
+DROP FUNCTION IF EXISTS registrar_preco_produto();
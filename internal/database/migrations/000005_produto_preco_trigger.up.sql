@@ -0,0 +1,26 @@
+-- Records every "PrecoProduto" change in PRODUTO_PRECO_HISTORICO, whoever makes
+-- it: the product sync sets wega.sincronizacao_id for its transaction so its
+-- rows point to the sync; manual updates (SQL, catalog dump tools) get NULL.
+CREATE OR REPLACE FUNCTION registrar_preco_produto() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' AND NEW."PrecoProduto" IS NULL THEN
+		RETURN NEW;
+	END IF;
+	IF TG_OP = 'UPDATE' AND NEW."PrecoProduto" IS NOT DISTINCT FROM OLD."PrecoProduto" THEN
+		RETURN NEW;
+	END IF;
+
+	INSERT INTO "PRODUTO_PRECO_HISTORICO" ("CodigoProduto", "PrecoAnterior", "Preco", "SincronizacaoID")
+	VALUES (
+		NEW."CodigoProduto",
+		CASE WHEN TG_OP = 'UPDATE' THEN OLD."PrecoProduto" END,
+		NEW."PrecoProduto",
+		NULLIF(current_setting('wega.sincronizacao_id', true), '')::integer
+	);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "trg_produto_preco_historico"
+	AFTER INSERT OR UPDATE OF "PrecoProduto" ON "PRODUTO"
+	FOR EACH ROW EXECUTE FUNCTION registrar_preco_produto();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "REFERENCIACRUZADA_AUDITORIA";
+DROP INDEX IF EXISTS "idx_referencia_id";
+ALTER TABLE "REFERENCIACRUZADA" DROP COLUMN IF EXISTS "ID";
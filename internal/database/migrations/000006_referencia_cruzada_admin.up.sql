@@ -0,0 +1,19 @@
+-- Surrogate key so the admin API can address a single cross reference (the
+-- dump table has none); existing rows are numbered by the serial default.
+ALTER TABLE "REFERENCIACRUZADA" ADD COLUMN IF NOT EXISTS "ID" SERIAL;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_referencia_id" ON "REFERENCIACRUZADA"("ID");
+
+-- Audit trail of the changes made through /admin/referencias, with the row
+-- before and after each change
+CREATE TABLE "REFERENCIACRUZADA_AUDITORIA" (
+	"ID" BIGSERIAL PRIMARY KEY,
+	"ReferenciaID" INTEGER NOT NULL, -- No FK: removed references keep their trail
+	"Acao" VARCHAR(10) NOT NULL,     -- criar, alterar or remover
+	"Ator" VARCHAR(100) NOT NULL,
+	"Anterior" JSONB,
+	"Novo" JSONB,
+	"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_referencia_auditoria_referencia" ON "REFERENCIACRUZADA_AUDITORIA"("ReferenciaID");
+CREATE INDEX "idx_referencia_auditoria_criado" ON "REFERENCIACRUZADA_AUDITORIA"("CriadoEm" DESC);
@@ -0,0 +1,17 @@
+-- Who changed what and when, for every data mutation made through the admin
+-- API, the scheduled jobs and the scraper (written by the repository
+-- decorators in internal/repository/auditado.go)
+CREATE TABLE "AUDIT_LOG" (
+	"ID" BIGSERIAL PRIMARY KEY,
+	"Ator" VARCHAR(100) NOT NULL,     -- X-Admin-User, a job name or motul-scraper
+	"Acao" VARCHAR(20) NOT NULL,      -- insert, update or delete
+	"Entidade" VARCHAR(50) NOT NULL,  -- Changed table, e.g. ESPECIFICACAO_TECNICA
+	"EntidadeID" VARCHAR(100),        -- Key of the changed row, NULL for bulk changes
+	"Dados" JSONB,                    -- What was written
+	"RequestID" VARCHAR(100),         -- API request ID, to match the access log
+	"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_audit_log_criado" ON "AUDIT_LOG"("CriadoEm" DESC);
+CREATE INDEX "idx_audit_log_entidade" ON "AUDIT_LOG"("Entidade", "EntidadeID");
+CREATE INDEX "idx_audit_log_ator" ON "AUDIT_LOG"("Ator", "CriadoEm" DESC);
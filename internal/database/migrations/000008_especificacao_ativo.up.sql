@@ -0,0 +1,42 @@
+-- Soft delete for specs: a bad scrape batch is deactivated by source and date
+-- range (/admin/especificacoes) instead of deleted, and can be reactivated
+-- after review. Inactive specs are left out of every read.
+ALTER TABLE "ESPECIFICACAO_TECNICA"
+	ADD COLUMN "Ativo" BOOLEAN NOT NULL DEFAULT TRUE,
+	ADD COLUMN "DeletadoEm" TIMESTAMP;
+
+ALTER TABLE "ESPECIFICACAO_TECNICA_HISTORICO"
+	ADD COLUMN "Ativo" BOOLEAN NOT NULL DEFAULT TRUE,
+	ADD COLUMN "DeletadoEm" TIMESTAMP;
+
+-- Batches are selected by source and last write
+CREATE INDEX "idx_especificacao_fonte_atualizado" ON "ESPECIFICACAO_TECNICA"("Fonte", "AtualizadoEm");
+
+-- The history keeps the flag, so as_of queries skip versions that were inactive
+CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+		SET "ValidoAte" = NOW()
+		WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+	END IF;
+
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe",
+			"CapacidadeLitros", "ViscosidadesSAE", "Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses",
+			"Ativo", "DeletadoEm"
+		) VALUES (
+			NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+			NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+			NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW(),
+			NEW."CapacidadeLitros", NEW."ViscosidadesSAE", NEW."Condicao", NEW."IntervaloTrocaKm", NEW."IntervaloTrocaMeses",
+			NEW."Ativo", NEW."DeletadoEm"
+		);
+	END IF;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,32 @@
+CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+		SET "ValidoAte" = NOW()
+		WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+	END IF;
+
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe",
+			"CapacidadeLitros", "ViscosidadesSAE", "Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses",
+			"Ativo", "DeletadoEm"
+		) VALUES (
+			NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+			NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+			NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW(),
+			NEW."CapacidadeLitros", NEW."ViscosidadesSAE", NEW."Condicao", NEW."IntervaloTrocaKm", NEW."IntervaloTrocaMeses",
+			NEW."Ativo", NEW."DeletadoEm"
+		);
+	END IF;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS "idx_especificacao_scraper_run";
+
+ALTER TABLE "ESPECIFICACAO_TECNICA_HISTORICO" DROP COLUMN IF EXISTS "ScraperRunID";
+ALTER TABLE "ESPECIFICACAO_TECNICA" DROP COLUMN IF EXISTS "ScraperRunID";
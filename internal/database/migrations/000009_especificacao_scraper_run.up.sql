@@ -0,0 +1,37 @@
+-- Tags each spec with the scraper run that last wrote it, so everything a run
+-- wrote can be rolled back (-rollback-run, /admin/scraper/runs/{id}/rollback).
+-- Runs now get their SCRAPER_RUN row when they start (status running).
+-- No FK: catalog snapshots carry the specs but not the run history.
+ALTER TABLE "ESPECIFICACAO_TECNICA" ADD COLUMN "ScraperRunID" INTEGER;
+ALTER TABLE "ESPECIFICACAO_TECNICA_HISTORICO" ADD COLUMN "ScraperRunID" INTEGER;
+
+CREATE INDEX "idx_especificacao_scraper_run" ON "ESPECIFICACAO_TECNICA"("ScraperRunID")
+	WHERE "ScraperRunID" IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+		SET "ValidoAte" = NOW()
+		WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+	END IF;
+
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe",
+			"CapacidadeLitros", "ViscosidadesSAE", "Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses",
+			"Ativo", "DeletadoEm", "ScraperRunID"
+		) VALUES (
+			NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+			NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+			NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW(),
+			NEW."CapacidadeLitros", NEW."ViscosidadesSAE", NEW."Condicao", NEW."IntervaloTrocaKm", NEW."IntervaloTrocaMeses",
+			NEW."Ativo", NEW."DeletadoEm", NEW."ScraperRunID"
+		);
+	END IF;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
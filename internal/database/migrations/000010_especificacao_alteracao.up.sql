@@ -0,0 +1,58 @@
+-- Idempotent re-runs: EspecificacaoRepository.Upsert hashes the normalized
+-- content of each spec and skips rewriting a row whose content is identical,
+-- only stamping "VerificadoEm" (used by -refresh-older-than). Inserts and
+-- changes are logged in ESPECIFICACAO_ALTERACAO with the changed fields.
+ALTER TABLE "ESPECIFICACAO_TECNICA" ADD COLUMN "VerificadoEm" TIMESTAMP;
+
+CREATE TABLE "ESPECIFICACAO_ALTERACAO" (
+	"ID" BIGSERIAL PRIMARY KEY,
+	"EspecificacaoID" INTEGER NOT NULL, -- No FK: the log outlives specs removed by a rollback
+	"CodigoAplicacao" INTEGER NOT NULL,
+	"TipoFluido" VARCHAR(50) NOT NULL,
+	"Condicao" VARCHAR(30) NOT NULL DEFAULT '',
+	"Fonte" VARCHAR(50) NOT NULL,
+	"Acao" VARCHAR(20) NOT NULL,        -- insert or update
+	"HashAnterior" CHAR(64),            -- NULL on insert
+	"HashNovo" CHAR(64) NOT NULL,       -- SHA-256 of the normalized content
+	"Campos" JSONB NOT NULL,            -- Changed fields: {"viscosidade": {"anterior": ..., "novo": ...}}
+	"ScraperRunID" INTEGER,
+	"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_especificacao_alteracao_aplicacao" ON "ESPECIFICACAO_ALTERACAO"("CodigoAplicacao", "CriadoEm" DESC);
+CREATE INDEX "idx_especificacao_alteracao_run" ON "ESPECIFICACAO_ALTERACAO"("ScraperRunID")
+	WHERE "ScraperRunID" IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+BEGIN
+	-- A re-run that finds identical content only touches "VerificadoEm":
+	-- no new version
+	IF TG_OP = 'UPDATE' AND to_jsonb(OLD) - 'VerificadoEm' = to_jsonb(NEW) - 'VerificadoEm' THEN
+		RETURN NULL;
+	END IF;
+
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+		SET "ValidoAte" = NOW()
+		WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+	END IF;
+
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe",
+			"CapacidadeLitros", "ViscosidadesSAE", "Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses",
+			"Ativo", "DeletadoEm", "ScraperRunID"
+		) VALUES (
+			NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+			NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+			NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW(),
+			NEW."CapacidadeLitros", NEW."ViscosidadesSAE", NEW."Condicao", NEW."IntervaloTrocaKm", NEW."IntervaloTrocaMeses",
+			NEW."Ativo", NEW."DeletadoEm", NEW."ScraperRunID"
+		);
+	END IF;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
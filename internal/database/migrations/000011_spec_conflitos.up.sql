@@ -0,0 +1,22 @@
+-- Viscosity disagreements between spec sources for the same vehicle, fluid
+-- and condition, flagged by EspecificacaoRepository.Upsert when a source
+-- overwrites a spec written by another one. One row per pair of sources
+-- (FonteA < FonteB); the row is removed when the sources agree again or an
+-- admin dismisses it after arbitration.
+CREATE TABLE "SPEC_CONFLITOS" (
+	"ID" SERIAL PRIMARY KEY,
+	"CodigoAplicacao" INTEGER NOT NULL,
+	"TipoFluido" VARCHAR(50) NOT NULL,
+	"Condicao" VARCHAR(30) NOT NULL DEFAULT '',
+	"FonteA" VARCHAR(50) NOT NULL,
+	"ViscosidadeA" VARCHAR(50),
+	"CapacidadeA" VARCHAR(50),
+	"FonteB" VARCHAR(50) NOT NULL,
+	"ViscosidadeB" VARCHAR(50),
+	"CapacidadeB" VARCHAR(50),
+	"DetectadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE ("CodigoAplicacao", "TipoFluido", "Condicao", "FonteA", "FonteB")
+);
+
+CREATE INDEX "idx_spec_conflitos_atualizado" ON "SPEC_CONFLITOS"("AtualizadoEm" DESC);
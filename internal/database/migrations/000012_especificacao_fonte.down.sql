@@ -0,0 +1,14 @@
+-- Back to one spec per (aplicacao, fluid, condition): keeps the most recently
+-- written source of each key
+DELETE FROM "ESPECIFICACAO_TECNICA" e
+USING "ESPECIFICACAO_TECNICA" newer
+WHERE e."CodigoAplicacao" = newer."CodigoAplicacao"
+	AND e."TipoFluido" = newer."TipoFluido"
+	AND e."Condicao" = newer."Condicao"
+	AND (e."AtualizadoEm", e."ID") < (newer."AtualizadoEm", newer."ID");
+
+DROP INDEX IF EXISTS "uq_especificacao_aplicacao_tipo_condicao_fonte";
+CREATE UNIQUE INDEX "uq_especificacao_aplicacao_tipo_condicao"
+	ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao", "TipoFluido", "Condicao");
+
+DROP FUNCTION IF EXISTS especificacao_fonte(TEXT);
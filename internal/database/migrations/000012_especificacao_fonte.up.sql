@@ -0,0 +1,13 @@
+-- One spec per source: the unique key of ESPECIFICACAO_TECNICA gains the
+-- source, so a second provider gets its own row (and content hash and change
+-- log) instead of overwriting the first one's. Sources are compared like
+-- repository.fonteCanonica: case and spaces ignored, legacy "MotulAPI" rows
+-- are the motul source.
+CREATE FUNCTION especificacao_fonte(fonte TEXT) RETURNS TEXT AS $$
+	SELECT CASE WHEN f = 'motulapi' THEN 'motul' ELSE f END
+	FROM (SELECT LOWER(regexp_replace(fonte, '\s', '', 'g')) AS f) normalizada
+$$ LANGUAGE SQL IMMUTABLE;
+
+DROP INDEX IF EXISTS "uq_especificacao_aplicacao_tipo_condicao";
+CREATE UNIQUE INDEX "uq_especificacao_aplicacao_tipo_condicao_fonte"
+	ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao", "TipoFluido", "Condicao", especificacao_fonte("Fonte"));
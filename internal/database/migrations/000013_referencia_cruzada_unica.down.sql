@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "uq_referencia_produto_fabricante_codigo";
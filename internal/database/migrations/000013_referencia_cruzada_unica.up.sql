@@ -0,0 +1,12 @@
+-- One cross reference per product, competitor and competitor code (case
+-- insensitive), so concurrent /admin/referencias requests can't both insert
+-- the same one. Duplicates already in the dump keep their oldest row.
+DELETE FROM "REFERENCIACRUZADA" rc
+USING "REFERENCIACRUZADA" outra
+WHERE outra."CodigoProduto" = rc."CodigoProduto"
+	AND outra."CodigoFabricante" = rc."CodigoFabricante"
+	AND UPPER(outra."NumeroProdutoPesq") = UPPER(rc."NumeroProdutoPesq")
+	AND outra."ID" < rc."ID";
+
+CREATE UNIQUE INDEX "uq_referencia_produto_fabricante_codigo"
+	ON "REFERENCIACRUZADA"("CodigoProduto", "CodigoFabricante", UPPER("NumeroProdutoPesq"));
@@ -0,0 +1,11 @@
+-- Keeps one alias per (Tipo, Marca, Origem): the one for every category if
+-- there is one, otherwise the most recently updated
+DELETE FROM "ALIAS_VEICULO" a
+USING "ALIAS_VEICULO" b
+WHERE a."Tipo" = b."Tipo" AND a."Marca" = b."Marca" AND a."Origem" = b."Origem"
+	AND a."Categoria" <> b."Categoria"
+	AND (b."Categoria" = '' OR (a."Categoria" <> '' AND (b."AtualizadoEm", b."Categoria") > (a."AtualizadoEm", a."Categoria")));
+
+ALTER TABLE "ALIAS_VEICULO" DROP CONSTRAINT "ALIAS_VEICULO_pkey";
+ALTER TABLE "ALIAS_VEICULO" DROP COLUMN "Categoria";
+ALTER TABLE "ALIAS_VEICULO" ADD PRIMARY KEY ("Tipo", "Marca", "Origem");
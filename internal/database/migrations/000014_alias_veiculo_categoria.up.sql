@@ -0,0 +1,8 @@
+-- Learned aliases are kept per Motul category (CAR, MOTORCYCLE, TRUCK, AGRI):
+-- each category matcher has its own catalog, so the same Wega brand can map to
+-- different Motul names and one category must not overwrite another's alias.
+-- Existing rows (and curated imports without a category) apply to every
+-- category.
+ALTER TABLE "ALIAS_VEICULO" ADD COLUMN "Categoria" VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE "ALIAS_VEICULO" DROP CONSTRAINT "ALIAS_VEICULO_pkey";
+ALTER TABLE "ALIAS_VEICULO" ADD PRIMARY KEY ("Tipo", "Categoria", "Marca", "Origem");
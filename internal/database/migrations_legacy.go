@@ -0,0 +1,1091 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/parser"
+)
+
+// migrateLegacySchema brings a database created before versioned migrations
+// (no schema_migrations table) up to the 000001_baseline schema, so it can be
+// marked as version 1. Each step checks what already exists, so it works on
+// any partially migrated legacy database; new schema changes go in
+// migrations/*.sql instead.
+func migrateLegacySchema(ctx context.Context, pool *pgxpool.Pool) error {
+	// Create ESPECIFICACAO_TECNICA table if not exists
+	if err := createEspecificacaoTecnicaTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Keep every version of each spec for as-of queries
+	if err := createEspecificacaoHistoricoTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Ensure one spec per (CodigoAplicacao, TipoFluido) so re-runs update instead of duplicating
+	if err := addEspecificacaoUniqueConstraint(ctx, pool); err != nil {
+		return err
+	}
+
+	// Store fluid types as canonical codes instead of Portuguese names
+	if err := migrateTipoFluidoCodes(ctx, pool); err != nil {
+		return err
+	}
+
+	// Store "Norma" as TEXT: a vehicle can list more standards and approvals than fit in 100 chars
+	if err := widenEspecificacaoNorma(ctx, pool); err != nil {
+		return err
+	}
+
+	// Add numeric capacity and normalized viscosity columns so specs can be filtered
+	if err := addEspecificacaoValoresNumericos(ctx, pool); err != nil {
+		return err
+	}
+
+	// Allow one spec per usage condition (normal vs severe use) per fluid type
+	if err := addEspecificacaoCondicao(ctx, pool); err != nil {
+		return err
+	}
+
+	// Store the oil change interval (km and months) recommended for each spec
+	if err := addEspecificacaoIntervaloTroca(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create SCRAPER_FALHAS table for retry tracking
+	if err := createScraperFalhasTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create APLICACAO_POPULARIDADE table fed by API queries
+	if err := createAplicacaoPopularidadeTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create SCRAPER_CHECKPOINT table so runs can resume across restarts and replicas
+	if err := createScraperCheckpointTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create API_QUOTA table with the per-key limits enforced by the API
+	if err := createAPIQuotaTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create SCRAPER_QUEUE table so several scraper instances can share a run
+	if err := createScraperQueueTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create SCRAPER_RUN table with the summary metrics of each run
+	if err := createScraperRunTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Track when each APLICACAO row is imported, for differential scraping
+	if err := addAplicacaoCriadoEm(ctx, pool); err != nil {
+		return err
+	}
+
+	// Keep run configuration and error breakdown in SCRAPER_RUN
+	if err := addScraperRunDetails(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create ALIAS_VEICULO table with learned and curated brand/model mappings
+	if err := createAliasVeiculoTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create SCRAPER_TENTATIVA table with the last scraper outcome per vehicle
+	if err := createScraperTentativaTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create RELATORIO_COMPLETUDE table with the periodic catalog completeness snapshots
+	if err := createRelatorioCompletudeTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create CLASSIFICACAO_VEICULO table caching LLM vehicle categories
+	if err := createClassificacaoVeiculoTable(ctx, pool); err != nil {
+		return err
+	}
+
+	// Create SCRAPER_RUN_TOKENS table with LLM token usage per run and API key
+	if err := createScraperRunTokensTable(ctx, pool); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createEspecificacaoTecnicaTable creates the specifications table
+func createEspecificacaoTecnicaTable(ctx context.Context, pool *pgxpool.Pool) error {
+	// Check if table exists
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.tables
+			WHERE table_schema = 'public'
+			AND table_name = 'ESPECIFICACAO_TECNICA'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if ESPECIFICACAO_TECNICA table exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	// Create table
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "ESPECIFICACAO_TECNICA" (
+			"ID" SERIAL PRIMARY KEY,
+			"CodigoAplicacao" INTEGER NOT NULL,
+			"TipoFluido" VARCHAR(50) NOT NULL,
+			"Viscosidade" VARCHAR(50),
+			"Capacidade" VARCHAR(50),
+			"Norma" TEXT,
+			"Recomendacao" TEXT,
+			"Observacao" TEXT,
+			"Fonte" VARCHAR(50) NOT NULL DEFAULT 'MotulAPI',
+			"MotulVehicleTypeId" VARCHAR(100),
+			"MatchConfidence" DECIMAL(5,2),
+			"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+			"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+			CONSTRAINT "fk_especificacao_aplicacao"
+				FOREIGN KEY ("CodigoAplicacao")
+				REFERENCES "APLICACAO"("CodigoAplicacao")
+				ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create ESPECIFICACAO_TECNICA table: %w", err)
+	}
+
+	// Create indexes
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_especificacao_aplicacao"
+		ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_aplicacao: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_especificacao_tipo"
+		ON "ESPECIFICACAO_TECNICA"("TipoFluido")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_tipo: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_especificacao_fonte"
+		ON "ESPECIFICACAO_TECNICA"("Fonte")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_fonte: %w", err)
+	}
+
+	return nil
+}
+
+// createEspecificacaoHistoricoTable creates the ESPECIFICACAO_TECNICA history table
+// and the trigger that fills it. Each row is one version of a spec, valid from
+// "ValidoDe" until "ValidoAte" (NULL while current); updates and deletes close the
+// current version, so every write path is recorded without repository changes.
+// Existing specs are seeded as valid since their "AtualizadoEm".
+func createEspecificacaoHistoricoTable(ctx context.Context, pool *pgxpool.Pool) error {
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.tables
+			WHERE table_schema = 'public'
+			AND table_name = 'ESPECIFICACAO_TECNICA_HISTORICO'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if ESPECIFICACAO_TECNICA_HISTORICO table exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin ESPECIFICACAO_TECNICA_HISTORICO migration: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TABLE "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"HistoricoID" BIGSERIAL PRIMARY KEY,
+			"ID" INTEGER NOT NULL,
+			"CodigoAplicacao" INTEGER NOT NULL,
+			"TipoFluido" VARCHAR(50) NOT NULL,
+			"Viscosidade" VARCHAR(50),
+			"Capacidade" VARCHAR(50),
+			"Norma" TEXT,
+			"Recomendacao" TEXT,
+			"Observacao" TEXT,
+			"Fonte" VARCHAR(50) NOT NULL,
+			"MotulVehicleTypeId" VARCHAR(100),
+			"MatchConfidence" DECIMAL(5,2),
+			"CriadoEm" TIMESTAMP NOT NULL,
+			"AtualizadoEm" TIMESTAMP NOT NULL,
+			"ValidoDe" TIMESTAMP NOT NULL,
+			"ValidoAte" TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create ESPECIFICACAO_TECNICA_HISTORICO table: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE INDEX "idx_especificacao_historico_aplicacao"
+		ON "ESPECIFICACAO_TECNICA_HISTORICO"("CodigoAplicacao", "ValidoDe")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_historico_aplicacao: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE INDEX "idx_especificacao_historico_atual"
+		ON "ESPECIFICACAO_TECNICA_HISTORICO"("ID") WHERE "ValidoAte" IS NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_historico_atual: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+		BEGIN
+			IF TG_OP IN ('UPDATE', 'DELETE') THEN
+				UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+				SET "ValidoAte" = NOW()
+				WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+			END IF;
+
+			IF TG_OP IN ('INSERT', 'UPDATE') THEN
+				INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+					"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+					"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+					"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe"
+				) VALUES (
+					NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+					NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+					NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW()
+				);
+			END IF;
+
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create especificacao_tecnica_historico function: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE TRIGGER "trg_especificacao_historico"
+		AFTER INSERT OR UPDATE OR DELETE ON "ESPECIFICACAO_TECNICA"
+		FOR EACH ROW EXECUTE FUNCTION especificacao_tecnica_historico()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create trg_especificacao_historico: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe"
+		)
+		SELECT
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "AtualizadoEm"
+		FROM "ESPECIFICACAO_TECNICA"
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to seed ESPECIFICACAO_TECNICA_HISTORICO: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit ESPECIFICACAO_TECNICA_HISTORICO migration: %w", err)
+	}
+
+	return nil
+}
+
+// addEspecificacaoUniqueConstraint removes duplicated specs (keeping the most recent row)
+// and creates the unique index used by EspecificacaoRepository.Upsert. It is
+// skipped once addEspecificacaoCondicao has replaced the index.
+func addEspecificacaoUniqueConstraint(ctx context.Context, pool *pgxpool.Pool) error {
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM pg_indexes
+			WHERE schemaname = 'public'
+			AND indexname IN ('uq_especificacao_aplicacao_tipo', 'uq_especificacao_aplicacao_tipo_condicao')
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if uq_especificacao_aplicacao_tipo exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = pool.Exec(ctx, `
+		DELETE FROM "ESPECIFICACAO_TECNICA" e
+		USING "ESPECIFICACAO_TECNICA" newer
+		WHERE e."CodigoAplicacao" = newer."CodigoAplicacao"
+		AND e."TipoFluido" = newer."TipoFluido"
+		AND e."ID" < newer."ID"
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to remove duplicated especificacoes: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE UNIQUE INDEX IF NOT EXISTS "uq_especificacao_aplicacao_tipo"
+		ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao", "TipoFluido")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create uq_especificacao_aplicacao_tipo: %w", err)
+	}
+
+	return nil
+}
+
+// migrateTipoFluidoCodes rewrites legacy Portuguese "TipoFluido" values to their
+// canonical code (model.LegacyTipoFluido). When a vehicle already has a row
+// with the code, the legacy row is dropped to respect the unique index.
+func migrateTipoFluidoCodes(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin TipoFluido migration: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for legacy, code := range model.LegacyTipoFluido {
+		_, err := tx.Exec(ctx, `
+			DELETE FROM "ESPECIFICACAO_TECNICA" legacy
+			USING "ESPECIFICACAO_TECNICA" coded
+			WHERE legacy."CodigoAplicacao" = coded."CodigoAplicacao"
+			AND legacy."TipoFluido" = $1
+			AND coded."TipoFluido" = $2
+		`, legacy, code)
+		if err != nil {
+			return fmt.Errorf("failed to remove superseded %q specs: %w", legacy, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE "ESPECIFICACAO_TECNICA"
+			SET "TipoFluido" = $2
+			WHERE "TipoFluido" = $1
+		`, legacy, code)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %q specs: %w", legacy, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit TipoFluido migration: %w", err)
+	}
+
+	return nil
+}
+
+// widenEspecificacaoNorma changes "Norma" from VARCHAR(100) to TEXT in the specs
+// and their history, so long lists of standards and OEM approvals
+// ("ACEA C3, API SN, VW 504.00, VW 507.00, MB 229.51, ...") are not rejected
+func widenEspecificacaoNorma(ctx context.Context, pool *pgxpool.Pool) error {
+	for _, table := range []string{"ESPECIFICACAO_TECNICA", "ESPECIFICACAO_TECNICA_HISTORICO"} {
+		var dataType string
+		err := pool.QueryRow(ctx, `
+			SELECT data_type FROM information_schema.columns
+			WHERE table_schema = 'public'
+			AND table_name = $1
+			AND column_name = 'Norma'
+		`, table).Scan(&dataType)
+		if err != nil {
+			return fmt.Errorf("failed to check %s.Norma type: %w", table, err)
+		}
+
+		if dataType == "text" {
+			continue
+		}
+
+		_, err = pool.Exec(ctx, fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN "Norma" TYPE TEXT`, table))
+		if err != nil {
+			return fmt.Errorf("failed to widen %s.Norma: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// addEspecificacaoValoresNumericos adds "CapacidadeLitros" (first capacity in
+// liters) and "ViscosidadesSAE" (every SAE grade, canonical "5W-30") to the
+// specs and their history, parsed from the free-text "Capacidade" and
+// "Viscosidade". Existing rows are backfilled once, with the history trigger
+// disabled so the backfill does not create new versions.
+func addEspecificacaoValoresNumericos(ctx context.Context, pool *pgxpool.Pool) error {
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = 'public'
+			AND table_name = 'ESPECIFICACAO_TECNICA'
+			AND column_name = 'CapacidadeLitros'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if ESPECIFICACAO_TECNICA.CapacidadeLitros exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin numeric spec columns migration: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, table := range []string{"ESPECIFICACAO_TECNICA", "ESPECIFICACAO_TECNICA_HISTORICO"} {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`
+			ALTER TABLE %q
+				ADD COLUMN IF NOT EXISTS "CapacidadeLitros" NUMERIC(7,2),
+				ADD COLUMN IF NOT EXISTS "ViscosidadesSAE" TEXT[]
+		`, table))
+		if err != nil {
+			return fmt.Errorf("failed to add numeric columns to %s: %w", table, err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_especificacao_capacidade_litros"
+		ON "ESPECIFICACAO_TECNICA"("CapacidadeLitros")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_capacidade_litros: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_especificacao_viscosidades_sae"
+		ON "ESPECIFICACAO_TECNICA" USING GIN ("ViscosidadesSAE")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_especificacao_viscosidades_sae: %w", err)
+	}
+
+	// The history trigger copies the new columns from now on
+	if err := updateHistoricoTrigger(ctx, tx, "CapacidadeLitros", "ViscosidadesSAE"); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		ALTER TABLE "ESPECIFICACAO_TECNICA" DISABLE TRIGGER "trg_especificacao_historico"
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to disable trg_especificacao_historico: %w", err)
+	}
+
+	if err := backfillValoresNumericos(ctx, tx, "ESPECIFICACAO_TECNICA", "ID"); err != nil {
+		return err
+	}
+	if err := backfillValoresNumericos(ctx, tx, "ESPECIFICACAO_TECNICA_HISTORICO", "HistoricoID"); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		ALTER TABLE "ESPECIFICACAO_TECNICA" ENABLE TRIGGER "trg_especificacao_historico"
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to enable trg_especificacao_historico: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit numeric spec columns migration: %w", err)
+	}
+
+	return nil
+}
+
+// updateHistoricoTrigger replaces the ESPECIFICACAO_TECNICA_HISTORICO trigger
+// function so it also copies columns added after the history table; columns
+// must list every column added so far
+func updateHistoricoTrigger(ctx context.Context, tx pgx.Tx, columns ...string) error {
+	var names, values string
+	for _, column := range columns {
+		names += fmt.Sprintf(", %q", column)
+		values += fmt.Sprintf(", NEW.%q", column)
+	}
+
+	_, err := tx.Exec(ctx, `
+		CREATE OR REPLACE FUNCTION especificacao_tecnica_historico() RETURNS TRIGGER AS $$
+		BEGIN
+			IF TG_OP IN ('UPDATE', 'DELETE') THEN
+				UPDATE "ESPECIFICACAO_TECNICA_HISTORICO"
+				SET "ValidoAte" = NOW()
+				WHERE "ID" = OLD."ID" AND "ValidoAte" IS NULL;
+			END IF;
+
+			IF TG_OP IN ('INSERT', 'UPDATE') THEN
+				INSERT INTO "ESPECIFICACAO_TECNICA_HISTORICO" (
+					"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+					"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+					"MatchConfidence", "CriadoEm", "AtualizadoEm", "ValidoDe"`+names+`
+				) VALUES (
+					NEW."ID", NEW."CodigoAplicacao", NEW."TipoFluido", NEW."Viscosidade", NEW."Capacidade",
+					NEW."Norma", NEW."Recomendacao", NEW."Observacao", NEW."Fonte", NEW."MotulVehicleTypeId",
+					NEW."MatchConfidence", NEW."CriadoEm", NEW."AtualizadoEm", NOW()`+values+`
+				);
+			END IF;
+
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to update especificacao_tecnica_historico function: %w", err)
+	}
+	return nil
+}
+
+// backfillValoresNumericos parses "Capacidade" and "Viscosidade" of every row
+// of table into the numeric columns
+func backfillValoresNumericos(ctx context.Context, tx pgx.Tx, table, key string) error {
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		SELECT %q, COALESCE("Capacidade", ''), COALESCE("Viscosidade", '')
+		FROM %q
+		WHERE "Capacidade" IS NOT NULL OR "Viscosidade" IS NOT NULL
+	`, key, table))
+	if err != nil {
+		return fmt.Errorf("failed to read %s for backfill: %w", table, err)
+	}
+
+	batch := &pgx.Batch{}
+	update := fmt.Sprintf(`
+		UPDATE %q SET "CapacidadeLitros" = $2, "ViscosidadesSAE" = $3 WHERE %q = $1
+	`, table, key)
+	for rows.Next() {
+		var id int64
+		var capacidade, viscosidade string
+		if err := rows.Scan(&id, &capacidade, &viscosidade); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan %s for backfill: %w", table, err)
+		}
+		litros, grades := parser.NumericSpecValues(capacidade, viscosidade)
+		if litros != nil || grades != nil {
+			batch.Queue(update, id, litros, grades)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s for backfill: %w", table, err)
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to backfill %s: %w", table, err)
+	}
+	return nil
+}
+
+// addEspecificacaoCondicao adds the usage condition ("Condicao", empty = normal
+// use, model.CondicaoUsoCode) to the specs and their history, and widens the
+// unique index to (CodigoAplicacao, TipoFluido, Condicao) so severe-use
+// recommendations are stored next to the default ones. Existing rows become
+// the default spec.
+func addEspecificacaoCondicao(ctx context.Context, pool *pgxpool.Pool) error {
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = 'public'
+			AND table_name = 'ESPECIFICACAO_TECNICA'
+			AND column_name = 'Condicao'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if ESPECIFICACAO_TECNICA.Condicao exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin Condicao migration: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, table := range []string{"ESPECIFICACAO_TECNICA", "ESPECIFICACAO_TECNICA_HISTORICO"} {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`
+			ALTER TABLE %q ADD COLUMN IF NOT EXISTS "Condicao" VARCHAR(30) NOT NULL DEFAULT ''
+		`, table))
+		if err != nil {
+			return fmt.Errorf("failed to add %s.Condicao: %w", table, err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		CREATE UNIQUE INDEX IF NOT EXISTS "uq_especificacao_aplicacao_tipo_condicao"
+		ON "ESPECIFICACAO_TECNICA"("CodigoAplicacao", "TipoFluido", "Condicao")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create uq_especificacao_aplicacao_tipo_condicao: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DROP INDEX IF EXISTS "uq_especificacao_aplicacao_tipo"`)
+	if err != nil {
+		return fmt.Errorf("failed to drop uq_especificacao_aplicacao_tipo: %w", err)
+	}
+
+	// The history trigger copies the condition from now on
+	if err := updateHistoricoTrigger(ctx, tx, "CapacidadeLitros", "ViscosidadesSAE", "Condicao"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit Condicao migration: %w", err)
+	}
+
+	return nil
+}
+
+// addEspecificacaoIntervaloTroca adds the recommended oil change interval
+// ("IntervaloTrocaKm", "IntervaloTrocaMeses", whichever comes first) to the
+// specs and their history. Existing rows keep NULL until scraped again.
+func addEspecificacaoIntervaloTroca(ctx context.Context, pool *pgxpool.Pool) error {
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = 'public'
+			AND table_name = 'ESPECIFICACAO_TECNICA'
+			AND column_name = 'IntervaloTrocaKm'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if ESPECIFICACAO_TECNICA.IntervaloTrocaKm exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin IntervaloTroca migration: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, table := range []string{"ESPECIFICACAO_TECNICA", "ESPECIFICACAO_TECNICA_HISTORICO"} {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`
+			ALTER TABLE %q
+				ADD COLUMN IF NOT EXISTS "IntervaloTrocaKm" INTEGER,
+				ADD COLUMN IF NOT EXISTS "IntervaloTrocaMeses" INTEGER
+		`, table))
+		if err != nil {
+			return fmt.Errorf("failed to add IntervaloTroca columns to %s: %w", table, err)
+		}
+	}
+
+	if err := updateHistoricoTrigger(ctx, tx,
+		"CapacidadeLitros", "ViscosidadesSAE", "Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses",
+	); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit IntervaloTroca migration: %w", err)
+	}
+
+	return nil
+}
+
+// createScraperFalhasTable creates the table for tracking failed scraper attempts
+func createScraperFalhasTable(ctx context.Context, pool *pgxpool.Pool) error {
+	// Check if table exists
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.tables
+			WHERE table_schema = 'public'
+			AND table_name = 'SCRAPER_FALHAS'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if SCRAPER_FALHAS table exists: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	// Create table
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SCRAPER_FALHAS" (
+			"ID" SERIAL PRIMARY KEY,
+			"CodigoAplicacao" INTEGER NOT NULL,
+			"TipoErro" VARCHAR(100) NOT NULL,
+			"MensagemErro" TEXT,
+			"Tentativas" INTEGER NOT NULL DEFAULT 1,
+			"UltimaTentativa" TIMESTAMP NOT NULL DEFAULT NOW(),
+			"ProximaTentativa" TIMESTAMP,
+			"Resolvido" BOOLEAN NOT NULL DEFAULT FALSE,
+			"ResolvidoEm" TIMESTAMP,
+			"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+			CONSTRAINT "fk_falha_aplicacao"
+				FOREIGN KEY ("CodigoAplicacao")
+				REFERENCES "APLICACAO"("CodigoAplicacao")
+				ON DELETE CASCADE,
+			CONSTRAINT "uq_falha_aplicacao"
+				UNIQUE ("CodigoAplicacao")
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAPER_FALHAS table: %w", err)
+	}
+
+	// Create indexes
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_falhas_resolvido"
+		ON "SCRAPER_FALHAS"("Resolvido") WHERE "Resolvido" = FALSE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_falhas_resolvido: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_falhas_proxima_tentativa"
+		ON "SCRAPER_FALHAS"("ProximaTentativa") WHERE "Resolvido" = FALSE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_falhas_proxima_tentativa: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_falhas_tipo"
+		ON "SCRAPER_FALHAS"("TipoErro")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_falhas_tipo: %w", err)
+	}
+
+	return nil
+}
+
+// createAplicacaoPopularidadeTable creates the table holding the demand score of
+// each application, updated by the API on every lookup
+func createAplicacaoPopularidadeTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "APLICACAO_POPULARIDADE" (
+			"CodigoAplicacao" INTEGER PRIMARY KEY,
+			"Consultas" INTEGER NOT NULL DEFAULT 0,
+			"ConsultasSemEspecificacao" INTEGER NOT NULL DEFAULT 0,
+			"Score" DOUBLE PRECISION NOT NULL DEFAULT 0,
+			"UltimaConsulta" TIMESTAMP NOT NULL DEFAULT NOW(),
+			CONSTRAINT "fk_popularidade_aplicacao"
+				FOREIGN KEY ("CodigoAplicacao")
+				REFERENCES "APLICACAO"("CodigoAplicacao")
+				ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create APLICACAO_POPULARIDADE table: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_popularidade_score"
+		ON "APLICACAO_POPULARIDADE"("Score" DESC)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_popularidade_score: %w", err)
+	}
+
+	return nil
+}
+
+// createScraperCheckpointTable creates the table holding the resume point of each scraper run
+func createScraperCheckpointTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SCRAPER_CHECKPOINT" (
+			"RunID" VARCHAR(100) PRIMARY KEY,
+			"UltimoCodigoAplicacao" INTEGER NOT NULL,
+			"IniciadoEm" TIMESTAMP NOT NULL,
+			"SalvoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+			"Sucesso" INTEGER NOT NULL DEFAULT 0,
+			"Falhas" INTEGER NOT NULL DEFAULT 0,
+			"Ignorados" INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAPER_CHECKPOINT table: %w", err)
+	}
+
+	return nil
+}
+
+// createAPIQuotaTable creates the table holding the request quota of each API key.
+// Only the SHA-256 of the key is stored; UsoDia is reset daily by the API job runner.
+func createAPIQuotaTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "API_QUOTA" (
+			"ID" SERIAL PRIMARY KEY,
+			"Nome" VARCHAR(100) NOT NULL,
+			"ChaveHash" CHAR(64) NOT NULL UNIQUE,
+			"ChavePrefixo" VARCHAR(20) NOT NULL,
+			"RequisicoesDia" INTEGER NOT NULL DEFAULT 0,
+			"Burst" INTEGER NOT NULL DEFAULT 0,
+			"Ativo" BOOLEAN NOT NULL DEFAULT TRUE,
+			"UsoDia" INTEGER NOT NULL DEFAULT 0,
+			"DiaUso" DATE NOT NULL DEFAULT CURRENT_DATE,
+			"CriadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+			"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create API_QUOTA table: %w", err)
+	}
+
+	return nil
+}
+
+// createScraperQueueTable creates the work queue claimed by distributed scraper instances
+func createScraperQueueTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SCRAPER_QUEUE" (
+			"RunID" VARCHAR(100) NOT NULL,
+			"CodigoAplicacao" INTEGER NOT NULL,
+			"Ordem" INTEGER NOT NULL,
+			"Status" VARCHAR(20) NOT NULL DEFAULT 'pendente',
+			"Worker" VARCHAR(100),
+			"ReivindicadoEm" TIMESTAMP,
+			"ConcluidoEm" TIMESTAMP,
+			"Tentativas" INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY ("RunID", "CodigoAplicacao")
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAPER_QUEUE table: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_scraper_queue_claim"
+		ON "SCRAPER_QUEUE"("RunID", "Status", "Ordem")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_scraper_queue_claim: %w", err)
+	}
+
+	return nil
+}
+
+// createScraperRunTable creates the table holding the summary metrics of each
+// finished scraper run, exported by the API in OpenMetrics format
+func createScraperRunTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SCRAPER_RUN" (
+			"ID" SERIAL PRIMARY KEY,
+			"RunID" VARCHAR(100) NOT NULL,
+			"Provedor" VARCHAR(50) NOT NULL,
+			"Worker" VARCHAR(100) NOT NULL DEFAULT '',
+			"Status" VARCHAR(20) NOT NULL,
+			"IniciadoEm" TIMESTAMPTZ NOT NULL,
+			"FinalizadoEm" TIMESTAMPTZ NOT NULL,
+			"DuracaoSegundos" DOUBLE PRECISION NOT NULL,
+			"Total" INTEGER NOT NULL DEFAULT 0,
+			"Processados" INTEGER NOT NULL DEFAULT 0,
+			"Sucesso" INTEGER NOT NULL DEFAULT 0,
+			"Falhas" INTEGER NOT NULL DEFAULT 0,
+			"Ignorados" INTEGER NOT NULL DEFAULT 0,
+			"MatchExato" INTEGER NOT NULL DEFAULT 0,
+			"MatchFuzzy" INTEGER NOT NULL DEFAULT 0,
+			"SemMatch" INTEGER NOT NULL DEFAULT 0,
+			"Requisicoes" INTEGER NOT NULL DEFAULT 0,
+			"ErrosRede" INTEGER NOT NULL DEFAULT 0,
+			"RateLimit" INTEGER NOT NULL DEFAULT 0,
+			"TokensLLM" BIGINT NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAPER_RUN table: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_scraper_run_finalizado"
+		ON "SCRAPER_RUN"("FinalizadoEm" DESC)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_scraper_run_finalizado: %w", err)
+	}
+
+	return nil
+}
+
+// addAplicacaoCriadoEm adds the import timestamp to APLICACAO. Existing rows keep
+// NULL (imported before tracking); rows inserted afterwards get NOW() by default.
+func addAplicacaoCriadoEm(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		ALTER TABLE "APLICACAO" ADD COLUMN IF NOT EXISTS "CriadoEm" TIMESTAMPTZ
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add APLICACAO.CriadoEm: %w", err)
+	}
+
+	// Set separately so the default does not backfill existing rows
+	_, err = pool.Exec(ctx, `
+		ALTER TABLE "APLICACAO" ALTER COLUMN "CriadoEm" SET DEFAULT NOW()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to set APLICACAO.CriadoEm default: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		CREATE INDEX IF NOT EXISTS "idx_aplicacao_criado_em"
+		ON "APLICACAO"("CriadoEm")
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idx_aplicacao_criado_em: %w", err)
+	}
+
+	return nil
+}
+
+// addScraperRunDetails adds the run configuration, failure breakdown, error-type
+// histogram and final error to SCRAPER_RUN, so runs can be compared in the API
+func addScraperRunDetails(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		ALTER TABLE "SCRAPER_RUN"
+			ADD COLUMN IF NOT EXISTS "Configuracao" JSONB,
+			ADD COLUMN IF NOT EXISTS "FalhasPorMotivo" JSONB,
+			ADD COLUMN IF NOT EXISTS "TiposErro" JSONB,
+			ADD COLUMN IF NOT EXISTS "Erro" TEXT
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add SCRAPER_RUN details: %w", err)
+	}
+	return nil
+}
+
+// createAliasVeiculoTable creates the Wega -> Motul brand and model mappings,
+// learned by the scraper or curated offline and imported in bulk
+func createAliasVeiculoTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "ALIAS_VEICULO" (
+			"Tipo" VARCHAR(10) NOT NULL,
+			"Marca" VARCHAR(100) NOT NULL DEFAULT '',
+			"Origem" VARCHAR(255) NOT NULL,
+			"Destino" VARCHAR(255) NOT NULL,
+			"Fonte" VARCHAR(20) NOT NULL DEFAULT 'aprendido',
+			"AtualizadoEm" TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY ("Tipo", "Marca", "Origem")
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create ALIAS_VEICULO table: %w", err)
+	}
+
+	return nil
+}
+
+// createScraperTentativaTable creates the table with the last outcome of each
+// vehicle searched by the scraper, used by the API coverage report to tell
+// vehicles without a match from vehicles never attempted
+func createScraperTentativaTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SCRAPER_TENTATIVA" (
+			"CodigoAplicacao" INTEGER PRIMARY KEY
+				REFERENCES "APLICACAO"("CodigoAplicacao") ON DELETE CASCADE,
+			"Resultado" VARCHAR(20) NOT NULL,
+			"Tentativas" INTEGER NOT NULL DEFAULT 1,
+			"UltimaTentativa" TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAPER_TENTATIVA table: %w", err)
+	}
+
+	return nil
+}
+
+// createRelatorioCompletudeTable creates the per-brand catalog completeness
+// snapshots written by the API's scheduled report
+func createRelatorioCompletudeTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "RELATORIO_COMPLETUDE" (
+			"GeradoEm" TIMESTAMP NOT NULL,
+			"CodigoFabricante" INTEGER NOT NULL,
+			"Fabricante" VARCHAR(255) NOT NULL,
+			"Aplicacoes" INTEGER NOT NULL,
+			"ComFiltro" INTEGER NOT NULL,
+			"ComEspecificacao" INTEGER NOT NULL,
+			"ComReferencia" INTEGER NOT NULL,
+			PRIMARY KEY ("GeradoEm", "CodigoFabricante")
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create RELATORIO_COMPLETUDE table: %w", err)
+	}
+
+	return nil
+}
+
+// createClassificacaoVeiculoTable caches the vehicle category the LLM picked
+// for each application, so each vehicle is classified only once
+func createClassificacaoVeiculoTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "CLASSIFICACAO_VEICULO" (
+			"CodigoAplicacao" INTEGER PRIMARY KEY
+				REFERENCES "APLICACAO"("CodigoAplicacao") ON DELETE CASCADE,
+			"Categoria" VARCHAR(20) NOT NULL,
+			"ClassificadoEm" TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create CLASSIFICACAO_VEICULO table: %w", err)
+	}
+
+	return nil
+}
+
+// createScraperRunTokensTable creates the LLM token usage of each run, per
+// provider and API key (identified by its last 4 characters only)
+func createScraperRunTokensTable(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SCRAPER_RUN_TOKENS" (
+			"RunID" INTEGER NOT NULL REFERENCES "SCRAPER_RUN"("ID") ON DELETE CASCADE,
+			"Provedor" VARCHAR(50) NOT NULL,
+			"Chave" VARCHAR(20) NOT NULL DEFAULT '',
+			"Requisicoes" BIGINT NOT NULL DEFAULT 0,
+			"TokensPrompt" BIGINT NOT NULL DEFAULT 0,
+			"TokensResposta" BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY ("RunID", "Provedor", "Chave")
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAPER_RUN_TOKENS table: %w", err)
+	}
+
+	return nil
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5/multitracer"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"wega-catalog-api/internal/config"
@@ -20,7 +24,20 @@ func NewPostgresPool(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 		cfg.Name,
 		cfg.SSLMode,
 	)
+	return newPool(dsn, cfg)
+}
+
+// NewReplicaPool connects to the read-only replica in cfg.ReplicaDSN, with the
+// pool size and query settings of the primary
+func NewReplicaPool(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	pool, err := newPool(cfg.ReplicaDSN, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("replica: %w", err)
+	}
+	return pool, nil
+}
 
+func newPool(dsn string, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
@@ -30,6 +47,20 @@ func NewPostgresPool(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	poolConfig.MinConns = int32(cfg.MinConns)
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
+	poolConfig.ConnConfig.Tracer = multitracer.New(
+		otelpgx.NewTracer(),
+		&queryTracer{timeout: cfg.QueryTimeout, slow: cfg.SlowQueryThreshold},
+	)
+	// A canceled context (request or query deadline) sends a cancel request so
+	// the server stops the statement and the connection returns to the pool,
+	// instead of the default of closing the connection mid-query
+	poolConfig.ConnConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{
+			Conn:               conn,
+			CancelRequestDelay: 0,
+			DeadlineDelay:      time.Second, // Fallback when the server does not answer the cancel
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -40,6 +71,7 @@ func NewPostgresPool(cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	}
 
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
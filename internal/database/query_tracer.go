@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryTimeoutKey carries a per-request override of the query timeout
+type queryTimeoutKey struct{}
+
+// queryTraceKey carries the state of a running query from start to end
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	sql    string
+	start  time.Time
+	cancel context.CancelFunc
+}
+
+// WithQueryTimeout overrides the per-query timeout for the queries run with
+// ctx; 0 removes it, for streaming exports and reports bounded by their own
+// deadline
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// queryTracer bounds every query with its own deadline (on top of the request
+// deadline) and logs the ones slower than the threshold. pgx runs the query
+// with the context returned by TraceQueryStart and calls TraceQueryEnd when
+// the rows are closed, so the deadline covers the whole result set.
+type queryTracer struct {
+	timeout time.Duration // 0 disables the per-query deadline
+	slow    time.Duration // 0 disables the slow query log
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	trace := &queryTrace{sql: data.SQL, start: time.Now()}
+
+	timeout := t.timeout
+	if override, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+		timeout = override
+	}
+	if timeout > 0 {
+		ctx, trace.cancel = context.WithTimeout(ctx, timeout)
+	}
+	return context.WithValue(ctx, queryTraceKey{}, trace)
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+	if trace.cancel != nil {
+		trace.cancel()
+	}
+
+	elapsed := time.Since(trace.start)
+	switch {
+	case timedOut && data.Err != nil:
+		slog.Warn("consulta cancelada por prazo", "duracao_ms", elapsed.Milliseconds(), "sql", compactSQL(trace.sql))
+	case t.slow > 0 && elapsed >= t.slow:
+		slog.Warn("consulta lenta",
+			"duracao_ms", elapsed.Milliseconds(),
+			"linhas", data.CommandTag.RowsAffected(),
+			"sql", compactSQL(trace.sql),
+			"error", data.Err,
+		)
+	}
+}
+
+// compactSQL collapses the indentation of a query into a single log line
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is the read side of a connection pool, satisfied by *pgxpool.Pool
+// and *ReadPool, so read-only repositories can run on a replica
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ReadPool sends reads to a read-only replica while it is healthy and to the
+// primary otherwise. Check updates the health from a ping and the replication
+// lag; a query that fails to reach the replica marks it unhealthy and is
+// retried on the primary.
+type ReadPool struct {
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+	maxLag  time.Duration // 0 accepts any lag
+
+	healthy atomic.Bool
+}
+
+// NewReadPool routes reads to replica, falling back to primary. A nil replica
+// sends every read to primary.
+func NewReadPool(primary, replica *pgxpool.Pool, maxLag time.Duration) *ReadPool {
+	p := &ReadPool{primary: primary, replica: replica, maxLag: maxLag}
+	p.healthy.Store(replica != nil)
+	return p
+}
+
+// UsingReplica reports whether reads currently go to the replica
+func (p *ReadPool) UsingReplica() bool {
+	return p.replica != nil && p.healthy.Load()
+}
+
+func (p *ReadPool) pool() *pgxpool.Pool {
+	if p.UsingReplica() {
+		return p.replica
+	}
+	return p.primary
+}
+
+func (p *ReadPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	pool := p.pool()
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil && pool == p.replica && replicaUnreachable(ctx, err) {
+		p.setHealthy(false, err)
+		return p.primary.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+// QueryRow runs on the pool chosen when it is called; its errors only surface
+// on Scan, so a replica failure here is left to the next Check
+func (p *ReadPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return p.pool().QueryRow(ctx, sql, args...)
+}
+
+// Check pings the replica and measures its replication lag, switching reads
+// between replica and primary when the result changes
+func (p *ReadPool) Check(ctx context.Context) error {
+	if p.replica == nil {
+		return nil
+	}
+
+	var lag float64
+	err := p.replica.QueryRow(ctx, `
+		SELECT COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	`).Scan(&lag)
+	if err == nil && p.maxLag > 0 && time.Duration(lag*float64(time.Second)) > p.maxLag {
+		err = fmt.Errorf("replication lag %.1fs above %s", lag, p.maxLag)
+	}
+	p.setHealthy(err == nil, err)
+	return err
+}
+
+// Close closes the replica pool; the primary belongs to the caller
+func (p *ReadPool) Close() {
+	if p.replica != nil {
+		p.replica.Close()
+	}
+}
+
+func (p *ReadPool) setHealthy(healthy bool, err error) {
+	if p.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		slog.Info("replica de leitura recuperada, leituras voltam para a replica")
+	} else {
+		slog.Warn("replica de leitura indisponivel, leituras vao para o primario", "error", err)
+	}
+}
+
+// replicaUnreachable tells connection failures from errors returned by the
+// server for the statement itself (which would fail on the primary too) and
+// from the caller giving up
+func replicaUnreachable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	return !errors.As(err, &pgErr)
+}
@@ -0,0 +1,62 @@
+// Package export writes tabular catalog dumps row by row, so large exports
+// stream to the client instead of being built in memory.
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Supported output formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// RowWriter writes a table one row at a time; the first row is the header.
+// Close must be called to finish the file.
+type RowWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// NewWriter returns the writer for a format, or false if it is not supported
+func NewWriter(format string, w io.Writer, sheet string) (RowWriter, bool) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), true
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheet), true
+	default:
+		return nil, false
+	}
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// CSVWriter writes rows as CSV
+type CSVWriter struct {
+	cw *csv.Writer
+}
+
+// NewCSVWriter creates a CSV writer
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{cw: csv.NewWriter(w)}
+}
+
+// WriteRow writes one record; csv.Writer flushes to w as its buffer fills
+func (c *CSVWriter) WriteRow(values []string) error {
+	return c.cw.Write(values)
+}
+
+// Close flushes the buffered rows
+func (c *CSVWriter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
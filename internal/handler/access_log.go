@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLog registra uma linha estruturada por requisicao: metodo, rota do
+// chi, status, latencia, request ID, IP do cliente e bytes da resposta.
+// Respostas de sucesso (abaixo de 400) sao amostradas com sampleRate (0 a 1),
+// que vai no log para os totais poderem ser reescalados; erros sao sempre
+// registrados, 4xx como warn e 5xx como error.
+func AccessLog(logger *slog.Logger, sampleRate float64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inicio := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			// Em defer para registrar tambem as conexoes abortadas (panic http.ErrAbortHandler)
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				level := slog.LevelInfo
+				switch {
+				case status >= 500:
+					level = slog.LevelError
+				case status >= 400:
+					level = slog.LevelWarn
+				case sampleRate < 1 && rand.Float64() >= sampleRate:
+					return
+				}
+
+				attrs := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("route", rotaDaRequisicao(r)),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Float64("latency_ms", float64(time.Since(inicio).Microseconds())/1000),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("client_ip", r.RemoteAddr),
+					slog.Int("bytes", ww.BytesWritten()),
+				}
+				if level == slog.LevelInfo && sampleRate < 1 {
+					attrs = append(attrs, slog.Float64("sample_rate", sampleRate))
+				}
+				if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+					attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+				}
+				logger.LogAttrs(r.Context(), level, "requisicao", attrs...)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// rotaDaRequisicao retorna o padrao da rota do chi ("/api/v1/especificacoes/aplicacao/{id}"),
+// que agrupa as requisicoes da mesma rota; vazio quando nenhuma rota casou
+func rotaDaRequisicao(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
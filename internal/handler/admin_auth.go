@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+// AdminAuth protege as rotas administrativas com a chave ADMIN_API_KEY
+// A chave pode ser enviada em "Authorization: Bearer <chave>" ou no header "X-Admin-Key".
+// Sem chave configurada, as rotas administrativas ficam desabilitadas.
+func AdminAuth(apiKey string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey == "" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(model.ErrorResponse{
+					Error:   "admin_disabled",
+					Message: "Endpoints administrativos desabilitados (ADMIN_API_KEY nao configurada)",
+				})
+				return
+			}
+
+			provided := r.Header.Get("X-Admin-Key")
+			if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+				provided = strings.TrimPrefix(auth, "Bearer ")
+			}
+
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(model.ErrorResponse{
+					Error:   "unauthorized",
+					Message: "Chave administrativa invalida ou ausente",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxAtorAdmin e o tamanho das colunas "Ator" das auditorias
+const maxAtorAdmin = 100
+
+// atorAdmin identifica quem fez uma alteracao administrativa nas auditorias:
+// o header X-Admin-User ou "admin", ja que a chave administrativa e uma so
+func atorAdmin(r *http.Request) string {
+	ator := strings.TrimSpace(r.Header.Get("X-Admin-User"))
+	if ator == "" {
+		return "admin"
+	}
+	if len(ator) > maxAtorAdmin {
+		ator = ator[:maxAtorAdmin]
+	}
+	return ator
+}
+
+// AtorAuditoria identifica nas alteracoes gravadas pelos repositorios
+// auditados quem fez a requisicao administrativa e o seu request ID
+func AtorAuditoria(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := repository.ComAtor(r.Context(), atorAdmin(r), middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
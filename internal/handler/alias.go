@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"wega-catalog-api/internal/model"
+)
+
+// maxAliasImportBytes limita o tamanho do CSV aceito na importacao
+const maxAliasImportBytes = 10 << 20
+
+// AliasStore exporta e importa os aliases de veiculos. repository.AliasRepo e
+// repository.AliasAuditado a implementam.
+type AliasStore interface {
+	List(ctx context.Context, tipo string) ([]model.AliasVeiculo, error)
+	Import(ctx context.Context, aliases []model.AliasVeiculo) (*model.AliasImportResponse, error)
+}
+
+type AliasHandler struct {
+	repo AliasStore
+}
+
+func NewAliasHandler(repo AliasStore) *AliasHandler {
+	return &AliasHandler{repo: repo}
+}
+
+// Export retorna os aliases de marca e modelo em CSV, para correcao em planilha
+func (h *AliasHandler) Export(w http.ResponseWriter, r *http.Request) {
+	tipo := r.URL.Query().Get("tipo")
+	if tipo != "" && tipo != model.AliasMarca && tipo != model.AliasModelo {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_tipo",
+			Message: "tipo deve ser marca ou modelo",
+		})
+		return
+	}
+
+	aliases, err := h.repo.List(r.Context(), tipo)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao listar aliases",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="aliases.csv"`)
+	model.EscreverAliasesCSV(w, aliases)
+}
+
+// Import grava em lote os aliases de um CSV no formato do export. Linhas com
+// destino vazio removem o alias; as demais sao gravadas como curadas e nao
+// sao mais sobrescritas pelo scraper.
+func (h *AliasHandler) Import(w http.ResponseWriter, r *http.Request) {
+	aliases, err := model.LerAliasesCSV(http.MaxBytesReader(w, r.Body, maxAliasImportBytes))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_csv",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.repo.Import(r.Context(), aliases)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao importar aliases",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 1000
+)
+
+type AuditLogHandler struct {
+	repo *repository.AuditLogRepo
+}
+
+func NewAuditLogHandler(repo *repository.AuditLogRepo) *AuditLogHandler {
+	return &AuditLogHandler{repo: repo}
+}
+
+// List consulta o AUDIT_LOG, mais recentes primeiro. Filtros opcionais: ator,
+// entidade (tabela, ex. ESPECIFICACAO_TECNICA), entidade_id, desde e ate
+// (datas YYYY-MM-DD inclusivas ou RFC3339) e limit.
+func (h *AuditLogHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filtro := model.AuditLogFiltro{
+		Ator:       strings.TrimSpace(q.Get("ator")),
+		Entidade:   strings.TrimSpace(q.Get("entidade")),
+		EntidadeID: strings.TrimSpace(q.Get("entidade_id")),
+		Limit:      defaultAuditLogLimit,
+	}
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		filtro.Limit = min(l, maxAuditLogLimit)
+	}
+
+	if param := q.Get("ate"); param != "" {
+		t, err := parseAsOf(param)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_ate",
+				Message: "ate deve ser uma data (YYYY-MM-DD) ou RFC3339",
+			})
+			return
+		}
+		filtro.Ate = &t
+	}
+	if param := q.Get("desde"); param != "" {
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			t, err = time.ParseInLocation(time.DateOnly, param, fusoBrasilia)
+		}
+		if err != nil || (filtro.Ate != nil && !t.Before(*filtro.Ate)) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_desde",
+				Message: "desde deve ser uma data (YYYY-MM-DD) ou RFC3339 anterior a ate",
+			})
+			return
+		}
+		filtro.Desde = &t
+	}
+
+	registros, err := h.repo.Listar(r.Context(), filtro)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao consultar audit log",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.AuditLogResponse{
+		Registros: registros,
+		Total:     len(registros),
+	})
+}
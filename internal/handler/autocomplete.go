@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
+)
+
+const (
+	minAutocompleteQuery     = 2
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 25
+)
+
+type AutocompleteHandler struct {
+	repo service.SugestaoReader
+}
+
+func NewAutocompleteHandler(repo service.SugestaoReader) *AutocompleteHandler {
+	return &AutocompleteHandler{repo: repo}
+}
+
+// Sugerir retorna sugestoes ranqueadas de marcas, modelos e codigos Wega para
+// o texto digitado (q, ao menos 2 caracteres; limit opcional)
+func (h *AutocompleteHandler) Sugerir(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	if utf8.RuneCountInString(q) < minAutocompleteQuery {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_param",
+			Message: "Parametro 'q' deve ter ao menos 2 caracteres",
+		})
+		return
+	}
+
+	limit := defaultAutocompleteLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 || n > maxAutocompleteLimit {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'limit' deve ficar entre 1 e 25",
+			})
+			return
+		}
+		limit = n
+	}
+
+	sugestoes, err := h.repo.Sugerir(ctx, q, limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar sugestoes",
+		})
+		return
+	}
+
+	if sugestoes == nil {
+		sugestoes = []model.Sugestao{}
+	}
+
+	// O mesmo prefixo se repete enquanto o usuario digita: o navegador reaproveita
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.AutocompleteResponse{
+		Query:     q,
+		Sugestoes: sugestoes,
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
+)
+
+// CacheHandler esvazia os caches em memoria da API, pelo nome
+type CacheHandler struct {
+	caches map[string]service.Invalidavel
+}
+
+func NewCacheHandler(caches map[string]service.Invalidavel) *CacheHandler {
+	return &CacheHandler{caches: caches}
+}
+
+// Invalidar esvazia o cache {nome} ou, sem nome, todos, e retorna quantas
+// entradas cada um descartou
+func (h *CacheHandler) Invalidar(w http.ResponseWriter, r *http.Request) {
+	nome := chi.URLParam(r, "nome")
+
+	invalidados := make(map[string]int)
+	if nome == "" {
+		for n, cache := range h.caches {
+			invalidados[n] = cache.Invalidar()
+		}
+	} else {
+		cache, ok := h.caches[nome]
+		if !ok {
+			nomes := make([]string, 0, len(h.caches))
+			for n := range h.caches {
+				nomes = append(nomes, n)
+			}
+			sort.Strings(nomes)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "not_found",
+				Message: "Cache desconhecido; use um de: " + strings.Join(nomes, ", "),
+			})
+			return
+		}
+		invalidados[nome] = cache.Invalidar()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]map[string]int{
+		"invalidados": invalidados,
+	})
+}
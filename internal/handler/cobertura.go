@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultCoberturaLimit = 100
+	maxCoberturaLimit     = 5000
+)
+
+type CoberturaHandler struct {
+	repo *repository.CoberturaRepo
+}
+
+func NewCoberturaHandler(repo *repository.CoberturaRepo) *CoberturaHandler {
+	return &CoberturaHandler{repo: repo}
+}
+
+// Relatorio retorna quantas aplicacoes de cada fabricante (ou modelo) tem
+// especificacao, falha pendente, foram tentadas sem resultado ou nunca foram
+// tentadas (filtros opcionais: agrupar, fabricante, tipo_fluido, limit)
+func (h *CoberturaHandler) Relatorio(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := repository.CoberturaFilter{
+		Agrupamento: q.Get("agrupar"),
+		Fabricante:  q.Get("fabricante"),
+		TipoFluido:  model.TipoFluidoCode(q.Get("tipo_fluido")),
+	}
+
+	switch filter.Agrupamento {
+	case "":
+		filter.Agrupamento = model.CoberturaPorFabricante
+	case model.CoberturaPorFabricante, model.CoberturaPorModelo:
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_param",
+			Message: "Parametro 'agrupar' deve ser fabricante ou modelo",
+		})
+		return
+	}
+
+	if filter.TipoFluido != "" && !model.IsTipoFluidoCode(filter.TipoFluido) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_param",
+			Message: "Parametro 'tipo_fluido' invalido",
+		})
+		return
+	}
+
+	limit := defaultCoberturaLimit
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxCoberturaLimit)
+	}
+
+	grupos, err := h.repo.Grupos(r.Context(), filter)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao calcular cobertura das especificacoes",
+		})
+		return
+	}
+
+	resp := model.CoberturaResponse{
+		Agrupamento: filter.Agrupamento,
+		TipoFluido:  filter.TipoFluido,
+		TotalGrupos: len(grupos),
+		Limit:       limit,
+	}
+	for _, g := range grupos {
+		resp.Resumo.Somar(g)
+	}
+	resp.Grupos = grupos[:min(limit, len(grupos))]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
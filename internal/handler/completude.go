@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
+)
+
+type CompletudeHandler struct {
+	service *service.CompletudeService
+}
+
+func NewCompletudeHandler(svc *service.CompletudeService) *CompletudeHandler {
+	return &CompletudeHandler{service: svc}
+}
+
+// Ultimo retorna o relatorio de completude mais recente por fabricante, com
+// tendencia em relacao ao anterior e metricas abaixo do SLA
+func (h *CompletudeHandler) Ultimo(w http.ResponseWriter, r *http.Request) {
+	relatorio, err := h.service.Ultimo(r.Context())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar relatorio de completude",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if relatorio == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Nenhum relatorio de completude gerado",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(relatorio)
+}
+
+// Gerar gera um relatorio de completude imediatamente, fora do agendamento
+func (h *CompletudeHandler) Gerar(w http.ResponseWriter, r *http.Request) {
+	relatorio, err := h.service.Gerar(r.Context())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao gerar relatorio de completude",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(relatorio)
+}
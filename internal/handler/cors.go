@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"wega-catalog-api/internal/config"
+)
+
+// CORS aplica a politica de CORS configurada. Origens permitidas recebem
+// Access-Control-Allow-Origin com a propria origem (ou "*" quando qualquer
+// origem vale e nao ha credenciais); as demais ficam sem headers de CORS e o
+// navegador bloqueia a resposta. Como a resposta depende da origem, todas
+// levam "Vary: Origin" para caches nao misturarem origens. Requisicoes
+// OPTIONS (preflight) sao respondidas aqui com 204.
+func CORS(cfg config.CORSConfig) func(http.Handler) http.Handler {
+	qualquerOrigem := false
+	for _, origem := range cfg.AllowedOrigins {
+		qualquerOrigem = qualquerOrigem || origem == "*"
+	}
+	if qualquerOrigem && cfg.AllowCredentials {
+		slog.Warn("CORS com credenciais para qualquer origem: qualquer site pode chamar a API com as credenciais do usuario")
+	}
+
+	metodos := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			preflight := r.Method == http.MethodOptions
+			w.Header().Add("Vary", "Origin")
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			origem := r.Header.Get("Origin")
+			if origem != "" && origemPermitida(cfg.AllowedOrigins, origem) {
+				if qualquerOrigem && !cfg.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origem)
+				}
+				if cfg.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if preflight {
+					w.Header().Set("Access-Control-Allow-Methods", metodos)
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+					if maxAge != "" {
+						w.Header().Set("Access-Control-Max-Age", maxAge)
+					}
+				}
+			}
+
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// origemPermitida compara a origem com a lista, sem diferenciar maiusculas;
+// "https://*.dominio" aceita qualquer subdominio (mas nao o proprio dominio)
+func origemPermitida(permitidas []string, origem string) bool {
+	origem = strings.ToLower(origem)
+	for _, permitida := range permitidas {
+		permitida = strings.ToLower(permitida)
+		if permitida == "*" || permitida == origem {
+			return true
+		}
+		if prefixo, dominio, ok := strings.Cut(permitida, "*."); ok {
+			if resto, ok := strings.CutPrefix(origem, prefixo); ok && strings.HasSuffix(resto, "."+dominio) {
+				return true
+			}
+		}
+	}
+	return false
+}
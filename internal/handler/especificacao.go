@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/text/language"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/parser"
+	"wega-catalog-api/internal/repository"
+	"wega-catalog-api/internal/service"
+)
+
+const (
+	defaultEspecificacoesLimit = 100
+	maxEspecificacoesLimit     = 1000
+)
+
+// idiomasSuportados lista os idiomas de resposta; o primeiro e o padrao
+var idiomasSuportados = []language.Tag{
+	language.BrazilianPortuguese,
+	language.English,
+}
+
+var idiomaMatcher = language.NewMatcher(idiomasSuportados)
+
+type EspecificacaoHandler struct {
+	repo         *repository.EspecificacaoRepository
+	service      *service.EspecificacaoService
+	popularidade *repository.PopularidadeRepo
+}
+
+func NewEspecificacaoHandler(
+	repo *repository.EspecificacaoRepository,
+	svc *service.EspecificacaoService,
+	popularidade *repository.PopularidadeRepo,
+) *EspecificacaoHandler {
+	return &EspecificacaoHandler{repo: repo, service: svc, popularidade: popularidade}
+}
+
+// PorAplicacao lista as especificacoes tecnicas (oleos e fluidos) de uma aplicacao
+// O nome do tipo de fluido segue o header Accept-Language (pt-BR ou en)
+// Com ?as_of= (RFC3339 ou AAAA-MM-DD) retorna as especificacoes como estavam naquele instante
+// Com ?ao_vivo=true, aplicacoes sem especificacao gravada sao consultadas no provedor na hora
+func (h *EspecificacaoHandler) PorAplicacao(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da aplicacao deve ser um numero",
+		})
+		return
+	}
+
+	var asOf *time.Time
+	if param := r.URL.Query().Get("as_of"); param != "" {
+		t, err := parseAsOf(param)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'as_of' deve ser uma data (AAAA-MM-DD) ou data/hora RFC3339",
+			})
+			return
+		}
+		asOf = &t
+	}
+
+	aoVivo := false
+	if param := r.URL.Query().Get("ao_vivo"); param != "" {
+		aoVivo, err = strconv.ParseBool(param)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'ao_vivo' deve ser true ou false",
+			})
+			return
+		}
+	}
+
+	resultado, err := h.service.BuscarEspecificacoes(ctx, id, asOf, aoVivo)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar especificacoes",
+		})
+		return
+	}
+
+	// Consultas sem especificacao indicam demanda que o scraper ainda nao atende
+	// (consultas historicas sao suporte, nao demanda)
+	if h.popularidade != nil && asOf == nil {
+		if err := h.popularidade.Record(ctx, id, resultado.Armazenadas == 0); err != nil {
+			slog.Warn("falha ao registrar popularidade", "codigo_aplicacao", id, "error", err)
+		}
+	}
+
+	idioma := idiomaDaRequisicao(r)
+	views := especificacaoViews(resultado.Especificacoes, idioma)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", idioma)
+	json.NewEncoder(w).Encode(model.EspecificacoesResponse{
+		CodigoAplicacao: id,
+		Idioma:          idioma,
+		AsOf:            asOf,
+		AoVivo:          resultado.AoVivo,
+		Especificacoes:  views,
+	})
+}
+
+// Buscar lista especificacoes por filtros, ex.: aplicacoes que usam 5W-30
+// (filtros opcionais: tipo_fluido, viscosidade, capacidade_min, capacidade_max, limit, offset)
+// A viscosidade e normalizada ("5w30" = "5W-30") e casa com qualquer grau da especificacao
+func (h *EspecificacaoHandler) Buscar(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := repository.EspecificacaoFilter{Limit: defaultEspecificacoesLimit}
+	if tipo := q.Get("tipo_fluido"); tipo != "" {
+		filter.TipoFluido = model.TipoFluidoCode(tipo)
+	}
+
+	if param := q.Get("viscosidade"); param != "" {
+		grau, ok := parser.NormalizeViscosity(param)
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'viscosidade' deve ser um grau SAE (ex.: 5W-30)",
+			})
+			return
+		}
+		filter.Viscosidade = grau
+	}
+
+	for _, p := range []struct {
+		nome  string
+		valor **float64
+	}{
+		{"capacidade_min", &filter.CapacidadeMin},
+		{"capacidade_max", &filter.CapacidadeMax},
+	} {
+		param := q.Get(p.nome)
+		if param == "" {
+			continue
+		}
+		litros, err := strconv.ParseFloat(strings.Replace(param, ",", ".", 1), 64)
+		if err != nil || litros < 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro '" + p.nome + "' deve ser um numero de litros",
+			})
+			return
+		}
+		*p.valor = &litros
+	}
+
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = min(limit, maxEspecificacoesLimit)
+	}
+	if offset, err := strconv.Atoi(q.Get("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	specs, total, err := h.repo.Buscar(r.Context(), filter)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar especificacoes",
+		})
+		return
+	}
+
+	idioma := idiomaDaRequisicao(r)
+	views := especificacaoViews(specs, idioma)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", idioma)
+	json.NewEncoder(w).Encode(model.EspecificacoesBuscaResponse{
+		Idioma:         idioma,
+		Especificacoes: views,
+		Total:          total,
+		Limit:          filter.Limit,
+		Offset:         filter.Offset,
+	})
+}
+
+// Componentes lista os tipos de fluido conhecidos com nome traduzido e total de
+// especificacoes, para montar filtros no front-end. Codigos presentes nos dados
+// mas ainda sem traducao aparecem no fim da lista com o proprio codigo como nome.
+func (h *EspecificacaoHandler) Componentes(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.repo.CountByTipoFluido(r.Context())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao contar especificacoes",
+		})
+		return
+	}
+
+	idioma := idiomaDaRequisicao(r)
+	componentes := make([]model.ComponenteInfo, 0, len(model.TiposFluido))
+	for _, codigo := range model.TiposFluido {
+		componentes = append(componentes, model.ComponenteInfo{
+			Codigo: codigo,
+			Nome:   model.NomeTipoFluido(codigo, idioma),
+			Total:  counts[codigo],
+		})
+		delete(counts, codigo)
+	}
+
+	desconhecidos := make([]string, 0, len(counts))
+	for codigo := range counts {
+		desconhecidos = append(desconhecidos, codigo)
+	}
+	sort.Strings(desconhecidos)
+	for _, codigo := range desconhecidos {
+		componentes = append(componentes, model.ComponenteInfo{
+			Codigo: codigo,
+			Nome:   codigo,
+			Total:  counts[codigo],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", idioma)
+	json.NewEncoder(w).Encode(model.ComponentesResponse{
+		Idioma:      idioma,
+		Componentes: componentes,
+	})
+}
+
+// especificacaoViews converte as especificacoes para a resposta, com o tipo de
+// fluido como codigo canonico e o nome no idioma pedido
+func especificacaoViews(specs []model.EspecificacaoTecnica, idioma string) []model.EspecificacaoView {
+	views := make([]model.EspecificacaoView, len(specs))
+	for i, spec := range specs {
+		spec.TipoFluido = model.TipoFluidoCode(spec.TipoFluido)
+		views[i] = model.EspecificacaoView{
+			EspecificacaoTecnica: spec,
+			TipoFluidoNome:       model.NomeTipoFluido(spec.TipoFluido, idioma),
+		}
+	}
+	return views
+}
+
+// idiomaDaRequisicao escolhe o idioma da resposta pelo Accept-Language (padrao pt-BR)
+func idiomaDaRequisicao(r *http.Request) string {
+	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	_, index, confidence := idiomaMatcher.Match(tags...)
+	if confidence == language.No || index == 0 {
+		return model.IdiomaPT
+	}
+	return model.IdiomaEN
+}
+
+// fusoBrasilia e o horario de Brasilia (sem horario de verao desde 2019)
+var fusoBrasilia = time.FixedZone("BRT", -3*60*60)
+
+// parseAsOf interpreta o parametro as_of. Uma data sem hora significa o fim do
+// dia (no horario de Brasilia), ou seja, o que estava valendo naquele dia.
+func parseAsOf(param string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, param); err == nil {
+		return t, nil
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, param, fusoBrasilia)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+// EspecificacaoLoteStore desativa e reativa lotes de especificacoes e desfaz
+// execucoes do scraper. repository.EspecificacaoRepository e
+// repository.EspecificacaoAuditada a implementam.
+type EspecificacaoLoteStore interface {
+	Desativar(ctx context.Context, lote repository.EspecificacaoLote) (int64, error)
+	Reativar(ctx context.Context, lote repository.EspecificacaoLote) (int64, error)
+	RollbackRun(ctx context.Context, runID int, remover bool) (int64, error)
+}
+
+// ScraperRunLookup confere se uma execucao do scraper existe.
+// repository.ScraperRunRepo a implementa.
+type ScraperRunLookup interface {
+	StartedAt(ctx context.Context, id int) (*time.Time, error)
+}
+
+// EspecificacaoAdminHandler tira do ar as especificacoes de um lote ruim do
+// scraper (ex. uma execucao com o matcher quebrado) sem apagar as linhas
+type EspecificacaoAdminHandler struct {
+	repo EspecificacaoLoteStore
+	runs ScraperRunLookup
+}
+
+func NewEspecificacaoAdminHandler(repo EspecificacaoLoteStore, runs ScraperRunLookup) *EspecificacaoAdminHandler {
+	return &EspecificacaoAdminHandler{repo: repo, runs: runs}
+}
+
+// Desativar marca como inativas as especificacoes da fonte gravadas entre
+// desde e ate; elas deixam de aparecer na API ate serem reativadas
+func (h *EspecificacaoAdminHandler) Desativar(w http.ResponseWriter, r *http.Request) {
+	lote, ok := parseLote(w, r)
+	if !ok {
+		return
+	}
+
+	alteradas, err := h.repo.Desativar(r.Context(), lote)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao desativar especificacoes",
+		})
+		return
+	}
+
+	writeLote(w, lote, alteradas)
+}
+
+// Reativar volta a publicar as especificacoes inativas do mesmo lote, depois
+// da revisao
+func (h *EspecificacaoAdminHandler) Reativar(w http.ResponseWriter, r *http.Request) {
+	lote, ok := parseLote(w, r)
+	if !ok {
+		return
+	}
+
+	alteradas, err := h.repo.Reativar(r.Context(), lote)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao reativar especificacoes",
+		})
+		return
+	}
+
+	writeLote(w, lote, alteradas)
+}
+
+// Rollback desfaz o que uma execucao do scraper gravou: por padrao desativa
+// as especificacoes (modo=desativar); modo=remover apaga as linhas. Uma
+// especificacao regravada por uma execucao posterior nao e afetada.
+func (h *EspecificacaoAdminHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da execucao deve ser um numero",
+		})
+		return
+	}
+
+	modo := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("modo")))
+	switch modo {
+	case "":
+		modo = model.RollbackDesativar
+	case model.RollbackDesativar, model.RollbackRemover:
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_modo",
+			Message: "modo deve ser desativar ou remover",
+		})
+		return
+	}
+
+	iniciada, err := h.runs.StartedAt(r.Context(), id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar execucao do scraper",
+		})
+		return
+	}
+	if iniciada == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Execucao do scraper nao encontrada",
+		})
+		return
+	}
+
+	alteradas, err := h.repo.RollbackRun(r.Context(), id, modo == model.RollbackRemover)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao desfazer execucao do scraper",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.RollbackRunResponse{
+		ScraperRunID: id,
+		Modo:         modo,
+		Alteradas:    alteradas,
+	})
+}
+
+// parseLote le fonte, desde e ate (datas YYYY-MM-DD inclusivas ou RFC3339),
+// todos obrigatorios para nao atingir uma fonte inteira por engano
+func parseLote(w http.ResponseWriter, r *http.Request) (repository.EspecificacaoLote, bool) {
+	q := r.URL.Query()
+	lote := repository.EspecificacaoLote{Fonte: strings.TrimSpace(q.Get("fonte"))}
+
+	var code, msg string
+	ate, errAte := parseAsOf(q.Get("ate"))
+	desde, errDesde := time.Parse(time.RFC3339, q.Get("desde"))
+	if errDesde != nil {
+		desde, errDesde = time.ParseInLocation(time.DateOnly, q.Get("desde"), fusoBrasilia)
+	}
+	switch {
+	case lote.Fonte == "" || q.Get("desde") == "" || q.Get("ate") == "":
+		code, msg = "missing_param", "Informe 'fonte', 'desde' e 'ate'"
+	case errAte != nil:
+		code, msg = "invalid_ate", "ate deve ser uma data (YYYY-MM-DD) ou RFC3339"
+	case errDesde != nil || !desde.Before(ate):
+		code, msg = "invalid_desde", "desde deve ser uma data (YYYY-MM-DD) ou RFC3339 anterior a ate"
+	}
+	if code != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Error: code, Message: msg})
+		return lote, false
+	}
+
+	lote.Desde, lote.Ate = desde, ate
+	return lote, true
+}
+
+func writeLote(w http.ResponseWriter, lote repository.EspecificacaoLote, alteradas int64) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.EspecificacoesLoteResponse{
+		Fonte:     lote.Fonte,
+		Desde:     lote.Desde,
+		Ate:       lote.Ate,
+		Alteradas: alteradas,
+	})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/export"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+type ExportHandler struct {
+	repo *repository.ExportRepo
+}
+
+func NewExportHandler(repo *repository.ExportRepo) *ExportHandler {
+	return &ExportHandler{repo: repo}
+}
+
+// contadorEscrita conta os bytes ja enviados ao cliente
+type contadorEscrita struct {
+	w io.Writer
+	n int64
+}
+
+func (c *contadorEscrita) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Exportar envia um conjunto de dados completo do catalogo (produtos,
+// aplicacoes ou referencias) em CSV ou XLSX, escrito conforme as linhas
+// chegam do banco. Filtros opcionais: ?fabricante= e ?tipo= (codigos).
+func (h *ExportHandler) Exportar(w http.ResponseWriter, r *http.Request) {
+	dataset := chi.URLParam(r, "dataset")
+	if !slices.Contains(model.ExportDatasets, dataset) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_dataset",
+			Message: "dataset deve ser " + strings.Join(model.ExportDatasets, ", "),
+		})
+		return
+	}
+
+	q := r.URL.Query()
+	formato := q.Get("formato")
+	if formato == "" {
+		formato = export.FormatCSV
+	}
+
+	var filter model.ExportFilter
+	for _, p := range []struct {
+		nome  string
+		valor *int
+	}{
+		{"fabricante", &filter.Fabricante},
+		{"tipo", &filter.Tipo},
+	} {
+		param := q.Get(p.nome)
+		if param == "" {
+			continue
+		}
+		v, err := strconv.Atoi(param)
+		if err != nil || v <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro '" + p.nome + "' deve ser um codigo numerico",
+			})
+			return
+		}
+		*p.valor = v
+	}
+
+	contador := &contadorEscrita{w: w}
+	out, ok := export.NewWriter(formato, contador, dataset)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_formato",
+			Message: "formato deve ser csv ou xlsx",
+		})
+		return
+	}
+
+	estenderPrazoEscrita(w, r)
+	w.Header().Set("Content-Type", export.ContentType(formato))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+dataset+"."+formato+`"`)
+
+	linhas, err := h.repo.Exportar(r.Context(), dataset, filter, out)
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		falhaExport(w, contador, dataset, linhas, err)
+	}
+}
+
+// AplicacoesNDJSON envia todas as aplicacoes de veiculo com filtros e
+// especificacoes, um objeto JSON por linha (NDJSON), para alimentar indices de
+// busca e data lakes sem paginar a API. Filtro opcional: ?fabricante= (codigo).
+func (h *ExportHandler) AplicacoesNDJSON(w http.ResponseWriter, r *http.Request) {
+	var fabricante int
+	if param := r.URL.Query().Get("fabricante"); param != "" {
+		v, err := strconv.Atoi(param)
+		if err != nil || v <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'fabricante' deve ser um codigo numerico",
+			})
+			return
+		}
+		fabricante = v
+	}
+
+	estenderPrazoEscrita(w, r)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+
+	contador := &contadorEscrita{w: w}
+	// Encode termina cada objeto com \n
+	enc := json.NewEncoder(contador)
+	linhas, err := h.repo.ExportarAplicacoesCompletas(r.Context(), fabricante, func(a model.AplicacaoCompleta) error {
+		return enc.Encode(a)
+	})
+	if err != nil {
+		falhaExport(w, contador, "aplicacoes_completas", linhas, err)
+	}
+}
+
+// estenderPrazoEscrita troca o WriteTimeout do servidor, que o export pode
+// ultrapassar, pelo prazo do contexto da rota
+func estenderPrazoEscrita(w http.ResponseWriter, r *http.Request) {
+	if prazo, ok := r.Context().Deadline(); ok {
+		if err := http.NewResponseController(w).SetWriteDeadline(prazo); err != nil {
+			slog.Warn("falha ao ajustar prazo de escrita do export", "error", err)
+		}
+	}
+}
+
+// falhaExport responde a um erro do export: com nada enviado ainda, um 500
+// normal; com parte do arquivo enviada, aborta a conexao para o cliente nao
+// tomar o arquivo truncado como completo
+func falhaExport(w http.ResponseWriter, contador *contadorEscrita, dataset string, linhas int64, err error) {
+	if contador.n == 0 {
+		w.Header().Del("Content-Disposition")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao exportar " + dataset,
+		})
+		return
+	}
+
+	slog.Error("export interrompido", "dataset", dataset, "linhas", linhas, "error", err)
+	panic(http.ErrAbortHandler)
+}
@@ -5,14 +5,14 @@ import (
 	"net/http"
 
 	"wega-catalog-api/internal/model"
-	"wega-catalog-api/internal/repository"
+	"wega-catalog-api/internal/service"
 )
 
 type FabricanteHandler struct {
-	repo *repository.FabricanteRepo
+	repo service.FabricanteReader
 }
 
-func NewFabricanteHandler(repo *repository.FabricanteRepo) *FabricanteHandler {
+func NewFabricanteHandler(repo service.FabricanteReader) *FabricanteHandler {
 	return &FabricanteHandler{repo: repo}
 }
 
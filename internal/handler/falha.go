@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultFalhasLimit = 100
+	maxFalhasLimit     = 1000
+)
+
+// FalhaStore lista e mantem as falhas do scraper. repository.ScraperFalhaRepo
+// e repository.FalhaAuditada a implementam.
+type FalhaStore interface {
+	List(ctx context.Context, filter repository.FalhaFilter) ([]model.ScraperFalha, int, error)
+	ForceRetry(ctx context.Context, id int) (bool, error)
+	Delete(ctx context.Context, id int) (bool, error)
+	DeleteResolved(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+type FalhaHandler struct {
+	repo FalhaStore
+}
+
+func NewFalhaHandler(repo FalhaStore) *FalhaHandler {
+	return &FalhaHandler{repo: repo}
+}
+
+// List lista falhas do scraper (filtros opcionais: tipo, resolvido, limit, offset)
+func (h *FalhaHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	filter := repository.FalhaFilter{
+		TipoErro: q.Get("tipo"),
+		Limit:    defaultFalhasLimit,
+	}
+
+	if resolvido := q.Get("resolvido"); resolvido != "" {
+		val, err := strconv.ParseBool(resolvido)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'resolvido' deve ser true ou false",
+			})
+			return
+		}
+		filter.Resolvido = &val
+	}
+
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = min(limit, maxFalhasLimit)
+	}
+	if offset, err := strconv.Atoi(q.Get("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	falhas, total, err := h.repo.List(ctx, filter)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar falhas do scraper",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.FalhasResponse{
+		Falhas: falhas,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	})
+}
+
+// Retry forca uma nova tentativa da falha na proxima execucao do scraper
+func (h *FalhaHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da falha deve ser um numero",
+		})
+		return
+	}
+
+	found, err := h.repo.ForceRetry(ctx, id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao agendar nova tentativa",
+		})
+		return
+	}
+
+	if !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Falha nao encontrada",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete remove uma falha pelo ID
+func (h *FalhaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da falha deve ser um numero",
+		})
+		return
+	}
+
+	found, err := h.repo.Delete(ctx, id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao remover falha",
+		})
+		return
+	}
+
+	if !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Falha nao encontrada",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteResolved remove falhas resolvidas ha mais tempo que older_than (padrao 720h)
+func (h *FalhaHandler) DeleteResolved(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	olderThan := 30 * 24 * time.Hour
+	if param := r.URL.Query().Get("older_than"); param != "" {
+		d, err := time.ParseDuration(param)
+		if err != nil || d < 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'older_than' deve ser uma duracao (ex: 720h)",
+			})
+			return
+		}
+		olderThan = d
+	}
+
+	removed, err := h.repo.DeleteResolved(ctx, olderThan)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao remover falhas resolvidas",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{
+		"removidas": removed,
+	})
+}
@@ -8,16 +8,15 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"wega-catalog-api/internal/model"
-	"wega-catalog-api/internal/repository"
 	"wega-catalog-api/internal/service"
 )
 
 type FiltroHandler struct {
 	catalogoSvc *service.CatalogoService
-	produtoRepo *repository.ProdutoRepo
+	produtoRepo service.ProdutoReader
 }
 
-func NewFiltroHandler(catalogoSvc *service.CatalogoService, produtoRepo *repository.ProdutoRepo) *FiltroHandler {
+func NewFiltroHandler(catalogoSvc *service.CatalogoService, produtoRepo service.ProdutoReader) *FiltroHandler {
 	return &FiltroHandler{
 		catalogoSvc: catalogoSvc,
 		produtoRepo: produtoRepo,
@@ -4,15 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"wega-catalog-api/internal/database"
 	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
 )
 
+// readinessTimeout limita cada verificacao do /readyz, abaixo do timeout tipico das probes
+const readinessTimeout = 2 * time.Second
+
 type HealthHandler struct {
 	db *pgxpool.Pool
+
+	// migrado guarda a primeira verificacao de migrations bem sucedida; as
+	// tabelas nao somem depois, entao as probes seguintes nao consultam de novo
+	migrado atomic.Bool
 }
 
 func NewHealthHandler(db *pgxpool.Pool) *HealthHandler {
@@ -41,3 +51,64 @@ func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// Liveness responde 200 enquanto o processo atende requisicoes (/healthz).
+// Nao consulta o banco: uma queda do banco nao deve reiniciar o pod.
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Readiness verifica se a API pode receber trafego (/readyz): banco
+// acessivel e migrations aplicadas. Responde 503 enquanto alguma falhar,
+// tirando o pod do balanceamento sem reinicia-lo.
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	response := model.ReadinessResponse{
+		Status:    "ready",
+		Checks:    map[string]string{"database": "ok", "migrations": "ok"},
+		Timestamp: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := h.db.Ping(ctx); err != nil {
+		response.Checks["database"] = err.Error()
+		response.Checks["migrations"] = "nao verificado"
+	} else if !h.migrado.Load() {
+		if err := database.CheckMigrations(ctx, h.db); err != nil {
+			response.Checks["migrations"] = err.Error()
+		} else {
+			h.migrado.Store(true)
+		}
+	}
+
+	status := http.StatusOK
+	for _, check := range response.Checks {
+		if check != "ok" {
+			response.Status = "not_ready"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+type SystemHealthHandler struct {
+	saudeSvc *service.SaudeService
+}
+
+func NewSystemHealthHandler(saudeSvc *service.SaudeService) *SystemHealthHandler {
+	return &SystemHealthHandler{saudeSvc: saudeSvc}
+}
+
+// Check retorna o score composto de saude (banco, indice de busca, scraper, falhas e provedores)
+// Sempre responde 200; o dashboard decide pelo status e pelo score
+func (h *SystemHealthHandler) Check(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.saudeSvc.Avaliar(ctx))
+}
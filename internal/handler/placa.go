@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
+)
+
+type PlacaHandler struct {
+	service *service.PlacaService
+}
+
+// NewPlacaHandler cria o handler da busca por placa; com svc nil (sem
+// PLATE_API_URL) a rota responde 503
+func NewPlacaHandler(svc *service.PlacaService) *PlacaHandler {
+	return &PlacaHandler{service: svc}
+}
+
+// BuscarPorPlaca resolve a placa no provedor externo e retorna o veiculo, a
+// busca de filtros feita com ele e, quando ela chega a uma aplicacao, suas
+// especificacoes. O nome do tipo de fluido segue o header Accept-Language.
+func (h *PlacaHandler) BuscarPorPlaca(w http.ResponseWriter, r *http.Request) {
+	if h.service == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "placa_indisponivel",
+			Message: "Consulta por placa nao configurada",
+		})
+		return
+	}
+
+	resultado, err := h.service.BuscarPorPlaca(r.Context(), chi.URLParam(r, "placa"))
+	if err != nil {
+		status, code, msg := http.StatusInternalServerError, "database_error", "Erro ao buscar filtros do veiculo"
+		switch {
+		case errors.Is(err, service.ErrPlacaInvalida):
+			status, code, msg = http.StatusBadRequest, "placa_invalida", "Placa invalida: use o formato ABC1234 ou ABC1D23"
+		case errors.Is(err, service.ErrPlacaNaoEncontrada):
+			status, code, msg = http.StatusNotFound, "placa_nao_encontrada", "Nenhum veiculo encontrado para essa placa"
+		case errors.Is(err, service.ErrProvedorPlaca):
+			slog.Warn("falha na consulta de placa", "error", err)
+			status, code, msg = http.StatusBadGateway, "provedor_placa_indisponivel", "Falha ao consultar o provedor de placas"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Error: code, Message: msg})
+		return
+	}
+
+	idioma := idiomaDaRequisicao(r)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", idioma)
+	json.NewEncoder(w).Encode(model.PlacaResponse{
+		Placa:           resultado.Placa,
+		Veiculo:         resultado.Veiculo,
+		Busca:           resultado.Busca,
+		Resultado:       resultado.Resultado,
+		CodigoAplicacao: resultado.CodigoAplicacao,
+		Idioma:          idioma,
+		Especificacoes:  especificacaoViews(resultado.Especificacoes, idioma),
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultPopularidadeLimit = 100
+	maxPopularidadeLimit     = 1000
+)
+
+type PopularidadeHandler struct {
+	repo *repository.PopularidadeRepo
+}
+
+func NewPopularidadeHandler(repo *repository.PopularidadeRepo) *PopularidadeHandler {
+	return &PopularidadeHandler{repo: repo}
+}
+
+// List lista as aplicacoes mais consultadas na API (filtros opcionais: sem_especificacao, limit, offset)
+func (h *PopularidadeHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	filter := repository.PopularidadeFilter{
+		Limit: defaultPopularidadeLimit,
+	}
+
+	if semEspecificacao := q.Get("sem_especificacao"); semEspecificacao != "" {
+		val, err := strconv.ParseBool(semEspecificacao)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'sem_especificacao' deve ser true ou false",
+			})
+			return
+		}
+		filter.SemEspecificacao = val
+	}
+
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = min(limit, maxPopularidadeLimit)
+	}
+	if offset, err := strconv.Atoi(q.Get("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	aplicacoes, total, err := h.repo.List(ctx, filter)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar popularidade das aplicacoes",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.PopularidadeResponse{
+		Aplicacoes: aplicacoes,
+		Total:      total,
+		Limit:      filter.Limit,
+		Offset:     filter.Offset,
+	})
+}
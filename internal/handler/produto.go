@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
+)
+
+type ProdutoHandler struct {
+	precos service.PrecoHistoricoReader
+	fotos  *service.FotoProdutoService // nil sem PRODUCT_IMAGE_BASE_URL
+	// maxDimensao limita largura e altura das fotos (PRODUCT_IMAGE_MAX_SIZE)
+	maxDimensao int
+}
+
+func NewProdutoHandler(precos service.PrecoHistoricoReader, fotos *service.FotoProdutoService, maxDimensao int) *ProdutoHandler {
+	return &ProdutoHandler{precos: precos, fotos: fotos, maxDimensao: maxDimensao}
+}
+
+// Precos retorna o preco atual e as mudancas de preco de um codigo Wega, da
+// mais antiga para a mais recente. desde e ate sao datas (YYYY-MM-DD,
+// inclusivas) ou RFC3339; sem eles vem o historico inteiro.
+func (h *ProdutoHandler) Precos(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var desde, ate *time.Time
+	if param := q.Get("ate"); param != "" {
+		t, err := parseAsOf(param)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_ate",
+				Message: "ate deve ser uma data (YYYY-MM-DD) ou RFC3339",
+			})
+			return
+		}
+		ate = &t
+	}
+	if param := q.Get("desde"); param != "" {
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			t, err = time.ParseInLocation(time.DateOnly, param, fusoBrasilia)
+		}
+		if err != nil || (ate != nil && !t.Before(*ate)) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_desde",
+				Message: "desde deve ser uma data (YYYY-MM-DD) ou RFC3339 anterior a ate",
+			})
+			return
+		}
+		desde = &t
+	}
+
+	historico, err := h.precos.HistoricoPrecos(r.Context(), chi.URLParam(r, "codigo"), desde, ate)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar historico de precos",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if historico == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Produto nao encontrado",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(historico)
+}
+
+// Foto envia a foto do codigo Wega, buscada no host de arquivos da Wega.
+// largura e altura (pixels) reduzem a foto para caber nelas, sem distorcer;
+// formato (jpeg, png ou webp) converte. A resposta pode ser guardada por
+// navegadores e CDNs e responde 304 a um If-None-Match com o mesmo ETag.
+func (h *ProdutoHandler) Foto(w http.ResponseWriter, r *http.Request) {
+	if h.fotos == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "fotos_nao_configuradas",
+			Message: "Fotos de produto nao configuradas (PRODUCT_IMAGE_BASE_URL)",
+		})
+		return
+	}
+
+	q := r.URL.Query()
+	var opcoes service.OpcoesFoto
+	for _, dim := range []struct {
+		nome  string
+		valor *int
+	}{{"largura", &opcoes.Largura}, {"altura", &opcoes.Altura}} {
+		param := q.Get(dim.nome)
+		if param == "" {
+			continue
+		}
+		v, err := strconv.Atoi(param)
+		if err != nil || v < 1 || v > h.maxDimensao {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_" + dim.nome,
+				Message: fmt.Sprintf("%s deve ser um numero de 1 a %d", dim.nome, h.maxDimensao),
+			})
+			return
+		}
+		*dim.valor = v
+	}
+	formato, ok := service.NormalizarFormatoFoto(q.Get("formato"))
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_formato",
+			Message: "formato deve ser jpeg, png ou webp",
+		})
+		return
+	}
+	opcoes.Formato = formato
+
+	foto, err := h.fotos.Foto(r.Context(), chi.URLParam(r, "codigo"), opcoes)
+	if err != nil {
+		status, code, msg := http.StatusInternalServerError, "database_error", "Erro ao buscar foto do produto"
+		switch {
+		case errors.Is(err, service.ErrProdutoNaoEncontrado):
+			status, code, msg = http.StatusNotFound, "not_found", "Produto nao encontrado"
+		case errors.Is(err, service.ErrFotoNaoEncontrada):
+			status, code, msg = http.StatusNotFound, "foto_nao_encontrada", "Produto sem foto"
+		case errors.Is(err, service.ErrFotoIndisponivel):
+			slog.Warn("falha ao buscar foto de produto", "codigo", chi.URLParam(r, "codigo"), "error", err)
+			status, code, msg = http.StatusBadGateway, "foto_indisponivel", "Falha ao buscar a foto no host de arquivos"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Error: code, Message: msg})
+		return
+	}
+
+	w.Header().Set("Cache-Control", h.fotos.CacheControl())
+	w.Header().Set("ETag", foto.ETag)
+	if r.Header.Get("If-None-Match") == foto.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", foto.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(foto.Dados)))
+	w.Write(foto.Dados)
+}
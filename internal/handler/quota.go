@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+)
+
+const (
+	chavePrefixo   = "wk_"
+	chavePrefixLen = 10 // Caracteres da chave exibidos na listagem
+)
+
+// QuotaStore mantem as cotas por chave de API. repository.QuotaRepo e
+// repository.QuotaAuditada a implementam.
+type QuotaStore interface {
+	List(ctx context.Context) ([]model.ApiQuota, error)
+	GetByID(ctx context.Context, id int) (*model.ApiQuota, error)
+	Create(ctx context.Context, req model.QuotaRequest, chaveHash, chavePrefixo string) (*model.ApiQuota, error)
+	Update(ctx context.Context, id int, req model.QuotaRequest) (*model.ApiQuota, error)
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+type QuotaHandler struct {
+	repo QuotaStore
+}
+
+func NewQuotaHandler(repo QuotaStore) *QuotaHandler {
+	return &QuotaHandler{repo: repo}
+}
+
+// List lista as cotas de todas as chaves de API
+func (h *QuotaHandler) List(w http.ResponseWriter, r *http.Request) {
+	quotas, err := h.repo.List(r.Context())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao listar cotas",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.QuotasResponse{
+		Quotas: quotas,
+		Total:  len(quotas),
+	})
+}
+
+// Get retorna a cota de uma chave pelo ID
+func (h *QuotaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := quotaID(w, r)
+	if !ok {
+		return
+	}
+
+	quota, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar cota",
+		})
+		return
+	}
+
+	writeQuota(w, http.StatusOK, quota)
+}
+
+// Create gera uma nova chave de API com a cota informada. A chave so e
+// retornada nesta resposta; o banco guarda apenas o hash.
+func (h *QuotaHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeQuotaRequest(w, r)
+	if !ok {
+		return
+	}
+
+	chave, err := gerarChave()
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Erro ao gerar chave de API",
+		})
+		return
+	}
+
+	quota, err := h.repo.Create(r.Context(), req, hashChave(chave), chave[:chavePrefixLen])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao criar cota",
+		})
+		return
+	}
+
+	quota.Chave = chave
+	writeQuota(w, http.StatusCreated, quota)
+}
+
+// Update altera nome, limites e status da cota de uma chave
+func (h *QuotaHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := quotaID(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeQuotaRequest(w, r)
+	if !ok {
+		return
+	}
+
+	quota, err := h.repo.Update(r.Context(), id, req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao atualizar cota",
+		})
+		return
+	}
+
+	writeQuota(w, http.StatusOK, quota)
+}
+
+// Delete remove uma chave de API e sua cota
+func (h *QuotaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := quotaID(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := h.repo.Delete(r.Context(), id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao remover cota",
+		})
+		return
+	}
+
+	if !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Cota nao encontrada",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// quotaID le o ID da URL, respondendo 400 se invalido
+func quotaID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da cota deve ser um numero",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeQuotaRequest le e valida o corpo, respondendo 400 se invalido
+func decodeQuotaRequest(w http.ResponseWriter, r *http.Request) (model.QuotaRequest, bool) {
+	var req model.QuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "JSON invalido no corpo da requisicao",
+		})
+		return req, false
+	}
+
+	req.Nome = strings.TrimSpace(req.Nome)
+	if req.Nome == "" || req.RequisicoesDia < 0 || req.Burst < 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Campo 'nome' obrigatorio; 'requisicoes_dia' e 'burst' devem ser >= 0",
+		})
+		return req, false
+	}
+
+	return req, true
+}
+
+// writeQuota responde com a cota, ou 404 se ela nao existir
+func writeQuota(w http.ResponseWriter, status int, quota *model.ApiQuota) {
+	w.Header().Set("Content-Type", "application/json")
+	if quota == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Cota nao encontrada",
+		})
+		return
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(quota)
+}
+
+// gerarChave cria uma chave de API aleatoria
+func gerarChave() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return chavePrefixo + hex.EncodeToString(b), nil
+}
+
+// hashChave retorna o SHA-256 da chave, que e o que fica gravado no banco
+func hashChave(chave string) string {
+	sum := sha256.Sum256([]byte(chave))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+// RateLimit aplica a cota da chave enviada no header "X-API-Key": burst (requisicoes
+// por segundo, em memoria) e limite diario (contador no banco, zerado pelo job runner).
+// Sem chave, a requisicao passa, a menos que requireKey esteja ativo.
+// Erros de banco nao bloqueiam o catalogo: a requisicao segue sem contar a cota.
+func RateLimit(repo *repository.QuotaRepo, requireKey bool) func(http.Handler) http.Handler {
+	buckets := newBurstLimiter()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chave := r.Header.Get("X-API-Key")
+			if chave == "" {
+				if requireKey {
+					writeRateLimitError(w, http.StatusUnauthorized, "missing_api_key", "Header X-API-Key obrigatorio")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			quota, err := repo.GetByKeyHash(ctx, hashChave(chave))
+			if err != nil {
+				slog.Warn("falha ao buscar cota da chave", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if quota == nil || !quota.Ativo {
+				writeRateLimitError(w, http.StatusUnauthorized, "invalid_api_key", "Chave de API invalida ou inativa")
+				return
+			}
+
+			if !buckets.allow(quota.ID, quota.Burst) {
+				w.Header().Set("Retry-After", "1")
+				writeRateLimitError(w, http.StatusTooManyRequests, "rate_limited", "Limite de requisicoes por segundo excedido")
+				return
+			}
+
+			uso, ok, err := repo.Consume(ctx, quota.ID)
+			if err != nil {
+				slog.Warn("falha ao contar uso da cota", "quota_id", quota.ID, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				writeRateLimitError(w, http.StatusTooManyRequests, "quota_exceeded", "Cota diaria de requisicoes esgotada")
+				return
+			}
+
+			if quota.RequisicoesDia > 0 {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(quota.RequisicoesDia))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(quota.RequisicoesDia-uso, 0)))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeRateLimitError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(model.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
+
+// burstLimiter mantem um token bucket por cota: ate burst requisicoes seguidas,
+// repostas a burst por segundo
+type burstLimiter struct {
+	mu      sync.Mutex
+	buckets map[int]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newBurstLimiter() *burstLimiter {
+	return &burstLimiter{buckets: make(map[int]*tokenBucket)}
+}
+
+// allow consome um token da cota (burst <= 0 = sem limite)
+func (l *burstLimiter) allow(id, burst int) bool {
+	if burst <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets[id] = b
+	}
+
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*float64(burst), float64(burst))
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
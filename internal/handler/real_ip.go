@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP troca o RemoteAddr pelo IP do cliente informado pelo proxy
+// (X-Forwarded-For, X-Real-IP ou True-Client-IP), mas so quando a conexao vem
+// de um dos proxies confiaveis (CIDRs ou IPs em TRUSTED_PROXIES). Das demais
+// conexoes os headers sao ignorados: senao qualquer cliente escolheria o
+// proprio IP e teria buckets ilimitados no Throttle e no LimitePorIP.
+func RealIP(trustedProxies []string) func(http.Handler) http.Handler {
+	confiaveis := ParseTrustedProxies(trustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(confiaveis) > 0 && ipConfiavel(confiaveis, net.ParseIP(clientIP(r))) {
+				if ip := forwardedIP(r, confiaveis); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ParseTrustedProxies converte a lista de CIDRs ou IPs; entradas invalidas
+// sao ignoradas (a configuracao ja as recusa na validacao)
+func ParseTrustedProxies(values []string) []*net.IPNet {
+	var redes []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				redes = append(redes, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+		if _, rede, err := net.ParseCIDR(value); err == nil {
+			redes = append(redes, rede)
+		}
+	}
+	return redes
+}
+
+// forwardedIP le o IP do cliente dos headers do proxy. No X-Forwarded-For,
+// percorre a lista da direita para a esquerda, pulando os proxies confiaveis:
+// o primeiro IP restante foi adicionado por um proxy nosso, e o que vem antes
+// dele pode ter sido escrito pelo proprio cliente.
+func forwardedIP(r *http.Request, confiaveis []*net.IPNet) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		ips := strings.Split(xff, ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(ips[i]))
+			if ip == nil {
+				return ""
+			}
+			if !ipConfiavel(confiaveis, ip) || i == 0 {
+				return ip.String()
+			}
+		}
+	}
+	for _, header := range []string{"X-Real-IP", "True-Client-IP"} {
+		if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(header))); ip != nil {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+func ipConfiavel(redes []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, rede := range redes {
+		if rede.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
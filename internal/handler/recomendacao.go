@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/service"
+)
+
+type RecomendacaoHandler struct {
+	service *service.RecomendacaoService
+}
+
+func NewRecomendacaoHandler(svc *service.RecomendacaoService) *RecomendacaoHandler {
+	return &RecomendacaoHandler{service: svc}
+}
+
+// RecomendacaoOleo retorna, em uma resposta, os filtros de oleo Wega e o oleo
+// do motor recomendado (viscosidade, capacidade, produtos) de uma aplicacao
+// O nome do tipo de fluido segue o header Accept-Language (pt-BR ou en)
+// Com ?ao_vivo=true, aplicacoes sem especificacao gravada sao consultadas no provedor na hora
+func (h *RecomendacaoHandler) RecomendacaoOleo(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da aplicacao deve ser um numero",
+		})
+		return
+	}
+
+	aoVivo := false
+	if param := r.URL.Query().Get("ao_vivo"); param != "" {
+		aoVivo, err = strconv.ParseBool(param)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro 'ao_vivo' deve ser true ou false",
+			})
+			return
+		}
+	}
+
+	recomendacao, err := h.service.RecomendacaoOleo(r.Context(), id, aoVivo)
+	if errors.Is(err, service.ErrAplicacaoNaoEncontrada) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Aplicacao nao encontrada",
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar recomendacao de oleo",
+		})
+		return
+	}
+
+	idioma := idiomaDaRequisicao(r)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", idioma)
+	json.NewEncoder(w).Encode(model.RecomendacaoOleoResponse{
+		Aplicacao:   recomendacao.Aplicacao,
+		Idioma:      idioma,
+		FiltrosOleo: recomendacao.FiltrosOleo,
+		Oleo:        especificacaoViews(recomendacao.Oleo, idioma),
+		AoVivo:      recomendacao.AoVivo,
+	})
+}
+
+// KitTrocaOleo monta o kit de troca de oleo de uma aplicacao: filtro de oleo,
+// oleo do motor com os litros a comprar e, com ?filtro_ar=true e
+// ?filtro_cabine=true, os filtros de ar e de cabine
+func (h *RecomendacaoHandler) KitTrocaOleo(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da aplicacao deve ser um numero",
+		})
+		return
+	}
+
+	var aoVivo bool
+	var opcoes service.OpcoesKit
+	for _, p := range []struct {
+		nome  string
+		valor *bool
+	}{
+		{"ao_vivo", &aoVivo},
+		{"filtro_ar", &opcoes.FiltroAr},
+		{"filtro_cabine", &opcoes.FiltroCabine},
+	} {
+		nome, valor := p.nome, p.valor
+		param := r.URL.Query().Get(nome)
+		if param == "" {
+			continue
+		}
+		if *valor, err = strconv.ParseBool(param); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "Parametro '" + nome + "' deve ser true ou false",
+			})
+			return
+		}
+	}
+
+	kit, err := h.service.MontarKitTrocaOleo(r.Context(), id, aoVivo, opcoes)
+	if errors.Is(err, service.ErrAplicacaoNaoEncontrada) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Aplicacao nao encontrada",
+		})
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao montar kit de troca de oleo",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(kit)
+}
@@ -5,14 +5,14 @@ import (
 	"net/http"
 
 	"wega-catalog-api/internal/model"
-	"wega-catalog-api/internal/repository"
+	"wega-catalog-api/internal/service"
 )
 
 type ReferenciaHandler struct {
-	repo *repository.ReferenciaRepo
+	repo service.ReferenciaReader
 }
 
-func NewReferenciaHandler(repo *repository.ReferenciaRepo) *ReferenciaHandler {
+func NewReferenciaHandler(repo service.ReferenciaReader) *ReferenciaHandler {
 	return &ReferenciaHandler{repo: repo}
 }
 
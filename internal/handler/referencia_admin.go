@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultReferenciasLimit = 100
+	maxReferenciasLimit     = 1000
+
+	// maxCodigoConcorrente e o tamanho de REFERENCIACRUZADA."NumeroProdutoPesq"
+	maxCodigoConcorrente = 50
+)
+
+// ReferenciaAdminStore mantem REFERENCIACRUZADA. repository.ReferenciaAdminRepo
+// e repository.ReferenciaAdminAuditada a implementam.
+type ReferenciaAdminStore interface {
+	Listar(ctx context.Context, codigoConcorrente, codigoWega string, limit int) ([]model.ReferenciaCruzada, error)
+	Criar(ctx context.Context, req model.ReferenciaRequest, ator string) (*model.ReferenciaCruzada, error)
+	Alterar(ctx context.Context, id int, req model.ReferenciaRequest, ator string) (*model.ReferenciaCruzada, error)
+	Remover(ctx context.Context, id int, ator string) (bool, error)
+	Auditoria(ctx context.Context, referenciaID, limit int) ([]model.AuditoriaReferencia, error)
+}
+
+// ReferenciaAdminHandler mantem as referencias cruzadas publicadas pela Wega
+// sem SQL direto, com auditoria de cada alteracao
+type ReferenciaAdminHandler struct {
+	repo ReferenciaAdminStore
+}
+
+func NewReferenciaAdminHandler(repo ReferenciaAdminStore) *ReferenciaAdminHandler {
+	return &ReferenciaAdminHandler{repo: repo}
+}
+
+// List busca as referencias de um codigo de concorrente (codigo) e/ou de um
+// codigo Wega (codigo_wega), com os IDs usados no PUT e no DELETE
+func (h *ReferenciaAdminHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	codigo := strings.TrimSpace(q.Get("codigo"))
+	codigoWega := strings.TrimSpace(q.Get("codigo_wega"))
+	if codigo == "" && codigoWega == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "missing_param",
+			Message: "Informe 'codigo' (concorrente) ou 'codigo_wega'",
+		})
+		return
+	}
+
+	limit := defaultReferenciasLimit
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxReferenciasLimit)
+	}
+
+	referencias, err := h.repo.Listar(r.Context(), codigo, codigoWega, limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao listar referencias cruzadas",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.ReferenciasResponse{
+		Referencias: referencias,
+		Total:       len(referencias),
+	})
+}
+
+// Create cadastra uma equivalencia nova
+func (h *ReferenciaAdminHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeReferenciaRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ref, err := h.repo.Criar(r.Context(), req, atorAdmin(r))
+	if err != nil {
+		writeReferenciaError(w, err, "Erro ao criar referencia cruzada")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ref)
+}
+
+// Update substitui o codigo do concorrente, o concorrente e o produto Wega de
+// uma referencia
+func (h *ReferenciaAdminHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := referenciaID(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeReferenciaRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ref, err := h.repo.Alterar(r.Context(), id, req, atorAdmin(r))
+	if err != nil {
+		writeReferenciaError(w, err, "Erro ao alterar referencia cruzada")
+		return
+	}
+	if ref == nil {
+		writeReferenciaNaoEncontrada(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ref)
+}
+
+// Delete remove uma referencia; a linha removida fica na auditoria
+func (h *ReferenciaAdminHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := referenciaID(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := h.repo.Remover(r.Context(), id, atorAdmin(r))
+	if err != nil {
+		writeReferenciaError(w, err, "Erro ao remover referencia cruzada")
+		return
+	}
+	if !found {
+		writeReferenciaNaoEncontrada(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Auditoria lista as alteracoes feitas pelo admin, mais recentes primeiro;
+// referencia_id restringe a uma referencia
+func (h *ReferenciaAdminHandler) Auditoria(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var referencia int
+	if param := q.Get("referencia_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_id",
+				Message: "referencia_id deve ser um numero",
+			})
+			return
+		}
+		referencia = id
+	}
+
+	limit := defaultReferenciasLimit
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxReferenciasLimit)
+	}
+
+	auditoria, err := h.repo.Auditoria(r.Context(), referencia, limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao listar auditoria de referencias cruzadas",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.AuditoriaReferenciasResponse{
+		Auditoria: auditoria,
+		Total:     len(auditoria),
+	})
+}
+
+// referenciaID le o ID da URL, respondendo 400 se invalido
+func referenciaID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da referencia deve ser um numero",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeReferenciaRequest le e valida o corpo, respondendo 400 se invalido.
+// O codigo do concorrente fica em maiusculas, como no catalogo.
+func decodeReferenciaRequest(w http.ResponseWriter, r *http.Request) (model.ReferenciaRequest, bool) {
+	var req model.ReferenciaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "JSON invalido no corpo da requisicao",
+		})
+		return req, false
+	}
+
+	req.CodigoConcorrente = strings.ToUpper(strings.TrimSpace(req.CodigoConcorrente))
+	req.CodigoWega = strings.TrimSpace(req.CodigoWega)
+	if req.CodigoConcorrente == "" || len(req.CodigoConcorrente) > maxCodigoConcorrente ||
+		req.CodigoWega == "" || req.CodigoFabricante <= 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Campos 'codigo_concorrente' (ate 50 caracteres), 'codigo_fabricante' e 'codigo_wega' obrigatorios",
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// writeReferenciaError responde 400 para concorrente ou produto inexistente,
+// 409 para referencia duplicada e 500 para os demais erros
+func writeReferenciaError(w http.ResponseWriter, err error, msg string) {
+	status, code := http.StatusInternalServerError, "database_error"
+	switch {
+	case errors.Is(err, repository.ErrReferenciaInvalida):
+		status, code, msg = http.StatusBadRequest, "invalid_referencia", err.Error()
+	case errors.Is(err, repository.ErrReferenciaDuplicada):
+		status, code, msg = http.StatusConflict, "referencia_duplicada", "O produto ja tem esse codigo do mesmo concorrente"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(model.ErrorResponse{Error: code, Message: msg})
+}
+
+func writeReferenciaNaoEncontrada(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(model.ErrorResponse{
+		Error:   "not_found",
+		Message: "Referencia cruzada nao encontrada",
+	})
+}
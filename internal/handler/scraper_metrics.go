@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultScraperRunsLimit = 100
+	maxScraperRunsLimit     = 5000
+
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// scraperRunMetric descreve uma metrica exportada por execucao do scraper
+type scraperRunMetric struct {
+	name  string
+	unit  string
+	help  string
+	value func(model.ScraperRun) float64
+}
+
+var scraperRunMetrics = []scraperRunMetric{
+	{"scraper_run_duration_seconds", "seconds", "Duracao da execucao", func(r model.ScraperRun) float64 { return r.Duration }},
+	{"scraper_run_vehicles", "", "Veiculos da fila da execucao", func(r model.ScraperRun) float64 { return float64(r.Total) }},
+	{"scraper_run_processed", "", "Veiculos processados", func(r model.ScraperRun) float64 { return float64(r.Processed) }},
+	{"scraper_run_success", "", "Veiculos com especificacoes gravadas", func(r model.ScraperRun) float64 { return float64(r.Success) }},
+	{"scraper_run_failed", "", "Veiculos com falha", func(r model.ScraperRun) float64 { return float64(r.Failed) }},
+	{"scraper_run_skipped", "", "Veiculos ignorados (categoria, parse, especificacao recente)", func(r model.ScraperRun) float64 { return float64(r.Skipped) }},
+	{"scraper_run_exact_match", "", "Matches exatos", func(r model.ScraperRun) float64 { return float64(r.ExactMatch) }},
+	{"scraper_run_fuzzy_match", "", "Matches fuzzy", func(r model.ScraperRun) float64 { return float64(r.FuzzyMatch) }},
+	{"scraper_run_no_match", "", "Veiculos sem match ou sem especificacoes", func(r model.ScraperRun) float64 { return float64(r.NoMatch) }},
+	{"scraper_run_success_ratio", "ratio", "Sucesso / (sucesso + falhas + sem match)", model.ScraperRun.SuccessRatio},
+	{"scraper_run_match_ratio", "ratio", "(Exatos + fuzzy) / (sucesso + falhas + sem match)", model.ScraperRun.MatchRatio},
+	{"scraper_run_requests", "", "Buscas no provedor", func(r model.ScraperRun) float64 { return float64(r.Requests) }},
+	{"scraper_run_network_errors", "", "Erros de rede", func(r model.ScraperRun) float64 { return float64(r.NetworkErrors) }},
+	{"scraper_run_rate_limit_hits", "", "Respostas 429 recebidas", func(r model.ScraperRun) float64 { return float64(r.RateLimitHits) }},
+	{"scraper_run_llm_tokens", "", "Tokens de LLM consumidos", func(r model.ScraperRun) float64 { return float64(r.LLMTokens) }},
+	{"scraper_run_finished_timestamp_seconds", "seconds", "Fim da execucao (unix)", func(r model.ScraperRun) float64 {
+		return float64(r.FinishedAt.UnixMilli()) / 1000
+	}},
+}
+
+type ScraperMetricsHandler struct {
+	repo *repository.ScraperRunRepo
+}
+
+func NewScraperMetricsHandler(repo *repository.ScraperRunRepo) *ScraperMetricsHandler {
+	return &ScraperMetricsHandler{repo: repo}
+}
+
+// Runs exporta as metricas das ultimas execucoes do scraper em formato OpenMetrics,
+// uma serie por execucao (labels run, run_id, provider, worker, status).
+// Com timestamps=true cada amostra leva o fim da execucao, para backfill via promtool.
+func (h *ScraperMetricsHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit := defaultScraperRunsLimit
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxScraperRunsLimit)
+	}
+	timestamps, _ := strconv.ParseBool(q.Get("timestamps"))
+
+	runs, err := h.repo.List(r.Context(), limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar execucoes do scraper",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", openMetricsContentType)
+	writeScraperRunMetrics(w, runs, timestamps)
+}
+
+func writeScraperRunMetrics(w io.Writer, runs []model.ScraperRun, timestamps bool) {
+	for _, metric := range scraperRunMetrics {
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric.name)
+		if metric.unit != "" {
+			fmt.Fprintf(w, "# UNIT %s %s\n", metric.name, metric.unit)
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+
+		for _, run := range runs {
+			fmt.Fprintf(w, "%s{run=\"%d\",run_id=\"%s\",provider=\"%s\",worker=\"%s\",status=\"%s\"} %s",
+				metric.name, run.ID,
+				escapeLabel(run.RunID), escapeLabel(run.Provider), escapeLabel(run.Worker), escapeLabel(run.Status),
+				strconv.FormatFloat(metric.value(run), 'g', -1, 64),
+			)
+			if timestamps {
+				fmt.Fprintf(w, " %s", strconv.FormatFloat(float64(run.FinishedAt.UnixMilli())/1000, 'f', 3, 64))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintln(w, "# EOF")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapa um valor de label OpenMetrics
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+// defaultConsumoLLMDias e o periodo de GET /admin/scraper/llm-uso sem desde
+const defaultConsumoLLMDias = 30
+
+type ScraperRunHandler struct {
+	repo   *repository.ScraperRunRepo
+	precos map[string]model.PrecoLLM
+}
+
+func NewScraperRunHandler(repo *repository.ScraperRunRepo, precos map[string]model.PrecoLLM) *ScraperRunHandler {
+	return &ScraperRunHandler{repo: repo, precos: precos}
+}
+
+// List retorna o historico de execucoes do scraper, mais recentes primeiro, com
+// configuracao, totais, breakdown de match e histograma de erros
+func (h *ScraperRunHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit := defaultScraperRunsLimit
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxScraperRunsLimit)
+	}
+
+	status := q.Get("status")
+	switch status {
+	case "", model.RunStatusRunning, model.RunStatusCompleted, model.RunStatusCancelled, model.RunStatusFailed:
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_status",
+			Message: "status deve ser running, completed, cancelled ou failed",
+		})
+		return
+	}
+
+	runs, err := h.repo.ListRecent(r.Context(), limit, q.Get("provider"), status)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar execucoes do scraper",
+		})
+		return
+	}
+
+	reports := make([]model.ScraperRunReport, len(runs))
+	for i, run := range runs {
+		reports[i] = model.NewScraperRunReport(run)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.ScraperRunsResponse{
+		Runs:  reports,
+		Total: len(reports),
+	})
+}
+
+// Get retorna uma execucao do scraper
+func (h *ScraperRunHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da execucao deve ser um numero",
+		})
+		return
+	}
+
+	run, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar execucao do scraper",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if run == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Execucao nao encontrada",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(model.NewScraperRunReport(*run))
+}
+
+// ConsumoLLM retorna os tokens de LLM das execucoes finalizadas no periodo,
+// por provedor e chave de API, com o custo estimado pelos precos de LLM_PRICES.
+// desde e ate sao datas (YYYY-MM-DD, inclusivas) ou RFC3339; o padrao sao os
+// ultimos 30 dias.
+func (h *ScraperRunHandler) ConsumoLLM(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	ate := time.Now()
+	if param := q.Get("ate"); param != "" {
+		t, err := parseAsOf(param)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_ate",
+				Message: "ate deve ser uma data (YYYY-MM-DD) ou RFC3339",
+			})
+			return
+		}
+		ate = t
+	}
+
+	desde := ate.AddDate(0, 0, -defaultConsumoLLMDias)
+	if param := q.Get("desde"); param != "" {
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			t, err = time.ParseInLocation(time.DateOnly, param, fusoBrasilia)
+		}
+		if err != nil || !t.Before(ate) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_desde",
+				Message: "desde deve ser uma data (YYYY-MM-DD) ou RFC3339 anterior a ate",
+			})
+			return
+		}
+		desde = t
+	}
+
+	usage, err := h.repo.TokenUsage(r.Context(), desde, ate)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar consumo de LLM do scraper",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.NovoConsumoLLM(desde, ate, usage, h.precos))
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+	"wega-catalog-api/internal/service"
+)
+
+const (
+	// maxSincronizacaoBytes limita o arquivo de precos e estoque enviado
+	maxSincronizacaoBytes = 50 << 20
+
+	defaultSincronizacoesLimit = 50
+	maxSincronizacoesLimit     = 500
+)
+
+type SincronizacaoHandler struct {
+	service *service.SincronizacaoProdutos
+	repo    *repository.ProdutoSincronizacaoRepo
+}
+
+func NewSincronizacaoHandler(svc *service.SincronizacaoProdutos, repo *repository.ProdutoSincronizacaoRepo) *SincronizacaoHandler {
+	return &SincronizacaoHandler{service: svc, repo: repo}
+}
+
+// Sincronizar aplica um arquivo de precos e estoque enviado no corpo: CSV
+// (codigo_wega,preco,disponivel,estoque) ou JSON com Content-Type
+// application/json. Retorna os totais; o detalhe das alteracoes fica em
+// GET /admin/produtos/sincronizacoes/{id}.
+func (h *SincronizacaoHandler) Sincronizar(w http.ResponseWriter, r *http.Request) {
+	atualizacoes, err := service.LerArquivo(r.Header.Get("Content-Type"), http.MaxBytesReader(w, r.Body, maxSincronizacaoBytes))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_file",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sincronizacao, err := h.service.Importar(r.Context(), model.SincronizacaoUpload, atualizacoes)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao sincronizar precos e estoque",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sincronizacao)
+}
+
+// SincronizarERP busca agora o arquivo de ERP_SYNC_URL, sem esperar o job
+func (h *SincronizacaoHandler) SincronizarERP(w http.ResponseWriter, r *http.Request) {
+	if !h.service.ERPConfigurado() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "erp_not_configured",
+			Message: "ERP_SYNC_URL nao configurada",
+		})
+		return
+	}
+
+	sincronizacao, err := h.service.SincronizarERP(r.Context())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "erp_sync_failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sincronizacao)
+}
+
+// List retorna as sincronizacoes mais recentes, com totais e falhas
+func (h *SincronizacaoHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultSincronizacoesLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxSincronizacoesLimit)
+	}
+
+	sincronizacoes, err := h.repo.Listar(r.Context(), limit)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao listar sincronizacoes",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.SincronizacoesResponse{
+		Sincronizacoes: sincronizacoes,
+		Total:          len(sincronizacoes),
+	})
+}
+
+// Get retorna uma sincronizacao com cada campo alterado (valor anterior e novo)
+func (h *SincronizacaoHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID da sincronizacao deve ser um numero",
+		})
+		return
+	}
+
+	sincronizacao, err := h.repo.BuscarPorID(r.Context(), id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao buscar sincronizacao",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if sincronizacao == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Sincronizacao nao encontrada",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(sincronizacao)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+const (
+	defaultConflitosLimit = 100
+	maxConflitosLimit     = 1000
+)
+
+// SpecConflitoStore lista e descarta os conflitos entre fontes.
+// repository.SpecConflitoRepo e repository.SpecConflitoAuditado a implementam.
+type SpecConflitoStore interface {
+	Listar(ctx context.Context, filtro repository.SpecConflitoFiltro) ([]model.SpecConflito, int, error)
+	Remover(ctx context.Context, id int) (bool, error)
+}
+
+// SpecConflitoHandler expoe as viscosidades divergentes entre fontes para
+// arbitragem manual
+type SpecConflitoHandler struct {
+	repo SpecConflitoStore
+}
+
+func NewSpecConflitoHandler(repo SpecConflitoStore) *SpecConflitoHandler {
+	return &SpecConflitoHandler{repo: repo}
+}
+
+// List lista os conflitos com o que cada fonte diz (filtros opcionais:
+// codigo_aplicacao, tipo_fluido, fonte, limit, offset)
+func (h *SpecConflitoHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filtro := repository.SpecConflitoFiltro{
+		Fonte: strings.TrimSpace(q.Get("fonte")),
+		Limit: defaultConflitosLimit,
+	}
+	if tipo := q.Get("tipo_fluido"); tipo != "" {
+		filtro.TipoFluido = model.TipoFluidoCode(tipo)
+	}
+	if param := q.Get("codigo_aplicacao"); param != "" {
+		codigo, err := strconv.Atoi(param)
+		if err != nil || codigo <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(model.ErrorResponse{
+				Error:   "invalid_param",
+				Message: "codigo_aplicacao deve ser um numero",
+			})
+			return
+		}
+		filtro.CodigoAplicacao = codigo
+	}
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+		filtro.Limit = min(limit, maxConflitosLimit)
+	}
+	if offset, err := strconv.Atoi(q.Get("offset")); err == nil && offset > 0 {
+		filtro.Offset = offset
+	}
+
+	conflitos, total, err := h.repo.Listar(r.Context(), filtro)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao listar conflitos entre fontes",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.SpecConflitosResponse{
+		Conflitos: conflitos,
+		Total:     total,
+		Limit:     filtro.Limit,
+		Offset:    filtro.Offset,
+	})
+}
+
+// Delete descarta um conflito depois da arbitragem
+func (h *SpecConflitoHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "ID do conflito deve ser um numero",
+		})
+		return
+	}
+
+	found, err := h.repo.Remover(r.Context(), id)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "database_error",
+			Message: "Erro ao descartar conflito",
+		})
+		return
+	}
+	if !found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error:   "not_found",
+			Message: "Conflito nao encontrado",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
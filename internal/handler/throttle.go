@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/config"
+)
+
+// Throttle limita as requisicoes por IP do cliente e no total, antes da cota
+// por chave, para robos nao esgotarem o pool do Postgres. Cada limite e um
+// token bucket reposto continuamente que aceita ate 10s de requisicoes
+// seguidas; acima dele a resposta e 429 com Retry-After. Uma mesma instancia
+// deve ser usada em todos os grupos de rotas para os contadores serem os mesmos.
+// Uma requisicao recusada pelo limite global devolve o token do seu IP.
+func Throttle(cfg config.ThrottleConfig) func(http.Handler) http.Handler {
+	porIP := newKeyedLimiter(cfg.PorIP)
+	global := newKeyedLimiter(cfg.Global)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			ip := clientIP(r)
+			if ok, espera := porIP.allow(ip, now); !ok {
+				w.Header().Set("Retry-After", retryAfterSeconds(espera))
+				writeRateLimitError(w, http.StatusTooManyRequests, "rate_limited_ip", "Limite de requisicoes por minuto deste IP excedido")
+				return
+			}
+			if ok, espera := global.allow("", now); !ok {
+				porIP.refund(ip)
+				w.Header().Set("Retry-After", retryAfterSeconds(espera))
+				writeRateLimitError(w, http.StatusTooManyRequests, "rate_limited_global", "API sobrecarregada, tente novamente em instantes")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LimitePorIP limita uma rota cara (como a consulta por placa, paga por
+// chamada) a perMinute requisicoes por minuto de cada IP, alem do Throttle
+// geral; 0 desativa. O IP e o do RealIP, que so aceita X-Forwarded-For de
+// proxies confiaveis: trocar o header nao gera consultas pagas extras.
+func LimitePorIP(perMinute int) func(http.Handler) http.Handler {
+	porIP := newKeyedLimiter(perMinute)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, espera := porIP.allow(clientIP(r), time.Now()); !ok {
+				w.Header().Set("Retry-After", retryAfterSeconds(espera))
+				writeRateLimitError(w, http.StatusTooManyRequests, "rate_limited_ip", "Limite de consultas por minuto deste IP excedido")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP retorna o IP do cliente; depois do middleware RealIP, RemoteAddr
+// so vem dos headers do proxy quando a conexao e de um proxy confiavel
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// retryAfterSeconds arredonda a espera para cima, em segundos inteiros
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
+}
+
+// keyedLimiter mantem um token bucket por chave, reposto a perMinute/60 por
+// segundo ate perMinute/6 tokens (10s de requisicoes). Buckets parados tempo
+// suficiente para encher de novo sao descartados, limitando a memoria por IP.
+type keyedLimiter struct {
+	rate     float64 // Tokens por segundo; 0 = sem limite
+	capacity float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newKeyedLimiter(perMinute int) *keyedLimiter {
+	l := &keyedLimiter{buckets: make(map[string]*tokenBucket)}
+	if perMinute > 0 {
+		l.rate = float64(perMinute) / 60
+		l.capacity = max(float64(perMinute)/6, 1)
+	}
+	return l
+}
+
+// allow consome um token da chave; sem token, retorna quanto falta para o proximo
+func (l *keyedLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	if l.rate == 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cheio := time.Duration(l.capacity / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) > cheio {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > cheio {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*l.rate, l.capacity)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// refund devolve o token consumido por allow quando a requisicao acabou
+// recusada por outro limite
+func (l *keyedLimiter) refund(key string) {
+	if l.rate == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = min(b.tokens+1, l.capacity)
+	}
+}
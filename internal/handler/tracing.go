@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing abre um span por requisicao, continuando o trace de quem chamou
+// (header traceparent). Terminado o roteamento, o span recebe o nome da rota
+// do chi ("GET /api/v1/especificacoes/aplicacao/{id}"), que agrupa as
+// requisicoes da mesma rota. As consultas ao banco e chamadas externas feitas
+// com o contexto da requisicao viram spans filhos.
+func Tracing(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		if pattern := rotaDaRequisicao(r); pattern != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + pattern)
+			span.SetAttributes(attribute.String("http.route", pattern))
+		}
+	})
+
+	return otelhttp.NewHandler(named, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+	)
+}
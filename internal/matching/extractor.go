@@ -1,6 +1,7 @@
 package matching
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 )
@@ -91,3 +92,38 @@ func (f VehicleFeatures) HasPotencia() bool {
 func (f VehicleFeatures) HasAno() bool {
 	return f.Ano > 0
 }
+
+// Displacements outside this range are model numbers the cilindrada regex
+// picks up ("Peugeot 206 1.4"), not engines
+const (
+	minCilindrada = 0.5
+	maxCilindrada = 10.0
+)
+
+// CompareFeatures compares the features a search asked for (want) with the
+// ones extracted from a catalog description (got). A feature only counts when
+// both sides have it: matches are the agreeing ones and conflicts the ones
+// that clearly differ, with the tolerances of the Motul matcher (0.1 L, 5 cv).
+func CompareFeatures(want, got VehicleFeatures) (matches, conflicts int) {
+	compare := func(agree bool) {
+		if agree {
+			matches++
+		} else {
+			conflicts++
+		}
+	}
+
+	if want.HasCilindrada() && got.Cilindrada >= minCilindrada && got.Cilindrada <= maxCilindrada {
+		compare(math.Abs(want.Cilindrada-got.Cilindrada) < 0.1)
+	}
+	if want.HasValvulas() && got.HasValvulas() {
+		compare(want.Valvulas == got.Valvulas)
+	}
+	if want.HasCilindros() && got.HasCilindros() {
+		compare(want.Cilindros == got.Cilindros)
+	}
+	if want.HasPotencia() && got.HasPotencia() {
+		compare(math.Abs(float64(want.Potencia-got.Potencia)) <= 5)
+	}
+	return matches, conflicts
+}
@@ -0,0 +1,64 @@
+package matching
+
+import (
+	"regexp"
+	"strings"
+
+	"wega-catalog-api/internal/model"
+)
+
+// fuelPatterns detect the fuel from catalog text, checked in order on the
+// normalized description: "Tetrafuel" and "Flex" win over a "GNV" kit note,
+// and diesel engine families (TDI, HDi, CRDi...) count as diesel
+var fuelPatterns = []struct {
+	fuel  string
+	regex *regexp.Regexp
+}{
+	{model.CombustivelDiesel, regexp.MustCompile(`\b(turbo ?diesel|diesel|tdi|tdci|hdi|crdi|dci|cdi|jtd|multijet|d-?4d|duratorq)\b`)},
+	{model.CombustivelFlex, regexp.MustCompile(`\b(total ?flex|flexpower|flex ?fuel|flex|bicombustivel|tetrafuel)\b`)},
+	{model.CombustivelGNV, regexp.MustCompile(`\b(gnv|gas natural)\b`)},
+	{model.CombustivelEtanol, regexp.MustCompile(`\b(etanol|alcool)\b`)},
+	{model.CombustivelGasolina, regexp.MustCompile(`\b(gasolina|gas)\b`)},
+}
+
+// DetectFuel returns the fuel named in a catalog description (one of the
+// model.Combustivel* values) or "" when the text does not say
+func DetectFuel(text string) string {
+	normalized := Normalize(text)
+	for _, p := range fuelPatterns {
+		if p.regex.MatchString(normalized) {
+			return p.fuel
+		}
+	}
+	return ""
+}
+
+// ExtractFuel finds a fuel word in free text ("1.6 diesel") and returns the
+// fuel and the text without it, so the rest can still be matched literally.
+// Returns "" and the text unchanged when there is none.
+func ExtractFuel(text string) (fuel, rest string) {
+	normalized := Normalize(text)
+	for _, p := range fuelPatterns {
+		if loc := p.regex.FindStringIndex(normalized); loc != nil {
+			rest = strings.Join(strings.Fields(normalized[:loc[0]]+" "+normalized[loc[1]:]), " ")
+			return p.fuel, rest
+		}
+	}
+	return "", text
+}
+
+// FuelCompatible reports whether a vehicle running on fuel fits a search for
+// wanted. Flex engines run on gasoline and ethanol, and GNV kits are fitted
+// to gasoline and flex engines; diesel only matches diesel.
+func FuelCompatible(wanted, fuel string) bool {
+	if wanted == fuel {
+		return true
+	}
+	switch wanted {
+	case model.CombustivelGasolina, model.CombustivelEtanol:
+		return fuel == model.CombustivelFlex
+	case model.CombustivelGNV:
+		return fuel == model.CombustivelFlex || fuel == model.CombustivelGasolina
+	}
+	return false
+}
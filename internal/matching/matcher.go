@@ -31,10 +31,11 @@ type MatchScore struct {
 
 // MatchResult contains the best match and its score
 type MatchResult struct {
-	VehicleType     *client.VehicleType
-	Score           MatchScore
-	MotulFeatures   VehicleFeatures
-	WegaFeatures    VehicleFeatures
+	VehicleType   *client.VehicleType
+	Score         MatchScore
+	MotulFeatures VehicleFeatures
+	WegaFeatures  VehicleFeatures
+	RunnerUpTotal int // Total score of the second-best type (ties mean the match is ambiguous)
 }
 
 // VehicleMatcher performs fuzzy matching between Wega and Motul vehicles
@@ -63,6 +64,7 @@ func (m *VehicleMatcher) FindBestMatch(
 	wegaFeatures := ExtractFeatures(wegaVehicle.DescricaoCompleta, wegaYear)
 
 	var bestMatch *MatchResult
+	runnerUp := 0
 
 	for i := range motulTypes {
 		motulType := &motulTypes[i]
@@ -75,14 +77,20 @@ func (m *VehicleMatcher) FindBestMatch(
 
 		// Update best match
 		if bestMatch == nil || score.Total > bestMatch.Score.Total {
+			if bestMatch != nil {
+				runnerUp = bestMatch.Score.Total
+			}
 			bestMatch = &MatchResult{
 				VehicleType:   motulType,
 				Score:         score,
 				MotulFeatures: motulFeatures,
 				WegaFeatures:  wegaFeatures,
 			}
+		} else if score.Total > runnerUp {
+			runnerUp = score.Total
 		}
 	}
+	bestMatch.RunnerUpTotal = runnerUp
 
 	// Check if best match meets minimum confidence
 	if bestMatch.Score.Confidence < m.minConfidence {
@@ -96,6 +104,12 @@ func (m *VehicleMatcher) FindBestMatch(
 	return bestMatch, nil
 }
 
+// Score scores a Wega vehicle against one Motul type, the breakdown
+// FindBestMatch ranks the types by
+func (m *VehicleMatcher) Score(wega, motul VehicleFeatures) MatchScore {
+	return m.calculateScore(wega, motul)
+}
+
 // calculateScore calculates matching score between two vehicles
 func (m *VehicleMatcher) calculateScore(wega, motul VehicleFeatures) MatchScore {
 	score := MatchScore{}
@@ -3,11 +3,8 @@ package matching
 import (
 	"regexp"
 	"strings"
-	"unicode"
 
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
+	"wega-catalog-api/internal/normalize"
 )
 
 var (
@@ -18,22 +15,9 @@ var (
 	generationSuffixes = []string{"G1", "G2", "G3", "G4", "G5", "G6", "G7", "G8"}
 )
 
-// Normalize normalizes a string for comparison
+// Normalize normalizes a string for comparison (see normalize.Text)
 func Normalize(s string) string {
-	// Convert to lowercase
-	s = strings.ToLower(s)
-
-	// Remove accents
-	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-	s, _, _ = transform.String(t, s)
-
-	// Remove extra whitespace
-	s = strings.Join(strings.Fields(s), " ")
-
-	// Trim
-	s = strings.TrimSpace(s)
-
-	return s
+	return normalize.Text.Apply(s)
 }
 
 // RemoveGenerationSuffix removes generation markers like "G7"
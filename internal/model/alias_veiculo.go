@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Tipos de alias
+const (
+	AliasMarca  = "marca"  // Marca Wega -> marca Motul
+	AliasModelo = "modelo" // Modelo Wega -> modelo Motul, dentro de uma marca Motul
+)
+
+// Fontes de alias
+const (
+	AliasAprendido = "aprendido" // Resolvido pelo matcher (exato, similaridade, LLM...)
+	AliasCurado    = "curado"    // Corrigido por um especialista; nunca sobrescrito pelo scraper
+)
+
+// aliasCSVHeader e o cabecalho do CSV de aliases
+var aliasCSVHeader = []string{"tipo", "marca", "origem", "destino", "fonte", "categoria"}
+
+// AliasVeiculo representa o mapeamento de um nome Wega para o nome no catalogo Motul
+type AliasVeiculo struct {
+	Tipo    string `json:"tipo"`
+	Marca   string `json:"marca,omitempty"` // Marca Motul; somente para aliases de modelo
+	Origem  string `json:"origem"`          // Nome no catalogo Wega
+	Destino string `json:"destino"`         // Nome no catalogo Motul; vazio na importacao remove o alias
+	Fonte   string `json:"fonte"`
+	// Categoria Motul (CAR, MOTORCYCLE, TRUCK, AGRI) do matcher que aprendeu
+	// o alias; vazia vale para todas
+	Categoria    string    `json:"categoria,omitempty"`
+	AtualizadoEm time.Time `json:"atualizado_em"`
+}
+
+// AliasImportResponse representa o resultado de uma importacao de aliases
+type AliasImportResponse struct {
+	Importados int `json:"importados"`
+	Removidos  int `json:"removidos"`
+}
+
+// Validar verifica tipo, marca e origem de um alias
+func (a AliasVeiculo) Validar() error {
+	switch a.Tipo {
+	case AliasMarca:
+		if a.Marca != "" {
+			return errors.New("alias de marca nao leva marca")
+		}
+	case AliasModelo:
+		if a.Marca == "" {
+			return errors.New("alias de modelo precisa da marca Motul")
+		}
+	default:
+		return fmt.Errorf("tipo %q invalido (use %s ou %s)", a.Tipo, AliasMarca, AliasModelo)
+	}
+	if a.Origem == "" {
+		return errors.New("origem vazia")
+	}
+	return nil
+}
+
+// EscreverAliasesCSV escreve os aliases em CSV (tipo,marca,origem,destino,fonte,categoria)
+func EscreverAliasesCSV(w io.Writer, aliases []AliasVeiculo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(aliasCSVHeader); err != nil {
+		return err
+	}
+	for _, a := range aliases {
+		if err := cw.Write([]string{a.Tipo, a.Marca, a.Origem, a.Destino, a.Fonte, a.Categoria}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// LerAliasesCSV le aliases no formato de EscreverAliasesCSV. As colunas fonte
+// e categoria sao opcionais; aliases importados sao sempre gravados como
+// curados, e sem categoria valem para todas.
+func LerAliasesCSV(r io.Reader) ([]AliasVeiculo, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV vazio")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, col := range aliasCSVHeader[:4] {
+		if i >= len(header) || !strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")), col) {
+			return nil, fmt.Errorf("cabecalho deve comecar com %s", strings.Join(aliasCSVHeader[:4], ","))
+		}
+	}
+
+	var aliases []AliasVeiculo
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 4 {
+			return nil, fmt.Errorf("linha %d: esperadas ao menos 4 colunas", line)
+		}
+
+		a := AliasVeiculo{
+			Tipo:    strings.ToLower(strings.TrimSpace(record[0])),
+			Marca:   strings.TrimSpace(record[1]),
+			Origem:  strings.TrimSpace(record[2]),
+			Destino: strings.TrimSpace(record[3]),
+			Fonte:   AliasCurado,
+		}
+		if len(record) > 5 {
+			a.Categoria = strings.ToUpper(strings.TrimSpace(record[5]))
+		}
+		if err := a.Validar(); err != nil {
+			return nil, fmt.Errorf("linha %d: %w", line, err)
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, nil
+}
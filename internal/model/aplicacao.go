@@ -11,6 +11,7 @@ type Aplicacao struct {
 	Ano                string `json:"ano,omitempty"`
 	Fabricante         string `json:"fabricante,omitempty"` // For scraper - brand name
 	Modelo             string `json:"modelo,omitempty"`     // For scraper - model name
+	AnoDesconhecido    bool   `json:"ano_desconhecido"`     // Sem ano reconhecivel no periodo nem na descricao
 }
 
 type OpcoesVeiculo struct {
@@ -19,6 +20,8 @@ type OpcoesVeiculo struct {
 }
 
 type OpcaoVeiculo struct {
-	ID        int    `json:"id"`
-	Descricao string `json:"descricao"`
+	ID              int     `json:"id"`
+	Descricao       string  `json:"descricao"`
+	AnoDesconhecido bool    `json:"ano_desconhecido"`
+	Score           float64 `json:"score"` // 0 a 1: proximidade do ano e motor em comum com a busca
 }
@@ -0,0 +1,28 @@
+package model
+
+// AplicacaoVetor e o embedding do texto de uma aplicacao (marca e descricao),
+// gravado em APLICACAO_EMBEDDING para a busca semantica
+type AplicacaoVetor struct {
+	CodigoAplicacao int
+	Texto           string
+	Embedding       []float32
+}
+
+// FiltroSemelhantes restringe a busca por similaridade: marca e ano como no
+// ILIKE, e so as aplicacoes com similaridade de cosseno acima de
+// MinSimilaridade e a ate Margem da mais parecida
+type FiltroSemelhantes struct {
+	Marca           string
+	Ano             string
+	MinSimilaridade float64
+	Margem          float64
+	Limite          int
+}
+
+// VizinhoMotul e uma aplicacao parecida a que o scraper ja associou um tipo
+// de veiculo Motul
+type VizinhoMotul struct {
+	CodigoAplicacao    int
+	MotulVehicleTypeID string
+	Similaridade       float64
+}
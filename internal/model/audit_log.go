@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Acoes do AUDIT_LOG
+const (
+	AuditInsert = "insert"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AtorScraper e o autor das alteracoes feitas pelo cmd/motul-scraper
+const AtorScraper = "motul-scraper"
+
+// AuditLog e uma alteracao de dados: quem, quando, o que e em qual linha
+type AuditLog struct {
+	ID         int64           `json:"id"`
+	Ator       string          `json:"ator"`
+	Acao       string          `json:"acao"`
+	Entidade   string          `json:"entidade"`
+	EntidadeID string          `json:"entidade_id,omitempty"`
+	Dados      json.RawMessage `json:"dados,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CriadoEm   time.Time       `json:"criado_em"`
+}
+
+// AuditLogFiltro restringe a consulta do AUDIT_LOG; campos vazios nao filtram
+type AuditLogFiltro struct {
+	Ator       string
+	Entidade   string
+	EntidadeID string
+	Desde      *time.Time
+	Ate        *time.Time
+	Limit      int
+}
+
+// AuditLogResponse lista as alteracoes mais recentes primeiro
+type AuditLogResponse struct {
+	Registros []AuditLog `json:"registros"`
+	Total     int        `json:"total"`
+}
@@ -0,0 +1,23 @@
+package model
+
+// Tipos de sugestao do autocomplete
+const (
+	SugestaoFabricante = "fabricante"
+	SugestaoModelo     = "modelo"
+	SugestaoProduto    = "produto"
+)
+
+// Sugestao e um item do autocomplete: uma marca, uma aplicacao (modelo) ou um
+// codigo de produto Wega
+type Sugestao struct {
+	Tipo    string  `json:"tipo"`
+	Texto   string  `json:"texto"`
+	Codigo  int     `json:"codigo"`            // CodigoFabricante, CodigoAplicacao ou CodigoProduto
+	Detalhe string  `json:"detalhe,omitempty"` // Marca do modelo ou tipo do filtro
+	Score   float64 `json:"score"`
+}
+
+type AutocompleteResponse struct {
+	Query     string     `json:"q"`
+	Sugestoes []Sugestao `json:"sugestoes"`
+}
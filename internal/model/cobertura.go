@@ -0,0 +1,49 @@
+package model
+
+// Agrupamentos do relatorio de cobertura
+const (
+	CoberturaPorFabricante = "fabricante"
+	CoberturaPorModelo     = "modelo"
+)
+
+// CoberturaGrupo conta as aplicacoes de um fabricante (ou modelo) por situacao.
+// Cada aplicacao entra em uma unica situacao, na ordem: com especificacao,
+// falha pendente, tentada sem resultado, nunca tentada.
+type CoberturaGrupo struct {
+	Fabricante       string  `json:"fabricante,omitempty"`
+	Modelo           string  `json:"modelo,omitempty"`
+	Total            int     `json:"total"`
+	ComEspecificacao int     `json:"com_especificacao"`
+	FalhaPendente    int     `json:"falha_pendente"` // Sem especificacao, com falha nao resolvida em SCRAPER_FALHAS
+	SemResultado     int     `json:"sem_resultado"`  // Tentadas pelo scraper sem match ou sem especificacao no provedor
+	NuncaTentadas    int     `json:"nunca_tentadas"`
+	Cobertura        float64 `json:"cobertura"` // ComEspecificacao / Total
+}
+
+// Somar acumula as contagens de outro grupo
+func (g *CoberturaGrupo) Somar(outro CoberturaGrupo) {
+	g.Total += outro.Total
+	g.ComEspecificacao += outro.ComEspecificacao
+	g.FalhaPendente += outro.FalhaPendente
+	g.SemResultado += outro.SemResultado
+	g.NuncaTentadas += outro.NuncaTentadas
+	g.CalcularCobertura()
+}
+
+// CalcularCobertura atualiza a fracao de aplicacoes com especificacao
+func (g *CoberturaGrupo) CalcularCobertura() {
+	g.Cobertura = 0
+	if g.Total > 0 {
+		g.Cobertura = float64(g.ComEspecificacao) / float64(g.Total)
+	}
+}
+
+// CoberturaResponse representa o relatorio de cobertura de especificacoes
+type CoberturaResponse struct {
+	Agrupamento string           `json:"agrupamento"`
+	TipoFluido  string           `json:"tipo_fluido,omitempty"` // Vazio = qualquer especificacao
+	Resumo      CoberturaGrupo   `json:"resumo"`                // Todas as aplicacoes do filtro
+	Grupos      []CoberturaGrupo `json:"grupos"`                // Mais aplicacoes sem especificacao primeiro
+	TotalGrupos int              `json:"total_grupos"`
+	Limit       int              `json:"limit"`
+}
@@ -0,0 +1,11 @@
+package model
+
+// Combustiveis reconhecidos na busca de filtros (BuscaFiltrosRequest.Combustivel)
+// e na descricao das aplicacoes
+const (
+	CombustivelFlex     = "flex"
+	CombustivelGasolina = "gasolina"
+	CombustivelEtanol   = "etanol"
+	CombustivelDiesel   = "diesel"
+	CombustivelGNV      = "gnv"
+)
@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+// Metricas de completude avaliadas contra o SLA
+const (
+	CompletudeFiltro        = "filtro"
+	CompletudeEspecificacao = "especificacao"
+	CompletudeReferencia    = "referencia"
+)
+
+// CompletudeSLA define a fracao minima (0-1) de aplicacoes de cada fabricante
+// com filtro, especificacao de oleo e referencia cruzada. 0 = sem meta.
+type CompletudeSLA struct {
+	Filtro        float64 `json:"filtro"`
+	Especificacao float64 `json:"especificacao"`
+	Referencia    float64 `json:"referencia"`
+}
+
+// CompletudeFabricante representa a completude do catalogo de um fabricante
+// num relatorio
+type CompletudeFabricante struct {
+	CodigoFabricante int    `json:"codigo_fabricante,omitempty"`
+	Fabricante       string `json:"fabricante"`
+	Aplicacoes       int    `json:"aplicacoes"`
+	ComFiltro        int    `json:"com_filtro"`        // Ao menos um produto em PRODUTO_APLICACAO
+	ComEspecificacao int    `json:"com_especificacao"` // Especificacao de oleo do motor
+	ComReferencia    int    `json:"com_referencia"`    // Algum filtro com referencia cruzada
+
+	PctFiltro        float64 `json:"pct_filtro"`
+	PctEspecificacao float64 `json:"pct_especificacao"`
+	PctReferencia    float64 `json:"pct_referencia"`
+
+	Tendencia *CompletudeTendencia `json:"tendencia,omitempty"` // Ausente sem relatorio anterior
+	ForaSLA   []string             `json:"fora_sla,omitempty"`  // Metricas abaixo do SLA
+}
+
+// CompletudeTendencia e a variacao, em pontos percentuais (0-1), desde o relatorio anterior
+type CompletudeTendencia struct {
+	Filtro        float64 `json:"filtro"`
+	Especificacao float64 `json:"especificacao"`
+	Referencia    float64 `json:"referencia"`
+}
+
+// CalcularPercentuais preenche as fracoes a partir das contagens
+func (c *CompletudeFabricante) CalcularPercentuais() {
+	c.PctFiltro, c.PctEspecificacao, c.PctReferencia = 0, 0, 0
+	if c.Aplicacoes > 0 {
+		total := float64(c.Aplicacoes)
+		c.PctFiltro = float64(c.ComFiltro) / total
+		c.PctEspecificacao = float64(c.ComEspecificacao) / total
+		c.PctReferencia = float64(c.ComReferencia) / total
+	}
+}
+
+// CompararCom preenche a tendencia em relacao ao mesmo fabricante no relatorio anterior
+func (c *CompletudeFabricante) CompararCom(anterior CompletudeFabricante) {
+	c.Tendencia = &CompletudeTendencia{
+		Filtro:        c.PctFiltro - anterior.PctFiltro,
+		Especificacao: c.PctEspecificacao - anterior.PctEspecificacao,
+		Referencia:    c.PctReferencia - anterior.PctReferencia,
+	}
+}
+
+// AvaliarSLA preenche as metricas abaixo da meta
+func (c *CompletudeFabricante) AvaliarSLA(sla CompletudeSLA) {
+	c.ForaSLA = nil
+	if sla.Filtro > 0 && c.PctFiltro < sla.Filtro {
+		c.ForaSLA = append(c.ForaSLA, CompletudeFiltro)
+	}
+	if sla.Especificacao > 0 && c.PctEspecificacao < sla.Especificacao {
+		c.ForaSLA = append(c.ForaSLA, CompletudeEspecificacao)
+	}
+	if sla.Referencia > 0 && c.PctReferencia < sla.Referencia {
+		c.ForaSLA = append(c.ForaSLA, CompletudeReferencia)
+	}
+}
+
+// CompletudeRelatorio representa um relatorio de completude do catalogo
+type CompletudeRelatorio struct {
+	GeradoEm     time.Time              `json:"gerado_em"`
+	Anterior     *time.Time             `json:"anterior,omitempty"` // Relatorio usado na tendencia
+	SLA          CompletudeSLA          `json:"sla"`
+	Geral        CompletudeFabricante   `json:"geral"`
+	Fabricantes  []CompletudeFabricante `json:"fabricantes"`
+	TotalForaSLA int                    `json:"total_fora_sla"` // Fabricantes com alguma metrica abaixo do SLA
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Usage condition codes stored in ESPECIFICACAO_TECNICA."Condicao". Normal (or
+// unspecified) use is the default spec, stored with an empty condition; other
+// conditions, such as severe use, get a row of their own per fluid type.
+const (
+	CondicaoPadrao = ""
+	CondicaoSevera = "SEVERE"
+)
+
+// CondicaoUsoCode maps the usage given by a provider ("Normal", "Severe
+// conditions", "Uso severo"...) to a condition code; unknown usages are
+// upper-cased with underscores
+func CondicaoUsoCode(usage string) string {
+	s := strings.ToLower(strings.TrimSpace(usage))
+	switch {
+	case s == "" || strings.Contains(s, "normal") || strings.Contains(s, "standard"):
+		return CondicaoPadrao
+	case strings.Contains(s, "sever") || strings.Contains(s, "heavy") ||
+		strings.Contains(s, "extrem") || strings.Contains(s, "pesad"):
+		return CondicaoSevera
+	}
+	return strings.Join(strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// PrecoLLM e o preco de um provedor de LLM, em USD por milhao de tokens
+type PrecoLLM struct {
+	Prompt   float64 `json:"prompt"`
+	Resposta float64 `json:"resposta"`
+}
+
+// Custo retorna o custo estimado do consumo, em USD
+func (p PrecoLLM) Custo(u LLMTokenUsage) float64 {
+	return (float64(u.PromptTokens)*p.Prompt + float64(u.CompletionTokens)*p.Resposta) / 1e6
+}
+
+// ConsumoLLM e o consumo de um provedor (ou de uma chave dele) com o custo estimado
+type ConsumoLLM struct {
+	LLMTokenUsage
+	TotalTokens int64 `json:"total_tokens"`
+	// CustoEstimadoUSD e nil quando nao ha preco configurado para o provedor
+	CustoEstimadoUSD *float64 `json:"custo_estimado_usd,omitempty"`
+}
+
+// ConsumoLLMResponse representa o consumo de tokens das execucoes finalizadas no periodo
+type ConsumoLLMResponse struct {
+	Desde            time.Time    `json:"desde"`
+	Ate              time.Time    `json:"ate"`
+	Provedores       []ConsumoLLM `json:"provedores"` // Total por provedor
+	Chaves           []ConsumoLLM `json:"chaves"`     // Por provedor e chave de API
+	TotalTokens      int64        `json:"total_tokens"`
+	CustoEstimadoUSD float64      `json:"custo_estimado_usd"` // Soma dos provedores com preco configurado
+}
+
+// NovoConsumoLLM agrupa o consumo por chave em totais por provedor e estima
+// o custo com os precos informados (por nome do provedor)
+func NovoConsumoLLM(desde, ate time.Time, usage []LLMTokenUsage, precos map[string]PrecoLLM) ConsumoLLMResponse {
+	resp := ConsumoLLMResponse{
+		Desde:      desde,
+		Ate:        ate,
+		Provedores: []ConsumoLLM{},
+		Chaves:     make([]ConsumoLLM, 0, len(usage)),
+	}
+
+	porProvedor := make(map[string]*LLMTokenUsage)
+	for _, u := range usage {
+		resp.Chaves = append(resp.Chaves, novoConsumo(u, precos))
+
+		total := porProvedor[u.Provider]
+		if total == nil {
+			total = &LLMTokenUsage{Provider: u.Provider}
+			porProvedor[u.Provider] = total
+		}
+		total.Requests += u.Requests
+		total.PromptTokens += u.PromptTokens
+		total.CompletionTokens += u.CompletionTokens
+	}
+
+	for _, total := range porProvedor {
+		consumo := novoConsumo(*total, precos)
+		resp.Provedores = append(resp.Provedores, consumo)
+		resp.TotalTokens += consumo.TotalTokens
+		if consumo.CustoEstimadoUSD != nil {
+			resp.CustoEstimadoUSD += *consumo.CustoEstimadoUSD
+		}
+	}
+	sort.Slice(resp.Provedores, func(i, j int) bool {
+		return resp.Provedores[i].Provider < resp.Provedores[j].Provider
+	})
+
+	return resp
+}
+
+func novoConsumo(u LLMTokenUsage, precos map[string]PrecoLLM) ConsumoLLM {
+	consumo := ConsumoLLM{LLMTokenUsage: u, TotalTokens: u.TotalTokens()}
+	if preco, ok := precos[u.Provider]; ok {
+		custo := preco.Custo(u)
+		consumo.CustoEstimadoUSD = &custo
+	}
+	return consumo
+}
@@ -3,17 +3,157 @@ package model
 import "time"
 
 type EspecificacaoTecnica struct {
-	ID                  int       `json:"id"`
-	CodigoAplicacao     int       `json:"codigo_aplicacao"`
-	TipoFluido          string    `json:"tipo_fluido"`
-	Viscosidade         *string   `json:"viscosidade,omitempty"`
-	Capacidade          *string   `json:"capacidade,omitempty"`
-	Norma               *string   `json:"norma,omitempty"`
-	Recomendacao        *string   `json:"recomendacao,omitempty"`
-	Observacao          *string   `json:"observacao,omitempty"`
-	Fonte               string    `json:"fonte"`
-	MotulVehicleTypeID  *string   `json:"motul_vehicle_type_id,omitempty"`
-	MatchConfidence     *float64  `json:"match_confidence,omitempty"`
-	CriadoEm            time.Time `json:"criado_em"`
-	AtualizadoEm        time.Time `json:"atualizado_em"`
+	ID                 int       `json:"id"`
+	CodigoAplicacao    int       `json:"codigo_aplicacao"`
+	TipoFluido         string    `json:"tipo_fluido"`
+	Condicao           string    `json:"condicao,omitempty"` // Vazia = uso normal; ver CondicaoUsoCode
+	Viscosidade        *string   `json:"viscosidade,omitempty"`
+	Capacidade         *string   `json:"capacidade,omitempty"`
+	Norma              *string   `json:"norma,omitempty"`
+	Recomendacao       *string   `json:"recomendacao,omitempty"`
+	Observacao         *string   `json:"observacao,omitempty"`
+	Fonte              string    `json:"fonte"`
+	MotulVehicleTypeID *string   `json:"motul_vehicle_type_id,omitempty"`
+	MatchConfidence    *float64  `json:"match_confidence,omitempty"`
+	CriadoEm           time.Time `json:"criado_em"`
+	AtualizadoEm       time.Time `json:"atualizado_em"`
+	// Valores extraidos de Capacidade e Viscosidade na gravacao, para filtros
+	CapacidadeLitros *float64 `json:"capacidade_litros,omitempty"`
+	ViscosidadesSAE  []string `json:"viscosidades_sae,omitempty"` // Graus SAE canonicos ("5W-30")
+	// Intervalo de troca recomendado pelo provedor, o que vencer primeiro
+	IntervaloTrocaKm    *int `json:"intervalo_troca_km,omitempty"`
+	IntervaloTrocaMeses *int `json:"intervalo_troca_meses,omitempty"`
+	// Execucao do scraper (SCRAPER_RUN."ID") que gravou a especificacao por ultimo
+	ScraperRunID *int `json:"scraper_run_id,omitempty"`
+	// Resultado do ultimo Upsert (GravacaoInserida, GravacaoAtualizada,
+	// GravacaoInalterada ou GravacaoInativa); vazio fora do banco
+	Gravacao string `json:"-"`
+}
+
+// Resultados de EspecificacaoRepository.Upsert: uma especificacao com o mesmo
+// conteudo normalizado nao e regravada, nem uma desativada pelo admin
+const (
+	GravacaoInserida   = "inserida"
+	GravacaoAtualizada = "atualizada"
+	GravacaoInalterada = "inalterada"
+	GravacaoInativa    = "inativa"
+)
+
+// MatchEspecificacao e o match de uma aplicacao com um tipo de veiculo Motul,
+// compartilhado pelas especificacoes gravadas a partir dele
+type MatchEspecificacao struct {
+	CodigoAplicacao    int
+	DescricaoAplicacao string
+	Motor              string
+	MotulVehicleTypeID string
+	MatchConfidence    *float64 // Menor confianca entre as especificacoes
+	Especificacoes     int
+}
+
+// AlteracaoCampo e um campo alterado no log de ESPECIFICACAO_ALTERACAO
+type AlteracaoCampo struct {
+	Anterior *string `json:"anterior"`
+	Novo     *string `json:"novo"`
+}
+
+// EspecificacaoView representa uma especificacao com o nome do tipo de fluido no idioma pedido
+type EspecificacaoView struct {
+	EspecificacaoTecnica
+	TipoFluidoNome string `json:"tipo_fluido_nome"`
+}
+
+// EspecificacoesResponse representa as especificacoes tecnicas de uma aplicacao
+type EspecificacoesResponse struct {
+	CodigoAplicacao int                 `json:"codigo_aplicacao"`
+	Idioma          string              `json:"idioma"`
+	AsOf            *time.Time          `json:"as_of,omitempty"`   // Presente quando a consulta e historica
+	AoVivo          bool                `json:"ao_vivo,omitempty"` // Consultadas no provedor na hora (?ao_vivo=true), nao gravadas
+	Especificacoes  []EspecificacaoView `json:"especificacoes"`
+}
+
+// EspecificacoesBuscaResponse representa o resultado da busca de especificacoes por filtros
+type EspecificacoesBuscaResponse struct {
+	Idioma         string              `json:"idioma"`
+	Especificacoes []EspecificacaoView `json:"especificacoes"`
+	Total          int                 `json:"total"`
+	Limit          int                 `json:"limit"`
+	Offset         int                 `json:"offset"`
+}
+
+// RecomendacaoOleoResponse reune, para uma aplicacao, os filtros de oleo Wega
+// e as especificacoes do oleo do motor
+type RecomendacaoOleoResponse struct {
+	Aplicacao   *Aplicacao          `json:"aplicacao"`
+	Idioma      string              `json:"idioma"`
+	FiltrosOleo []Produto           `json:"filtros_oleo"`
+	Oleo        []EspecificacaoView `json:"oleo"`              // Padrao primeiro, depois por condicao de uso
+	AoVivo      bool                `json:"ao_vivo,omitempty"` // Especificacoes consultadas no provedor na hora
+}
+
+// KitTrocaOleo e o kit de troca de oleo de uma aplicacao para integracoes de
+// e-commerce: filtro de oleo, filtros de ar e cabine quando pedidos, e o oleo
+// com a quantidade a comprar
+type KitTrocaOleo struct {
+	Aplicacao    *Aplicacao `json:"aplicacao"`
+	FiltroOleo   *Produto   `json:"filtro_oleo"`
+	FiltroAr     *Produto   `json:"filtro_ar,omitempty"`
+	FiltroCabine *Produto   `json:"filtro_cabine,omitempty"`
+	Oleo         *OleoKit   `json:"oleo"`
+	Completo     bool       `json:"completo"`           // Todos os itens pedidos foram encontrados
+	Faltando     []string   `json:"faltando,omitempty"` // Itens pedidos sem produto ou especificacao
+}
+
+// OleoKit e o oleo do motor do kit
+type OleoKit struct {
+	Viscosidade         string  `json:"viscosidade,omitempty"`  // Grau SAE canonico ("5W-30")
+	CapacidadeLitros    float64 `json:"capacidade_litros"`      // Capacidade informada pelo provedor
+	Litros              int     `json:"litros"`                 // Frascos de 1 L a comprar (capacidade arredondada para cima)
+	Recomendacao        string  `json:"recomendacao,omitempty"` // Produtos recomendados pelo provedor
+	Norma               string  `json:"norma,omitempty"`
+	IntervaloTrocaKm    *int    `json:"intervalo_troca_km,omitempty"`
+	IntervaloTrocaMeses *int    `json:"intervalo_troca_meses,omitempty"`
+}
+
+// Itens do kit de troca de oleo, usados em KitTrocaOleo.Faltando
+const (
+	ItemKitFiltroOleo   = "filtro_oleo"
+	ItemKitFiltroAr     = "filtro_ar"
+	ItemKitFiltroCabine = "filtro_cabine"
+	ItemKitOleo         = "oleo"
+)
+
+// ComponenteInfo representa um tipo de fluido/componente com nome traduzido e total de especificacoes
+type ComponenteInfo struct {
+	Codigo string `json:"codigo"`
+	Nome   string `json:"nome"`
+	Total  int    `json:"total"`
+}
+
+// ComponentesResponse representa a lista de tipos de fluido conhecidos
+type ComponentesResponse struct {
+	Idioma      string           `json:"idioma"`
+	Componentes []ComponenteInfo `json:"componentes"`
+}
+
+// EspecificacoesLoteResponse e o resultado de desativar ou reativar as
+// especificacoes de uma fonte gravadas entre Desde e Ate
+type EspecificacoesLoteResponse struct {
+	Fonte     string    `json:"fonte"`
+	Desde     time.Time `json:"desde"`
+	Ate       time.Time `json:"ate"`
+	Alteradas int64     `json:"alteradas"`
+}
+
+// Modos de POST /admin/scraper/runs/{id}/rollback
+const (
+	RollbackDesativar = "desativar"
+	RollbackRemover   = "remover"
+)
+
+// RollbackRunResponse e o resultado de desfazer o que uma execucao do scraper
+// gravou
+type RollbackRunResponse struct {
+	ScraperRunID int    `json:"scraper_run_id"`
+	Modo         string `json:"modo"`
+	Alteradas    int64  `json:"alteradas"`
 }
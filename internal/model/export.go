@@ -0,0 +1,27 @@
+package model
+
+// Conjuntos de dados do export do catalogo
+const (
+	ExportProdutos    = "produtos"
+	ExportAplicacoes  = "aplicacoes"
+	ExportReferencias = "referencias"
+)
+
+// ExportDatasets lista os conjuntos de dados exportaveis
+var ExportDatasets = []string{ExportProdutos, ExportAplicacoes, ExportReferencias}
+
+// AplicacaoCompleta e uma aplicacao com seus filtros Wega e especificacoes
+// atuais, uma linha do export NDJSON para indices de busca e data lakes
+type AplicacaoCompleta struct {
+	Aplicacao
+	Filtros        []Produto              `json:"filtros"`
+	Especificacoes []EspecificacaoTecnica `json:"especificacoes"`
+}
+
+// ExportFilter filtra o export; zero = sem filtro
+type ExportFilter struct {
+	// Fabricante e o CodigoFabricante: a montadora para produtos e aplicacoes,
+	// o concorrente para referencias
+	Fabricante int
+	Tipo       int // CodigoSubGrupoProduto (ver /tipos-filtro)
+}
@@ -0,0 +1,24 @@
+package model
+
+// VeiculoPlaca e o veiculo registrado para uma placa, como o provedor informou
+type VeiculoPlaca struct {
+	Marca         string `json:"marca"`
+	Modelo        string `json:"modelo"`
+	Versao        string `json:"versao,omitempty"`
+	AnoFabricacao int    `json:"ano_fabricacao,omitempty"`
+	AnoModelo     int    `json:"ano_modelo,omitempty"`
+	Combustivel   string `json:"combustivel,omitempty"`
+}
+
+// PlacaResponse e o veiculo de uma placa com a busca de filtros montada a
+// partir dele e, quando a busca chegou a uma aplicacao, suas especificacoes
+type PlacaResponse struct {
+	Placa     string                `json:"placa"`
+	Veiculo   VeiculoPlaca          `json:"veiculo"`
+	Busca     BuscaFiltrosRequest   `json:"busca"` // Termos usados na busca de filtros
+	Resultado *BuscaFiltrosResponse `json:"resultado"`
+	// Aplicacao escolhida: a unica encontrada ou o best_guess das opcoes
+	CodigoAplicacao int                 `json:"codigo_aplicacao,omitempty"`
+	Idioma          string              `json:"idioma"`
+	Especificacoes  []EspecificacaoView `json:"especificacoes,omitempty"`
+}
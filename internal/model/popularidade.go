@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// PopularidadeAplicacao represents how often an application is looked up in the API.
+// Score decays over time, so recent demand outweighs old demand.
+type PopularidadeAplicacao struct {
+	CodigoAplicacao           int       `json:"codigo_aplicacao"`
+	Marca                     string    `json:"marca"`
+	DescricaoAplicacao        string    `json:"descricao"`
+	Consultas                 int       `json:"consultas"`
+	ConsultasSemEspecificacao int       `json:"consultas_sem_especificacao"`
+	Score                     float64   `json:"score"`
+	UltimaConsulta            time.Time `json:"ultima_consulta"`
+	TemEspecificacao          bool      `json:"tem_especificacao"`
+}
+
+// PopularidadeResponse represents a page of the popularity report
+type PopularidadeResponse struct {
+	Aplicacoes []PopularidadeAplicacao `json:"aplicacoes"`
+	Total      int                     `json:"total"`
+	Limit      int                     `json:"limit"`
+	Offset     int                     `json:"offset"`
+}
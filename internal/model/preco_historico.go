@@ -0,0 +1,48 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Origens de uma mudanca de preco no historico
+const (
+	PrecoOrigemSincronizacao = "sincronizacao" // Sincronizacao de precos e estoque (upload ou ERP)
+	PrecoOrigemManual        = "manual"        // Qualquer outra alteracao de PrecoProduto
+)
+
+// PrecoHistorico e uma mudanca de preco de um produto
+type PrecoHistorico struct {
+	CodigoProduto   int       `json:"codigo_produto"`
+	PrecoAnterior   *float64  `json:"preco_anterior"`
+	Preco           *float64  `json:"preco"`
+	AlteradoEm      time.Time `json:"alterado_em"`
+	Origem          string    `json:"origem"`
+	SincronizacaoID *int      `json:"sincronizacao_id,omitempty"`
+	// VariacaoPercentual e o reajuste sobre o preco anterior, com duas casas
+	VariacaoPercentual *float64 `json:"variacao_percentual,omitempty"`
+}
+
+// HistoricoPrecosResponse e a serie de precos de um codigo Wega, da mudanca
+// mais antiga para a mais recente
+type HistoricoPrecosResponse struct {
+	CodigoWega string           `json:"codigo_wega"`
+	PrecoAtual *float64         `json:"preco_atual"`
+	Desde      *time.Time       `json:"desde,omitempty"`
+	Ate        *time.Time       `json:"ate,omitempty"`
+	Historico  []PrecoHistorico `json:"historico"`
+	Total      int              `json:"total"`
+}
+
+// NovoPrecoHistorico completa a origem e a variacao de uma mudanca de preco
+func NovoPrecoHistorico(p PrecoHistorico) PrecoHistorico {
+	p.Origem = PrecoOrigemManual
+	if p.SincronizacaoID != nil {
+		p.Origem = PrecoOrigemSincronizacao
+	}
+	if p.PrecoAnterior != nil && p.Preco != nil && *p.PrecoAnterior > 0 {
+		variacao := math.Round((*p.Preco/(*p.PrecoAnterior)-1)*10000) / 100
+		p.VariacaoPercentual = &variacao
+	}
+	return p
+}
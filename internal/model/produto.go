@@ -1,5 +1,12 @@
 package model
 
+import (
+	"slices"
+	"strings"
+
+	"wega-catalog-api/internal/normalize"
+)
+
 type Produto struct {
 	CodigoProduto int      `json:"codigo_produto"`
 	CodigoWega    string   `json:"codigo_wega"`
@@ -7,6 +14,7 @@ type Produto struct {
 	Tipo          string   `json:"tipo"`
 	FotoURL       *string  `json:"foto_url"`
 	Preco         *float64 `json:"preco,omitempty"`
+	Disponivel    *bool    `json:"disponivel,omitempty"` // Da ultima sincronizacao de estoque; nil se nunca informado
 }
 
 type TipoFiltro struct {
@@ -17,3 +25,41 @@ type TipoFiltro struct {
 type TiposFiltroResponse struct {
 	Tipos []TipoFiltro `json:"tipos"`
 }
+
+// Categorias de filtro, derivadas do tipo (SUBGRUPOPRODUTO) do produto
+const (
+	FiltroOleo        = "oleo"
+	FiltroAr          = "ar"
+	FiltroCabine      = "cabine"
+	FiltroCombustivel = "combustivel"
+)
+
+// CategoriaFiltro classifica o tipo de um produto ("Filtro do Óleo", "Filtro
+// do Ar", "Filtro de Cabine"...) em uma das categorias de filtro; retorna ""
+// quando o tipo nao e reconhecido
+func CategoriaFiltro(tipo string) string {
+	t := normalize.Text.Apply(tipo)
+	palavras := strings.Fields(normalize.StripPunctuation(t))
+	switch {
+	case strings.Contains(t, "oleo"):
+		return FiltroOleo
+	case strings.Contains(t, "cabine") || strings.Contains(t, "condicionado") || strings.Contains(t, "climatiz"):
+		return FiltroCabine
+	case strings.Contains(t, "combust"):
+		return FiltroCombustivel
+	case slices.Contains(palavras, "ar"):
+		return FiltroAr
+	}
+	return ""
+}
+
+// FiltrarPorCategoria retorna os produtos da categoria de filtro informada
+func FiltrarPorCategoria(produtos []Produto, categoria string) []Produto {
+	filtrados := []Produto{}
+	for _, p := range produtos {
+		if CategoriaFiltro(p.Tipo) == categoria {
+			filtrados = append(filtrados, p)
+		}
+	}
+	return filtrados
+}
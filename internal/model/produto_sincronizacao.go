@@ -0,0 +1,208 @@
+package model
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Origens de uma sincronizacao de precos e estoque
+const (
+	SincronizacaoUpload = "upload" // Arquivo enviado em /admin/produtos/sincronizar
+	SincronizacaoERP    = "erp"    // Arquivo baixado de ERP_SYNC_URL
+)
+
+// Campos de produto alterados por uma sincronizacao
+const (
+	CampoPreco      = "preco"
+	CampoDisponivel = "disponivel"
+	CampoEstoque    = "estoque"
+)
+
+// AtualizacaoProduto e uma linha do arquivo de sincronizacao. Campos nil nao
+// mudam o produto.
+type AtualizacaoProduto struct {
+	CodigoWega string   `json:"codigo_wega"`
+	Preco      *float64 `json:"preco"`
+	Disponivel *bool    `json:"disponivel"`
+	Estoque    *int     `json:"estoque"`
+}
+
+// SincronizacaoProdutos e uma sincronizacao de precos e estoque e seus totais
+type SincronizacaoProdutos struct {
+	ID             int        `json:"id"`
+	Origem         string     `json:"origem"`
+	IniciadoEm     time.Time  `json:"iniciado_em"`
+	FinalizadoEm   *time.Time `json:"finalizado_em,omitempty"`
+	Linhas         int        `json:"linhas"`
+	Alterados      int        `json:"alterados"`       // Produtos com algum campo alterado
+	NaoEncontrados []string   `json:"nao_encontrados"` // Codigos sem produto no catalogo
+	Erro           string     `json:"erro,omitempty"`
+	// Alteracoes so vem no detalhe da sincronizacao
+	Alteracoes []AlteracaoProduto `json:"alteracoes,omitempty"`
+}
+
+// AlteracaoProduto e um campo de produto alterado por uma sincronizacao
+type AlteracaoProduto struct {
+	CodigoProduto int     `json:"codigo_produto"`
+	CodigoWega    string  `json:"codigo_wega"`
+	Campo         string  `json:"campo"`
+	ValorAnterior *string `json:"valor_anterior"`
+	ValorNovo     *string `json:"valor_novo"`
+}
+
+// SincronizacoesResponse lista as sincronizacoes mais recentes
+type SincronizacoesResponse struct {
+	Sincronizacoes []SincronizacaoProdutos `json:"sincronizacoes"`
+	Total          int                     `json:"total"`
+}
+
+// LerAtualizacoesCSV le o arquivo de sincronizacao: cabecalho com codigo_wega e
+// ao menos uma das colunas preco, disponivel e estoque, separadas por virgula
+// ou ponto e virgula. Precos aceitam "1.234,56"; disponivel aceita sim/nao e
+// true/false. Sem a coluna disponivel, o estoque informado define a
+// disponibilidade. Celulas vazias nao mudam o produto.
+func LerAtualizacoesCSV(r io.Reader) ([]AtualizacaoProduto, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	texto := strings.TrimPrefix(string(data), "\ufeff")
+
+	cr := csv.NewReader(strings.NewReader(texto))
+	primeiraLinha, _, _ := strings.Cut(texto, "\n")
+	if strings.Count(primeiraLinha, ";") > strings.Count(primeiraLinha, ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV vazio")
+	}
+	if err != nil {
+		return nil, err
+	}
+	colunas := make(map[string]int)
+	for i, col := range header {
+		colunas[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	if _, ok := colunas["codigo_wega"]; !ok {
+		return nil, errors.New("cabecalho sem a coluna codigo_wega")
+	}
+	_, temPreco := colunas[CampoPreco]
+	_, temDisponivel := colunas[CampoDisponivel]
+	_, temEstoque := colunas[CampoEstoque]
+	if !temPreco && !temDisponivel && !temEstoque {
+		return nil, errors.New("cabecalho sem nenhuma das colunas preco, disponivel e estoque")
+	}
+
+	celula := func(record []string, coluna string) string {
+		if i, ok := colunas[coluna]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var atualizacoes []AtualizacaoProduto
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		a := AtualizacaoProduto{CodigoWega: celula(record, "codigo_wega")}
+		if a.CodigoWega == "" {
+			return nil, fmt.Errorf("linha %d: codigo_wega vazio", line)
+		}
+		if v := celula(record, CampoPreco); v != "" {
+			preco, err := parsePreco(v)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: %w", line, err)
+			}
+			a.Preco = &preco
+		}
+		if v := celula(record, CampoDisponivel); v != "" {
+			disponivel, err := parseDisponivel(v)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: %w", line, err)
+			}
+			a.Disponivel = &disponivel
+		}
+		if v := celula(record, CampoEstoque); v != "" {
+			estoque, err := strconv.Atoi(v)
+			if err != nil || estoque < 0 {
+				return nil, fmt.Errorf("linha %d: estoque %q invalido", line, v)
+			}
+			a.Estoque = &estoque
+		}
+		atualizacoes = append(atualizacoes, a.comDisponibilidade())
+	}
+
+	return atualizacoes, nil
+}
+
+// LerAtualizacoesJSON le o arquivo de sincronizacao em JSON: uma lista de
+// objetos com os campos de AtualizacaoProduto
+func LerAtualizacoesJSON(r io.Reader) ([]AtualizacaoProduto, error) {
+	var atualizacoes []AtualizacaoProduto
+	if err := json.NewDecoder(r).Decode(&atualizacoes); err != nil {
+		return nil, fmt.Errorf("JSON invalido: %w", err)
+	}
+	for i, a := range atualizacoes {
+		a.CodigoWega = strings.TrimSpace(a.CodigoWega)
+		if a.CodigoWega == "" {
+			return nil, fmt.Errorf("item %d: codigo_wega vazio", i+1)
+		}
+		if a.Preco != nil && *a.Preco < 0 {
+			return nil, fmt.Errorf("item %d: preco negativo", i+1)
+		}
+		if a.Estoque != nil && *a.Estoque < 0 {
+			return nil, fmt.Errorf("item %d: estoque negativo", i+1)
+		}
+		atualizacoes[i] = a.comDisponibilidade()
+	}
+	return atualizacoes, nil
+}
+
+// comDisponibilidade deriva a disponibilidade do estoque quando ela nao veio
+func (a AtualizacaoProduto) comDisponibilidade() AtualizacaoProduto {
+	if a.Disponivel == nil && a.Estoque != nil {
+		disponivel := *a.Estoque > 0
+		a.Disponivel = &disponivel
+	}
+	return a
+}
+
+// parsePreco aceita "1234.56", "1234,56" e "1.234,56"
+func parsePreco(v string) (float64, error) {
+	v = strings.TrimSpace(strings.TrimPrefix(v, "R$"))
+	if strings.Contains(v, ",") {
+		v = strings.ReplaceAll(strings.ReplaceAll(v, ".", ""), ",", ".")
+	}
+	preco, err := strconv.ParseFloat(v, 64)
+	if err != nil || preco < 0 {
+		return 0, fmt.Errorf("preco %q invalido", v)
+	}
+	return preco, nil
+}
+
+// parseDisponivel aceita sim/nao, s/n, true/false e 1/0
+func parseDisponivel(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "sim", "s", "true", "1":
+		return true, nil
+	case "nao", "não", "n", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("disponivel %q invalido (use sim ou nao)", v)
+}
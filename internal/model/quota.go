@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// ApiQuota representa a cota de uso de uma chave de API
+type ApiQuota struct {
+	ID             int       `json:"id"`
+	Nome           string    `json:"nome"`
+	Chave          string    `json:"chave,omitempty"` // Somente na criacao; o banco guarda o hash
+	ChavePrefixo   string    `json:"chave_prefixo"`
+	RequisicoesDia int       `json:"requisicoes_dia"` // 0 = sem limite diario
+	Burst          int       `json:"burst"`           // Requisicoes por segundo; 0 = sem limite
+	Ativo          bool      `json:"ativo"`
+	UsoDia         int       `json:"uso_dia"`
+	CriadoEm       time.Time `json:"criado_em"`
+	AtualizadoEm   time.Time `json:"atualizado_em"`
+}
+
+// QuotaRequest representa o corpo de criacao/atualizacao de uma cota
+type QuotaRequest struct {
+	Nome           string `json:"nome"`
+	RequisicoesDia int    `json:"requisicoes_dia"`
+	Burst          int    `json:"burst"`
+	Ativo          *bool  `json:"ativo,omitempty"` // Padrao: true
+}
+
+// QuotasResponse representa a lista de cotas
+type QuotasResponse struct {
+	Quotas []ApiQuota `json:"quotas"`
+	Total  int        `json:"total"`
+}
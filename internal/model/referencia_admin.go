@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Acoes registradas na auditoria das referencias cruzadas
+const (
+	AcaoCriar   = "criar"
+	AcaoAlterar = "alterar"
+	AcaoRemover = "remover"
+)
+
+// ReferenciaCruzada e uma linha de REFERENCIACRUZADA: o codigo de um
+// concorrente equivalente a um produto Wega
+type ReferenciaCruzada struct {
+	ID                int    `json:"id"`
+	CodigoConcorrente string `json:"codigo_concorrente"` // NumeroProdutoPesq
+	CodigoFabricante  int    `json:"codigo_fabricante"`
+	MarcaConcorrente  string `json:"marca_concorrente"`
+	CodigoProduto     int    `json:"codigo_produto"`
+	CodigoWega        string `json:"codigo_wega"`
+}
+
+// ReferenciaRequest cria ou altera uma referencia cruzada. codigo_fabricante
+// e um concorrente de /fabricantes?tipo=concorrente.
+type ReferenciaRequest struct {
+	CodigoConcorrente string `json:"codigo_concorrente"`
+	CodigoFabricante  int    `json:"codigo_fabricante"`
+	CodigoWega        string `json:"codigo_wega"`
+}
+
+// ReferenciasResponse lista referencias cruzadas
+type ReferenciasResponse struct {
+	Referencias []ReferenciaCruzada `json:"referencias"`
+	Total       int                 `json:"total"`
+}
+
+// AuditoriaReferencia e uma alteracao feita em /admin/referencias, com a
+// referencia antes (nil ao criar) e depois (nil ao remover)
+type AuditoriaReferencia struct {
+	ID           int64              `json:"id"`
+	ReferenciaID int                `json:"referencia_id"`
+	Acao         string             `json:"acao"`
+	Ator         string             `json:"ator"`
+	Anterior     *ReferenciaCruzada `json:"anterior"`
+	Novo         *ReferenciaCruzada `json:"novo"`
+	CriadoEm     time.Time          `json:"criado_em"`
+}
+
+// AuditoriaReferenciasResponse lista as alteracoes mais recentes primeiro
+type AuditoriaReferenciasResponse struct {
+	Auditoria []AuditoriaReferencia `json:"auditoria"`
+	Total     int                   `json:"total"`
+}
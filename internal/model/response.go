@@ -9,29 +9,42 @@ type BuscaFiltrosRequest struct {
 	Ano         string `json:"ano,omitempty"`
 	Motor       string `json:"motor,omitempty"`
 	Combustivel string `json:"combustivel,omitempty"`
+	// Caracteristicas do motor, opcionais: descartam as versoes que as contradizem
+	Cilindrada float64 `json:"cilindrada,omitempty"` // Litros (1.6)
+	Valvulas   int     `json:"valvulas,omitempty"`   // 8, 16...
+	Potencia   int     `json:"potencia,omitempty"`   // cv
+}
+
+// TemCaracteristicasMotor indica se a busca informou cilindrada, valvulas ou potencia
+func (r BuscaFiltrosRequest) TemCaracteristicasMotor() bool {
+	return r.Cilindrada > 0 || r.Valvulas > 0 || r.Potencia > 0
 }
 
 // BuscaFiltrosResponse representa a resposta da busca de filtros
 type BuscaFiltrosResponse struct {
-	Status       string         `json:"status"` // "completo", "incompleto", "multiplos", "nao_encontrado"
-	Mensagem     string         `json:"mensagem,omitempty"`
-	Veiculo      *VeiculoInfo   `json:"veiculo,omitempty"`
-	Filtros      []Produto      `json:"filtros,omitempty"`
-	TotalFiltros int            `json:"total_filtros,omitempty"`
+	Status       string       `json:"status"` // "completo", "incompleto", "multiplos", "nao_encontrado"
+	Mensagem     string       `json:"mensagem,omitempty"`
+	Veiculo      *VeiculoInfo `json:"veiculo,omitempty"`
+	Filtros      []Produto    `json:"filtros,omitempty"`
+	TotalFiltros int          `json:"total_filtros,omitempty"`
 	// Quando incompleto
 	CamposFaltantes   []string       `json:"campos_faltantes,omitempty"`
 	OpcoesDisponiveis *OpcoesVeiculo `json:"opcoes_disponiveis,omitempty"`
-	// Quando multiplos
-	Opcoes []OpcaoVeiculo `json:"opcoes,omitempty"`
+	// Quando multiplos: opcoes da mais para a menos provavel e o ID da que o
+	// frontend pode pre-selecionar (omitido quando as melhores empatam)
+	Opcoes    []OpcaoVeiculo `json:"opcoes,omitempty"`
+	BestGuess int            `json:"best_guess,omitempty"`
 }
 
 // VeiculoInfo representa informacoes do veiculo encontrado
 type VeiculoInfo struct {
+	ID                int    `json:"id,omitempty"` // Aplicacao, quando a busca chegou a uma so
 	Marca             string `json:"marca"`
 	Modelo            string `json:"modelo"`
 	Ano               string `json:"ano,omitempty"`
 	Motor             string `json:"motor,omitempty"`
 	DescricaoCompleta string `json:"descricao_completa"`
+	AnoDesconhecido   bool   `json:"ano_desconhecido"` // Veiculo sem ano no catalogo: o ano pedido nao foi verificado
 }
 
 // FiltrosAplicacaoResponse representa a resposta de filtros por aplicacao
@@ -42,9 +55,9 @@ type FiltrosAplicacaoResponse struct {
 
 // ReferenciaResponse representa a resposta de referencia cruzada
 type ReferenciaResponse struct {
-	CodigoPesquisado  string    `json:"codigo_pesquisado"`
-	MarcaConcorrente  string    `json:"marca_concorrente,omitempty"`
-	EquivalentesWega  []Produto `json:"equivalentes_wega"`
+	CodigoPesquisado string    `json:"codigo_pesquisado"`
+	MarcaConcorrente string    `json:"marca_concorrente,omitempty"`
+	EquivalentesWega []Produto `json:"equivalentes_wega"`
 }
 
 // HealthResponse representa a resposta do health check
@@ -54,6 +67,14 @@ type HealthResponse struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// ReadinessResponse representa a resposta do /readyz; Checks traz "ok" ou o
+// erro de cada verificacao
+type ReadinessResponse struct {
+	Status    string            `json:"status"` // ready ou not_ready
+	Checks    map[string]string `json:"checks"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
 // ErrorResponse representa uma resposta de erro
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Niveis de uma verificacao do system-health, do melhor para o pior
+const (
+	SaudeOK       = "ok"
+	SaudeWarning  = "warning"
+	SaudeCritical = "critical"
+)
+
+// HealthCheck representa uma verificacao de subsistema com seu score (0-100)
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Score   int    `json:"score"`
+	Value   any    `json:"value,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// EstadoChavesLLM e o estado do pool de chaves do LLM, lido do /llm-status
+// do match server ou do monitor do scraper
+type EstadoChavesLLM struct {
+	Configurado        bool       `json:"configured"`
+	TotalChaves        int        `json:"total_keys"`
+	ChavesAtivas       int        `json:"active_keys"`
+	ChavesLimitadas    int        `json:"rate_limited_keys"` // Limite por minuto (429), voltam em 1 minuto
+	ChavesEsgotadasDia int        `json:"daily_exhausted_keys"`
+	EsgotadasAte       *time.Time `json:"all_exhausted_until,omitempty"` // Todas esgotadas ate o reset diario
+}
+
+// SystemHealthResponse representa a saude geral do sistema para o dashboard de operacao.
+// Score e a media dos scores das verificacoes; Status e o pior status entre elas.
+type SystemHealthResponse struct {
+	Status    string        `json:"status"`
+	Score     int           `json:"score"`
+	Timestamp time.Time     `json:"timestamp"`
+	Checks    []HealthCheck `json:"checks"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ScraperCheckpoint represents the saved state of a scraper run
+type ScraperCheckpoint struct {
+	RunID           string          `json:"run_id,omitempty"`
+	LastProcessedID int             `json:"last_processed_id"`
+	StartedAt       time.Time       `json:"started_at"`
+	SavedAt         time.Time       `json:"saved_at"`
+	Stats           CheckpointStats `json:"stats"`
+}
+
+// CheckpointStats holds the run counters at the time of the checkpoint
+type CheckpointStats struct {
+	Success int `json:"success"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
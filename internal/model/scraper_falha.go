@@ -16,6 +16,14 @@ type ScraperFalha struct {
 	CriadoEm         time.Time  `json:"criado_em"`
 }
 
+// FalhasResponse represents a page of scraper failures
+type FalhasResponse struct {
+	Falhas []ScraperFalha `json:"falhas"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
 // Error types for categorization
 const (
 	ErroTipoRateLimit           = "rate_limit"
@@ -24,16 +32,25 @@ const (
 	ErroTipoAPIGroq             = "api_groq"
 	ErroTipoRede                = "rede"
 	ErroTipoParse               = "parse"
+	ErroTipoSchemaDrift         = "schema_drift"
+	ErroTipoBaixaConfianca      = "baixa_confianca" // Match abaixo de -min-save-confidence ou rebaixado pelo -rescore-confidence, aguardando revisao
+	ErroTipoSpecInvalida        = "spec_invalida"   // Especificacao rejeitada pela validacao antes de gravar
 	ErroTipoDesconhecido        = "desconhecido"
 )
 
 // ClassifyError categorizes an error string into a type
 func ClassifyError(errMsg string) string {
 	switch {
+	case contains(errMsg, "low confidence match"):
+		return ErroTipoBaixaConfianca
+	case contains(errMsg, "spec validation"):
+		return ErroTipoSpecInvalida
 	case contains(errMsg, "rate limit", "429", "too many requests"):
 		return ErroTipoRateLimit
 	case contains(errMsg, "model not found", "LLM indicated no match"):
 		return ErroTipoModeloNaoEncontrado
+	case contains(errMsg, "schema drift"):
+		return ErroTipoSchemaDrift
 	case contains(errMsg, "Motul API"):
 		return ErroTipoAPIMotul
 	case contains(errMsg, "Groq API"):
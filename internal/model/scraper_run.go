@@ -0,0 +1,98 @@
+package model
+
+import "time"
+
+// Status de uma execucao do scraper; running ate o fim da execucao
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusCancelled = "cancelled"
+	RunStatusFailed    = "failed"
+)
+
+// ScraperRun represents the summary metrics of one scraper run
+type ScraperRun struct {
+	ID            int       `json:"id"`
+	RunID         string    `json:"run_id"`
+	Provider      string    `json:"provider"`
+	Worker        string    `json:"worker"`
+	Status        string    `json:"status"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Duration      float64   `json:"duration_seconds"`
+	Total         int       `json:"total"`
+	Processed     int       `json:"processed"`
+	Success       int       `json:"success"`
+	Failed        int       `json:"failed"`
+	Skipped       int       `json:"skipped"`
+	ExactMatch    int       `json:"exact_match"`
+	FuzzyMatch    int       `json:"fuzzy_match"`
+	NoMatch       int       `json:"no_match"`
+	Requests      int       `json:"requests"`
+	NetworkErrors int       `json:"network_errors"`
+	RateLimitHits int       `json:"rate_limit_hits"`
+	LLMTokens     int       `json:"llm_tokens"`
+
+	Config           map[string]string `json:"config,omitempty"`             // Configuracao da execucao (workers, rate limit, pipeline...)
+	FailuresByReason map[string]int    `json:"failures_by_reason,omitempty"` // Falhas por etapa
+	ErrorTypes       map[string]int    `json:"error_types,omitempty"`        // Histograma de ClassifyError
+	Error            string            `json:"error,omitempty"`              // Erro que encerrou a execucao (status failed)
+	TokenUsage       []LLMTokenUsage   `json:"token_usage,omitempty"`        // Tokens por provedor e chave de API
+}
+
+// LLMTokenUsage e o consumo de tokens de um provedor de LLM e chave de API
+type LLMTokenUsage struct {
+	Provider         string `json:"provider"`
+	Key              string `json:"key,omitempty"` // Ultimos 4 caracteres da chave; vazio para provedores sem chave
+	Requests         int64  `json:"requests"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+}
+
+// TotalTokens retorna prompt + resposta
+func (u LLMTokenUsage) TotalTokens() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Attempted returns the vehicles that reached the provider search (not skipped)
+func (r ScraperRun) Attempted() int {
+	return r.Success + r.Failed + r.NoMatch
+}
+
+// SuccessRatio returns the share of attempted vehicles that got specs
+func (r ScraperRun) SuccessRatio() float64 {
+	if r.Attempted() == 0 {
+		return 0
+	}
+	return float64(r.Success) / float64(r.Attempted())
+}
+
+// MatchRatio returns the share of attempted vehicles matched in the provider catalog
+func (r ScraperRun) MatchRatio() float64 {
+	if r.Attempted() == 0 {
+		return 0
+	}
+	return float64(r.ExactMatch+r.FuzzyMatch) / float64(r.Attempted())
+}
+
+// ScraperRunReport e uma execucao com as razoes usadas para comparar cobertura
+type ScraperRunReport struct {
+	ScraperRun
+	SuccessRatio float64 `json:"success_ratio"`
+	MatchRatio   float64 `json:"match_ratio"`
+}
+
+// NewScraperRunReport calcula as razoes de uma execucao
+func NewScraperRunReport(run ScraperRun) ScraperRunReport {
+	return ScraperRunReport{
+		ScraperRun:   run,
+		SuccessRatio: run.SuccessRatio(),
+		MatchRatio:   run.MatchRatio(),
+	}
+}
+
+// ScraperRunsResponse representa o historico de execucoes, mais recentes primeiro
+type ScraperRunsResponse struct {
+	Runs  []ScraperRunReport `json:"runs"`
+	Total int                `json:"total"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// SpecConflito e uma divergencia de viscosidade entre duas fontes para a
+// mesma especificacao (aplicacao, tipo de fluido e condicao), para
+// arbitragem manual
+type SpecConflito struct {
+	ID                 int                 `json:"id"`
+	CodigoAplicacao    int                 `json:"codigo_aplicacao"`
+	DescricaoAplicacao string              `json:"descricao_aplicacao"`
+	TipoFluido         string              `json:"tipo_fluido"`
+	Condicao           string              `json:"condicao,omitempty"`
+	Fontes             []SpecConflitoFonte `json:"fontes"`
+	DetectadoEm        time.Time           `json:"detectado_em"`
+	AtualizadoEm       time.Time           `json:"atualizado_em"`
+}
+
+// SpecConflitoFonte e o que uma das fontes diz, com a especificacao que a
+// fonte mantem gravada (cada fonte tem a sua linha)
+type SpecConflitoFonte struct {
+	Fonte           string  `json:"fonte"`
+	Viscosidade     *string `json:"viscosidade,omitempty"`
+	Capacidade      *string `json:"capacidade,omitempty"`
+	EspecificacaoID *int    `json:"especificacao_id,omitempty"` // Vazio se a linha da fonte foi removida
+	Ativa           bool    `json:"ativa"`                      // A especificacao da fonte esta publicada
+}
+
+// SpecConflitosResponse representa a lista de conflitos entre fontes
+type SpecConflitosResponse struct {
+	Conflitos []SpecConflito `json:"conflitos"`
+	Total     int            `json:"total"`
+	Limit     int            `json:"limit"`
+	Offset    int            `json:"offset"`
+}
@@ -0,0 +1,93 @@
+package model
+
+import "strings"
+
+// Canonical fluid type codes stored in ESPECIFICACAO_TECNICA."TipoFluido"
+// They are the Motul component category codes; display names are resolved per
+// language at the API layer.
+const (
+	FluidoOleoMotor       = "ENGINE_OIL"
+	FluidoOleoTransmissao = "TRANSMISSION_OIL"
+	FluidoFreio           = "BRAKE_FLUID"
+	FluidoArrefecimento   = "COOLANT"
+	FluidoDirecao         = "POWER_STEERING"
+	FluidoDiferencial     = "DIFFERENTIAL"
+)
+
+// Supported display languages
+const (
+	IdiomaPT = "pt-BR"
+	IdiomaEN = "en"
+)
+
+// TiposFluido lists every canonical fluid type code in display order
+var TiposFluido = []string{
+	FluidoOleoMotor,
+	FluidoOleoTransmissao,
+	FluidoFreio,
+	FluidoArrefecimento,
+	FluidoDirecao,
+	FluidoDiferencial,
+}
+
+// nomesTipoFluido maps language -> fluid type code -> display name
+var nomesTipoFluido = map[string]map[string]string{
+	IdiomaPT: {
+		FluidoOleoMotor:       "Óleo do Motor",
+		FluidoOleoTransmissao: "Óleo de Transmissão",
+		FluidoFreio:           "Fluido de Freio",
+		FluidoArrefecimento:   "Líquido de Arrefecimento",
+		FluidoDirecao:         "Direção Hidráulica",
+		FluidoDiferencial:     "Diferencial",
+	},
+	IdiomaEN: {
+		FluidoOleoMotor:       "Engine Oil",
+		FluidoOleoTransmissao: "Transmission Oil",
+		FluidoFreio:           "Brake Fluid",
+		FluidoArrefecimento:   "Coolant",
+		FluidoDirecao:         "Power Steering Fluid",
+		FluidoDiferencial:     "Differential Oil",
+	},
+}
+
+// LegacyTipoFluido maps Portuguese names stored before codes were introduced
+// (by the scraper and the old HTML parser) to their code
+var LegacyTipoFluido = map[string]string{
+	"Óleo do Motor":            FluidoOleoMotor,
+	"Óleo de Transmissão":      FluidoOleoTransmissao,
+	"Fluido de Freio":          FluidoFreio,
+	"Líquido de Arrefecimento": FluidoArrefecimento,
+	"Direção Hidráulica":       FluidoDirecao,
+	"Diferencial":              FluidoDiferencial,
+	"Motor":                    FluidoOleoMotor,
+	"Transmissao":              FluidoOleoTransmissao,
+}
+
+// IsTipoFluidoCode reports whether code is a known canonical fluid type
+func IsTipoFluidoCode(code string) bool {
+	_, ok := nomesTipoFluido[IdiomaPT][code]
+	return ok
+}
+
+// TipoFluidoCode returns the canonical code for a stored value, accepting
+// codes and legacy Portuguese names; unknown values are returned unchanged
+func TipoFluidoCode(value string) string {
+	if code, ok := LegacyTipoFluido[value]; ok {
+		return code
+	}
+	return strings.TrimSpace(value)
+}
+
+// NomeTipoFluido returns the display name of a fluid type in idioma
+// (IdiomaPT or IdiomaEN), falling back to the stored value when unknown
+func NomeTipoFluido(value, idioma string) string {
+	code := TipoFluidoCode(value)
+	nomes, ok := nomesTipoFluido[idioma]
+	if !ok {
+		nomes = nomesTipoFluido[IdiomaPT]
+	}
+	if nome, ok := nomes[code]; ok {
+		return nome
+	}
+	return value
+}
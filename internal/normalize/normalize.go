@@ -0,0 +1,130 @@
+// Package normalize turns vehicle, brand and model names into comparable
+// strings. Index keys and lookups must go through the same Pipeline, so every
+// caller uses one of the presets below (or builds its own from the steps)
+// instead of rolling its own lowercase/trim helper.
+package normalize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Step is one normalization transformation
+type Step func(string) string
+
+// Pipeline applies a sequence of steps in order
+type Pipeline struct {
+	steps []Step
+}
+
+// New creates a pipeline running steps in the given order
+func New(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps}
+}
+
+// With returns a copy of the pipeline with extra steps appended
+func (p *Pipeline) With(steps ...Step) *Pipeline {
+	combined := make([]Step, 0, len(p.steps)+len(steps))
+	combined = append(combined, p.steps...)
+	return &Pipeline{steps: append(combined, steps...)}
+}
+
+// Apply runs every step on s
+func (p *Pipeline) Apply(s string) string {
+	for _, step := range p.steps {
+		s = step(s)
+	}
+	return s
+}
+
+// Equal reports whether a and b normalize to the same string
+func (p *Pipeline) Equal(a, b string) bool {
+	return p.Apply(a) == p.Apply(b)
+}
+
+// Presets shared by the API, the scraper and the matcher
+var (
+	// Key builds map keys: "Mercedes-Benz", "mercedes benz" and "MERCEDES BENZ"
+	// all become "mercedesbenz", "Citroën" becomes "citroen"
+	Key = New(StripAccents, Lower, StripPunctuation, RemoveSpaces)
+
+	// Text is for comparisons that keep word boundaries and punctuation
+	// ("Gol 1.0 16V" -> "gol 1.0 16v")
+	Text = New(StripAccents, Lower, CollapseSpaces)
+
+	// Display is for names sent to spec providers ("GOL  CITY" -> "Gol City")
+	Display = New(StripAccents, CollapseSpaces, Title)
+)
+
+// StripAccents removes diacritics ("Citroën" -> "Citroen")
+func StripAccents(s string) string {
+	// Chained transformers are stateful, so build one per call
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return result
+}
+
+// Lower converts to lower case
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// Upper converts to upper case
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+// Title lower-cases and capitalizes each word ("GOL CITY" -> "Gol City")
+func Title(s string) string {
+	return cases.Title(language.Und).String(s)
+}
+
+// CollapseSpaces trims and collapses runs of whitespace into one space
+func CollapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// RemoveSpaces drops all whitespace
+func RemoveSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// StripPunctuation replaces punctuation and symbols with spaces, so
+// "Mercedes-Benz" and "Mercedes Benz" tokenize the same way
+func StripPunctuation(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// Aliases returns a step replacing a string found in aliases ("vw" ->
+// "volkswagen"). Only whole strings are substituted, so "mercedes" ->
+// "mercedes-benz" leaves "mercedes benz" alone. Keys must already be in the
+// form produced by the steps before it in the pipeline.
+func Aliases(aliases map[string]string) Step {
+	return func(s string) string {
+		if alias, ok := aliases[s]; ok {
+			return alias
+		}
+		return s
+	}
+}
+
+// SQL accent folding, for queries that must compare like StripAccents +
+// Lower: translate(LOWER(col), SQLAccented, SQLPlain)
+const (
+	SQLAccented = "áàâãäåéèêëíìîïóòôõöúùûüçñý"
+	SQLPlain    = "aaaaaaeeeeiiiiooooouuuucny"
+)
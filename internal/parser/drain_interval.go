@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DrainInterval is an oil change interval; zero fields are unknown
+type DrainInterval struct {
+	Km     int
+	Months int
+}
+
+// IsZero reports whether neither distance nor time is known
+func (d DrainInterval) IsZero() bool {
+	return d.Km == 0 && d.Months == 0
+}
+
+// Shorter returns the stricter of two intervals, field by field, ignoring
+// unknown fields: "15000 km" and "10000 km / 12 months" give 10000 km / 12 months
+func (d DrainInterval) Shorter(other DrainInterval) DrainInterval {
+	return DrainInterval{Km: minKnown(d.Km, other.Km), Months: minKnown(d.Months, other.Months)}
+}
+
+func minKnown(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+var (
+	// Thousands may be grouped with a space, dot or comma: "15 000 km", "15.000km"
+	distanceRegex = regexp.MustCompile(`(?i)(\d{1,3}(?:[ .,]\d{3})+|\d+)\s*(km|kms|quil[oô]metros?|kilomet(?:er|re)s?|mi|miles?|milhas?)\b`)
+	durationRegex = regexp.MustCompile(`(?i)(\d+)\s*(months?|meses|m[eê]s|years?|anos?)\b`)
+	bareNumber    = regexp.MustCompile(`^\s*(\d{1,3}(?:[ .,]\d{3})+|\d+)\s*$`)
+)
+
+// ParseDrainInterval reads a change interval such as "15 000 km / 12 months",
+// "10.000 km ou 1 ano" or "5000 miles". A bare number is taken as km. Miles
+// are converted to km.
+func ParseDrainInterval(text string) (DrainInterval, bool) {
+	var d DrainInterval
+
+	if m := bareNumber.FindStringSubmatch(text); m != nil {
+		d.Km = groupedInt(m[1])
+		return d, d.Km > 0
+	}
+
+	if m := distanceRegex.FindStringSubmatch(text); m != nil {
+		d.Km = groupedInt(m[1])
+		if unit := strings.ToLower(m[2]); strings.HasPrefix(unit, "mi") {
+			d.Km = int(math.Round(float64(d.Km) * 1.609344))
+		}
+	}
+
+	if m := durationRegex.FindStringSubmatch(text); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if unit := strings.ToLower(m[2]); strings.HasPrefix(unit, "y") || strings.HasPrefix(unit, "ano") {
+			n *= 12
+		}
+		d.Months = n
+	}
+
+	return d, !d.IsZero()
+}
+
+// groupedInt parses an integer with optional thousands separators
+func groupedInt(s string) int {
+	n, _ := strconv.Atoi(strings.NewReplacer(" ", "", ".", "", ",", "").Replace(s))
+	return n
+}
@@ -16,11 +16,13 @@ var (
 // OilSpec represents a parsed oil specification
 type OilSpec struct {
 	TipoFluido   string
+	Condicao     string // Usage condition code, "" = normal use
 	Viscosidade  string
 	Capacidade   string
 	Norma        string
 	Recomendacao string
 	Observacao   string
+	Intervalo    DrainInterval // Oil change interval, zero = unknown
 }
 
 // MotulParser parses Motul API responses
@@ -0,0 +1,253 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"wega-catalog-api/internal/model"
+)
+
+// ViscosityRange bounds the SAE grades accepted for a fluid: the winter grade
+// (before the W, in steps of 5) and the hot grade (after the dash)
+type ViscosityRange struct {
+	MinWinter, MaxWinter int
+	MinHot, MaxHot       int
+}
+
+// contains reports whether a grade falls in the range; hot < 0 is a monograde
+func (r ViscosityRange) contains(winter, hot int) bool {
+	if winter%5 != 0 || winter < r.MinWinter || winter > r.MaxWinter {
+		return false
+	}
+	return hot < 0 || (hot >= r.MinHot && hot <= r.MaxHot)
+}
+
+// SpecValidatorConfig configures a SpecValidator
+type SpecValidatorConfig struct {
+	EngineViscosity ViscosityRange // ENGINE_OIL (0W-20 to 25W-60)
+	// GearViscosity is also accepted for gearboxes and differentials, which
+	// take either gear oil (75W-90) or engine oil (10W-40 in motorcycles)
+	GearViscosity  ViscosityRange
+	MaxCapacity    float64 // Largest plausible capacity in liters
+	MaxDrainKm     int     // Longest plausible change interval in km
+	MaxDrainMonths int     // Longest plausible change interval in months
+}
+
+// DefaultSpecValidatorConfig returns the ranges used by the scraper
+func DefaultSpecValidatorConfig() SpecValidatorConfig {
+	return SpecValidatorConfig{
+		EngineViscosity: ViscosityRange{MinWinter: 0, MaxWinter: 25, MinHot: 20, MaxHot: 60},
+		GearViscosity:   ViscosityRange{MinWinter: 70, MaxWinter: 85, MinHot: 80, MaxHot: 250},
+		MaxCapacity:     100,
+		MaxDrainKm:      100000,
+		MaxDrainMonths:  60,
+	}
+}
+
+// SpecReject is a spec, or one value of it, dropped by the validator
+type SpecReject struct {
+	TipoFluido string
+	Reason     string
+}
+
+func (r SpecReject) String() string {
+	return r.TipoFluido + ": " + r.Reason
+}
+
+// SpecValidator normalizes specs before they are saved: fluid types become
+// canonical codes, capacities "4,3 L" become "4.3 L", viscosities "5w30"
+// become "5W-30". Values outside the configured ranges are dropped, and specs
+// left without data or repeating a fluid type and usage condition already
+// seen are rejected.
+type SpecValidator struct {
+	config SpecValidatorConfig
+}
+
+// NewSpecValidator creates a validator
+func NewSpecValidator(config SpecValidatorConfig) *SpecValidator {
+	return &SpecValidator{config: config}
+}
+
+// Validate returns the normalized specs that passed and everything dropped
+func (v *SpecValidator) Validate(specs []OilSpec) ([]OilSpec, []SpecReject) {
+	var valid []OilSpec
+	var rejects []SpecReject
+	seen := make(map[string]bool)
+
+	for _, spec := range specs {
+		spec.TipoFluido = StandardFluidType(spec.TipoFluido)
+		if spec.TipoFluido == "" {
+			rejects = append(rejects, SpecReject{Reason: "missing fluid type"})
+			continue
+		}
+
+		var dropped []string
+		spec.Viscosidade, dropped = v.normalizeViscosities(spec.TipoFluido, spec.Viscosidade)
+		for _, value := range dropped {
+			rejects = append(rejects, SpecReject{spec.TipoFluido, fmt.Sprintf("invalid viscosity %q", value)})
+		}
+		spec.Capacidade, dropped = v.normalizeCapacities(spec.Capacidade)
+		for _, value := range dropped {
+			rejects = append(rejects, SpecReject{spec.TipoFluido, fmt.Sprintf("invalid capacity %q", value)})
+		}
+		if km := spec.Intervalo.Km; km < 0 || (v.config.MaxDrainKm > 0 && km > v.config.MaxDrainKm) {
+			rejects = append(rejects, SpecReject{spec.TipoFluido, fmt.Sprintf("invalid drain interval %d km", km)})
+			spec.Intervalo.Km = 0
+		}
+		if months := spec.Intervalo.Months; months < 0 || (v.config.MaxDrainMonths > 0 && months > v.config.MaxDrainMonths) {
+			rejects = append(rejects, SpecReject{spec.TipoFluido, fmt.Sprintf("invalid drain interval %d months", months)})
+			spec.Intervalo.Months = 0
+		}
+		spec.Norma = strings.TrimSpace(spec.Norma)
+		spec.Recomendacao = strings.TrimSpace(spec.Recomendacao)
+		spec.Observacao = strings.TrimSpace(spec.Observacao)
+
+		if spec.Viscosidade == "" && spec.Capacidade == "" && spec.Norma == "" && spec.Recomendacao == "" {
+			rejects = append(rejects, SpecReject{spec.TipoFluido, "empty spec"})
+			continue
+		}
+		key := spec.TipoFluido + "/" + spec.Condicao
+		if seen[key] {
+			rejects = append(rejects, SpecReject{spec.TipoFluido, "duplicate fluid type and condition"})
+			continue
+		}
+		seen[key] = true
+		valid = append(valid, spec)
+	}
+
+	return valid, rejects
+}
+
+// normalizeViscosities normalizes a comma-separated list of grades and
+// returns the ones outside the ranges for the fluid type
+func (v *SpecValidator) normalizeViscosities(tipoFluido, text string) (string, []string) {
+	ranges := []ViscosityRange{v.config.EngineViscosity}
+	if tipoFluido != model.FluidoOleoMotor {
+		ranges = append(ranges, v.config.GearViscosity)
+	}
+
+	var grades, dropped []string
+	for _, value := range strings.Split(text, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		grade, winter, hot, ok := parseViscosity(value)
+		inRange := false
+		for _, r := range ranges {
+			inRange = inRange || (ok && r.contains(winter, hot))
+		}
+		if !inRange {
+			dropped = append(dropped, value)
+			continue
+		}
+		grades = appendUnique(grades, grade)
+	}
+	return strings.Join(grades, ", "), dropped
+}
+
+// normalizeCapacities normalizes every capacity in the text to "<liters> L"
+// and returns the implausible ones
+func (v *SpecValidator) normalizeCapacities(text string) (string, []string) {
+	var capacities, dropped []string
+	for _, match := range capacityValueRegex.FindAllStringSubmatch(text, -1) {
+		liters, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+		if err != nil || liters <= 0 || (v.config.MaxCapacity > 0 && liters > v.config.MaxCapacity) {
+			dropped = append(dropped, strings.TrimSpace(match[0]))
+			continue
+		}
+		capacities = appendUnique(capacities, strconv.FormatFloat(liters, 'f', -1, 64)+" L")
+	}
+	if len(capacities) == 0 && len(dropped) == 0 && strings.TrimSpace(text) != "" {
+		dropped = append(dropped, strings.TrimSpace(text))
+	}
+	return strings.Join(capacities, ", "), dropped
+}
+
+var (
+	viscosityGradeRegex = regexp.MustCompile(`(?i)^(\d{1,2})\s*W\s*-?\s*(\d{1,3})?$`)
+	viscosityFindRegex  = regexp.MustCompile(`(?i)\b\d{1,2}\s?W\s?-?\s?\d{0,3}\b`)
+	// A decimal comma is followed by a digit, so "4,3 L, 4.5 L" reads as two capacities
+	capacityValueRegex = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:litros?|liters?|litres?|lts?|l)?\b`)
+)
+
+// parseViscosity reads an SAE grade such as "5W-30", "5w30" or "80W";
+// hot is -1 for monogrades
+func parseViscosity(value string) (grade string, winter, hot int, ok bool) {
+	m := viscosityGradeRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return "", 0, 0, false
+	}
+	winter, _ = strconv.Atoi(m[1])
+	if m[2] == "" {
+		return fmt.Sprintf("%dW", winter), winter, -1, true
+	}
+	hot, _ = strconv.Atoi(m[2])
+	return fmt.Sprintf("%dW-%d", winter, hot), winter, hot, true
+}
+
+// NormalizeViscosity returns the first SAE grade found in a stored
+// Viscosidade value in canonical form ("MOTUL 5w30" -> "5W-30")
+func NormalizeViscosity(text string) (string, bool) {
+	grade, _, _, ok := parseViscosity(viscosityFindRegex.FindString(text))
+	return grade, ok
+}
+
+// ViscosityGrades returns every SAE grade found in a stored Viscosidade value
+// in canonical form, without repeats ("5w30, 10W-40" -> 5W-30, 10W-40)
+func ViscosityGrades(text string) []string {
+	var grades []string
+	for _, match := range viscosityFindRegex.FindAllString(text, -1) {
+		if grade, _, _, ok := parseViscosity(match); ok {
+			grades = appendUnique(grades, grade)
+		}
+	}
+	return grades
+}
+
+// CapacityLiters returns the first capacity in a stored Capacidade value
+// ("4,3 L" -> 4.3)
+func CapacityLiters(text string) (float64, bool) {
+	m := capacityValueRegex.FindStringSubmatch(text)
+	if m == nil {
+		return 0, false
+	}
+	liters, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+	return liters, err == nil && liters > 0
+}
+
+// NumericSpecValues parses a stored Capacidade and Viscosidade into the
+// numeric columns: the first capacity in liters and every SAE grade (nil when
+// nothing parses)
+func NumericSpecValues(capacity, viscosity string) (*float64, []string) {
+	var liters *float64
+	if l, ok := CapacityLiters(capacity); ok {
+		liters = &l
+	}
+	return liters, ViscosityGrades(viscosity)
+}
+
+// StandardFluidType maps a fluid type (code, legacy Portuguese name or
+// spelling variant such as "engine oil") to its canonical code; unknown types
+// are upper-cased with underscores
+func StandardFluidType(value string) string {
+	code := model.TipoFluidoCode(strings.TrimSpace(value))
+	if model.IsTipoFluidoCode(code) {
+		return code
+	}
+	return strings.Join(strings.FieldsFunc(strings.ToUpper(code), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+// appendUnique appends value unless it is already in values
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
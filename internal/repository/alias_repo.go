@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// AliasRepo mantem os mapeamentos de marca e modelo Wega -> Motul em ALIAS_VEICULO
+type AliasRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAliasRepo(pool *pgxpool.Pool) *AliasRepo {
+	return &AliasRepo{pool: pool}
+}
+
+// List retorna os aliases do tipo informado (vazio = todos), ordenados por
+// tipo, marca, origem e categoria
+func (r *AliasRepo) List(ctx context.Context, tipo string) ([]model.AliasVeiculo, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT "Tipo", "Marca", "Origem", "Destino", "Fonte", "Categoria", "AtualizadoEm"
+		FROM "ALIAS_VEICULO"
+		WHERE $1 = '' OR "Tipo" = $1
+		ORDER BY "Tipo", "Marca", "Origem", "Categoria"
+	`, tipo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []model.AliasVeiculo{}
+	for rows.Next() {
+		var a model.AliasVeiculo
+		if err := rows.Scan(&a.Tipo, &a.Marca, &a.Origem, &a.Destino, &a.Fonte, &a.Categoria, &a.AtualizadoEm); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, rows.Err()
+}
+
+// Import grava aliases curados numa unica transacao: os com destino sobrescrevem
+// o alias existente, os sem destino o removem
+func (r *AliasRepo) Import(ctx context.Context, aliases []model.AliasVeiculo) (*model.AliasImportResponse, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin alias import: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result := &model.AliasImportResponse{}
+	for _, a := range aliases {
+		if a.Destino == "" {
+			tag, err := tx.Exec(ctx, `
+				DELETE FROM "ALIAS_VEICULO"
+				WHERE "Tipo" = $1 AND "Marca" = $2 AND "Origem" = $3 AND "Categoria" = $4
+			`, a.Tipo, a.Marca, a.Origem, a.Categoria)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete alias %q: %w", a.Origem, err)
+			}
+			result.Removidos += int(tag.RowsAffected())
+			continue
+		}
+
+		if _, err := upsertAlias(ctx, tx, a, model.AliasCurado, true); err != nil {
+			return nil, err
+		}
+		result.Importados++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit alias import: %w", err)
+	}
+	return result, nil
+}
+
+// SaveLearned grava os aliases resolvidos pelos matchers, cada um na categoria
+// do matcher, sem sobrescrever os curados. Retorna quantos foram inseridos ou
+// alterados.
+func (r *AliasRepo) SaveLearned(ctx context.Context, aliases []model.AliasVeiculo) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin alias save: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved := 0
+	for _, a := range aliases {
+		changed, err := upsertAlias(ctx, tx, a, model.AliasAprendido, false)
+		if err != nil {
+			return 0, err
+		}
+		if changed {
+			saved++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit alias save: %w", err)
+	}
+	return saved, nil
+}
+
+// upsertAlias insere ou atualiza um alias e informa se a linha mudou. Sem
+// overwriteCurated, aliases curados ficam como estao e os demais so sao
+// atualizados quando o destino muda.
+func upsertAlias(ctx context.Context, tx pgx.Tx, a model.AliasVeiculo, fonte string, overwriteCurated bool) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO "ALIAS_VEICULO" ("Tipo", "Marca", "Origem", "Destino", "Fonte", "Categoria")
+		VALUES ($1, $2, $3, $4, $5, $8)
+		ON CONFLICT ("Tipo", "Categoria", "Marca", "Origem") DO UPDATE SET
+			"Destino" = EXCLUDED."Destino",
+			"Fonte" = EXCLUDED."Fonte",
+			"AtualizadoEm" = NOW()
+		WHERE $6 OR ("ALIAS_VEICULO"."Fonte" <> $7 AND "ALIAS_VEICULO"."Destino" <> EXCLUDED."Destino")
+	`, a.Tipo, a.Marca, a.Origem, a.Destino, fonte, overwriteCurated, model.AliasCurado, a.Categoria)
+	if err != nil {
+		return false, fmt.Errorf("failed to save alias %q: %w", a.Origem, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
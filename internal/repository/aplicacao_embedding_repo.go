@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// AplicacaoEmbeddingRepo guarda os embeddings das descricoes das aplicacoes
+// (pgvector, migration 000003) e busca as mais parecidas com um vetor. Sem a
+// extensao a tabela nao existe: Disponivel retorna false e os demais metodos
+// falham.
+type AplicacaoEmbeddingRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAplicacaoEmbeddingRepo(pool *pgxpool.Pool) *AplicacaoEmbeddingRepo {
+	return &AplicacaoEmbeddingRepo{pool: pool}
+}
+
+// textoEmbedding e o texto embutido de cada aplicacao; quando muda (descricao
+// corrigida), a aplicacao volta a ficar pendente
+const textoEmbedding = `f."DescricaoFabricante" || ' ' || a."DescricaoAplicacao"`
+
+// vetorSQL formata o vetor no formato de entrada do pgvector ("[0.1,0.2]")
+func vetorSQL(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// Disponivel indica se a tabela APLICACAO_EMBEDDING existe (pgvector instalado)
+func (r *AplicacaoEmbeddingRepo) Disponivel(ctx context.Context) (bool, error) {
+	var existe bool
+	err := r.pool.QueryRow(ctx, `SELECT to_regclass('"APLICACAO_EMBEDDING"') IS NOT NULL`).Scan(&existe)
+	return existe, err
+}
+
+// Pendentes retorna ate limit aplicacoes sem embedding do modelo ou cujo texto
+// mudou desde que foi calculado
+func (r *AplicacaoEmbeddingRepo) Pendentes(ctx context.Context, modelo string, limit int) ([]model.AplicacaoVetor, error) {
+	query := `
+		SELECT a."CodigoAplicacao", ` + textoEmbedding + `
+		FROM "APLICACAO" a
+		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+		LEFT JOIN "APLICACAO_EMBEDDING" e ON e."CodigoAplicacao" = a."CodigoAplicacao"
+		WHERE f."FlagAplicacao" = 1
+			AND (e."CodigoAplicacao" IS NULL
+				OR e."Modelo" <> $1
+				OR e."Texto" <> ` + textoEmbedding + `)
+		ORDER BY a."CodigoAplicacao"
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, modelo, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pendentes []model.AplicacaoVetor
+	for rows.Next() {
+		var v model.AplicacaoVetor
+		if err := rows.Scan(&v.CodigoAplicacao, &v.Texto); err != nil {
+			return nil, err
+		}
+		pendentes = append(pendentes, v)
+	}
+
+	return pendentes, rows.Err()
+}
+
+// Salvar grava (ou substitui) os embeddings calculados com modelo
+func (r *AplicacaoEmbeddingRepo) Salvar(ctx context.Context, modelo string, vetores []model.AplicacaoVetor) error {
+	query := `
+		INSERT INTO "APLICACAO_EMBEDDING" ("CodigoAplicacao", "Modelo", "Texto", "Embedding", "AtualizadoEm")
+		VALUES ($1, $2, $3, $4::vector, NOW())
+		ON CONFLICT ("CodigoAplicacao") DO UPDATE SET
+			"Modelo" = EXCLUDED."Modelo",
+			"Texto" = EXCLUDED."Texto",
+			"Embedding" = EXCLUDED."Embedding",
+			"AtualizadoEm" = NOW()
+	`
+
+	batch := &pgx.Batch{}
+	for _, v := range vetores {
+		batch.Queue(query, v.CodigoAplicacao, modelo, v.Texto, vetorSQL(v.Embedding))
+	}
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// BuscarSemelhantes retorna as aplicacoes cujo embedding (de modelo) mais se
+// aproxima de vetor, com as mesmas colunas e o mesmo tratamento de ano de
+// AplicacaoRepo.BuscarPorVeiculo. A ordem e a da similaridade, com as
+// aplicacoes sem ano conhecido por ultimo.
+func (r *AplicacaoEmbeddingRepo) BuscarSemelhantes(ctx context.Context, vetor []float32, modelo string, filtro model.FiltroSemelhantes) ([]model.Aplicacao, error) {
+	args := []interface{}{vetorSQL(vetor), modelo}
+	filtros := ""
+
+	if filtro.Marca != "" {
+		args = append(args, termoBusca(filtro.Marca))
+		filtros += fmt.Sprintf(` AND %s LIKE $%d`, semAcento(`f."DescricaoFabricante"`), len(args))
+	}
+	if filtro.Ano != "" {
+		args = append(args, "%"+filtro.Ano+"%")
+		filtros += fmt.Sprintf(` AND (a."DescricaoAplicacao" ILIKE $%d OR %s)`, len(args), anoDesconhecido)
+	}
+	args = append(args, filtro.Limite, filtro.MinSimilaridade, filtro.Margem)
+	n := len(args)
+
+	query := `
+		WITH candidatas AS (
+			SELECT
+				a."CodigoAplicacao",
+				f."DescricaoFabricante" as marca,
+				a."DescricaoAplicacao",
+				COALESCE(a."ComplementoAplicacao3", '') as motor,
+				COALESCE(a."ComplementoAplicacao2", '') as periodo,
+				` + anoDesconhecido + ` as ano_desconhecido,
+				1 - (e."Embedding" <=> $1::vector) as similaridade
+			FROM "APLICACAO_EMBEDDING" e
+			JOIN "APLICACAO" a ON e."CodigoAplicacao" = a."CodigoAplicacao"
+			JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+			WHERE f."FlagAplicacao" = 1
+				AND e."Modelo" = $2` + filtros + `
+			ORDER BY e."Embedding" <=> $1::vector
+			LIMIT $` + strconv.Itoa(n-2) + `
+		)
+		SELECT "CodigoAplicacao", marca, "DescricaoAplicacao", motor, periodo, ano_desconhecido
+		FROM candidatas
+		WHERE similaridade >= $` + strconv.Itoa(n-1) + `
+			AND similaridade >= (SELECT MAX(similaridade) FROM candidatas) - $` + strconv.Itoa(n) + `
+		ORDER BY ano_desconhecido, similaridade DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aplicacoes []model.Aplicacao
+	for rows.Next() {
+		var a model.Aplicacao
+		if err := rows.Scan(&a.CodigoAplicacao, &a.Marca, &a.DescricaoAplicacao, &a.Motor, &a.Periodo, &a.AnoDesconhecido); err != nil {
+			return nil, err
+		}
+		aplicacoes = append(aplicacoes, a)
+	}
+
+	return aplicacoes, rows.Err()
+}
+
+// VizinhosMotul retorna as limit aplicacoes cujo embedding (de modelo) mais
+// se aproxima do da aplicacao codigoAplicacao e que ja tem especificacao do
+// scraper, com o tipo de veiculo Motul da mais recente. Sem embedding da
+// aplicacao o resultado e vazio.
+func (r *AplicacaoEmbeddingRepo) VizinhosMotul(ctx context.Context, codigoAplicacao int, modelo string, limit int) ([]model.VizinhoMotul, error) {
+	query := `
+		SELECT v."CodigoAplicacao", s."MotulVehicleTypeId", 1 - (v."Embedding" <=> e."Embedding")
+		FROM "APLICACAO_EMBEDDING" e
+		JOIN "APLICACAO_EMBEDDING" v ON v."Modelo" = e."Modelo" AND v."CodigoAplicacao" <> e."CodigoAplicacao"
+		JOIN LATERAL (
+			SELECT et."MotulVehicleTypeId"
+			FROM "ESPECIFICACAO_TECNICA" et
+			WHERE et."CodigoAplicacao" = v."CodigoAplicacao"
+				AND et."Ativo"
+				AND COALESCE(et."MotulVehicleTypeId", '') <> ''
+			ORDER BY et."AtualizadoEm" DESC
+			LIMIT 1
+		) s ON true
+		WHERE e."CodigoAplicacao" = $1
+			AND e."Modelo" = $2
+		ORDER BY v."Embedding" <=> e."Embedding"
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, codigoAplicacao, modelo, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vizinhos []model.VizinhoMotul
+	for rows.Next() {
+		var v model.VizinhoMotul
+		if err := rows.Scan(&v.CodigoAplicacao, &v.MotulVehicleTypeID, &v.Similaridade); err != nil {
+			return nil, err
+		}
+		vizinhos = append(vizinhos, v)
+	}
+
+	return vizinhos, rows.Err()
+}
@@ -3,22 +3,43 @@ package repository
 import (
 	"context"
 	"fmt"
-	"strings"
-
-	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 
+	"wega-catalog-api/internal/database"
 	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/normalize"
 )
 
 type AplicacaoRepo struct {
-	db *pgxpool.Pool
+	db database.Querier
 }
 
-func NewAplicacaoRepo(db *pgxpool.Pool) *AplicacaoRepo {
+// NewAplicacaoRepo recebe o pool primario ou um database.ReadPool (replica de leitura)
+func NewAplicacaoRepo(db database.Querier) *AplicacaoRepo {
 	return &AplicacaoRepo{db: db}
 }
 
-// BuscarPorVeiculo busca aplicacoes por marca, modelo, ano e motor
+// semAcento aplica na coluna a mesma normalizacao de normalize.Text (minusculas, sem acento)
+func semAcento(coluna string) string {
+	return fmt.Sprintf(`translate(LOWER(%s), '%s', '%s')`, coluna, normalize.SQLAccented, normalize.SQLPlain)
+}
+
+// termoBusca normaliza o termo digitado e monta o padrao LIKE de substring
+func termoBusca(termo string) string {
+	return "%" + normalize.Text.Apply(termo) + "%"
+}
+
+// regexAno reconhece um ano no periodo ou na descricao: "2019 -->" ou "// 08 -- 10"
+const regexAno = `(19|20)[0-9]{2}|//\s*[0-9]{2}\M`
+
+// anoDesconhecido e a expressao SQL verdadeira quando a aplicacao nao tem
+// nenhum ano reconhecivel no periodo nem na descricao
+var anoDesconhecido = `(COALESCE(a."ComplementoAplicacao2", '') !~ '` + regexAno + `'
+			AND a."DescricaoAplicacao" !~ '` + regexAno + `')`
+
+// BuscarPorVeiculo busca aplicacoes por marca, modelo, ano e motor. Com ano,
+// aplicacoes sem ano conhecido tambem entram no resultado (depois das demais),
+// marcadas com AnoDesconhecido, em vez de serem descartadas.
 func (r *AplicacaoRepo) BuscarPorVeiculo(ctx context.Context, marca, modelo, ano, motor string) ([]model.Aplicacao, error) {
 	query := `
 		SELECT DISTINCT
@@ -26,7 +47,8 @@ func (r *AplicacaoRepo) BuscarPorVeiculo(ctx context.Context, marca, modelo, ano
 			f."DescricaoFabricante" as marca,
 			a."DescricaoAplicacao",
 			COALESCE(a."ComplementoAplicacao3", '') as motor,
-			COALESCE(a."ComplementoAplicacao2", '') as periodo
+			COALESCE(a."ComplementoAplicacao2", '') as periodo,
+			` + anoDesconhecido + ` as ano_desconhecido
 		FROM "APLICACAO" a
 		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
 		WHERE f."FlagAplicacao" = 1
@@ -37,33 +59,33 @@ func (r *AplicacaoRepo) BuscarPorVeiculo(ctx context.Context, marca, modelo, ano
 
 	// Filtro por marca
 	if marca != "" {
-		query += fmt.Sprintf(` AND LOWER(f."DescricaoFabricante") ILIKE $%d`, argIndex)
-		args = append(args, "%"+strings.ToLower(marca)+"%")
+		query += fmt.Sprintf(` AND %s LIKE $%d`, semAcento(`f."DescricaoFabricante"`), argIndex)
+		args = append(args, termoBusca(marca))
 		argIndex++
 	}
 
 	// Filtro por modelo
 	if modelo != "" {
-		query += fmt.Sprintf(` AND LOWER(a."DescricaoAplicacao") ILIKE $%d`, argIndex)
-		args = append(args, "%"+strings.ToLower(modelo)+"%")
+		query += fmt.Sprintf(` AND %s LIKE $%d`, semAcento(`a."DescricaoAplicacao"`), argIndex)
+		args = append(args, termoBusca(modelo))
 		argIndex++
 	}
 
-	// Filtro por ano
+	// Filtro por ano (sem ano conhecido nao da para filtrar, entao a aplicacao e mantida)
 	if ano != "" {
-		query += fmt.Sprintf(` AND a."DescricaoAplicacao" ILIKE $%d`, argIndex)
+		query += fmt.Sprintf(` AND (a."DescricaoAplicacao" ILIKE $%d OR %s)`, argIndex, anoDesconhecido)
 		args = append(args, "%"+ano+"%")
 		argIndex++
 	}
 
 	// Filtro por motor
 	if motor != "" {
-		query += fmt.Sprintf(` AND a."DescricaoAplicacao" ILIKE $%d`, argIndex)
-		args = append(args, "%"+motor+"%")
+		query += fmt.Sprintf(` AND %s LIKE $%d`, semAcento(`a."DescricaoAplicacao"`), argIndex)
+		args = append(args, termoBusca(motor))
 		argIndex++
 	}
 
-	query += ` ORDER BY a."DescricaoAplicacao" LIMIT 50`
+	query += ` ORDER BY ano_desconhecido, a."DescricaoAplicacao" LIMIT 50`
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -74,7 +96,7 @@ func (r *AplicacaoRepo) BuscarPorVeiculo(ctx context.Context, marca, modelo, ano
 	var aplicacoes []model.Aplicacao
 	for rows.Next() {
 		var a model.Aplicacao
-		if err := rows.Scan(&a.CodigoAplicacao, &a.Marca, &a.DescricaoAplicacao, &a.Motor, &a.Periodo); err != nil {
+		if err := rows.Scan(&a.CodigoAplicacao, &a.Marca, &a.DescricaoAplicacao, &a.Motor, &a.Periodo, &a.AnoDesconhecido); err != nil {
 			return nil, err
 		}
 		aplicacoes = append(aplicacoes, a)
@@ -92,12 +114,12 @@ func (r *AplicacaoRepo) ListarOpcoes(ctx context.Context, marca, modelo string)
 		FROM "APLICACAO" a
 		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
 		WHERE f."FlagAplicacao" = 1
-			AND LOWER(f."DescricaoFabricante") ILIKE $1
-			AND LOWER(a."DescricaoAplicacao") ILIKE $2
+			AND ` + semAcento(`f."DescricaoFabricante"`) + ` LIKE $1
+			AND ` + semAcento(`a."DescricaoAplicacao"`) + ` LIKE $2
 		ORDER BY periodo, motor
 	`
 
-	rows, err := r.db.Query(ctx, query, "%"+strings.ToLower(marca)+"%", "%"+strings.ToLower(modelo)+"%")
+	rows, err := r.db.Query(ctx, query, termoBusca(marca), termoBusca(modelo))
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +160,8 @@ func (r *AplicacaoRepo) BuscarPorID(ctx context.Context, id int) (*model.Aplicac
 			f."DescricaoFabricante" as marca,
 			a."DescricaoAplicacao",
 			COALESCE(a."ComplementoAplicacao3", '') as motor,
-			COALESCE(a."ComplementoAplicacao2", '') as periodo
+			COALESCE(a."ComplementoAplicacao2", '') as periodo,
+			` + anoDesconhecido + ` as ano_desconhecido
 		FROM "APLICACAO" a
 		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
 		WHERE a."CodigoAplicacao" = $1
@@ -146,7 +169,7 @@ func (r *AplicacaoRepo) BuscarPorID(ctx context.Context, id int) (*model.Aplicac
 
 	var a model.Aplicacao
 	err := r.db.QueryRow(ctx, query, id).Scan(
-		&a.CodigoAplicacao, &a.Marca, &a.DescricaoAplicacao, &a.Motor, &a.Periodo,
+		&a.CodigoAplicacao, &a.Marca, &a.DescricaoAplicacao, &a.Motor, &a.Periodo, &a.AnoDesconhecido,
 	)
 	if err != nil {
 		return nil, err
@@ -155,8 +178,35 @@ func (r *AplicacaoRepo) BuscarPorID(ctx context.Context, id int) (*model.Aplicac
 	return &a, nil
 }
 
+// HasSearchIndex verifica se o indice full-text usado na busca por veiculo existe
+func (r *AplicacaoRepo) HasSearchIndex(ctx context.Context) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM pg_indexes
+			WHERE schemaname = 'public'
+			AND indexname = 'idx_aplicacao_descricao'
+		)
+	`).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check search index: %w", err)
+	}
+	return exists, nil
+}
+
 // GetAllVehicles returns all vehicles from the database for scraping
 func (r *AplicacaoRepo) GetAllVehicles(ctx context.Context) ([]model.Aplicacao, error) {
+	return r.queryVehicles(ctx, ``)
+}
+
+// GetVehiclesCreatedSince returns the vehicles imported after since, for
+// differential scraping. Rows imported before CriadoEm was tracked are excluded.
+func (r *AplicacaoRepo) GetVehiclesCreatedSince(ctx context.Context, since time.Time) ([]model.Aplicacao, error) {
+	return r.queryVehicles(ctx, ` AND a."CriadoEm" > $1`, since)
+}
+
+// queryVehicles returns the scrapable vehicles matching the extra filter, by ID
+func (r *AplicacaoRepo) queryVehicles(ctx context.Context, filter string, args ...any) ([]model.Aplicacao, error) {
 	query := `
 		SELECT
 			a."CodigoAplicacao",
@@ -167,11 +217,11 @@ func (r *AplicacaoRepo) GetAllVehicles(ctx context.Context) ([]model.Aplicacao,
 			COALESCE(a."ComplementoAplicacao3", '') as motor
 		FROM "APLICACAO" a
 		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
-		WHERE f."FlagAplicacao" = 1
+		WHERE f."FlagAplicacao" = 1` + filter + `
 		ORDER BY a."CodigoAplicacao"
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query vehicles: %w", err)
 	}
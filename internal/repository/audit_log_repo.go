@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// atorSistema assina as alteracoes feitas sem ator no contexto
+const atorSistema = "sistema"
+
+type atorKey struct{}
+
+type atorContexto struct {
+	ator      string
+	requestID string
+}
+
+// ComAtor marca o contexto com quem faz as alteracoes (usuario do admin, job
+// ou model.AtorScraper) e o request ID da API, gravados no AUDIT_LOG
+func ComAtor(ctx context.Context, ator, requestID string) context.Context {
+	return context.WithValue(ctx, atorKey{}, atorContexto{ator: ator, requestID: requestID})
+}
+
+// AtorDe retorna o ator do contexto ("sistema" se nao houver)
+func AtorDe(ctx context.Context) string {
+	if a, ok := ctx.Value(atorKey{}).(atorContexto); ok && a.ator != "" {
+		return a.ator
+	}
+	return atorSistema
+}
+
+// AuditLogRepo grava e consulta o AUDIT_LOG
+type AuditLogRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditLogRepo(pool *pgxpool.Pool) *AuditLogRepo {
+	return &AuditLogRepo{pool: pool}
+}
+
+// novoRegistro monta uma linha do AUDIT_LOG com o ator do contexto
+func novoRegistro(ctx context.Context, acao, entidade, entidadeID string, dados any) model.AuditLog {
+	registro := model.AuditLog{
+		Ator:       AtorDe(ctx),
+		Acao:       acao,
+		Entidade:   entidade,
+		EntidadeID: entidadeID,
+	}
+	if a, ok := ctx.Value(atorKey{}).(atorContexto); ok {
+		registro.RequestID = a.requestID
+	}
+	if dados != nil {
+		registro.Dados, _ = json.Marshal(dados)
+	}
+	return registro
+}
+
+// Registrar grava as linhas em um unico round trip
+func (r *AuditLogRepo) Registrar(ctx context.Context, registros ...model.AuditLog) error {
+	if len(registros) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, reg := range registros {
+		batch.Queue(`
+			INSERT INTO "AUDIT_LOG" ("Ator", "Acao", "Entidade", "EntidadeID", "Dados", "RequestID")
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''))
+		`, reg.Ator, reg.Acao, reg.Entidade, reg.EntidadeID, []byte(reg.Dados), reg.RequestID)
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Listar retorna as alteracoes mais recentes primeiro
+func (r *AuditLogRepo) Listar(ctx context.Context, filtro model.AuditLogFiltro) ([]model.AuditLog, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filtro.Ator != "" {
+		add(`"Ator" = $%d`, filtro.Ator)
+	}
+	if filtro.Entidade != "" {
+		add(`"Entidade" = UPPER($%d)`, filtro.Entidade)
+	}
+	if filtro.EntidadeID != "" {
+		add(`"EntidadeID" = $%d`, filtro.EntidadeID)
+	}
+	if filtro.Desde != nil {
+		add(`"CriadoEm" >= $%d`, *filtro.Desde)
+	}
+	if filtro.Ate != nil {
+		add(`"CriadoEm" <= $%d`, *filtro.Ate)
+	}
+
+	query := `
+		SELECT "ID", "Ator", "Acao", "Entidade", COALESCE("EntidadeID", ''), "Dados", COALESCE("RequestID", ''), "CriadoEm"
+		FROM "AUDIT_LOG"`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	args = append(args, filtro.Limit)
+	query += fmt.Sprintf(` ORDER BY "CriadoEm" DESC, "ID" DESC LIMIT $%d`, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	registros := []model.AuditLog{}
+	for rows.Next() {
+		var reg model.AuditLog
+		var dados []byte
+		if err := rows.Scan(&reg.ID, &reg.Ator, &reg.Acao, &reg.Entidade, &reg.EntidadeID, &dados, &reg.RequestID, &reg.CriadoEm); err != nil {
+			return nil, err
+		}
+		reg.Dados = dados
+		registros = append(registros, reg)
+	}
+	return registros, rows.Err()
+}
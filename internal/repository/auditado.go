@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Decoradores que gravam no AUDIT_LOG as alteracoes feitas pelo admin, pelos
+// jobs e pelo scraper, com o ator de ComAtor. Cada um embute o repositorio
+// original (as leituras passam direto) e so registra depois que a alteracao
+// foi confirmada; uma falha ao auditar fica no log e nao desfaz a alteracao.
+
+// auditar grava os registros, logando a falha
+func auditar(ctx context.Context, audit *AuditLogRepo, registros ...model.AuditLog) {
+	if err := audit.Registrar(context.WithoutCancel(ctx), registros...); err != nil {
+		slog.Warn("falha ao gravar audit log", "ator", AtorDe(ctx), "registros", len(registros), "error", err)
+	}
+}
+
+func idTexto(id int) string {
+	return strconv.Itoa(id)
+}
+
+// EspecificacaoAuditada audita as gravacoes de ESPECIFICACAO_TECNICA
+type EspecificacaoAuditada struct {
+	*EspecificacaoRepository
+	audit *AuditLogRepo
+}
+
+func NewEspecificacaoAuditada(repo *EspecificacaoRepository, audit *AuditLogRepo) *EspecificacaoAuditada {
+	return &EspecificacaoAuditada{EspecificacaoRepository: repo, audit: audit}
+}
+
+func (r *EspecificacaoAuditada) Insert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	if err := r.EspecificacaoRepository.Insert(ctx, spec); err != nil {
+		return err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditInsert, "ESPECIFICACAO_TECNICA", idTexto(spec.ID), spec))
+	return nil
+}
+
+func (r *EspecificacaoAuditada) InsertBatch(ctx context.Context, specs []model.EspecificacaoTecnica) error {
+	if err := r.EspecificacaoRepository.InsertBatch(ctx, specs); err != nil {
+		return err
+	}
+	registros := make([]model.AuditLog, len(specs))
+	for i := range specs {
+		registros[i] = novoRegistro(ctx, model.AuditInsert, "ESPECIFICACAO_TECNICA", idTexto(specs[i].ID), specs[i])
+	}
+	auditar(ctx, r.audit, registros...)
+	return nil
+}
+
+// Upsert registra insert ou update conforme spec.Gravacao; uma regravacao com
+// o mesmo conteudo, ou recusada por estar inativa, nao e auditada
+func (r *EspecificacaoAuditada) Upsert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	if err := r.EspecificacaoRepository.Upsert(ctx, spec); err != nil {
+		return err
+	}
+	if spec.Gravacao == model.GravacaoInalterada || spec.Gravacao == model.GravacaoInativa {
+		return nil
+	}
+	acao := model.AuditUpdate
+	if spec.Gravacao == model.GravacaoInserida {
+		acao = model.AuditInsert
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, acao, "ESPECIFICACAO_TECNICA", idTexto(spec.ID), spec))
+	return nil
+}
+
+func (r *EspecificacaoAuditada) UpdateNorma(ctx context.Context, id int, norma string) error {
+	if err := r.EspecificacaoRepository.UpdateNorma(ctx, id, norma); err != nil {
+		return err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "ESPECIFICACAO_TECNICA", idTexto(id), map[string]string{"norma": norma}))
+	return nil
+}
+
+func (r *EspecificacaoAuditada) Desativar(ctx context.Context, lote EspecificacaoLote) (int64, error) {
+	alteradas, err := r.EspecificacaoRepository.Desativar(ctx, lote)
+	if err != nil || alteradas == 0 {
+		return alteradas, err
+	}
+	auditar(ctx, r.audit, novoRegistroLote(ctx, lote, false, alteradas))
+	return alteradas, nil
+}
+
+func (r *EspecificacaoAuditada) Reativar(ctx context.Context, lote EspecificacaoLote) (int64, error) {
+	alteradas, err := r.EspecificacaoRepository.Reativar(ctx, lote)
+	if err != nil || alteradas == 0 {
+		return alteradas, err
+	}
+	auditar(ctx, r.audit, novoRegistroLote(ctx, lote, true, alteradas))
+	return alteradas, nil
+}
+
+func (r *EspecificacaoAuditada) RollbackRun(ctx context.Context, runID int, remover bool) (int64, error) {
+	alteradas, err := r.EspecificacaoRepository.RollbackRun(ctx, runID, remover)
+	if err != nil || alteradas == 0 {
+		return alteradas, err
+	}
+	acao := model.AuditUpdate
+	if remover {
+		acao = model.AuditDelete
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, acao, "ESPECIFICACAO_TECNICA", "", map[string]any{
+		"scraper_run_id": runID,
+		"remover":        remover,
+		"alteradas":      alteradas,
+	}))
+	return alteradas, nil
+}
+
+func (r *EspecificacaoAuditada) RebaixarConfianca(ctx context.Context, codigoAplicacao int, motulTypeID string, confianca float64) (int64, error) {
+	alteradas, err := r.EspecificacaoRepository.RebaixarConfianca(ctx, codigoAplicacao, motulTypeID, confianca)
+	if err != nil || alteradas == 0 {
+		return alteradas, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "ESPECIFICACAO_TECNICA", "", map[string]any{
+		"codigo_aplicacao":      codigoAplicacao,
+		"motul_vehicle_type_id": motulTypeID,
+		"match_confidence":      confianca,
+		"alteradas":             alteradas,
+	}))
+	return alteradas, nil
+}
+
+func novoRegistroLote(ctx context.Context, lote EspecificacaoLote, ativo bool, alteradas int64) model.AuditLog {
+	return novoRegistro(ctx, model.AuditUpdate, "ESPECIFICACAO_TECNICA", "", map[string]any{
+		"fonte":     lote.Fonte,
+		"desde":     lote.Desde,
+		"ate":       lote.Ate,
+		"ativo":     ativo,
+		"alteradas": alteradas,
+	})
+}
+
+// QuotaAuditada audita o cadastro de cotas de API; o contador de uso nao
+// e auditado
+type QuotaAuditada struct {
+	*QuotaRepo
+	audit *AuditLogRepo
+}
+
+func NewQuotaAuditada(repo *QuotaRepo, audit *AuditLogRepo) *QuotaAuditada {
+	return &QuotaAuditada{QuotaRepo: repo, audit: audit}
+}
+
+func (r *QuotaAuditada) Create(ctx context.Context, req model.QuotaRequest, chaveHash, chavePrefixo string) (*model.ApiQuota, error) {
+	quota, err := r.QuotaRepo.Create(ctx, req, chaveHash, chavePrefixo)
+	if err != nil {
+		return nil, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditInsert, "API_QUOTA", idTexto(quota.ID), quota))
+	return quota, nil
+}
+
+func (r *QuotaAuditada) Update(ctx context.Context, id int, req model.QuotaRequest) (*model.ApiQuota, error) {
+	quota, err := r.QuotaRepo.Update(ctx, id, req)
+	if err != nil || quota == nil {
+		return quota, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "API_QUOTA", idTexto(id), quota))
+	return quota, nil
+}
+
+func (r *QuotaAuditada) Delete(ctx context.Context, id int) (bool, error) {
+	found, err := r.QuotaRepo.Delete(ctx, id)
+	if err != nil || !found {
+		return found, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditDelete, "API_QUOTA", idTexto(id), nil))
+	return true, nil
+}
+
+// FalhaAuditada audita as acoes do admin sobre SCRAPER_FALHAS; as falhas
+// registradas pelo scraper a cada tentativa nao sao auditadas
+type FalhaAuditada struct {
+	*ScraperFalhaRepo
+	audit *AuditLogRepo
+}
+
+func NewFalhaAuditada(repo *ScraperFalhaRepo, audit *AuditLogRepo) *FalhaAuditada {
+	return &FalhaAuditada{ScraperFalhaRepo: repo, audit: audit}
+}
+
+func (r *FalhaAuditada) ForceRetry(ctx context.Context, id int) (bool, error) {
+	found, err := r.ScraperFalhaRepo.ForceRetry(ctx, id)
+	if err != nil || !found {
+		return found, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "SCRAPER_FALHAS", idTexto(id), map[string]bool{"retry": true}))
+	return true, nil
+}
+
+func (r *FalhaAuditada) Delete(ctx context.Context, id int) (bool, error) {
+	found, err := r.ScraperFalhaRepo.Delete(ctx, id)
+	if err != nil || !found {
+		return found, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditDelete, "SCRAPER_FALHAS", idTexto(id), nil))
+	return true, nil
+}
+
+func (r *FalhaAuditada) DeleteResolved(ctx context.Context, olderThan time.Duration) (int64, error) {
+	removed, err := r.ScraperFalhaRepo.DeleteResolved(ctx, olderThan)
+	if err != nil || removed == 0 {
+		return removed, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditDelete, "SCRAPER_FALHAS", "", map[string]any{
+		"resolvidas_ha_mais_de": olderThan.String(),
+		"removidas":             removed,
+	}))
+	return removed, nil
+}
+
+// AliasAuditado audita os aliases importados pelo admin e os aprendidos pelo
+// scraper
+type AliasAuditado struct {
+	*AliasRepo
+	audit *AuditLogRepo
+}
+
+func NewAliasAuditado(repo *AliasRepo, audit *AuditLogRepo) *AliasAuditado {
+	return &AliasAuditado{AliasRepo: repo, audit: audit}
+}
+
+// Import registra um insert por alias gravado e um delete por alias removido
+func (r *AliasAuditado) Import(ctx context.Context, aliases []model.AliasVeiculo) (*model.AliasImportResponse, error) {
+	result, err := r.AliasRepo.Import(ctx, aliases)
+	if err != nil {
+		return nil, err
+	}
+	registros := make([]model.AuditLog, 0, len(aliases))
+	for _, a := range aliases {
+		acao := model.AuditInsert
+		if a.Destino == "" {
+			acao = model.AuditDelete
+		}
+		registros = append(registros, novoRegistro(ctx, acao, "ALIAS_VEICULO", chaveAlias(a), a))
+	}
+	auditar(ctx, r.audit, registros...)
+	return result, nil
+}
+
+// SaveLearned registra um resumo: o scraper grava centenas de aliases por
+// execucao, quase todos sem mudanca
+func (r *AliasAuditado) SaveLearned(ctx context.Context, aliases []model.AliasVeiculo) (int, error) {
+	saved, err := r.AliasRepo.SaveLearned(ctx, aliases)
+	if err != nil || saved == 0 {
+		return saved, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "ALIAS_VEICULO", "", map[string]int{
+		"aliases":   len(aliases),
+		"alterados": saved,
+	}))
+	return saved, nil
+}
+
+// chaveAlias identifica um alias como a chave unica de ALIAS_VEICULO
+func chaveAlias(a model.AliasVeiculo) string {
+	return a.Tipo + "|" + a.Categoria + "|" + a.Marca + "|" + a.Origem
+}
+
+// SincronizacaoAuditada audita as sincronizacoes de preco e estoque; o
+// detalhe por campo fica em PRODUTO_SINCRONIZACAO_ALTERACAO
+type SincronizacaoAuditada struct {
+	*ProdutoSincronizacaoRepo
+	audit *AuditLogRepo
+}
+
+func NewSincronizacaoAuditada(repo *ProdutoSincronizacaoRepo, audit *AuditLogRepo) *SincronizacaoAuditada {
+	return &SincronizacaoAuditada{ProdutoSincronizacaoRepo: repo, audit: audit}
+}
+
+func (r *SincronizacaoAuditada) Sincronizar(ctx context.Context, origem string, atualizacoes []model.AtualizacaoProduto) (*model.SincronizacaoProdutos, error) {
+	s, err := r.ProdutoSincronizacaoRepo.Sincronizar(ctx, origem, atualizacoes)
+	if err != nil {
+		return nil, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "PRODUTO", "", map[string]any{
+		"sincronizacao_id": s.ID,
+		"origem":           s.Origem,
+		"linhas":           s.Linhas,
+		"alterados":        s.Alterados,
+	}))
+	return s, nil
+}
+
+// ReferenciaAdminAuditada leva as alteracoes de referencias cruzadas ao
+// AUDIT_LOG, alem da auditoria propria em REFERENCIACRUZADA_AUDITORIA
+type ReferenciaAdminAuditada struct {
+	*ReferenciaAdminRepo
+	audit *AuditLogRepo
+}
+
+func NewReferenciaAdminAuditada(repo *ReferenciaAdminRepo, audit *AuditLogRepo) *ReferenciaAdminAuditada {
+	return &ReferenciaAdminAuditada{ReferenciaAdminRepo: repo, audit: audit}
+}
+
+func (r *ReferenciaAdminAuditada) Criar(ctx context.Context, req model.ReferenciaRequest, ator string) (*model.ReferenciaCruzada, error) {
+	ref, err := r.ReferenciaAdminRepo.Criar(ctx, req, ator)
+	if err != nil {
+		return nil, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditInsert, "REFERENCIACRUZADA", idTexto(ref.ID), ref))
+	return ref, nil
+}
+
+func (r *ReferenciaAdminAuditada) Alterar(ctx context.Context, id int, req model.ReferenciaRequest, ator string) (*model.ReferenciaCruzada, error) {
+	ref, err := r.ReferenciaAdminRepo.Alterar(ctx, id, req, ator)
+	if err != nil || ref == nil {
+		return ref, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditUpdate, "REFERENCIACRUZADA", idTexto(id), ref))
+	return ref, nil
+}
+
+func (r *ReferenciaAdminAuditada) Remover(ctx context.Context, id int, ator string) (bool, error) {
+	found, err := r.ReferenciaAdminRepo.Remover(ctx, id, ator)
+	if err != nil || !found {
+		return found, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditDelete, "REFERENCIACRUZADA", idTexto(id), nil))
+	return true, nil
+}
+
+// SpecConflitoAuditado audita os conflitos entre fontes descartados pelo
+// admin; a deteccao no Upsert nao e auditada
+type SpecConflitoAuditado struct {
+	*SpecConflitoRepo
+	audit *AuditLogRepo
+}
+
+func NewSpecConflitoAuditado(repo *SpecConflitoRepo, audit *AuditLogRepo) *SpecConflitoAuditado {
+	return &SpecConflitoAuditado{SpecConflitoRepo: repo, audit: audit}
+}
+
+func (r *SpecConflitoAuditado) Remover(ctx context.Context, id int) (bool, error) {
+	found, err := r.SpecConflitoRepo.Remover(ctx, id)
+	if err != nil || !found {
+		return found, err
+	}
+	auditar(ctx, r.audit, novoRegistro(ctx, model.AuditDelete, "SPEC_CONFLITOS", idTexto(id), nil))
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"wega-catalog-api/internal/database"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/normalize"
+)
+
+// AutocompleteRepo sugere marcas, modelos e codigos de produto para caixas de
+// busca com type-ahead, por prefixo e por similaridade de trigramas (pg_trgm,
+// migration 000002)
+type AutocompleteRepo struct {
+	db database.Querier
+}
+
+func NewAutocompleteRepo(db database.Querier) *AutocompleteRepo {
+	return &AutocompleteRepo{db: db}
+}
+
+// codigoSemSeparador e a expressao do indice idx_produto_numero_trgm: o codigo
+// em maiusculas sem hifen, espaco, ponto ou barra ("wo 340" acha "WO-340")
+const codigoSemSeparador = `UPPER(translate(p."NumeroProduto", '- ./', ''))`
+
+// normalizarCodigo aplica ao termo digitado a mesma regra de codigoSemSeparador
+func normalizarCodigo(termo string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "", ".", "", "/", "").Replace(termo))
+}
+
+// Sugerir retorna ate limit sugestoes para termo, da maior para a menor
+// pontuacao: 1 a mais para quem comeca com o termo (0,5 para modelos em que
+// uma palavra comeca com ele) somado a similaridade de trigramas
+func (r *AutocompleteRepo) Sugerir(ctx context.Context, termo string, limit int) ([]model.Sugestao, error) {
+	descricao := semAcento(`a."DescricaoAplicacao"`)
+	marca := semAcento(`f."DescricaoFabricante"`)
+
+	query := `
+		WITH fabricantes AS (
+			SELECT
+				'` + model.SugestaoFabricante + `' AS tipo,
+				f."DescricaoFabricante" AS texto,
+				f."CodigoFabricante" AS codigo,
+				'' AS detalhe,
+				((CASE WHEN ` + marca + ` LIKE $1 || '%' THEN 1 ELSE 0 END) + similarity(` + marca + `, $1))::float8 AS score
+			FROM "FABRICANTE" f
+			WHERE f."FlagAplicacao" = 1
+				AND (` + marca + ` LIKE $1 || '%' OR ` + marca + ` % $1)
+			ORDER BY score DESC
+			LIMIT $3
+		), modelos AS (
+			SELECT
+				'` + model.SugestaoModelo + `',
+				a."DescricaoAplicacao",
+				a."CodigoAplicacao",
+				f."DescricaoFabricante",
+				((CASE
+					WHEN ` + descricao + ` LIKE $1 || '%' THEN 1
+					WHEN ` + descricao + ` LIKE '% ' || $1 || '%' THEN 0.5
+					ELSE 0
+				END) + word_similarity($1, ` + descricao + `))::float8 AS score
+			FROM "APLICACAO" a
+			JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+			WHERE f."FlagAplicacao" = 1
+				AND (` + descricao + ` LIKE $1 || '%'
+					OR ` + descricao + ` LIKE '% ' || $1 || '%'
+					OR $1 <% ` + descricao + `)
+			ORDER BY score DESC, length(a."DescricaoAplicacao")
+			LIMIT $3
+		), produtos AS (
+			SELECT
+				'` + model.SugestaoProduto + `',
+				p."NumeroProduto",
+				p."CodigoProduto",
+				sg."DescricaoSubGrupoProduto",
+				((CASE WHEN ` + codigoSemSeparador + ` LIKE $2 || '%' THEN 1 ELSE 0 END)
+					+ similarity(` + codigoSemSeparador + `, $2))::float8 AS score
+			FROM "PRODUTO" p
+			JOIN "SUBGRUPOPRODUTO" sg ON p."CodigoSubGrupoProduto" = sg."CodigoSubGrupoProduto"
+			WHERE $2 <> ''
+				AND (` + codigoSemSeparador + ` LIKE $2 || '%' OR ` + codigoSemSeparador + ` % $2)
+			ORDER BY score DESC, p."NumeroProduto"
+			LIMIT $3
+		)
+		SELECT * FROM fabricantes
+		UNION ALL SELECT * FROM modelos
+		UNION ALL SELECT * FROM produtos
+		ORDER BY score DESC, texto
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, normalize.Text.Apply(termo), normalizarCodigo(termo), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sugestoes []model.Sugestao
+	for rows.Next() {
+		var s model.Sugestao
+		if err := rows.Scan(&s.Tipo, &s.Texto, &s.Codigo, &s.Detalhe, &s.Score); err != nil {
+			return nil, err
+		}
+		sugestoes = append(sugestoes, s)
+	}
+
+	return sugestoes, rows.Err()
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ClassificacaoRepo caches LLM vehicle categories in CLASSIFICACAO_VEICULO
+type ClassificacaoRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewClassificacaoRepo creates a new vehicle classification repository
+func NewClassificacaoRepo(pool *pgxpool.Pool) *ClassificacaoRepo {
+	return &ClassificacaoRepo{pool: pool}
+}
+
+// LoadClassifications returns the cached category of every classified vehicle
+func (r *ClassificacaoRepo) LoadClassifications(ctx context.Context) (map[int]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT "CodigoAplicacao", "Categoria" FROM "CLASSIFICACAO_VEICULO"`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load classifications: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var category string
+		if err := rows.Scan(&id, &category); err != nil {
+			return nil, fmt.Errorf("failed to scan classification: %w", err)
+		}
+		categories[id] = category
+	}
+
+	return categories, rows.Err()
+}
+
+// SaveClassifications stores (or replaces) the category of each vehicle
+func (r *ClassificacaoRepo) SaveClassifications(ctx context.Context, categories map[int]string) error {
+	batch := &pgx.Batch{}
+	for id, category := range categories {
+		batch.Queue(`
+			INSERT INTO "CLASSIFICACAO_VEICULO" ("CodigoAplicacao", "Categoria")
+			VALUES ($1, $2)
+			ON CONFLICT ("CodigoAplicacao") DO UPDATE SET
+				"Categoria" = EXCLUDED."Categoria",
+				"ClassificadoEm" = NOW()
+		`, id, category)
+	}
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save classifications: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Situacoes de uma aplicacao no relatorio de cobertura
+const (
+	situacaoComEspecificacao = "com_especificacao"
+	situacaoFalhaPendente    = "falha_pendente"
+	situacaoSemResultado     = "sem_resultado"
+	situacaoNuncaTentada     = "nunca_tentada"
+)
+
+// CoberturaRepo agrega a cobertura de especificacoes das aplicacoes do catalogo
+type CoberturaRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCoberturaRepo(pool *pgxpool.Pool) *CoberturaRepo {
+	return &CoberturaRepo{pool: pool}
+}
+
+// CoberturaFilter holds the options of the coverage report
+type CoberturaFilter struct {
+	Agrupamento string // model.CoberturaPorFabricante ou model.CoberturaPorModelo
+	Fabricante  string // Busca parcial, sem acento (vazio = todos)
+	TipoFluido  string // Codigo canonico exigido na especificacao (vazio = qualquer)
+}
+
+// Grupos retorna a cobertura de cada fabricante (ou fabricante + modelo), com
+// mais aplicacoes sem especificacao primeiro. O modelo e a parte da descricao
+// antes do primeiro " - ", como no scraper.
+func (r *CoberturaRepo) Grupos(ctx context.Context, filter CoberturaFilter) ([]model.CoberturaGrupo, error) {
+	args := []any{filter.TipoFluido}
+
+	where := ``
+	if filter.Fabricante != "" {
+		where = ` AND ` + semAcento(`f."DescricaoFabricante"`) + ` LIKE $2`
+		args = append(args, termoBusca(filter.Fabricante))
+	}
+
+	modelo := `''`
+	if filter.Agrupamento == model.CoberturaPorModelo {
+		modelo = `TRIM(split_part(a."DescricaoAplicacao", ' - ', 1))`
+	}
+
+	query := `
+		WITH situacao AS (
+			SELECT
+				f."DescricaoFabricante" AS fabricante,
+				` + modelo + ` AS modelo,
+				CASE
+					WHEN EXISTS (
+						SELECT 1 FROM "ESPECIFICACAO_TECNICA" e
+						WHERE e."CodigoAplicacao" = a."CodigoAplicacao" AND e."Ativo"
+							AND ($1 = '' OR e."TipoFluido" = $1)
+					) THEN '` + situacaoComEspecificacao + `'
+					WHEN EXISTS (
+						SELECT 1 FROM "SCRAPER_FALHAS" sf
+						WHERE sf."CodigoAplicacao" = a."CodigoAplicacao" AND sf."Resolvido" = FALSE
+					) THEN '` + situacaoFalhaPendente + `'
+					WHEN EXISTS (
+						SELECT 1 FROM "SCRAPER_TENTATIVA" t
+						WHERE t."CodigoAplicacao" = a."CodigoAplicacao"
+					) THEN '` + situacaoSemResultado + `'
+					ELSE '` + situacaoNuncaTentada + `'
+				END AS situacao
+			FROM "APLICACAO" a
+			JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+			WHERE f."FlagAplicacao" = 1` + where + `
+		)
+		SELECT
+			fabricante,
+			modelo,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE situacao = '` + situacaoComEspecificacao + `'),
+			COUNT(*) FILTER (WHERE situacao = '` + situacaoFalhaPendente + `'),
+			COUNT(*) FILTER (WHERE situacao = '` + situacaoSemResultado + `'),
+			COUNT(*) FILTER (WHERE situacao = '` + situacaoNuncaTentada + `')
+		FROM situacao
+		GROUP BY fabricante, modelo
+		ORDER BY COUNT(*) FILTER (WHERE situacao <> '` + situacaoComEspecificacao + `') DESC, fabricante, modelo`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query coverage: %w", err)
+	}
+	defer rows.Close()
+
+	grupos := []model.CoberturaGrupo{}
+	for rows.Next() {
+		var g model.CoberturaGrupo
+		if err := rows.Scan(
+			&g.Fabricante, &g.Modelo, &g.Total,
+			&g.ComEspecificacao, &g.FalhaPendente, &g.SemResultado, &g.NuncaTentadas,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan coverage row: %w", err)
+		}
+		g.CalcularCobertura()
+		grupos = append(grupos, g)
+	}
+
+	return grupos, rows.Err()
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/database"
+	"wega-catalog-api/internal/model"
+)
+
+// CompletudeRepo calcula e guarda os relatorios de completude do catalogo em RELATORIO_COMPLETUDE
+type CompletudeRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCompletudeRepo(pool *pgxpool.Pool) *CompletudeRepo {
+	return &CompletudeRepo{pool: pool}
+}
+
+// Calcular conta, por fabricante, as aplicacoes com filtro, com especificacao
+// de oleo do motor e com referencia cruzada em algum dos filtros. Percorre o
+// catalogo inteiro, entao nao usa o prazo por consulta das rotas da API.
+func (r *CompletudeRepo) Calcular(ctx context.Context) ([]model.CompletudeFabricante, error) {
+	rows, err := r.pool.Query(database.WithQueryTimeout(ctx, 0), `
+		SELECT
+			f."CodigoFabricante",
+			f."DescricaoFabricante",
+			COUNT(*),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM "PRODUTO_APLICACAO" pa
+				WHERE pa."CodigoAplicacao" = a."CodigoAplicacao"
+			)),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM "ESPECIFICACAO_TECNICA" e
+				WHERE e."CodigoAplicacao" = a."CodigoAplicacao" AND e."TipoFluido" = $1 AND e."Ativo"
+			)),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM "PRODUTO_APLICACAO" pa
+				JOIN "REFERENCIACRUZADA" rc ON rc."CodigoProduto" = pa."CodigoProduto"
+				WHERE pa."CodigoAplicacao" = a."CodigoAplicacao"
+			))
+		FROM "APLICACAO" a
+		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+		WHERE f."FlagAplicacao" = 1
+		GROUP BY f."CodigoFabricante", f."DescricaoFabricante"
+		ORDER BY f."DescricaoFabricante"
+	`, model.FluidoOleoMotor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute completeness: %w", err)
+	}
+
+	return scanCompletude(rows)
+}
+
+// Salvar grava um relatorio calculado com a data informada
+func (r *CompletudeRepo) Salvar(ctx context.Context, geradoEm time.Time, fabricantes []model.CompletudeFabricante) error {
+	batch := &pgx.Batch{}
+	for _, c := range fabricantes {
+		batch.Queue(`
+			INSERT INTO "RELATORIO_COMPLETUDE" (
+				"GeradoEm", "CodigoFabricante", "Fabricante",
+				"Aplicacoes", "ComFiltro", "ComEspecificacao", "ComReferencia"
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, geradoEm, c.CodigoFabricante, c.Fabricante, c.Aplicacoes, c.ComFiltro, c.ComEspecificacao, c.ComReferencia)
+	}
+
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save completeness report: %w", err)
+	}
+	return nil
+}
+
+// Datas retorna as datas dos ultimos relatorios gravados, mais recentes primeiro
+func (r *CompletudeRepo) Datas(ctx context.Context, limit int) ([]time.Time, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT "GeradoEm" FROM "RELATORIO_COMPLETUDE"
+		ORDER BY "GeradoEm" DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completeness reports: %w", err)
+	}
+	defer rows.Close()
+
+	var datas []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("failed to scan completeness report date: %w", err)
+		}
+		datas = append(datas, t)
+	}
+
+	return datas, rows.Err()
+}
+
+// Buscar retorna os fabricantes do relatorio gravado na data informada
+func (r *CompletudeRepo) Buscar(ctx context.Context, geradoEm time.Time) ([]model.CompletudeFabricante, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			"CodigoFabricante", "Fabricante",
+			"Aplicacoes", "ComFiltro", "ComEspecificacao", "ComReferencia"
+		FROM "RELATORIO_COMPLETUDE"
+		WHERE "GeradoEm" = $1
+		ORDER BY "Fabricante"
+	`, geradoEm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load completeness report: %w", err)
+	}
+
+	return scanCompletude(rows)
+}
+
+func scanCompletude(rows pgx.Rows) ([]model.CompletudeFabricante, error) {
+	defer rows.Close()
+
+	fabricantes := []model.CompletudeFabricante{}
+	for rows.Next() {
+		var c model.CompletudeFabricante
+		if err := rows.Scan(
+			&c.CodigoFabricante, &c.Fabricante,
+			&c.Aplicacoes, &c.ComFiltro, &c.ComEspecificacao, &c.ComReferencia,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan completeness row: %w", err)
+		}
+		fabricantes = append(fabricantes, c)
+	}
+
+	return fabricantes, rows.Err()
+}
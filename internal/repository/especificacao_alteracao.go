@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"wega-catalog-api/internal/model"
+)
+
+// campoConteudo e um campo do conteudo normalizado de uma especificacao
+// (nil = vazio)
+type campoConteudo struct {
+	nome  string
+	valor *string
+}
+
+// camposConteudo lista, sempre na mesma ordem, o que a especificacao diz do
+// veiculo. A fonte faz parte da chave (cada fonte tem a sua linha), e
+// MatchConfidence e ScraperRunID ficam de fora: uma nova execucao com o mesmo
+// resultado nao e uma alteracao (Upsert so atualiza a confianca).
+func camposConteudo(spec *model.EspecificacaoTecnica) []campoConteudo {
+	return []campoConteudo{
+		{"motul_vehicle_type_id", textoNormalizado(spec.MotulVehicleTypeID)},
+		{"viscosidade", textoNormalizado(spec.Viscosidade)},
+		{"capacidade", textoNormalizado(spec.Capacidade)},
+		{"norma", textoNormalizado(spec.Norma)},
+		{"recomendacao", textoNormalizado(spec.Recomendacao)},
+		{"observacao", textoNormalizado(spec.Observacao)},
+		{"intervalo_troca_km", inteiroTexto(spec.IntervaloTrocaKm)},
+		{"intervalo_troca_meses", inteiroTexto(spec.IntervaloTrocaMeses)},
+	}
+}
+
+// textoNormalizado junta espacos repetidos e apara as pontas; vazio vira nil
+func textoNormalizado(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.Join(strings.Fields(*s), " ")
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+func inteiroTexto(n *int) *string {
+	if n == nil {
+		return nil
+	}
+	t := strconv.Itoa(*n)
+	return &t
+}
+
+// hashConteudo e o SHA-256 (hex) dos campos normalizados
+func hashConteudo(campos []campoConteudo) string {
+	h := sha256.New()
+	for _, c := range campos {
+		h.Write([]byte(c.nome))
+		if c.valor != nil {
+			h.Write([]byte{'='})
+			h.Write([]byte(*c.valor))
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// especificacaoAtual le e trava a linha com a mesma chave de spec, inclusive a fonte (nil se
+// nao existe), com o flag Ativo e os graus SAE usados por detectarConflito
+func especificacaoAtual(ctx context.Context, tx pgx.Tx, spec *model.EspecificacaoTecnica) (*model.EspecificacaoTecnica, bool, error) {
+	var atual model.EspecificacaoTecnica
+	var ativo bool
+	err := tx.QueryRow(ctx, `
+		SELECT "ID", "Fonte", "MotulVehicleTypeId", "Viscosidade", "Capacidade", "Norma",
+			"Recomendacao", "Observacao", "IntervaloTrocaKm", "IntervaloTrocaMeses", "ViscosidadesSAE", "Ativo"
+		FROM "ESPECIFICACAO_TECNICA"
+		WHERE "CodigoAplicacao" = $1 AND "TipoFluido" = $2 AND "Condicao" = $3
+			AND especificacao_fonte("Fonte") = especificacao_fonte($4)
+		FOR UPDATE
+	`, spec.CodigoAplicacao, spec.TipoFluido, spec.Condicao, spec.Fonte).Scan(
+		&atual.ID, &atual.Fonte, &atual.MotulVehicleTypeID, &atual.Viscosidade, &atual.Capacidade, &atual.Norma,
+		&atual.Recomendacao, &atual.Observacao, &atual.IntervaloTrocaKm, &atual.IntervaloTrocaMeses, &atual.ViscosidadesSAE, &ativo,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read current especificacao: %w", err)
+	}
+	return &atual, ativo, nil
+}
+
+// registrarAlteracao grava em ESPECIFICACAO_ALTERACAO os campos que mudaram
+// (todos os preenchidos, na insercao); anterior e nil na insercao
+func registrarAlteracao(ctx context.Context, tx pgx.Tx, spec *model.EspecificacaoTecnica, anterior, novo []campoConteudo) error {
+	campos := make(map[string]model.AlteracaoCampo)
+	for i, c := range novo {
+		var antes *string
+		if anterior != nil {
+			antes = anterior[i].valor
+		}
+		if !textoIgual(antes, c.valor) {
+			campos[c.nome] = model.AlteracaoCampo{Anterior: antes, Novo: c.valor}
+		}
+	}
+
+	acao := model.AuditInsert
+	var hashAnterior *string
+	if anterior != nil {
+		acao = model.AuditUpdate
+		h := hashConteudo(anterior)
+		hashAnterior = &h
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO "ESPECIFICACAO_ALTERACAO" (
+			"EspecificacaoID", "CodigoAplicacao", "TipoFluido", "Condicao", "Fonte",
+			"Acao", "HashAnterior", "HashNovo", "Campos", "ScraperRunID"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, spec.ID, spec.CodigoAplicacao, spec.TipoFluido, spec.Condicao, spec.Fonte,
+		acao, hashAnterior, hashConteudo(novo), campos, spec.ScraperRunID)
+	if err != nil {
+		return fmt.Errorf("failed to log especificacao change: %w", err)
+	}
+	return nil
+}
+
+func textoIgual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/parser"
 )
 
 type EspecificacaoRepository struct {
@@ -19,6 +22,8 @@ func NewEspecificacaoRepository(db *pgxpool.Pool) *EspecificacaoRepository {
 
 // Insert insere uma especificacao tecnica e retorna o registro com ID e timestamps gerados
 func (r *EspecificacaoRepository) Insert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	preencherValoresNumericos(spec)
+
 	query := `
 		INSERT INTO "ESPECIFICACAO_TECNICA" (
 			"CodigoAplicacao",
@@ -30,8 +35,14 @@ func (r *EspecificacaoRepository) Insert(ctx context.Context, spec *model.Especi
 			"Observacao",
 			"Fonte",
 			"MotulVehicleTypeId",
-			"MatchConfidence"
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			"MatchConfidence",
+			"CapacidadeLitros",
+			"ViscosidadesSAE",
+			"Condicao",
+			"IntervaloTrocaKm",
+			"IntervaloTrocaMeses",
+			"ScraperRunID"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING "ID", "CriadoEm", "AtualizadoEm"
 	`
 
@@ -48,6 +59,12 @@ func (r *EspecificacaoRepository) Insert(ctx context.Context, spec *model.Especi
 		spec.Fonte,
 		spec.MotulVehicleTypeID,
 		spec.MatchConfidence,
+		spec.CapacidadeLitros,
+		spec.ViscosidadesSAE,
+		spec.Condicao,
+		spec.IntervaloTrocaKm,
+		spec.IntervaloTrocaMeses,
+		spec.ScraperRunID,
 	).Scan(&spec.ID, &spec.CriadoEm, &spec.AtualizadoEm)
 
 	if err != nil {
@@ -76,12 +93,19 @@ func (r *EspecificacaoRepository) InsertBatch(ctx context.Context, specs []model
 			"Observacao",
 			"Fonte",
 			"MotulVehicleTypeId",
-			"MatchConfidence"
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			"MatchConfidence",
+			"CapacidadeLitros",
+			"ViscosidadesSAE",
+			"Condicao",
+			"IntervaloTrocaKm",
+			"IntervaloTrocaMeses",
+			"ScraperRunID"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING "ID", "CriadoEm", "AtualizadoEm"
 	`
 
 	for i := range specs {
+		preencherValoresNumericos(&specs[i])
 		err := tx.QueryRow(
 			ctx,
 			query,
@@ -95,6 +119,12 @@ func (r *EspecificacaoRepository) InsertBatch(ctx context.Context, specs []model
 			specs[i].Fonte,
 			specs[i].MotulVehicleTypeID,
 			specs[i].MatchConfidence,
+			specs[i].CapacidadeLitros,
+			specs[i].ViscosidadesSAE,
+			specs[i].Condicao,
+			specs[i].IntervaloTrocaKm,
+			specs[i].IntervaloTrocaMeses,
+			specs[i].ScraperRunID,
 		).Scan(&specs[i].ID, &specs[i].CriadoEm, &specs[i].AtualizadoEm)
 
 		if err != nil {
@@ -109,7 +139,8 @@ func (r *EspecificacaoRepository) InsertBatch(ctx context.Context, specs []model
 	return nil
 }
 
-// ExistsForVehicle verifica se existem especificacoes para um determinado veiculo
+// ExistsForVehicle verifica se existem especificacoes para um determinado veiculo,
+// inclusive inativas: um veiculo com lote desativado aguarda revisao, nao e prioridade do scraper
 func (r *EspecificacaoRepository) ExistsForVehicle(ctx context.Context, codigoAplicacao int) (bool, error) {
 	query := `
 		SELECT EXISTS(
@@ -126,3 +157,572 @@ func (r *EspecificacaoRepository) ExistsForVehicle(ctx context.Context, codigoAp
 
 	return exists, nil
 }
+
+// LastUpdatedForVehicle retorna a data da atualizacao mais recente das especificacoes de um veiculo,
+// contando as regravacoes com conteudo igual (VerificadoEm) e, como ExistsForVehicle, as inativas
+// Retorna nil quando o veiculo ainda nao possui especificacoes
+func (r *EspecificacaoRepository) LastUpdatedForVehicle(ctx context.Context, codigoAplicacao int) (*time.Time, error) {
+	query := `
+		SELECT MAX(COALESCE("VerificadoEm", "AtualizadoEm"))
+		FROM "ESPECIFICACAO_TECNICA"
+		WHERE "CodigoAplicacao" = $1
+	`
+
+	var lastUpdated *time.Time
+	err := r.db.QueryRow(ctx, query, codigoAplicacao).Scan(&lastUpdated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last update: %w", err)
+	}
+
+	return lastUpdated, nil
+}
+
+// Upsert insere a especificacao ou, se ja existir uma para (CodigoAplicacao, TipoFluido, Condicao,
+// Fonte), atualiza os dados e renova o campo AtualizadoEm: cada fonte tem a sua linha. Se o conteudo
+// normalizado for igual ao gravado a linha nao e regravada, so VerificadoEm e MatchConfidence (um
+// match melhor do mesmo conteudo) mudam; spec.Gravacao diz o que aconteceu. Insercoes e
+// alteracoes ficam em ESPECIFICACAO_ALTERACAO, e uma viscosidade que contradiz a gravada por outra
+// fonte fica em SPEC_CONFLITOS. Uma linha desativada nao e tocada (GravacaoInativa): o lote fica como
+// foi desativado ate a revisao, e so Reativar volta a publica-lo.
+func (r *EspecificacaoRepository) Upsert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	preencherValoresNumericos(spec)
+	novo := camposConteudo(spec)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	atual, ativo, err := especificacaoAtual(ctx, tx, spec)
+	if err != nil {
+		return err
+	}
+	if atual != nil && !ativo {
+		spec.ID = atual.ID
+		spec.Gravacao = model.GravacaoInativa
+		return nil
+	}
+	var anterior []campoConteudo
+	if atual != nil {
+		anterior = camposConteudo(atual)
+	}
+
+	if atual != nil && hashConteudo(anterior) == hashConteudo(novo) {
+		err := tx.QueryRow(ctx, `
+			UPDATE "ESPECIFICACAO_TECNICA" SET "VerificadoEm" = NOW(), "MatchConfidence" = $2
+			WHERE "ID" = $1
+			RETURNING "ID", "CriadoEm", "AtualizadoEm"
+		`, atual.ID, spec.MatchConfidence).Scan(&spec.ID, &spec.CriadoEm, &spec.AtualizadoEm)
+		if err != nil {
+			return fmt.Errorf("failed to mark especificacao verified: %w", err)
+		}
+		spec.Gravacao = model.GravacaoInalterada
+		return tx.Commit(ctx)
+	}
+
+	query := `
+		INSERT INTO "ESPECIFICACAO_TECNICA" (
+			"CodigoAplicacao",
+			"TipoFluido",
+			"Viscosidade",
+			"Capacidade",
+			"Norma",
+			"Recomendacao",
+			"Observacao",
+			"Fonte",
+			"MotulVehicleTypeId",
+			"MatchConfidence",
+			"CapacidadeLitros",
+			"ViscosidadesSAE",
+			"Condicao",
+			"IntervaloTrocaKm",
+			"IntervaloTrocaMeses",
+			"ScraperRunID",
+			"VerificadoEm"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
+		ON CONFLICT ("CodigoAplicacao", "TipoFluido", "Condicao", especificacao_fonte("Fonte")) DO UPDATE SET
+			"Viscosidade" = EXCLUDED."Viscosidade",
+			"Capacidade" = EXCLUDED."Capacidade",
+			"Norma" = EXCLUDED."Norma",
+			"Recomendacao" = EXCLUDED."Recomendacao",
+			"Observacao" = EXCLUDED."Observacao",
+			"Fonte" = EXCLUDED."Fonte",
+			"MotulVehicleTypeId" = EXCLUDED."MotulVehicleTypeId",
+			"MatchConfidence" = EXCLUDED."MatchConfidence",
+			"CapacidadeLitros" = EXCLUDED."CapacidadeLitros",
+			"ViscosidadesSAE" = EXCLUDED."ViscosidadesSAE",
+			"IntervaloTrocaKm" = EXCLUDED."IntervaloTrocaKm",
+			"IntervaloTrocaMeses" = EXCLUDED."IntervaloTrocaMeses",
+			"ScraperRunID" = EXCLUDED."ScraperRunID",
+			"AtualizadoEm" = NOW(),
+			"VerificadoEm" = NOW()
+		RETURNING "ID", "CriadoEm", "AtualizadoEm"
+	`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		spec.CodigoAplicacao,
+		spec.TipoFluido,
+		spec.Viscosidade,
+		spec.Capacidade,
+		spec.Norma,
+		spec.Recomendacao,
+		spec.Observacao,
+		spec.Fonte,
+		spec.MotulVehicleTypeID,
+		spec.MatchConfidence,
+		spec.CapacidadeLitros,
+		spec.ViscosidadesSAE,
+		spec.Condicao,
+		spec.IntervaloTrocaKm,
+		spec.IntervaloTrocaMeses,
+		spec.ScraperRunID,
+	).Scan(&spec.ID, &spec.CriadoEm, &spec.AtualizadoEm)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert especificacao: %w", err)
+	}
+
+	spec.Gravacao = model.GravacaoAtualizada
+	if atual == nil {
+		spec.Gravacao = model.GravacaoInserida
+	}
+	if err := registrarAlteracao(ctx, tx, spec, anterior, novo); err != nil {
+		return err
+	}
+	if err := detectarConflitos(ctx, tx, spec); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListMissingNorma lista especificacoes da Motul sem Norma, com ID maior que afterID
+// Usado pelo backfill de normas; retorna no maximo limit registros ordenados por ID
+func (r *EspecificacaoRepository) ListMissingNorma(ctx context.Context, afterID, limit int) ([]model.EspecificacaoTecnica, error) {
+	query := `
+		SELECT "ID", "CodigoAplicacao", "TipoFluido", "Condicao", "MotulVehicleTypeId"
+		FROM "ESPECIFICACAO_TECNICA"
+		WHERE "Norma" IS NULL
+			AND "Ativo"
+			AND "Fonte" = 'motul'
+			AND "MotulVehicleTypeId" IS NOT NULL
+			AND "ID" > $1
+		ORDER BY "ID"
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list specs missing norma: %w", err)
+	}
+	defer rows.Close()
+
+	var specs []model.EspecificacaoTecnica
+	for rows.Next() {
+		var spec model.EspecificacaoTecnica
+		if err := rows.Scan(&spec.ID, &spec.CodigoAplicacao, &spec.TipoFluido, &spec.Condicao, &spec.MotulVehicleTypeID); err != nil {
+			return nil, fmt.Errorf("failed to scan spec: %w", err)
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, rows.Err()
+}
+
+// UpdateNorma preenche a Norma de uma especificacao e renova o campo AtualizadoEm
+func (r *EspecificacaoRepository) UpdateNorma(ctx context.Context, id int, norma string) error {
+	query := `
+		UPDATE "ESPECIFICACAO_TECNICA"
+		SET "Norma" = $2, "AtualizadoEm" = NOW()
+		WHERE "ID" = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, id, norma); err != nil {
+		return fmt.Errorf("failed to update norma: %w", err)
+	}
+
+	return nil
+}
+
+// LastUpdatedAt retorna quando alguma especificacao foi gravada pela ultima vez (nil se nao houver nenhuma)
+func (r *EspecificacaoRepository) LastUpdatedAt(ctx context.Context) (*time.Time, error) {
+	var lastUpdated *time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT MAX("AtualizadoEm") FROM "ESPECIFICACAO_TECNICA" WHERE "Ativo"
+	`).Scan(&lastUpdated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last especificacao update: %w", err)
+	}
+	return lastUpdated, nil
+}
+
+// CountCoveredVehicles conta as aplicacoes de veiculo com alguma especificacao
+// e o total de aplicacoes de veiculo
+func (r *EspecificacaoRepository) CountCoveredVehicles(ctx context.Context) (covered, total int, err error) {
+	err = r.db.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM "ESPECIFICACAO_TECNICA" e WHERE e."CodigoAplicacao" = a."CodigoAplicacao" AND e."Ativo"
+			)),
+			COUNT(*)
+		FROM "APLICACAO" a
+		JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+		WHERE f."FlagAplicacao" = 1
+	`).Scan(&covered, &total)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count covered vehicles: %w", err)
+	}
+	return covered, total, nil
+}
+
+// ListByAplicacao lista as especificacoes tecnicas de uma aplicacao
+func (r *EspecificacaoRepository) ListByAplicacao(ctx context.Context, codigoAplicacao int) ([]model.EspecificacaoTecnica, error) {
+	query := `
+		SELECT
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "CapacidadeLitros", "ViscosidadesSAE",
+			"Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses"
+		FROM "ESPECIFICACAO_TECNICA"
+		WHERE "CodigoAplicacao" = $1 AND "Ativo"
+		ORDER BY "TipoFluido", "Condicao", "Fonte"
+	`
+
+	rows, err := r.db.Query(ctx, query, codigoAplicacao)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list especificacoes: %w", err)
+	}
+	return scanEspecificacoes(rows)
+}
+
+// ListByAplicacaoAsOf lista as especificacoes de uma aplicacao como estavam no instante asOf,
+// a partir do historico mantido pelo trigger de ESPECIFICACAO_TECNICA
+func (r *EspecificacaoRepository) ListByAplicacaoAsOf(ctx context.Context, codigoAplicacao int, asOf time.Time) ([]model.EspecificacaoTecnica, error) {
+	// asOf vai como texto com fuso para o Postgres converter no fuso da sessao,
+	// o mesmo usado pelo NOW() que preencheu "ValidoDe"/"ValidoAte"
+	query := `
+		SELECT
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "CapacidadeLitros", "ViscosidadesSAE",
+			"Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses"
+		FROM "ESPECIFICACAO_TECNICA_HISTORICO"
+		WHERE "CodigoAplicacao" = $1
+		AND "Ativo"
+		AND "ValidoDe" <= $2::timestamptz
+		AND ("ValidoAte" IS NULL OR "ValidoAte" > $2::timestamptz)
+		ORDER BY "TipoFluido", "Condicao", "Fonte"
+	`
+
+	rows, err := r.db.Query(ctx, query, codigoAplicacao, asOf.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list especificacoes as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	return scanEspecificacoes(rows)
+}
+
+// EspecificacaoFilter contem os filtros opcionais da busca de especificacoes
+type EspecificacaoFilter struct {
+	TipoFluido    string   // Codigo canonico
+	Viscosidade   string   // Grau SAE canonico ("5W-30"), casado com qualquer grau da especificacao
+	CapacidadeMin *float64 // Litros, inclusive
+	CapacidadeMax *float64 // Litros, inclusive
+	Limit         int
+	Offset        int
+}
+
+// Buscar lista as especificacoes que atendem ao filtro, ordenadas por aplicacao
+// e tipo de fluido, e o total sem paginacao
+func (r *EspecificacaoRepository) Buscar(ctx context.Context, filter EspecificacaoFilter) ([]model.EspecificacaoTecnica, int, error) {
+	where := ` WHERE "Ativo"`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.TipoFluido != "" {
+		where += fmt.Sprintf(` AND "TipoFluido" = $%d`, argIndex)
+		args = append(args, filter.TipoFluido)
+		argIndex++
+	}
+
+	if filter.Viscosidade != "" {
+		where += fmt.Sprintf(` AND "ViscosidadesSAE" @> ARRAY[$%d]::text[]`, argIndex)
+		args = append(args, filter.Viscosidade)
+		argIndex++
+	}
+
+	if filter.CapacidadeMin != nil {
+		where += fmt.Sprintf(` AND "CapacidadeLitros" >= $%d`, argIndex)
+		args = append(args, *filter.CapacidadeMin)
+		argIndex++
+	}
+
+	if filter.CapacidadeMax != nil {
+		where += fmt.Sprintf(` AND "CapacidadeLitros" <= $%d`, argIndex)
+		args = append(args, *filter.CapacidadeMax)
+		argIndex++
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM "ESPECIFICACAO_TECNICA"`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count especificacoes: %w", err)
+	}
+
+	query := `
+		SELECT
+			"ID", "CodigoAplicacao", "TipoFluido", "Viscosidade", "Capacidade",
+			"Norma", "Recomendacao", "Observacao", "Fonte", "MotulVehicleTypeId",
+			"MatchConfidence", "CriadoEm", "AtualizadoEm", "CapacidadeLitros", "ViscosidadesSAE",
+			"Condicao", "IntervaloTrocaKm", "IntervaloTrocaMeses"
+		FROM "ESPECIFICACAO_TECNICA"` + where + fmt.Sprintf(`
+		ORDER BY "CodigoAplicacao", "TipoFluido", "Condicao", "Fonte"
+		LIMIT $%d OFFSET $%d`, argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search especificacoes: %w", err)
+	}
+	specs, err := scanEspecificacoes(rows)
+	return specs, total, err
+}
+
+// EspecificacaoLote seleciona as especificacoes de uma fonte gravadas
+// (AtualizadoEm) entre Desde e Ate, inclusive: um lote do scraper
+type EspecificacaoLote struct {
+	Fonte string
+	Desde time.Time
+	Ate   time.Time
+}
+
+// Desativar marca as especificacoes ativas do lote como inativas, sem apagar
+// as linhas; elas somem das leituras ate serem reativadas. Retorna quantas mudaram.
+func (r *EspecificacaoRepository) Desativar(ctx context.Context, lote EspecificacaoLote) (int64, error) {
+	return r.alterarAtivo(ctx, lote, false)
+}
+
+// Reativar volta a publicar as especificacoes inativas do lote. Retorna quantas mudaram.
+func (r *EspecificacaoRepository) Reativar(ctx context.Context, lote EspecificacaoLote) (int64, error) {
+	return r.alterarAtivo(ctx, lote, true)
+}
+
+// alterarAtivo nao mexe em AtualizadoEm, para o mesmo lote poder ser
+// reativado depois; o historico registra a mudanca pelo trigger
+func (r *EspecificacaoRepository) alterarAtivo(ctx context.Context, lote EspecificacaoLote, ativo bool) (int64, error) {
+	// Datas como texto com fuso, convertidas no fuso da sessao como em ListByAplicacaoAsOf
+	result, err := r.db.Exec(ctx, `
+		UPDATE "ESPECIFICACAO_TECNICA"
+		SET "Ativo" = $4, "DeletadoEm" = CASE WHEN $4 THEN NULL ELSE NOW() END
+		WHERE "Ativo" <> $4
+			AND LOWER("Fonte") = LOWER($1)
+			AND "AtualizadoEm" >= $2::timestamptz
+			AND "AtualizadoEm" <= $3::timestamptz
+	`, lote.Fonte, lote.Desde.Format(time.RFC3339Nano), lote.Ate.Format(time.RFC3339Nano), ativo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set especificacoes ativo=%t: %w", ativo, err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// RollbackRun desfaz o que uma execucao do scraper gravou: desativa as
+// especificacoes ativas cujo ultimo gravador foi a execucao ou, com remover,
+// volta as que ja existiam antes dela a versao anterior do historico e apaga
+// as que ela inseriu (o historico guarda a versao removida). Retorna quantas mudaram.
+func (r *EspecificacaoRepository) RollbackRun(ctx context.Context, runID int, remover bool) (int64, error) {
+	if !remover {
+		result, err := r.db.Exec(ctx, `
+			UPDATE "ESPECIFICACAO_TECNICA"
+			SET "Ativo" = FALSE, "DeletadoEm" = NOW()
+			WHERE "ScraperRunID" = $1 AND "Ativo"
+		`, runID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to roll back scraper run %d: %w", runID, err)
+		}
+		return result.RowsAffected(), nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A versao anterior e a ultima do historico antes da primeira gravada pela execucao
+	restauradas, err := tx.Exec(ctx, `
+		WITH primeira AS (
+			SELECT "ID", MIN("ValidoDe") AS "ValidoDe"
+			FROM "ESPECIFICACAO_TECNICA_HISTORICO"
+			WHERE "ScraperRunID" = $1
+			GROUP BY "ID"
+		), anterior AS (
+			SELECT DISTINCT ON (h."ID") h.*
+			FROM "ESPECIFICACAO_TECNICA_HISTORICO" h
+			JOIN primeira p ON p."ID" = h."ID" AND h."ValidoDe" < p."ValidoDe"
+			ORDER BY h."ID", h."ValidoDe" DESC, h."HistoricoID" DESC
+		)
+		UPDATE "ESPECIFICACAO_TECNICA" e SET
+			"Viscosidade" = a."Viscosidade",
+			"Capacidade" = a."Capacidade",
+			"Norma" = a."Norma",
+			"Recomendacao" = a."Recomendacao",
+			"Observacao" = a."Observacao",
+			"MotulVehicleTypeId" = a."MotulVehicleTypeId",
+			"MatchConfidence" = a."MatchConfidence",
+			"CapacidadeLitros" = a."CapacidadeLitros",
+			"ViscosidadesSAE" = a."ViscosidadesSAE",
+			"IntervaloTrocaKm" = a."IntervaloTrocaKm",
+			"IntervaloTrocaMeses" = a."IntervaloTrocaMeses",
+			"AtualizadoEm" = a."AtualizadoEm",
+			"Ativo" = a."Ativo",
+			"DeletadoEm" = a."DeletadoEm",
+			"ScraperRunID" = a."ScraperRunID",
+			"VerificadoEm" = NULL
+		FROM anterior a
+		WHERE e."ID" = a."ID" AND e."ScraperRunID" = $1
+	`, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore specs before scraper run %d: %w", runID, err)
+	}
+
+	removidas, err := tx.Exec(ctx, `DELETE FROM "ESPECIFICACAO_TECNICA" WHERE "ScraperRunID" = $1`, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll back scraper run %d: %w", runID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return restauradas.RowsAffected() + removidas.RowsAffected(), nil
+}
+
+// ListMatches lista os matches Motul (aplicacao e MotulVehicleTypeId) das especificacoes ativas
+// depois de (afterCodigo, afterTypeID), com a descricao e o motor da aplicacao, para o rescore de
+// confianca; retorna no maximo limit matches ordenados pela chave
+func (r *EspecificacaoRepository) ListMatches(ctx context.Context, afterCodigo int, afterTypeID string, limit int) ([]model.MatchEspecificacao, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT e."CodigoAplicacao", a."DescricaoAplicacao", COALESCE(a."ComplementoAplicacao3", ''),
+			e."MotulVehicleTypeId", MIN(e."MatchConfidence"), COUNT(*)
+		FROM "ESPECIFICACAO_TECNICA" e
+		JOIN "APLICACAO" a ON a."CodigoAplicacao" = e."CodigoAplicacao"
+		WHERE e."Ativo"
+			AND e."MotulVehicleTypeId" IS NOT NULL
+			AND (e."CodigoAplicacao", e."MotulVehicleTypeId") > ($1, $2)
+		GROUP BY e."CodigoAplicacao", a."DescricaoAplicacao", a."ComplementoAplicacao3", e."MotulVehicleTypeId"
+		ORDER BY e."CodigoAplicacao", e."MotulVehicleTypeId"
+		LIMIT $3
+	`, afterCodigo, afterTypeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spec matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []model.MatchEspecificacao
+	for rows.Next() {
+		var m model.MatchEspecificacao
+		if err := rows.Scan(&m.CodigoAplicacao, &m.DescricaoAplicacao, &m.Motor, &m.MotulVehicleTypeID, &m.MatchConfidence, &m.Especificacoes); err != nil {
+			return nil, fmt.Errorf("failed to scan spec match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// RebaixarConfianca reduz para confianca o MatchConfidence das especificacoes ativas de um match,
+// sem mexer em AtualizadoEm; as que ja tem confianca menor ficam como estao. Retorna quantas mudaram.
+func (r *EspecificacaoRepository) RebaixarConfianca(ctx context.Context, codigoAplicacao int, motulTypeID string, confianca float64) (int64, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE "ESPECIFICACAO_TECNICA"
+		SET "MatchConfidence" = $3
+		WHERE "CodigoAplicacao" = $1
+			AND "MotulVehicleTypeId" = $2
+			AND "Ativo"
+			AND ("MatchConfidence" IS NULL OR "MatchConfidence" > $3)
+	`, codigoAplicacao, motulTypeID, confianca)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lower match confidence: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// scanEspecificacoes le todas as linhas de uma consulta de especificacoes e fecha rows
+func scanEspecificacoes(rows pgx.Rows) ([]model.EspecificacaoTecnica, error) {
+	defer rows.Close()
+
+	specs := []model.EspecificacaoTecnica{}
+	for rows.Next() {
+		var spec model.EspecificacaoTecnica
+		if err := rows.Scan(
+			&spec.ID,
+			&spec.CodigoAplicacao,
+			&spec.TipoFluido,
+			&spec.Viscosidade,
+			&spec.Capacidade,
+			&spec.Norma,
+			&spec.Recomendacao,
+			&spec.Observacao,
+			&spec.Fonte,
+			&spec.MotulVehicleTypeID,
+			&spec.MatchConfidence,
+			&spec.CriadoEm,
+			&spec.AtualizadoEm,
+			&spec.CapacidadeLitros,
+			&spec.ViscosidadesSAE,
+			&spec.Condicao,
+			&spec.IntervaloTrocaKm,
+			&spec.IntervaloTrocaMeses,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan especificacao: %w", err)
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, rows.Err()
+}
+
+// CountByTipoFluido conta as especificacoes por tipo de fluido
+// Valores legados em portugues sao somados ao codigo canonico correspondente
+func (r *EspecificacaoRepository) CountByTipoFluido(ctx context.Context) (map[string]int, error) {
+	query := `
+		SELECT "TipoFluido", COUNT(*)
+		FROM "ESPECIFICACAO_TECNICA"
+		WHERE "Ativo"
+		GROUP BY "TipoFluido"
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count especificacoes: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tipo string
+		var total int
+		if err := rows.Scan(&tipo, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan count: %w", err)
+		}
+		counts[model.TipoFluidoCode(tipo)] += total
+	}
+
+	return counts, rows.Err()
+}
+
+// preencherValoresNumericos calcula CapacidadeLitros e ViscosidadesSAE a partir
+// do texto de Capacidade e Viscosidade, para todo caminho de gravacao
+func preencherValoresNumericos(spec *model.EspecificacaoTecnica) {
+	var capacidade, viscosidade string
+	if spec.Capacidade != nil {
+		capacidade = *spec.Capacidade
+	}
+	if spec.Viscosidade != nil {
+		viscosidade = *spec.Viscosidade
+	}
+	spec.CapacidadeLitros, spec.ViscosidadesSAE = parser.NumericSpecValues(capacidade, viscosidade)
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/database"
+	"wega-catalog-api/internal/export"
+	"wega-catalog-api/internal/model"
+)
+
+// exportDataset e a consulta de um conjunto de dados do export. Todas as
+// colunas sao texto, na ordem do cabecalho.
+type exportDataset struct {
+	cabecalho []string
+	query     string
+	// Condicoes dos filtros de fabricante e tipo, com %d no lugar do parametro
+	filtroFabricante string
+	filtroTipo       string
+	orderBy          string
+}
+
+var exportDatasets = map[string]exportDataset{
+	model.ExportProdutos: {
+		cabecalho: []string{"codigo_produto", "codigo_wega", "descricao", "tipo", "preco", "foto"},
+		query: `
+			SELECT
+				p."CodigoProduto"::text,
+				p."NumeroProduto",
+				COALESCE(p."DescricaoProduto", ''),
+				sg."DescricaoSubGrupoProduto",
+				COALESCE(p."PrecoProduto"::text, ''),
+				COALESCE(p."ArquivoFotoProduto", '')
+			FROM "PRODUTO" p
+			JOIN "SUBGRUPOPRODUTO" sg ON p."CodigoSubGrupoProduto" = sg."CodigoSubGrupoProduto"
+			WHERE TRUE`,
+		// Produtos com alguma aplicacao da montadora
+		filtroFabricante: `EXISTS (
+				SELECT 1 FROM "PRODUTO_APLICACAO" pa
+				JOIN "APLICACAO" a ON a."CodigoAplicacao" = pa."CodigoAplicacao"
+				WHERE pa."CodigoProduto" = p."CodigoProduto" AND a."CodigoFabricante" = $%d
+			)`,
+		filtroTipo: `p."CodigoSubGrupoProduto" = $%d`,
+		orderBy:    `p."NumeroProduto"`,
+	},
+	model.ExportAplicacoes: {
+		cabecalho: []string{"codigo_aplicacao", "marca", "descricao_aplicacao", "motor", "periodo", "codigo_produto", "codigo_wega", "tipo"},
+		query: `
+			SELECT
+				a."CodigoAplicacao"::text,
+				f."DescricaoFabricante",
+				a."DescricaoAplicacao",
+				COALESCE(a."ComplementoAplicacao3", ''),
+				COALESCE(a."ComplementoAplicacao2", ''),
+				p."CodigoProduto"::text,
+				p."NumeroProduto",
+				sg."DescricaoSubGrupoProduto"
+			FROM "PRODUTO_APLICACAO" pa
+			JOIN "APLICACAO" a ON a."CodigoAplicacao" = pa."CodigoAplicacao"
+			JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+			JOIN "PRODUTO" p ON pa."CodigoProduto" = p."CodigoProduto"
+			JOIN "SUBGRUPOPRODUTO" sg ON p."CodigoSubGrupoProduto" = sg."CodigoSubGrupoProduto"
+			WHERE f."FlagAplicacao" = 1`,
+		filtroFabricante: `a."CodigoFabricante" = $%d`,
+		filtroTipo:       `p."CodigoSubGrupoProduto" = $%d`,
+		orderBy:          `f."DescricaoFabricante", a."DescricaoAplicacao", a."CodigoAplicacao", p."NumeroProduto"`,
+	},
+	model.ExportReferencias: {
+		cabecalho: []string{"marca_concorrente", "codigo_concorrente", "codigo_produto", "codigo_wega", "tipo"},
+		query: `
+			SELECT
+				f."DescricaoFabricante",
+				rc."NumeroProdutoPesq",
+				p."CodigoProduto"::text,
+				p."NumeroProduto",
+				sg."DescricaoSubGrupoProduto"
+			FROM "REFERENCIACRUZADA" rc
+			JOIN "PRODUTO" p ON rc."CodigoProduto" = p."CodigoProduto"
+			JOIN "FABRICANTE" f ON rc."CodigoFabricante" = f."CodigoFabricante"
+			JOIN "SUBGRUPOPRODUTO" sg ON p."CodigoSubGrupoProduto" = sg."CodigoSubGrupoProduto"
+			WHERE TRUE`,
+		filtroFabricante: `rc."CodigoFabricante" = $%d`,
+		filtroTipo:       `p."CodigoSubGrupoProduto" = $%d`,
+		orderBy:          `f."DescricaoFabricante", rc."NumeroProdutoPesq", p."NumeroProduto"`,
+	},
+}
+
+type ExportRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExportRepo(db *pgxpool.Pool) *ExportRepo {
+	return &ExportRepo{db: db}
+}
+
+// Exportar escreve o cabecalho e as linhas do conjunto de dados em out,
+// uma linha por vez conforme chegam do banco, e retorna quantas linhas
+// foram escritas (sem o cabecalho)
+func (r *ExportRepo) Exportar(ctx context.Context, dataset string, filter model.ExportFilter, out export.RowWriter) (int64, error) {
+	ds, ok := exportDatasets[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown export dataset %q", dataset)
+	}
+
+	query := ds.query
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Fabricante > 0 {
+		query += ` AND ` + fmt.Sprintf(ds.filtroFabricante, argIndex)
+		args = append(args, filter.Fabricante)
+		argIndex++
+	}
+
+	if filter.Tipo > 0 {
+		query += ` AND ` + fmt.Sprintf(ds.filtroTipo, argIndex)
+		args = append(args, filter.Tipo)
+		argIndex++
+	}
+
+	query += ` ORDER BY ` + ds.orderBy
+
+	// O streaming dura o export inteiro: vale o EXPORT_TIMEOUT, nao o prazo por consulta
+	rows, err := r.db.Query(database.WithQueryTimeout(ctx, 0), query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if err := out.WriteRow(ds.cabecalho); err != nil {
+		return 0, err
+	}
+
+	linha := make([]string, len(ds.cabecalho))
+	dest := make([]interface{}, len(linha))
+	for i := range linha {
+		dest[i] = &linha[i]
+	}
+
+	var total int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return total, err
+		}
+		if err := out.WriteRow(linha); err != nil {
+			return total, err
+		}
+		total++
+	}
+
+	return total, rows.Err()
+}
+
+// aplicacoesCompletasQuery traz cada aplicacao com filtros e especificacoes
+// agregados em JSON, com as chaves das tags json de model.Produto e
+// model.EspecificacaoTecnica, para o banco montar cada linha do export em uma
+// so consulta
+var aplicacoesCompletasQuery = `
+	SELECT
+		a."CodigoAplicacao",
+		a."CodigoFabricante",
+		f."DescricaoFabricante",
+		a."DescricaoAplicacao",
+		COALESCE(a."ComplementoAplicacao3", ''),
+		COALESCE(a."ComplementoAplicacao2", ''),
+		` + anoDesconhecido + `,
+		filtros.lista,
+		especificacoes.lista
+	FROM "APLICACAO" a
+	JOIN "FABRICANTE" f ON a."CodigoFabricante" = f."CodigoFabricante"
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(json_agg(json_build_object(
+			'codigo_produto', p."CodigoProduto",
+			'codigo_wega', p."NumeroProduto",
+			'descricao', COALESCE(p."DescricaoProduto", ''),
+			'tipo', sg."DescricaoSubGrupoProduto",
+			'foto_url', p."ArquivoFotoProduto",
+			'preco', p."PrecoProduto"
+		) ORDER BY sg."DescricaoSubGrupoProduto", p."NumeroProduto"), '[]') AS lista
+		FROM "PRODUTO_APLICACAO" pa
+		JOIN "PRODUTO" p ON pa."CodigoProduto" = p."CodigoProduto"
+		JOIN "SUBGRUPOPRODUTO" sg ON p."CodigoSubGrupoProduto" = sg."CodigoSubGrupoProduto"
+		WHERE pa."CodigoAplicacao" = a."CodigoAplicacao"
+	) filtros
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(json_agg(json_build_object(
+			'id', e."ID",
+			'codigo_aplicacao', e."CodigoAplicacao",
+			'tipo_fluido', e."TipoFluido",
+			'condicao', e."Condicao",
+			'viscosidade', e."Viscosidade",
+			'capacidade', e."Capacidade",
+			'norma', e."Norma",
+			'recomendacao', e."Recomendacao",
+			'observacao', e."Observacao",
+			'fonte', e."Fonte",
+			'motul_vehicle_type_id', e."MotulVehicleTypeId",
+			'match_confidence', e."MatchConfidence",
+			'criado_em', e."CriadoEm",
+			'atualizado_em', e."AtualizadoEm",
+			'capacidade_litros', e."CapacidadeLitros",
+			'viscosidades_sae', e."ViscosidadesSAE",
+			'intervalo_troca_km', e."IntervaloTrocaKm",
+			'intervalo_troca_meses', e."IntervaloTrocaMeses"
+		) ORDER BY e."TipoFluido", e."Condicao", e."Fonte"), '[]') AS lista
+		FROM "ESPECIFICACAO_TECNICA" e
+		WHERE e."CodigoAplicacao" = a."CodigoAplicacao" AND e."Ativo"
+	) especificacoes
+	WHERE f."FlagAplicacao" = 1`
+
+// ExportarAplicacoesCompletas chama fn para cada aplicacao de veiculo, com
+// filtros e especificacoes, em ordem de codigo e conforme as linhas chegam do
+// banco, e retorna quantas foram exportadas. fabricante > 0 restringe a uma
+// montadora.
+func (r *ExportRepo) ExportarAplicacoesCompletas(ctx context.Context, fabricante int, fn func(model.AplicacaoCompleta) error) (int64, error) {
+	query := aplicacoesCompletasQuery
+	args := []interface{}{}
+	if fabricante > 0 {
+		query += ` AND a."CodigoFabricante" = $1`
+		args = append(args, fabricante)
+	}
+	query += ` ORDER BY a."CodigoAplicacao"`
+
+	rows, err := r.db.Query(database.WithQueryTimeout(ctx, 0), query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total int64
+	for rows.Next() {
+		var a model.AplicacaoCompleta
+		if err := rows.Scan(
+			&a.CodigoAplicacao, &a.CodigoFabricante, &a.Marca, &a.DescricaoAplicacao,
+			&a.Motor, &a.Periodo, &a.AnoDesconhecido, &a.Filtros, &a.Especificacoes,
+		); err != nil {
+			return total, err
+		}
+		if err := fn(a); err != nil {
+			return total, err
+		}
+		total++
+	}
+
+	return total, rows.Err()
+}
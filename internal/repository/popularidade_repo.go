@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+const (
+	// popularidadeMeiaVidaDias e o tempo para o score de uma aplicacao cair pela metade sem consultas
+	popularidadeMeiaVidaDias = 30
+
+	// Peso de cada consulta no score. Consultas sem especificacao pesam mais:
+	// sao demanda que o scraper ainda nao atende.
+	pesoConsulta                 = 1.0
+	pesoConsultaSemEspecificacao = 3.0
+)
+
+// scoreAtual e a expressao SQL do score com decaimento ate agora
+var scoreAtual = fmt.Sprintf(
+	`p."Score" * POWER(0.5, EXTRACT(EPOCH FROM NOW() - p."UltimaConsulta") / %d)`,
+	popularidadeMeiaVidaDias*24*60*60,
+)
+
+// PopularidadeRepo mantem o score de popularidade por aplicacao, alimentado pelas consultas da API
+type PopularidadeRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPopularidadeRepo(pool *pgxpool.Pool) *PopularidadeRepo {
+	return &PopularidadeRepo{pool: pool}
+}
+
+// Record registra uma consulta a aplicacao, aplicando o decaimento ao score anterior
+func (r *PopularidadeRepo) Record(ctx context.Context, codigoAplicacao int, semEspecificacao bool) error {
+	peso, miss := pesoConsulta, 0
+	if semEspecificacao {
+		peso, miss = pesoConsultaSemEspecificacao, 1
+	}
+
+	query := `
+		INSERT INTO "APLICACAO_POPULARIDADE" AS p (
+			"CodigoAplicacao", "Consultas", "ConsultasSemEspecificacao", "Score", "UltimaConsulta"
+		) VALUES ($1, 1, $2, $3, NOW())
+		ON CONFLICT ("CodigoAplicacao") DO UPDATE SET
+			"Consultas" = p."Consultas" + 1,
+			"ConsultasSemEspecificacao" = p."ConsultasSemEspecificacao" + $2,
+			"Score" = ` + scoreAtual + ` + $3,
+			"UltimaConsulta" = NOW()
+	`
+
+	if _, err := r.pool.Exec(ctx, query, codigoAplicacao, miss, peso); err != nil {
+		return fmt.Errorf("failed to record popularity: %w", err)
+	}
+	return nil
+}
+
+// PopularidadeFilter holds optional filters for the popularity report
+type PopularidadeFilter struct {
+	SemEspecificacao bool // Somente aplicacoes ainda sem especificacao
+	Limit            int
+	Offset           int
+}
+
+// List retorna as aplicacoes mais consultadas (score atual decrescente) e o total
+func (r *PopularidadeRepo) List(ctx context.Context, filter PopularidadeFilter) ([]model.PopularidadeAplicacao, int, error) {
+	from := `
+		FROM "APLICACAO_POPULARIDADE" p
+		JOIN "APLICACAO" a ON a."CodigoAplicacao" = p."CodigoAplicacao"
+		JOIN "FABRICANTE" f ON f."CodigoFabricante" = a."CodigoFabricante"`
+	temEspecificacao := `EXISTS (
+			SELECT 1 FROM "ESPECIFICACAO_TECNICA" e
+			WHERE e."CodigoAplicacao" = p."CodigoAplicacao" AND e."Ativo"
+		)`
+
+	where := ``
+	if filter.SemEspecificacao {
+		where = ` WHERE NOT ` + temEspecificacao
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*)`+from+where).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count popularity: %w", err)
+	}
+
+	query := `
+		SELECT
+			p."CodigoAplicacao", f."DescricaoFabricante", a."DescricaoAplicacao",
+			p."Consultas", p."ConsultasSemEspecificacao",
+			` + scoreAtual + ` AS score,
+			p."UltimaConsulta",
+			` + temEspecificacao + from + where + `
+		ORDER BY score DESC, p."CodigoAplicacao"
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.pool.Query(ctx, query, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list popularity: %w", err)
+	}
+	defer rows.Close()
+
+	aplicacoes := []model.PopularidadeAplicacao{}
+	for rows.Next() {
+		var p model.PopularidadeAplicacao
+		if err := rows.Scan(
+			&p.CodigoAplicacao, &p.Marca, &p.DescricaoAplicacao,
+			&p.Consultas, &p.ConsultasSemEspecificacao,
+			&p.Score, &p.UltimaConsulta, &p.TemEspecificacao,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan popularity row: %w", err)
+		}
+		aplicacoes = append(aplicacoes, p)
+	}
+
+	return aplicacoes, total, rows.Err()
+}
+
+// Scores retorna o score atual das aplicacoes informadas (ausentes nao constam no mapa)
+func (r *PopularidadeRepo) Scores(ctx context.Context, codigosAplicacao []int) (map[int]float64, error) {
+	query := `
+		SELECT p."CodigoAplicacao", ` + scoreAtual + `
+		FROM "APLICACAO_POPULARIDADE" p
+		WHERE p."CodigoAplicacao" = ANY($1)`
+
+	return r.queryScores(ctx, query, codigosAplicacao)
+}
+
+// AllScores returns the current score of every application that was ever looked up
+func (r *PopularidadeRepo) AllScores(ctx context.Context) (map[int]float64, error) {
+	query := `
+		SELECT p."CodigoAplicacao", ` + scoreAtual + `
+		FROM "APLICACAO_POPULARIDADE" p`
+
+	return r.queryScores(ctx, query)
+}
+
+func (r *PopularidadeRepo) queryScores(ctx context.Context, query string, args ...any) (map[int]float64, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query popularity scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[int]float64)
+	for rows.Next() {
+		var id int
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan popularity score: %w", err)
+		}
+		scores[id] = score
+	}
+
+	return scores, rows.Err()
+}
@@ -2,17 +2,23 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 
+	"wega-catalog-api/internal/database"
 	"wega-catalog-api/internal/model"
 )
 
 type ProdutoRepo struct {
-	db *pgxpool.Pool
+	db database.Querier
 }
 
-func NewProdutoRepo(db *pgxpool.Pool) *ProdutoRepo {
+// NewProdutoRepo recebe o pool primario ou um database.ReadPool (replica de leitura)
+func NewProdutoRepo(db database.Querier) *ProdutoRepo {
 	return &ProdutoRepo{db: db}
 }
 
@@ -29,7 +35,8 @@ func (r *ProdutoRepo) BuscarPorAplicacoes(ctx context.Context, codigosAplicacao
 			COALESCE(p."DescricaoProduto", '') as descricao,
 			sg."DescricaoSubGrupoProduto" as tipo,
 			p."ArquivoFotoProduto" as foto,
-			p."PrecoProduto" as preco
+			p."PrecoProduto" as preco,
+			p."Disponivel" as disponivel
 		FROM "PRODUTO_APLICACAO" pa
 		JOIN "PRODUTO" p ON pa."CodigoProduto" = p."CodigoProduto"
 		JOIN "SUBGRUPOPRODUTO" sg ON p."CodigoSubGrupoProduto" = sg."CodigoSubGrupoProduto"
@@ -46,7 +53,7 @@ func (r *ProdutoRepo) BuscarPorAplicacoes(ctx context.Context, codigosAplicacao
 	var produtos []model.Produto
 	for rows.Next() {
 		var p model.Produto
-		if err := rows.Scan(&p.CodigoProduto, &p.CodigoWega, &p.Descricao, &p.Tipo, &p.FotoURL, &p.Preco); err != nil {
+		if err := rows.Scan(&p.CodigoProduto, &p.CodigoWega, &p.Descricao, &p.Tipo, &p.FotoURL, &p.Preco, &p.Disponivel); err != nil {
 			return nil, err
 		}
 		produtos = append(produtos, p)
@@ -85,3 +92,69 @@ func (r *ProdutoRepo) ListarTiposFiltro(ctx context.Context) ([]model.TipoFiltro
 
 	return tipos, rows.Err()
 }
+
+// HistoricoPrecos retorna o preco atual e as mudancas de preco dos produtos
+// de um codigo Wega no periodo (desde e ate nil nao limitam). Retorna nil se
+// o codigo nao existe.
+func (r *ProdutoRepo) HistoricoPrecos(ctx context.Context, codigoWega string, desde, ate *time.Time) (*model.HistoricoPrecosResponse, error) {
+	codigo := strings.ToUpper(strings.TrimSpace(codigoWega))
+
+	resp := &model.HistoricoPrecosResponse{Desde: desde, Ate: ate, Historico: []model.PrecoHistorico{}}
+	err := r.db.QueryRow(ctx, `
+		SELECT TRIM("NumeroProduto"), "PrecoProduto"::float8
+		FROM "PRODUTO"
+		WHERE UPPER(TRIM("NumeroProduto")) = $1
+		ORDER BY "CodigoProduto"
+		LIMIT 1
+	`, codigo).Scan(&resp.CodigoWega, &resp.PrecoAtual)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT h."CodigoProduto", h."PrecoAnterior"::float8, h."Preco"::float8, h."AlteradoEm", h."SincronizacaoID"
+		FROM "PRODUTO_PRECO_HISTORICO" h
+		JOIN "PRODUTO" p ON p."CodigoProduto" = h."CodigoProduto"
+		WHERE UPPER(TRIM(p."NumeroProduto")) = $1
+			AND ($2::timestamptz IS NULL OR h."AlteradoEm" >= $2)
+			AND ($3::timestamptz IS NULL OR h."AlteradoEm" <= $3)
+		ORDER BY h."AlteradoEm", h."ID"
+	`, codigo, desde, ate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h model.PrecoHistorico
+		if err := rows.Scan(&h.CodigoProduto, &h.PrecoAnterior, &h.Preco, &h.AlteradoEm, &h.SincronizacaoID); err != nil {
+			return nil, err
+		}
+		resp.Historico = append(resp.Historico, model.NovoPrecoHistorico(h))
+	}
+	resp.Total = len(resp.Historico)
+	return resp, rows.Err()
+}
+
+// ArquivoFoto retorna o ArquivoFotoProduto de um codigo Wega, preferindo um
+// produto com foto quando o codigo tem mais de um; encontrado e false se o
+// codigo nao existe e o arquivo vem vazio se o produto nao tem foto
+func (r *ProdutoRepo) ArquivoFoto(ctx context.Context, codigoWega string) (arquivo string, encontrado bool, err error) {
+	err = r.db.QueryRow(ctx, `
+		SELECT COALESCE(TRIM("ArquivoFotoProduto"), '')
+		FROM "PRODUTO"
+		WHERE UPPER(TRIM("NumeroProduto")) = $1
+		ORDER BY COALESCE(TRIM("ArquivoFotoProduto"), '') = '', "CodigoProduto"
+		LIMIT 1
+	`, strings.ToUpper(strings.TrimSpace(codigoWega))).Scan(&arquivo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return arquivo, true, nil
+}
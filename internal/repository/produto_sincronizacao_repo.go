@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// ProdutoSincronizacaoRepo aplica as sincronizacoes de preco e estoque aos
+// produtos, registrando cada campo alterado e o historico de precos
+type ProdutoSincronizacaoRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewProdutoSincronizacaoRepo(pool *pgxpool.Pool) *ProdutoSincronizacaoRepo {
+	return &ProdutoSincronizacaoRepo{pool: pool}
+}
+
+// estadoProduto e o preco e o estoque de um produto antes da sincronizacao
+type estadoProduto struct {
+	codigo     int
+	preco      *float64
+	disponivel *bool
+	estoque    *int
+}
+
+// Sincronizar aplica as atualizacoes em uma transacao: so os campos que mudam
+// sao gravados, cada um com uma linha de auditoria. As mudancas de preco
+// entram no historico pelo trigger de PRODUTO (migration 000005), ligadas a
+// sincronizacao. Codigos repetidos valem pela ultima linha; codigos sem
+// produto ficam em NaoEncontrados.
+func (r *ProdutoSincronizacaoRepo) Sincronizar(ctx context.Context, origem string, atualizacoes []model.AtualizacaoProduto) (*model.SincronizacaoProdutos, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin product sync: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	s := &model.SincronizacaoProdutos{Origem: origem, Linhas: len(atualizacoes), NaoEncontrados: []string{}}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO "PRODUTO_SINCRONIZACAO" ("Origem", "Linhas") VALUES ($1, $2)
+		RETURNING "ID", "IniciadoEm"
+	`, origem, len(atualizacoes)).Scan(&s.ID, &s.IniciadoEm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start product sync: %w", err)
+	}
+	// Lido pelo trigger do historico de precos, so nesta transacao
+	if _, err := tx.Exec(ctx, `SELECT set_config('wega.sincronizacao_id', $1, true)`, strconv.Itoa(s.ID)); err != nil {
+		return nil, fmt.Errorf("failed to start product sync: %w", err)
+	}
+
+	// Uma atualizacao por codigo, na ordem do arquivo
+	porCodigo := make(map[string]model.AtualizacaoProduto, len(atualizacoes))
+	var codigos []string
+	for _, a := range atualizacoes {
+		codigo := strings.ToUpper(strings.TrimSpace(a.CodigoWega))
+		if _, ok := porCodigo[codigo]; !ok {
+			codigos = append(codigos, codigo)
+		}
+		porCodigo[codigo] = a
+	}
+
+	atuais, err := estadosProdutos(ctx, tx, codigos)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &pgx.Batch{}
+	var comEstoque []int
+	for _, codigo := range codigos {
+		produtos, ok := atuais[codigo]
+		if !ok {
+			s.NaoEncontrados = append(s.NaoEncontrados, porCodigo[codigo].CodigoWega)
+			continue
+		}
+
+		a := porCodigo[codigo]
+		if a.Preco != nil {
+			preco := math.Round(*a.Preco*100) / 100
+			a.Preco = &preco
+		}
+		for _, p := range produtos {
+			if a.Disponivel != nil || a.Estoque != nil {
+				comEstoque = append(comEstoque, p.codigo)
+			}
+			alteracoes := p.alteracoes(a)
+			if len(alteracoes) == 0 {
+				continue
+			}
+			s.Alterados++
+
+			batch.Queue(`
+				UPDATE "PRODUTO" SET
+					"PrecoProduto" = COALESCE($2::numeric, "PrecoProduto"),
+					"Disponivel" = COALESCE($3::boolean, "Disponivel"),
+					"Estoque" = COALESCE($4::integer, "Estoque")
+				WHERE "CodigoProduto" = $1
+			`, p.codigo, a.Preco, a.Disponivel, a.Estoque)
+			for _, alt := range alteracoes {
+				batch.Queue(`
+					INSERT INTO "PRODUTO_SINCRONIZACAO_ALTERACAO"
+						("SincronizacaoID", "CodigoProduto", "Campo", "ValorAnterior", "ValorNovo")
+					VALUES ($1, $2, $3, $4, $5)
+				`, s.ID, p.codigo, alt.Campo, alt.ValorAnterior, alt.ValorNovo)
+			}
+		}
+	}
+
+	// Estoque confirmado, mesmo sem mudanca, fica com a data da sincronizacao
+	if len(comEstoque) > 0 {
+		batch.Queue(`UPDATE "PRODUTO" SET "EstoqueAtualizadoEm" = NOW() WHERE "CodigoProduto" = ANY($1)`, comEstoque)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, fmt.Errorf("failed to apply product sync: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE "PRODUTO_SINCRONIZACAO" SET "FinalizadoEm" = NOW(), "Alterados" = $2, "NaoEncontrados" = $3
+		WHERE "ID" = $1
+		RETURNING "FinalizadoEm"
+	`, s.ID, s.Alterados, s.NaoEncontrados).Scan(&s.FinalizadoEm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish product sync: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit product sync: %w", err)
+	}
+	return s, nil
+}
+
+// estadosProdutos trava e retorna o preco e o estoque dos produtos de cada
+// codigo (em maiusculas); um codigo pode ter mais de um produto
+func estadosProdutos(ctx context.Context, tx pgx.Tx, codigos []string) (map[string][]estadoProduto, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT "CodigoProduto", UPPER(TRIM("NumeroProduto")), "PrecoProduto"::float8, "Disponivel", "Estoque"
+		FROM "PRODUTO"
+		WHERE UPPER(TRIM("NumeroProduto")) = ANY($1)
+		FOR UPDATE
+	`, codigos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read products: %w", err)
+	}
+	defer rows.Close()
+
+	estados := make(map[string][]estadoProduto)
+	for rows.Next() {
+		var codigo string
+		var p estadoProduto
+		if err := rows.Scan(&p.codigo, &codigo, &p.preco, &p.disponivel, &p.estoque); err != nil {
+			return nil, err
+		}
+		estados[codigo] = append(estados[codigo], p)
+	}
+	return estados, rows.Err()
+}
+
+// alteracoes compara o produto com a atualizacao e retorna os campos que mudam
+func (p estadoProduto) alteracoes(a model.AtualizacaoProduto) []model.AlteracaoProduto {
+	var alteracoes []model.AlteracaoProduto
+	if a.Preco != nil && (p.preco == nil || *p.preco != *a.Preco) {
+		alteracoes = append(alteracoes, model.AlteracaoProduto{
+			Campo:         model.CampoPreco,
+			ValorAnterior: textoValor(p.preco, formatarPreco),
+			ValorNovo:     textoValor(a.Preco, formatarPreco),
+		})
+	}
+	if a.Disponivel != nil && (p.disponivel == nil || *p.disponivel != *a.Disponivel) {
+		alteracoes = append(alteracoes, model.AlteracaoProduto{
+			Campo:         model.CampoDisponivel,
+			ValorAnterior: textoValor(p.disponivel, strconv.FormatBool),
+			ValorNovo:     textoValor(a.Disponivel, strconv.FormatBool),
+		})
+	}
+	if a.Estoque != nil && (p.estoque == nil || *p.estoque != *a.Estoque) {
+		alteracoes = append(alteracoes, model.AlteracaoProduto{
+			Campo:         model.CampoEstoque,
+			ValorAnterior: textoValor(p.estoque, strconv.Itoa),
+			ValorNovo:     textoValor(a.Estoque, strconv.Itoa),
+		})
+	}
+	return alteracoes
+}
+
+func formatarPreco(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// textoValor formata um valor opcional para a auditoria (nil continua nil)
+func textoValor[T any](v *T, format func(T) string) *string {
+	if v == nil {
+		return nil
+	}
+	s := format(*v)
+	return &s
+}
+
+// RegistrarFalha grava uma sincronizacao que nao pode ser aplicada (ERP fora
+// do ar, arquivo invalido), para aparecer no historico
+func (r *ProdutoSincronizacaoRepo) RegistrarFalha(ctx context.Context, origem string, erro string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO "PRODUTO_SINCRONIZACAO" ("Origem", "FinalizadoEm", "Erro") VALUES ($1, NOW(), $2)
+	`, origem, erro)
+	if err != nil {
+		return fmt.Errorf("failed to record product sync failure: %w", err)
+	}
+	return nil
+}
+
+// sincronizacaoColumns lista as colunas lidas por scanSincronizacao
+const sincronizacaoColumns = `
+	"ID", "Origem", "IniciadoEm", "FinalizadoEm", "Linhas", "Alterados", "NaoEncontrados", COALESCE("Erro", '')`
+
+func scanSincronizacao(row pgx.Row) (model.SincronizacaoProdutos, error) {
+	var s model.SincronizacaoProdutos
+	err := row.Scan(&s.ID, &s.Origem, &s.IniciadoEm, &s.FinalizadoEm, &s.Linhas, &s.Alterados, &s.NaoEncontrados, &s.Erro)
+	return s, err
+}
+
+// Listar retorna as sincronizacoes mais recentes primeiro, sem as alteracoes
+func (r *ProdutoSincronizacaoRepo) Listar(ctx context.Context, limit int) ([]model.SincronizacaoProdutos, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+sincronizacaoColumns+`
+		FROM "PRODUTO_SINCRONIZACAO"
+		ORDER BY "IniciadoEm" DESC, "ID" DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sincronizacoes := []model.SincronizacaoProdutos{}
+	for rows.Next() {
+		s, err := scanSincronizacao(rows)
+		if err != nil {
+			return nil, err
+		}
+		sincronizacoes = append(sincronizacoes, s)
+	}
+	return sincronizacoes, rows.Err()
+}
+
+// BuscarPorID retorna uma sincronizacao com os campos alterados (nil se nao existe)
+func (r *ProdutoSincronizacaoRepo) BuscarPorID(ctx context.Context, id int) (*model.SincronizacaoProdutos, error) {
+	s, err := scanSincronizacao(r.pool.QueryRow(ctx, `
+		SELECT `+sincronizacaoColumns+` FROM "PRODUTO_SINCRONIZACAO" WHERE "ID" = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT a."CodigoProduto", COALESCE(p."NumeroProduto", ''), a."Campo", a."ValorAnterior", a."ValorNovo"
+		FROM "PRODUTO_SINCRONIZACAO_ALTERACAO" a
+		LEFT JOIN "PRODUTO" p ON p."CodigoProduto" = a."CodigoProduto"
+		WHERE a."SincronizacaoID" = $1
+		ORDER BY p."NumeroProduto", a."Campo"
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s.Alteracoes = []model.AlteracaoProduto{}
+	for rows.Next() {
+		var a model.AlteracaoProduto
+		if err := rows.Scan(&a.CodigoProduto, &a.CodigoWega, &a.Campo, &a.ValorAnterior, &a.ValorNovo); err != nil {
+			return nil, err
+		}
+		s.Alteracoes = append(s.Alteracoes, a)
+	}
+	return &s, rows.Err()
+}
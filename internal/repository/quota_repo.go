@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+const quotaColumns = `
+	"ID", "Nome", "ChavePrefixo", "RequisicoesDia", "Burst", "Ativo",
+	"UsoDia", "CriadoEm", "AtualizadoEm"`
+
+// QuotaRepo mantem as cotas por chave de API e o contador de uso diario
+type QuotaRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewQuotaRepo(pool *pgxpool.Pool) *QuotaRepo {
+	return &QuotaRepo{pool: pool}
+}
+
+// List retorna todas as cotas, da mais antiga para a mais nova
+func (r *QuotaRepo) List(ctx context.Context) ([]model.ApiQuota, error) {
+	rows, err := r.pool.Query(ctx, `SELECT`+quotaColumns+` FROM "API_QUOTA" ORDER BY "ID"`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quotas: %w", err)
+	}
+	defer rows.Close()
+
+	quotas := []model.ApiQuota{}
+	for rows.Next() {
+		q, err := scanQuota(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, *q)
+	}
+
+	return quotas, rows.Err()
+}
+
+// GetByID busca uma cota pelo ID (nil se nao existir)
+func (r *QuotaRepo) GetByID(ctx context.Context, id int) (*model.ApiQuota, error) {
+	return r.queryOne(ctx, `SELECT`+quotaColumns+` FROM "API_QUOTA" WHERE "ID" = $1`, id)
+}
+
+// GetByKeyHash busca a cota de uma chave pelo hash (nil se nao existir)
+func (r *QuotaRepo) GetByKeyHash(ctx context.Context, chaveHash string) (*model.ApiQuota, error) {
+	return r.queryOne(ctx, `SELECT`+quotaColumns+` FROM "API_QUOTA" WHERE "ChaveHash" = $1`, chaveHash)
+}
+
+// Create grava uma nova cota para a chave com o hash e prefixo informados
+func (r *QuotaRepo) Create(ctx context.Context, req model.QuotaRequest, chaveHash, chavePrefixo string) (*model.ApiQuota, error) {
+	query := `
+		INSERT INTO "API_QUOTA" ("Nome", "ChaveHash", "ChavePrefixo", "RequisicoesDia", "Burst", "Ativo")
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING` + quotaColumns
+
+	return r.queryOne(ctx, query, req.Nome, chaveHash, chavePrefixo, req.RequisicoesDia, req.Burst, ativo(req))
+}
+
+// Update altera nome, limites e status de uma cota (nil se nao existir)
+func (r *QuotaRepo) Update(ctx context.Context, id int, req model.QuotaRequest) (*model.ApiQuota, error) {
+	query := `
+		UPDATE "API_QUOTA" SET
+			"Nome" = $2,
+			"RequisicoesDia" = $3,
+			"Burst" = $4,
+			"Ativo" = $5,
+			"AtualizadoEm" = NOW()
+		WHERE "ID" = $1
+		RETURNING` + quotaColumns
+
+	return r.queryOne(ctx, query, id, req.Nome, req.RequisicoesDia, req.Burst, ativo(req))
+}
+
+// Delete remove uma cota pelo ID
+func (r *QuotaRepo) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM "API_QUOTA" WHERE "ID" = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete quota: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Consume conta uma requisicao da cota se o limite diario permitir.
+// Retorna o uso do dia apos a requisicao e se ela foi aceita.
+func (r *QuotaRepo) Consume(ctx context.Context, id int) (int, bool, error) {
+	var uso int
+	err := r.pool.QueryRow(ctx, `
+		UPDATE "API_QUOTA" SET "UsoDia" = "UsoDia" + 1
+		WHERE "ID" = $1
+			AND ("RequisicoesDia" = 0 OR "UsoDia" < "RequisicoesDia")
+		RETURNING "UsoDia"
+	`, id).Scan(&uso)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	return uso, true, nil
+}
+
+// ResetDailyUsage zera os contadores das cotas cujo dia de uso ja passou
+func (r *QuotaRepo) ResetDailyUsage(ctx context.Context) (int64, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE "API_QUOTA" SET "UsoDia" = 0, "DiaUso" = CURRENT_DATE
+		WHERE "DiaUso" < CURRENT_DATE
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset quota usage: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *QuotaRepo) queryOne(ctx context.Context, query string, args ...any) (*model.ApiQuota, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quota: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	return scanQuota(rows)
+}
+
+func scanQuota(rows pgx.Rows) (*model.ApiQuota, error) {
+	var q model.ApiQuota
+	if err := rows.Scan(
+		&q.ID, &q.Nome, &q.ChavePrefixo, &q.RequisicoesDia, &q.Burst, &q.Ativo,
+		&q.UsoDia, &q.CriadoEm, &q.AtualizadoEm,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan quota: %w", err)
+	}
+	return &q, nil
+}
+
+// ativo aplica o padrao (ativo) quando o campo nao e enviado
+func ativo(req model.QuotaRequest) bool {
+	return req.Ativo == nil || *req.Ativo
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+var (
+	// ErrReferenciaInvalida indica concorrente ou produto Wega inexistente; a mensagem diz qual
+	ErrReferenciaInvalida = errors.New("referencia invalida")
+	// ErrReferenciaDuplicada indica que o produto ja tem esse codigo do mesmo concorrente
+	ErrReferenciaDuplicada = errors.New("referencia ja cadastrada")
+)
+
+// referenciaSelect le uma referencia com a marca do concorrente e o codigo Wega
+const referenciaSelect = `
+	SELECT rc."ID", rc."NumeroProdutoPesq", rc."CodigoFabricante", f."DescricaoFabricante",
+		rc."CodigoProduto", p."NumeroProduto"
+	FROM "REFERENCIACRUZADA" rc
+	JOIN "PRODUTO" p ON p."CodigoProduto" = rc."CodigoProduto"
+	JOIN "FABRICANTE" f ON f."CodigoFabricante" = rc."CodigoFabricante"`
+
+// ReferenciaAdminRepo mantem REFERENCIACRUZADA pelo admin, gravando cada
+// alteracao em REFERENCIACRUZADA_AUDITORIA na mesma transacao. Usa o pool
+// primario; as buscas publicas seguem no ReferenciaRepo.
+type ReferenciaAdminRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewReferenciaAdminRepo(pool *pgxpool.Pool) *ReferenciaAdminRepo {
+	return &ReferenciaAdminRepo{pool: pool}
+}
+
+func scanReferencia(row pgx.Row) (*model.ReferenciaCruzada, error) {
+	var ref model.ReferenciaCruzada
+	err := row.Scan(&ref.ID, &ref.CodigoConcorrente, &ref.CodigoFabricante, &ref.MarcaConcorrente, &ref.CodigoProduto, &ref.CodigoWega)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ref, nil
+}
+
+// Listar busca as referencias de um codigo de concorrente e/ou de um codigo
+// Wega (vazios nao filtram)
+func (r *ReferenciaAdminRepo) Listar(ctx context.Context, codigoConcorrente, codigoWega string, limit int) ([]model.ReferenciaCruzada, error) {
+	rows, err := r.pool.Query(ctx, referenciaSelect+`
+		WHERE ($1 = '' OR UPPER(rc."NumeroProdutoPesq") = UPPER($1))
+			AND ($2 = '' OR UPPER(TRIM(p."NumeroProduto")) = UPPER($2))
+		ORDER BY rc."NumeroProdutoPesq", f."DescricaoFabricante", p."NumeroProduto"
+		LIMIT $3
+	`, codigoConcorrente, codigoWega, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cross references: %w", err)
+	}
+	defer rows.Close()
+
+	referencias := []model.ReferenciaCruzada{}
+	for rows.Next() {
+		ref, err := scanReferencia(rows)
+		if err != nil {
+			return nil, err
+		}
+		referencias = append(referencias, *ref)
+	}
+	return referencias, rows.Err()
+}
+
+// BuscarPorID retorna uma referencia (nil se nao existe)
+func (r *ReferenciaAdminRepo) BuscarPorID(ctx context.Context, id int) (*model.ReferenciaCruzada, error) {
+	return scanReferencia(r.pool.QueryRow(ctx, referenciaSelect+` WHERE rc."ID" = $1`, id))
+}
+
+// Criar grava uma referencia nova
+func (r *ReferenciaAdminRepo) Criar(ctx context.Context, req model.ReferenciaRequest, ator string) (*model.ReferenciaCruzada, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin cross reference change: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	codigoProduto, err := validarReferencia(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO "REFERENCIACRUZADA" ("CodigoProduto", "CodigoFabricante", "NumeroProdutoPesq")
+		VALUES ($1, $2, $3)
+		ON CONFLICT ("CodigoProduto", "CodigoFabricante", UPPER("NumeroProdutoPesq")) DO NOTHING
+		RETURNING "ID"
+	`, codigoProduto, req.CodigoFabricante, req.CodigoConcorrente).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReferenciaDuplicada
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cross reference: %w", err)
+	}
+
+	return finalizarReferencia(ctx, tx, id, model.AcaoCriar, ator, nil)
+}
+
+// Alterar troca o codigo, o concorrente ou o produto de uma referencia (nil
+// se nao existe)
+func (r *ReferenciaAdminRepo) Alterar(ctx context.Context, id int, req model.ReferenciaRequest, ator string) (*model.ReferenciaCruzada, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin cross reference change: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	anterior, err := scanReferencia(tx.QueryRow(ctx, referenciaSelect+` WHERE rc."ID" = $1 FOR UPDATE OF rc`, id))
+	if err != nil || anterior == nil {
+		return nil, err
+	}
+
+	codigoProduto, err := validarReferencia(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "REFERENCIACRUZADA" SET "CodigoProduto" = $2, "CodigoFabricante" = $3, "NumeroProdutoPesq" = $4
+		WHERE "ID" = $1
+	`, id, codigoProduto, req.CodigoFabricante, req.CodigoConcorrente)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+		return nil, ErrReferenciaDuplicada
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cross reference: %w", err)
+	}
+
+	return finalizarReferencia(ctx, tx, id, model.AcaoAlterar, ator, anterior)
+}
+
+// Remover apaga uma referencia; retorna false se ela nao existe
+func (r *ReferenciaAdminRepo) Remover(ctx context.Context, id int, ator string) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin cross reference change: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	anterior, err := scanReferencia(tx.QueryRow(ctx, referenciaSelect+` WHERE rc."ID" = $1 FOR UPDATE OF rc`, id))
+	if err != nil || anterior == nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM "REFERENCIACRUZADA" WHERE "ID" = $1`, id); err != nil {
+		return false, fmt.Errorf("failed to delete cross reference: %w", err)
+	}
+	if err := auditarReferencia(ctx, tx, id, model.AcaoRemover, ator, anterior, nil); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit cross reference change: %w", err)
+	}
+	return true, nil
+}
+
+// Auditoria retorna as alteracoes mais recentes, de uma referencia ou de
+// todas (referenciaID 0)
+func (r *ReferenciaAdminRepo) Auditoria(ctx context.Context, referenciaID, limit int) ([]model.AuditoriaReferencia, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT "ID", "ReferenciaID", "Acao", "Ator", "Anterior", "Novo", "CriadoEm"
+		FROM "REFERENCIACRUZADA_AUDITORIA"
+		WHERE $1 = 0 OR "ReferenciaID" = $1
+		ORDER BY "CriadoEm" DESC, "ID" DESC
+		LIMIT $2
+	`, referenciaID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cross reference audit: %w", err)
+	}
+	defer rows.Close()
+
+	auditoria := []model.AuditoriaReferencia{}
+	for rows.Next() {
+		var a model.AuditoriaReferencia
+		if err := rows.Scan(&a.ID, &a.ReferenciaID, &a.Acao, &a.Ator, &a.Anterior, &a.Novo, &a.CriadoEm); err != nil {
+			return nil, err
+		}
+		auditoria = append(auditoria, a)
+	}
+	return auditoria, rows.Err()
+}
+
+// validarReferencia confere o concorrente e o produto Wega da requisicao e
+// retorna o CodigoProduto. Referencias repetidas sao barradas pelo indice
+// unico uq_referencia_produto_fabricante_codigo na gravacao.
+func validarReferencia(ctx context.Context, tx pgx.Tx, req model.ReferenciaRequest) (int, error) {
+	var concorrente bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM "FABRICANTE" WHERE "CodigoFabricante" = $1 AND "FlagProduto" = 1)
+	`, req.CodigoFabricante).Scan(&concorrente)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check manufacturer: %w", err)
+	}
+	if !concorrente {
+		return 0, fmt.Errorf("%w: codigo_fabricante %d nao e um concorrente", ErrReferenciaInvalida, req.CodigoFabricante)
+	}
+
+	// Um codigo Wega repetido no catalogo fica com o primeiro produto
+	var codigoProduto int
+	err = tx.QueryRow(ctx, `
+		SELECT "CodigoProduto" FROM "PRODUTO"
+		WHERE UPPER(TRIM("NumeroProduto")) = UPPER($1)
+		ORDER BY "CodigoProduto"
+		LIMIT 1
+	`, req.CodigoWega).Scan(&codigoProduto)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%w: codigo_wega %q nao existe", ErrReferenciaInvalida, req.CodigoWega)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find product: %w", err)
+	}
+	return codigoProduto, nil
+}
+
+// finalizarReferencia le a referencia gravada, audita a mudanca e confirma
+func finalizarReferencia(ctx context.Context, tx pgx.Tx, id int, acao, ator string, anterior *model.ReferenciaCruzada) (*model.ReferenciaCruzada, error) {
+	novo, err := scanReferencia(tx.QueryRow(ctx, referenciaSelect+` WHERE rc."ID" = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := auditarReferencia(ctx, tx, id, acao, ator, anterior, novo); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit cross reference change: %w", err)
+	}
+	return novo, nil
+}
+
+func auditarReferencia(ctx context.Context, tx pgx.Tx, id int, acao, ator string, anterior, novo *model.ReferenciaCruzada) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO "REFERENCIACRUZADA_AUDITORIA" ("ReferenciaID", "Acao", "Ator", "Anterior", "Novo")
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, acao, ator, anterior, novo)
+	if err != nil {
+		return fmt.Errorf("failed to audit cross reference change: %w", err)
+	}
+	return nil
+}
@@ -4,16 +4,16 @@ import (
 	"context"
 	"strings"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
+	"wega-catalog-api/internal/database"
 	"wega-catalog-api/internal/model"
 )
 
 type ReferenciaRepo struct {
-	db *pgxpool.Pool
+	db database.Querier
 }
 
-func NewReferenciaRepo(db *pgxpool.Pool) *ReferenciaRepo {
+// NewReferenciaRepo recebe o pool primario ou um database.ReadPool (replica de leitura)
+func NewReferenciaRepo(db database.Querier) *ReferenciaRepo {
 	return &ReferenciaRepo{db: db}
 }
 
@@ -26,7 +26,8 @@ func (r *ReferenciaRepo) BuscarPorCodigo(ctx context.Context, codigo string) (*m
 			p."NumeroProduto" as codigo_wega,
 			COALESCE(p."DescricaoProduto", '') as descricao,
 			sg."DescricaoSubGrupoProduto" as tipo,
-			p."ArquivoFotoProduto" as foto
+			p."ArquivoFotoProduto" as foto,
+			p."Disponivel" as disponivel
 		FROM "REFERENCIACRUZADA" rc
 		JOIN "PRODUTO" p ON rc."CodigoProduto" = p."CodigoProduto"
 		JOIN "FABRICANTE" f ON rc."CodigoFabricante" = f."CodigoFabricante"
@@ -49,7 +50,7 @@ func (r *ReferenciaRepo) BuscarPorCodigo(ctx context.Context, codigo string) (*m
 	for rows.Next() {
 		var marcaConcorrente string
 		var p model.Produto
-		if err := rows.Scan(&marcaConcorrente, &p.CodigoProduto, &p.CodigoWega, &p.Descricao, &p.Tipo, &p.FotoURL); err != nil {
+		if err := rows.Scan(&marcaConcorrente, &p.CodigoProduto, &p.CodigoWega, &p.Descricao, &p.Tipo, &p.FotoURL, &p.Disponivel); err != nil {
 			return nil, err
 		}
 		if response.MarcaConcorrente == "" {
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// ScraperCheckpointRepo stores scraper checkpoints in SCRAPER_CHECKPOINT, one row per run ID
+type ScraperCheckpointRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewScraperCheckpointRepo creates a new scraper checkpoint repository
+func NewScraperCheckpointRepo(pool *pgxpool.Pool) *ScraperCheckpointRepo {
+	return &ScraperCheckpointRepo{pool: pool}
+}
+
+// Load returns the checkpoint of a run, or nil if the run has none
+func (r *ScraperCheckpointRepo) Load(ctx context.Context, runID string) (*model.ScraperCheckpoint, error) {
+	query := `
+		SELECT "RunID", "UltimoCodigoAplicacao", "IniciadoEm", "SalvoEm",
+			"Sucesso", "Falhas", "Ignorados"
+		FROM "SCRAPER_CHECKPOINT"
+		WHERE "RunID" = $1
+	`
+
+	var c model.ScraperCheckpoint
+	err := r.pool.QueryRow(ctx, query, runID).Scan(
+		&c.RunID, &c.LastProcessedID, &c.StartedAt, &c.SavedAt,
+		&c.Stats.Success, &c.Stats.Failed, &c.Stats.Skipped,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	return &c, nil
+}
+
+// Save inserts or replaces the checkpoint of a run
+func (r *ScraperCheckpointRepo) Save(ctx context.Context, c *model.ScraperCheckpoint) error {
+	query := `
+		INSERT INTO "SCRAPER_CHECKPOINT" (
+			"RunID", "UltimoCodigoAplicacao", "IniciadoEm", "SalvoEm",
+			"Sucesso", "Falhas", "Ignorados"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ("RunID") DO UPDATE SET
+			"UltimoCodigoAplicacao" = EXCLUDED."UltimoCodigoAplicacao",
+			"IniciadoEm" = EXCLUDED."IniciadoEm",
+			"SalvoEm" = EXCLUDED."SalvoEm",
+			"Sucesso" = EXCLUDED."Sucesso",
+			"Falhas" = EXCLUDED."Falhas",
+			"Ignorados" = EXCLUDED."Ignorados"
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		c.RunID, c.LastProcessedID, c.StartedAt, c.SavedAt,
+		c.Stats.Success, c.Stats.Failed, c.Stats.Skipped,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the checkpoint of a run
+func (r *ScraperCheckpointRepo) Delete(ctx context.Context, runID string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM "SCRAPER_CHECKPOINT" WHERE "RunID" = $1`, runID); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
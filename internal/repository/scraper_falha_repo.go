@@ -34,8 +34,8 @@ func (r *ScraperFalhaRepo) Upsert(ctx context.Context, codigoAplicacao int, tipo
 		// Network error: retry in 5 minutes
 		t := time.Now().Add(5 * time.Minute)
 		proximaTentativa = &t
-	case model.ErroTipoModeloNaoEncontrado:
-		// Model not found: don't auto-retry (likely permanent)
+	case model.ErroTipoModeloNaoEncontrado, model.ErroTipoBaixaConfianca, model.ErroTipoSpecInvalida:
+		// Model not found, weak match or invalid provider data: don't auto-retry (needs review)
 		proximaTentativa = nil
 	default:
 		// Other errors: retry in 30 minutes
@@ -185,6 +185,36 @@ func (r *ScraperFalhaRepo) GetStats(ctx context.Context) (map[string]int, error)
 	return stats, nil
 }
 
+// CountRecentByType returns how many failures of each type had an attempt within the last period
+func (r *ScraperFalhaRepo) CountRecentByType(ctx context.Context, period time.Duration) (map[string]int, error) {
+	cutoff := time.Now().Add(-period)
+
+	query := `
+		SELECT "TipoErro", COUNT(*) as count
+		FROM "SCRAPER_FALHAS"
+		WHERE "UltimaTentativa" > $1
+		GROUP BY "TipoErro"
+	`
+
+	rows, err := r.pool.Query(ctx, query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent failures: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tipoErro string
+		var count int
+		if err := rows.Scan(&tipoErro, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan recent failures row: %w", err)
+		}
+		counts[tipoErro] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // CountPending returns total count of unresolved failures
 func (r *ScraperFalhaRepo) CountPending(ctx context.Context) (int, error) {
 	var count int
@@ -211,3 +241,93 @@ func (r *ScraperFalhaRepo) DeleteResolved(ctx context.Context, olderThan time.Du
 
 	return result.RowsAffected(), nil
 }
+
+// FalhaFilter holds optional filters for listing failures
+type FalhaFilter struct {
+	TipoErro  string
+	Resolvido *bool
+	Limit     int
+	Offset    int
+}
+
+// List returns failures matching the filter, most recent attempts first, plus the total count
+func (r *ScraperFalhaRepo) List(ctx context.Context, filter FalhaFilter) ([]model.ScraperFalha, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.TipoErro != "" {
+		where += fmt.Sprintf(` AND "TipoErro" = $%d`, argIndex)
+		args = append(args, filter.TipoErro)
+		argIndex++
+	}
+
+	if filter.Resolvido != nil {
+		where += fmt.Sprintf(` AND "Resolvido" = $%d`, argIndex)
+		args = append(args, *filter.Resolvido)
+		argIndex++
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "SCRAPER_FALHAS"`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count failures: %w", err)
+	}
+
+	query := `
+		SELECT
+			"ID", "CodigoAplicacao", "TipoErro", "MensagemErro",
+			"Tentativas", "UltimaTentativa", "ProximaTentativa",
+			"Resolvido", "ResolvidoEm", "CriadoEm"
+		FROM "SCRAPER_FALHAS"` + where + fmt.Sprintf(`
+		ORDER BY "UltimaTentativa" DESC
+		LIMIT $%d OFFSET $%d`, argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list failures: %w", err)
+	}
+	defer rows.Close()
+
+	falhas := []model.ScraperFalha{}
+	for rows.Next() {
+		var f model.ScraperFalha
+		err := rows.Scan(
+			&f.ID, &f.CodigoAplicacao, &f.TipoErro, &f.MensagemErro,
+			&f.Tentativas, &f.UltimaTentativa, &f.ProximaTentativa,
+			&f.Resolvido, &f.ResolvidoEm, &f.CriadoEm,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan failure row: %w", err)
+		}
+		falhas = append(falhas, f)
+	}
+
+	return falhas, total, rows.Err()
+}
+
+// ForceRetry makes a failure eligible for retry immediately (even if resolved or permanent)
+// Returns false if the failure does not exist
+func (r *ScraperFalhaRepo) ForceRetry(ctx context.Context, id int) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE "SCRAPER_FALHAS"
+		SET "ProximaTentativa" = NOW(), "Resolvido" = FALSE, "ResolvidoEm" = NULL
+		WHERE "ID" = $1
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to force retry: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Delete removes a single failure record
+// Returns false if the failure does not exist
+func (r *ScraperFalhaRepo) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM "SCRAPER_FALHAS" WHERE "ID" = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete failure: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Status of a vehicle in SCRAPER_QUEUE
+const (
+	FilaPendente      = "pendente"
+	FilaProcessamento = "em_processamento"
+	FilaConcluido     = "concluido"
+)
+
+// ErrClaimLost is returned by Complete when the worker no longer holds the
+// claim: its lease expired and the vehicle was reclaimed, or it was released
+var ErrClaimLost = errors.New("queued vehicle is no longer claimed by this worker")
+
+// ScraperQueueRepo shares the vehicles of a scraper run between instances via SCRAPER_QUEUE
+type ScraperQueueRepo struct {
+	pool  *pgxpool.Pool
+	lease time.Duration // Claims older than this are considered abandoned
+}
+
+// NewScraperQueueRepo creates a new scraper queue repository
+func NewScraperQueueRepo(pool *pgxpool.Pool, lease time.Duration) *ScraperQueueRepo {
+	return &ScraperQueueRepo{pool: pool, lease: lease}
+}
+
+// Enqueue adds vehicles to the run queue keeping their order; vehicles already
+// queued for the run (by this or another instance) are left untouched
+func (r *ScraperQueueRepo) Enqueue(ctx context.Context, runID string, ids []int) (int64, error) {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO "SCRAPER_QUEUE" ("RunID", "CodigoAplicacao", "Ordem")
+		SELECT $1, v.id, v.ordem
+		FROM unnest($2::int[]) WITH ORDINALITY AS v(id, ordem)
+		ON CONFLICT ("RunID", "CodigoAplicacao") DO NOTHING
+	`, runID, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue vehicles: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// CountPending returns the vehicles of the run not yet completed
+func (r *ScraperQueueRepo) CountPending(ctx context.Context, runID string) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM "SCRAPER_QUEUE"
+		WHERE "RunID" = $1 AND "Status" <> $2
+	`, runID, FilaConcluido).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending vehicles: %w", err)
+	}
+	return count, nil
+}
+
+// Claim reserves up to limit vehicles for worker, in queue order. Rows locked by
+// another instance's claim are skipped instead of waited on; claims older than
+// the lease are taken over.
+func (r *ScraperQueueRepo) Claim(ctx context.Context, runID, worker string, limit int) ([]int, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH claimed AS (
+			SELECT "CodigoAplicacao"
+			FROM "SCRAPER_QUEUE"
+			WHERE "RunID" = $1
+				AND ("Status" = $4
+					OR ("Status" = $5 AND "ReivindicadoEm" < $6))
+			ORDER BY "Ordem"
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE "SCRAPER_QUEUE" q SET
+			"Status" = $5,
+			"Worker" = $2,
+			"ReivindicadoEm" = NOW(),
+			"Tentativas" = q."Tentativas" + 1
+		FROM claimed
+		WHERE q."RunID" = $1 AND q."CodigoAplicacao" = claimed."CodigoAplicacao"
+		RETURNING q."CodigoAplicacao", q."Ordem"
+	`, runID, worker, limit, FilaPendente, FilaProcessamento, time.Now().Add(-r.lease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim vehicles: %w", err)
+	}
+	defer rows.Close()
+
+	// RETURNING has no order; sort back to queue order
+	type claim struct{ id, ordem int }
+	var claims []claim
+	for rows.Next() {
+		var c claim
+		if err := rows.Scan(&c.id, &c.ordem); err != nil {
+			return nil, fmt.Errorf("failed to scan claimed vehicle: %w", err)
+		}
+		claims = append(claims, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(claims, func(a, b claim) int { return a.ordem - b.ordem })
+	ids := make([]int, len(claims))
+	for i, c := range claims {
+		ids[i] = c.id
+	}
+	return ids, nil
+}
+
+// Complete marks a vehicle claimed by worker as processed, or returns ErrClaimLost
+func (r *ScraperQueueRepo) Complete(ctx context.Context, runID, worker string, id int) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE "SCRAPER_QUEUE" SET "Status" = $4, "ConcluidoEm" = NOW()
+		WHERE "RunID" = $1 AND "CodigoAplicacao" = $2 AND "Worker" = $3 AND "Status" = $5
+	`, runID, id, worker, FilaConcluido, FilaProcessamento)
+	if err != nil {
+		return fmt.Errorf("failed to complete queued vehicle: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Release returns the unfinished claims of worker to the queue
+func (r *ScraperQueueRepo) Release(ctx context.Context, runID, worker string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE "SCRAPER_QUEUE" SET "Status" = $3, "Worker" = NULL, "ReivindicadoEm" = NULL
+		WHERE "RunID" = $1 AND "Worker" = $2 AND "Status" = $4
+	`, runID, worker, FilaPendente, FilaProcessamento)
+	if err != nil {
+		return fmt.Errorf("failed to release claims: %w", err)
+	}
+	return nil
+}
+
+// Reset removes the queue of a run so the next Enqueue starts it over
+func (r *ScraperQueueRepo) Reset(ctx context.Context, runID string) (int64, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM "SCRAPER_QUEUE" WHERE "RunID" = $1`, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset queue: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// ScraperRunRepo stores the scraper runs and their summary metrics in SCRAPER_RUN
+type ScraperRunRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewScraperRunRepo creates a new scraper run repository
+func NewScraperRunRepo(pool *pgxpool.Pool) *ScraperRunRepo {
+	return &ScraperRunRepo{pool: pool}
+}
+
+// Start inserts the row of a run that is beginning (status running) and
+// returns its ID
+func (r *ScraperRunRepo) Start(ctx context.Context, run model.ScraperRun) (int, error) {
+	var id int
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO "SCRAPER_RUN" ("RunID", "Provedor", "Worker", "Status", "IniciadoEm", "FinalizadoEm", "DuracaoSegundos", "Configuracao")
+		VALUES ($1, $2, $3, $4, $5, $5, 0, $6)
+		RETURNING "ID"
+	`, run.RunID, run.Provider, run.Worker, run.Status, run.StartedAt, run.Config).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start scraper run: %w", err)
+	}
+	return id, nil
+}
+
+// Record stores the summary of a finished run with its LLM token usage:
+// it updates the row created by Start (run.ID) or inserts a new one
+func (r *ScraperRunRepo) Record(ctx context.Context, run model.ScraperRun) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record scraper run: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if run.ID > 0 {
+		err = r.finish(ctx, tx, run)
+	} else {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO "SCRAPER_RUN" (
+				"RunID", "Provedor", "Worker", "Status", "IniciadoEm", "FinalizadoEm", "DuracaoSegundos",
+				"Total", "Processados", "Sucesso", "Falhas", "Ignorados",
+				"MatchExato", "MatchFuzzy", "SemMatch",
+				"Requisicoes", "ErrosRede", "RateLimit", "TokensLLM",
+				"Configuracao", "FalhasPorMotivo", "TiposErro", "Erro"
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, NULLIF($23, ''))
+			RETURNING "ID"
+		`,
+			run.RunID, run.Provider, run.Worker, run.Status, run.StartedAt, run.FinishedAt, run.Duration,
+			run.Total, run.Processed, run.Success, run.Failed, run.Skipped,
+			run.ExactMatch, run.FuzzyMatch, run.NoMatch,
+			run.Requests, run.NetworkErrors, run.RateLimitHits, run.LLMTokens,
+			run.Config, run.FailuresByReason, run.ErrorTypes, run.Error,
+		).Scan(&run.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record scraper run: %w", err)
+	}
+
+	if len(run.TokenUsage) > 0 {
+		batch := &pgx.Batch{}
+		for _, u := range run.TokenUsage {
+			batch.Queue(`
+				INSERT INTO "SCRAPER_RUN_TOKENS" ("RunID", "Provedor", "Chave", "Requisicoes", "TokensPrompt", "TokensResposta")
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, run.ID, u.Provider, u.Key, u.Requests, u.PromptTokens, u.CompletionTokens)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to record scraper run tokens: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to record scraper run: %w", err)
+	}
+	return nil
+}
+
+// finish writes the final summary over the row created by Start
+func (r *ScraperRunRepo) finish(ctx context.Context, tx pgx.Tx, run model.ScraperRun) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE "SCRAPER_RUN" SET
+			"Status" = $2, "IniciadoEm" = $3, "FinalizadoEm" = $4, "DuracaoSegundos" = $5,
+			"Total" = $6, "Processados" = $7, "Sucesso" = $8, "Falhas" = $9, "Ignorados" = $10,
+			"MatchExato" = $11, "MatchFuzzy" = $12, "SemMatch" = $13,
+			"Requisicoes" = $14, "ErrosRede" = $15, "RateLimit" = $16, "TokensLLM" = $17,
+			"Configuracao" = $18, "FalhasPorMotivo" = $19, "TiposErro" = $20, "Erro" = NULLIF($21, '')
+		WHERE "ID" = $1
+	`,
+		run.ID, run.Status, run.StartedAt, run.FinishedAt, run.Duration,
+		run.Total, run.Processed, run.Success, run.Failed, run.Skipped,
+		run.ExactMatch, run.FuzzyMatch, run.NoMatch,
+		run.Requests, run.NetworkErrors, run.RateLimitHits, run.LLMTokens,
+		run.Config, run.FailuresByReason, run.ErrorTypes, run.Error,
+	)
+	return err
+}
+
+// Heartbeat moves FinalizadoEm of a running run to now: while the status is
+// running, FinalizadoEm is the last sign of life used by FailStale
+func (r *ScraperRunRepo) Heartbeat(ctx context.Context, id int) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE "SCRAPER_RUN"
+		SET "FinalizadoEm" = NOW(), "DuracaoSegundos" = EXTRACT(EPOCH FROM NOW() - "IniciadoEm")
+		WHERE "ID" = $1 AND "Status" = $2
+	`, id, model.RunStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to update scraper run heartbeat: %w", err)
+	}
+	return nil
+}
+
+// FailStale marks as failed the runs still running without a heartbeat for
+// longer than after: their process crashed or was killed before Record.
+// Returns how many runs changed.
+func (r *ScraperRunRepo) FailStale(ctx context.Context, after time.Duration) (int64, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE "SCRAPER_RUN"
+		SET "Status" = $1, "Erro" = $2
+		WHERE "Status" = $3 AND "FinalizadoEm" < NOW() - make_interval(secs => $4)
+	`, model.RunStatusFailed, "run stopped without finishing (no heartbeat)", model.RunStatusRunning, after.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale scraper runs: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// StartedAt returns when a run started (nil if the run does not exist)
+func (r *ScraperRunRepo) StartedAt(ctx context.Context, id int) (*time.Time, error) {
+	var startedAt time.Time
+	err := r.pool.QueryRow(ctx, `SELECT "IniciadoEm" FROM "SCRAPER_RUN" WHERE "ID" = $1`, id).Scan(&startedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scraper run: %w", err)
+	}
+	return &startedAt, nil
+}
+
+// scraperRunColumns lists the columns read by scanScraperRun
+const scraperRunColumns = `
+	"ID", "RunID", "Provedor", "Worker", "Status", "IniciadoEm", "FinalizadoEm", "DuracaoSegundos",
+	"Total", "Processados", "Sucesso", "Falhas", "Ignorados",
+	"MatchExato", "MatchFuzzy", "SemMatch",
+	"Requisicoes", "ErrosRede", "RateLimit", "TokensLLM",
+	"Configuracao", "FalhasPorMotivo", "TiposErro", COALESCE("Erro", '')`
+
+// scanScraperRun scans a row selected with scraperRunColumns
+func scanScraperRun(row pgx.Row) (model.ScraperRun, error) {
+	var run model.ScraperRun
+	err := row.Scan(
+		&run.ID, &run.RunID, &run.Provider, &run.Worker, &run.Status, &run.StartedAt, &run.FinishedAt, &run.Duration,
+		&run.Total, &run.Processed, &run.Success, &run.Failed, &run.Skipped,
+		&run.ExactMatch, &run.FuzzyMatch, &run.NoMatch,
+		&run.Requests, &run.NetworkErrors, &run.RateLimitHits, &run.LLMTokens,
+		&run.Config, &run.FailuresByReason, &run.ErrorTypes, &run.Error,
+	)
+	return run, err
+}
+
+// GetByID returns one run (nil if it does not exist)
+func (r *ScraperRunRepo) GetByID(ctx context.Context, id int) (*model.ScraperRun, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+scraperRunColumns+` FROM "SCRAPER_RUN" WHERE "ID" = $1`, id)
+	run, err := scanScraperRun(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scraper run: %w", err)
+	}
+
+	runs := []model.ScraperRun{run}
+	if err := r.attachTokenUsage(ctx, runs); err != nil {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// List returns the most recent finished runs, oldest first
+func (r *ScraperRunRepo) List(ctx context.Context, limit int) ([]model.ScraperRun, error) {
+	runs, err := r.ListRecent(ctx, limit, "", "")
+	if err != nil {
+		return nil, err
+	}
+	runs = slices.DeleteFunc(runs, func(run model.ScraperRun) bool {
+		return run.Status == model.RunStatusRunning
+	})
+	slices.Reverse(runs)
+	return runs, nil
+}
+
+// ListRecent returns the most recent runs, newest first, optionally filtered
+// by provider and status ("" = any)
+func (r *ScraperRunRepo) ListRecent(ctx context.Context, limit int, provider, status string) ([]model.ScraperRun, error) {
+	query := `SELECT ` + scraperRunColumns + ` FROM "SCRAPER_RUN" WHERE 1=1`
+	args := []interface{}{}
+	if provider != "" {
+		args = append(args, provider)
+		query += fmt.Sprintf(` AND "Provedor" = $%d`, len(args))
+	}
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(` AND "Status" = $%d`, len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY "FinalizadoEm" DESC LIMIT $%d`, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scraper runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []model.ScraperRun{}
+	for rows.Next() {
+		run, err := scanScraperRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scraper run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.attachTokenUsage(ctx, runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// attachTokenUsage fills TokenUsage of the runs from SCRAPER_RUN_TOKENS
+func (r *ScraperRunRepo) attachTokenUsage(ctx context.Context, runs []model.ScraperRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+	ids := make([]int, len(runs))
+	index := make(map[int]int, len(runs))
+	for i, run := range runs {
+		ids[i] = run.ID
+		index[run.ID] = i
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT "RunID", "Provedor", "Chave", "Requisicoes", "TokensPrompt", "TokensResposta"
+		FROM "SCRAPER_RUN_TOKENS"
+		WHERE "RunID" = ANY($1)
+		ORDER BY "RunID", "Provedor", "Chave"
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get scraper run tokens: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var u model.LLMTokenUsage
+		if err := rows.Scan(&id, &u.Provider, &u.Key, &u.Requests, &u.PromptTokens, &u.CompletionTokens); err != nil {
+			return fmt.Errorf("failed to scan scraper run tokens: %w", err)
+		}
+		i := index[id]
+		runs[i].TokenUsage = append(runs[i].TokenUsage, u)
+	}
+	return rows.Err()
+}
+
+// TokenUsage sums the LLM token usage per provider and API key of the runs
+// finished in [since, until)
+func (r *ScraperRunRepo) TokenUsage(ctx context.Context, since, until time.Time) ([]model.LLMTokenUsage, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT t."Provedor", t."Chave", SUM(t."Requisicoes"), SUM(t."TokensPrompt"), SUM(t."TokensResposta")
+		FROM "SCRAPER_RUN_TOKENS" t
+		JOIN "SCRAPER_RUN" r ON r."ID" = t."RunID"
+		WHERE r."FinalizadoEm" >= $1 AND r."FinalizadoEm" < $2
+		GROUP BY t."Provedor", t."Chave"
+		ORDER BY t."Provedor", t."Chave"
+	`, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum scraper run tokens: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []model.LLMTokenUsage{}
+	for rows.Next() {
+		var u model.LLMTokenUsage
+		if err := rows.Scan(&u.Provider, &u.Key, &u.Requests, &u.PromptTokens, &u.CompletionTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan scraper run tokens: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
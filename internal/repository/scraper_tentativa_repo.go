@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScraperTentativaRepo keeps the last scraper outcome of each vehicle in SCRAPER_TENTATIVA
+type ScraperTentativaRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewScraperTentativaRepo creates a new scraper attempt repository
+func NewScraperTentativaRepo(pool *pgxpool.Pool) *ScraperTentativaRepo {
+	return &ScraperTentativaRepo{pool: pool}
+}
+
+// RecordAttempt stores the outcome of a provider search for the vehicle
+func (r *ScraperTentativaRepo) RecordAttempt(ctx context.Context, codigoAplicacao int, outcome string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO "SCRAPER_TENTATIVA" AS t ("CodigoAplicacao", "Resultado")
+		VALUES ($1, $2)
+		ON CONFLICT ("CodigoAplicacao") DO UPDATE SET
+			"Resultado" = EXCLUDED."Resultado",
+			"Tentativas" = t."Tentativas" + 1,
+			"UltimaTentativa" = NOW()
+	`, codigoAplicacao, outcome)
+	if err != nil {
+		return fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SnapshotTable is a scraper-produced table included in catalog snapshots
+type SnapshotTable struct {
+	Name   string
+	Serial string // SERIAL column whose sequence is reset after a restore; empty = none
+}
+
+// SnapshotTables are the tables exported by catalog snapshots, in restore
+// order. Run state (checkpoints, queue) is not included; the run history is,
+// so ScraperRunID, the change log and the token usage keep pointing at the
+// runs that wrote them. ESPECIFICACAO_TECNICA_HISTORICO comes after
+// ESPECIFICACAO_TECNICA so the history rows its trigger writes during the
+// restore are replaced, and SCRAPER_RUN_TOKENS after SCRAPER_RUN, its FK.
+var SnapshotTables = []SnapshotTable{
+	{Name: "ESPECIFICACAO_TECNICA", Serial: "ID"},
+	{Name: "ESPECIFICACAO_TECNICA_HISTORICO", Serial: "HistoricoID"},
+	{Name: "ESPECIFICACAO_ALTERACAO", Serial: "ID"},
+	{Name: "SPEC_CONFLITOS", Serial: "ID"},
+	{Name: "SCRAPER_FALHAS", Serial: "ID"},
+	{Name: "SCRAPER_TENTATIVA"},
+	{Name: "ALIAS_VEICULO"},
+	{Name: "CLASSIFICACAO_VEICULO"},
+	{Name: "SCRAPER_RUN", Serial: "ID"},
+	{Name: "SCRAPER_RUN_TOKENS"},
+}
+
+// SnapshotRepo copies the snapshot tables in and out of the database as CSV
+type SnapshotRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewSnapshotRepo creates a new snapshot repository
+func NewSnapshotRepo(pool *pgxpool.Pool) *SnapshotRepo {
+	return &SnapshotRepo{pool: pool}
+}
+
+// Export writes every snapshot table as CSV with a header row to the writer
+// open returns for it. All tables are read in one repeatable-read
+// transaction, so the snapshot is consistent even while a scraper is running.
+// Returns the number of rows per table.
+func (r *SnapshotRepo) Export(ctx context.Context, open func(table string) (io.Writer, error)) (map[string]int64, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin snapshot export: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows := make(map[string]int64, len(SnapshotTables))
+	for _, table := range SnapshotTables {
+		w, err := open(table.Name)
+		if err != nil {
+			return nil, err
+		}
+		tag, err := tx.Conn().PgConn().CopyTo(ctx, w,
+			fmt.Sprintf(`COPY %q TO STDOUT WITH (FORMAT csv, HEADER true)`, table.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", table.Name, err)
+		}
+		rows[table.Name] = tag.RowsAffected()
+	}
+
+	return rows, tx.Commit(ctx)
+}
+
+// Restore replaces the contents of the snapshot tables with the CSV data
+// open returns for them, in one transaction: either every table is restored
+// or none is. Tables for which open returns a nil reader are left untouched.
+// Columns are taken from each CSV header, so snapshots taken before a
+// migration added a column restore with that column's default.
+// Returns the number of rows per restored table.
+func (r *SnapshotRepo) Restore(ctx context.Context, open func(table string) (io.Reader, error)) (map[string]int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin snapshot restore: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows := make(map[string]int64, len(SnapshotTables))
+	for _, table := range SnapshotTables {
+		src, err := open(table.Name)
+		if err != nil {
+			return nil, err
+		}
+		if src == nil {
+			continue
+		}
+
+		data := bufio.NewReader(src)
+		columns, err := snapshotColumns(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s snapshot: %w", table.Name, err)
+		}
+
+		// CASCADE: SCRAPER_RUN_TOKENS references SCRAPER_RUN and is restored right after it
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`TRUNCATE %q CASCADE`, table.Name)); err != nil {
+			return nil, fmt.Errorf("failed to truncate %s: %w", table.Name, err)
+		}
+		tag, err := tx.Conn().PgConn().CopyFrom(ctx, data,
+			fmt.Sprintf(`COPY %q (%s) FROM STDIN WITH (FORMAT csv)`, table.Name, columns))
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", table.Name, err)
+		}
+		rows[table.Name] = tag.RowsAffected()
+
+		if table.Serial != "" {
+			_, err := tx.Exec(ctx, fmt.Sprintf(
+				`SELECT setval(pg_get_serial_sequence('%q', '%s'), COALESCE(MAX(%q), 0) + 1, false) FROM %q`,
+				table.Name, table.Serial, table.Serial, table.Name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to reset %s sequence: %w", table.Name, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit snapshot restore: %w", err)
+	}
+	return rows, nil
+}
+
+// snapshotColumns reads the CSV header row and returns it as a quoted column list
+func snapshotColumns(data *bufio.Reader) (string, error) {
+	line, err := data.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("missing CSV header: %w", err)
+	}
+	header, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return "", fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = pgx.Identifier{name}.Sanitize()
+	}
+	return strings.Join(columns, ", "), nil
+}
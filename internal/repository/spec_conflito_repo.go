@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/model"
+)
+
+// SpecConflitoFiltro contem os filtros opcionais da lista de conflitos
+type SpecConflitoFiltro struct {
+	CodigoAplicacao int
+	TipoFluido      string
+	Fonte           string
+	Limit           int
+	Offset          int
+}
+
+// SpecConflitoRepo lista e descarta os conflitos entre fontes de
+// SPEC_CONFLITOS; a deteccao fica no Upsert de EspecificacaoRepository
+type SpecConflitoRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewSpecConflitoRepo(pool *pgxpool.Pool) *SpecConflitoRepo {
+	return &SpecConflitoRepo{pool: pool}
+}
+
+// Listar retorna os conflitos mais recentes primeiro e o total sem paginacao
+func (r *SpecConflitoRepo) Listar(ctx context.Context, filtro SpecConflitoFiltro) ([]model.SpecConflito, int, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filtro.CodigoAplicacao > 0 {
+		add(`c."CodigoAplicacao" = $%d`, filtro.CodigoAplicacao)
+	}
+	if filtro.TipoFluido != "" {
+		add(`c."TipoFluido" = $%d`, filtro.TipoFluido)
+	}
+	if filtro.Fonte != "" {
+		add(`$%d IN (c."FonteA", c."FonteB")`, fonteCanonica(filtro.Fonte))
+	}
+	condicao := ""
+	if len(where) > 0 {
+		condicao = ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "SPEC_CONFLITOS" c`+condicao, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count spec conflicts: %w", err)
+	}
+
+	args = append(args, filtro.Limit, filtro.Offset)
+	rows, err := r.pool.Query(ctx, `
+		SELECT c."ID", c."CodigoAplicacao", COALESCE(a."DescricaoAplicacao", ''), c."TipoFluido", c."Condicao",
+			c."FonteA", c."ViscosidadeA", c."CapacidadeA", ea."ID", COALESCE(ea."Ativo", FALSE),
+			c."FonteB", c."ViscosidadeB", c."CapacidadeB", eb."ID", COALESCE(eb."Ativo", FALSE),
+			c."DetectadoEm", c."AtualizadoEm"
+		FROM "SPEC_CONFLITOS" c
+		LEFT JOIN "APLICACAO" a ON a."CodigoAplicacao" = c."CodigoAplicacao"
+		LEFT JOIN "ESPECIFICACAO_TECNICA" ea ON ea."CodigoAplicacao" = c."CodigoAplicacao"
+			AND ea."TipoFluido" = c."TipoFluido" AND ea."Condicao" = c."Condicao"
+			AND especificacao_fonte(ea."Fonte") = c."FonteA"
+		LEFT JOIN "ESPECIFICACAO_TECNICA" eb ON eb."CodigoAplicacao" = c."CodigoAplicacao"
+			AND eb."TipoFluido" = c."TipoFluido" AND eb."Condicao" = c."Condicao"
+			AND especificacao_fonte(eb."Fonte") = c."FonteB"
+	`+condicao+fmt.Sprintf(`
+		ORDER BY c."AtualizadoEm" DESC, c."ID" DESC
+		LIMIT $%d OFFSET $%d
+	`, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list spec conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	conflitos := []model.SpecConflito{}
+	for rows.Next() {
+		var c model.SpecConflito
+		var a, b model.SpecConflitoFonte
+		err := rows.Scan(&c.ID, &c.CodigoAplicacao, &c.DescricaoAplicacao, &c.TipoFluido, &c.Condicao,
+			&a.Fonte, &a.Viscosidade, &a.Capacidade, &a.EspecificacaoID, &a.Ativa,
+			&b.Fonte, &b.Viscosidade, &b.Capacidade, &b.EspecificacaoID, &b.Ativa,
+			&c.DetectadoEm, &c.AtualizadoEm)
+		if err != nil {
+			return nil, 0, err
+		}
+		c.Fontes = []model.SpecConflitoFonte{a, b}
+		conflitos = append(conflitos, c)
+	}
+	return conflitos, total, rows.Err()
+}
+
+// Remover descarta um conflito depois da arbitragem; retorna false se ele
+// nao existe. Volta a ser detectado se as fontes continuarem divergindo.
+func (r *SpecConflitoRepo) Remover(ctx context.Context, id int) (bool, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM "SPEC_CONFLITOS" WHERE "ID" = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete spec conflict: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// fonteCanonica compara fontes sem diferenciar maiusculas; linhas antigas
+// gravadas como "MotulAPI" (default da tabela) sao da fonte motul
+func fonteCanonica(fonte string) string {
+	fonte = strings.ToLower(strings.Join(strings.Fields(fonte), ""))
+	if fonte == "motulapi" {
+		return "motul"
+	}
+	return fonte
+}
+
+// detectarConflitos compara a especificacao recem-gravada com as ativas das
+// outras fontes para a mesma aplicacao, fluido e condicao (cada fonte tem a
+// sua linha, entao nada se perde): viscosidades sem nenhum grau SAE em comum
+// (ou textos diferentes, quando nao ha grau) geram ou renovam o conflito do
+// par de fontes; viscosidades compativeis encerram o conflito do par
+func detectarConflitos(ctx context.Context, tx pgx.Tx, spec *model.EspecificacaoTecnica) error {
+	rows, err := tx.Query(ctx, `
+		SELECT "Fonte", "Viscosidade", "Capacidade", "ViscosidadesSAE"
+		FROM "ESPECIFICACAO_TECNICA"
+		WHERE "CodigoAplicacao" = $1 AND "TipoFluido" = $2 AND "Condicao" = $3
+			AND especificacao_fonte("Fonte") <> especificacao_fonte($4) AND "Ativo"
+	`, spec.CodigoAplicacao, spec.TipoFluido, spec.Condicao, spec.Fonte)
+	if err != nil {
+		return fmt.Errorf("failed to read other sources' specs: %w", err)
+	}
+	outras, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.EspecificacaoTecnica, error) {
+		var e model.EspecificacaoTecnica
+		err := row.Scan(&e.Fonte, &e.Viscosidade, &e.Capacidade, &e.ViscosidadesSAE)
+		return e, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read other sources' specs: %w", err)
+	}
+
+	for i := range outras {
+		if err := registrarConflito(ctx, tx, &outras[i], spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registrarConflito grava ou encerra o conflito entre a especificacao de
+// outra fonte e a recem-gravada
+func registrarConflito(ctx context.Context, tx pgx.Tx, outra, spec *model.EspecificacaoTecnica) error {
+	a := model.SpecConflitoFonte{Fonte: fonteCanonica(outra.Fonte), Viscosidade: outra.Viscosidade, Capacidade: outra.Capacidade}
+	b := model.SpecConflitoFonte{Fonte: fonteCanonica(spec.Fonte), Viscosidade: spec.Viscosidade, Capacidade: spec.Capacidade}
+	if b.Fonte < a.Fonte {
+		a, b = b, a
+	}
+
+	if viscosidadesCompativeis(outra, spec) {
+		_, err := tx.Exec(ctx, `
+			DELETE FROM "SPEC_CONFLITOS"
+			WHERE "CodigoAplicacao" = $1 AND "TipoFluido" = $2 AND "Condicao" = $3
+				AND "FonteA" = $4 AND "FonteB" = $5
+		`, spec.CodigoAplicacao, spec.TipoFluido, spec.Condicao, a.Fonte, b.Fonte)
+		if err != nil {
+			return fmt.Errorf("failed to clear spec conflict: %w", err)
+		}
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO "SPEC_CONFLITOS" (
+			"CodigoAplicacao", "TipoFluido", "Condicao",
+			"FonteA", "ViscosidadeA", "CapacidadeA", "FonteB", "ViscosidadeB", "CapacidadeB"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT ("CodigoAplicacao", "TipoFluido", "Condicao", "FonteA", "FonteB") DO UPDATE SET
+			"ViscosidadeA" = EXCLUDED."ViscosidadeA",
+			"CapacidadeA" = EXCLUDED."CapacidadeA",
+			"ViscosidadeB" = EXCLUDED."ViscosidadeB",
+			"CapacidadeB" = EXCLUDED."CapacidadeB",
+			"AtualizadoEm" = NOW()
+	`, spec.CodigoAplicacao, spec.TipoFluido, spec.Condicao,
+		a.Fonte, a.Viscosidade, a.Capacidade, b.Fonte, b.Viscosidade, b.Capacidade)
+	if err != nil {
+		return fmt.Errorf("failed to flag spec conflict: %w", err)
+	}
+	return nil
+}
+
+// viscosidadesCompativeis considera compativel uma viscosidade ausente: so
+// ha conflito quando as duas fontes dizem algo e nada coincide
+func viscosidadesCompativeis(outra, spec *model.EspecificacaoTecnica) bool {
+	va, vb := textoNormalizado(outra.Viscosidade), textoNormalizado(spec.Viscosidade)
+	if va == nil || vb == nil {
+		return true
+	}
+	if len(outra.ViscosidadesSAE) > 0 && len(spec.ViscosidadesSAE) > 0 {
+		return slices.ContainsFunc(outra.ViscosidadesSAE, func(grau string) bool {
+			return slices.Contains(spec.ViscosidadesSAE, grau)
+		})
+	}
+	return strings.EqualFold(*va, *vb)
+}
@@ -0,0 +1,304 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimitAlertPayload is the JSON body posted to the alert webhook
+type RateLimitAlertPayload struct {
+	Event          string    `json:"event"`
+	Service        string    `json:"service"`
+	HitsLastMinute int       `json:"hits_last_minute"`
+	Threshold      int       `json:"threshold"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// RateLimitAlerter fires a webhook when rate-limit hits exceed a threshold per minute
+// At most one alert is sent per one-minute window
+type RateLimitAlerter struct {
+	webhookURL string
+	threshold  int
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+	alerted     bool
+}
+
+// NewRateLimitAlerter creates a new rate-limit alerter
+func NewRateLimitAlerter(webhookURL string, threshold int, logger *slog.Logger) *RateLimitAlerter {
+	return &RateLimitAlerter{
+		webhookURL: webhookURL,
+		threshold:  threshold,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Record registers a rate-limit hit and sends an alert if the threshold is exceeded
+func (a *RateLimitAlerter) Record(service string) {
+	if a.threshold <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	now := time.Now()
+	if now.Sub(a.windowStart) >= time.Minute {
+		a.windowStart = now
+		a.count = 0
+		a.alerted = false
+	}
+	a.count++
+	shouldAlert := a.count > a.threshold && !a.alerted
+	if shouldAlert {
+		a.alerted = true
+	}
+	hits := a.count
+	a.mu.Unlock()
+
+	if !shouldAlert {
+		return
+	}
+
+	a.logger.Warn("rate-limit hits exceeded threshold",
+		"service", service,
+		"hits_last_minute", hits,
+		"threshold", a.threshold,
+	)
+
+	if a.webhookURL == "" {
+		return
+	}
+
+	go func() {
+		payload := RateLimitAlertPayload{
+			Event:          "rate_limit_threshold_exceeded",
+			Service:        service,
+			HitsLastMinute: hits,
+			Threshold:      a.threshold,
+			Timestamp:      now,
+		}
+		if err := a.send(payload); err != nil {
+			a.logger.Warn("failed to send rate-limit alert", "error", err)
+		}
+	}()
+}
+
+// send posts the alert payload to the webhook
+func (a *RateLimitAlerter) send(payload RateLimitAlertPayload) error {
+	return postWebhook(a.httpClient, a.webhookURL, payload)
+}
+
+// postWebhook posts a JSON alert payload to a webhook
+func postWebhook(httpClient *http.Client, webhookURL string, payload any) error {
+	return postWebhookContext(context.Background(), httpClient, webhookURL, payload)
+}
+
+// postWebhookContext is postWebhook bounded by ctx (and at most 10s)
+func postWebhookContext(ctx context.Context, httpClient *http.Client, webhookURL string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SuccessRateAlertPayload is the JSON body posted when the rolling success rate drops
+type SuccessRateAlertPayload struct {
+	Event       string    `json:"event"`
+	SuccessRate float64   `json:"success_rate"`
+	Window      int       `json:"window"`
+	Threshold   float64   `json:"threshold"`
+	Paused      bool      `json:"paused"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// SuccessRateMonitor tracks the success rate over the last N attempted vehicles
+// and alerts (optionally pausing the run) when it drops below a threshold.
+// Skipped vehicles are not counted. After an alert it stays quiet until the
+// rate recovers, so a sustained drop fires once.
+type SuccessRateMonitor struct {
+	window     int
+	threshold  float64
+	pause      bool
+	webhookURL string
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	results []bool // Ring buffer of recent outcomes
+	next    int
+	alerted bool
+	resume  chan struct{} // Non-nil while paused; closed by Resume
+}
+
+// NewSuccessRateMonitor creates a monitor; threshold <= 0 disables it
+func NewSuccessRateMonitor(window int, threshold float64, pause bool, webhookURL string, logger *slog.Logger) *SuccessRateMonitor {
+	if window <= 0 {
+		window = 200
+	}
+	return &SuccessRateMonitor{
+		window:     window,
+		threshold:  threshold,
+		pause:      pause,
+		webhookURL: webhookURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger:  logger,
+		results: make([]bool, 0, window),
+	}
+}
+
+// Record registers the outcome of an attempted vehicle
+func (m *SuccessRateMonitor) Record(success bool) {
+	if m.threshold <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	if len(m.results) < m.window {
+		m.results = append(m.results, success)
+	} else {
+		m.results[m.next] = success
+		m.next = (m.next + 1) % m.window
+	}
+
+	// Wait for a full window so a few early failures don't trip the alert
+	if len(m.results) < m.window {
+		m.mu.Unlock()
+		return
+	}
+
+	rate := m.rateLocked()
+	if rate >= m.threshold {
+		m.alerted = false
+		m.mu.Unlock()
+		return
+	}
+	if m.alerted {
+		m.mu.Unlock()
+		return
+	}
+
+	m.alerted = true
+	paused := m.pause && m.resume == nil
+	if paused {
+		m.resume = make(chan struct{})
+	}
+	m.mu.Unlock()
+
+	m.logger.Warn("success rate dropped below threshold",
+		"success_rate", fmt.Sprintf("%.2f", rate),
+		"window", m.window,
+		"threshold", m.threshold,
+		"paused", paused,
+	)
+
+	if m.webhookURL == "" {
+		return
+	}
+
+	go func() {
+		payload := SuccessRateAlertPayload{
+			Event:       "success_rate_below_threshold",
+			SuccessRate: rate,
+			Window:      m.window,
+			Threshold:   m.threshold,
+			Paused:      paused,
+			Timestamp:   time.Now(),
+		}
+		if err := postWebhook(m.httpClient, m.webhookURL, payload); err != nil {
+			m.logger.Warn("failed to send success-rate alert", "error", err)
+		}
+	}()
+}
+
+// rateLocked returns the success rate of the current window (caller holds mu)
+func (m *SuccessRateMonitor) rateLocked() float64 {
+	if len(m.results) == 0 {
+		return 1
+	}
+	ok := 0
+	for _, success := range m.results {
+		if success {
+			ok++
+		}
+	}
+	return float64(ok) / float64(len(m.results))
+}
+
+// WaitIfPaused blocks while the run is paused, until Resume or ctx is done
+func (m *SuccessRateMonitor) WaitIfPaused(ctx context.Context) error {
+	m.mu.Lock()
+	resume := m.resume
+	m.mu.Unlock()
+
+	if resume == nil {
+		return nil
+	}
+
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Paused reports whether the run is currently paused
+func (m *SuccessRateMonitor) Paused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.resume != nil
+}
+
+// Resume unpauses the run and clears the window so it is judged afresh.
+// It returns false if the run was not paused.
+func (m *SuccessRateMonitor) Resume() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.resume == nil {
+		return false
+	}
+	close(m.resume)
+	m.resume = nil
+	m.results = m.results[:0]
+	m.next = 0
+	m.alerted = false
+
+	m.logger.Info("run resumed after low success-rate pause")
+	return true
+}
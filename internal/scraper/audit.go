@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit outcomes recorded per vehicle
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeSkipped = "skipped"
+	AuditOutcomeNoMatch = "no_match"
+	AuditOutcomeFailed  = "failed"
+	AuditOutcomeDryRun  = "dry_run"
+)
+
+// AuditRecord is one line of the audit log, written after each processed vehicle
+type AuditRecord struct {
+	Timestamp          time.Time          `json:"timestamp"`
+	CodigoAplicacao    int                `json:"codigo_aplicacao"`
+	Descricao          string             `json:"descricao"`
+	Category           string             `json:"category,omitempty"`
+	Outcome            string             `json:"outcome"`
+	MotulVehicleTypeID string             `json:"motul_vehicle_type_id,omitempty"`
+	MatchMethod        string             `json:"match_method,omitempty"`
+	UnknownYear        bool               `json:"unknown_year,omitempty"`     // No parseable year; searched without one
+	PromptTruncated    bool               `json:"prompt_truncated,omitempty"` // Description compacted for the LLM prompt
+	TimingsMs          map[string]float64 `json:"timings_ms"`
+	Error              string             `json:"error,omitempty"`
+}
+
+// AuditLogger appends AuditRecords as NDJSON to a file
+type AuditLogger struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewAuditLogger opens (or creates) the audit log for appending
+func NewAuditLogger(path string) (*AuditLogger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLogger{file: f}, nil
+}
+
+// Write appends a record
+func (a *AuditLogger) Write(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.file.Write(line)
+	return err
+}
+
+// Close closes the audit log
+func (a *AuditLogger) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
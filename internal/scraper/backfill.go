@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+
+	"wega-catalog-api/internal/model"
+)
+
+// NormaRepository lists and updates specs whose Norma was never filled
+type NormaRepository interface {
+	ListMissingNorma(ctx context.Context, afterID, limit int) ([]model.EspecificacaoTecnica, error)
+	UpdateNorma(ctx context.Context, id int, norma string) error
+}
+
+// NormaBackfillStats summarizes a Norma backfill run
+type NormaBackfillStats struct {
+	Rows     int // Specs without Norma examined
+	Updated  int // Specs that got a Norma
+	NotFound int // Motul returned no standards for the fluid
+	Failed   int // Spec fetch or update failed
+}
+
+// BackfillNorma re-fetches Motul specs for existing rows without Norma and fills
+// it in place. Specs are fetched once per Motul vehicle type within a batch.
+func BackfillNorma(ctx context.Context, repo NormaRepository, motul SpecProvider, batchSize int, logger *slog.Logger) (NormaBackfillStats, error) {
+	var stats NormaBackfillStats
+	afterID := 0
+
+	for {
+		rows, err := repo.ListMissingNorma(ctx, afterID, batchSize)
+		if err != nil {
+			return stats, err
+		}
+		if len(rows) == 0 {
+			return stats, nil
+		}
+
+		normas := make(map[string]map[string]string) // vehicle type ID -> TipoFluido/Condicao -> Norma
+		for _, row := range rows {
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			afterID = row.ID
+			stats.Rows++
+
+			typeID := derefString(row.MotulVehicleTypeID)
+			byFluid, ok := normas[typeID]
+			if !ok {
+				specs, err := motul.GetSpecifications(ctx, typeID)
+				if err != nil {
+					logger.Warn("failed to fetch specifications for backfill",
+						"id", row.ID,
+						"motul_id", typeID,
+						"error", err,
+					)
+					stats.Failed++
+					continue
+				}
+				byFluid = make(map[string]string, len(specs))
+				for _, spec := range specs {
+					byFluid[spec.TipoFluido+"/"+spec.Condicao] = spec.Norma
+				}
+				normas[typeID] = byFluid
+			}
+
+			norma := byFluid[model.TipoFluidoCode(row.TipoFluido)+"/"+row.Condicao]
+			if norma == "" {
+				stats.NotFound++
+				continue
+			}
+
+			if err := repo.UpdateNorma(ctx, row.ID, norma); err != nil {
+				logger.Warn("failed to update norma", "id", row.ID, "error", err)
+				stats.Failed++
+				continue
+			}
+			stats.Updated++
+		}
+
+		logger.Info("norma backfill progress",
+			"rows", stats.Rows,
+			"updated", stats.Updated,
+			"not_found", stats.NotFound,
+			"failed", stats.Failed,
+		)
+	}
+}
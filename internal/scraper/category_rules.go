@@ -0,0 +1,296 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/normalize"
+)
+
+// Built-in vehicle category keywords, used when no -skip-rules file replaces
+// them. Categories are checked in the order of defaultCategoryRules.
+var (
+	motorcycleBrands = []string{
+		"yamaha", "honda motos", "suzuki motos", "kawasaki", "harley",
+		"bmw motorrad", "ducati", "triumph", "ktm",
+	}
+
+	agriBrands = []string{
+		"case", "new holland", "massey ferguson", "john deere", "valtra",
+	}
+	agriPatterns = []string{
+		"trator", "colheitadeira", "retroescavadeira",
+		"mf ", "massey", "new holland", "case ih", "john deere",
+		"valtra", "ls tractor",
+	}
+
+	truckBrands = []string{
+		"scania", "daf", "man", "iveco",
+		"international", "navistar", "freightliner", "kenworth", "peterbilt",
+		"hino", "isuzu trucks", "ud trucks", "fuso",
+		"agrale", // Mostly trucks/buses
+	}
+	truckPatterns = []string{
+		// Truck model patterns (more generic)
+		"cargo", "constellation", "worker", "delivery",
+		"fh ", "fh-", "fm ", "fm-", "fmx", "vm ", "vm-", "nh12", "nh ", "edc",
+		"axor", "atego", "actros", "arocs",
+		"stralis", "trakker", "eurocargo",
+		"serie p", "serie g", "serie r", "serie s",
+		// Bus models
+		"of-", "o-", "volare", "busscar", "mascarello",
+		"marcopolo", "neobus", "caio", "comil",
+		// Specific commercial brands/series
+		"9200", "9800", "4700", "8600", // International trucks
+		"series ", "hr ", "hd ",
+		// Ford trucks (various formats)
+		"f-350", "f-4000", "f-14000", "f350", "f4000", "f14000",
+		"fb4000", "fb-4000", "f 4000", "fb 4000",
+		// Chevrolet/GM trucks
+		"d-20", "d20", "d-40", "d40", "d-60", "d60",
+		"c-10", "c10", "c-60", "c60", "c-15", "c15",
+		// VW trucks (numeric models)
+		"5.140", "6.80", "6.90", "7.90", "7.100", "7.110", "7.120",
+		"8.120", "8.140", "8.150", "8.160",
+		"9.150", "9.170", "10.160", "11.130", "11.180", "12.140", "13.150", "13.180",
+		"15.170", "15.180", "15.190", "16.200", "17.180", "17.190", "17.210", "17.220", "17.230", "17.250", "17.280", "17.310",
+		"18.310", "19.320", "19.330", "19.360", "19.390", "19.420",
+		"23.210", "23.220", "23.230", "23.250", "23.310", "24.250", "24.280", "24.310",
+		"25.320", "25.360", "25.370", "25.390", "25.420", "26.260", "26.280", "26.310",
+		"31.260", "31.280", "31.310", "31.320", "31.330", "31.370", "31.390", "31.420",
+		"furgovan", "kombi furgao",
+		// Agrale specific
+		"6000", "7000", "8000", "8500", "10000", "13000", "14000",
+	}
+
+	// Heavy equipment and stationary engines have no Motul category
+	equipmentBrands = []string{
+		"atlas copco", "caterpillar", "komatsu", "jcb", "bobcat",
+		"cummins", "perkins", "deutz", // Engines
+	}
+	equipmentPatterns = []string{
+		"escavadeira", "pa carregadeira", "motoniveladora",
+		"rolo compactador", "guindaste", "empilhadeira",
+		"compressor", "gerador",
+	}
+)
+
+// categoryUnsupported marks vehicles outside every Motul category (heavy equipment, engines)
+const categoryUnsupported = "UNSUPPORTED"
+
+// defaultCategoryRules maps keyword lists to vehicle categories, most specific first
+var defaultCategoryRules = []CategoryRule{
+	{Category: categoryUnsupported, Brands: equipmentBrands, Patterns: equipmentPatterns},
+	{Category: client.CategoryMotorcycle, Brands: motorcycleBrands},
+	{Category: client.CategoryAgri, Brands: agriBrands, Patterns: agriPatterns},
+	{Category: client.CategoryTruck, Brands: truckBrands, Patterns: truckPatterns},
+}
+
+// keywordText lowercases and strips accents but keeps spacing, so keywords
+// like "fh " only match whole words at their end
+var keywordText = normalize.New(normalize.StripAccents, normalize.Lower)
+
+// CategoryRule assigns Category to vehicles whose brand contains one of Brands,
+// or whose model and description contain one of Patterns or match one of Regexes
+type CategoryRule struct {
+	Category string   `json:"category"`
+	Brands   []string `json:"brands,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+	Regexes  []string `json:"regexes,omitempty"`
+}
+
+// SkipRule lists vehicles that are never scraped, whatever their category
+type SkipRule struct {
+	Brands   []string `json:"brands,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+	Regexes  []string `json:"regexes,omitempty"`
+}
+
+// FabricanteRule overrides the rules for one Wega fabricante
+type FabricanteRule struct {
+	Category     string   `json:"category,omitempty"`      // Forces the category of every vehicle
+	Skip         bool     `json:"skip,omitempty"`          // Skips every vehicle
+	SkipPatterns []string `json:"skip_patterns,omitempty"` // Skips vehicles whose model/description contain one
+	SkipRegexes  []string `json:"skip_regexes,omitempty"`
+}
+
+// CategoryRulesFile is the JSON file loaded with -skip-rules. Keywords are
+// compared without case or accents; regexes run on the lowercased,
+// accent-free model and description.
+type CategoryRulesFile struct {
+	Categories  []CategoryRule            `json:"categories,omitempty"` // Replaces the built-in rules when set
+	Skip        SkipRule                  `json:"skip"`
+	Fabricantes map[string]FabricanteRule `json:"fabricantes,omitempty"` // Keyed by Wega fabricante name
+}
+
+// keywordMatcher is a compiled brand/pattern/regex list
+type keywordMatcher struct {
+	brands   []string
+	patterns []string
+	regexes  []*regexp.Regexp
+}
+
+func newKeywordMatcher(brands, patterns, regexes []string) (keywordMatcher, error) {
+	k := keywordMatcher{}
+	for _, b := range brands {
+		k.brands = append(k.brands, keywordText.Apply(b))
+	}
+	for _, p := range patterns {
+		k.patterns = append(k.patterns, keywordText.Apply(p))
+	}
+	for _, expr := range regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return k, fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+		k.regexes = append(k.regexes, re)
+	}
+	return k, nil
+}
+
+// matchBrand reports whether brand (normalized) contains one of the brands
+func (k keywordMatcher) matchBrand(brand string) bool {
+	return slices.ContainsFunc(k.brands, func(b string) bool { return strings.Contains(brand, b) })
+}
+
+// matchText reports whether text (normalized) contains a pattern or matches a regex
+func (k keywordMatcher) matchText(text string) bool {
+	if slices.ContainsFunc(k.patterns, func(p string) bool { return strings.Contains(text, p) }) {
+		return true
+	}
+	return slices.ContainsFunc(k.regexes, func(re *regexp.Regexp) bool { return re.MatchString(text) })
+}
+
+type compiledCategoryRule struct {
+	category string
+	keywordMatcher
+}
+
+type compiledFabricanteRule struct {
+	category string
+	skip     bool
+	skipText keywordMatcher
+}
+
+// CategoryRules classifies Wega vehicles into Motul vehicle categories and
+// decides which are skipped. Safe for concurrent use; reloads swap the whole value.
+type CategoryRules struct {
+	categories  []compiledCategoryRule
+	skip        keywordMatcher
+	fabricantes map[string]compiledFabricanteRule // Keyed by normalize.Key of the fabricante
+}
+
+// DefaultCategoryRules returns the built-in rules, with no skip list
+func DefaultCategoryRules() *CategoryRules {
+	rules, err := CompileCategoryRules(CategoryRulesFile{Categories: defaultCategoryRules})
+	if err != nil {
+		panic(err) // Built-in keywords have no regexes and always compile
+	}
+	return rules
+}
+
+// LoadCategoryRules reads and compiles a JSON rules file
+func LoadCategoryRules(path string) (*CategoryRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file CategoryRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	return CompileCategoryRules(file)
+}
+
+// CompileCategoryRules validates categories and compiles regexes. Without
+// categories the built-in category rules are used.
+func CompileCategoryRules(file CategoryRulesFile) (*CategoryRules, error) {
+	if len(file.Categories) == 0 {
+		file.Categories = defaultCategoryRules
+	}
+
+	rules := &CategoryRules{fabricantes: make(map[string]compiledFabricanteRule, len(file.Fabricantes))}
+	for _, rule := range file.Categories {
+		category, err := ruleCategory(rule.Category)
+		if err != nil {
+			return nil, err
+		}
+		k, err := newKeywordMatcher(rule.Brands, rule.Patterns, rule.Regexes)
+		if err != nil {
+			return nil, fmt.Errorf("category %s: %w", category, err)
+		}
+		rules.categories = append(rules.categories, compiledCategoryRule{category: category, keywordMatcher: k})
+	}
+
+	skip, err := newKeywordMatcher(file.Skip.Brands, file.Skip.Patterns, file.Skip.Regexes)
+	if err != nil {
+		return nil, fmt.Errorf("skip: %w", err)
+	}
+	rules.skip = skip
+
+	for name, rule := range file.Fabricantes {
+		compiled := compiledFabricanteRule{skip: rule.Skip}
+		if rule.Category != "" {
+			if compiled.category, err = ruleCategory(rule.Category); err != nil {
+				return nil, fmt.Errorf("fabricante %s: %w", name, err)
+			}
+		}
+		if compiled.skipText, err = newKeywordMatcher(nil, rule.SkipPatterns, rule.SkipRegexes); err != nil {
+			return nil, fmt.Errorf("fabricante %s: %w", name, err)
+		}
+		rules.fabricantes[normalize.Key.Apply(name)] = compiled
+	}
+
+	return rules, nil
+}
+
+// ruleCategory validates a category name of a rules file
+func ruleCategory(category string) (string, error) {
+	category = strings.ToUpper(strings.TrimSpace(category))
+	if category != categoryUnsupported && !client.IsCategory(category) {
+		return "", fmt.Errorf("unknown category %q (use %s or %s)", category, strings.Join(client.Categories, ", "), categoryUnsupported)
+	}
+	return category, nil
+}
+
+// Classify returns the Motul vehicle category of a Wega vehicle
+// (client.CategoryCar when no keyword matches) and whether it is skipped
+func (r *CategoryRules) Classify(brand, model, description string) (category string, skip bool) {
+	brandLower := normalize.Text.Apply(brand)
+	combined := normalize.Text.Apply(model + " " + description)
+
+	fabricante, hasOverride := r.fabricantes[normalize.Key.Apply(brand)]
+	if hasOverride && (fabricante.skip || fabricante.skipText.matchText(combined)) {
+		skip = true
+	}
+	if r.skip.matchBrand(brandLower) || r.skip.matchText(combined) {
+		skip = true
+	}
+
+	if hasOverride && fabricante.category != "" {
+		return fabricante.category, skip
+	}
+
+	// Check brands first: a truck brand's model names are often generic
+	for _, rule := range r.categories {
+		if rule.matchBrand(brandLower) {
+			return rule.category, skip
+		}
+	}
+
+	// Then model patterns and regexes
+	for _, rule := range r.categories {
+		if rule.matchText(combined) {
+			return rule.category, skip
+		}
+	}
+
+	return client.CategoryCar, skip
+}
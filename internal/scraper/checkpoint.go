@@ -1,41 +1,50 @@
 package scraper
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"time"
+
+	"wega-catalog-api/internal/model"
 )
 
 // Checkpoint represents saved scraper state
-type Checkpoint struct {
-	LastProcessedID int       `json:"last_processed_id"`
-	StartedAt       time.Time `json:"started_at"`
-	SavedAt         time.Time `json:"saved_at"`
-	Stats           struct {
-		Success int `json:"success"`
-		Failed  int `json:"failed"`
-		Skipped int `json:"skipped"`
-	} `json:"stats"`
+type Checkpoint = model.ScraperCheckpoint
+
+// CheckpointStore persists one checkpoint per run ID
+type CheckpointStore interface {
+	Load(ctx context.Context, runID string) (*Checkpoint, error) // nil when no checkpoint exists
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+	Delete(ctx context.Context, runID string) error
 }
 
 // CheckpointManager handles saving and loading scraper state
 type CheckpointManager struct {
-	filePath string
+	store CheckpointStore
+	runID string
 }
 
-// NewCheckpointManager creates a new checkpoint manager
+// NewCheckpointManager creates a checkpoint manager backed by a local JSON file
 func NewCheckpointManager(filePath string) *CheckpointManager {
+	return NewCheckpointManagerWithStore(NewFileCheckpointStore(filePath), "")
+}
+
+// NewCheckpointManagerWithStore creates a checkpoint manager for runID on store
+func NewCheckpointManagerWithStore(store CheckpointStore, runID string) *CheckpointManager {
 	return &CheckpointManager{
-		filePath: filePath,
+		store: store,
+		runID: runID,
 	}
 }
 
 // Save saves the current checkpoint
-func (c *CheckpointManager) Save(lastID int, progress *ProgressTracker) error {
+func (c *CheckpointManager) Save(ctx context.Context, lastID int, progress *ProgressTracker) error {
 	snapshot := progress.GetSnapshot()
 
-	checkpoint := Checkpoint{
+	checkpoint := &Checkpoint{
+		RunID:           c.runID,
 		LastProcessedID: lastID,
 		StartedAt:       snapshot.StartedAt,
 		SavedAt:         time.Now(),
@@ -44,21 +53,33 @@ func (c *CheckpointManager) Save(lastID int, progress *ProgressTracker) error {
 	checkpoint.Stats.Failed = snapshot.Failed
 	checkpoint.Stats.Skipped = snapshot.Skipped
 
-	data, err := json.MarshalIndent(checkpoint, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal checkpoint: %w", err)
-	}
+	return c.store.Save(ctx, checkpoint)
+}
 
-	if err := os.WriteFile(c.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write checkpoint file: %w", err)
-	}
+// Load loads the checkpoint if it exists (nil otherwise)
+func (c *CheckpointManager) Load(ctx context.Context) (*Checkpoint, error) {
+	return c.store.Load(ctx, c.runID)
+}
 
-	return nil
+// Delete removes the checkpoint
+func (c *CheckpointManager) Delete(ctx context.Context) error {
+	return c.store.Delete(ctx, c.runID)
+}
+
+// FileCheckpointStore keeps the checkpoint in a local JSON file. It is used when
+// the scraper runs without a database; the run ID is recorded but not used as a key.
+type FileCheckpointStore struct {
+	filePath string
+}
+
+// NewFileCheckpointStore creates a file checkpoint store
+func NewFileCheckpointStore(filePath string) *FileCheckpointStore {
+	return &FileCheckpointStore{filePath: filePath}
 }
 
-// Load loads the checkpoint if it exists
-func (c *CheckpointManager) Load() (*Checkpoint, error) {
-	data, err := os.ReadFile(c.filePath)
+// Load implements CheckpointStore
+func (f *FileCheckpointStore) Load(ctx context.Context, runID string) (*Checkpoint, error) {
+	data, err := os.ReadFile(f.filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil // No checkpoint exists
@@ -74,16 +95,24 @@ func (c *CheckpointManager) Load() (*Checkpoint, error) {
 	return &checkpoint, nil
 }
 
-// Delete removes the checkpoint file
-func (c *CheckpointManager) Delete() error {
-	if err := os.Remove(c.filePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete checkpoint file: %w", err)
+// Save implements CheckpointStore
+func (f *FileCheckpointStore) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
+
+	if err := os.WriteFile(f.filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
 	return nil
 }
 
-// Exists checks if checkpoint file exists
-func (c *CheckpointManager) Exists() bool {
-	_, err := os.Stat(c.filePath)
-	return err == nil
+// Delete implements CheckpointStore
+func (f *FileCheckpointStore) Delete(ctx context.Context, runID string) error {
+	if err := os.Remove(f.filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete checkpoint file: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,192 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errWorkerRetired is returned by RunControl.Wait to a worker above the worker
+// count once no more vehicles will be queued, so it can exit
+var errWorkerRetired = errors.New("worker retired")
+
+// RunControl holds the operator controls of a running scrape (pause, abort,
+// rate limit, worker count), changed through the HTTP monitor and honored by
+// the workers between vehicles
+type RunControl struct {
+	mu        sync.Mutex
+	paused    bool
+	aborted   bool
+	workers   int           // Workers allowed to take vehicles
+	started   int           // Worker goroutines started so far
+	rateLimit time.Duration // Delay between vehicles per worker
+	changed   chan struct{} // Closed and replaced on every change
+	feeding   bool          // Vehicles may still be queued
+	spawn     func(id int)  // Starts another worker while feeding
+	abort     context.CancelFunc
+}
+
+// RunControlState is the control state reported by the HTTP monitor
+type RunControlState struct {
+	Paused    bool   `json:"paused"`
+	Aborted   bool   `json:"aborted"`
+	Workers   int    `json:"workers"`
+	RateLimit string `json:"rate_limit"`
+}
+
+// NewRunControl creates the controls with the configured worker count and rate limit
+func NewRunControl(workers int, rateLimit time.Duration) *RunControl {
+	return &RunControl{
+		workers:   max(workers, 1),
+		rateLimit: rateLimit,
+		changed:   make(chan struct{}),
+	}
+}
+
+// setAbort sets the function that cancels the run context
+func (c *RunControl) setAbort(abort context.CancelFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.abort = abort
+}
+
+// start starts the configured number of workers with spawn, which starts worker id
+func (c *RunControl) start(spawn func(id int)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.spawn = spawn
+	c.feeding = true
+	c.started = 0
+	c.spawnLocked()
+}
+
+// stopFeeding is called before the work queue is closed: no more workers are
+// started, and workers above the worker count exit instead of waiting
+func (c *RunControl) stopFeeding() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.feeding = false
+	c.spawn = nil
+	c.broadcastLocked()
+}
+
+// spawnLocked starts workers up to the worker count (caller holds mu)
+func (c *RunControl) spawnLocked() {
+	for c.spawn != nil && c.started < c.workers {
+		c.spawn(c.started)
+		c.started++
+	}
+}
+
+func (c *RunControl) broadcastLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Wait blocks worker id while the run is paused or the worker is above the
+// worker count. It returns errWorkerRetired when a parked worker is no longer
+// needed, or ctx.Err() when the run is cancelled.
+func (c *RunControl) Wait(ctx context.Context, id int) error {
+	for {
+		c.mu.Lock()
+		if !c.paused && id < c.workers {
+			c.mu.Unlock()
+			return nil
+		}
+		if id >= c.workers && !c.feeding {
+			c.mu.Unlock()
+			return errWorkerRetired
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Pause stops workers from taking new vehicles; in-flight vehicles finish.
+// It returns false if the run was already paused.
+func (c *RunControl) Pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		return false
+	}
+	c.paused = true
+	c.broadcastLocked()
+	return true
+}
+
+// Resume lets workers take vehicles again. It returns false if the run was not paused.
+func (c *RunControl) Resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		return false
+	}
+	c.paused = false
+	c.broadcastLocked()
+	return true
+}
+
+// Abort cancels the run as on SIGINT: workers stop, the checkpoint is saved
+// and queue claims are released. It returns false if the run was not started.
+func (c *RunControl) Abort() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.abort == nil {
+		return false
+	}
+	c.aborted = true
+	c.abort()
+	return true
+}
+
+// SetRateLimit changes the delay between vehicles of each worker
+func (c *RunControl) SetRateLimit(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = d
+}
+
+// RateLimit returns the current delay between vehicles of each worker
+func (c *RunControl) RateLimit() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+// SetWorkers changes how many workers take vehicles (at least 1). Extra
+// workers are started while vehicles are still being queued; surplus workers
+// finish their current vehicle and wait.
+func (c *RunControl) SetWorkers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.workers = max(n, 1)
+	c.spawnLocked()
+	c.broadcastLocked()
+}
+
+// State returns the current control state
+func (c *RunControl) State() RunControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return RunControlState{
+		Paused:    c.paused,
+		Aborted:   c.aborted,
+		Workers:   c.workers,
+		RateLimit: c.rateLimit.String(),
+	}
+}
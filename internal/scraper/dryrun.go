@@ -0,0 +1,191 @@
+package scraper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/normalize"
+)
+
+// Dry-run decisions recorded per vehicle
+const (
+	DryRunWouldSearch    = "would_search"    // Would be searched in the provider
+	DryRunParseFailed    = "parse_failed"    // Brand/model could not be parsed from the description
+	DryRunRuleSkip       = "rule_skip"       // On the -skip-rules skip list
+	DryRunCategorySkip   = "category_skip"   // Category not enabled in this run
+	DryRunAlreadyScraped = "already_scraped" // Fresh specs already stored
+)
+
+// DryRunVehicle is one vehicle of the dry-run report
+type DryRunVehicle struct {
+	CodigoAplicacao int    `json:"codigo_aplicacao"`
+	Descricao       string `json:"descricao"`
+	Decision        string `json:"decision"`
+	Brand           string `json:"brand,omitempty"`
+	Model           string `json:"model,omitempty"`
+	Year            int    `json:"year,omitempty"` // 0 = no parseable year
+	Category        string `json:"category,omitempty"`
+	Commercial      bool   `json:"commercial"` // Classified as a truck or bus
+	ParseError      string `json:"parse_error,omitempty"`
+}
+
+// DryRunEstimate is the number of external calls a real run would make for
+// the vehicles that would be searched
+type DryRunEstimate struct {
+	ProviderSearches int `json:"provider_searches"` // One vehicle search per vehicle
+	SpecFetches      int `json:"spec_fetches"`      // At most one spec request per matched vehicle
+	// Upper bound: one brand call per distinct brand, one model call per
+	// distinct brand/model and one type call per vehicle. Exact, alias,
+	// similarity and embedding passes resolve most of them without the LLM.
+	LLMRequestsMax int `json:"llm_requests_max"`
+	Brands         int `json:"distinct_brands"`
+	Models         int `json:"distinct_models"`
+}
+
+// DryRunSummary aggregates the dry-run report
+type DryRunSummary struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Provider    string         `json:"provider"`
+	Total       int            `json:"total"`
+	Parsed      int            `json:"parsed"`
+	ParseFailed int            `json:"parse_failed"` // No brand/model parsed, whatever the decision
+	UnknownYear int            `json:"unknown_year"`
+	Commercial  int            `json:"commercial"`
+	Decisions   map[string]int `json:"decisions"`
+	Categories  map[string]int `json:"categories"`
+	Estimate    DryRunEstimate `json:"estimate"`
+}
+
+// DryRunReport collects what a dry run would do and writes it as JSON, or as
+// CSV when the path ends in .csv
+type DryRunReport struct {
+	mu       sync.Mutex
+	path     string
+	provider string
+	vehicles []DryRunVehicle
+}
+
+// NewDryRunReport creates a report written to path on Write
+func NewDryRunReport(path, provider string) *DryRunReport {
+	return &DryRunReport{path: path, provider: provider}
+}
+
+// Add records one vehicle
+func (r *DryRunReport) Add(v DryRunVehicle) {
+	v.Commercial = v.Category == client.CategoryTruck
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vehicles = append(r.vehicles, v)
+}
+
+// Summary aggregates the vehicles recorded so far
+func (r *DryRunReport) Summary() DryRunSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := DryRunSummary{
+		GeneratedAt: time.Now(),
+		Provider:    r.provider,
+		Total:       len(r.vehicles),
+		Decisions:   make(map[string]int),
+		Categories:  make(map[string]int),
+	}
+	brands := make(map[string]bool)
+	models := make(map[string]bool)
+	for _, v := range r.vehicles {
+		summary.Decisions[v.Decision]++
+		// Unparseable vehicles can also be already scraped; they never count as parsed
+		if v.Decision == DryRunParseFailed || v.ParseError != "" {
+			summary.ParseFailed++
+			continue
+		}
+		summary.Parsed++
+		summary.Categories[v.Category]++
+		if v.Year == 0 {
+			summary.UnknownYear++
+		}
+		if v.Commercial {
+			summary.Commercial++
+		}
+		if v.Decision != DryRunWouldSearch {
+			continue
+		}
+		// Matcher caches are per category and keyed by normalized names
+		brand := v.Category + "|" + normalize.Key.Apply(v.Brand)
+		brands[brand] = true
+		models[brand+"|"+normalize.Key.Apply(v.Model)] = true
+		summary.Estimate.ProviderSearches++
+	}
+
+	summary.Estimate.SpecFetches = summary.Estimate.ProviderSearches
+	summary.Estimate.Brands = len(brands)
+	summary.Estimate.Models = len(models)
+	summary.Estimate.LLMRequestsMax = len(brands) + len(models) + summary.Estimate.ProviderSearches
+	return summary
+}
+
+// Write writes the report, vehicles sorted by ID
+func (r *DryRunReport) Write() error {
+	summary := r.Summary()
+
+	r.mu.Lock()
+	vehicles := append([]DryRunVehicle(nil), r.vehicles...)
+	r.mu.Unlock()
+	sort.Slice(vehicles, func(i, j int) bool {
+		return vehicles[i].CodigoAplicacao < vehicles[j].CodigoAplicacao
+	})
+
+	f, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to create dry-run report: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(r.path), ".csv") {
+		err = writeDryRunCSV(f, vehicles)
+	} else {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(struct {
+			Summary  DryRunSummary   `json:"summary"`
+			Vehicles []DryRunVehicle `json:"vehicles"`
+		}{summary, vehicles})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write dry-run report: %w", err)
+	}
+	return f.Close()
+}
+
+// writeDryRunCSV writes one row per vehicle; the summary is only in the JSON form
+func writeDryRunCSV(f *os.File, vehicles []DryRunVehicle) error {
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{
+		"codigo_aplicacao", "descricao", "decision", "brand", "model", "year", "category", "commercial", "parse_error",
+	}); err != nil {
+		return err
+	}
+	for _, v := range vehicles {
+		year := ""
+		if v.Year > 0 {
+			year = strconv.Itoa(v.Year)
+		}
+		if err := w.Write([]string{
+			strconv.Itoa(v.CodigoAplicacao), v.Descricao, v.Decision, v.Brand, v.Model,
+			year, v.Category, strconv.FormatBool(v.Commercial), v.ParseError,
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
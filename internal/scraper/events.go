@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Vehicle event types streamed by the monitor's /events endpoint
+const (
+	EventMatched = "matched" // Specs found (or would be, in dry-run)
+	EventFailed  = "failed"  // Error or no Motul match
+	EventSkipped = "skipped" // Already scraped, outside enabled categories or unparseable
+)
+
+// eventHistory is how many past events are kept for Last-Event-ID replay
+const eventHistory = 256
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before it starts missing events
+const subscriberBuffer = 256
+
+// VehicleEvent is one processed vehicle: its audit record plus an event type
+// and a sequence number. Gaps in ID mean the subscriber missed events.
+type VehicleEvent struct {
+	ID   uint64 `json:"id"`
+	Type string `json:"type"`
+	AuditRecord
+}
+
+// eventType maps an audit outcome to an event type
+func eventType(outcome string) string {
+	switch outcome {
+	case AuditOutcomeSuccess, AuditOutcomeDryRun:
+		return EventMatched
+	case AuditOutcomeSkipped:
+		return EventSkipped
+	default:
+		return EventFailed
+	}
+}
+
+// EventHub fans out vehicle events to subscribers without ever blocking the
+// workers: a subscriber whose buffer is full misses the event
+type EventHub struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []VehicleEvent // Ring buffer of the last eventHistory events
+	subscribers map[chan VehicleEvent]struct{}
+
+	dropped atomic.Int64
+}
+
+// NewEventHub creates an event hub
+func NewEventHub() *EventHub {
+	return &EventHub{
+		history:     make([]VehicleEvent, 0, eventHistory),
+		subscribers: make(map[chan VehicleEvent]struct{}),
+	}
+}
+
+// Publish sends the outcome of a processed vehicle to every subscriber
+func (h *EventHub) Publish(record AuditRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event := VehicleEvent{
+		ID:          h.nextID,
+		Type:        eventType(record.Outcome),
+		AuditRecord: record,
+	}
+
+	if len(h.history) < eventHistory {
+		h.history = append(h.history, event)
+	} else {
+		h.history[(event.ID-1)%eventHistory] = event
+	}
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			h.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe returns the buffered events after afterID (0 = none), a channel of
+// new events and a function to unsubscribe
+func (h *EventHub) Subscribe(afterID uint64) ([]VehicleEvent, <-chan VehicleEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []VehicleEvent
+	if afterID > 0 {
+		// Walk the ring oldest first; once full, the oldest event follows the newest
+		oldest := 0
+		if len(h.history) == eventHistory {
+			oldest = int(h.nextID % eventHistory)
+		}
+		for i := range h.history {
+			event := h.history[(oldest+i)%len(h.history)]
+			if event.ID > afterID {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	ch := make(chan VehicleEvent, subscriberBuffer)
+	h.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, ch)
+	}
+	return backlog, ch, unsubscribe
+}
+
+// Dropped returns how many events slow subscribers have missed
+func (h *EventHub) Dropped() int64 {
+	return h.dropped.Load()
+}
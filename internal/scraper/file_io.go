@@ -0,0 +1,244 @@
+package scraper
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// csvInputColumns are the required columns of a CSV vehicle input file
+var csvInputColumns = []string{"id", "marca", "modelo", "descricao", "ano"}
+
+// CSVVehicleSource reads vehicles from a CSV file instead of the Wega database
+// Expected header: id,marca,modelo,descricao,ano (any order, extra columns ignored)
+type CSVVehicleSource struct {
+	path string
+}
+
+// NewCSVVehicleSource creates a vehicle source backed by a CSV file
+func NewCSVVehicleSource(path string) *CSVVehicleSource {
+	return &CSVVehicleSource{path: path}
+}
+
+// GetAllVehicles implements VehicleRepository
+func (s *CSVVehicleSource) GetAllVehicles(ctx context.Context) ([]model.Aplicacao, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input header: %w", err)
+	}
+
+	idx := make(map[string]int, len(header))
+	for i, col := range header {
+		idx[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")))] = i
+	}
+	for _, col := range csvInputColumns {
+		if _, ok := idx[col]; !ok {
+			return nil, fmt.Errorf("input is missing column %q", col)
+		}
+	}
+
+	var vehicles []model.Aplicacao
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input line %d: %w", line, err)
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(record[idx["id"]]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid id on line %d: %w", line, err)
+		}
+
+		vehicles = append(vehicles, model.Aplicacao{
+			CodigoAplicacao:    id,
+			Fabricante:         strings.TrimSpace(record[idx["marca"]]),
+			Modelo:             strings.TrimSpace(record[idx["modelo"]]),
+			DescricaoAplicacao: strings.TrimSpace(record[idx["descricao"]]),
+			Ano:                strings.TrimSpace(record[idx["ano"]]),
+		})
+	}
+
+	// Keep the same ordering as the database source so checkpoints work
+	sort.Slice(vehicles, func(i, j int) bool {
+		return vehicles[i].CodigoAplicacao < vehicles[j].CodigoAplicacao
+	})
+
+	return vehicles, nil
+}
+
+// GetVehicleByID implements VehicleRepository
+func (s *CSVVehicleSource) GetVehicleByID(ctx context.Context, id int) (*model.Aplicacao, error) {
+	vehicles, err := s.GetAllVehicles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vehicles {
+		if vehicles[i].CodigoAplicacao == id {
+			return &vehicles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("vehicle %d not found in input", id)
+}
+
+// FileSpecWriter collects specifications in memory and writes them to a CSV or
+// JSON file (chosen by extension) instead of Postgres
+type FileSpecWriter struct {
+	path  string
+	mu    sync.Mutex
+	specs map[string]*model.EspecificacaoTecnica // codigoAplicacao:tipoFluido:condicao -> spec
+}
+
+// NewFileSpecWriter creates a spec writer that flushes to path
+func NewFileSpecWriter(path string) *FileSpecWriter {
+	return &FileSpecWriter{
+		path:  path,
+		specs: make(map[string]*model.EspecificacaoTecnica),
+	}
+}
+
+// Upsert implements SpecSink
+func (w *FileSpecWriter) Upsert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	key := fmt.Sprintf("%d:%s:%s", spec.CodigoAplicacao, spec.TipoFluido, spec.Condicao)
+	if existing, ok := w.specs[key]; ok {
+		spec.CriadoEm = existing.CriadoEm
+	} else {
+		spec.CriadoEm = now
+	}
+	spec.AtualizadoEm = now
+
+	stored := *spec
+	w.specs[key] = &stored
+	return nil
+}
+
+// ExistsForVehicle implements SpecSink
+func (w *FileSpecWriter) ExistsForVehicle(ctx context.Context, codigoAplicacao int) (bool, error) {
+	t, err := w.LastUpdatedForVehicle(ctx, codigoAplicacao)
+	return t != nil, err
+}
+
+// LastUpdatedForVehicle implements SpecSink
+func (w *FileSpecWriter) LastUpdatedForVehicle(ctx context.Context, codigoAplicacao int) (*time.Time, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var last *time.Time
+	for _, spec := range w.specs {
+		if spec.CodigoAplicacao != codigoAplicacao {
+			continue
+		}
+		if last == nil || spec.AtualizadoEm.After(*last) {
+			t := spec.AtualizadoEm
+			last = &t
+		}
+	}
+	return last, nil
+}
+
+// Flush writes every collected spec to the output file
+func (w *FileSpecWriter) Flush() error {
+	w.mu.Lock()
+	specs := make([]model.EspecificacaoTecnica, 0, len(w.specs))
+	for _, spec := range w.specs {
+		specs = append(specs, *spec)
+	}
+	w.mu.Unlock()
+
+	sort.Slice(specs, func(i, j int) bool {
+		if specs[i].CodigoAplicacao != specs[j].CodigoAplicacao {
+			return specs[i].CodigoAplicacao < specs[j].CodigoAplicacao
+		}
+		if specs[i].TipoFluido != specs[j].TipoFluido {
+			return specs[i].TipoFluido < specs[j].TipoFluido
+		}
+		return specs[i].Condicao < specs[j].Condicao
+	})
+
+	f, err := os.Create(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(w.path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(specs)
+	}
+
+	cw := csv.NewWriter(f)
+	if err := cw.Write([]string{
+		"id", "tipo_fluido", "condicao", "viscosidade", "capacidade", "norma",
+		"recomendacao", "observacao", "intervalo_troca_km", "intervalo_troca_meses",
+		"motul_vehicle_type_id", "match_confidence",
+	}); err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		confidence := ""
+		if spec.MatchConfidence != nil {
+			confidence = strconv.FormatFloat(*spec.MatchConfidence, 'f', 2, 64)
+		}
+		if err := cw.Write([]string{
+			strconv.Itoa(spec.CodigoAplicacao),
+			spec.TipoFluido,
+			spec.Condicao,
+			derefString(spec.Viscosidade),
+			derefString(spec.Capacidade),
+			derefString(spec.Norma),
+			derefString(spec.Recomendacao),
+			derefString(spec.Observacao),
+			formatIntPtr(spec.IntervaloTrocaKm),
+			formatIntPtr(spec.IntervaloTrocaMeses),
+			derefString(spec.MotulVehicleTypeID),
+			confidence,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatIntPtr formats the pointed int or returns "" for nil
+func formatIntPtr(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// derefString returns the pointed string or "" for nil
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -2,17 +2,55 @@ package scraper
 
 import (
 	"context"
+	"crypto/subtle"
+	_ "embed"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// dashboardHTML is the live dashboard served at /; it follows /status/stream
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// statusInterval is how often /status/stream pushes a status update
+const statusInterval = 2 * time.Second
+
+// keepAliveInterval is how often an idle /events stream sends a comment so
+// proxies keep the connection open
+const keepAliveInterval = 15 * time.Second
+
 // HTTPMonitor provides HTTP endpoints for monitoring scraper progress
 type HTTPMonitor struct {
-	server   *http.Server
-	progress *ProgressTracker
+	server      *http.Server
+	mux         *http.ServeMux
+	progress    *ProgressTracker
+	successRate *SuccessRateMonitor
+	rateSource  RateSource
+	keyHealth   KeyHealthSource
+	events      *EventHub
+	done        chan struct{} // Closed by Stop to end SSE streams
+
+	control      *RunControl
+	controlToken string // Bearer token required by /resume, /control/* and /debug/* ("" = loopback only)
+}
+
+// RateSource reports the current effective request rate of an adaptive client
+type RateSource interface {
+	EffectiveRate() float64
+}
+
+// KeyHealthSource reports the health of an LLM client's API keys
+type KeyHealthSource interface {
+	GetKeyStatus() map[string]interface{}
 }
 
 // NewHTTPMonitor creates a new HTTP monitoring server
@@ -24,15 +62,120 @@ func NewHTTPMonitor(port int, progress *ProgressTracker) *HTTPMonitor {
 			Addr:    fmt.Sprintf(":%d", port),
 			Handler: mux,
 		},
+		mux:      mux,
 		progress: progress,
+		done:     make(chan struct{}),
 	}
 
+	mux.HandleFunc("/{$}", monitor.handleDashboard)
 	mux.HandleFunc("/status", monitor.handleStatus)
+	mux.HandleFunc("/status/stream", monitor.handleStatusStream)
+	mux.HandleFunc("/events", monitor.handleEvents)
 	mux.HandleFunc("/health", monitor.handleHealth)
+	mux.HandleFunc("/llm-status", monitor.handleLLMStatus)
+	mux.HandleFunc("/resume", monitor.handleResume)
+	mux.HandleFunc("/control/pause", monitor.handleControl(monitor.controlPause))
+	mux.HandleFunc("/control/resume", monitor.handleControl(monitor.controlResume))
+	mux.HandleFunc("/control/abort", monitor.handleControl(monitor.controlAbort))
+	mux.HandleFunc("/control/rate-limit", monitor.handleControl(monitor.controlRateLimit))
+	mux.HandleFunc("/control/workers", monitor.handleControl(monitor.controlWorkers))
 
 	return monitor
 }
 
+// SetSuccessRateMonitor exposes the success-rate guard's pause state and /resume
+func (m *HTTPMonitor) SetSuccessRateMonitor(successRate *SuccessRateMonitor) {
+	m.successRate = successRate
+}
+
+// SetControl exposes the run controls under POST /control/*
+func (m *HTTPMonitor) SetControl(control *RunControl) {
+	m.control = control
+}
+
+// SetControlToken requires "Authorization: Bearer <token>" on /resume, /control/* and /debug/*
+func (m *HTTPMonitor) SetControlToken(token string) {
+	m.controlToken = token
+}
+
+// EnableDebug serves the Go runtime profiles under /debug/pprof/ and the
+// expvar variables (memstats) under /debug/vars, to diagnose memory and
+// goroutine growth during long runs. Call it after SetControlToken and before
+// Start; it refuses to run without a token. The command line is never served
+// (neither /debug/pprof/cmdline nor the cmdline expvar): it carries secrets
+// passed as flags, such as -db-password.
+func (m *HTTPMonitor) EnableDebug() error {
+	if m.controlToken == "" {
+		return fmt.Errorf("debug endpoints require a control token")
+	}
+	m.mux.Handle("/debug/pprof/", m.requireToken(http.HandlerFunc(pprof.Index)))
+	m.mux.Handle("/debug/pprof/profile", m.requireToken(http.HandlerFunc(pprof.Profile)))
+	m.mux.Handle("/debug/pprof/symbol", m.requireToken(http.HandlerFunc(pprof.Symbol)))
+	m.mux.Handle("/debug/pprof/trace", m.requireToken(http.HandlerFunc(pprof.Trace)))
+	m.mux.Handle("/debug/vars", m.requireToken(http.HandlerFunc(handleExpvar)))
+	return nil
+}
+
+// handleExpvar writes the expvar variables like expvar.Handler, minus cmdline
+func handleExpvar(w http.ResponseWriter, r *http.Request) {
+	vars := make(map[string]json.RawMessage)
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key != "cmdline" {
+			vars[kv.Key] = json.RawMessage(kv.Value.String())
+		}
+	})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(vars)
+}
+
+// requireToken rejects requests without the control token, when one is set
+func (m *HTTPMonitor) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized reports whether the request carries the control token. Without
+// a token only loopback clients are accepted: the monitor listens on every
+// interface, and anyone on the network could otherwise stop or throttle a run.
+func (m *HTTPMonitor) authorized(r *http.Request) bool {
+	if m.controlToken == "" {
+		return isLoopback(r)
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(m.controlToken)) == 1
+}
+
+// isLoopback reports whether the request came from this host
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// SetRateSource exposes the Motul client's effective (adaptive) request rate
+func (m *HTTPMonitor) SetRateSource(source RateSource) {
+	m.rateSource = source
+}
+
+// SetKeyHealthSource exposes the Groq key health on /status, /llm-status and the dashboard
+func (m *HTTPMonitor) SetKeyHealthSource(source KeyHealthSource) {
+	m.keyHealth = source
+}
+
+// SetEventHub streams the hub's per-vehicle events on /events
+func (m *HTTPMonitor) SetEventHub(events *EventHub) {
+	m.events = events
+}
+
 // Start starts the HTTP server in a goroutine
 func (m *HTTPMonitor) Start() error {
 	go func() {
@@ -47,12 +190,161 @@ func (m *HTTPMonitor) Start() error {
 // Stop gracefully stops the HTTP server
 func (m *HTTPMonitor) Stop(ctx context.Context) error {
 	slog.Info("Stopping HTTP monitor")
+	// Shutdown waits for active connections, so end the SSE streams first
+	close(m.done)
 	return m.server.Shutdown(ctx)
 }
 
 // handleStatus returns current scraper status as JSON
 func (m *HTTPMonitor) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.status())
+}
+
+// handleDashboard serves the embedded live dashboard
+func (m *HTTPMonitor) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHTML)
+}
+
+// startStream sets the server-sent event headers; it fails when the
+// connection cannot be flushed
+func startStream(w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	return flusher, true
+}
+
+// writeEvent writes one server-sent event with a JSON payload (id 0 = none)
+func writeEvent(w http.ResponseWriter, id uint64, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+// handleEvents streams per-vehicle events (matched, failed, skipped) until the
+// client disconnects or the server shuts down. A reconnecting client sending
+// Last-Event-ID (or ?since=<id>) first gets the buffered events it missed.
+func (m *HTTPMonitor) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if m.events == nil {
+		http.Error(w, "events not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("since")
+	}
+	var afterID uint64
+	if lastID != "" {
+		id, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid event id", http.StatusBadRequest)
+			return
+		}
+		afterID = id
+	}
+
+	flusher, ok := startStream(w)
+	if !ok {
+		return
+	}
+
+	backlog, events, unsubscribe := m.events.Subscribe(afterID)
+	defer unsubscribe()
+
+	for _, event := range backlog {
+		if err := writeEvent(w, event.ID, event.Type, event); err != nil {
+			return
+		}
+	}
+	// Open the stream even when there is nothing to replay yet
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			if err := writeEvent(w, event.ID, event.Type, event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-m.done:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// handleStatusStream streams the /status document every statusInterval until
+// the client disconnects or the server shuts down
+func (m *HTTPMonitor) handleStatusStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := startStream(w)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := writeEvent(w, 0, "status", m.status()); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// status builds the document served by /status and /events
+func (m *HTTPMonitor) status() map[string]interface{} {
 	snapshot := m.progress.GetSnapshot()
+	if m.successRate != nil && m.successRate.Paused() {
+		snapshot.Status = "paused"
+	}
+	if m.control != nil && m.control.State().Paused {
+		snapshot.Status = "paused"
+	}
+
+	rate := map[string]interface{}{
+		"current_rps":          fmt.Sprintf("%.2f", snapshot.RequestsPerSec),
+		"avg_time_per_vehicle": fmt.Sprintf("%.2fs", snapshot.AvgTimePerVehicle),
+	}
+	if m.rateSource != nil {
+		rate["motul_effective_rps"] = fmt.Sprintf("%.2f", m.rateSource.EffectiveRate())
+	}
 
 	response := map[string]interface{}{
 		"status":     snapshot.Status,
@@ -70,22 +362,46 @@ func (m *HTTPMonitor) handleStatus(w http.ResponseWriter, r *http.Request) {
 			"exact_match": snapshot.ExactMatch,
 			"fuzzy_match": snapshot.FuzzyMatch,
 			"no_match":    snapshot.NoMatch,
+
+			"unknown_year":       snapshot.UnknownYear,
+			"unknown_year_share": fmt.Sprintf("%.4f", snapshot.UnknownYearShare()),
 		},
-		"rate": map[string]interface{}{
-			"current_rps":           fmt.Sprintf("%.2f", snapshot.RequestsPerSec),
-			"avg_time_per_vehicle":  fmt.Sprintf("%.2fs", snapshot.AvgTimePerVehicle),
+		"rate": rate,
+		"errors": map[string]interface{}{
+			"network_errors":  snapshot.NetworkErrors,
+			"rate_limit_hits": snapshot.RateLimitHits,
+			"schema_drift":    snapshot.SchemaDrift,
 		},
 		"eta": map[string]interface{}{
-			"remaining_vehicles":      snapshot.TotalVehicles - snapshot.Processed,
-			"estimated_completion":    snapshot.ETA.Format(time.RFC3339),
-			"time_remaining":          snapshot.Remaining.String(),
+			"remaining_vehicles":   snapshot.TotalVehicles - snapshot.Processed,
+			"estimated_completion": snapshot.ETA.Format(time.RFC3339),
+			"time_remaining":       snapshot.Remaining.String(),
 		},
-		"last_error":      snapshot.LastError,
-		"current_vehicle": snapshot.CurrentVehicle,
+		"llm_usage": map[string]interface{}{
+			"total_tokens": snapshot.LLMTokens,
+			"by_key":       snapshot.TokenUsage,
+		},
+		"failures_by_reason": snapshot.FailuresByReason,
+		"error_types":        snapshot.ErrorTypes,
+		"stage_latency_ms":   stageLatencyMillis(snapshot.StageLatency),
+		"last_error":         snapshot.LastError,
+		"recent_failures":    snapshot.RecentFailures,
+		"current_vehicle":    snapshot.CurrentVehicle,
+	}
+	if snapshot.RecentFailures == nil {
+		response["recent_failures"] = []RecentFailure{}
+	}
+	if m.control != nil {
+		response["control"] = m.control.State()
+	}
+	if m.keyHealth != nil {
+		response["groq_keys"] = m.keyHealth.GetKeyStatus()
+	}
+	if m.events != nil {
+		response["events_dropped"] = m.events.Dropped()
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return response
 }
 
 // handleHealth returns simple health check
@@ -95,3 +411,133 @@ func (m *HTTPMonitor) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"status": "ok",
 	})
 }
+
+// handleLLMStatus returns the LLM key pool state
+func (m *HTTPMonitor) handleLLMStatus(w http.ResponseWriter, r *http.Request) {
+	writeLLMStatus(w, m.keyHealth)
+}
+
+// LLMStatusHandler serves the key pool state of source as /llm-status does
+// on the monitor; source may be nil when the LLM provider has no key pool
+func LLMStatusHandler(source KeyHealthSource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeLLMStatus(w, source)
+	})
+}
+
+// writeLLMStatus writes the key counts (active, rate limited, daily
+// exhausted), the per-key state and, when every key is exhausted,
+// all_exhausted_until. "configured" is false without a key pool.
+func writeLLMStatus(w http.ResponseWriter, source KeyHealthSource) {
+	status := map[string]interface{}{"configured": false}
+	if source != nil {
+		status = source.GetKeyStatus()
+		status["configured"] = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleResume resumes a run paused by the success-rate guard; it takes the
+// same token as /control/*
+func (m *HTTPMonitor) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !m.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	resumed := m.successRate != nil && m.successRate.Resume()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{
+		"resumed": resumed,
+	})
+}
+
+// controlRequest is the optional JSON body of POST /control/*
+type controlRequest struct {
+	RateLimit string `json:"rate_limit"` // Go duration, e.g. "500ms"
+	Workers   int    `json:"workers"`
+}
+
+// handleControl checks method and token, applies action and answers with the control state
+func (m *HTTPMonitor) handleControl(action func(req controlRequest) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if m.control == nil {
+			http.Error(w, "run controls not available", http.StatusServiceUnavailable)
+			return
+		}
+		if !m.authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req controlRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid JSON body", http.StatusBadRequest)
+				return
+			}
+		}
+		if err := action(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.control.State())
+	}
+}
+
+func (m *HTTPMonitor) controlPause(controlRequest) error {
+	if m.control.Pause() {
+		slog.Info("run paused by operator")
+	}
+	return nil
+}
+
+// controlResume lifts an operator pause and a success-rate guard pause
+func (m *HTTPMonitor) controlResume(controlRequest) error {
+	if m.control.Resume() {
+		slog.Info("run resumed by operator")
+	}
+	if m.successRate != nil {
+		m.successRate.Resume()
+	}
+	return nil
+}
+
+func (m *HTTPMonitor) controlAbort(controlRequest) error {
+	if m.control.Abort() {
+		slog.Warn("run aborted by operator")
+	}
+	return nil
+}
+
+func (m *HTTPMonitor) controlRateLimit(req controlRequest) error {
+	d, err := time.ParseDuration(req.RateLimit)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("rate_limit must be a positive duration, e.g. \"500ms\"")
+	}
+	m.control.SetRateLimit(d)
+	slog.Info("rate limit changed by operator", "rate_limit", d)
+	return nil
+}
+
+func (m *HTTPMonitor) controlWorkers(req controlRequest) error {
+	if req.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1")
+	}
+	m.control.SetWorkers(req.Workers)
+	slog.Info("worker count changed by operator", "workers", req.Workers)
+	return nil
+}
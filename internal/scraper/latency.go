@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Pipeline stages timed per vehicle
+const (
+	StageParse      = "parse"
+	StageBrandMatch = "brand_match"
+	StageModelMatch = "model_match"
+	StageTypeMatch  = "type_match" // Includes embedding and LLM calls
+	StageSpecFetch  = "spec_fetch"
+	StageSave       = "save"
+)
+
+// stages lists every stage in pipeline order
+var stages = []string{
+	StageParse,
+	StageBrandMatch,
+	StageModelMatch,
+	StageTypeMatch,
+	StageSpecFetch,
+	StageSave,
+}
+
+// latencyWindow is how many recent samples per stage are kept for percentiles
+const latencyWindow = 1000
+
+// StageTimings is the time a single vehicle spent in each stage it reached
+type StageTimings map[string]time.Duration
+
+// StageLatencyStats summarizes recent latencies of one stage
+type StageLatencyStats struct {
+	Count int           // Samples recorded over the whole run
+	P50   time.Duration // Over the last latencyWindow samples
+	P95   time.Duration
+}
+
+// stageLatency keeps a ring buffer of recent samples per stage
+type stageLatency struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+	next    map[string]int
+	counts  map[string]int
+}
+
+// newStageLatency creates an empty latency tracker
+func newStageLatency() *stageLatency {
+	return &stageLatency{
+		samples: make(map[string][]time.Duration),
+		next:    make(map[string]int),
+		counts:  make(map[string]int),
+	}
+}
+
+// record adds a sample for stage, overwriting the oldest once the window is full
+func (l *stageLatency) record(stage string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.counts[stage]++
+	if len(l.samples[stage]) < latencyWindow {
+		l.samples[stage] = append(l.samples[stage], d)
+		return
+	}
+	l.samples[stage][l.next[stage]] = d
+	l.next[stage] = (l.next[stage] + 1) % latencyWindow
+}
+
+// snapshot computes P50/P95 for every stage with samples
+func (l *stageLatency) snapshot() map[string]StageLatencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]StageLatencyStats, len(l.samples))
+	for stage, samples := range l.samples {
+		sorted := slices.Clone(samples)
+		slices.Sort(sorted)
+		stats[stage] = StageLatencyStats{
+			Count: l.counts[stage],
+			P50:   percentile(sorted, 0.50),
+			P95:   percentile(sorted, 0.95),
+		}
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p (0-1) of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p*float64(len(sorted))+0.5) - 1
+	idx = max(0, min(idx, len(sorted)-1))
+	return sorted[idx]
+}
+
+// stageLatencyMillis formats stage stats for JSON output (ms), in pipeline order
+func stageLatencyMillis(stats map[string]StageLatencyStats) map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{}, len(stats))
+	for _, stage := range stages {
+		st, ok := stats[stage]
+		if !ok {
+			continue
+		}
+		out[stage] = map[string]interface{}{
+			"count": st.Count,
+			"p50":   durationMillis(st.P50),
+			"p95":   durationMillis(st.P95),
+		}
+	}
+	return out
+}
+
+// durationMillis converts a duration to fractional milliseconds
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
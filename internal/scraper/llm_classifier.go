@@ -0,0 +1,169 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/pkg/motulmatch"
+)
+
+// DefaultClassifyBatchSize is how many vehicles go into one classification prompt
+const DefaultClassifyBatchSize = 20
+
+// classificationOptions are the answers offered to the LLM, in prompt order
+var classificationOptions = []struct {
+	label    string
+	category string
+}{
+	{"passenger car, SUV, van or pickup", client.CategoryCar},
+	{"motorcycle or scooter", client.CategoryMotorcycle},
+	{"truck or bus", client.CategoryTruck},
+	{"tractor or agricultural machine", client.CategoryAgri},
+	{"construction equipment, forklift or stationary engine", categoryUnsupported},
+}
+
+// ClassificationStore caches LLM vehicle categories between runs
+type ClassificationStore interface {
+	LoadClassifications(ctx context.Context) (map[int]string, error)
+	SaveClassifications(ctx context.Context, categories map[int]string) error
+}
+
+// LLMClassifier asks the LLM whether each vehicle is a car, motorcycle,
+// truck, agricultural machine or equipment. Answers are cached in the store,
+// so only vehicles never classified before cost LLM calls.
+type LLMClassifier struct {
+	llm       client.LLMClient
+	store     ClassificationStore
+	batchSize int
+	logger    *slog.Logger
+}
+
+// NewLLMClassifier creates a classifier; batchSize <= 0 uses DefaultClassifyBatchSize.
+// Clients without batch support are asked one vehicle at a time.
+func NewLLMClassifier(llm client.LLMClient, store ClassificationStore, batchSize int, logger *slog.Logger) *LLMClassifier {
+	if batchSize <= 0 {
+		batchSize = DefaultClassifyBatchSize
+	}
+	return &LLMClassifier{llm: llm, store: store, batchSize: batchSize, logger: logger}
+}
+
+// Classify returns the category of each vehicle it could classify, keyed by
+// CodigoAplicacao. With cachedOnly (dry runs) no LLM call is made. Vehicles
+// the LLM failed on are left out and fall back to the keyword rules.
+func (c *LLMClassifier) Classify(ctx context.Context, vehicles []model.Aplicacao, cachedOnly bool) (map[int]string, error) {
+	cached, err := c.store.LoadClassifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[int]string, len(vehicles))
+	var pending []model.Aplicacao
+	for _, v := range vehicles {
+		if category, ok := cached[v.CodigoAplicacao]; ok {
+			categories[v.CodigoAplicacao] = category
+		} else {
+			pending = append(pending, v)
+		}
+	}
+
+	c.logger.Info("vehicle classification",
+		"vehicles", len(vehicles),
+		"cached", len(categories),
+		"pending", len(pending),
+		"cached_only", cachedOnly,
+	)
+	if cachedOnly || len(pending) == 0 {
+		return categories, nil
+	}
+
+	classified := 0
+	for start := 0; start < len(pending); start += c.batchSize {
+		if err := ctx.Err(); err != nil {
+			return categories, err
+		}
+
+		batch := pending[start:min(start+c.batchSize, len(pending))]
+		answers := c.classifyBatch(ctx, batch)
+		if len(answers) == 0 {
+			continue
+		}
+		if err := c.store.SaveClassifications(ctx, answers); err != nil {
+			c.logger.Warn("failed to cache classifications", "error", err)
+		}
+		for id, category := range answers {
+			categories[id] = category
+		}
+		classified += len(answers)
+
+		if (start/c.batchSize+1)%50 == 0 {
+			c.logger.Info("classification progress", "classified", classified, "pending", len(pending)-start-len(batch))
+		}
+	}
+
+	c.logger.Info("vehicle classification finished", "classified", classified, "failed", len(pending)-classified)
+	return categories, nil
+}
+
+// classifyBatch classifies one batch, in a single call when the client supports it
+func (c *LLMClassifier) classifyBatch(ctx context.Context, batch []model.Aplicacao) map[int]string {
+	options := make([]string, len(classificationOptions))
+	for i, o := range classificationOptions {
+		options[i] = o.label
+	}
+
+	answers := make(map[int]string, len(batch))
+	if batcher, ok := c.llm.(client.BatchNormalizer); ok {
+		requests := make([]client.BatchMatchRequest, len(batch))
+		for i, v := range batch {
+			requests[i] = client.BatchMatchRequest{ID: v.CodigoAplicacao, Vehicle: classificationPrompt(v), Options: options}
+		}
+		results, err := batcher.NormalizeVehicleBatch(ctx, requests)
+		if err != nil {
+			c.logger.Warn("batch classification failed", "vehicles", len(batch), "error", err)
+			return answers
+		}
+		for _, r := range results {
+			if r.Error == nil && r.MatchedIndex >= 0 && r.MatchedIndex < len(classificationOptions) {
+				answers[r.ID] = classificationOptions[r.MatchedIndex].category
+			}
+		}
+		return answers
+	}
+
+	for _, v := range batch {
+		answer, err := c.llm.NormalizeVehicle(ctx, classificationPrompt(v), options)
+		if err != nil {
+			c.logger.Debug("classification failed", "id", v.CodigoAplicacao, "error", err)
+			continue
+		}
+		if category, err := classificationCategory(answer); err == nil {
+			answers[v.CodigoAplicacao] = category
+		}
+	}
+	return answers
+}
+
+// classificationPrompt describes a vehicle for the classification prompt
+func classificationPrompt(v model.Aplicacao) string {
+	brand := v.Fabricante
+	if brand == "" {
+		brand = v.Marca
+	}
+	description, _ := motulmatch.CompactDescription(
+		strings.TrimSpace(brand+" "+v.DescricaoAplicacao), motulmatch.DefaultMaxPromptDescription)
+	return description
+}
+
+// classificationCategory maps an LLM answer back to its category
+func classificationCategory(answer string) (string, error) {
+	for _, o := range classificationOptions {
+		if o.label == answer {
+			return o.category, nil
+		}
+	}
+	return "", fmt.Errorf("unexpected classification answer %q", answer)
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"wega-catalog-api/internal/model"
+)
+
+// LookupRequest is the body of POST /lookup: a Wega vehicle as stored in APLICACAO
+type LookupRequest struct {
+	Fabricante string `json:"fabricante"`
+	Modelo     string `json:"modelo,omitempty"` // Defaults to the description up to " - "
+	Descricao  string `json:"descricao"`
+	Periodo    string `json:"periodo,omitempty"`
+}
+
+// LookupVehicle is the provider vehicle matched by a lookup
+type LookupVehicle struct {
+	ID          string `json:"id"`
+	Brand       string `json:"brand"`
+	Model       string `json:"model"`
+	Description string `json:"description"`
+	MatchMethod string `json:"match_method"`
+}
+
+// LookupResponse is the result of POST /lookup
+type LookupResponse struct {
+	Provider string             `json:"provider"`
+	Category string             `json:"category"`
+	Vehicle  LookupVehicle      `json:"vehicle"`
+	Specs    []OilSpecification `json:"specs"`
+}
+
+// LookupHandler serves POST /lookup: it parses and classifies a Wega vehicle
+// like a scraper run, finds it in the provider and fetches its specs. Nothing
+// is persisted; the API uses it for on-demand lookups of long-tail vehicles.
+func LookupHandler(provider SpecProvider, rules *CategoryRules) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeLookupError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+			return
+		}
+
+		var req LookupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeLookupError(w, http.StatusBadRequest, "invalid_request", "invalid JSON body")
+			return
+		}
+
+		vehicle := model.Aplicacao{
+			Fabricante:         strings.TrimSpace(req.Fabricante),
+			Modelo:             strings.TrimSpace(req.Modelo),
+			DescricaoAplicacao: strings.TrimSpace(req.Descricao),
+			Periodo:            req.Periodo,
+		}
+		if vehicle.Fabricante == "" || vehicle.DescricaoAplicacao == "" {
+			writeLookupError(w, http.StatusBadRequest, "invalid_request", "fabricante and descricao are required")
+			return
+		}
+
+		brand, modelName, year, err := parseVehicleDescription(vehicle)
+		if err != nil {
+			writeLookupError(w, http.StatusUnprocessableEntity, "unparseable", err.Error())
+			return
+		}
+
+		category, skip := rules.Classify(brand, modelName, vehicle.DescricaoAplicacao)
+		if skip || category == categoryUnsupported {
+			writeLookupError(w, http.StatusUnprocessableEntity, "unsupported", "vehicle is skipped or has no provider category")
+			return
+		}
+
+		found, err := provider.SearchVehicle(r.Context(), category, brand, modelName, year)
+		if err != nil || found == nil {
+			message := "no provider vehicle matched"
+			if err != nil {
+				message = err.Error()
+			}
+			writeLookupError(w, http.StatusUnprocessableEntity, "no_match", message)
+			return
+		}
+
+		specs, err := provider.GetSpecifications(r.Context(), found.ID)
+		if err != nil {
+			writeLookupError(w, http.StatusBadGateway, "provider_error", err.Error())
+			return
+		}
+		if specs == nil {
+			specs = []OilSpecification{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(LookupResponse{
+			Provider: provider.Name(),
+			Category: category,
+			Vehicle: LookupVehicle{
+				ID:          found.ID,
+				Brand:       found.Brand,
+				Model:       found.Model,
+				Description: found.Description,
+				MatchMethod: found.MotorType,
+			},
+			Specs: specs,
+		})
+	})
+}
+
+// writeLookupError writes an error body shaped like the API's ErrorResponse
+func writeLookupError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(model.ErrorResponse{Error: code, Message: message})
+}
@@ -4,46 +4,81 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"sort"
 	"strings"
 
 	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/parser"
+	"wega-catalog-api/pkg/motulmatch"
 )
 
 // MotulAdapter adapts the smart matcher to work with the scraper service
 type MotulAdapter struct {
-	smartMatcher *SmartMatcher
+	smartMatcher *motulmatch.Matcher            // Car matcher
+	matchers     map[string]*motulmatch.Matcher // Other vehicle categories
 	motulClient  *client.MotulClient
 	logger       *slog.Logger
 }
 
 // NewMotulAdapter creates a new Motul adapter with smart matching
 func NewMotulAdapter(
-	smartMatcher *SmartMatcher,
+	smartMatcher *motulmatch.Matcher,
 	motulClient *client.MotulClient,
 	logger *slog.Logger,
 ) *MotulAdapter {
 	return &MotulAdapter{
 		smartMatcher: smartMatcher,
+		matchers:     make(map[string]*motulmatch.Matcher),
 		motulClient:  motulClient,
 		logger:       logger,
 	}
 }
 
-// SearchVehicle implements the scraper.MotulClient interface
-func (a *MotulAdapter) SearchVehicle(ctx context.Context, brand, model string, year int) (*MotulVehicle, error) {
+// SetCategoryMatcher sets the matcher used for a non-car vehicle category
+func (a *MotulAdapter) SetCategoryMatcher(category string, matcher *motulmatch.Matcher) {
+	a.matchers[category] = matcher
+}
+
+// Ensure MotulAdapter is a spec provider
+var _ SpecProvider = (*MotulAdapter)(nil)
+
+// Name implements SpecProvider
+func (a *MotulAdapter) Name() string {
+	return ProviderMotul
+}
+
+// SearchVehicle implements SpecProvider
+func (a *MotulAdapter) SearchVehicle(ctx context.Context, category, brand, model string, year int) (*ProviderVehicle, error) {
+	matcher := a.smartMatcher
+	if category != client.CategoryCar {
+		matcher = a.matchers[category]
+	}
+	if matcher == nil {
+		return nil, fmt.Errorf("no matcher for vehicle category %s", category)
+	}
+
 	// Use smart matcher to find the best match
-	result, err := a.smartMatcher.FindMatch(ctx, brand, model, model, year)
+	result, err := matcher.FindMatch(ctx, brand, model, model, year)
 	if err != nil {
 		return nil, err
 	}
 
-	return &MotulVehicle{
-		ID:          result.VehicleType.ID,
-		Brand:       result.MotulBrand,
-		Model:       result.MotulModel,
-		Year:        year,
-		Description: result.VehicleType.Name,
-		MotorType:   result.MatchMethod,
+	return &ProviderVehicle{
+		ID:              result.VehicleType.ID,
+		Brand:           result.MotulBrand,
+		Model:           result.MotulModel,
+		Year:            year,
+		Description:     result.VehicleType.Name,
+		MotorType:       result.MatchMethod,
+		Confidence:      result.Confidence,
+		PromptTruncated: result.PromptTruncated,
+		Timings: StageTimings{
+			StageBrandMatch: result.Timings.Brand,
+			StageModelMatch: result.Timings.Model,
+			StageTypeMatch:  result.Timings.Type,
+		},
 	}, nil
 }
 
@@ -68,50 +103,120 @@ func (a *MotulAdapter) GetSpecifications(ctx context.Context, vehicleTypeID stri
 
 	// Parse components from the response (components are nested inside vehicle)
 	for _, comp := range resp.Vehicle.Components {
-		spec := OilSpecification{
-			TipoFluido: a.parseFluidType(comp.Category.Name),
-		}
+		tipoFluido := a.parseFluidType(comp.Category.Code, comp.Category.Name)
 
-		// Extract capacity
-		if len(comp.Capacities) > 0 {
-			var capacities []string
-			for _, cap := range comp.Capacities {
-				if cap.Label != "" {
-					capacities = append(capacities, cap.Label+" L")
-				}
+		// Extract capacity (shared by every usage condition)
+		var capacities []string
+		for _, cap := range comp.Capacities {
+			if cap.Label != "" {
+				capacities = append(capacities, cap.Label+" L")
 			}
-			spec.Capacidade = strings.Join(capacities, ", ")
 		}
+		capacidade := strings.Join(capacities, ", ")
 
-		// Extract product recommendations and viscosities
-		if len(comp.Recommendations) > 0 {
-			var productNames []string
-			var viscosities []string
-
-			for _, rec := range comp.Recommendations {
-				for _, prod := range rec.Products {
-					if prod.Name != "" {
-						productNames = append(productNames, prod.Name)
-						// Extract viscosity from product name (e.g., "MOTUL 8100 ECO-NERGY 5W-30")
-						if visc := extractViscosity(prod.Name); visc != "" {
-							viscosities = append(viscosities, visc)
-						}
-					}
-				}
+		// One spec per usage condition: normal use is the default spec, severe
+		// use (and any other condition) gets its own row
+		groups := groupRecommendations(comp)
+		if len(groups) == 0 {
+			groups = []*conditionGroup{{}}
+		}
+
+		for _, g := range groups {
+			spec := OilSpecification{
+				TipoFluido:   tipoFluido,
+				Condicao:     g.condition,
+				Capacidade:   capacidade,
+				Recomendacao: strings.Join(unique(g.productNames), ", "),
+				Viscosidade:  strings.Join(unique(g.viscosities), ", "),
+				Norma:        strings.Join(unique(normalizeStandards(g.standards)), ", "),
+				Observacao:   strings.Join(unique(g.notes), "; "),
 			}
+			spec.IntervaloTrocaKm, spec.IntervaloTrocaMeses = g.interval.Km, g.interval.Months
 
-			// Remove duplicates
-			spec.Recomendacao = strings.Join(unique(productNames), ", ")
-			spec.Viscosidade = strings.Join(unique(viscosities), ", ")
+			// Only add if we have useful data
+			if spec.TipoFluido != "" && (spec.Viscosidade != "" || spec.Capacidade != "" || spec.Recomendacao != "") {
+				result = append(result, spec)
+			}
 		}
+	}
+
+	return result, nil
+}
 
-		// Only add if we have useful data
-		if spec.TipoFluido != "" && (spec.Viscosidade != "" || spec.Capacidade != "" || spec.Recomendacao != "") {
-			result = append(result, spec)
+// conditionGroup collects the recommendations of one usage condition
+type conditionGroup struct {
+	condition    string // model.CondicaoUsoCode
+	productNames []string
+	viscosities  []string
+	standards    []string
+	notes        []string // Usage and mileage as given by Motul, for Observacao
+	interval     parser.DrainInterval
+}
+
+// groupRecommendations splits a component's recommendations by usage
+// condition, default (normal use) first and the others in response order
+func groupRecommendations(comp client.Component) []*conditionGroup {
+	var groups []*conditionGroup
+	byCondition := make(map[string]*conditionGroup)
+
+	for _, rec := range comp.Recommendations {
+		condition := model.CondicaoUsoCode(rec.Conditions.Usage)
+		g, ok := byCondition[condition]
+		if !ok {
+			g = &conditionGroup{condition: condition}
+			byCondition[condition] = g
+			groups = append(groups, g)
+		}
+		if note := conditionNote(rec.Conditions.Usage, rec.Conditions.Mileage); note != "" {
+			g.notes = append(g.notes, note)
+		}
+		// The drain interval is given in its own field or as the mileage;
+		// several recommendations for a condition keep the stricter one
+		interval, ok := parser.ParseDrainInterval(rec.Conditions.Interval)
+		if !ok {
+			interval, ok = parser.ParseDrainInterval(rec.Conditions.Mileage)
+		}
+		if ok {
+			g.interval = g.interval.Shorter(interval)
+		}
+
+		for _, prod := range rec.Products {
+			if prod.Name != "" {
+				g.productNames = append(g.productNames, prod.Name)
+				// Extract viscosity from product name (e.g., "MOTUL 8100 ECO-NERGY 5W-30")
+				if visc := extractViscosity(prod.Name); visc != "" {
+					g.viscosities = append(g.viscosities, visc)
+				}
+			}
+			g.standards = append(g.standards, prod.Standards...)
+			g.standards = append(g.standards, prod.Approvals...)
+			g.standards = append(g.standards, extractStandards(prod.Name+" "+prod.Description)...)
 		}
 	}
 
-	return result, nil
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].condition == model.CondicaoPadrao && groups[j].condition != model.CondicaoPadrao
+	})
+	return groups
+}
+
+// conditionNote describes a recommendation's usage condition for Observacao,
+// e.g. "Severe conditions, 10000 km"; bare mileage numbers get " km"
+func conditionNote(usage, mileage string) string {
+	usage = strings.Join(strings.Fields(usage), " ")
+	mileage = strings.Join(strings.Fields(mileage), " ")
+	if mileage != "" && strings.Trim(mileage, "0123456789.") == "" {
+		mileage += " km"
+	}
+
+	switch {
+	case usage != "" && mileage != "":
+		return usage + ", " + mileage
+	case usage != "":
+		return usage
+	default:
+		return mileage
+	}
 }
 
 // extractViscosity extracts viscosity pattern from product name
@@ -129,6 +234,31 @@ func extractViscosity(name string) string {
 	return ""
 }
 
+// standardPattern matches standard and approval codes quoted in product text:
+// ACEA C3, API SN PLUS, ILSAC GF-6A, VW 504.00, MB 229.51, BMW LL-04, dexos2...
+var standardPattern = regexp.MustCompile(`(?i)\b(?:ACEA\s+[A-C]\d{1,2}(?:-\d{2})?|API\s+(?:S[A-Z]|C[A-Z](?:-4)?|GL-\d)(?:\s+PLUS)?|ILSAC\s+GF-\d[AB]?|JASO\s+(?:MA2?|MB|DL-1)|VW\s+\d{3}\s?\d{2}|MB(?:-Approval)?\s+2\d{2}\.\d{1,2}|BMW\s+LL-\d{2}(?:\s?FE\+?)?|dexos\s?\d|DOT\s?[345](?:\.1)?|RN\s?0\d{3}|PSA\s+B71\s?\d{4}|FIAT\s+9\.55535-[A-Z0-9]+)\b`)
+
+// extractStandards finds standard/approval codes in free text
+func extractStandards(text string) []string {
+	return standardPattern.FindAllString(text, -1)
+}
+
+// normalizeStandards upper-cases and collapses whitespace so duplicates merge
+func normalizeStandards(standards []string) []string {
+	result := make([]string, 0, len(standards))
+	for _, s := range standards {
+		s = strings.Join(strings.Fields(s), " ")
+		if s == "" {
+			continue
+		}
+		if !strings.HasPrefix(strings.ToLower(s), "dexos") {
+			s = strings.ToUpper(s)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
 // unique returns unique strings from a slice
 func unique(strs []string) []string {
 	seen := make(map[string]bool)
@@ -142,19 +272,11 @@ func unique(strs []string) []string {
 	return result
 }
 
-// parseFluidType converts Motul component type to Portuguese fluid type
-func (a *MotulAdapter) parseFluidType(componentType string) string {
-	typeMap := map[string]string{
-		"ENGINE_OIL":       "Óleo do Motor",
-		"TRANSMISSION_OIL": "Óleo de Transmissão",
-		"BRAKE_FLUID":      "Fluido de Freio",
-		"COOLANT":          "Líquido de Arrefecimento",
-		"POWER_STEERING":   "Direção Hidráulica",
-		"DIFFERENTIAL":     "Diferencial",
-	}
-
-	if pt, ok := typeMap[componentType]; ok {
-		return pt
+// parseFluidType returns the canonical fluid type code (model.Fluido*) for a
+// component; display names are resolved per language by the API
+func (a *MotulAdapter) parseFluidType(code, name string) string {
+	if code != "" {
+		return model.TipoFluidoCode(code)
 	}
-	return componentType
+	return model.TipoFluidoCode(name)
 }
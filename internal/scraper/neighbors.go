@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"context"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// neighborLimit is how many nearest matched applications are compared
+const neighborLimit = 5
+
+// MatchMethodNeighbor marks vehicles that reused the Motul type of a nearly
+// identical application instead of going through the matcher
+const MatchMethodNeighbor = "neighbor"
+
+// NeighborSource finds the already matched Wega applications whose description
+// embedding (pgvector, computed by the API) is closest to an application's.
+// repository.AplicacaoEmbeddingRepo satisfies it.
+type NeighborSource interface {
+	VizinhosMotul(ctx context.Context, codigoAplicacao int, modelo string, limit int) ([]model.VizinhoMotul, error)
+}
+
+// SetNeighborSource reuses the Motul vehicle type of already matched
+// applications whose embeddings (of embeddingModel) have at least minScore
+// cosine similarity to the vehicle's, skipping the brand, model and type
+// matching. Every neighbor above minScore must agree on the type.
+func (s *ScraperService) SetNeighborSource(source NeighborSource, embeddingModel string, minScore float64) {
+	s.neighbors = source
+	s.neighborModel = embeddingModel
+	s.neighborMinScore = minScore
+}
+
+// matchNeighbor returns the Motul type shared by the vehicle's closest matched
+// neighbors, or nil when there is no neighbor source, no neighbor clears the
+// minimum score or the close ones disagree
+func (s *ScraperService) matchNeighbor(ctx context.Context, vehicle model.Aplicacao, year int, timings StageTimings) *ProviderVehicle {
+	if s.neighbors == nil || s.provider.Name() != ProviderMotul {
+		return nil
+	}
+
+	start := time.Now()
+	neighbors, err := s.neighbors.VizinhosMotul(ctx, vehicle.CodigoAplicacao, s.neighborModel, neighborLimit)
+	timings[StageTypeMatch] = time.Since(start)
+	if err != nil {
+		s.logger.Debug("neighbor lookup failed, using the matcher", "id", vehicle.CodigoAplicacao, "error", err)
+		return nil
+	}
+	if len(neighbors) == 0 || neighbors[0].Similaridade < s.neighborMinScore {
+		return nil
+	}
+
+	best := neighbors[0]
+	for _, n := range neighbors[1:] {
+		if n.Similaridade >= s.neighborMinScore && n.MotulVehicleTypeID != best.MotulVehicleTypeID {
+			s.logger.Debug("close neighbors disagree on the Motul type, using the matcher",
+				"id", vehicle.CodigoAplicacao,
+				"neighbor", best.CodigoAplicacao,
+				"other", n.CodigoAplicacao,
+			)
+			return nil
+		}
+	}
+
+	s.logger.Debug("reusing the Motul type of a neighbor",
+		"id", vehicle.CodigoAplicacao,
+		"neighbor", best.CodigoAplicacao,
+		"motul_type", best.MotulVehicleTypeID,
+		"similarity", best.Similaridade,
+	)
+	return &ProviderVehicle{
+		ID:         best.MotulVehicleTypeID,
+		Year:       year,
+		MotorType:  MatchMethodNeighbor,
+		Confidence: best.Similaridade,
+	}
+}
@@ -1,123 +1,297 @@
 package scraper
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/model"
+)
+
+// Failure reasons used to categorize failed vehicles by pipeline stage
+const (
+	FailureReasonSearch     = "search_error"
+	FailureReasonSpecsFetch = "specs_fetch_error"
+	FailureReasonSave       = "save_error"
+	FailureReasonSchema     = "schema_drift"
+	FailureReasonLowConf    = "low_confidence"
+	// FailureReasonInvalidSpecs is used when the spec validator rejected every spec
+	FailureReasonInvalidSpecs = "invalid_specs"
 )
 
+// maxErrorTypes bounds the error-type histogram; further types are counted as errorTypeOther
+const maxErrorTypes = 32
+
+// errorTypeOther is the histogram bucket used once maxErrorTypes is reached
+const errorTypeOther = "other"
+
+// maxRecentFailures bounds the recent-failure list shown by the dashboard
+const maxRecentFailures = 20
+
+// failureReasons lists every reason tracked by the progress tracker
+var failureReasons = []string{
+	FailureReasonSearch,
+	FailureReasonSpecsFetch,
+	FailureReasonSave,
+	FailureReasonSchema,
+	FailureReasonLowConf,
+	FailureReasonInvalidSpecs,
+}
+
 // ProgressTracker tracks scraping progress
+// Counters are atomics so workers never contend on a lock; the mutex is only
+// taken to assemble a snapshot.
 type ProgressTracker struct {
-	mu sync.RWMutex
+	mu sync.Mutex
+
+	startedAt     time.Time
+	totalVehicles int
+
+	processed atomic.Int64
+	success   atomic.Int64
+	failed    atomic.Int64
+	skipped   atomic.Int64
 
-	StartedAt        time.Time
-	TotalVehicles    int
-	Processed        int
-	Success          int
-	Failed           int
-	Skipped          int
-	CurrentVehicle   string
-	LastError        string
+	currentVehicle atomic.Value // string
+	lastError      atomic.Value // string
 
 	// Matching stats
-	ExactMatch       int
-	FuzzyMatch       int
-	NoMatch          int
+	exactMatch atomic.Int64
+	fuzzyMatch atomic.Int64
+	noMatch    atomic.Int64
+
+	// Vehicles without a parseable year (searched without a year filter)
+	unknownYear atomic.Int64
 
 	// Performance
-	TotalRequests    int
-	NetworkErrors    int
-	RateLimitHits    int
+	totalRequests atomic.Int64
+	networkErrors atomic.Int64
+	rateLimitHits atomic.Int64
+	schemaDrift   atomic.Int64
+	llmTokens     atomic.Int64
+
+	// Token usage per LLM provider and API key (guarded by tokensMu)
+	tokensMu   sync.Mutex
+	tokenUsage map[tokenUsageKey]*model.LLMTokenUsage
+
+	// Failure counters by reason (map is read-only after construction)
+	failuresByReason map[string]*atomic.Int64
+
+	// Error-type histogram (model.ClassifyError type -> *atomic.Int64)
+	errorTypes     sync.Map
+	errorTypeCount atomic.Int32
+
+	// Recent per-stage latencies (P50/P95)
+	latency *stageLatency
+
+	// Most recent failures, newest last (guarded by mu)
+	recentFailures []RecentFailure
+}
+
+// tokenUsageKey identifies an LLM provider and API key in the token usage
+type tokenUsageKey struct {
+	provider string
+	key      string
+}
+
+// RecentFailure is one failed vehicle kept for the monitor dashboard
+type RecentFailure struct {
+	Time    time.Time `json:"time"`
+	Vehicle string    `json:"vehicle"`
+	Reason  string    `json:"reason"`
+	Error   string    `json:"error"`
 }
 
 // NewProgressTracker creates a new progress tracker
 func NewProgressTracker(totalVehicles int) *ProgressTracker {
-	return &ProgressTracker{
-		StartedAt:     time.Now(),
-		TotalVehicles: totalVehicles,
+	p := &ProgressTracker{
+		startedAt:        time.Now(),
+		totalVehicles:    totalVehicles,
+		failuresByReason: make(map[string]*atomic.Int64, len(failureReasons)),
+		tokenUsage:       make(map[tokenUsageKey]*model.LLMTokenUsage),
+		latency:          newStageLatency(),
 	}
+	for _, reason := range failureReasons {
+		p.failuresByReason[reason] = &atomic.Int64{}
+	}
+	p.currentVehicle.Store("")
+	p.lastError.Store("")
+	return p
 }
 
 // IncrementProcessed increments processed counter
 func (p *ProgressTracker) IncrementProcessed() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.Processed++
+	p.processed.Add(1)
 }
 
 // IncrementSuccess increments success counter
 func (p *ProgressTracker) IncrementSuccess() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.Success++
+	p.success.Add(1)
 }
 
-// IncrementFailed increments failed counter and sets error
-func (p *ProgressTracker) IncrementFailed(err string) {
+// Failed returns the number of failed vehicles so far
+func (p *ProgressTracker) Failed() int {
+	return int(p.failed.Load())
+}
+
+// Processed returns the number of processed vehicles so far
+func (p *ProgressTracker) Processed() int {
+	return int(p.processed.Load())
+}
+
+// IncrementFailed increments failed counter, the reason counter, sets error
+// and appends the failure to the recent-failure list
+func (p *ProgressTracker) IncrementFailed(reason, vehicle, err string) {
+	p.failed.Add(1)
+	if counter, ok := p.failuresByReason[reason]; ok {
+		counter.Add(1)
+	}
+	p.recordErrorType(model.ClassifyError(err))
+	p.lastError.Store(err)
+
 	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.Failed++
-	p.LastError = err
+	if len(p.recentFailures) == maxRecentFailures {
+		p.recentFailures = append(p.recentFailures[:0], p.recentFailures[1:]...)
+	}
+	p.recentFailures = append(p.recentFailures, RecentFailure{
+		Time:    time.Now(),
+		Vehicle: vehicle,
+		Reason:  reason,
+		Error:   err,
+	})
+	p.mu.Unlock()
+}
+
+// recordErrorType increments the histogram bucket for an error type
+func (p *ProgressTracker) recordErrorType(errType string) {
+	if counter, ok := p.errorTypes.Load(errType); ok {
+		counter.(*atomic.Int64).Add(1)
+		return
+	}
+
+	if p.errorTypeCount.Load() >= maxErrorTypes {
+		errType = errorTypeOther
+	}
+
+	counter, loaded := p.errorTypes.LoadOrStore(errType, &atomic.Int64{})
+	if !loaded {
+		p.errorTypeCount.Add(1)
+	}
+	counter.(*atomic.Int64).Add(1)
 }
 
 // IncrementSkipped increments skipped counter
 func (p *ProgressTracker) IncrementSkipped() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.Skipped++
+	p.skipped.Add(1)
 }
 
 // IncrementExactMatch increments exact match counter
 func (p *ProgressTracker) IncrementExactMatch() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.ExactMatch++
+	p.exactMatch.Add(1)
 }
 
 // IncrementFuzzyMatch increments fuzzy match counter
 func (p *ProgressTracker) IncrementFuzzyMatch() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.FuzzyMatch++
+	p.fuzzyMatch.Add(1)
 }
 
 // IncrementNoMatch increments no match counter
 func (p *ProgressTracker) IncrementNoMatch() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.NoMatch++
+	p.noMatch.Add(1)
+}
+
+// IncrementUnknownYear increments the counter of vehicles without a parseable year
+func (p *ProgressTracker) IncrementUnknownYear() {
+	p.unknownYear.Add(1)
 }
 
 // SetCurrentVehicle sets the current vehicle being processed
 func (p *ProgressTracker) SetCurrentVehicle(vehicle string) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.CurrentVehicle = vehicle
+	p.currentVehicle.Store(vehicle)
 }
 
 // IncrementRequests increments total requests counter
 func (p *ProgressTracker) IncrementRequests() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.TotalRequests++
+	p.totalRequests.Add(1)
+}
+
+// IncrementNetworkErrors increments network errors counter
+func (p *ProgressTracker) IncrementNetworkErrors() {
+	p.networkErrors.Add(1)
+}
+
+// IncrementRateLimitHits increments rate limit hits counter
+func (p *ProgressTracker) IncrementRateLimitHits() {
+	p.rateLimitHits.Add(1)
+}
+
+// IncrementSchemaDrift increments the schema drift counter
+func (p *ProgressTracker) IncrementSchemaDrift() {
+	p.schemaDrift.Add(1)
+}
+
+// AddTokens adds the LLM tokens used by a request
+func (p *ProgressTracker) AddTokens(usage client.TokenUsage) {
+	p.llmTokens.Add(int64(usage.Total()))
+
+	p.tokensMu.Lock()
+	defer p.tokensMu.Unlock()
+	k := tokenUsageKey{provider: usage.Service, key: usage.Key}
+	u := p.tokenUsage[k]
+	if u == nil {
+		u = &model.LLMTokenUsage{Provider: usage.Service, Key: usage.Key}
+		p.tokenUsage[k] = u
+	}
+	u.Requests++
+	u.PromptTokens += int64(usage.PromptTokens)
+	u.CompletionTokens += int64(usage.CompletionTokens)
+}
+
+// tokenUsageSnapshot returns the token usage sorted by provider and key
+func (p *ProgressTracker) tokenUsageSnapshot() []model.LLMTokenUsage {
+	p.tokensMu.Lock()
+	defer p.tokensMu.Unlock()
+
+	usage := make([]model.LLMTokenUsage, 0, len(p.tokenUsage))
+	for _, u := range p.tokenUsage {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Provider != usage[j].Provider {
+			return usage[i].Provider < usage[j].Provider
+		}
+		return usage[i].Key < usage[j].Key
+	})
+	return usage
+}
+
+// RecordStage records the time one vehicle spent in a pipeline stage
+func (p *ProgressTracker) RecordStage(stage string, d time.Duration) {
+	p.latency.record(stage, d)
 }
 
 // GetSnapshot returns a snapshot of current progress
 func (p *ProgressTracker) GetSnapshot() ProgressSnapshot {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	processed := int(p.processed.Load())
+	totalRequests := int(p.totalRequests.Load())
 
-	elapsed := time.Since(p.StartedAt)
+	elapsed := time.Since(p.startedAt)
 	percentage := 0.0
-	if p.TotalVehicles > 0 {
-		percentage = (float64(p.Processed) / float64(p.TotalVehicles)) * 100
+	if p.totalVehicles > 0 {
+		percentage = (float64(processed) / float64(p.totalVehicles)) * 100
 	}
 
 	// Calculate ETA
 	var eta time.Time
 	var remaining time.Duration
-	if p.Processed > 0 {
-		avgTimePerVehicle := elapsed / time.Duration(p.Processed)
-		remainingVehicles := p.TotalVehicles - p.Processed
+	if processed > 0 {
+		avgTimePerVehicle := elapsed / time.Duration(processed)
+		remainingVehicles := p.totalVehicles - processed
 		remaining = avgTimePerVehicle * time.Duration(remainingVehicles)
 		eta = time.Now().Add(remaining)
 	}
@@ -125,34 +299,55 @@ func (p *ProgressTracker) GetSnapshot() ProgressSnapshot {
 	// Calculate rate
 	reqPerSecond := 0.0
 	if elapsed.Seconds() > 0 {
-		reqPerSecond = float64(p.TotalRequests) / elapsed.Seconds()
+		reqPerSecond = float64(totalRequests) / elapsed.Seconds()
 	}
 
 	avgTimePerVehicle := 0.0
-	if p.Processed > 0 {
-		avgTimePerVehicle = elapsed.Seconds() / float64(p.Processed)
+	if processed > 0 {
+		avgTimePerVehicle = elapsed.Seconds() / float64(processed)
+	}
+
+	failuresByReason := make(map[string]int, len(p.failuresByReason))
+	for reason, counter := range p.failuresByReason {
+		failuresByReason[reason] = int(counter.Load())
 	}
 
+	errorTypes := make(map[string]int)
+	p.errorTypes.Range(func(key, value any) bool {
+		errorTypes[key.(string)] = int(value.(*atomic.Int64).Load())
+		return true
+	})
+
 	return ProgressSnapshot{
-		Status:         "running",
-		StartedAt:      p.StartedAt,
-		Elapsed:        elapsed,
-		TotalVehicles:  p.TotalVehicles,
-		Processed:      p.Processed,
-		Success:        p.Success,
-		Failed:         p.Failed,
-		Skipped:        p.Skipped,
-		Percentage:     percentage,
-		CurrentVehicle: p.CurrentVehicle,
-		LastError:      p.LastError,
-		ExactMatch:     p.ExactMatch,
-		FuzzyMatch:     p.FuzzyMatch,
-		NoMatch:        p.NoMatch,
-		TotalRequests:  p.TotalRequests,
-		RequestsPerSec: reqPerSecond,
+		Status:            "running",
+		StartedAt:         p.startedAt,
+		Elapsed:           elapsed,
+		TotalVehicles:     p.totalVehicles,
+		Processed:         processed,
+		Success:           int(p.success.Load()),
+		Failed:            int(p.failed.Load()),
+		Skipped:           int(p.skipped.Load()),
+		Percentage:        percentage,
+		CurrentVehicle:    p.currentVehicle.Load().(string),
+		LastError:         p.lastError.Load().(string),
+		ExactMatch:        int(p.exactMatch.Load()),
+		FuzzyMatch:        int(p.fuzzyMatch.Load()),
+		NoMatch:           int(p.noMatch.Load()),
+		UnknownYear:       int(p.unknownYear.Load()),
+		TotalRequests:     totalRequests,
+		NetworkErrors:     int(p.networkErrors.Load()),
+		RateLimitHits:     int(p.rateLimitHits.Load()),
+		SchemaDrift:       int(p.schemaDrift.Load()),
+		LLMTokens:         int(p.llmTokens.Load()),
+		TokenUsage:        p.tokenUsageSnapshot(),
+		FailuresByReason:  failuresByReason,
+		ErrorTypes:        errorTypes,
+		StageLatency:      p.latency.snapshot(),
+		RecentFailures:    append([]RecentFailure(nil), p.recentFailures...),
+		RequestsPerSec:    reqPerSecond,
 		AvgTimePerVehicle: avgTimePerVehicle,
-		ETA:            eta,
-		Remaining:      remaining,
+		ETA:               eta,
+		Remaining:         remaining,
 	}
 }
 
@@ -172,9 +367,27 @@ type ProgressSnapshot struct {
 	ExactMatch        int
 	FuzzyMatch        int
 	NoMatch           int
+	UnknownYear       int // Processed vehicles without a parseable year
 	TotalRequests     int
+	NetworkErrors     int
+	RateLimitHits     int
+	SchemaDrift       int                   // Motul responses not matching the expected shape
+	LLMTokens         int                   // Prompt + completion tokens reported by the LLM clients
+	TokenUsage        []model.LLMTokenUsage // LLMTokens per provider and API key
+	FailuresByReason  map[string]int
+	ErrorTypes        map[string]int // model.ClassifyError type -> count
+	StageLatency      map[string]StageLatencyStats
+	RecentFailures    []RecentFailure // Oldest first, at most maxRecentFailures
 	RequestsPerSec    float64
 	AvgTimePerVehicle float64
 	ETA               time.Time
 	Remaining         time.Duration
 }
+
+// UnknownYearShare returns the share of processed vehicles without a parseable year
+func (s ProgressSnapshot) UnknownYearShare() float64 {
+	if s.Processed == 0 {
+		return 0
+	}
+	return float64(s.UnknownYear) / float64(s.Processed)
+}
@@ -0,0 +1,59 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ProviderMotul is the registry name of the Motul spec provider
+const ProviderMotul = "motul"
+
+// SpecProvider is an external source of oil specifications (Motul, Castrol,
+// Mobil, ...). The scraper service handles checkpointing, progress and failure
+// tracking; a provider only has to find a vehicle and return its specs.
+type SpecProvider interface {
+	// Name identifies the provider; it is stored as the spec Fonte
+	Name() string
+	SearchVehicle(ctx context.Context, category, brand, modelName string, year int) (*ProviderVehicle, error)
+	GetSpecifications(ctx context.Context, vehicleTypeID string) ([]OilSpecification, error)
+}
+
+// ProviderRegistry holds the spec providers available to the scraper by name
+type ProviderRegistry struct {
+	providers map[string]SpecProvider
+}
+
+// NewProviderRegistry creates an empty provider registry
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{providers: make(map[string]SpecProvider)}
+}
+
+// Register adds a provider; registering the same name twice is an error
+func (r *ProviderRegistry) Register(provider SpecProvider) error {
+	name := provider.Name()
+	if _, exists := r.providers[name]; exists {
+		return fmt.Errorf("spec provider %q already registered", name)
+	}
+	r.providers[name] = provider
+	return nil
+}
+
+// Get returns the provider registered under name
+func (r *ProviderRegistry) Get(name string) (SpecProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown spec provider %q (available: %v)", name, r.Names())
+	}
+	return provider, nil
+}
+
+// Names returns the registered provider names, sorted
+func (r *ProviderRegistry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,148 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CoverageSource counts the catalog vehicles with specs, so run reports can
+// show how much a run added
+type CoverageSource interface {
+	CountCoveredVehicles(ctx context.Context) (covered, total int, err error)
+}
+
+// RunReport is the final stats block of a run
+type RunReport struct {
+	RunID            string
+	Provider         string
+	Status           string // model.RunStatus*
+	Error            string
+	Duration         time.Duration
+	Total            int
+	Processed        int
+	Success          int
+	Failed           int
+	Skipped          int
+	ExactMatch       int
+	FuzzyMatch       int
+	NoMatch          int
+	FailuresByReason map[string]int
+
+	// Catalog coverage before and after the run; HasCoverage is false without
+	// a CoverageSource or when counting failed
+	HasCoverage   bool
+	CoveredBefore int
+	CoveredAfter  int
+	CoverageTotal int
+}
+
+// Title returns the report headline, e.g. "Scraper run completed (motul)"
+func (r RunReport) Title() string {
+	return fmt.Sprintf("Scraper run %s (%s)", r.Status, r.Provider)
+}
+
+// Lines returns the stats block, one "label: value" per line
+func (r RunReport) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("Duration:   %s", r.Duration.Round(time.Second)),
+		fmt.Sprintf("Processed:  %d/%d", r.Processed, r.Total),
+		fmt.Sprintf("Success:    %d", r.Success),
+		fmt.Sprintf("Failed:     %d", r.Failed),
+		fmt.Sprintf("Skipped:    %d", r.Skipped),
+		fmt.Sprintf("Matches:    %d exact, %d fuzzy, %d no match", r.ExactMatch, r.FuzzyMatch, r.NoMatch),
+	}
+
+	reasons := make([]string, 0, len(r.FailuresByReason))
+	for reason, count := range r.FailuresByReason {
+		if count > 0 {
+			reasons = append(reasons, fmt.Sprintf("%s %d", reason, count))
+		}
+	}
+	if len(reasons) > 0 {
+		sort.Strings(reasons)
+		lines = append(lines, "Failures:   "+strings.Join(reasons, ", "))
+	}
+
+	if r.HasCoverage && r.CoverageTotal > 0 {
+		lines = append(lines, fmt.Sprintf("Coverage:   %d -> %d of %d vehicles (%.1f%% -> %.1f%%, %+d)",
+			r.CoveredBefore, r.CoveredAfter, r.CoverageTotal,
+			percent(r.CoveredBefore, r.CoverageTotal), percent(r.CoveredAfter, r.CoverageTotal),
+			r.CoveredAfter-r.CoveredBefore,
+		))
+	}
+	if r.Error != "" {
+		lines = append(lines, "Error:      "+r.Error)
+	}
+	return lines
+}
+
+func percent(part, total int) float64 {
+	return float64(part) * 100 / float64(total)
+}
+
+// ReportNotifier sends the final report of a run to a chat service
+type ReportNotifier interface {
+	Name() string
+	SendReport(ctx context.Context, report RunReport) error
+}
+
+// Ensure chat notifiers implement ReportNotifier
+var _ ReportNotifier = (*SlackNotifier)(nil)
+var _ ReportNotifier = (*DiscordNotifier)(nil)
+
+// SlackNotifier posts run reports to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook URL
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{webhookURL: webhookURL, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name implements ReportNotifier
+func (n *SlackNotifier) Name() string { return "slack" }
+
+// SendReport implements ReportNotifier: the title in bold and the stats in a
+// code block, so the columns line up
+func (n *SlackNotifier) SendReport(ctx context.Context, report RunReport) error {
+	text := fmt.Sprintf("*%s*\n```\n%s\n```", report.Title(), strings.Join(report.Lines(), "\n"))
+	return postWebhookContext(ctx, n.httpClient, n.webhookURL, map[string]string{"text": text})
+}
+
+// DiscordNotifier posts run reports to a Discord channel webhook
+type DiscordNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewDiscordNotifier creates a notifier for a Discord webhook URL
+func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
+	return &DiscordNotifier{webhookURL: webhookURL, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name implements ReportNotifier
+func (n *DiscordNotifier) Name() string { return "discord" }
+
+// discordMaxContent is the longest message Discord accepts
+const discordMaxContent = 2000
+
+// SendReport implements ReportNotifier
+func (n *DiscordNotifier) SendReport(ctx context.Context, report RunReport) error {
+	content := fmt.Sprintf("**%s**\n```\n%s\n```", report.Title(), strings.Join(report.Lines(), "\n"))
+	if len(content) > discordMaxContent {
+		// Long errors are the only unbounded line; cut it and close the block
+		cut := discordMaxContent - len("…\n```")
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		content = content[:cut] + "…\n```"
+	}
+	return postWebhookContext(ctx, n.httpClient, n.webhookURL, map[string]string{"content": content})
+}
@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/matching"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/pkg/motulmatch"
+)
+
+// ConfidenceRepository lists the Motul matches behind saved specs and lowers
+// their MatchConfidence
+type ConfidenceRepository interface {
+	ListMatches(ctx context.Context, afterCodigo int, afterTypeID string, limit int) ([]model.MatchEspecificacao, error)
+	RebaixarConfianca(ctx context.Context, codigoAplicacao int, motulTypeID string, confianca float64) (int64, error)
+}
+
+// ReviewFlagger queues a vehicle for manual review in SCRAPER_FALHAS
+type ReviewFlagger interface {
+	Upsert(ctx context.Context, codigoAplicacao int, tipoErro, mensagemErro string) error
+}
+
+// VehicleTypeLookup resolves a stored Motul vehicle type ID to its catalog
+// name. *motulmatch.CatalogLoader implements it.
+type VehicleTypeLookup interface {
+	FindVehicleType(id string) *motulmatch.CatalogVehicleType
+}
+
+// RescoreStats summarizes a confidence re-scoring run
+type RescoreStats struct {
+	Matches    int // Distinct (vehicle, Motul type) matches examined
+	Specs      int // Active specs behind those matches
+	Agree      int // No engine feature conflicts
+	Partial    int // Some conflicts, outweighed by agreeing features
+	Downgraded int // Matches whose confidence was lowered
+	Flagged    int // Vehicles queued for review in SCRAPER_FALHAS
+	NotFound   int // Motul type ID missing from the catalog
+	Failed     int // Update or flag failed
+}
+
+// RescoreConfidence re-evaluates the matches of existing specs with the
+// deterministic matcher, comparing the engine features of the Wega
+// description and motor with the stored Motul type name. A match whose
+// features clearly disagree (more conflicts than agreements) has its
+// MatchConfidence lowered to the matcher score and the vehicle flagged as
+// baixa_confianca; confidence is never raised. With dryRun nothing is written.
+func RescoreConfidence(ctx context.Context, repo ConfidenceRepository, falhas ReviewFlagger, catalog VehicleTypeLookup, dryRun bool, batchSize int, logger *slog.Logger) (RescoreStats, error) {
+	var stats RescoreStats
+	matcher := matching.NewVehicleMatcher(0)
+	afterCodigo, afterTypeID := 0, ""
+
+	for {
+		matches, err := repo.ListMatches(ctx, afterCodigo, afterTypeID, batchSize)
+		if err != nil {
+			return stats, err
+		}
+		if len(matches) == 0 {
+			return stats, nil
+		}
+
+		for _, m := range matches {
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			afterCodigo, afterTypeID = m.CodigoAplicacao, m.MotulVehicleTypeID
+			stats.Matches++
+			stats.Specs += m.Especificacoes
+
+			vt := catalog.FindVehicleType(m.MotulVehicleTypeID)
+			if vt == nil {
+				stats.NotFound++
+				continue
+			}
+
+			wega := m.DescricaoAplicacao + " " + m.Motor
+			agree, conflicts := matching.CompareFeatures(
+				matching.ExtractFeatures(wega, 0),
+				matching.ExtractFeatures(vt.Name, 0),
+			)
+			switch {
+			case conflicts == 0:
+				stats.Agree++
+				continue
+			case conflicts <= agree:
+				stats.Partial++
+				continue
+			}
+
+			result, err := matcher.FindBestMatch(
+				&model.Aplicacao{DescricaoCompleta: wega},
+				[]client.VehicleType{{ID: vt.ID, Name: vt.Name}},
+			)
+			if err != nil {
+				logger.Warn("failed to score match", "id", m.CodigoAplicacao, "motul_id", m.MotulVehicleTypeID, "error", err)
+				stats.Failed++
+				continue
+			}
+			confidence := result.Score.Confidence
+			if m.MatchConfidence != nil && *m.MatchConfidence < confidence {
+				confidence = *m.MatchConfidence
+			}
+
+			logger.Info("match features disagree",
+				"id", m.CodigoAplicacao,
+				"wega", wega,
+				"motul_id", m.MotulVehicleTypeID,
+				"motul", vt.Name,
+				"agree", agree,
+				"conflicts", conflicts,
+				"stored_confidence", m.MatchConfidence,
+				"confidence", confidence,
+				"dry_run", dryRun,
+			)
+			if dryRun {
+				stats.Downgraded++
+				stats.Flagged++
+				continue
+			}
+
+			changed, err := repo.RebaixarConfianca(ctx, m.CodigoAplicacao, m.MotulVehicleTypeID, confidence)
+			if err != nil {
+				logger.Warn("failed to lower match confidence", "id", m.CodigoAplicacao, "error", err)
+				stats.Failed++
+				continue
+			}
+			if changed > 0 {
+				stats.Downgraded++
+			}
+
+			msg := fmt.Sprintf("low confidence match: rescore found %d conflicting engine features vs %d agreeing (%s: %s)",
+				conflicts, agree, m.MotulVehicleTypeID, vt.Name)
+			if err := falhas.Upsert(ctx, m.CodigoAplicacao, model.ErroTipoBaixaConfianca, msg); err != nil {
+				logger.Warn("failed to flag match for review", "id", m.CodigoAplicacao, "error", err)
+				stats.Failed++
+				continue
+			}
+			stats.Flagged++
+		}
+
+		logger.Info("confidence rescore progress",
+			"matches", stats.Matches,
+			"specs", stats.Specs,
+			"agree", stats.Agree,
+			"partial", stats.Partial,
+			"downgraded", stats.Downgraded,
+			"flagged", stats.Flagged,
+			"not_found", stats.NotFound,
+			"failed", stats.Failed,
+		)
+	}
+}
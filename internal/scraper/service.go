@@ -2,18 +2,25 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-	"unicode"
 
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
+	"wega-catalog-api/internal/client"
 	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/normalize"
+	"wega-catalog-api/internal/parser"
 )
 
 // VehicleRepository defines methods needed from aplicacao repository
@@ -22,10 +29,19 @@ type VehicleRepository interface {
 	GetVehicleByID(ctx context.Context, id int) (*model.Aplicacao, error)
 }
 
-// EspecificacaoRepository defines methods for saving specifications
-type EspecificacaoRepository interface {
-	Insert(ctx context.Context, spec *model.EspecificacaoTecnica) error
+// IncrementalVehicleRepository is implemented by vehicle sources that know when
+// each vehicle was added, enabling differential runs (ScraperConfig.Since)
+type IncrementalVehicleRepository interface {
+	GetVehiclesCreatedSince(ctx context.Context, since time.Time) ([]model.Aplicacao, error)
+}
+
+// SpecSink receives scraped specifications (Postgres, file or HTTP endpoint)
+// Sinks that cannot answer the existence queries report false/nil, so every
+// vehicle is scraped and only the checkpoint prevents repeated work.
+type SpecSink interface {
+	Upsert(ctx context.Context, spec *model.EspecificacaoTecnica) error
 	ExistsForVehicle(ctx context.Context, codigoAplicacao int) (bool, error)
+	LastUpdatedForVehicle(ctx context.Context, codigoAplicacao int) (*time.Time, error)
 }
 
 // FalhaRepository defines methods for tracking failures
@@ -36,41 +52,79 @@ type FalhaRepository interface {
 	CountPending(ctx context.Context) (int, error)
 }
 
-// MotulClient defines methods needed from Motul API client
-type MotulClient interface {
-	SearchVehicle(ctx context.Context, brand, modelName string, year int) (*MotulVehicle, error)
-	GetSpecifications(ctx context.Context, vehicleTypeID string) ([]OilSpecification, error)
+// AttemptRecorder records the last outcome of each vehicle the provider was
+// searched for, so coverage reports can tell "no match" from "never attempted"
+type AttemptRecorder interface {
+	RecordAttempt(ctx context.Context, codigoAplicacao int, outcome string) error
+}
+
+// PopularityRepository provides API demand scores used to prioritize vehicles
+type PopularityRepository interface {
+	AllScores(ctx context.Context) (map[int]float64, error)
 }
 
-// OilSpecification represents a single oil specification from Motul
+// OilSpecification represents a single oil specification from a spec provider
 type OilSpecification struct {
-	TipoFluido   string
-	Viscosidade  string
-	Capacidade   string
-	Norma        string
-	Recomendacao string
+	TipoFluido   string `json:"tipo_fluido"`
+	Condicao     string `json:"condicao,omitempty"` // Usage condition code, "" = normal use
+	Viscosidade  string `json:"viscosidade,omitempty"`
+	Capacidade   string `json:"capacidade,omitempty"`
+	Norma        string `json:"norma,omitempty"`
+	Recomendacao string `json:"recomendacao,omitempty"`
+	Observacao   string `json:"observacao,omitempty"` // Usage condition and mileage as given by the provider
+	// Oil change interval, 0 = unknown
+	IntervaloTrocaKm    int `json:"intervalo_troca_km,omitempty"`
+	IntervaloTrocaMeses int `json:"intervalo_troca_meses,omitempty"`
 }
 
-// MotulVehicle represents a vehicle from Motul API
-type MotulVehicle struct {
+// ProviderVehicle represents a vehicle matched in a spec provider catalog
+type ProviderVehicle struct {
 	ID          string
 	Brand       string
 	Model       string
 	Year        int
 	Description string
 	MotorType   string
+	Confidence  float64      // Match confidence (0.0-1.0), 0 when the provider doesn't report one
+	Timings     StageTimings // Brand/model/type match durations, when known
+	// PromptTruncated is set when the description was compacted for the LLM prompt
+	PromptTruncated bool
 }
 
 // ScraperConfig holds configuration for the scraper
 type ScraperConfig struct {
-	Workers          int
-	RateLimit        time.Duration
-	CheckpointEvery  int
-	CheckpointFile   string
-	ResumeFromID     int
-	DryRun           bool
-	HTTPMonitorPort  int
-	EnableMonitoring bool
+	Workers           int
+	RateLimit         time.Duration
+	CheckpointEvery   int
+	CheckpointFile    string
+	ResumeFromID      int
+	DryRun            bool
+	DryRunReport      string // JSON (or .csv) report of what the dry run would do ("" = disabled)
+	HTTPMonitorPort   int
+	EnableMonitoring  bool
+	RefreshOlderThan  time.Duration // Re-scrape specs older than this (0 = never refresh)
+	AuditFile         string        // NDJSON audit log with per-vehicle outcome and stage timings ("" = disabled)
+	Categories        []string      // Motul vehicle categories to scrape (empty = cars only)
+	PrioritizePopular bool          // Process the most looked-up vehicles (API popularity) first
+	Since             time.Time     // Only vehicles added after this (zero = all vehicles)
+	OnlyIDs           []int         // Only these CodigoAplicacao, re-scraped even with fresh specs and without checkpoints (debugging)
+	ControlToken      string        // Bearer token for the monitor's /control/* and /debug/* endpoints ("" = loopback only)
+	DebugEndpoints    bool          // Serve pprof and expvar under the monitor's /debug/
+	MinSaveConfidence float64       // Fuzzy matches below this go to SCRAPER_FALHAS for review instead of the sink (0 = save all)
+
+	// Alerting
+	AlertWebhookURL         string // Webhook notified when rate-limit hits exceed the threshold
+	RateLimitAlertThreshold int    // Rate-limit hits per minute before alerting (0 = disabled)
+
+	// Success-rate guard
+	SuccessRateWindow     int     // Number of recent attempted vehicles considered
+	SuccessRateThreshold  float64 // Alert when the rolling success rate drops below this (0 = disabled)
+	PauseOnLowSuccessRate bool    // Also pause workers until resumed via POST /resume
+
+	// Event webhooks: run started/finished, failure threshold, LLM keys exhausted
+	WebhookURLs           []string // Endpoints notified of every event (empty = disabled)
+	WebhookSecret         string   // HMAC-SHA256 key signing each delivery ("" = unsigned)
+	FailureAlertThreshold int      // Notify once when the run's failed vehicles reach this (0 = disabled)
 }
 
 // DefaultScraperConfig returns default configuration
@@ -84,6 +138,8 @@ func DefaultScraperConfig() ScraperConfig {
 		DryRun:           false,
 		HTTPMonitorPort:  9090,
 		EnableMonitoring: true,
+
+		SuccessRateWindow: 200,
 	}
 }
 
@@ -91,32 +147,93 @@ func DefaultScraperConfig() ScraperConfig {
 type ScraperService struct {
 	config      ScraperConfig
 	vehicleRepo VehicleRepository
-	specRepo    EspecificacaoRepository
+	sink        SpecSink
 	falhaRepo   FalhaRepository
-	motulClient MotulClient
+	attempts    AttemptRecorder
+	popularity  PopularityRepository
+	provider    SpecProvider
 	checkpoint  *CheckpointManager
-	progress    *ProgressTracker
+	progress    atomic.Pointer[ProgressTracker] // Set by start; read by observers called from client goroutines
 	monitor     *HTTPMonitor
+	alerter     *RateLimitAlerter
+	successRate *SuccessRateMonitor
+	webhooks    *WebhookNotifier // nil without WebhookURLs
+	reporters   []ReportNotifier // Chat services sent the final report
+	coverage    CoverageSource   // Optional, for the coverage delta in reports
+	rateSource  RateSource
+	keyHealth   KeyHealthSource
+	audit       *AuditLogger
+	events      *EventHub // Per-vehicle events for the monitor's /events
+	dryRun      *DryRunReport
+	rules       atomic.Pointer[CategoryRules] // Swapped by SetCategoryRules, e.g. on SIGHUP
+	classifier  *LLMClassifier
+	llmCategory map[int]string // Filled by classifier before workers start; read-only afterwards
+	validator   *parser.SpecValidator
 	logger      *slog.Logger
+
+	// Operator controls exposed by the HTTP monitor
+	control *RunControl
+
+	// Webhook events already sent, so each fires once
+	failureAlerted atomic.Bool
+	exhaustedMu    sync.Mutex
+	exhaustedUntil map[string]time.Time // Service -> reset time last notified
+
+	// Run metrics (optional, set via SetRunRecorder)
+	runRecorder RunRecorder
+	runLabels   model.ScraperRun
+
+	// Matched-neighbor reuse (optional, set via SetNeighborSource)
+	neighbors        NeighborSource
+	neighborModel    string
+	neighborMinScore float64
+
+	// Distributed mode (optional, set via SetWorkQueue)
+	queue       WorkQueue
+	queueRunID  string
+	queueWorker string
 }
 
+// Ensure ScraperService can observe external client requests
+// tracer starts the per-vehicle spans; the Motul, LLM and database calls of
+// a vehicle are recorded under its span
+var tracer = otel.Tracer("wega-catalog-api/internal/scraper")
+
+var _ client.RequestObserver = (*ScraperService)(nil)
+var _ client.SchemaDriftObserver = (*ScraperService)(nil)
+var _ client.TokenUsageObserver = (*ScraperService)(nil)
+var _ client.KeyExhaustionObserver = (*ScraperService)(nil)
+
 // NewScraperService creates a new scraper service
 func NewScraperService(
 	config ScraperConfig,
 	vehicleRepo VehicleRepository,
-	specRepo EspecificacaoRepository,
-	motulClient MotulClient,
+	sink SpecSink,
+	provider SpecProvider,
 	logger *slog.Logger,
 ) *ScraperService {
-	return &ScraperService{
+	s := &ScraperService{
 		config:      config,
 		vehicleRepo: vehicleRepo,
-		specRepo:    specRepo,
+		sink:        sink,
 		falhaRepo:   nil, // Optional, set via SetFalhaRepo
-		motulClient: motulClient,
+		provider:    provider,
 		checkpoint:  NewCheckpointManager(config.CheckpointFile),
-		logger:      logger,
+		control:     NewRunControl(config.Workers, config.RateLimit),
+		alerter:     NewRateLimitAlerter(config.AlertWebhookURL, config.RateLimitAlertThreshold, logger),
+		successRate: NewSuccessRateMonitor(
+			config.SuccessRateWindow,
+			config.SuccessRateThreshold,
+			config.PauseOnLowSuccessRate,
+			config.AlertWebhookURL,
+			logger,
+		),
+		webhooks:       NewWebhookNotifier(config.WebhookURLs, config.WebhookSecret, logger),
+		exhaustedUntil: make(map[string]time.Time),
+		logger:         logger,
 	}
+	s.rules.Store(DefaultCategoryRules())
+	return s
 }
 
 // SetFalhaRepo sets the failure repository for tracking failed attempts
@@ -124,8 +241,405 @@ func (s *ScraperService) SetFalhaRepo(repo FalhaRepository) {
 	s.falhaRepo = repo
 }
 
-// Run executes the scraping process
+// SetCategoryRules replaces the category and skip rules; safe while running
+func (s *ScraperService) SetCategoryRules(rules *CategoryRules) {
+	s.rules.Store(rules)
+}
+
+// SetSpecValidator normalizes and validates specs before they reach the sink;
+// rejected specs are recorded in SCRAPER_FALHAS
+func (s *ScraperService) SetSpecValidator(validator *parser.SpecValidator) {
+	s.validator = validator
+}
+
+// SetClassifier enables LLM vehicle classification; its categories take
+// precedence over the keyword rules, which still apply their skip list
+func (s *ScraperService) SetClassifier(classifier *LLMClassifier) {
+	s.classifier = classifier
+}
+
+// SetAttemptRecorder sets where the outcome of each searched vehicle is recorded
+func (s *ScraperService) SetAttemptRecorder(recorder AttemptRecorder) {
+	s.attempts = recorder
+}
+
+// SetCheckpointStore replaces the checkpoint file with store, keyed by runID
+func (s *ScraperService) SetCheckpointStore(store CheckpointStore, runID string) {
+	s.checkpoint = NewCheckpointManagerWithStore(store, runID)
+}
+
+// SetPopularityRepo sets the source of popularity scores used by PrioritizePopular
+func (s *ScraperService) SetPopularityRepo(repo PopularityRepository) {
+	s.popularity = repo
+}
+
+// AddReportNotifier sends the final report of every run to a chat service
+func (s *ScraperService) AddReportNotifier(notifier ReportNotifier) {
+	s.reporters = append(s.reporters, notifier)
+}
+
+// SetCoverageSource sets where catalog coverage is counted before and after
+// the run, for the coverage delta in reports
+func (s *ScraperService) SetCoverageSource(source CoverageSource) {
+	s.coverage = source
+}
+
+// SetRateSource sets the client whose effective request rate is shown by the monitor
+func (s *ScraperService) SetRateSource(source RateSource) {
+	s.rateSource = source
+}
+
+// SetKeyHealthSource sets the LLM client whose API key health is shown by the monitor
+func (s *ScraperService) SetKeyHealthSource(source KeyHealthSource) {
+	s.keyHealth = source
+}
+
+// OnRateLimited implements client.RequestObserver
+func (s *ScraperService) OnRateLimited(service string) {
+	if progress := s.progress.Load(); progress != nil {
+		progress.IncrementRateLimitHits()
+	}
+	s.alerter.Record(service)
+}
+
+// OnNetworkError implements client.RequestObserver
+func (s *ScraperService) OnNetworkError(service string, err error) {
+	if progress := s.progress.Load(); progress != nil {
+		progress.IncrementNetworkErrors()
+	}
+	s.logger.Debug("network error", "service", service, "error", err)
+}
+
+// OnTokensUsed implements client.TokenUsageObserver
+func (s *ScraperService) OnTokensUsed(usage client.TokenUsage) {
+	if progress := s.progress.Load(); progress != nil {
+		progress.AddTokens(usage)
+	}
+}
+
+// OnSchemaDrift implements client.SchemaDriftObserver
+func (s *ScraperService) OnSchemaDrift(service, endpoint string, problems []string) {
+	if progress := s.progress.Load(); progress != nil {
+		progress.IncrementSchemaDrift()
+	}
+	s.logger.Warn("schema drift detected", "service", service, "endpoint", endpoint, "problems", problems)
+}
+
+// OnKeysExhausted implements client.KeyExhaustionObserver; the webhook fires
+// once per service and reset time
+func (s *ScraperService) OnKeysExhausted(service string, until time.Time) {
+	s.exhaustedMu.Lock()
+	notified := s.exhaustedUntil[service].Equal(until)
+	s.exhaustedUntil[service] = until
+	s.exhaustedMu.Unlock()
+	if notified {
+		return
+	}
+
+	s.notify(WebhookKeysExhausted,
+		fmt.Sprintf("All %s API keys exhausted until %s", service, until.Format(time.RFC3339)),
+		KeysExhaustedData{Service: service, Until: until},
+	)
+}
+
+// RunRecorder persists each run: Start creates its row when it begins (the ID
+// tags the specs it writes), Heartbeat keeps it alive while it runs and Record
+// stores the final summary metrics
+type RunRecorder interface {
+	Start(ctx context.Context, run model.ScraperRun) (int, error)
+	Heartbeat(ctx context.Context, id int) error
+	Record(ctx context.Context, run model.ScraperRun) error
+}
+
+// runHeartbeatInterval is how often a running run refreshes its row
+const runHeartbeatInterval = time.Minute
+
+// StaleRunAfter is how long a run may go without a heartbeat before a new
+// scraper marks it failed (see ScraperRunRepo.FailStale)
+const StaleRunAfter = 10 * runHeartbeatInterval
+
+// SetRunRecorder records the summary of every run under runID and worker
+func (s *ScraperService) SetRunRecorder(recorder RunRecorder, runID, worker string) {
+	s.runRecorder = recorder
+	s.runLabels.RunID = runID
+	s.runLabels.Worker = worker
+}
+
+// SetRunConfig adds settings decided outside the service (LLM, match pipeline...)
+// to the configuration recorded with the run
+func (s *ScraperService) SetRunConfig(config map[string]string) {
+	s.runLabels.Config = config
+}
+
+// runConfig returns the configuration recorded with the run
+func (s *ScraperService) runConfig() map[string]string {
+	categories := s.config.Categories
+	if len(categories) == 0 {
+		categories = []string{client.CategoryCar}
+	}
+
+	config := map[string]string{
+		"workers":            strconv.Itoa(s.config.Workers),
+		"rate_limit":         s.config.RateLimit.String(),
+		"dry_run":            strconv.FormatBool(s.config.DryRun),
+		"categories":         strings.Join(categories, ","),
+		"prioritize_popular": strconv.FormatBool(s.config.PrioritizePopular),
+		"distributed":        strconv.FormatBool(s.queue != nil),
+	}
+	if !s.config.Since.IsZero() {
+		config["since"] = s.config.Since.Format(time.RFC3339)
+	}
+	if s.config.RefreshOlderThan > 0 {
+		config["refresh_older_than"] = s.config.RefreshOlderThan.String()
+	}
+	if len(s.config.OnlyIDs) > 0 {
+		ids := make([]string, len(s.config.OnlyIDs))
+		for i, id := range s.config.OnlyIDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		config["only_ids"] = strings.Join(ids, ",")
+	}
+	if s.config.ResumeFromID > 0 {
+		config["resume_from_id"] = strconv.Itoa(s.config.ResumeFromID)
+	}
+	for key, value := range s.runLabels.Config {
+		config[key] = value
+	}
+	return config
+}
+
+// Run executes the scraping process and records its summary metrics
 func (s *ScraperService) Run(ctx context.Context) error {
+	// POST /control/abort cancels this context
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.control.setAbort(cancel)
+
+	var report RunReport
+	if len(s.reporters) > 0 && s.coverage != nil {
+		covered, total, err := s.coverage.CountCoveredVehicles(ctx)
+		if err != nil {
+			s.logger.Warn("failed to count coverage, reports will not show it", "error", err)
+		} else {
+			report.HasCoverage, report.CoveredBefore, report.CoverageTotal = true, covered, total
+		}
+	}
+
+	s.startRun(ctx)
+	stopHeartbeat := s.heartbeatRun(ctx)
+	err := s.run(ctx)
+	stopHeartbeat()
+	s.recordRun(ctx, err)
+	s.finishReport(ctx, &report, err)
+	s.notifyRunFinished(report)
+	s.sendReports(ctx, report)
+	s.webhooks.Flush(15 * time.Second)
+	return err
+}
+
+// runStatus returns the model.RunStatus* of a run that ended with runErr
+func runStatus(ctx context.Context, runErr error) string {
+	switch {
+	case ctx.Err() != nil:
+		return model.RunStatusCancelled
+	case runErr != nil:
+		return model.RunStatusFailed
+	default:
+		return model.RunStatusCompleted
+	}
+}
+
+// notify posts a webhook event labeled with the run and provider
+func (s *ScraperService) notify(event, text string, data any) {
+	s.webhooks.Notify(WebhookEvent{
+		Event:    event,
+		Text:     text,
+		RunID:    s.runLabels.RunID,
+		Provider: s.provider.Name(),
+		Data:     data,
+	})
+}
+
+// finishReport fills the run report with the final counters and coverage;
+// counters are zero when the run failed before processing started
+func (s *ScraperService) finishReport(ctx context.Context, report *RunReport, runErr error) {
+	report.RunID = s.runLabels.RunID
+	report.Provider = s.provider.Name()
+	report.Status = runStatus(ctx, runErr)
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	if progress := s.progress.Load(); progress != nil {
+		snapshot := progress.GetSnapshot()
+		report.Duration = snapshot.Elapsed
+		report.Total = snapshot.TotalVehicles
+		report.Processed = snapshot.Processed
+		report.Success = snapshot.Success
+		report.Failed = snapshot.Failed
+		report.Skipped = snapshot.Skipped
+		report.ExactMatch = snapshot.ExactMatch
+		report.FuzzyMatch = snapshot.FuzzyMatch
+		report.NoMatch = snapshot.NoMatch
+		report.FailuresByReason = snapshot.FailuresByReason
+	}
+
+	if report.HasCoverage {
+		covered, total, err := s.coverage.CountCoveredVehicles(context.WithoutCancel(ctx))
+		if err != nil {
+			s.logger.Warn("failed to count coverage after run", "error", err)
+			report.HasCoverage = false
+		} else {
+			report.CoveredAfter, report.CoverageTotal = covered, total
+		}
+	}
+}
+
+// notifyRunFinished posts the run summary to the event webhooks
+func (s *ScraperService) notifyRunFinished(report RunReport) {
+	data := RunFinishedData{
+		Status:           report.Status,
+		DurationSeconds:  report.Duration.Seconds(),
+		Total:            report.Total,
+		Processed:        report.Processed,
+		Success:          report.Success,
+		Failed:           report.Failed,
+		Skipped:          report.Skipped,
+		FailuresByReason: report.FailuresByReason,
+		Error:            report.Error,
+	}
+
+	text := fmt.Sprintf("Scraper run %s: %d/%d processed, %d success, %d failed, %d skipped in %s",
+		data.Status, data.Processed, data.Total, data.Success, data.Failed, data.Skipped,
+		report.Duration.Round(time.Second))
+	if data.Error != "" {
+		text += " (" + data.Error + ")"
+	}
+	s.notify(WebhookRunFinished, text, data)
+}
+
+// sendReports sends the final report to every chat notifier, also when the
+// run was aborted
+func (s *ScraperService) sendReports(ctx context.Context, report RunReport) {
+	for _, notifier := range s.reporters {
+		if err := notifier.SendReport(context.WithoutCancel(ctx), report); err != nil {
+			s.logger.Warn("failed to send run report", "notifier", notifier.Name(), "error", err)
+		}
+	}
+}
+
+// Control returns the operator controls of the run (pause, abort, rate limit, workers)
+func (s *ScraperService) Control() *RunControl {
+	return s.control
+}
+
+// startRun creates the run row so the specs written by this run carry its ID;
+// without it the specs are saved untagged
+func (s *ScraperService) startRun(ctx context.Context) {
+	if s.runRecorder == nil {
+		return
+	}
+
+	run := s.runLabels
+	run.Provider = s.provider.Name()
+	run.Status = model.RunStatusRunning
+	run.StartedAt = time.Now()
+	run.FinishedAt = run.StartedAt
+	run.Config = s.runConfig()
+	id, err := s.runRecorder.Start(ctx, run)
+	if err != nil {
+		s.logger.Warn("failed to start run record, specs will not be tagged with the run", "error", err)
+		return
+	}
+	s.runLabels.ID = id
+	s.runLabels.StartedAt = run.StartedAt
+	s.logger.Info("run recorded", "scraper_run_id", id)
+}
+
+// heartbeatRun refreshes the run row every runHeartbeatInterval until the
+// returned stop is called, so a crashed run can be told apart from a live one
+func (s *ScraperService) heartbeatRun(ctx context.Context) (stop func()) {
+	if s.runRecorder == nil || s.runLabels.ID == 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(runHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.runRecorder.Heartbeat(ctx, s.runLabels.ID); err != nil && ctx.Err() == nil {
+					s.logger.Warn("failed to refresh run heartbeat", "error", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// scraperRunID returns the ID of the run row that tags saved specs (nil if none)
+func (s *ScraperService) scraperRunID() *int {
+	if s.runLabels.ID == 0 {
+		return nil
+	}
+	id := s.runLabels.ID
+	return &id
+}
+
+// recordRun persists the run summary (no-op without a recorder, or before
+// progress started when the run has no row yet)
+func (s *ScraperService) recordRun(ctx context.Context, runErr error) {
+	progress := s.progress.Load()
+	if s.runRecorder == nil || (progress == nil && s.runLabels.ID == 0) {
+		return
+	}
+
+	var snapshot ProgressSnapshot
+	if progress != nil {
+		snapshot = progress.GetSnapshot()
+	} else {
+		snapshot.StartedAt = s.runLabels.StartedAt
+	}
+	run := s.runLabels
+	run.Provider = s.provider.Name()
+	run.Status = runStatus(ctx, runErr)
+	run.StartedAt = snapshot.StartedAt
+	run.FinishedAt = time.Now()
+	run.Duration = run.FinishedAt.Sub(run.StartedAt).Seconds()
+	run.Total = snapshot.TotalVehicles
+	run.Processed = snapshot.Processed
+	run.Success = snapshot.Success
+	run.Failed = snapshot.Failed
+	run.Skipped = snapshot.Skipped
+	run.ExactMatch = snapshot.ExactMatch
+	run.FuzzyMatch = snapshot.FuzzyMatch
+	run.NoMatch = snapshot.NoMatch
+	run.Requests = snapshot.TotalRequests
+	run.NetworkErrors = snapshot.NetworkErrors
+	run.RateLimitHits = snapshot.RateLimitHits
+	run.LLMTokens = snapshot.LLMTokens
+	run.TokenUsage = snapshot.TokenUsage
+	run.Config = s.runConfig()
+	run.FailuresByReason = snapshot.FailuresByReason
+	run.ErrorTypes = snapshot.ErrorTypes
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+
+	if err := s.runRecorder.Record(context.WithoutCancel(ctx), run); err != nil {
+		s.logger.Warn("failed to record run metrics", "error", err)
+	}
+}
+
+func (s *ScraperService) run(ctx context.Context) error {
 	s.logger.Info("starting scraper service",
 		"workers", s.config.Workers,
 		"rate_limit", s.config.RateLimit,
@@ -133,30 +647,48 @@ func (s *ScraperService) Run(ctx context.Context) error {
 	)
 
 	// Load vehicles from database
-	vehicles, err := s.vehicleRepo.GetAllVehicles(ctx)
+	vehicles, err := s.loadVehicles(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load vehicles: %w", err)
 	}
 
 	s.logger.Info("loaded vehicles", "count", len(vehicles))
 
-	// Handle resume from checkpoint
-	startIndex := 0
-	if s.checkpoint.Exists() {
-		checkpoint, err := s.checkpoint.Load()
+	if s.classifier != nil {
+		// Dry runs only use classifications cached by earlier runs
+		categories, err := s.classifier.Classify(ctx, vehicles, s.config.DryRun)
 		if err != nil {
-			s.logger.Warn("failed to load checkpoint, starting fresh", "error", err)
-		} else {
-			s.logger.Info("resuming from checkpoint",
-				"last_id", checkpoint.LastProcessedID,
-				"saved_at", checkpoint.SavedAt,
-			)
-			// Find index of last processed vehicle
-			for i, v := range vehicles {
-				if v.CodigoAplicacao == checkpoint.LastProcessedID {
-					startIndex = i + 1
-					break
-				}
+			s.logger.Warn("vehicle classification failed, using keyword rules", "error", err)
+		}
+		s.llmCategory = categories
+	}
+
+	if s.config.PrioritizePopular {
+		s.prioritizePopular(ctx, vehicles)
+	}
+
+	if s.queue != nil {
+		return s.runDistributed(ctx, vehicles)
+	}
+
+	// Handle resume from checkpoint (a run over -only-ids neither resumes nor
+	// moves the checkpoint of the full run)
+	startIndex := 0
+	useCheckpoint := len(s.config.OnlyIDs) == 0
+	if !useCheckpoint {
+		s.logger.Info("processing selected vehicles only, checkpoint disabled", "ids", s.config.OnlyIDs)
+	} else if checkpoint, err := s.checkpoint.Load(ctx); err != nil {
+		s.logger.Warn("failed to load checkpoint, starting fresh", "error", err)
+	} else if checkpoint != nil {
+		s.logger.Info("resuming from checkpoint",
+			"last_id", checkpoint.LastProcessedID,
+			"saved_at", checkpoint.SavedAt,
+		)
+		// Find index of last processed vehicle
+		for i, v := range vehicles {
+			if v.CodigoAplicacao == checkpoint.LastProcessedID {
+				startIndex = i + 1
+				break
 			}
 		}
 	}
@@ -178,31 +710,18 @@ func (s *ScraperService) Run(ctx context.Context) error {
 		"skipped", startIndex,
 	)
 
-	// Initialize progress tracker
-	s.progress = NewProgressTracker(len(vehiclesToProcess))
-
-	// Start HTTP monitoring server if enabled
-	if s.config.EnableMonitoring {
-		s.monitor = NewHTTPMonitor(s.config.HTTPMonitorPort, s.progress)
-		if err := s.monitor.Start(); err != nil {
-			s.logger.Warn("failed to start HTTP monitor", "error", err)
-		} else {
-			s.logger.Info("HTTP monitoring started", "port", s.config.HTTPMonitorPort)
-			defer func() {
-				s.monitor.Stop(context.Background())
-			}()
-		}
+	stop, err := s.start(len(vehiclesToProcess))
+	if err != nil {
+		return err
 	}
+	defer stop()
 
 	// Create work queue
 	workQueue := make(chan model.Aplicacao, s.config.Workers*2)
 	var wg sync.WaitGroup
 
 	// Start workers
-	for i := 0; i < s.config.Workers; i++ {
-		wg.Add(1)
-		go s.worker(ctx, i, workQueue, &wg)
-	}
+	s.startWorkers(ctx, workQueue, &wg)
 
 	// Feed work queue
 	checkpointCounter := 0
@@ -217,6 +736,7 @@ func (s *ScraperService) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			s.logger.Info("context cancelled, stopping...")
+			s.control.stopFeeding()
 			close(workQueue)
 			wg.Wait()
 			return ctx.Err()
@@ -234,8 +754,8 @@ func (s *ScraperService) Run(ctx context.Context) error {
 			}
 
 			// Save checkpoint periodically
-			if checkpointCounter%s.config.CheckpointEvery == 0 {
-				if err := s.checkpoint.Save(lastProcessedID, s.progress); err != nil {
+			if useCheckpoint && checkpointCounter%s.config.CheckpointEvery == 0 {
+				if err := s.checkpoint.Save(ctx, lastProcessedID, s.progress.Load()); err != nil {
 					s.logger.Warn("failed to save checkpoint", "error", err)
 				} else {
 					s.logger.Info("checkpoint saved", "last_id", lastProcessedID)
@@ -245,12 +765,15 @@ func (s *ScraperService) Run(ctx context.Context) error {
 	}
 
 	// Close queue and wait for workers
+	s.control.stopFeeding()
 	close(workQueue)
 	wg.Wait()
 
-	// Final checkpoint save
-	if err := s.checkpoint.Save(lastProcessedID, s.progress); err != nil {
-		s.logger.Warn("failed to save final checkpoint", "error", err)
+	// Final checkpoint save (also on shutdown, when ctx is already cancelled)
+	if useCheckpoint {
+		if err := s.checkpoint.Save(context.WithoutCancel(ctx), lastProcessedID, s.progress.Load()); err != nil {
+			s.logger.Warn("failed to save final checkpoint", "error", err)
+		}
 	}
 
 	// Print final statistics
@@ -259,24 +782,194 @@ func (s *ScraperService) Run(ctx context.Context) error {
 	return nil
 }
 
+// loadVehicles returns every vehicle, only the ones in config.OnlyIDs, or only
+// the ones added after config.Since
+func (s *ScraperService) loadVehicles(ctx context.Context) ([]model.Aplicacao, error) {
+	if len(s.config.OnlyIDs) > 0 {
+		vehicles := make([]model.Aplicacao, 0, len(s.config.OnlyIDs))
+		for _, id := range s.config.OnlyIDs {
+			vehicle, err := s.vehicleRepo.GetVehicleByID(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("vehicle %d: %w", id, err)
+			}
+			if vehicle == nil {
+				return nil, fmt.Errorf("vehicle %d not found", id)
+			}
+			vehicles = append(vehicles, *vehicle)
+		}
+		return vehicles, nil
+	}
+	if s.config.Since.IsZero() {
+		return s.vehicleRepo.GetAllVehicles(ctx)
+	}
+
+	incremental, ok := s.vehicleRepo.(IncrementalVehicleRepository)
+	if !ok {
+		return nil, fmt.Errorf("vehicle source does not track when vehicles were added; differential runs need the Wega DB")
+	}
+	s.logger.Info("differential run", "since", s.config.Since)
+	return incremental.GetVehiclesCreatedSince(ctx, s.config.Since)
+}
+
+// runDistributed processes the vehicles claimed from the shared work queue
+func (s *ScraperService) runDistributed(ctx context.Context, vehicles []model.Aplicacao) error {
+	pending, err := s.enqueueVehicles(ctx, vehicles)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue vehicles: %w", err)
+	}
+
+	stop, err := s.start(pending)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	workQueue := make(chan model.Aplicacao, s.config.Workers*2)
+	var wg sync.WaitGroup
+	s.startWorkers(ctx, workQueue, &wg)
+
+	feedErr := s.feedFromQueue(ctx, vehicles, workQueue)
+
+	s.control.stopFeeding()
+	close(workQueue)
+	wg.Wait()
+
+	// Hand back vehicles claimed but not processed (cancellation, claim error)
+	s.releaseClaims(context.WithoutCancel(ctx))
+
+	if feedErr != nil {
+		if ctx.Err() != nil {
+			s.logger.Info("context cancelled, stopping...")
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to claim vehicles: %w", feedErr)
+	}
+
+	s.printFinalStats()
+	return nil
+}
+
+// startWorkers starts the workers; more can be added by /control/workers while feeding
+func (s *ScraperService) startWorkers(ctx context.Context, workQueue <-chan model.Aplicacao, wg *sync.WaitGroup) {
+	s.control.start(func(id int) {
+		wg.Add(1)
+		go s.worker(ctx, id, workQueue, wg)
+	})
+}
+
+// start initializes progress tracking, the audit log and the HTTP monitor for
+// total vehicles. The returned function closes what was opened.
+func (s *ScraperService) start(total int) (func(), error) {
+	// Initialize progress tracker
+	progress := NewProgressTracker(total)
+	s.progress.Store(progress)
+	s.notify(WebhookRunStarted,
+		fmt.Sprintf("Scraper run started: %d vehicles, %d workers", total, s.config.Workers),
+		RunStartedData{Total: total, DryRun: s.config.DryRun, Workers: s.config.Workers},
+	)
+
+	var closers []func()
+	stop := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	// Open audit log if configured
+	if s.config.AuditFile != "" {
+		audit, err := NewAuditLogger(s.config.AuditFile)
+		if err != nil {
+			return nil, err
+		}
+		s.audit = audit
+		closers = append(closers, func() { audit.Close() })
+	}
+
+	// Collect the dry-run report; written on stop, also when the run is cancelled
+	if s.config.DryRun && s.config.DryRunReport != "" {
+		s.dryRun = NewDryRunReport(s.config.DryRunReport, s.provider.Name())
+		closers = append(closers, s.writeDryRunReport)
+	}
+
+	// Start HTTP monitoring server if enabled
+	if s.config.EnableMonitoring {
+		s.monitor = NewHTTPMonitor(s.config.HTTPMonitorPort, progress)
+		s.events = NewEventHub()
+		s.monitor.SetEventHub(s.events)
+		s.monitor.SetSuccessRateMonitor(s.successRate)
+		s.monitor.SetControl(s.control)
+		s.monitor.SetControlToken(s.config.ControlToken)
+		if s.config.DebugEndpoints {
+			if err := s.monitor.EnableDebug(); err != nil {
+				s.logger.Warn("debug endpoints not enabled", "error", err)
+			}
+		}
+		if s.rateSource != nil {
+			s.monitor.SetRateSource(s.rateSource)
+		}
+		if s.keyHealth != nil {
+			s.monitor.SetKeyHealthSource(s.keyHealth)
+		}
+		if err := s.monitor.Start(); err != nil {
+			s.logger.Warn("failed to start HTTP monitor", "error", err)
+		} else {
+			s.logger.Info("HTTP monitoring started", "port", s.config.HTTPMonitorPort)
+			closers = append(closers, func() { s.monitor.Stop(context.Background()) })
+		}
+	}
+
+	return stop, nil
+}
+
 // worker processes vehicles from the work queue
 func (s *ScraperService) worker(ctx context.Context, id int, queue <-chan model.Aplicacao, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	s.logger.Info("worker started", "worker_id", id)
 
-	rateLimiter := time.NewTicker(s.config.RateLimit)
+	rateLimit := s.control.RateLimit()
+	rateLimiter := time.NewTicker(rateLimit)
 	defer rateLimiter.Stop()
 
 	processedCount := 0
-	for vehicle := range queue {
-		// Rate limiting
+	for {
+		// Block while paused by an operator or parked by a lower worker count
+		if err := s.control.Wait(ctx, id); err != nil {
+			if errors.Is(err, errWorkerRetired) {
+				s.logger.Info("worker retired", "worker_id", id, "total_processed", processedCount)
+			} else {
+				s.logger.Info("worker stopping while paused", "worker_id", id)
+			}
+			return
+		}
+
+		vehicle, ok := <-queue
+		if !ok {
+			break
+		}
+
+		// Block while paused by the success-rate guard
+		if err := s.successRate.WaitIfPaused(ctx); err != nil {
+			s.logger.Info("worker stopping while paused", "worker_id", id)
+			return
+		}
+
+		// Rate limiting (changed at runtime by /control/rate-limit)
+		if current := s.control.RateLimit(); current != rateLimit {
+			rateLimit = current
+			rateLimiter.Reset(rateLimit)
+		}
 		<-rateLimiter.C
 
 		// Process vehicle
 		s.processVehicle(ctx, vehicle)
 		processedCount++
 
+		// Interrupted vehicles stay claimed and are released for another run
+		if ctx.Err() == nil {
+			s.completeClaim(ctx, vehicle.CodigoAplicacao)
+		}
+
 		// Log progress every 100 vehicles per worker
 		if processedCount%100 == 0 {
 			s.logger.Info("worker progress",
@@ -297,257 +990,545 @@ func (s *ScraperService) worker(ctx context.Context, id int, queue <-chan model.
 	s.logger.Info("worker finished", "worker_id", id, "total_processed", processedCount)
 }
 
-// commercialVehiclePatterns contains patterns to skip (trucks, buses, tractors, etc.)
-// These vehicles typically don't exist in Motul's car catalog
-var commercialVehiclePatterns = []string{
-	// Truck model patterns (more generic)
-	"cargo", "constellation", "worker", "delivery",
-	"fh ", "fh-", "fm ", "fm-", "fmx", "vm ", "vm-", "nh12", "nh ", "edc",
-	"axor", "atego", "actros", "arocs",
-	"stralis", "trakker", "eurocargo",
-	"serie p", "serie g", "serie r", "serie s",
-	// Bus models
-	"of-", "o-", "volare", "busscar", "mascarello",
-	"marcopolo", "neobus", "caio", "comil",
-	// Tractors/Agricultural
-	"trator", "colheitadeira", "retroescavadeira",
-	"mf ", "massey", "new holland", "case ih", "john deere",
-	"valtra", "ls tractor",
-	// Heavy equipment
-	"escavadeira", "pa carregadeira", "motoniveladora",
-	"rolo compactador", "guindaste", "empilhadeira",
-	"compressor", "gerador",
-	// Specific commercial brands/series
-	"9200", "9800", "4700", "8600", // International trucks
-	"series ", "hr ", "hd ",
-	// Ford trucks (various formats)
-	"f-350", "f-4000", "f-14000", "f350", "f4000", "f14000",
-	"fb4000", "fb-4000", "f 4000", "fb 4000",
-	// Chevrolet/GM trucks
-	"d-20", "d20", "d-40", "d40", "d-60", "d60",
-	"c-10", "c10", "c-60", "c60", "c-15", "c15",
-	// VW trucks (numeric models)
-	"5.140", "6.80", "6.90", "7.90", "7.100", "7.110", "7.120",
-	"8.120", "8.140", "8.150", "8.160",
-	"9.150", "9.170", "10.160", "11.130", "11.180", "12.140", "13.150", "13.180",
-	"15.170", "15.180", "15.190", "16.200", "17.180", "17.190", "17.210", "17.220", "17.230", "17.250", "17.280", "17.310",
-	"18.310", "19.320", "19.330", "19.360", "19.390", "19.420",
-	"23.210", "23.220", "23.230", "23.250", "23.310", "24.250", "24.280", "24.310",
-	"25.320", "25.360", "25.370", "25.390", "25.420", "26.260", "26.280", "26.310",
-	"31.260", "31.280", "31.310", "31.320", "31.330", "31.370", "31.390", "31.420",
-	"furgovan", "kombi furgao",
-	// Agrale specific
-	"6000", "7000", "8000", "8500", "9200", "10000", "13000", "14000",
-}
-
-// commercialBrands are brands that are primarily commercial/industrial vehicles
-var commercialBrands = []string{
-	// Truck manufacturers
-	"scania", "daf", "man", "iveco",
-	"international", "navistar", "freightliner", "kenworth", "peterbilt",
-	"hino", "isuzu trucks", "ud trucks", "fuso",
-	// Industrial/Equipment
-	"atlas copco", "caterpillar", "komatsu", "jcb", "bobcat",
-	"case", "new holland", "massey ferguson", "john deere", "valtra",
-	"agrale",                      // Mostly trucks/buses
-	"cummins", "perkins", "deutz", // Engines
-	// Motorcycle brands (also not in Motul car catalog)
-	"yamaha", "honda motos", "suzuki motos", "kawasaki", "harley",
-	"bmw motorrad", "ducati", "triumph", "ktm",
-}
-
-// isCommercialVehicle checks if a vehicle is a commercial vehicle that should be skipped
-func (s *ScraperService) isCommercialVehicle(brand, model, description string) bool {
-	// Normalize all to lowercase for comparison
-	brandLower := strings.ToLower(brand)
-	modelLower := strings.ToLower(model)
-	descLower := strings.ToLower(description)
-
-	// Check brand
-	for _, cb := range commercialBrands {
-		if strings.Contains(brandLower, cb) {
+// categoryEnabled reports whether vehicles of category are scraped in this run
+func (s *ScraperService) categoryEnabled(category string) bool {
+	categories := s.config.Categories
+	if len(categories) == 0 {
+		categories = []string{client.CategoryCar}
+	}
+	for _, c := range categories {
+		if c == category {
 			return true
 		}
 	}
+	return false
+}
 
-	// Check model patterns
-	combined := modelLower + " " + descLower
-	for _, pattern := range commercialVehiclePatterns {
-		if strings.Contains(combined, pattern) {
-			return true
-		}
+// prioritizePopular orders vehicles by API popularity score (highest first),
+// keeping ID order among vehicles with the same score. Checkpoints resume by
+// position in this order, which shifts only as scores change between runs.
+func (s *ScraperService) prioritizePopular(ctx context.Context, vehicles []model.Aplicacao) {
+	if s.popularity == nil {
+		s.logger.Warn("popularity ordering requested but no popularity source is configured")
+		return
 	}
 
-	return false
+	scores, err := s.popularity.AllScores(ctx)
+	if err != nil {
+		s.logger.Warn("failed to load popularity scores, keeping ID order", "error", err)
+		return
+	}
+
+	sort.SliceStable(vehicles, func(i, j int) bool {
+		return scores[vehicles[i].CodigoAplicacao] > scores[vehicles[j].CodigoAplicacao]
+	})
+	s.logger.Info("vehicles ordered by popularity", "with_score", len(scores))
 }
 
 // processVehicle handles a single vehicle scraping
 func (s *ScraperService) processVehicle(ctx context.Context, vehicle model.Aplicacao) {
+	ctx, span := tracer.Start(ctx, "scraper.process_vehicle",
+		trace.WithAttributes(attribute.Int("wega.codigo_aplicacao", vehicle.CodigoAplicacao)))
+	defer span.End()
+
 	s.logger.Info("processing vehicle",
 		"id", vehicle.CodigoAplicacao,
 		"description", vehicle.DescricaoAplicacao[:min(50, len(vehicle.DescricaoAplicacao))],
 	)
 
-	s.progress.SetCurrentVehicle(vehicle.DescricaoAplicacao)
-	s.progress.IncrementProcessed()
+	s.progress.Load().SetCurrentVehicle(vehicle.DescricaoAplicacao)
+	s.progress.Load().IncrementProcessed()
+
+	timings := make(StageTimings)
+	record := AuditRecord{
+		CodigoAplicacao: vehicle.CodigoAplicacao,
+		Descricao:       vehicle.DescricaoAplicacao,
+	}
+	defer s.finishVehicle(ctx, &record, timings)
 
-	// Parse vehicle data early to check if it's commercial
-	brand, modelName, year, parseErr := s.parseVehicleDescription(vehicle)
+	// Parse vehicle data early to pick its Motul category
+	start := time.Now()
+	brand, modelName, year, parseErr := parseVehicleDescription(vehicle)
+	timings[StageParse] = time.Since(start)
 
-	// Skip commercial vehicles (trucks, buses, tractors) - they're not in Motul car catalog
-	if parseErr == nil && s.isCommercialVehicle(brand, modelName, vehicle.DescricaoAplicacao) {
-		s.logger.Info("skipping commercial vehicle",
+	s.logger.Debug("parsed vehicle",
+		"id", vehicle.CodigoAplicacao,
+		"fabricante", vehicle.Fabricante,
+		"modelo", vehicle.Modelo,
+		"description", vehicle.DescricaoAplicacao,
+		"motor", vehicle.Motor,
+		"periodo", vehicle.Periodo,
+		"brand", brand,
+		"model", modelName,
+		"year", year,
+		"error", parseErr,
+	)
+
+	// Vehicles without a parseable year are still searched, just without a year filter
+	if parseErr == nil && year == 0 {
+		s.progress.Load().IncrementUnknownYear()
+		record.UnknownYear = true
+	}
+
+	// Skip vehicles on the skip list and those whose category is not loaded (by default only cars are)
+	category, skip := client.CategoryCar, false
+	if parseErr == nil {
+		category, skip = s.rules.Load().Classify(brand, modelName, vehicle.DescricaoAplicacao)
+		if llmCategory, ok := s.llmCategory[vehicle.CodigoAplicacao]; ok {
+			category = llmCategory
+		}
+		record.Category = category
+	}
+	if parseErr == nil && skip {
+		s.logger.Info("skipping vehicle on the skip list",
+			"id", vehicle.CodigoAplicacao,
+			"brand", brand,
+			"model", modelName,
+		)
+		s.progress.Load().IncrementSkipped()
+		record.Outcome = AuditOutcomeSkipped
+		s.recordDryRun(vehicle, DryRunRuleSkip, brand, modelName, year, category, nil)
+		return
+	}
+	if parseErr == nil && !s.categoryEnabled(category) {
+		s.logger.Info("skipping vehicle outside enabled categories",
 			"id", vehicle.CodigoAplicacao,
 			"brand", brand,
 			"model", modelName,
+			"category", category,
 		)
-		s.progress.IncrementSkipped()
+		s.progress.Load().IncrementSkipped()
+		record.Outcome = AuditOutcomeSkipped
+		s.recordDryRun(vehicle, DryRunCategorySkip, brand, modelName, year, category, nil)
 		return
 	}
 
-	// Check if specs already exist for this vehicle
-	if s.specRepo != nil {
-		exists, err := s.specRepo.ExistsForVehicle(ctx, vehicle.CodigoAplicacao)
+	// Check if specs already exist for this vehicle (and whether they are stale);
+	// vehicles picked with -only-ids are always re-scraped
+	if s.sink != nil && len(s.config.OnlyIDs) == 0 {
+		fresh, stale, err := s.hasFreshSpecs(ctx, vehicle.CodigoAplicacao)
 		if err != nil {
 			s.logger.Warn("failed to check existing specs", "id", vehicle.CodigoAplicacao, "error", err)
-		} else if exists {
+		} else if fresh {
 			s.logger.Debug("specs already exist, skipping", "id", vehicle.CodigoAplicacao)
-			s.progress.IncrementSkipped()
+			s.progress.Load().IncrementSkipped()
+			record.Outcome = AuditOutcomeSkipped
+			s.recordDryRun(vehicle, DryRunAlreadyScraped, brand, modelName, year, category, parseErr)
 			return
+		} else if stale {
+			s.logger.Info("specs are stale, refreshing", "id", vehicle.CodigoAplicacao)
 		}
 	}
 
-	// Check parse error (we already parsed above for commercial check)
+	// Check parse error (we already parsed above for the category check)
 	if parseErr != nil {
 		s.logger.Debug("failed to parse vehicle",
 			"id", vehicle.CodigoAplicacao,
 			"description", vehicle.DescricaoAplicacao,
 			"error", parseErr,
 		)
-		s.progress.IncrementSkipped()
+		s.progress.Load().IncrementSkipped()
+		record.Outcome = AuditOutcomeSkipped
+		record.Error = parseErr.Error()
+		s.recordDryRun(vehicle, DryRunParseFailed, "", "", 0, "", parseErr)
 		return
 	}
 
 	// Skip if dry run
 	if s.config.DryRun {
-		s.logger.Info("dry run - would search Motul",
+		s.logger.Info("dry run - would search provider",
+			"provider", s.provider.Name(),
 			"brand", brand,
 			"model", modelName,
 			"year", year,
 		)
-		s.progress.IncrementSuccess()
+		s.progress.Load().IncrementSuccess()
+		record.Outcome = AuditOutcomeDryRun
+		s.recordDryRun(vehicle, DryRunWouldSearch, brand, modelName, year, category, nil)
 		return
 	}
 
-	// Search Motul API
-	s.progress.IncrementRequests()
-	motulVehicle, err := s.motulClient.SearchVehicle(ctx, brand, modelName, year)
+	if year == 0 {
+		s.logger.Debug("no parseable year, searching without year",
+			"id", vehicle.CodigoAplicacao,
+			"periodo", vehicle.Periodo,
+		)
+	}
+
+	// Reuse the Motul type of a nearly identical, already matched application;
+	// otherwise search the spec provider
+	providerVehicle := s.matchNeighbor(ctx, vehicle, year, timings)
+	var err error
+	if providerVehicle == nil {
+		s.progress.Load().IncrementRequests()
+		providerVehicle, err = s.provider.SearchVehicle(ctx, category, brand, modelName, year)
+	}
 	if err != nil {
-		s.logger.Warn("Motul API search failed",
+		s.logger.Warn("provider search failed",
+			"provider", s.provider.Name(),
 			"id", vehicle.CodigoAplicacao,
 			"brand", brand,
 			"model", modelName,
 			"year", year,
 			"error", err,
 		)
-		s.progress.IncrementFailed(err.Error())
+		s.progress.Load().IncrementFailed(FailureReasonSearch, vehicle.DescricaoAplicacao, err.Error())
 		s.saveFailure(ctx, vehicle.CodigoAplicacao, err.Error())
+		record.Outcome = AuditOutcomeFailed
+		record.Error = err.Error()
 		return
 	}
 
-	if motulVehicle == nil {
-		s.logger.Debug("no match found in Motul",
+	if providerVehicle == nil {
+		s.logger.Debug("no match found in provider",
+			"provider", s.provider.Name(),
 			"id", vehicle.CodigoAplicacao,
 			"brand", brand,
 			"model", modelName,
 			"year", year,
 		)
-		s.progress.IncrementNoMatch()
+		s.progress.Load().IncrementNoMatch()
+		record.Outcome = AuditOutcomeNoMatch
 		return
 	}
 
+	for stage, d := range providerVehicle.Timings {
+		timings[stage] = d
+	}
+	record.MotulVehicleTypeID = providerVehicle.ID
+	record.MatchMethod = providerVehicle.MotorType
+	record.PromptTruncated = providerVehicle.PromptTruncated
+
 	// Determine match type and log
 	matchMethod := "fuzzy"
-	if s.isExactMatch(vehicle, motulVehicle) {
+	if s.isExactMatch(vehicle, providerVehicle) {
 		matchMethod = "exact"
-		s.progress.IncrementExactMatch()
+		s.progress.Load().IncrementExactMatch()
 	} else {
-		s.progress.IncrementFuzzyMatch()
+		s.progress.Load().IncrementFuzzyMatch()
 	}
 
 	s.logger.Info(matchMethod+" match",
 		"id", vehicle.CodigoAplicacao,
 		"wega", vehicle.DescricaoAplicacao,
-		"motul", motulVehicle.Description,
+		"provider", providerVehicle.Description,
+		"confidence", providerVehicle.Confidence,
 	)
 
-	// Fetch specifications from Motul
-	specs, err := s.motulClient.GetSpecifications(ctx, motulVehicle.ID)
+	// Weak fuzzy matches (e.g. the LLM fallback's first option) are kept out
+	// of the sink and queued for review; exact matches are always trusted
+	if matchMethod != "exact" && providerVehicle.Confidence > 0 &&
+		providerVehicle.Confidence < s.config.MinSaveConfidence {
+		msg := fmt.Sprintf("low confidence match: %.2f below %.2f (%s: %s)",
+			providerVehicle.Confidence, s.config.MinSaveConfidence, providerVehicle.MotorType, providerVehicle.Description)
+		s.logger.Info("match below minimum confidence, not saving",
+			"id", vehicle.CodigoAplicacao,
+			"confidence", providerVehicle.Confidence,
+			"match_method", providerVehicle.MotorType,
+		)
+		s.progress.Load().IncrementFailed(FailureReasonLowConf, vehicle.DescricaoAplicacao, msg)
+		s.saveFailure(ctx, vehicle.CodigoAplicacao, msg)
+		record.Outcome = AuditOutcomeFailed
+		record.Error = msg
+		return
+	}
+
+	// Fetch specifications from the provider
+	start = time.Now()
+	specs, err := s.provider.GetSpecifications(ctx, providerVehicle.ID)
+	timings[StageSpecFetch] = time.Since(start)
 	if err != nil {
 		s.logger.Warn("failed to get specifications",
 			"id", vehicle.CodigoAplicacao,
-			"motul_id", motulVehicle.ID,
+			"provider_id", providerVehicle.ID,
 			"error", err,
 		)
-		s.progress.IncrementFailed("specs_fetch_error")
+		reason := FailureReasonSpecsFetch
+		if errors.Is(err, client.ErrSchemaDrift) {
+			reason = FailureReasonSchema
+		}
+		s.progress.Load().IncrementFailed(reason, vehicle.DescricaoAplicacao, err.Error())
 		s.saveFailure(ctx, vehicle.CodigoAplicacao, "specs_fetch_error: "+err.Error())
+		record.Outcome = AuditOutcomeFailed
+		record.Error = err.Error()
 		return
 	}
 
 	if len(specs) == 0 {
 		s.logger.Debug("no specifications found",
 			"id", vehicle.CodigoAplicacao,
-			"motul_id", motulVehicle.ID,
+			"provider_id", providerVehicle.ID,
 		)
-		s.progress.IncrementNoMatch()
+		s.progress.Load().IncrementNoMatch()
+		record.Outcome = AuditOutcomeNoMatch
 		return
 	}
 
-	// Save specifications to database
-	if s.specRepo != nil {
+	for _, spec := range specs {
+		s.logger.Debug("specification parsed",
+			"id", vehicle.CodigoAplicacao,
+			"provider_id", providerVehicle.ID,
+			"tipo", spec.TipoFluido,
+			"condicao", spec.Condicao,
+			"viscosidade", spec.Viscosidade,
+			"capacidade", spec.Capacidade,
+			"norma", spec.Norma,
+			"recomendacao", spec.Recomendacao,
+		)
+	}
+
+	// Normalize and validate before saving
+	var rejected string
+	if s.validator != nil {
+		specs, rejected = s.validateSpecs(specs)
+		if rejected != "" {
+			s.logger.Warn("specifications rejected by validation",
+				"id", vehicle.CodigoAplicacao,
+				"provider_id", providerVehicle.ID,
+				"rejects", rejected,
+			)
+		}
+		if len(specs) == 0 {
+			msg := "spec validation: " + rejected
+			s.progress.Load().IncrementFailed(FailureReasonInvalidSpecs, vehicle.DescricaoAplicacao, msg)
+			s.saveFailure(ctx, vehicle.CodigoAplicacao, msg)
+			record.Outcome = AuditOutcomeFailed
+			record.Error = msg
+			return
+		}
+	}
+
+	// Save specifications to the sink
+	if s.sink != nil {
 		confidence := 0.85
 		if matchMethod == "exact" {
 			confidence = 0.95
+		} else if providerVehicle.Confidence > 0 {
+			confidence = providerVehicle.Confidence
 		}
 
-		savedCount := 0
+		start = time.Now()
+		savedCount, unchangedCount, inactiveCount := 0, 0, 0
+		var lastSaveErr error
 		for _, spec := range specs {
 			especificacao := &model.EspecificacaoTecnica{
-				CodigoAplicacao:    vehicle.CodigoAplicacao,
-				TipoFluido:         spec.TipoFluido,
-				Viscosidade:        strPtr(spec.Viscosidade),
-				Capacidade:         strPtr(spec.Capacidade),
-				Norma:              strPtr(spec.Norma),
-				Recomendacao:       strPtr(spec.Recomendacao),
-				Fonte:              "motul",
-				MotulVehicleTypeID: strPtr(motulVehicle.ID),
-				MatchConfidence:    &confidence,
+				CodigoAplicacao:     vehicle.CodigoAplicacao,
+				TipoFluido:          spec.TipoFluido,
+				Condicao:            spec.Condicao,
+				Viscosidade:         strPtr(spec.Viscosidade),
+				Capacidade:          strPtr(spec.Capacidade),
+				Norma:               strPtr(spec.Norma),
+				Recomendacao:        strPtr(spec.Recomendacao),
+				Observacao:          strPtr(spec.Observacao),
+				IntervaloTrocaKm:    intPtr(spec.IntervaloTrocaKm),
+				IntervaloTrocaMeses: intPtr(spec.IntervaloTrocaMeses),
+				Fonte:               s.provider.Name(),
+				MotulVehicleTypeID:  strPtr(providerVehicle.ID),
+				MatchConfidence:     &confidence,
+				ScraperRunID:        s.scraperRunID(),
 			}
 
-			if err := s.specRepo.Insert(ctx, especificacao); err != nil {
+			// Upsert keeps re-runs (resume, deleted checkpoint, refresh) from duplicating rows
+			if err := s.sink.Upsert(ctx, especificacao); err != nil {
 				s.logger.Warn("failed to save specification",
 					"id", vehicle.CodigoAplicacao,
 					"tipo", spec.TipoFluido,
+					"condicao", spec.Condicao,
 					"error", err,
 				)
+				lastSaveErr = err
 				continue
 			}
 			savedCount++
+			switch especificacao.Gravacao {
+			case model.GravacaoInalterada:
+				unchangedCount++
+			case model.GravacaoInativa:
+				inactiveCount++
+			}
+		}
+		timings[StageSave] = time.Since(start)
+
+		if savedCount == 0 && lastSaveErr != nil {
+			s.progress.Load().IncrementFailed(FailureReasonSave, vehicle.DescricaoAplicacao, lastSaveErr.Error())
+			record.Outcome = AuditOutcomeFailed
+			record.Error = lastSaveErr.Error()
+			return
 		}
 
 		s.logger.Info("saved specifications",
 			"id", vehicle.CodigoAplicacao,
 			"count", savedCount,
+			"unchanged", unchangedCount,
+			"inactive", inactiveCount,
 			"total", len(specs),
 		)
 
-		// Mark any previous failure as resolved
-		if savedCount > 0 {
+		// Mark any previous failure as resolved; partly rejected specs stay open for review
+		if rejected != "" {
+			s.saveFailure(ctx, vehicle.CodigoAplicacao, "spec validation: "+rejected)
+		} else if savedCount > 0 {
 			s.markFailureResolved(ctx, vehicle.CodigoAplicacao)
 		}
 	}
 
-	s.progress.IncrementSuccess()
+	s.progress.Load().IncrementSuccess()
+	record.Outcome = AuditOutcomeSuccess
+}
+
+// validateSpecs runs the spec validator and returns the specs that passed
+// with a summary of the rejects ("" when nothing was rejected)
+func (s *ScraperService) validateSpecs(specs []OilSpecification) ([]OilSpecification, string) {
+	in := make([]parser.OilSpec, len(specs))
+	for i, spec := range specs {
+		in[i] = parser.OilSpec{
+			TipoFluido:   spec.TipoFluido,
+			Condicao:     spec.Condicao,
+			Viscosidade:  spec.Viscosidade,
+			Capacidade:   spec.Capacidade,
+			Norma:        spec.Norma,
+			Recomendacao: spec.Recomendacao,
+			Observacao:   spec.Observacao,
+			Intervalo:    parser.DrainInterval{Km: spec.IntervaloTrocaKm, Months: spec.IntervaloTrocaMeses},
+		}
+	}
+
+	valid, rejects := s.validator.Validate(in)
+	out := make([]OilSpecification, len(valid))
+	for i, spec := range valid {
+		out[i] = OilSpecification{
+			TipoFluido:          spec.TipoFluido,
+			Condicao:            spec.Condicao,
+			Viscosidade:         spec.Viscosidade,
+			Capacidade:          spec.Capacidade,
+			Norma:               spec.Norma,
+			Recomendacao:        spec.Recomendacao,
+			Observacao:          spec.Observacao,
+			IntervaloTrocaKm:    spec.Intervalo.Km,
+			IntervaloTrocaMeses: spec.Intervalo.Months,
+		}
+	}
+
+	reasons := make([]string, len(rejects))
+	for i, r := range rejects {
+		reasons[i] = r.String()
+	}
+	return out, strings.Join(reasons, "; ")
+}
+
+// finishVehicle feeds stage timings to the progress tracker, the outcome to the
+// success-rate guard and the attempt recorder, publishes the vehicle event and
+// writes the audit record
+func (s *ScraperService) finishVehicle(ctx context.Context, record *AuditRecord, timings StageTimings) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("scraper.outcome", record.Outcome),
+		attribute.String("scraper.match_method", record.MatchMethod),
+	)
+	if record.Outcome == AuditOutcomeFailed {
+		span.SetStatus(codes.Error, record.Error)
+	}
+
+	for stage, d := range timings {
+		s.progress.Load().RecordStage(stage, d)
+	}
+
+	switch record.Outcome {
+	case AuditOutcomeSuccess:
+		s.successRate.Record(true)
+	case AuditOutcomeFailed, AuditOutcomeNoMatch:
+		s.successRate.Record(false)
+		s.checkFailureThreshold()
+	}
+
+	switch record.Outcome {
+	case AuditOutcomeSuccess, AuditOutcomeFailed, AuditOutcomeNoMatch:
+		if s.attempts != nil {
+			if err := s.attempts.RecordAttempt(context.WithoutCancel(ctx), record.CodigoAplicacao, record.Outcome); err != nil {
+				s.logger.Debug("failed to record attempt", "id", record.CodigoAplicacao, "error", err)
+			}
+		}
+	}
+
+	if s.audit == nil && s.events == nil {
+		return
+	}
+
+	record.Timestamp = time.Now()
+	record.TimingsMs = make(map[string]float64, len(timings))
+	for stage, d := range timings {
+		record.TimingsMs[stage] = durationMillis(d)
+	}
+	if s.events != nil {
+		s.events.Publish(*record)
+	}
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Write(*record); err != nil {
+		s.logger.Warn("failed to write audit record", "id", record.CodigoAplicacao, "error", err)
+	}
+}
+
+// recordDryRun adds a vehicle to the dry-run report (no-op outside dry runs
+// or without a report path)
+func (s *ScraperService) recordDryRun(vehicle model.Aplicacao, decision, brand, modelName string, year int, category string, parseErr error) {
+	if s.dryRun == nil {
+		return
+	}
+	entry := DryRunVehicle{
+		CodigoAplicacao: vehicle.CodigoAplicacao,
+		Descricao:       vehicle.DescricaoAplicacao,
+		Decision:        decision,
+		Brand:           brand,
+		Model:           modelName,
+		Year:            year,
+		Category:        category,
+	}
+	if parseErr != nil {
+		entry.ParseError = parseErr.Error()
+	}
+	s.dryRun.Add(entry)
+}
+
+// writeDryRunReport writes the dry-run report and logs its summary
+func (s *ScraperService) writeDryRunReport() {
+	if err := s.dryRun.Write(); err != nil {
+		s.logger.Error("failed to write dry-run report", "error", err)
+		return
+	}
+	summary := s.dryRun.Summary()
+	s.logger.Info("dry-run report written",
+		"path", s.config.DryRunReport,
+		"vehicles", summary.Total,
+		"would_search", summary.Decisions[DryRunWouldSearch],
+		"parsed", summary.Parsed,
+		"parse_failed", summary.ParseFailed,
+		"commercial", summary.Commercial,
+		"llm_requests_max", summary.Estimate.LLMRequestsMax,
+	)
+}
+
+// hasFreshSpecs reports whether the vehicle already has specs that should not be re-scraped.
+// stale is true when specs exist but are older than RefreshOlderThan.
+func (s *ScraperService) hasFreshSpecs(ctx context.Context, codigoAplicacao int) (fresh, stale bool, err error) {
+	if s.config.RefreshOlderThan <= 0 {
+		exists, err := s.sink.ExistsForVehicle(ctx, codigoAplicacao)
+		return exists, false, err
+	}
+
+	lastUpdated, err := s.sink.LastUpdatedForVehicle(ctx, codigoAplicacao)
+	if err != nil {
+		return false, false, err
+	}
+	if lastUpdated == nil {
+		return false, false, nil
+	}
+
+	if time.Since(*lastUpdated) > s.config.RefreshOlderThan {
+		return false, true, nil
+	}
+	return true, false, nil
 }
 
 // strPtr returns a pointer to a string, or nil if empty
@@ -558,8 +1539,16 @@ func strPtr(s string) *string {
 	return &s
 }
 
+// intPtr returns nil for 0 (unknown)
+func intPtr(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
 // parseVehicleDescription extracts brand, model, and year from vehicle description
-func (s *ScraperService) parseVehicleDescription(vehicle model.Aplicacao) (brand, modelName string, year int, err error) {
+func parseVehicleDescription(vehicle model.Aplicacao) (brand, modelName string, year int, err error) {
 	// Use brand from Fabricante field if available
 	brand = vehicle.Fabricante
 	if brand == "" {
@@ -608,36 +1597,48 @@ func (s *ScraperService) parseVehicleDescription(vehicle model.Aplicacao) (brand
 	}
 
 	// Normalize strings
-	brand = s.normalizeString(brand)
-	modelName = s.normalizeString(modelName)
+	brand = normalize.Display.Apply(brand)
+	modelName = normalize.Display.Apply(modelName)
 
 	return brand, modelName, year, nil
 }
 
-// normalizeString removes accents and normalizes text
-func (s *ScraperService) normalizeString(text string) string {
-	// Remove accents
-	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-	normalized, _, _ := transform.String(t, text)
-
-	// Trim and convert to title case
-	normalized = strings.TrimSpace(normalized)
-	return strings.Title(strings.ToLower(normalized))
-}
-
 // isExactMatch determines if Wega and Motul vehicles are an exact match
-func (s *ScraperService) isExactMatch(wega model.Aplicacao, motul *MotulVehicle) bool {
+func (s *ScraperService) isExactMatch(wega model.Aplicacao, motul *ProviderVehicle) bool {
 	// Normalize both descriptions
-	wegaDesc := s.normalizeString(wega.DescricaoAplicacao)
-	motulDesc := s.normalizeString(motul.Description)
+	wegaDesc := normalize.Text.Apply(wega.DescricaoAplicacao)
+	motulDesc := normalize.Text.Apply(motul.Description)
+	if motulDesc == "" {
+		return false // Reused from a neighbor: only the type ID is known
+	}
 
 	// Check if descriptions are similar (fuzzy matching could be enhanced)
 	return strings.Contains(wegaDesc, motulDesc) || strings.Contains(motulDesc, wegaDesc)
 }
 
+// checkFailureThreshold notifies once when the run's failed vehicles reach
+// FailureAlertThreshold
+func (s *ScraperService) checkFailureThreshold() {
+	threshold := s.config.FailureAlertThreshold
+	if threshold <= 0 || s.webhooks == nil {
+		return
+	}
+	failed := s.progress.Load().Failed()
+	if failed < threshold || !s.failureAlerted.CompareAndSwap(false, true) {
+		return
+	}
+
+	processed := s.progress.Load().Processed()
+	s.logger.Warn("failed vehicles reached alert threshold", "failed", failed, "threshold", threshold)
+	s.notify(WebhookFailureThreshold,
+		fmt.Sprintf("Scraper failures reached %d (threshold %d) after %d vehicles", failed, threshold, processed),
+		FailureThresholdData{Failed: failed, Threshold: threshold, Processed: processed},
+	)
+}
+
 // printFinalStats prints final scraping statistics
 func (s *ScraperService) printFinalStats() {
-	snapshot := s.progress.GetSnapshot()
+	snapshot := s.progress.Load().GetSnapshot()
 
 	s.logger.Info("scraping completed",
 		"elapsed", snapshot.Elapsed.String(),
@@ -649,11 +1650,34 @@ func (s *ScraperService) printFinalStats() {
 		"exact_match", snapshot.ExactMatch,
 		"fuzzy_match", snapshot.FuzzyMatch,
 		"no_match", snapshot.NoMatch,
+		"unknown_year", snapshot.UnknownYear,
+		"unknown_year_share", fmt.Sprintf("%.4f", snapshot.UnknownYearShare()),
 		"total_requests", snapshot.TotalRequests,
+		"network_errors", snapshot.NetworkErrors,
+		"rate_limit_hits", snapshot.RateLimitHits,
+		"schema_drift", snapshot.SchemaDrift,
+		"llm_tokens", snapshot.LLMTokens,
+		"failures_by_reason", snapshot.FailuresByReason,
+		"error_types", snapshot.ErrorTypes,
+		"dominant_error_type", dominantErrorType(snapshot.ErrorTypes),
+		"stage_latency_ms", stageLatencyMillis(snapshot.StageLatency),
 		"req_per_sec", fmt.Sprintf("%.2f", snapshot.RequestsPerSec),
 	)
 }
 
+// dominantErrorType returns the error type with the highest count, or "" if none
+func dominantErrorType(errorTypes map[string]int) string {
+	dominant := ""
+	maxCount := 0
+	for errType, count := range errorTypes {
+		if count > maxCount || (count == maxCount && errType < dominant) {
+			dominant = errType
+			maxCount = count
+		}
+	}
+	return dominant
+}
+
 // saveFailure records a failed scraping attempt to the database
 func (s *ScraperService) saveFailure(ctx context.Context, codigoAplicacao int, errMsg string) {
 	if s.falhaRepo == nil {
@@ -0,0 +1,186 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Sink names accepted by the -sink flag
+const (
+	SinkDB     = "db"
+	SinkFile   = "file"
+	SinkNDJSON = "ndjson"
+	SinkHTTP   = "http"
+)
+
+// NDJSONSpecSink appends one JSON spec per line to a file
+// Existing lines are read on open so re-runs skip already scraped vehicles.
+type NDJSONSpecSink struct {
+	mu          sync.Mutex
+	file        *os.File
+	lastUpdated map[int]time.Time // codigoAplicacao -> latest AtualizadoEm
+}
+
+// NewNDJSONSpecSink opens (or creates) an NDJSON output file for appending
+func NewNDJSONSpecSink(path string) (*NDJSONSpecSink, error) {
+	s := &NDJSONSpecSink{lastUpdated: make(map[int]time.Time)}
+
+	if existing, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(existing)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var spec model.EspecificacaoTecnica
+			if err := json.Unmarshal(scanner.Bytes(), &spec); err != nil {
+				continue // Tolerate a truncated last line from an interrupted run
+			}
+			s.track(&spec)
+		}
+		existing.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read existing output: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open output: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output: %w", err)
+	}
+	s.file = f
+	return s, nil
+}
+
+// track records the latest update time for a vehicle (caller holds mu or is the constructor)
+func (s *NDJSONSpecSink) track(spec *model.EspecificacaoTecnica) {
+	if last, ok := s.lastUpdated[spec.CodigoAplicacao]; !ok || spec.AtualizadoEm.After(last) {
+		s.lastUpdated[spec.CodigoAplicacao] = spec.AtualizadoEm
+	}
+}
+
+// Upsert implements SpecSink (appends; readers should keep the last line per vehicle/fluid)
+func (s *NDJSONSpecSink) Upsert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	now := time.Now()
+	if spec.CriadoEm.IsZero() {
+		spec.CriadoEm = now
+	}
+	spec.AtualizadoEm = now
+
+	line, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write spec: %w", err)
+	}
+	s.track(spec)
+	return nil
+}
+
+// ExistsForVehicle implements SpecSink
+func (s *NDJSONSpecSink) ExistsForVehicle(ctx context.Context, codigoAplicacao int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.lastUpdated[codigoAplicacao]
+	return ok, nil
+}
+
+// LastUpdatedForVehicle implements SpecSink
+func (s *NDJSONSpecSink) LastUpdatedForVehicle(ctx context.Context, codigoAplicacao int) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.lastUpdated[codigoAplicacao]; ok {
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// Close closes the output file
+func (s *NDJSONSpecSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// HTTPSpecSink POSTs each spec as JSON to an external ingestion endpoint
+// It cannot answer existence queries, so every vehicle is scraped.
+type HTTPSpecSink struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewHTTPSpecSink creates a sink posting to url; token (optional) is sent as a Bearer token
+func NewHTTPSpecSink(url, token string) *HTTPSpecSink {
+	return &HTTPSpecSink{
+		url:        url,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Upsert implements SpecSink
+func (s *HTTPSpecSink) Upsert(ctx context.Context, spec *model.EspecificacaoTecnica) error {
+	now := time.Now()
+	if spec.CriadoEm.IsZero() {
+		spec.CriadoEm = now
+	}
+	spec.AtualizadoEm = now
+
+	body, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post spec: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ingestion endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// ExistsForVehicle implements SpecSink (always false)
+func (s *HTTPSpecSink) ExistsForVehicle(ctx context.Context, codigoAplicacao int) (bool, error) {
+	return false, nil
+}
+
+// LastUpdatedForVehicle implements SpecSink (always nil)
+func (s *HTTPSpecSink) LastUpdatedForVehicle(ctx context.Context, codigoAplicacao int) (*time.Time, error) {
+	return nil, nil
+}
+
+// Ensure sinks implement SpecSink
+var _ SpecSink = (*FileSpecWriter)(nil)
+var _ SpecSink = (*NDJSONSpecSink)(nil)
+var _ SpecSink = (*HTTPSpecSink)(nil)
@@ -0,0 +1,319 @@
+package scraper
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"wega-catalog-api/pkg/motulmatch"
+)
+
+// SnapshotVersion is the archive layout written by ExportSnapshot
+const SnapshotVersion = 1
+
+// Snapshot files restored next to the tables (catalog and embeddings caches)
+const (
+	SnapshotCatalog    = "catalog"
+	SnapshotEmbeddings = "embeddings"
+)
+
+// SnapshotStore copies the scraper-produced tables in and out of the database
+type SnapshotStore interface {
+	Export(ctx context.Context, open func(table string) (io.Writer, error)) (map[string]int64, error)
+	Restore(ctx context.Context, open func(table string) (io.Reader, error)) (map[string]int64, error)
+}
+
+// SnapshotManifest describes the contents of a snapshot archive
+type SnapshotManifest struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Tables    map[string]int64 `json:"tables"` // Rows per table
+	Files     []string         `json:"files"`  // SnapshotCatalog, SnapshotEmbeddings
+}
+
+// ExportSnapshot writes a tar.gz archive with a manifest, one CSV per
+// snapshot table (tables/<name>.csv) and the given cache files
+// (files/<name>), keyed by snapshot file name. Missing cache files are left
+// out. The archive is written to a temporary file and renamed into place,
+// so a failed export never leaves a truncated snapshot behind.
+func ExportSnapshot(ctx context.Context, store SnapshotStore, archive string, files map[string]string) (*SnapshotManifest, error) {
+	dir, err := os.MkdirTemp("", "wega-snapshot-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// Tables go to temporary files first: tar needs each entry's size upfront
+	var open []*os.File
+	defer func() {
+		for _, f := range open {
+			f.Close()
+		}
+	}()
+	tables, err := store.Export(ctx, func(table string) (io.Writer, error) {
+		f, err := os.Create(filepath.Join(dir, table+".csv"))
+		if err != nil {
+			return nil, err
+		}
+		open = append(open, f)
+		return f, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &SnapshotManifest{Version: SnapshotVersion, CreatedAt: time.Now(), Tables: tables}
+	entries := make(map[string]string) // Archive name -> local file
+	for table := range tables {
+		entries["tables/"+table+".csv"] = filepath.Join(dir, table+".csv")
+	}
+	for name, local := range files {
+		if local == "" {
+			continue
+		}
+		if _, err := os.Stat(local); errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		entries["files/"+name] = local
+		manifest.Files = append(manifest.Files, name)
+	}
+	sort.Strings(manifest.Files)
+
+	tmp := archive + ".tmp"
+	if err := writeSnapshotArchive(tmp, manifest, entries); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, archive); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// writeSnapshotArchive writes the manifest and then entries in name order
+func writeSnapshotArchive(file string, manifest *SnapshotManifest, entries map[string]string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name: "manifest.json", Mode: 0o644, Size: int64(len(data)), ModTime: manifest.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := addSnapshotFile(tw, name, entries[name]); err != nil {
+			return fmt.Errorf("failed to add %s to snapshot: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// addSnapshotFile copies a local file into the archive
+func addSnapshotFile(tw *tar.Writer, name, local string) error {
+	src, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, src)
+	return err
+}
+
+// RestoreSnapshot restores an archive written by ExportSnapshot: every table
+// in it replaces the current table contents in one transaction, then the
+// cache files are written to the paths in files, keyed by snapshot file
+// name. Files without a destination are skipped. The archive is unpacked and
+// the catalog checked before anything is changed.
+func RestoreSnapshot(ctx context.Context, store SnapshotStore, archive string, files map[string]string) (*SnapshotManifest, error) {
+	dir, err := os.MkdirTemp("", "wega-snapshot-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := unpackSnapshot(archive, dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than supported version %d", manifest.Version, SnapshotVersion)
+	}
+	for _, name := range manifest.Files {
+		if name == SnapshotCatalog {
+			if _, err := motulmatch.LoadCatalogFile(filepath.Join(dir, "files", name)); err != nil {
+				return nil, fmt.Errorf("snapshot catalog is invalid: %w", err)
+			}
+		}
+	}
+
+	var open []*os.File
+	defer func() {
+		for _, f := range open {
+			f.Close()
+		}
+	}()
+	tables, err := store.Restore(ctx, func(table string) (io.Reader, error) {
+		if _, ok := manifest.Tables[table]; !ok {
+			return nil, nil
+		}
+		f, err := os.Open(filepath.Join(dir, "tables", table+".csv"))
+		if err != nil {
+			return nil, fmt.Errorf("snapshot is missing table %s: %w", table, err)
+		}
+		open = append(open, f)
+		return f, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &SnapshotManifest{Version: manifest.Version, CreatedAt: manifest.CreatedAt, Tables: tables}
+	for _, name := range manifest.Files {
+		dst := files[name]
+		if dst == "" {
+			continue
+		}
+		if err := copySnapshotFile(filepath.Join(dir, "files", name), dst); err != nil {
+			return restored, fmt.Errorf("failed to restore %s file: %w", name, err)
+		}
+		restored.Files = append(restored.Files, name)
+	}
+	return restored, nil
+}
+
+// unpackSnapshot extracts the archive into dir and returns its manifest.
+// Only the entries ExportSnapshot writes are accepted.
+func unpackSnapshot(archive, dir string) (*SnapshotManifest, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *SnapshotManifest
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+
+		name := path.Clean(hdr.Name)
+		if name == "manifest.json" {
+			manifest = &SnapshotManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+			}
+			continue
+		}
+		entryDir, base := path.Split(name)
+		if (entryDir != "tables/" && entryDir != "files/") || base == "" || strings.HasPrefix(base, ".") {
+			return nil, fmt.Errorf("unexpected snapshot entry %q", hdr.Name)
+		}
+		if err := extractSnapshotEntry(tr, filepath.Join(dir, entryDir, base)); err != nil {
+			return nil, err
+		}
+	}
+
+	if manifest == nil {
+		return nil, errors.New("snapshot has no manifest.json")
+	}
+	return manifest, nil
+}
+
+// extractSnapshotEntry writes the current archive entry to dst
+func extractSnapshotEntry(r io.Reader, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to extract %s: %w", filepath.Base(dst), err)
+	}
+	return out.Close()
+}
+
+// copySnapshotFile replaces dst with src through a temporary file
+func copySnapshotFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
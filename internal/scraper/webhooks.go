@@ -0,0 +1,218 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Webhook event types
+const (
+	WebhookRunStarted       = "run_started"
+	WebhookRunFinished      = "run_finished"
+	WebhookFailureThreshold = "failure_threshold_exceeded"
+	WebhookKeysExhausted    = "llm_keys_exhausted"
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" with the shared secret, prefixed with "sha256="; the
+// timestamp (Unix seconds) lets receivers reject replays.
+const (
+	WebhookEventHeader     = "X-Wega-Event"
+	WebhookTimestampHeader = "X-Wega-Timestamp"
+	WebhookSignatureHeader = "X-Wega-Signature"
+)
+
+// webhookAttempts is how many times a delivery is tried before it is dropped
+const webhookAttempts = 3
+
+// WebhookEvent is the JSON body posted for every scraper event. Text is a
+// one-line summary, so Slack and Teams incoming webhooks can show it as is.
+type WebhookEvent struct {
+	Event     string    `json:"event"`
+	Text      string    `json:"text"`
+	RunID     string    `json:"run_id,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"`
+}
+
+// RunStartedData is the data of a run_started event
+type RunStartedData struct {
+	Total   int  `json:"total"`
+	DryRun  bool `json:"dry_run"`
+	Workers int  `json:"workers"`
+}
+
+// RunFinishedData is the data of a run_finished event
+type RunFinishedData struct {
+	Status           string         `json:"status"` // model.RunStatus*
+	DurationSeconds  float64        `json:"duration_seconds"`
+	Total            int            `json:"total"`
+	Processed        int            `json:"processed"`
+	Success          int            `json:"success"`
+	Failed           int            `json:"failed"`
+	Skipped          int            `json:"skipped"`
+	FailuresByReason map[string]int `json:"failures_by_reason,omitempty"`
+	Error            string         `json:"error,omitempty"`
+}
+
+// FailureThresholdData is the data of a failure_threshold_exceeded event
+type FailureThresholdData struct {
+	Failed    int `json:"failed"`
+	Threshold int `json:"threshold"`
+	Processed int `json:"processed"`
+}
+
+// KeysExhaustedData is the data of an llm_keys_exhausted event
+type KeysExhaustedData struct {
+	Service string    `json:"service"`
+	Until   time.Time `json:"until"` // When the daily limits reset
+}
+
+// WebhookNotifier posts scraper events to every configured URL. Deliveries
+// run in the background and are retried on errors and 5xx answers; Flush
+// waits for the ones in flight. A nil notifier ignores every event.
+type WebhookNotifier struct {
+	urls       []string
+	secret     []byte
+	httpClient *http.Client
+	logger     *slog.Logger
+	backoff    time.Duration
+
+	wg sync.WaitGroup
+}
+
+// NewWebhookNotifier creates a notifier; it returns nil without URLs. An
+// empty secret sends the events unsigned.
+func NewWebhookNotifier(urls []string, secret string, logger *slog.Logger) *WebhookNotifier {
+	if len(urls) == 0 {
+		return nil
+	}
+	return &WebhookNotifier{
+		urls:   urls,
+		secret: []byte(secret),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger:  logger,
+		backoff: time.Second,
+	}
+}
+
+// Notify sends the event to every URL in the background
+func (n *WebhookNotifier) Notify(event WebhookEvent) {
+	if n == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Warn("failed to marshal webhook event", "event", event.Event, "error", err)
+		return
+	}
+
+	for _, target := range n.urls {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			if err := n.deliver(target, event.Event, body); err != nil {
+				n.logger.Warn("failed to deliver webhook", "event", event.Event, "host", webhookHost(target), "error", err)
+			}
+		}()
+	}
+}
+
+// Flush waits until the deliveries in flight finish or timeout passes
+func (n *WebhookNotifier) Flush(timeout time.Duration) {
+	if n == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		n.logger.Warn("webhook deliveries still pending at shutdown")
+	}
+}
+
+// deliver posts body to target, retrying with a growing backoff
+func (n *WebhookNotifier) deliver(target, event string, body []byte) error {
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		var retry bool
+		if retry, err = n.post(target, event, body); err == nil || !retry {
+			return err
+		}
+		if attempt < webhookAttempts {
+			time.Sleep(n.backoff * time.Duration(attempt))
+		}
+	}
+	return err
+}
+
+// post makes one delivery attempt; retry reports whether it is worth another
+func (n *WebhookNotifier) post(target, event string, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", target, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	if len(n.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(n.secret, timestamp, body))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests,
+			fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>", the value
+// receivers compare with X-Wega-Signature (after the "sha256=" prefix)
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookHost returns the host of a webhook URL for logs; chat webhook URLs
+// carry their token in the path
+func webhookHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "invalid URL"
+}
@@ -0,0 +1,111 @@
+package scraper
+
+import (
+	"context"
+
+	"wega-catalog-api/internal/model"
+)
+
+// WorkQueue shares the vehicles of a run between scraper instances. Vehicles
+// are claimed in batches (SELECT ... FOR UPDATE SKIP LOCKED), so each one is
+// handed to a single instance; claims of a crashed instance expire and are
+// picked up again.
+type WorkQueue interface {
+	// Enqueue adds the vehicles to the run queue in order, ignoring ones already queued
+	Enqueue(ctx context.Context, runID string, ids []int) (int64, error)
+	// CountPending returns the vehicles of the run not yet completed
+	CountPending(ctx context.Context, runID string) (int, error)
+	// Claim reserves up to limit pending vehicles for worker
+	Claim(ctx context.Context, runID, worker string, limit int) ([]int, error)
+	// Complete marks a vehicle claimed by worker as processed; it fails when
+	// the claim expired and another worker took the vehicle
+	Complete(ctx context.Context, runID, worker string, id int) error
+	// Release returns the unfinished claims of worker to the queue
+	Release(ctx context.Context, runID, worker string) error
+}
+
+// SetWorkQueue makes Run claim vehicles from queue instead of walking the full
+// list, so several instances can share a run. The checkpoint is not used in this mode.
+func (s *ScraperService) SetWorkQueue(queue WorkQueue, runID, worker string) {
+	s.queue = queue
+	s.queueRunID = runID
+	s.queueWorker = worker
+}
+
+// enqueueVehicles adds every vehicle to the run queue and returns how many are pending
+func (s *ScraperService) enqueueVehicles(ctx context.Context, vehicles []model.Aplicacao) (int, error) {
+	ids := make([]int, len(vehicles))
+	for i, v := range vehicles {
+		ids[i] = v.CodigoAplicacao
+	}
+
+	added, err := s.queue.Enqueue(ctx, s.queueRunID, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := s.queue.CountPending(ctx, s.queueRunID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("work queue ready",
+		"run_id", s.queueRunID,
+		"worker", s.queueWorker,
+		"added", added,
+		"pending", pending,
+	)
+	return pending, nil
+}
+
+// feedFromQueue claims batches of vehicles and sends them to the workers
+// until the run queue is empty
+func (s *ScraperService) feedFromQueue(ctx context.Context, vehicles []model.Aplicacao, workQueue chan<- model.Aplicacao) error {
+	byID := make(map[int]model.Aplicacao, len(vehicles))
+	for _, v := range vehicles {
+		byID[v.CodigoAplicacao] = v
+	}
+
+	batchSize := s.config.Workers * 2
+	for {
+		ids, err := s.queue.Claim(ctx, s.queueRunID, s.queueWorker, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		for _, id := range ids {
+			vehicle, ok := byID[id]
+			if !ok {
+				// Queued by an instance with a different vehicle list; nothing to do here
+				s.completeClaim(ctx, id)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case workQueue <- vehicle:
+			}
+		}
+	}
+}
+
+// completeClaim marks a vehicle as done in the run queue (no-op without a queue)
+func (s *ScraperService) completeClaim(ctx context.Context, id int) {
+	if s.queue == nil {
+		return
+	}
+	if err := s.queue.Complete(ctx, s.queueRunID, s.queueWorker, id); err != nil {
+		s.logger.Warn("failed to complete queued vehicle", "id", id, "error", err)
+	}
+}
+
+// releaseClaims returns this instance's unfinished claims to the queue
+func (s *ScraperService) releaseClaims(ctx context.Context) {
+	if err := s.queue.Release(ctx, s.queueRunID, s.queueWorker); err != nil {
+		s.logger.Warn("failed to release queued vehicles", "error", err)
+	}
+}
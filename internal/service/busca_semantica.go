@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/model"
+)
+
+const (
+	// loteEmbedding e quantas aplicacoes sao embutidas por chamada ao provedor
+	loteEmbedding = 64
+	// limiteSemelhantes e o maximo de aplicacoes da busca, o mesmo do ILIKE
+	limiteSemelhantes = 50
+	// timeoutEmbeddingBusca limita o embedding do termo buscado; passando dele
+	// a busca segue pelo ILIKE em vez de segurar a requisicao
+	timeoutEmbeddingBusca = 2 * time.Second
+)
+
+// VetorStore guarda os embeddings das aplicacoes e busca as mais parecidas.
+// repository.AplicacaoEmbeddingRepo a implementa.
+type VetorStore interface {
+	Disponivel(ctx context.Context) (bool, error)
+	Pendentes(ctx context.Context, modelo string, limit int) ([]model.AplicacaoVetor, error)
+	Salvar(ctx context.Context, modelo string, vetores []model.AplicacaoVetor) error
+	BuscarSemelhantes(ctx context.Context, vetor []float32, modelo string, filtro model.FiltroSemelhantes) ([]model.Aplicacao, error)
+}
+
+// NewEmbedder cria o cliente de embeddings configurado; nil quando a busca
+// semantica esta desativada
+func NewEmbedder(cfg config.BuscaSemanticaConfig) client.Embedder {
+	switch cfg.Provider {
+	case "ollama":
+		return client.NewOllamaEmbedder(cfg.OllamaURL, cfg.Modelo)
+	case "openai":
+		return client.NewOpenAIEmbedder(cfg.OpenAIAPIKey, cfg.Modelo)
+	}
+	return nil
+}
+
+// BuscaSemantica mantem os embeddings das descricoes das aplicacoes
+// atualizados e busca veiculos por similaridade. Sem a tabela
+// APLICACAO_EMBEDDING (pgvector ausente no banco) fica indisponivel e as
+// buscas seguem pelo ILIKE.
+type BuscaSemantica struct {
+	embedder   client.Embedder
+	store      VetorStore
+	cfg        config.BuscaSemanticaConfig
+	disponivel atomic.Bool
+	verificada atomic.Bool
+	// indexada fica verdadeira depois da primeira indexacao completa: antes
+	// dela a busca acharia so as aplicacoes ja embutidas
+	indexada atomic.Bool
+	// Embeddings dos termos buscados: os mesmos veiculos sao pesquisados o dia todo
+	consultas *lruCache[string, []float32]
+}
+
+func NewBuscaSemantica(embedder client.Embedder, store VetorStore, cfg config.BuscaSemanticaConfig) *BuscaSemantica {
+	return &BuscaSemantica{
+		embedder:  embedder,
+		store:     store,
+		cfg:       cfg,
+		consultas: newLRUCache[string, []float32](24*time.Hour, 5000),
+	}
+}
+
+// Disponivel indica se a ultima verificacao encontrou a tabela de embeddings
+// e se as aplicacoes ja foram todas indexadas
+func (b *BuscaSemantica) Disponivel() bool {
+	return b.disponivel.Load() && b.indexada.Load()
+}
+
+// Verificar confere se a tabela de embeddings existe, registrando as mudancas
+func (b *BuscaSemantica) Verificar(ctx context.Context) error {
+	disponivel, err := b.store.Disponivel(ctx)
+	if err != nil {
+		return err
+	}
+	antes := b.disponivel.Swap(disponivel)
+	if !b.verificada.Swap(true) || antes != disponivel {
+		if disponivel {
+			slog.Info("busca semantica disponivel", "modelo", b.embedder.Model())
+		} else {
+			slog.Warn("busca semantica indisponivel: tabela APLICACAO_EMBEDDING ausente (pgvector), usando ILIKE")
+		}
+	}
+	return nil
+}
+
+// Indexar calcula os embeddings das aplicacoes novas, alteradas ou de outro
+// modelo, em lotes, ate nao sobrar pendente. Feito para rodar no JobRunner.
+func (b *BuscaSemantica) Indexar(ctx context.Context) error {
+	if err := b.Verificar(ctx); err != nil {
+		return err
+	}
+	if !b.disponivel.Load() {
+		return nil
+	}
+
+	modelo := b.embedder.Model()
+	total := 0
+	for ctx.Err() == nil {
+		pendentes, err := b.store.Pendentes(ctx, modelo, loteEmbedding)
+		if err != nil {
+			return err
+		}
+		if len(pendentes) == 0 {
+			b.indexada.Store(true)
+			break
+		}
+
+		textos := make([]string, len(pendentes))
+		for i, p := range pendentes {
+			textos[i] = p.Texto
+		}
+		vetores, err := b.embedder.Embed(ctx, textos)
+		if err != nil {
+			return fmt.Errorf("falha ao calcular embeddings: %w", err)
+		}
+		for i := range pendentes {
+			pendentes[i].Embedding = vetores[i]
+		}
+		if err := b.store.Salvar(ctx, modelo, pendentes); err != nil {
+			return err
+		}
+		total += len(pendentes)
+	}
+
+	if total > 0 {
+		slog.Info("embeddings das aplicacoes atualizados", "aplicacoes", total, "modelo", modelo)
+	}
+	return ctx.Err()
+}
+
+// Semelhantes busca as aplicacoes mais parecidas com marca, modelo e motor,
+// filtrando marca e ano como o ILIKE
+func (b *BuscaSemantica) Semelhantes(ctx context.Context, marca, modelo, ano, motor string) ([]model.Aplicacao, error) {
+	termo := strings.Join(strings.Fields(strings.ToUpper(marca+" "+modelo+" "+motor)), " ")
+
+	vetor, ok := b.consultas.Get(termo)
+	if !ok {
+		embedCtx, cancel := context.WithTimeout(ctx, timeoutEmbeddingBusca)
+		vetores, err := b.embedder.Embed(embedCtx, []string{termo})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("falha ao calcular embedding da busca: %w", err)
+		}
+		vetor = vetores[0]
+		b.consultas.Set(termo, vetor)
+	}
+
+	return b.store.BuscarSemelhantes(ctx, vetor, b.embedder.Model(), model.FiltroSemelhantes{
+		Marca:           marca,
+		Ano:             ano,
+		MinSimilaridade: b.cfg.MinSimilaridade,
+		Margem:          b.cfg.Margem,
+		Limite:          limiteSemelhantes,
+	})
+}
+
+// AplicacaoSemantica busca os veiculos por similaridade de embeddings, o que
+// acha o modelo mesmo com erro de digitacao, abreviacao ou outra ordem de
+// palavras ("golf" x "gol", "s10 2.8"). Sem busca semantica disponivel, com
+// falha no provedor ou sem nenhum resultado acima da similaridade minima, a
+// busca segue pelo ILIKE do AplicacaoReader embutido; as demais consultas
+// passam direto para ele.
+type AplicacaoSemantica struct {
+	AplicacaoReader
+	busca *BuscaSemantica
+}
+
+func NewAplicacaoSemantica(leitor AplicacaoReader, busca *BuscaSemantica) *AplicacaoSemantica {
+	return &AplicacaoSemantica{AplicacaoReader: leitor, busca: busca}
+}
+
+func (a *AplicacaoSemantica) BuscarPorVeiculo(ctx context.Context, marca, modelo, ano, motor string) ([]model.Aplicacao, error) {
+	if modelo == "" || !a.busca.Disponivel() {
+		return a.AplicacaoReader.BuscarPorVeiculo(ctx, marca, modelo, ano, motor)
+	}
+
+	aplicacoes, err := a.busca.Semelhantes(ctx, marca, modelo, ano, motor)
+	if err != nil {
+		slog.Warn("falha na busca semantica, usando ILIKE", "error", err)
+	}
+	if err != nil || len(aplicacoes) == 0 {
+		return a.AplicacaoReader.BuscarPorVeiculo(ctx, marca, modelo, ano, motor)
+	}
+	return aplicacoes, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"sort"
+
+	"wega-catalog-api/internal/matching"
+	"wega-catalog-api/internal/model"
+)
+
+// caracteristicasPedidas monta as caracteristicas de motor informadas na busca
+func caracteristicasPedidas(req model.BuscaFiltrosRequest) matching.VehicleFeatures {
+	return matching.VehicleFeatures{
+		Cilindrada: req.Cilindrada,
+		Valvulas:   req.Valvulas,
+		Potencia:   req.Potencia,
+	}
+}
+
+// caracteristicasAplicacao extrai cilindrada, valvulas e potencia da descricao
+// e do motor (ComplementoAplicacao3) da aplicacao
+func caracteristicasAplicacao(a model.Aplicacao) matching.VehicleFeatures {
+	return matching.ExtractFeatures(a.DescricaoAplicacao+" "+a.Motor, 0)
+}
+
+// combustivelAplicacao detecta o combustivel na descricao e no motor da aplicacao ("" se nao diz)
+func combustivelAplicacao(a model.Aplicacao) string {
+	return matching.DetectFuel(a.DescricaoAplicacao + " " + a.Motor)
+}
+
+// separarCombustivel normaliza o combustivel pedido ("Total Flex", "alcool")
+// e, quando ele nao veio, procura no motor e no modelo ("1.6 diesel"),
+// tirando a palavra do texto que sera buscado literalmente na descricao
+func separarCombustivel(req model.BuscaFiltrosRequest) model.BuscaFiltrosRequest {
+	if req.Combustivel != "" {
+		req.Combustivel = matching.DetectFuel(req.Combustivel)
+		return req
+	}
+	if combustivel, resto := matching.ExtractFuel(req.Motor); combustivel != "" {
+		req.Combustivel, req.Motor = combustivel, resto
+		return req
+	}
+	if combustivel, resto := matching.ExtractFuel(req.Modelo); combustivel != "" && resto != "" {
+		req.Combustivel, req.Modelo = combustivel, resto
+	}
+	return req
+}
+
+// filtrarCompativeis descarta as aplicacoes cuja descricao contradiz as
+// caracteristicas de motor ou o combustivel pedidos e coloca primeiro as que
+// confirmam mais deles. Aplicacoes que nao trazem a caracteristica (ou o
+// combustivel) na descricao continuam, depois das que a confirmam; entre as
+// de mesmo numero de confirmacoes a ordem nao muda.
+func filtrarCompativeis(aplicacoes []model.Aplicacao, req model.BuscaFiltrosRequest) []model.Aplicacao {
+	if !req.TemCaracteristicasMotor() && req.Combustivel == "" {
+		return aplicacoes
+	}
+
+	pedidas := caracteristicasPedidas(req)
+	confirmadas := make(map[int]int, len(aplicacoes))
+	compativeis := aplicacoes[:0:0]
+	for _, a := range aplicacoes {
+		matches, conflitos := matching.CompareFeatures(pedidas, caracteristicasAplicacao(a))
+		if req.Combustivel != "" {
+			if combustivel := combustivelAplicacao(a); combustivel != "" {
+				if matching.FuelCompatible(req.Combustivel, combustivel) {
+					matches++
+				} else {
+					conflitos++
+				}
+			}
+		}
+		if conflitos > 0 {
+			continue
+		}
+		confirmadas[a.CodigoAplicacao] = matches
+		compativeis = append(compativeis, a)
+	}
+
+	sort.SliceStable(compativeis, func(i, j int) bool {
+		return confirmadas[compativeis[i].CodigoAplicacao] > confirmadas[compativeis[j].CodigoAplicacao]
+	})
+	return compativeis
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Invalidavel e um cache que o endpoint admin pode esvaziar apos mudancas no
+// catalogo; Invalidar retorna quantas entradas foram descartadas
+type Invalidavel interface {
+	Invalidar() int
+}
+
+// FabricanteCache guarda em memoria as listas de fabricantes, consultadas a
+// cada carregamento do frontend e alteradas so nas cargas do catalogo
+type FabricanteCache struct {
+	repo  FabricanteReader
+	cache *lruCache[string, []model.Fabricante]
+}
+
+func NewFabricanteCache(repo FabricanteReader, ttl time.Duration) *FabricanteCache {
+	return &FabricanteCache{repo: repo, cache: newLRUCache[string, []model.Fabricante](ttl, 2)}
+}
+
+func (c *FabricanteCache) ListarVeiculos(ctx context.Context) ([]model.Fabricante, error) {
+	return cached(ctx, c.cache, "veiculos", c.repo.ListarVeiculos)
+}
+
+func (c *FabricanteCache) ListarConcorrentes(ctx context.Context) ([]model.Fabricante, error) {
+	return cached(ctx, c.cache, "concorrentes", c.repo.ListarConcorrentes)
+}
+
+func (c *FabricanteCache) Invalidar() int {
+	return c.cache.Clear()
+}
+
+// TipoFiltroCache guarda em memoria os tipos de filtro; as buscas de produtos
+// por aplicacao passam direto para o repositorio
+type TipoFiltroCache struct {
+	ProdutoReader
+	cache *lruCache[string, []model.TipoFiltro]
+}
+
+func NewTipoFiltroCache(repo ProdutoReader, ttl time.Duration) *TipoFiltroCache {
+	return &TipoFiltroCache{ProdutoReader: repo, cache: newLRUCache[string, []model.TipoFiltro](ttl, 1)}
+}
+
+func (c *TipoFiltroCache) ListarTiposFiltro(ctx context.Context) ([]model.TipoFiltro, error) {
+	return cached(ctx, c.cache, "tipos", c.ProdutoReader.ListarTiposFiltro)
+}
+
+func (c *TipoFiltroCache) Invalidar() int {
+	return c.cache.Clear()
+}
+
+// cached retorna o valor em cache de key ou o busca com load e guarda;
+// erros nao sao guardados
+func cached[V any](ctx context.Context, cache *lruCache[string, V], key string, load func(context.Context) (V, error)) (V, error) {
+	if value, ok := cache.Get(key); ok {
+		return value, nil
+	}
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	cache.Set(key, value)
+	return value, nil
+}
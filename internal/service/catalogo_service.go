@@ -2,29 +2,33 @@ package service
 
 import (
 	"context"
+	"log/slog"
+	"sort"
 
 	"wega-catalog-api/internal/model"
-	"wega-catalog-api/internal/repository"
 )
 
 type CatalogoService struct {
-	fabricanteRepo *repository.FabricanteRepo
-	aplicacaoRepo  *repository.AplicacaoRepo
-	produtoRepo    *repository.ProdutoRepo
-	referenciaRepo *repository.ReferenciaRepo
+	fabricanteRepo FabricanteReader
+	aplicacaoRepo  AplicacaoReader
+	produtoRepo    ProdutoReader
+	referenciaRepo ReferenciaReader
+	popularidade   PopularidadeStore
 }
 
 func NewCatalogoService(
-	fr *repository.FabricanteRepo,
-	ar *repository.AplicacaoRepo,
-	pr *repository.ProdutoRepo,
-	rr *repository.ReferenciaRepo,
+	fr FabricanteReader,
+	ar AplicacaoReader,
+	pr ProdutoReader,
+	rr ReferenciaReader,
+	pop PopularidadeStore,
 ) *CatalogoService {
 	return &CatalogoService{
 		fabricanteRepo: fr,
 		aplicacaoRepo:  ar,
 		produtoRepo:    pr,
 		referenciaRepo: rr,
+		popularidade:   pop,
 	}
 }
 
@@ -39,11 +43,15 @@ func (s *CatalogoService) BuscarFiltros(ctx context.Context, req model.BuscaFilt
 		}, nil
 	}
 
+	// Combustivel no motor ou no modelo ("1.6 diesel") vira filtro, nao texto
+	req = separarCombustivel(req)
+
 	// Buscar aplicacoes que combinam
 	aplicacoes, err := s.aplicacaoRepo.BuscarPorVeiculo(ctx, req.Marca, req.Modelo, req.Ano, req.Motor)
 	if err != nil {
 		return nil, err
 	}
+	s.ordenarPorPopularidade(ctx, aplicacoes)
 
 	// Nenhum resultado
 	if len(aplicacoes) == 0 {
@@ -53,14 +61,24 @@ func (s *CatalogoService) BuscarFiltros(ctx context.Context, req model.BuscaFilt
 		}, nil
 	}
 
+	// Cilindrada, valvulas, potencia e combustivel informados descartam as versoes que os contradizem
+	aplicacoes = filtrarCompativeis(aplicacoes, req)
+	if len(aplicacoes) == 0 {
+		return &model.BuscaFiltrosResponse{
+			Status:   "nao_encontrado",
+			Mensagem: "Encontrei o veiculo, mas nenhuma versao com a motorizacao ou o combustivel informados.",
+		}, nil
+	}
+	semMotor := req.Motor == "" && !req.TemCaracteristicasMotor()
+
 	// Verifica se precisa de mais info (muitas opcoes diferentes)
-	if len(aplicacoes) > 10 && (req.Ano == "" || req.Motor == "") {
+	if len(aplicacoes) > 10 && (req.Ano == "" || semMotor) {
 		opcoes, _ := s.aplicacaoRepo.ListarOpcoes(ctx, req.Marca, req.Modelo)
 		faltantes := []string{}
 		if req.Ano == "" {
 			faltantes = append(faltantes, "ano")
 		}
-		if req.Motor == "" {
+		if semMotor {
 			faltantes = append(faltantes, "motor")
 		}
 		return &model.BuscaFiltrosResponse{
@@ -73,17 +91,12 @@ func (s *CatalogoService) BuscarFiltros(ctx context.Context, req model.BuscaFilt
 
 	// Se ainda temos multiplas opcoes distintas, perguntar
 	if len(aplicacoes) > 1 && s.saoOpcoesDistintas(aplicacoes) {
-		opcoes := make([]model.OpcaoVeiculo, 0, len(aplicacoes))
-		for _, a := range aplicacoes {
-			opcoes = append(opcoes, model.OpcaoVeiculo{
-				ID:        a.CodigoAplicacao,
-				Descricao: a.DescricaoAplicacao,
-			})
-		}
+		opcoes, palpite := ranquearOpcoes(aplicacoes, req)
 		return &model.BuscaFiltrosResponse{
-			Status:   "multiplos",
-			Mensagem: "Encontrei mais de uma opcao. Qual delas?",
-			Opcoes:   opcoes,
+			Status:    "multiplos",
+			Mensagem:  "Encontrei mais de uma opcao. Qual delas?",
+			Opcoes:    opcoes,
+			BestGuess: palpite,
 		}, nil
 	}
 
@@ -98,14 +111,18 @@ func (s *CatalogoService) BuscarFiltros(ctx context.Context, req model.BuscaFilt
 		return nil, err
 	}
 
+	s.registrarConsultas(ctx, codigosAplicacao...)
+
 	if len(filtros) == 0 {
 		return &model.BuscaFiltrosResponse{
 			Status:   "nao_encontrado",
 			Mensagem: "Encontrei o veiculo, mas nao ha filtros cadastrados para ele.",
 			Veiculo: &model.VeiculoInfo{
+				ID:                aplicacaoUnica(aplicacoes),
 				Marca:             aplicacoes[0].Marca,
 				Modelo:            req.Modelo,
 				DescricaoCompleta: aplicacoes[0].DescricaoAplicacao,
+				AnoDesconhecido:   aplicacoes[0].AnoDesconhecido,
 			},
 		}, nil
 	}
@@ -114,11 +131,13 @@ func (s *CatalogoService) BuscarFiltros(ctx context.Context, req model.BuscaFilt
 	return &model.BuscaFiltrosResponse{
 		Status: "completo",
 		Veiculo: &model.VeiculoInfo{
+			ID:                aplicacaoUnica(aplicacoes),
 			Marca:             aplicacoes[0].Marca,
 			Modelo:            req.Modelo,
 			Ano:               req.Ano,
 			Motor:             aplicacoes[0].Motor,
 			DescricaoCompleta: aplicacoes[0].DescricaoAplicacao,
+			AnoDesconhecido:   aplicacoes[0].AnoDesconhecido,
 		},
 		Filtros:      filtros,
 		TotalFiltros: len(filtros),
@@ -137,12 +156,63 @@ func (s *CatalogoService) BuscarPorAplicacao(ctx context.Context, aplicacaoID in
 		return nil, err
 	}
 
+	s.registrarConsultas(ctx, aplicacaoID)
+
 	return &model.FiltrosAplicacaoResponse{
 		Aplicacao: aplicacao,
 		Filtros:   filtros,
 	}, nil
 }
 
+// ordenarPorPopularidade coloca as aplicacoes mais consultadas primeiro, mantendo
+// a ordem alfabetica entre as de mesmo score. Sem scores, a ordem nao muda.
+func (s *CatalogoService) ordenarPorPopularidade(ctx context.Context, aplicacoes []model.Aplicacao) {
+	if s.popularidade == nil || len(aplicacoes) < 2 {
+		return
+	}
+
+	codigos := make([]int, len(aplicacoes))
+	for i, a := range aplicacoes {
+		codigos[i] = a.CodigoAplicacao
+	}
+
+	scores, err := s.popularidade.Scores(ctx, codigos)
+	if err != nil {
+		slog.Warn("falha ao buscar popularidade", "error", err)
+		return
+	}
+
+	// Aplicacoes sem ano conhecido continuam depois das que confirmam o ano
+	sort.SliceStable(aplicacoes, func(i, j int) bool {
+		if aplicacoes[i].AnoDesconhecido != aplicacoes[j].AnoDesconhecido {
+			return !aplicacoes[i].AnoDesconhecido
+		}
+		return scores[aplicacoes[i].CodigoAplicacao] > scores[aplicacoes[j].CodigoAplicacao]
+	})
+}
+
+// registrarConsultas soma uma consulta a popularidade de cada aplicacao.
+// Falhas sao apenas logadas para nao afetar a resposta.
+func (s *CatalogoService) registrarConsultas(ctx context.Context, codigosAplicacao ...int) {
+	if s.popularidade == nil {
+		return
+	}
+	for _, codigo := range codigosAplicacao {
+		if err := s.popularidade.Record(ctx, codigo, false); err != nil {
+			slog.Warn("falha ao registrar popularidade", "codigo_aplicacao", codigo, "error", err)
+			return
+		}
+	}
+}
+
+// aplicacaoUnica retorna o codigo da aplicacao quando a busca chegou a uma so (0 caso contrario)
+func aplicacaoUnica(aplicacoes []model.Aplicacao) int {
+	if len(aplicacoes) != 1 {
+		return 0
+	}
+	return aplicacoes[0].CodigoAplicacao
+}
+
 // saoOpcoesDistintas verifica se as aplicacoes sao veiculos realmente diferentes
 func (s *CatalogoService) saoOpcoesDistintas(apps []model.Aplicacao) bool {
 	if len(apps) <= 1 {
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+// EventoCompletude identifica o relatorio de completude no webhook
+const EventoCompletude = "completude_report"
+
+// CompletudeWebhookPayload e o corpo enviado ao webhook a cada relatorio gerado
+type CompletudeWebhookPayload struct {
+	Event       string                       `json:"event"`
+	GeradoEm    time.Time                    `json:"gerado_em"`
+	SLA         model.CompletudeSLA          `json:"sla"`
+	Geral       model.CompletudeFabricante   `json:"geral"`
+	ForaSLA     []model.CompletudeFabricante `json:"fora_sla"` // Somente fabricantes abaixo do SLA
+	Fabricantes int                          `json:"fabricantes"`
+}
+
+// CompletudeService gera, guarda e publica os relatorios de completude do catalogo
+type CompletudeService struct {
+	repo       *repository.CompletudeRepo
+	cfg        config.CompletudeConfig
+	httpClient *http.Client
+}
+
+func NewCompletudeService(repo *repository.CompletudeRepo, cfg config.CompletudeConfig) *CompletudeService {
+	return &CompletudeService{
+		repo:       repo,
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GerarSeVencido gera um relatorio quando o ultimo gravado tem mais de Intervalo,
+// para que reinicios da API nao produzam relatorios extras
+func (s *CompletudeService) GerarSeVencido(ctx context.Context) error {
+	datas, err := s.repo.Datas(ctx, 1)
+	if err != nil {
+		return err
+	}
+	if len(datas) > 0 && time.Since(datas[0]) < s.cfg.Intervalo {
+		return nil
+	}
+
+	relatorio, err := s.Gerar(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("relatorio de completude gerado",
+		"fabricantes", len(relatorio.Fabricantes),
+		"fora_sla", relatorio.TotalForaSLA,
+	)
+	return nil
+}
+
+// Gerar calcula a completude atual, grava o relatorio e o envia ao webhook
+func (s *CompletudeService) Gerar(ctx context.Context) (*model.CompletudeRelatorio, error) {
+	var anteriores []model.CompletudeFabricante
+	var anterior *time.Time
+	datas, err := s.repo.Datas(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(datas) > 0 {
+		anterior = &datas[0]
+		if anteriores, err = s.repo.Buscar(ctx, datas[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	atuais, err := s.repo.Calcular(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Truncado para casar com a precisao de TIMESTAMP nas buscas por data
+	geradoEm := time.Now().UTC().Truncate(time.Microsecond)
+	if err := s.repo.Salvar(ctx, geradoEm, atuais); err != nil {
+		return nil, err
+	}
+
+	relatorio := s.montar(geradoEm, atuais, anterior, anteriores)
+
+	if s.cfg.WebhookURL != "" {
+		if err := s.notificar(ctx, relatorio); err != nil {
+			slog.Warn("falha ao enviar relatorio de completude", "error", err)
+		}
+	}
+
+	return relatorio, nil
+}
+
+// Ultimo retorna o relatorio gravado mais recente, com tendencia em relacao ao
+// anterior, ou nil se nenhum foi gerado
+func (s *CompletudeService) Ultimo(ctx context.Context) (*model.CompletudeRelatorio, error) {
+	datas, err := s.repo.Datas(ctx, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(datas) == 0 {
+		return nil, nil
+	}
+
+	atuais, err := s.repo.Buscar(ctx, datas[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var anteriores []model.CompletudeFabricante
+	var anterior *time.Time
+	if len(datas) > 1 {
+		anterior = &datas[1]
+		if anteriores, err = s.repo.Buscar(ctx, datas[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.montar(datas[0], atuais, anterior, anteriores), nil
+}
+
+// montar calcula percentuais, tendencia e SLA de cada fabricante e do total
+func (s *CompletudeService) montar(
+	geradoEm time.Time,
+	atuais []model.CompletudeFabricante,
+	anterior *time.Time,
+	anteriores []model.CompletudeFabricante,
+) *model.CompletudeRelatorio {
+	porCodigo := make(map[int]model.CompletudeFabricante, len(anteriores))
+	geralAnterior := model.CompletudeFabricante{Fabricante: "Geral"}
+	for _, c := range anteriores {
+		c.CalcularPercentuais()
+		porCodigo[c.CodigoFabricante] = c
+		somarCompletude(&geralAnterior, c)
+	}
+	geralAnterior.CalcularPercentuais()
+
+	relatorio := &model.CompletudeRelatorio{
+		GeradoEm:    geradoEm,
+		Anterior:    anterior,
+		SLA:         s.cfg.SLA,
+		Geral:       model.CompletudeFabricante{Fabricante: "Geral"},
+		Fabricantes: atuais,
+	}
+
+	for i := range relatorio.Fabricantes {
+		c := &relatorio.Fabricantes[i]
+		c.CalcularPercentuais()
+		if prev, ok := porCodigo[c.CodigoFabricante]; ok {
+			c.CompararCom(prev)
+		}
+		c.AvaliarSLA(s.cfg.SLA)
+		if len(c.ForaSLA) > 0 {
+			relatorio.TotalForaSLA++
+		}
+		somarCompletude(&relatorio.Geral, *c)
+	}
+
+	relatorio.Geral.CalcularPercentuais()
+	if anterior != nil {
+		relatorio.Geral.CompararCom(geralAnterior)
+	}
+	relatorio.Geral.AvaliarSLA(s.cfg.SLA)
+
+	return relatorio
+}
+
+func somarCompletude(total *model.CompletudeFabricante, c model.CompletudeFabricante) {
+	total.Aplicacoes += c.Aplicacoes
+	total.ComFiltro += c.ComFiltro
+	total.ComEspecificacao += c.ComEspecificacao
+	total.ComReferencia += c.ComReferencia
+}
+
+// notificar envia o resumo do relatorio ao webhook configurado
+func (s *CompletudeService) notificar(ctx context.Context, relatorio *model.CompletudeRelatorio) error {
+	payload := CompletudeWebhookPayload{
+		Event:       EventoCompletude,
+		GeradoEm:    relatorio.GeradoEm,
+		SLA:         relatorio.SLA,
+		Geral:       relatorio.Geral,
+		ForaSLA:     []model.CompletudeFabricante{},
+		Fabricantes: len(relatorio.Fabricantes),
+	}
+	for _, c := range relatorio.Fabricantes {
+		if len(c.ForaSLA) > 0 {
+			payload.ForaSLA = append(payload.ForaSLA, c)
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal completeness report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/sync/singleflight"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/telemetry"
+)
+
+// EspecificacoesResultado representa as especificacoes de uma aplicacao e de onde vieram
+type EspecificacoesResultado struct {
+	Especificacoes []model.EspecificacaoTecnica
+	AoVivo         bool // Consultadas no provedor agora (ou em cache), nao gravadas pelo scraper
+	Armazenadas    int  // Quantas vieram do banco
+}
+
+// maxConsultasAoVivo limita as respostas do match server guardadas em cache
+const maxConsultasAoVivo = 10000
+
+// lookupSpec e lookupResponse espelham a resposta de POST /lookup do match server
+type lookupSpec struct {
+	TipoFluido   string `json:"tipo_fluido"`
+	Condicao     string `json:"condicao"`
+	Viscosidade  string `json:"viscosidade"`
+	Capacidade   string `json:"capacidade"`
+	Norma        string `json:"norma"`
+	Recomendacao string `json:"recomendacao"`
+	Observacao   string `json:"observacao"`
+	// Intervalo de troca, 0 = desconhecido
+	IntervaloTrocaKm    int `json:"intervalo_troca_km"`
+	IntervaloTrocaMeses int `json:"intervalo_troca_meses"`
+}
+
+type lookupResponse struct {
+	Provider string `json:"provider"`
+	Vehicle  struct {
+		ID string `json:"id"`
+	} `json:"vehicle"`
+	Specs []lookupSpec `json:"specs"`
+}
+
+// EspecificacaoService busca especificacoes gravadas e, opcionalmente, consulta
+// ao vivo o provedor para aplicacoes que o scraper ainda nao cobriu
+type EspecificacaoService struct {
+	repo          EspecificacaoReader
+	aplicacaoRepo AplicacaoReader
+	cfg           config.AoVivoConfig
+	httpClient    *http.Client
+
+	// Respostas por aplicacao, vazias quando o provedor nao encontrou o
+	// veiculo; consultas simultaneas da mesma aplicacao viram uma so
+	cache    *lruCache[int, []model.EspecificacaoTecnica]
+	consulta singleflight.Group
+}
+
+func NewEspecificacaoService(
+	repo EspecificacaoReader,
+	aplicacaoRepo AplicacaoReader,
+	cfg config.AoVivoConfig,
+) *EspecificacaoService {
+	return &EspecificacaoService{
+		repo:          repo,
+		aplicacaoRepo: aplicacaoRepo,
+		cfg:           cfg,
+		httpClient:    telemetry.NewHTTPClient(cfg.Timeout), // Continua o trace no match server
+		cache:         newLRUCache[int, []model.EspecificacaoTecnica](cfg.CacheTTL, maxConsultasAoVivo),
+	}
+}
+
+// AoVivoDisponivel indica se a consulta ao vivo esta configurada
+func (s *EspecificacaoService) AoVivoDisponivel() bool {
+	return s.cfg.URL != ""
+}
+
+// BuscarEspecificacoes retorna as especificacoes gravadas da aplicacao (ou como
+// estavam em asOf). Sem especificacoes gravadas e com aoVivo, consulta o
+// provedor pelo match server; falhas da consulta ao vivo sao registradas e
+// retornam a lista vazia, nunca um erro.
+func (s *EspecificacaoService) BuscarEspecificacoes(ctx context.Context, id int, asOf *time.Time, aoVivo bool) (*EspecificacoesResultado, error) {
+	var specs []model.EspecificacaoTecnica
+	var err error
+	if asOf != nil {
+		specs, err = s.repo.ListByAplicacaoAsOf(ctx, id, *asOf)
+	} else {
+		specs, err = s.repo.ListByAplicacao(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resultado := &EspecificacoesResultado{Especificacoes: specs, Armazenadas: len(specs)}
+	// Consultas historicas nunca vao ao provedor
+	if len(specs) > 0 || !aoVivo || asOf != nil || !s.AoVivoDisponivel() {
+		return resultado, nil
+	}
+
+	live, err := s.consultarAoVivo(ctx, id)
+	if err != nil {
+		slog.Warn("falha na consulta ao vivo de especificacoes", "codigo_aplicacao", id, "error", err)
+		return resultado, nil
+	}
+	if len(live) > 0 {
+		resultado.Especificacoes = live
+		resultado.AoVivo = true
+	}
+	return resultado, nil
+}
+
+// consultarAoVivo consulta o match server, usando o cache quando possivel
+func (s *EspecificacaoService) consultarAoVivo(ctx context.Context, id int) ([]model.EspecificacaoTecnica, error) {
+	if specs, ok := s.cache.Get(id); ok {
+		return specs, nil
+	}
+
+	// A consulta compartilhada nao cai se o primeiro cliente desistir
+	ctx = context.WithoutCancel(ctx)
+	v, err, _ := s.consulta.Do(strconv.Itoa(id), func() (any, error) {
+		specs, err := s.buscarAoVivo(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Set(id, specs)
+		return specs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]model.EspecificacaoTecnica), nil
+}
+
+// buscarAoVivo faz a consulta ao match server, sem cache
+func (s *EspecificacaoService) buscarAoVivo(ctx context.Context, id int) ([]model.EspecificacaoTecnica, error) {
+	aplicacao, err := s.aplicacaoRepo.BuscarPorID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]string{
+		"fabricante": aplicacao.Marca,
+		"descricao":  aplicacao.DescricaoAplicacao,
+		"periodo":    aplicacao.Periodo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(s.cfg.URL, "/")+"/lookup", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var specs []model.EspecificacaoTecnica
+	switch {
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		// Veiculo sem match no provedor: guardado em cache como resultado vazio
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("lookup returned status %d", resp.StatusCode)
+	default:
+		var lookup lookupResponse
+		if err := json.NewDecoder(resp.Body).Decode(&lookup); err != nil {
+			return nil, fmt.Errorf("failed to decode lookup: %w", err)
+		}
+		specs = especificacoesAoVivo(id, lookup)
+	}
+	return specs, nil
+}
+
+// especificacoesAoVivo converte a resposta do match server para o formato da API
+func especificacoesAoVivo(id int, lookup lookupResponse) []model.EspecificacaoTecnica {
+	agora := time.Now()
+	specs := make([]model.EspecificacaoTecnica, len(lookup.Specs))
+	for i, spec := range lookup.Specs {
+		specs[i] = model.EspecificacaoTecnica{
+			CodigoAplicacao:     id,
+			TipoFluido:          spec.TipoFluido,
+			Condicao:            spec.Condicao,
+			Viscosidade:         textoOpcional(spec.Viscosidade),
+			Capacidade:          textoOpcional(spec.Capacidade),
+			Norma:               textoOpcional(spec.Norma),
+			Recomendacao:        textoOpcional(spec.Recomendacao),
+			Observacao:          textoOpcional(spec.Observacao),
+			IntervaloTrocaKm:    inteiroOpcional(spec.IntervaloTrocaKm),
+			IntervaloTrocaMeses: inteiroOpcional(spec.IntervaloTrocaMeses),
+			Fonte:               lookup.Provider,
+			MotulVehicleTypeID:  textoOpcional(lookup.Vehicle.ID),
+			CriadoEm:            agora,
+			AtualizadoEm:        agora,
+		}
+	}
+	return specs
+}
+
+func textoOpcional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// inteiroOpcional retorna nil para 0 (desconhecido)
+func inteiroOpcional(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
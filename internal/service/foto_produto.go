@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/telemetry"
+)
+
+var (
+	ErrProdutoNaoEncontrado = errors.New("produto nao encontrado")
+	// ErrFotoNaoEncontrada indica produto sem foto ou arquivo ausente no host de fotos
+	ErrFotoNaoEncontrada = errors.New("foto nao encontrada")
+	// ErrFotoIndisponivel envolve as falhas do host de fotos (fora do ar, timeout, imagem invalida)
+	ErrFotoIndisponivel = errors.New("falha ao buscar a foto")
+)
+
+// Formatos de saida das fotos
+const (
+	FormatoJPEG = "jpeg"
+	FormatoPNG  = "png"
+	FormatoWebP = "webp"
+)
+
+const (
+	// maxArquivoFoto limita a foto baixada do host de fotos
+	maxArquivoFoto = 20 << 20
+	// maxPixelsFoto recusa fotos grandes demais para decodificar em memoria
+	maxPixelsFoto = 40_000_000
+	// maxFotosEmCache limita as fotos em memoria (cada tamanho e formato conta uma)
+	maxFotosEmCache = 500
+	// maxBytesFotosEmCache limita a memoria das fotos em cache
+	maxBytesFotosEmCache = 256 << 20
+	// maxProcessamentosFoto limita as fotos decodificadas ao mesmo tempo; com
+	// maxPixelsFoto, cada uma ocupa ate 160 MB em memoria
+	maxProcessamentosFoto = 4
+	qualidadeJPEG         = 85
+)
+
+// OpcoesFoto pede a foto redimensionada para caber em Largura x Altura
+// (0 nao limita) e/ou convertida para Formato (vazio mantem o original)
+type OpcoesFoto struct {
+	Largura int
+	Altura  int
+	Formato string
+}
+
+// FotoProduto e a imagem pronta para enviar
+type FotoProduto struct {
+	Dados       []byte
+	ContentType string
+	ETag        string
+}
+
+// FotoProdutoService resolve a foto de um produto no host de arquivos da Wega,
+// redimensiona e converte quando pedido e guarda o resultado em memoria
+type FotoProdutoService struct {
+	repo       FotoProdutoReader
+	baseURL    string
+	maxAge     time.Duration
+	httpClient *http.Client
+	cache      *lruCache[string, *FotoProduto]
+	processos  chan struct{} // Semaforo de maxProcessamentosFoto
+}
+
+func NewFotoProdutoService(repo FotoProdutoReader, cfg config.FotoProdutoConfig) *FotoProdutoService {
+	return &FotoProdutoService{
+		repo:       repo,
+		baseURL:    cfg.BaseURL,
+		maxAge:     cfg.MaxAge,
+		httpClient: telemetry.NewHTTPClient(cfg.Timeout),
+		cache: newLRUCacheBytes[string](cfg.CacheTTL, maxFotosEmCache, maxBytesFotosEmCache,
+			func(foto *FotoProduto) int64 { return int64(len(foto.Dados)) }),
+		processos: make(chan struct{}, maxProcessamentosFoto),
+	}
+}
+
+// NormalizarFormatoFoto aceita jpeg (ou jpg), png e webp, sem diferenciar
+// maiusculas; vazio continua vazio
+func NormalizarFormatoFoto(formato string) (string, bool) {
+	switch formato = strings.ToLower(strings.TrimSpace(formato)); formato {
+	case "":
+		return "", true
+	case "jpg", FormatoJPEG:
+		return FormatoJPEG, true
+	case FormatoPNG, FormatoWebP:
+		return formato, true
+	}
+	return "", false
+}
+
+// Foto retorna a foto do codigo Wega com as opcoes pedidas. Sem opcoes, ou
+// quando nada muda, os bytes do host de fotos seguem como estao.
+func (s *FotoProdutoService) Foto(ctx context.Context, codigoWega string, opcoes OpcoesFoto) (*FotoProduto, error) {
+	codigoWega = strings.ToUpper(strings.TrimSpace(codigoWega))
+	original, err := s.original(ctx, codigoWega)
+	if err != nil || opcoes == (OpcoesFoto{}) {
+		return original, err
+	}
+
+	chave := fmt.Sprintf("%s|%d|%d|%s", codigoWega, opcoes.Largura, opcoes.Altura, opcoes.Formato)
+	if foto, ok := s.cache.Get(chave); ok {
+		return foto, nil
+	}
+
+	select {
+	case s.processos <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	foto, err := processarFoto(original, opcoes)
+	<-s.processos
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFotoIndisponivel, err)
+	}
+	s.cache.Set(chave, foto)
+	return foto, nil
+}
+
+// original retorna a foto como esta no host de arquivos, guardada em cache
+// para as demais variacoes de tamanho e formato
+func (s *FotoProdutoService) original(ctx context.Context, codigoWega string) (*FotoProduto, error) {
+	if foto, ok := s.cache.Get(codigoWega); ok {
+		return foto, nil
+	}
+
+	arquivo, encontrado, err := s.repo.ArquivoFoto(ctx, codigoWega)
+	if err != nil {
+		return nil, err
+	}
+	if !encontrado {
+		return nil, ErrProdutoNaoEncontrado
+	}
+	if arquivo == "" {
+		return nil, ErrFotoNaoEncontrada
+	}
+
+	dados, contentType, err := s.baixar(ctx, arquivo)
+	if err != nil {
+		return nil, err
+	}
+	foto := novaFoto(dados, contentType)
+	s.cache.Set(codigoWega, foto)
+	return foto, nil
+}
+
+// novaFoto calcula o ETag da imagem pelo conteudo
+func novaFoto(dados []byte, contentType string) *FotoProduto {
+	soma := sha256.Sum256(dados)
+	return &FotoProduto{Dados: dados, ContentType: contentType, ETag: `"` + hex.EncodeToString(soma[:8]) + `"`}
+}
+
+// CacheControl e o header Cache-Control das fotos (PRODUCT_IMAGE_MAX_AGE)
+func (s *FotoProdutoService) CacheControl() string {
+	return fmt.Sprintf("public, max-age=%d", int(s.maxAge.Seconds()))
+}
+
+// Invalidar esvazia as fotos em memoria (fotos trocadas no host de arquivos)
+func (s *FotoProdutoService) Invalidar() int {
+	return s.cache.Clear()
+}
+
+// urlFoto monta a URL do arquivo no host de fotos; ArquivoFotoProduto pode
+// vir com caminho no estilo Windows ("fotos\\WO780.jpg")
+func (s *FotoProdutoService) urlFoto(arquivo string) (string, error) {
+	arquivo = strings.TrimLeft(strings.ReplaceAll(arquivo, `\`, "/"), "/")
+	return url.JoinPath(s.baseURL, strings.Split(arquivo, "/")...)
+}
+
+// baixar busca a foto no host de arquivos e retorna os bytes e o Content-Type
+func (s *FotoProdutoService) baixar(ctx context.Context, arquivo string) ([]byte, string, error) {
+	fotoURL, err := s.urlFoto(arquivo)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: arquivo %q invalido: %v", ErrFotoIndisponivel, arquivo, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fotoURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrFotoIndisponivel, err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrFotoIndisponivel, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, "", ErrFotoNaoEncontrada
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("%w: host de fotos respondeu status %d", ErrFotoIndisponivel, resp.StatusCode)
+	}
+
+	dados, err := io.ReadAll(io.LimitReader(resp.Body, maxArquivoFoto+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrFotoIndisponivel, err)
+	}
+	if len(dados) > maxArquivoFoto {
+		return nil, "", fmt.Errorf("%w: foto maior que %d bytes", ErrFotoIndisponivel, maxArquivoFoto)
+	}
+
+	// Hosts de arquivos costumam responder application/octet-stream
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(dados)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("%w: arquivo nao e uma imagem (%s)", ErrFotoIndisponivel, contentType)
+	}
+	return dados, contentType, nil
+}
+
+// processarFoto redimensiona a foto para caber nas dimensoes pedidas, sem
+// ampliar nem distorcer, e a codifica no formato pedido (ou no original)
+func processarFoto(original *FotoProduto, opcoes OpcoesFoto) (*FotoProduto, error) {
+	cfg, formatoOriginal, err := image.DecodeConfig(bytes.NewReader(original.Dados))
+	if err != nil {
+		return nil, fmt.Errorf("imagem invalida: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPixelsFoto {
+		return nil, fmt.Errorf("imagem grande demais (%dx%d)", cfg.Width, cfg.Height)
+	}
+
+	formato := opcoes.Formato
+	if formato == "" {
+		formato = formatoOriginal
+		if formato == "gif" {
+			formato = FormatoPNG
+		}
+	}
+
+	largura, altura := dimensoesFoto(cfg.Width, cfg.Height, opcoes.Largura, opcoes.Altura)
+	if largura == cfg.Width && altura == cfg.Height && (opcoes.Formato == "" || formato == formatoOriginal) {
+		return original, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(original.Dados))
+	if err != nil {
+		return nil, fmt.Errorf("imagem invalida: %w", err)
+	}
+	if largura != cfg.Width || altura != cfg.Height {
+		redimensionada := image.NewNRGBA(image.Rect(0, 0, largura, altura))
+		xdraw.CatmullRom.Scale(redimensionada, redimensionada.Bounds(), img, img.Bounds(), draw.Src, nil)
+		img = redimensionada
+	}
+
+	var buf bytes.Buffer
+	switch formato {
+	case FormatoWebP:
+		err = nativewebp.Encode(&buf, img, nil)
+	case FormatoPNG:
+		err = png.Encode(&buf, img)
+	default:
+		// JPEG nao tem transparencia: o fundo transparente vira branco
+		fundo := image.NewRGBA(img.Bounds())
+		draw.Draw(fundo, fundo.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(fundo, fundo.Bounds(), img, img.Bounds().Min, draw.Over)
+		formato = FormatoJPEG
+		err = jpeg.Encode(&buf, fundo, &jpeg.Options{Quality: qualidadeJPEG})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao codificar %s: %w", formato, err)
+	}
+	return novaFoto(buf.Bytes(), "image/"+formato), nil
+}
+
+// dimensoesFoto calcula o tamanho que cabe em maxLargura x maxAltura (0 nao
+// limita) mantendo a proporcao; a foto nunca e ampliada
+func dimensoesFoto(largura, altura, maxLargura, maxAltura int) (int, int) {
+	escala := 1.0
+	if maxLargura > 0 && largura > maxLargura {
+		escala = float64(maxLargura) / float64(largura)
+	}
+	if maxAltura > 0 && altura > maxAltura {
+		escala = min(escala, float64(maxAltura)/float64(altura))
+	}
+	if escala == 1 {
+		return largura, altura
+	}
+	return max(int(float64(largura)*escala+0.5), 1), max(int(float64(altura)*escala+0.5), 1)
+}
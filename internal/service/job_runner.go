@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job e uma tarefa periodica executada em background pela API
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// JobRunner executa jobs periodicos ate o contexto ser cancelado
+type JobRunner struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+func NewJobRunner() *JobRunner {
+	return &JobRunner{}
+}
+
+// Add registra um job; deve ser chamado antes de Start
+func (j *JobRunner) Add(name string, interval time.Duration, run func(ctx context.Context) error) {
+	j.jobs = append(j.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start executa cada job imediatamente e depois a cada Interval, em goroutines proprias
+func (j *JobRunner) Start(ctx context.Context) {
+	for _, job := range j.jobs {
+		j.wg.Add(1)
+		go func() {
+			defer j.wg.Done()
+			j.loop(ctx, job)
+		}()
+	}
+}
+
+// Wait aguarda os jobs terminarem apos o cancelamento do contexto
+func (j *JobRunner) Wait() {
+	j.wg.Wait()
+}
+
+func (j *JobRunner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("job falhou", "job", job.Name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruCache e um cache em memoria com validade por entrada e limite de
+// tamanho: acima de maxEntries (ou de maxBytes, quando ha tamanho por
+// entrada) a entrada usada ha mais tempo sai primeiro
+type lruCache[K comparable, V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64         // 0 nao limita
+	tamanho    func(V) int64 // Bytes de uma entrada, para maxBytes
+
+	mu      sync.Mutex
+	order   *list.List // Mais recente na frente
+	entries map[K]*list.Element
+	bytes   int64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key      K
+	value    V
+	expiraEm time.Time
+	bytes    int64
+}
+
+func newLRUCache[K comparable, V any](ttl time.Duration, maxEntries int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[K]*list.Element),
+	}
+}
+
+// newLRUCacheBytes cria um cache limitado tambem pelo total de bytes das
+// entradas, medido por tamanho; uma entrada maior que maxBytes nao e guardada
+func newLRUCacheBytes[K comparable, V any](ttl time.Duration, maxEntries int, maxBytes int64, tamanho func(V) int64) *lruCache[K, V] {
+	c := newLRUCache[K, V](ttl, maxEntries)
+	c.maxBytes, c.tamanho = maxBytes, tamanho
+	return c
+}
+
+// Get retorna o valor ainda valido de key
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[K, V])
+	if time.Now().After(entry.expiraEm) {
+		c.remove(elem)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set guarda value por ttl, descartando a entrada menos usada se o cache encheu
+func (c *lruCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var bytes int64
+	if c.tamanho != nil {
+		bytes = c.tamanho(value)
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	if c.maxBytes > 0 && bytes > c.maxBytes {
+		return
+	}
+
+	entry := &lruEntry[K, V]{key: key, value: value, expiraEm: time.Now().Add(c.ttl), bytes: bytes}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += bytes
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove tira uma entrada do cache (caller holds mu)
+func (c *lruCache[K, V]) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry[K, V])
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.bytes
+}
+
+// Clear esvazia o cache e retorna quantas entradas havia
+func (c *lruCache[K, V]) Clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.order.Len()
+	c.order.Init()
+	clear(c.entries)
+	c.bytes = 0
+	return n
+}
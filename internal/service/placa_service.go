@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/matching"
+	"wega-catalog-api/internal/model"
+)
+
+var (
+	ErrPlacaInvalida      = errors.New("placa invalida")
+	ErrPlacaNaoEncontrada = errors.New("placa nao encontrada")
+	// ErrProvedorPlaca envolve as falhas do provedor de placas (fora do ar, timeout, resposta invalida)
+	ErrProvedorPlaca = errors.New("falha no provedor de placas")
+)
+
+// placaRegex aceita o padrao antigo (ABC1234) e o Mercosul (ABC1D23)
+var placaRegex = regexp.MustCompile(`^[A-Z]{3}[0-9][A-Z0-9][0-9]{2}$`)
+
+// cilindradaColada separa a cilindrada das letras coladas nela ("1.4MT"),
+// que o extrator leria como 1
+var cilindradaColada = regexp.MustCompile(`(\d[.,]\d)([A-Z])`)
+
+// marcasPlaca traduz as abreviacoes do registro do veiculo para o nome do fabricante no catalogo
+var marcasPlaca = map[string]string{
+	"VW":     "VOLKSWAGEN",
+	"VOLKS":  "VOLKSWAGEN",
+	"GM":     "CHEVROLET",
+	"CHEV":   "CHEVROLET",
+	"MB":     "MERCEDES",
+	"M.BENZ": "MERCEDES",
+	"MBENZ":  "MERCEDES",
+	"LR":     "LAND ROVER",
+}
+
+// palavrasIgnoradasModelo nao identificam o modelo ("NOVO UNO" e o UNO)
+var palavrasIgnoradasModelo = map[string]bool{"NOVO": true, "NOVA": true, "NEW": true}
+
+// consultaPlaca e uma resposta do provedor guardada em cache; veiculo nil
+// quando a placa nao foi encontrada
+type consultaPlaca struct {
+	veiculo *client.PlateVehicle
+}
+
+// PlacaResultado e o veiculo de uma placa com a busca de filtros feita a
+// partir dele
+type PlacaResultado struct {
+	Placa     string
+	Veiculo   model.VeiculoPlaca
+	Busca     model.BuscaFiltrosRequest
+	Resultado *model.BuscaFiltrosResponse
+	// CodigoAplicacao e a unica aplicacao encontrada ou o best_guess das opcoes (0 sem nenhuma)
+	CodigoAplicacao int
+	Especificacoes  []model.EspecificacaoTecnica
+}
+
+// PlacaService resolve uma placa no provedor externo e busca os filtros e as
+// especificacoes do veiculo. As respostas do provedor, que cobra por
+// consulta, ficam em cache, inclusive as placas nao encontradas.
+type PlacaService struct {
+	lookup         client.PlateLookup
+	catalogo       *CatalogoService
+	especificacoes *EspecificacaoService
+	cache          *lruCache[string, consultaPlaca]
+}
+
+func NewPlacaService(
+	lookup client.PlateLookup,
+	catalogo *CatalogoService,
+	especificacoes *EspecificacaoService,
+	cfg config.PlacaConfig,
+) *PlacaService {
+	return &PlacaService{
+		lookup:         lookup,
+		catalogo:       catalogo,
+		especificacoes: especificacoes,
+		cache:          newLRUCache[string, consultaPlaca](cfg.CacheTTL, 10000),
+	}
+}
+
+// NewPlateLookup cria o cliente do provedor de placas configurado; nil
+// quando a consulta por placa esta desativada
+func NewPlateLookup(cfg config.PlacaConfig) client.PlateLookup {
+	if cfg.URL == "" {
+		return nil
+	}
+	campos := client.PlateFields{
+		Brand:     cfg.Campos["marca"],
+		Model:     cfg.Campos["modelo"],
+		Version:   cfg.Campos["versao"],
+		Year:      cfg.Campos["ano"],
+		ModelYear: cfg.Campos["ano_modelo"],
+		Fuel:      cfg.Campos["combustivel"],
+	}
+	return client.NewHTTPPlateLookup(client.HTTPPlateConfig{
+		URLTemplate:       cfg.URL,
+		AuthHeader:        cfg.AuthHeader,
+		AuthScheme:        cfg.AuthScheme,
+		Token:             cfg.Token,
+		Fields:            campos,
+		Timeout:           cfg.Timeout,
+		RequestsPerMinute: cfg.LimiteProvedor,
+	})
+}
+
+// NormalizarPlaca coloca a placa em maiusculas sem hifen nem espacos
+// ("abc-1d23" vira "ABC1D23") e confere o formato
+func NormalizarPlaca(placa string) (string, bool) {
+	placa = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(placa))
+	return placa, placaRegex.MatchString(placa)
+}
+
+// BuscarPorPlaca consulta o veiculo da placa e busca seus filtros como o
+// POST /filtros/buscar; chegando a uma aplicacao, inclui as especificacoes
+func (s *PlacaService) BuscarPorPlaca(ctx context.Context, placa string) (*PlacaResultado, error) {
+	placa, ok := NormalizarPlaca(placa)
+	if !ok {
+		return nil, ErrPlacaInvalida
+	}
+
+	veiculo, err := s.consultarPlaca(ctx, placa)
+	if err != nil {
+		return nil, err
+	}
+
+	req := requisicaoPlaca(veiculo)
+	resultado, err := s.catalogo.BuscarFiltros(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PlacaResultado{
+		Placa: placa,
+		Veiculo: model.VeiculoPlaca{
+			Marca:         veiculo.Brand,
+			Modelo:        veiculo.Model,
+			Versao:        veiculo.Version,
+			AnoFabricacao: veiculo.Year,
+			AnoModelo:     veiculo.ModelYear,
+			Combustivel:   veiculo.Fuel,
+		},
+		Busca:     req,
+		Resultado: resultado,
+	}
+	if resultado.Veiculo != nil && resultado.Veiculo.ID > 0 {
+		r.CodigoAplicacao = resultado.Veiculo.ID
+	} else {
+		r.CodigoAplicacao = resultado.BestGuess
+	}
+
+	if r.CodigoAplicacao > 0 {
+		specs, err := s.especificacoes.BuscarEspecificacoes(ctx, r.CodigoAplicacao, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		r.Especificacoes = specs.Especificacoes
+	}
+	return r, nil
+}
+
+// consultarPlaca consulta o provedor, usando o cache quando possivel
+func (s *PlacaService) consultarPlaca(ctx context.Context, placa string) (*client.PlateVehicle, error) {
+	if c, ok := s.cache.Get(placa); ok {
+		if c.veiculo == nil {
+			return nil, ErrPlacaNaoEncontrada
+		}
+		return c.veiculo, nil
+	}
+
+	veiculo, err := s.lookup.LookupPlate(ctx, placa)
+	if errors.Is(err, client.ErrPlateNotFound) {
+		s.cache.Set(placa, consultaPlaca{})
+		return nil, ErrPlacaNaoEncontrada
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvedorPlaca, err)
+	}
+
+	s.cache.Set(placa, consultaPlaca{veiculo: veiculo})
+	return veiculo, nil
+}
+
+// requisicaoPlaca monta a busca de filtros a partir do registro do veiculo:
+// marca sem abreviacao, a primeira palavra do modelo ("GOL" de "GOL 1.0 12V
+// MPI TOTALFLEX"), as caracteristicas do motor do restante, o ano modelo e o
+// combustivel
+func requisicaoPlaca(v *client.PlateVehicle) model.BuscaFiltrosRequest {
+	marca, modelo := separarMarcaModelo(v.Brand, v.Model)
+
+	palavras := strings.Fields(modelo + " " + strings.ToUpper(v.Version))
+	for len(palavras) > 1 && palavrasIgnoradasModelo[palavras[0]] {
+		palavras = palavras[1:]
+	}
+
+	req := model.BuscaFiltrosRequest{Marca: marca}
+	if len(palavras) > 0 {
+		req.Modelo = palavras[0]
+		// O nome do modelo fica de fora: "208" nao e cilindrada
+		motor := matching.ExtractFeatures(cilindradaColada.ReplaceAllString(strings.Join(palavras[1:], " "), "$1 $2"), 0)
+		if motor.Cilindrada >= 0.6 && motor.Cilindrada <= 8 {
+			req.Cilindrada = motor.Cilindrada
+		}
+		req.Valvulas = motor.Valvulas
+		req.Potencia = motor.Potencia
+	}
+
+	if ano := v.ModelYear; ano > 0 {
+		req.Ano = strconv.Itoa(ano)
+	} else if v.Year > 0 {
+		req.Ano = strconv.Itoa(v.Year)
+	}
+
+	req.Combustivel = combustivelPlaca(v.Fuel)
+	return req
+}
+
+// separarMarcaModelo tira o "I/" dos importados, separa a marca do modelo
+// registrado como "VW/GOL" e expande as abreviacoes das marcas
+func separarMarcaModelo(marca, modelo string) (string, string) {
+	marca = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(marca)), "I/")
+	modelo = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(modelo)), "I/")
+
+	if prefixo, resto, ok := strings.Cut(modelo, "/"); ok && !strings.Contains(prefixo, " ") {
+		if marca == "" || marca == prefixo {
+			marca = prefixo
+		}
+		modelo = strings.TrimSpace(resto)
+	}
+	if prefixo, resto, ok := strings.Cut(marca, "/"); ok {
+		marca = prefixo
+		if modelo == "" {
+			modelo = strings.TrimSpace(resto)
+		}
+	}
+
+	if nome, ok := marcasPlaca[marca]; ok {
+		marca = nome
+	}
+	return marca, modelo
+}
+
+// combustivelPlaca interpreta o combustivel do registro; "ALCOOL/GASOLINA" e flex
+func combustivelPlaca(combustivel string) string {
+	texto := matching.Normalize(combustivel)
+	if strings.Contains(texto, "gasolina") && (strings.Contains(texto, "alcool") || strings.Contains(texto, "etanol")) {
+		return model.CombustivelFlex
+	}
+	return matching.DetectFuel(combustivel)
+}
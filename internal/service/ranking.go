@@ -0,0 +1,160 @@
+package service
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"wega-catalog-api/internal/matching"
+	"wega-catalog-api/internal/model"
+)
+
+// Pesos do score de desambiguacao (somam 1)
+const (
+	pesoAno   = 0.5
+	pesoMotor = 0.5
+	// scoreNeutro e a nota de um criterio que a busca ou a aplicacao nao informa
+	scoreNeutro = 0.5
+	// anosTolerancia e a distancia, em anos, a partir da qual o ano zera
+	anosTolerancia = 10
+)
+
+var (
+	anoCompletoRegex = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+	// "// 08 -- 10" ou "// 08 -->": anos com dois digitos no fim da descricao
+	anoCurtoRegex = regexp.MustCompile(`//\s*(\d{2})\b(?:\s*--\s*(\d{2})\b)?`)
+)
+
+// ranquearOpcoes pontua cada aplicacao de 0 a 1 pela proximidade do ano e
+// pelas caracteristicas de motor (cilindrada, valvulas, potencia, combustivel)
+// em comum com a busca, e retorna as opcoes da maior para a menor nota. Em
+// empate a ordem recebida (popularidade) se mantem. O palpite e a primeira
+// opcao quando ela supera a segunda; 0 quando ha empate.
+func ranquearOpcoes(aplicacoes []model.Aplicacao, req model.BuscaFiltrosRequest) (opcoes []model.OpcaoVeiculo, palpite int) {
+	ano, _ := strconv.Atoi(strings.TrimSpace(req.Ano))
+	pedidas := caracteristicasPedidas(req)
+	if req.Motor != "" {
+		// "1.6 16V" no motor conta como cilindrada e valvulas pedidas
+		doMotor := matching.ExtractFeatures(req.Motor, 0)
+		if !pedidas.HasCilindrada() {
+			pedidas.Cilindrada = doMotor.Cilindrada
+		}
+		if !pedidas.HasValvulas() {
+			pedidas.Valvulas = doMotor.Valvulas
+		}
+		if !pedidas.HasPotencia() {
+			pedidas.Potencia = doMotor.Potencia
+		}
+	}
+
+	opcoes = make([]model.OpcaoVeiculo, 0, len(aplicacoes))
+	for _, a := range aplicacoes {
+		score := pesoAno*scoreAno(a, ano) + pesoMotor*scoreMotor(a, pedidas, req.Combustivel)
+		opcoes = append(opcoes, model.OpcaoVeiculo{
+			ID:              a.CodigoAplicacao,
+			Descricao:       a.DescricaoAplicacao,
+			AnoDesconhecido: a.AnoDesconhecido,
+			Score:           math.Round(score*100) / 100,
+		})
+	}
+
+	sort.SliceStable(opcoes, func(i, j int) bool {
+		return opcoes[i].Score > opcoes[j].Score
+	})
+	if len(opcoes) == 1 || (len(opcoes) > 1 && opcoes[0].Score > opcoes[1].Score) {
+		palpite = opcoes[0].ID
+	}
+	return opcoes, palpite
+}
+
+// scoreAno e 1 quando o ano pedido esta no periodo da aplicacao e cai
+// linearmente ate 0 a anosTolerancia anos de distancia
+func scoreAno(a model.Aplicacao, ano int) float64 {
+	de, ate, ok := intervaloAnos(a)
+	if ano == 0 || !ok {
+		return scoreNeutro
+	}
+
+	distancia := 0
+	switch {
+	case ano < de:
+		distancia = de - ano
+	case ano > ate:
+		distancia = ano - ate
+	}
+	return math.Max(0, 1-float64(distancia)/anosTolerancia)
+}
+
+// scoreMotor e a fracao das caracteristicas pedidas que a aplicacao confirma;
+// as que a descricao nao traz valem meio ponto
+func scoreMotor(a model.Aplicacao, pedidas matching.VehicleFeatures, combustivel string) float64 {
+	total := 0
+	for _, pedida := range []bool{pedidas.HasCilindrada(), pedidas.HasValvulas(), pedidas.HasPotencia(), combustivel != ""} {
+		if pedida {
+			total++
+		}
+	}
+	if total == 0 {
+		return scoreNeutro
+	}
+
+	matches, conflitos := matching.CompareFeatures(pedidas, caracteristicasAplicacao(a))
+	if combustivel != "" {
+		if doVeiculo := combustivelAplicacao(a); doVeiculo != "" {
+			if matching.FuelCompatible(combustivel, doVeiculo) {
+				matches++
+			} else {
+				conflitos++
+			}
+		}
+	}
+	desconhecidas := total - matches - conflitos
+	return (float64(matches) + scoreNeutro*float64(desconhecidas)) / float64(total)
+}
+
+// intervaloAnos le o periodo da aplicacao: anos com quatro digitos no periodo
+// ou na descricao ("2019 -->", "2008 -- 2012") ou, sem eles, os de dois
+// digitos do fim da descricao ("// 08 -- 10"). Periodos abertos ("-->") vao
+// ate o ano atual.
+func intervaloAnos(a model.Aplicacao) (de, ate int, ok bool) {
+	texto := a.Periodo + " " + a.DescricaoAplicacao
+
+	var anos []int
+	for _, m := range anoCompletoRegex.FindAllString(texto, -1) {
+		ano, _ := strconv.Atoi(m)
+		anos = append(anos, ano)
+	}
+	if len(anos) == 0 {
+		if m := anoCurtoRegex.FindStringSubmatch(texto); m != nil {
+			for _, curto := range m[1:] {
+				if curto != "" {
+					anos = append(anos, anoDeDoisDigitos(curto))
+				}
+			}
+		}
+	}
+	if len(anos) == 0 {
+		return 0, 0, false
+	}
+
+	de, ate = anos[0], anos[0]
+	for _, ano := range anos[1:] {
+		de, ate = min(de, ano), max(ate, ano)
+	}
+	if len(anos) == 1 && strings.Contains(texto, "-->") {
+		ate = max(ate, time.Now().Year())
+	}
+	return de, ate, true
+}
+
+// anoDeDoisDigitos converte "08" em 2008 e "98" em 1998 (ate o ano que vem e deste seculo)
+func anoDeDoisDigitos(curto string) int {
+	n, _ := strconv.Atoi(curto)
+	if n <= time.Now().Year()%100+1 {
+		return 2000 + n
+	}
+	return 1900 + n
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/jackc/pgx/v5"
+
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/parser"
+)
+
+// ErrAplicacaoNaoEncontrada indica que o ID nao corresponde a nenhuma aplicacao
+var ErrAplicacaoNaoEncontrada = errors.New("aplicacao nao encontrada")
+
+// RecomendacaoOleo reune os filtros de oleo Wega e as especificacoes do oleo do
+// motor de uma aplicacao
+type RecomendacaoOleo struct {
+	Aplicacao   *model.Aplicacao
+	FiltrosOleo []model.Produto
+	Oleo        []model.EspecificacaoTecnica // Padrao primeiro, depois por condicao de uso
+	AoVivo      bool
+
+	produtos []model.Produto // Todos os produtos da aplicacao, para o kit
+}
+
+// RecomendacaoService junta o catalogo de filtros Wega as especificacoes de
+// fluidos raspadas dos provedores
+type RecomendacaoService struct {
+	aplicacaoRepo  AplicacaoReader
+	produtoRepo    ProdutoReader
+	especificacoes *EspecificacaoService
+	popularidade   PopularidadeStore
+}
+
+func NewRecomendacaoService(
+	aplicacaoRepo AplicacaoReader,
+	produtoRepo ProdutoReader,
+	especificacoes *EspecificacaoService,
+	popularidade PopularidadeStore,
+) *RecomendacaoService {
+	return &RecomendacaoService{
+		aplicacaoRepo:  aplicacaoRepo,
+		produtoRepo:    produtoRepo,
+		especificacoes: especificacoes,
+		popularidade:   popularidade,
+	}
+}
+
+// RecomendacaoOleo busca a aplicacao, seus filtros de oleo e as especificacoes
+// do oleo do motor (consultadas ao vivo quando aoVivo e nao houver gravadas).
+// Retorna ErrAplicacaoNaoEncontrada para IDs inexistentes.
+func (s *RecomendacaoService) RecomendacaoOleo(ctx context.Context, id int, aoVivo bool) (*RecomendacaoOleo, error) {
+	aplicacao, err := s.aplicacaoRepo.BuscarPorID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAplicacaoNaoEncontrada
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aplicacao: %w", err)
+	}
+
+	produtos, err := s.produtoRepo.BuscarPorAplicacao(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get produtos: %w", err)
+	}
+
+	resultado, err := s.especificacoes.BuscarEspecificacoes(ctx, id, nil, aoVivo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consultas sem especificacao indicam demanda que o scraper ainda nao atende
+	if s.popularidade != nil {
+		if err := s.popularidade.Record(ctx, id, resultado.Armazenadas == 0); err != nil {
+			slog.Warn("falha ao registrar popularidade", "codigo_aplicacao", id, "error", err)
+		}
+	}
+
+	oleo := []model.EspecificacaoTecnica{}
+	for _, spec := range resultado.Especificacoes {
+		if model.TipoFluidoCode(spec.TipoFluido) == model.FluidoOleoMotor {
+			oleo = append(oleo, spec)
+		}
+	}
+
+	return &RecomendacaoOleo{
+		Aplicacao:   aplicacao,
+		FiltrosOleo: model.FiltrarPorCategoria(produtos, model.FiltroOleo),
+		Oleo:        oleo,
+		AoVivo:      resultado.AoVivo,
+		produtos:    produtos,
+	}, nil
+}
+
+// OpcoesKit escolhe os filtros opcionais do kit de troca de oleo
+type OpcoesKit struct {
+	FiltroAr     bool
+	FiltroCabine bool
+}
+
+// MontarKitTrocaOleo monta o kit de troca de oleo de uma aplicacao: o filtro de
+// oleo, os filtros de ar e cabine se pedidos, e o oleo do motor (especificacao
+// padrao, nao a de uso severo) com os litros arredondados para cima. Itens sem
+// produto ou especificacao ficam de fora e sao listados em Faltando.
+func (s *RecomendacaoService) MontarKitTrocaOleo(ctx context.Context, id int, aoVivo bool, opcoes OpcoesKit) (*model.KitTrocaOleo, error) {
+	recomendacao, err := s.RecomendacaoOleo(ctx, id, aoVivo)
+	if err != nil {
+		return nil, err
+	}
+
+	kit := &model.KitTrocaOleo{Aplicacao: recomendacao.Aplicacao}
+	if len(recomendacao.FiltrosOleo) > 0 {
+		kit.FiltroOleo = &recomendacao.FiltrosOleo[0]
+	} else {
+		kit.Faltando = append(kit.Faltando, model.ItemKitFiltroOleo)
+	}
+
+	if opcoes.FiltroAr {
+		kit.FiltroAr = primeiroDaCategoria(recomendacao.produtos, model.FiltroAr)
+		if kit.FiltroAr == nil {
+			kit.Faltando = append(kit.Faltando, model.ItemKitFiltroAr)
+		}
+	}
+	if opcoes.FiltroCabine {
+		kit.FiltroCabine = primeiroDaCategoria(recomendacao.produtos, model.FiltroCabine)
+		if kit.FiltroCabine == nil {
+			kit.Faltando = append(kit.Faltando, model.ItemKitFiltroCabine)
+		}
+	}
+
+	kit.Oleo = oleoDoKit(recomendacao.Oleo)
+	if kit.Oleo == nil {
+		kit.Faltando = append(kit.Faltando, model.ItemKitOleo)
+	}
+
+	kit.Completo = len(kit.Faltando) == 0
+	return kit, nil
+}
+
+// primeiroDaCategoria retorna o primeiro produto da categoria de filtro, ou nil
+func primeiroDaCategoria(produtos []model.Produto, categoria string) *model.Produto {
+	if filtrados := model.FiltrarPorCategoria(produtos, categoria); len(filtrados) > 0 {
+		return &filtrados[0]
+	}
+	return nil
+}
+
+// oleoDoKit monta o oleo do kit a partir da especificacao padrao (ou da
+// primeira, se so houver por condicao de uso). Sem capacidade reconhecivel nao
+// ha como dizer quanto comprar, e o oleo fica de fora.
+func oleoDoKit(specs []model.EspecificacaoTecnica) *model.OleoKit {
+	if len(specs) == 0 {
+		return nil
+	}
+	spec := specs[0]
+	for _, s := range specs {
+		if s.Condicao == model.CondicaoPadrao {
+			spec = s
+			break
+		}
+	}
+
+	var capacidade, viscosidade string
+	if spec.Capacidade != nil {
+		capacidade = *spec.Capacidade
+	}
+	if spec.Viscosidade != nil {
+		viscosidade = *spec.Viscosidade
+	}
+	// Especificacoes ao vivo nao passam pelo repositorio, que preenche os valores numericos
+	litros, graus := spec.CapacidadeLitros, spec.ViscosidadesSAE
+	if litros == nil {
+		litros, graus = parser.NumericSpecValues(capacidade, viscosidade)
+	}
+	if litros == nil {
+		return nil
+	}
+
+	oleo := &model.OleoKit{
+		CapacidadeLitros:    *litros,
+		Litros:              int(math.Ceil(*litros)),
+		IntervaloTrocaKm:    spec.IntervaloTrocaKm,
+		IntervaloTrocaMeses: spec.IntervaloTrocaMeses,
+	}
+	if len(graus) > 0 {
+		oleo.Viscosidade = graus[0]
+	}
+	if spec.Recomendacao != nil {
+		oleo.Recomendacao = *spec.Recomendacao
+	}
+	if spec.Norma != nil {
+		oleo.Norma = *spec.Norma
+	}
+	return oleo
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Interfaces do acesso a dados usado pelos services e handlers. Os repositorios
+// de internal/repository as implementam; os consumidores dependem delas para
+// poder trocar a implementacao (cache, replica, testes) sem mudar o wiring.
+
+// FabricanteReader lista os fabricantes de veiculos e os concorrentes
+type FabricanteReader interface {
+	ListarVeiculos(ctx context.Context) ([]model.Fabricante, error)
+	ListarConcorrentes(ctx context.Context) ([]model.Fabricante, error)
+}
+
+// AplicacaoReader busca as aplicacoes (veiculos) do catalogo
+type AplicacaoReader interface {
+	BuscarPorVeiculo(ctx context.Context, marca, modelo, ano, motor string) ([]model.Aplicacao, error)
+	ListarOpcoes(ctx context.Context, marca, modelo string) (*model.OpcoesVeiculo, error)
+	BuscarPorID(ctx context.Context, id int) (*model.Aplicacao, error)
+}
+
+// ProdutoReader busca os filtros Wega por aplicacao e os tipos de filtro
+type ProdutoReader interface {
+	BuscarPorAplicacoes(ctx context.Context, codigosAplicacao []int) ([]model.Produto, error)
+	BuscarPorAplicacao(ctx context.Context, codigoAplicacao int) ([]model.Produto, error)
+	ListarTiposFiltro(ctx context.Context) ([]model.TipoFiltro, error)
+}
+
+// PrecoHistoricoReader busca a serie de precos de um codigo Wega
+type PrecoHistoricoReader interface {
+	HistoricoPrecos(ctx context.Context, codigoWega string, desde, ate *time.Time) (*model.HistoricoPrecosResponse, error)
+}
+
+// FotoProdutoReader busca o arquivo da foto de um codigo Wega
+type FotoProdutoReader interface {
+	ArquivoFoto(ctx context.Context, codigoWega string) (arquivo string, encontrado bool, err error)
+}
+
+// ReferenciaReader busca as equivalencias de codigos de concorrentes
+type ReferenciaReader interface {
+	BuscarPorCodigo(ctx context.Context, codigo string) (*model.ReferenciaResponse, error)
+}
+
+// PopularidadeStore registra as consultas por aplicacao e devolve seus scores
+type PopularidadeStore interface {
+	Record(ctx context.Context, codigoAplicacao int, semEspecificacao bool) error
+	Scores(ctx context.Context, codigosAplicacao []int) (map[int]float64, error)
+}
+
+// EspecificacaoReader busca as especificacoes gravadas de uma aplicacao
+type EspecificacaoReader interface {
+	ListByAplicacao(ctx context.Context, codigoAplicacao int) ([]model.EspecificacaoTecnica, error)
+	ListByAplicacaoAsOf(ctx context.Context, codigoAplicacao int, asOf time.Time) ([]model.EspecificacaoTecnica, error)
+}
+
+// SugestaoReader sugere marcas, modelos e codigos de produto para o autocomplete
+type SugestaoReader interface {
+	Sugerir(ctx context.Context, termo string, limit int) ([]model.Sugestao, error)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/repository"
+)
+
+// providerErrorPeriod e a janela usada para contar erros por provedor
+const providerErrorPeriod = 24 * time.Hour
+
+// providerErrorTypes agrupa os tipos de erro do scraper por provedor externo
+var providerErrorTypes = []struct {
+	provider string
+	tipos    []string
+}{
+	{"motul", []string{model.ErroTipoAPIMotul, model.ErroTipoSchemaDrift, model.ErroTipoRateLimit}},
+	{"llm", []string{model.ErroTipoAPIGroq}},
+}
+
+// SaudeService calcula o score de saude do sistema a partir dos subsistemas
+type SaudeService struct {
+	db                *pgxpool.Pool
+	aplicacaoRepo     *repository.AplicacaoRepo
+	especificacaoRepo *repository.EspecificacaoRepository
+	falhaRepo         *repository.ScraperFalhaRepo
+	limites           config.HealthThresholds
+	httpClient        *http.Client
+}
+
+func NewSaudeService(
+	db *pgxpool.Pool,
+	ar *repository.AplicacaoRepo,
+	er *repository.EspecificacaoRepository,
+	fr *repository.ScraperFalhaRepo,
+	limites config.HealthThresholds,
+) *SaudeService {
+	return &SaudeService{
+		db:                db,
+		aplicacaoRepo:     ar,
+		especificacaoRepo: er,
+		falhaRepo:         fr,
+		limites:           limites,
+		httpClient:        &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Avaliar executa todas as verificacoes e combina os resultados
+func (s *SaudeService) Avaliar(ctx context.Context) *model.SystemHealthResponse {
+	checks := []model.HealthCheck{
+		s.verificarBanco(ctx),
+		s.verificarIndiceBusca(ctx),
+		s.verificarScraper(ctx),
+		s.verificarFalhasPendentes(ctx),
+	}
+	checks = append(checks, s.verificarProvedores(ctx)...)
+	if s.limites.LLMStatusURL != "" {
+		checks = append(checks, s.verificarChavesLLM(ctx))
+	}
+
+	response := &model.SystemHealthResponse{
+		Status:    model.SaudeOK,
+		Timestamp: time.Now(),
+		Checks:    checks,
+	}
+
+	total := 0
+	for _, check := range checks {
+		total += check.Score
+		if pesoStatus(check.Status) > pesoStatus(response.Status) {
+			response.Status = check.Status
+		}
+	}
+	response.Score = total / len(checks)
+
+	return response
+}
+
+// verificarBanco mede a latencia de um ping ao banco
+func (s *SaudeService) verificarBanco(ctx context.Context) model.HealthCheck {
+	start := time.Now()
+	if err := s.db.Ping(ctx); err != nil {
+		return falhaVerificacao("database", err)
+	}
+	latencia := time.Since(start)
+
+	return novaVerificacao("database", latencia.String(),
+		nivel(float64(latencia), float64(s.limites.DBLatencyWarning), float64(s.limites.DBLatencyCritical)))
+}
+
+// verificarIndiceBusca confere o indice full-text recomendado para a busca de veiculos
+func (s *SaudeService) verificarIndiceBusca(ctx context.Context) model.HealthCheck {
+	exists, err := s.aplicacaoRepo.HasSearchIndex(ctx)
+	if err != nil {
+		return falhaVerificacao("search_index", err)
+	}
+	if !exists {
+		check := novaVerificacao("search_index", false, model.SaudeWarning)
+		check.Message = "idx_aplicacao_descricao nao existe; a busca por veiculo faz varredura completa"
+		return check
+	}
+	return novaVerificacao("search_index", true, model.SaudeOK)
+}
+
+// verificarScraper usa a ultima especificacao gravada como idade da ultima execucao bem sucedida
+func (s *SaudeService) verificarScraper(ctx context.Context) model.HealthCheck {
+	lastUpdated, err := s.especificacaoRepo.LastUpdatedAt(ctx)
+	if err != nil {
+		return falhaVerificacao("scraper_last_run", err)
+	}
+	if lastUpdated == nil {
+		check := novaVerificacao("scraper_last_run", nil, model.SaudeCritical)
+		check.Message = "nenhuma especificacao gravada"
+		return check
+	}
+
+	idade := time.Since(*lastUpdated)
+	check := novaVerificacao("scraper_last_run", lastUpdated.Format(time.RFC3339),
+		nivel(float64(idade), float64(s.limites.ScraperAgeWarning), float64(s.limites.ScraperAgeCritical)))
+	check.Message = fmt.Sprintf("ultima especificacao gravada ha %s", idade.Round(time.Minute))
+	return check
+}
+
+// verificarFalhasPendentes conta as falhas do scraper ainda nao resolvidas
+func (s *SaudeService) verificarFalhasPendentes(ctx context.Context) model.HealthCheck {
+	pendentes, err := s.falhaRepo.CountPending(ctx)
+	if err != nil {
+		return falhaVerificacao("pending_failures", err)
+	}
+	return novaVerificacao("pending_failures", pendentes,
+		nivel(float64(pendentes), float64(s.limites.PendingFailuresWarning), float64(s.limites.PendingFailuresCritical)))
+}
+
+// verificarProvedores conta os erros recentes de cada provedor externo (SLA)
+func (s *SaudeService) verificarProvedores(ctx context.Context) []model.HealthCheck {
+	counts, err := s.falhaRepo.CountRecentByType(ctx, providerErrorPeriod)
+
+	checks := make([]model.HealthCheck, 0, len(providerErrorTypes))
+	for _, p := range providerErrorTypes {
+		name := "provider_" + p.provider
+		if err != nil {
+			checks = append(checks, falhaVerificacao(name, err))
+			continue
+		}
+
+		erros := 0
+		for _, tipo := range p.tipos {
+			erros += counts[tipo]
+		}
+		check := novaVerificacao(name, erros,
+			nivel(float64(erros), float64(s.limites.ProviderErrorsWarning), float64(s.limites.ProviderErrorsCritical)))
+		check.Message = fmt.Sprintf("erros nas ultimas %.0fh", providerErrorPeriod.Hours())
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// verificarChavesLLM le o estado do pool de chaves do LLM no scraper: critical
+// quando todas as chaves estao esgotadas no dia, warning quando alguma esta
+// esgotada ou nenhuma esta livre do limite por minuto. O scraper fora do ar
+// (nenhuma execucao em andamento) nao e um problema e conta como ok.
+func (s *SaudeService) verificarChavesLLM(ctx context.Context) model.HealthCheck {
+	estado, err := s.estadoChavesLLM(ctx)
+	if err != nil {
+		check := novaVerificacao("llm_keys", nil, model.SaudeOK)
+		check.Message = "estado das chaves indisponivel: " + err.Error()
+		return check
+	}
+	if !estado.Configurado {
+		check := novaVerificacao("llm_keys", estado, model.SaudeOK)
+		check.Message = "provedor de LLM sem pool de chaves"
+		return check
+	}
+
+	status := model.SaudeOK
+	switch {
+	case estado.EsgotadasAte != nil || (estado.TotalChaves > 0 && estado.ChavesEsgotadasDia == estado.TotalChaves):
+		status = model.SaudeCritical
+	case estado.ChavesEsgotadasDia > 0 || estado.ChavesAtivas == 0:
+		status = model.SaudeWarning
+	}
+
+	check := novaVerificacao("llm_keys", estado, status)
+	check.Message = fmt.Sprintf("%d de %d chaves ativas, %d limitadas por minuto, %d esgotadas no dia",
+		estado.ChavesAtivas, estado.TotalChaves, estado.ChavesLimitadas, estado.ChavesEsgotadasDia)
+	if estado.EsgotadasAte != nil {
+		check.Message += "; todas esgotadas ate " + estado.EsgotadasAte.Format(time.RFC3339)
+	}
+	return check
+}
+
+// estadoChavesLLM consulta o /llm-status configurado
+func (s *SaudeService) estadoChavesLLM(ctx context.Context) (*model.EstadoChavesLLM, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.limites.LLMStatusURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("llm-status returned status %d", resp.StatusCode)
+	}
+
+	var estado model.EstadoChavesLLM
+	if err := json.NewDecoder(resp.Body).Decode(&estado); err != nil {
+		return nil, fmt.Errorf("failed to decode llm-status: %w", err)
+	}
+	return &estado, nil
+}
+
+// nivel classifica value pelos limites de warning e critical (limite <= 0 = desativado)
+func nivel(value, warning, critical float64) string {
+	switch {
+	case critical > 0 && value >= critical:
+		return model.SaudeCritical
+	case warning > 0 && value >= warning:
+		return model.SaudeWarning
+	default:
+		return model.SaudeOK
+	}
+}
+
+// novaVerificacao monta uma verificacao com o score correspondente ao status
+func novaVerificacao(name string, value any, status string) model.HealthCheck {
+	return model.HealthCheck{
+		Name:   name,
+		Status: status,
+		Score:  scoreStatus(status),
+		Value:  value,
+	}
+}
+
+// falhaVerificacao marca como critica uma verificacao que nao pode ser executada
+func falhaVerificacao(name string, err error) model.HealthCheck {
+	return model.HealthCheck{
+		Name:    name,
+		Status:  model.SaudeCritical,
+		Score:   0,
+		Message: err.Error(),
+	}
+}
+
+func scoreStatus(status string) int {
+	switch status {
+	case model.SaudeOK:
+		return 100
+	case model.SaudeWarning:
+		return 50
+	default:
+		return 0
+	}
+}
+
+func pesoStatus(status string) int {
+	switch status {
+	case model.SaudeOK:
+		return 0
+	case model.SaudeWarning:
+		return 1
+	default:
+		return 2
+	}
+}
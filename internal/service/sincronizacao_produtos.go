@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"wega-catalog-api/internal/config"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/telemetry"
+)
+
+// maxArquivoERP limita o arquivo de precos e estoque baixado do ERP
+const maxArquivoERP = 50 << 20
+
+// SincronizacaoStore aplica e registra as sincronizacoes de preco e estoque.
+// repository.ProdutoSincronizacaoRepo a implementa.
+type SincronizacaoStore interface {
+	Sincronizar(ctx context.Context, origem string, atualizacoes []model.AtualizacaoProduto) (*model.SincronizacaoProdutos, error)
+	RegistrarFalha(ctx context.Context, origem string, erro string) error
+}
+
+// SincronizacaoProdutos atualiza precos e disponibilidade dos produtos a
+// partir de um arquivo enviado ou baixado do ERP
+type SincronizacaoProdutos struct {
+	store      SincronizacaoStore
+	cfg        config.SincronizacaoERPConfig
+	httpClient *http.Client
+}
+
+func NewSincronizacaoProdutos(store SincronizacaoStore, cfg config.SincronizacaoERPConfig) *SincronizacaoProdutos {
+	return &SincronizacaoProdutos{
+		store:      store,
+		cfg:        cfg,
+		httpClient: telemetry.NewHTTPClient(cfg.Timeout),
+	}
+}
+
+// ERPConfigurado indica se ha ERP_SYNC_URL para buscar o arquivo
+func (s *SincronizacaoProdutos) ERPConfigurado() bool {
+	return s.cfg.URL != ""
+}
+
+// LerArquivo interpreta o arquivo de sincronizacao pelo Content-Type: JSON
+// com application/json, CSV nos demais casos
+func LerArquivo(contentType string, r io.Reader) ([]model.AtualizacaoProduto, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/json" {
+		return model.LerAtualizacoesJSON(r)
+	}
+	return model.LerAtualizacoesCSV(r)
+}
+
+// Importar aplica as atualizacoes; uma falha fica registrada no historico
+func (s *SincronizacaoProdutos) Importar(ctx context.Context, origem string, atualizacoes []model.AtualizacaoProduto) (*model.SincronizacaoProdutos, error) {
+	sincronizacao, err := s.store.Sincronizar(ctx, origem, atualizacoes)
+	if err != nil {
+		s.registrarFalha(ctx, origem, err)
+		return nil, err
+	}
+
+	slog.Info("precos e estoque sincronizados",
+		"id", sincronizacao.ID,
+		"origem", origem,
+		"linhas", sincronizacao.Linhas,
+		"alterados", sincronizacao.Alterados,
+		"nao_encontrados", len(sincronizacao.NaoEncontrados),
+	)
+	return sincronizacao, nil
+}
+
+// SincronizarERP baixa o arquivo de ERP_SYNC_URL e o aplica. Feito para rodar
+// no JobRunner e no POST /admin/produtos/sincronizar/erp.
+func (s *SincronizacaoProdutos) SincronizarERP(ctx context.Context) (*model.SincronizacaoProdutos, error) {
+	atualizacoes, err := s.baixarERP(ctx)
+	if err != nil {
+		s.registrarFalha(ctx, model.SincronizacaoERP, err)
+		return nil, err
+	}
+	return s.Importar(ctx, model.SincronizacaoERP, atualizacoes)
+}
+
+// baixarERP busca e interpreta o arquivo do ERP
+func (s *SincronizacaoProdutos) baixarERP(ctx context.Context) ([]model.AtualizacaoProduto, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.cfg.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar requisicao ao ERP: %w", err)
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar o ERP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ERP respondeu status %d", resp.StatusCode)
+	}
+
+	// le um byte alem do limite para detectar arquivo cortado em vez de
+	// interpretar a ultima linha truncada como registro valido
+	corpo, err := io.ReadAll(io.LimitReader(resp.Body, maxArquivoERP+1))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler arquivo do ERP: %w", err)
+	}
+	if len(corpo) > maxArquivoERP {
+		return nil, fmt.Errorf("arquivo do ERP acima do limite de %d bytes", maxArquivoERP)
+	}
+
+	atualizacoes, err := LerArquivo(resp.Header.Get("Content-Type"), bytes.NewReader(corpo))
+	if err != nil {
+		return nil, fmt.Errorf("arquivo do ERP invalido: %w", err)
+	}
+	return atualizacoes, nil
+}
+
+// registrarFalha grava a falha no historico; um erro aqui so e logado
+func (s *SincronizacaoProdutos) registrarFalha(ctx context.Context, origem string, causa error) {
+	slog.Error("falha na sincronizacao de precos e estoque", "origem", origem, "error", causa)
+	if err := s.store.RegistrarFalha(context.WithoutCancel(ctx), origem, causa.Error()); err != nil {
+		slog.Warn("falha ao registrar sincronizacao com erro", "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package motulmatch
+
+import (
+	"cmp"
+	"slices"
+
+	"wega-catalog-api/internal/model"
+)
+
+// Aliases returns the brand and model mappings the matcher has resolved so
+// far (or loaded with LoadAliases), sorted by type, brand and Wega name
+func (m *Matcher) Aliases() []model.AliasVeiculo {
+	var aliases []model.AliasVeiculo
+
+	m.brandCache.Range(func(key, value any) bool {
+		aliases = append(aliases, model.AliasVeiculo{
+			Tipo:    model.AliasMarca,
+			Origem:  key.(string),
+			Destino: value.(string),
+			Fonte:   model.AliasAprendido,
+		})
+		return true
+	})
+	m.modelCache.Range(func(key, value any) bool {
+		k := key.(modelKey)
+		aliases = append(aliases, model.AliasVeiculo{
+			Tipo:    model.AliasModelo,
+			Marca:   k.motulBrand,
+			Origem:  k.wegaModel,
+			Destino: value.(string),
+			Fonte:   model.AliasAprendido,
+		})
+		return true
+	})
+
+	slices.SortFunc(aliases, func(a, b model.AliasVeiculo) int {
+		return cmp.Or(
+			cmp.Compare(a.Tipo, b.Tipo),
+			cmp.Compare(a.Marca, b.Marca),
+			cmp.Compare(a.Origem, b.Origem),
+		)
+	})
+	return aliases
+}
+
+// LoadAliases seeds the matcher with known brand and model mappings, which then
+// take precedence over every pipeline strategy. Mappings to brands or models
+// missing from the matcher's catalog (e.g. another category) are skipped.
+// It returns how many were loaded.
+func (m *Matcher) LoadAliases(aliases []model.AliasVeiculo) int {
+	loaded := 0
+	for _, a := range aliases {
+		if a.Destino == "" {
+			continue
+		}
+
+		switch a.Tipo {
+		case model.AliasMarca:
+			brand := m.catalog.FindBrand(a.Destino)
+			if brand == nil {
+				continue
+			}
+			m.brandCache.Store(a.Origem, brand.Name)
+			loaded++
+
+		case model.AliasModelo:
+			brand := m.catalog.FindBrand(a.Marca)
+			if brand == nil || !slices.Contains(m.catalog.GetModelNames(brand.Name), a.Destino) {
+				continue
+			}
+			m.modelCache.Store(modelKey{motulBrand: brand.Name, wegaModel: a.Origem}, a.Destino)
+			loaded++
+		}
+	}
+	return loaded
+}
@@ -0,0 +1,196 @@
+package motulmatch
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"wega-catalog-api/internal/client"
+)
+
+// DefaultBatchWait is how long a type prompt waits for others to share its batch
+const DefaultBatchWait = time.Second
+
+// TypeBatcher collects the type prompts of concurrent matches and sends them
+// to the LLM in one batched call, so the instructions and reply format are
+// paid once per batch instead of once per vehicle. A batch is sent when it is
+// full or when its oldest prompt has waited maxWait.
+// It is safe for concurrent use.
+type TypeBatcher struct {
+	llm     client.BatchNormalizer
+	size    int
+	maxWait time.Duration
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	pending []*batchCall
+	timer   *time.Timer
+
+	batches  atomic.Int64
+	requests atomic.Int64
+}
+
+// batchCall is one type prompt waiting for its batch
+type batchCall struct {
+	ctx     context.Context
+	request client.BatchMatchRequest
+	done    chan batchAnswer // Buffered: the caller may have given up
+}
+
+type batchAnswer struct {
+	value      string
+	confidence float64
+	err        error
+}
+
+// NewTypeBatcher creates a batcher sending up to size prompts per call.
+// maxWait <= 0 uses DefaultBatchWait. A nil logger uses slog.Default().
+func NewTypeBatcher(llm client.BatchNormalizer, size int, maxWait time.Duration, logger *slog.Logger) *TypeBatcher {
+	if size < 1 {
+		size = 1
+	}
+	if maxWait <= 0 {
+		maxWait = DefaultBatchWait
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TypeBatcher{llm: llm, size: size, maxWait: maxWait, logger: logger}
+}
+
+// NormalizeVehicle queues the prompt and blocks until its batch is answered
+func (b *TypeBatcher) NormalizeVehicle(ctx context.Context, vehicle string, options []string) (string, error) {
+	value, _, err := b.NormalizeVehicleConfidence(ctx, vehicle, options)
+	return value, err
+}
+
+// NormalizeVehicleConfidence is NormalizeVehicle plus the model's confidence
+// in the answer (0 = not reported)
+func (b *TypeBatcher) NormalizeVehicleConfidence(ctx context.Context, vehicle string, options []string) (string, float64, error) {
+	if len(options) == 0 {
+		return "", 0, errors.New("no options provided")
+	}
+	call := &batchCall{
+		ctx:     ctx,
+		request: client.BatchMatchRequest{Vehicle: vehicle, Options: options},
+		done:    make(chan batchAnswer, 1),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, call)
+	var full []*batchCall
+	if len(b.pending) >= b.size {
+		full = b.take()
+	} else if len(b.pending) == 1 {
+		b.timer = time.AfterFunc(b.maxWait, b.flushPending)
+	}
+	b.mu.Unlock()
+
+	if full != nil {
+		b.send(full)
+	}
+
+	select {
+	case answer := <-call.done:
+		return answer.value, answer.confidence, answer.err
+	case <-ctx.Done():
+		return "", 0, ctx.Err()
+	}
+}
+
+// Stats returns the number of batched calls sent and the prompts they carried
+func (b *TypeBatcher) Stats() (batches, requests int64) {
+	return b.batches.Load(), b.requests.Load()
+}
+
+// take removes the pending prompts; b.mu must be held
+func (b *TypeBatcher) take() []*batchCall {
+	calls := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return calls
+}
+
+// flushPending sends whatever is pending when the oldest prompt times out
+func (b *TypeBatcher) flushPending() {
+	b.mu.Lock()
+	calls := b.take()
+	b.mu.Unlock()
+	if len(calls) > 0 {
+		b.send(calls)
+	}
+}
+
+// send makes one batched LLM call and hands each caller its answer. Prompts
+// whose caller already gave up are dropped first.
+func (b *TypeBatcher) send(calls []*batchCall) {
+	live := calls[:0]
+	for _, call := range calls {
+		if call.ctx.Err() == nil {
+			live = append(live, call)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	requests := make([]client.BatchMatchRequest, len(live))
+	for i, call := range live {
+		requests[i] = call.request
+		requests[i].ID = i
+	}
+
+	b.batches.Add(1)
+	b.requests.Add(int64(len(live)))
+	b.logger.Debug("sending batched type prompts", "prompts", len(live))
+
+	// The batch runs on behalf of all its callers: it is only cancelled when
+	// every one of them has gone away
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for _, call := range live {
+			select {
+			case <-call.ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	results, err := b.llm.NormalizeVehicleBatch(ctx, requests)
+	if err != nil {
+		for _, call := range live {
+			call.done <- batchAnswer{err: err}
+		}
+		return
+	}
+
+	answered := make([]bool, len(live))
+	for _, r := range results {
+		if r.ID < 0 || r.ID >= len(live) || answered[r.ID] {
+			continue
+		}
+		answered[r.ID] = true
+		switch {
+		case r.Error != nil:
+			live[r.ID].done <- batchAnswer{err: r.Error}
+		case r.MatchedIndex < 0 || r.MatchedIndex >= len(requests[r.ID].Options):
+			live[r.ID].done <- batchAnswer{err: client.ErrNoMatch}
+		default:
+			live[r.ID].done <- batchAnswer{value: requests[r.ID].Options[r.MatchedIndex], confidence: r.Confidence}
+		}
+	}
+	for i, call := range live {
+		if !answered[i] {
+			call.done <- batchAnswer{err: errors.New("no answer in batch response")}
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package scraper
+package motulmatch
 
 import (
 	"context"
@@ -10,21 +10,54 @@ import (
 	"time"
 
 	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/normalize"
 )
 
 // MotulCatalog holds the complete Motul catalog data
 type MotulCatalog struct {
-	LoadedAt time.Time                       `json:"loaded_at"`
-	Brands   []CatalogBrand                  `json:"brands"`
-	BrandMap map[string]*CatalogBrand        `json:"-"` // brand name (normalized) -> brand
-	ModelMap map[string][]CatalogVehicleType `json:"-"` // brandID:modelID -> types
+	LoadedAt   time.Time                       `json:"loaded_at"`
+	Categories []string                        `json:"categories,omitempty"` // Vehicle categories fetched (empty = cars only)
+	Brands     []CatalogBrand                  `json:"brands"`
+	BrandMap   map[string]*CatalogBrand        `json:"-"` // brand name (normalized) -> brand, cars first
+	ModelMap   map[string][]CatalogVehicleType `json:"-"` // brandID:modelID -> types
+	TypeMap    map[string]CatalogVehicleType   `json:"-"` // type ID -> type
 }
 
 // CatalogBrand represents a brand with its models
 type CatalogBrand struct {
-	ID     string         `json:"id"`
-	Name   string         `json:"name"`
-	Models []CatalogModel `json:"models"`
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Category string         `json:"category,omitempty"` // Motul vehicle category (empty = client.CategoryCar)
+	Models   []CatalogModel `json:"models"`
+}
+
+// VehicleCategory returns the brand's Motul vehicle category
+func (b *CatalogBrand) VehicleCategory() string {
+	if b.Category == "" {
+		return client.CategoryCar
+	}
+	return b.Category
+}
+
+// hasCategories reports whether the catalog was fetched with every category in categories
+func (c *MotulCatalog) hasCategories(categories []string) bool {
+	fetched := c.Categories
+	if len(fetched) == 0 {
+		fetched = []string{client.CategoryCar}
+	}
+	for _, category := range categories {
+		found := false
+		for _, f := range fetched {
+			if f == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 // CatalogModel represents a model with its vehicle types
@@ -47,16 +80,69 @@ type CatalogVehicleType struct {
 type CatalogLoader struct {
 	motulClient *client.MotulClient
 	logger      *slog.Logger
+	categories  []string // Vehicle categories to fetch
 	catalog     *MotulCatalog
 	mu          sync.RWMutex
 }
 
-// NewCatalogLoader creates a new catalog loader
+// NewCatalogLoader creates a new catalog loader that fetches from the Motul API
+// when the cache file is missing or stale
 func NewCatalogLoader(motulClient *client.MotulClient, logger *slog.Logger) *CatalogLoader {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &CatalogLoader{
 		motulClient: motulClient,
 		logger:      logger,
+		categories:  []string{client.CategoryCar},
+	}
+}
+
+// SetCategories sets the Motul vehicle categories to load (default: cars only).
+// A cache file missing any of them is refetched.
+func (l *CatalogLoader) SetCategories(categories []string) {
+	l.categories = categories
+}
+
+// ForCategory returns a loader restricted to the brands of one vehicle category,
+// so a matcher built on it never picks e.g. a car model for a motorcycle
+func (l *CatalogLoader) ForCategory(category string) *CatalogLoader {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	view := &MotulCatalog{Categories: []string{category}}
+	if l.catalog != nil {
+		view.LoadedAt = l.catalog.LoadedAt
+		for _, brand := range l.catalog.Brands {
+			if brand.VehicleCategory() == category {
+				view.Brands = append(view.Brands, brand)
+			}
+		}
+	}
+	return NewStaticCatalogLoader(view, l.logger)
+}
+
+// NewStaticCatalogLoader wraps an already loaded catalog (e.g. from LoadCatalogFile)
+// without any Motul API access
+func NewStaticCatalogLoader(catalog *MotulCatalog, logger *slog.Logger) *CatalogLoader {
+	l := NewCatalogLoader(nil, logger)
+	l.catalog = catalog
+	l.buildIndexes()
+	return l
+}
+
+// LoadCatalogFile reads a catalog cache file regardless of its age
+func LoadCatalogFile(filename string) (*MotulCatalog, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog MotulCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
 	}
+	return &catalog, nil
 }
 
 // LoadOrFetch loads catalog from file or fetches from API
@@ -73,6 +159,10 @@ func (l *CatalogLoader) LoadOrFetch(ctx context.Context, cacheFile string) (*Mot
 		return catalog, nil
 	}
 
+	if l.motulClient == nil {
+		return nil, fmt.Errorf("no usable catalog cache at %s and no Motul client to fetch one", cacheFile)
+	}
+
 	// Fetch from API
 	l.logger.Info("fetching Motul catalog from API (this may take a few minutes)...")
 	catalog, err := l.fetchFromAPI(ctx)
@@ -101,22 +191,21 @@ func (l *CatalogLoader) GetCatalog() *MotulCatalog {
 
 // loadFromFile loads catalog from JSON file
 func (l *CatalogLoader) loadFromFile(filename string) (*MotulCatalog, error) {
-	data, err := os.ReadFile(filename)
+	catalog, err := LoadCatalogFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	var catalog MotulCatalog
-	if err := json.Unmarshal(data, &catalog); err != nil {
-		return nil, err
-	}
-
 	// Check if cache is too old (older than 7 days)
 	if time.Since(catalog.LoadedAt) > 7*24*time.Hour {
 		return nil, fmt.Errorf("cache is too old")
 	}
 
-	return &catalog, nil
+	if !catalog.hasCategories(l.categories) {
+		return nil, fmt.Errorf("cache does not cover categories %v", l.categories)
+	}
+
+	return catalog, nil
 }
 
 // saveToFile saves catalog to JSON file
@@ -131,17 +220,28 @@ func (l *CatalogLoader) saveToFile(filename string, catalog *MotulCatalog) error
 // fetchFromAPI fetches complete catalog from Motul API
 func (l *CatalogLoader) fetchFromAPI(ctx context.Context) (*MotulCatalog, error) {
 	catalog := &MotulCatalog{
-		LoadedAt: time.Now(),
-		Brands:   []CatalogBrand{},
+		LoadedAt:   time.Now(),
+		Categories: l.categories,
+		Brands:     []CatalogBrand{},
 	}
 
-	// 1. Get all brands
-	l.logger.Info("fetching brands...")
-	brands, err := l.motulClient.GetBrands(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get brands: %w", err)
+	// 1. Get all brands of each category
+	type categoryBrand struct {
+		client.Brand
+		category string
+	}
+	var brands []categoryBrand
+	for _, category := range l.categories {
+		l.logger.Info("fetching brands...", "category", category)
+		categoryBrands, err := l.motulClient.GetBrandsByCategory(ctx, category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s brands: %w", category, err)
+		}
+		for _, brand := range categoryBrands {
+			brands = append(brands, categoryBrand{Brand: brand, category: category})
+		}
+		l.logger.Info("fetched brands", "category", category, "count", len(categoryBrands))
 	}
-	l.logger.Info("fetched brands", "count", len(brands))
 
 	// 2. For each brand, get models
 	for i, brand := range brands {
@@ -152,9 +252,10 @@ func (l *CatalogLoader) fetchFromAPI(ctx context.Context) (*MotulCatalog, error)
 		}
 
 		catalogBrand := CatalogBrand{
-			ID:     brand.ID,
-			Name:   brand.Name,
-			Models: []CatalogModel{},
+			ID:       brand.ID,
+			Name:     brand.Name,
+			Category: brand.category,
+			Models:   []CatalogModel{},
 		}
 
 		l.logger.Debug("fetching models for brand",
@@ -254,17 +355,24 @@ func (l *CatalogLoader) buildIndexes() {
 
 	l.catalog.BrandMap = make(map[string]*CatalogBrand)
 	l.catalog.ModelMap = make(map[string][]CatalogVehicleType)
+	l.catalog.TypeMap = make(map[string]CatalogVehicleType)
 
 	for i := range l.catalog.Brands {
 		brand := &l.catalog.Brands[i]
-		// Index by normalized name
-		normalizedName := normalizeString(brand.Name)
-		l.catalog.BrandMap[normalizedName] = brand
+		// Index by normalized name; a name shared across categories (Honda, BMW)
+		// keeps the first brand, which is the car one
+		normalizedName := normalize.Key.Apply(brand.Name)
+		if _, ok := l.catalog.BrandMap[normalizedName]; !ok {
+			l.catalog.BrandMap[normalizedName] = brand
+		}
 
 		for j := range brand.Models {
 			model := &brand.Models[j]
 			key := fmt.Sprintf("%s:%s", brand.ID, model.ID)
 			l.catalog.ModelMap[key] = model.Types
+			for _, vt := range model.Types {
+				l.catalog.TypeMap[vt.ID] = vt
+			}
 		}
 	}
 }
@@ -294,7 +402,7 @@ func (l *CatalogLoader) GetModelNames(brandName string) []string {
 		return nil
 	}
 
-	normalized := normalizeString(brandName)
+	normalized := normalize.Key.Apply(brandName)
 	brand, ok := l.catalog.BrandMap[normalized]
 	if !ok {
 		return nil
@@ -316,16 +424,16 @@ func (l *CatalogLoader) GetVehicleTypes(brandName, modelName string) []CatalogVe
 		return nil
 	}
 
-	normalized := normalizeString(brandName)
+	normalized := normalize.Key.Apply(brandName)
 	brand, ok := l.catalog.BrandMap[normalized]
 	if !ok {
 		return nil
 	}
 
 	// Find model
-	normalizedModel := normalizeString(modelName)
+	normalizedModel := normalize.Key.Apply(modelName)
 	for _, model := range brand.Models {
-		if normalizeString(model.Name) == normalizedModel {
+		if normalize.Key.Apply(model.Name) == normalizedModel {
 			return model.Types
 		}
 	}
@@ -333,8 +441,9 @@ func (l *CatalogLoader) GetVehicleTypes(brandName, modelName string) []CatalogVe
 	return nil
 }
 
-// FindBrand finds a brand by name (case-insensitive)
-func (l *CatalogLoader) FindBrand(brandName string) *CatalogBrand {
+// FindVehicleType returns the vehicle type with a Motul type ID (nil if the
+// catalog does not have it)
+func (l *CatalogLoader) FindVehicleType(id string) *CatalogVehicleType {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
@@ -342,22 +451,22 @@ func (l *CatalogLoader) FindBrand(brandName string) *CatalogBrand {
 		return nil
 	}
 
-	normalized := normalizeString(brandName)
-	return l.catalog.BrandMap[normalized]
+	vt, ok := l.catalog.TypeMap[id]
+	if !ok {
+		return nil
+	}
+	return &vt
 }
 
-// normalizeString normalizes a string for comparison
-func normalizeString(s string) string {
-	// Simple normalization for map keys
-	result := make([]byte, 0, len(s))
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c >= 'A' && c <= 'Z' {
-			c = c + 32 // toLower
-		}
-		if c != ' ' {
-			result = append(result, c)
-		}
+// FindBrand finds a brand by name (case-insensitive)
+func (l *CatalogLoader) FindBrand(brandName string) *CatalogBrand {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.catalog == nil {
+		return nil
 	}
-	return string(result)
+
+	normalized := normalize.Key.Apply(brandName)
+	return l.catalog.BrandMap[normalized]
 }
@@ -0,0 +1,22 @@
+// Package motulmatch matches Wega vehicles (brand, model, description, year)
+// to Motul catalog vehicle types.
+//
+// It combines a pre-loaded Motul catalog with cheap exact/partial matching and
+// falls back to an LLM only when several candidates remain. The scraper uses it
+// internally, and other services can embed it without running the scraper:
+//
+//	catalog, err := motulmatch.LoadCatalogFile("motul_catalog.json")
+//	if err != nil {
+//		return err
+//	}
+//	matcher := motulmatch.New(motulmatch.NewStaticCatalogLoader(catalog, logger), llm, logger)
+//	result, err := matcher.FindMatch(ctx, "Volkswagen", "Gol", "1.0 12V Total Flex", 2020)
+//
+// Use NewCatalogLoader with a Motul client instead when the catalog should be
+// fetched from the API and cached on disk.
+//
+// The strategies tried at each stage and their thresholds are configurable:
+//
+//	pipeline, err := motulmatch.ParsePipeline("exact,alias,similarity:0.9,rules,llm")
+//	matcher.SetPipeline(pipeline)
+package motulmatch
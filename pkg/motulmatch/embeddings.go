@@ -0,0 +1,196 @@
+package motulmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// embeddingBatchSize is how many catalog vehicle types are embedded per request
+const embeddingBatchSize = 64
+
+// Embedder computes vector embeddings for texts.
+// client.OllamaEmbedder and client.OpenAIEmbedder both satisfy it.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Model() string
+}
+
+// EmbeddingIndex holds one normalized embedding per catalog vehicle type,
+// kept in memory and cached on disk
+type EmbeddingIndex struct {
+	embedder Embedder
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	vectors map[string][]float32 // vehicle type ID -> unit vector
+}
+
+// embeddingCache is the on-disk format of an EmbeddingIndex
+type embeddingCache struct {
+	Model   string               `json:"model"`
+	BuiltAt time.Time            `json:"built_at"`
+	Vectors map[string][]float32 `json:"vectors"`
+}
+
+// NewEmbeddingIndex creates an empty index using embedder for queries and builds
+func NewEmbeddingIndex(embedder Embedder, logger *slog.Logger) *EmbeddingIndex {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &EmbeddingIndex{
+		embedder: embedder,
+		logger:   logger,
+		vectors:  make(map[string][]float32),
+	}
+}
+
+// LoadOrBuild loads vectors from cacheFile (when built with the same model) and
+// embeds any catalog vehicle types still missing, then saves the cache
+func (x *EmbeddingIndex) LoadOrBuild(ctx context.Context, catalog *CatalogLoader, cacheFile string) error {
+	if data, err := os.ReadFile(cacheFile); err == nil {
+		var cache embeddingCache
+		if err := json.Unmarshal(data, &cache); err == nil && cache.Model == x.embedder.Model() {
+			x.mu.Lock()
+			x.vectors = cache.Vectors
+			x.mu.Unlock()
+			x.logger.Info("loaded embedding cache", "file", cacheFile, "vectors", len(cache.Vectors))
+		}
+	}
+
+	mc := catalog.GetCatalog()
+	if mc == nil {
+		return fmt.Errorf("catalog not loaded")
+	}
+
+	// Collect vehicle types without a vector
+	var ids, texts []string
+	x.mu.RLock()
+	for _, brand := range mc.Brands {
+		for _, model := range brand.Models {
+			for _, vt := range model.Types {
+				if _, ok := x.vectors[vt.ID]; !ok {
+					ids = append(ids, vt.ID)
+					texts = append(texts, vt.FullPath)
+				}
+			}
+		}
+	}
+	x.mu.RUnlock()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	x.logger.Info("embedding catalog vehicle types", "count", len(ids), "model", x.embedder.Model())
+
+	for start := 0; start < len(ids); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(ids))
+		vectors, err := x.embedder.Embed(ctx, texts[start:end])
+		if err != nil {
+			// Keep what we have so a re-run resumes instead of starting over
+			x.save(cacheFile)
+			return fmt.Errorf("failed to embed catalog: %w", err)
+		}
+
+		x.mu.Lock()
+		for i, v := range vectors {
+			x.vectors[ids[start+i]] = normalizeVector(v)
+		}
+		x.mu.Unlock()
+	}
+
+	if err := x.save(cacheFile); err != nil {
+		x.logger.Warn("failed to save embedding cache", "error", err)
+	}
+	return nil
+}
+
+// save writes the current vectors to cacheFile
+func (x *EmbeddingIndex) save(cacheFile string) error {
+	x.mu.RLock()
+	data, err := json.Marshal(embeddingCache{
+		Model:   x.embedder.Model(),
+		BuiltAt: time.Now(),
+		Vectors: x.vectors,
+	})
+	x.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, data, 0644)
+}
+
+// Rank returns the best and second-best cosine similarity of text among
+// types. With a single candidate the second-best score is 0, so the margin
+// is the best score itself rather than being inflated by a sentinel.
+func (x *EmbeddingIndex) Rank(ctx context.Context, text string, types []CatalogVehicleType) (best CatalogVehicleType, bestScore, secondScore float64, err error) {
+	vectors, err := x.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return best, 0, 0, err
+	}
+	query := normalizeVector(vectors[0])
+
+	bestScore, secondScore = math.Inf(-1), math.Inf(-1)
+	ranked := 0
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	for _, vt := range types {
+		v, ok := x.vectors[vt.ID]
+		if !ok {
+			continue
+		}
+		ranked++
+		score := dot(query, v)
+		if score > bestScore {
+			secondScore = bestScore
+			bestScore = score
+			best = vt
+		} else if score > secondScore {
+			secondScore = score
+		}
+	}
+
+	if ranked == 0 {
+		return best, 0, 0, fmt.Errorf("no embeddings for candidate types")
+	}
+	if ranked == 1 {
+		secondScore = 0
+	}
+	return best, bestScore, secondScore, nil
+}
+
+// normalizeVector scales v to unit length so cosine similarity is a dot product
+func normalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	norm := math.Sqrt(sum)
+	if norm == 0 {
+		return v
+	}
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(float64(f) / norm)
+	}
+	return out
+}
+
+// dot returns the dot product of two equally sized vectors
+func dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return -1
+	}
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
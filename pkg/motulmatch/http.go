@@ -0,0 +1,90 @@
+package motulmatch
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// MatchRequest is the body of POST /match
+type MatchRequest struct {
+	Brand       string `json:"brand"`
+	Model       string `json:"model"`
+	Description string `json:"description"`
+	Year        int    `json:"year,omitempty"`
+}
+
+// errorResponse is the error body returned by Handler
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// Handler exposes the matcher over HTTP (match-as-a-service):
+//
+//	POST /match   {brand, model, description, year} -> MatchResult
+//	GET  /health
+//
+// Nothing is persisted; it only runs the matching pipeline.
+func Handler(m *Matcher) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	mux.HandleFunc("/match", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{
+				Error:   "method_not_allowed",
+				Message: "use POST",
+			})
+			return
+		}
+
+		var req MatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error:   "invalid_request",
+				Message: "invalid JSON body",
+			})
+			return
+		}
+
+		req.Brand = strings.TrimSpace(req.Brand)
+		req.Model = strings.TrimSpace(req.Model)
+		if req.Brand == "" || req.Model == "" {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error:   "invalid_request",
+				Message: "brand and model are required",
+			})
+			return
+		}
+
+		description := req.Description
+		if description == "" {
+			description = req.Model
+		}
+
+		result, err := m.FindMatch(r.Context(), req.Brand, req.Model, description, req.Year)
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse{
+				Error:   "no_match",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, result)
+	})
+
+	return mux
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
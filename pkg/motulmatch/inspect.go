@@ -0,0 +1,94 @@
+package motulmatch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"wega-catalog-api/internal/matching"
+)
+
+// Candidate is a vehicle type of the matched model with its deterministic
+// feature score
+type Candidate struct {
+	VehicleType CatalogVehicleType
+	Score       matching.MatchScore
+	Agree       int // Engine features that agree with the description
+	Conflicts   int // Engine features that clearly differ
+}
+
+// Inspection explains how a vehicle is matched: the candidates, what the
+// rules strategy and the LLM would pick and what the pipeline returns
+type Inspection struct {
+	MotulBrand      string
+	MotulModel      string
+	Prompt          string // Description sent to the LLM (compacted when too long)
+	PromptTruncated bool
+	Candidates      []Candidate  // Best feature score first
+	Rules           *MatchResult // Clear winner by feature score (nil if none clears the threshold or the best is tied)
+	LLMPick         *MatchResult // LLM choice among the candidates (nil without an llm step or with a single candidate)
+	Result          *MatchResult // What FindMatch returns (nil when no strategy matched)
+	ResultErr       error
+}
+
+// Inspect runs the match like FindMatch and also reports the candidate types,
+// their feature scores and the picks of the rules strategy and the LLM, even
+// when an earlier strategy decides. Brand and model errors are returned as by
+// FindMatch. Only the brand and model caches are written.
+func (m *Matcher) Inspect(ctx context.Context, wegaBrand, wegaModel, wegaDescription string, year int) (*Inspection, error) {
+	motulBrand, err := m.matchBrand(ctx, wegaBrand)
+	if err != nil {
+		return nil, fmt.Errorf("brand not found: %w", err)
+	}
+	motulModel, err := m.matchModel(ctx, motulBrand, wegaModel)
+	if err != nil {
+		return &Inspection{MotulBrand: motulBrand}, fmt.Errorf("model not found: %w", err)
+	}
+
+	in := &Inspection{MotulBrand: motulBrand, MotulModel: motulModel}
+	in.Prompt, in.PromptTruncated = CompactDescription(typeDescription(wegaBrand, wegaModel, wegaDescription, year), m.maxPromptDescription)
+
+	types := m.catalog.GetVehicleTypes(motulBrand, motulModel)
+	scorer := matching.NewVehicleMatcher(0)
+	wega := matching.ExtractFeatures(wegaDescription, year)
+	for _, vt := range types {
+		motul := matching.ExtractFeatures(vt.Name, year)
+		agree, conflicts := matching.CompareFeatures(wega, motul)
+		in.Candidates = append(in.Candidates, Candidate{
+			VehicleType: vt,
+			Score:       scorer.Score(wega, motul),
+			Agree:       agree,
+			Conflicts:   conflicts,
+		})
+	}
+	sort.SliceStable(in.Candidates, func(i, j int) bool {
+		return in.Candidates[i].Score.Total > in.Candidates[j].Score.Total
+	})
+
+	in.Result, in.ResultErr = m.matchType(ctx, motulBrand, motulModel, wegaBrand, wegaModel, wegaDescription, year)
+	if len(types) < 2 {
+		return in, nil
+	}
+
+	rules := m.minConfidence
+	hasLLM := false
+	for _, step := range m.pipeline {
+		switch step.Strategy {
+		case StrategyRules:
+			rules = step.threshold(m.minConfidence)
+		case StrategyLLM:
+			hasLLM = true
+		}
+	}
+	in.Rules = m.matchByFeatures(wegaDescription, year, types, rules)
+
+	if hasLLM {
+		// Reuse the pipeline's LLM call when it got that far
+		if in.Result != nil && (in.Result.MatchMethod == "llm" || in.Result.MatchMethod == "fallback") {
+			in.LLMPick = in.Result
+		} else {
+			in.LLMPick = m.matchTypeLLM(ctx, in.Prompt, types)
+		}
+	}
+	return in, nil
+}
@@ -0,0 +1,585 @@
+package motulmatch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wega-catalog-api/internal/client"
+	"wega-catalog-api/internal/matching"
+	"wega-catalog-api/internal/model"
+	"wega-catalog-api/internal/normalize"
+)
+
+// DefaultMinConfidence is the feature-score confidence a deterministic match needs to skip the LLM
+const DefaultMinConfidence = 0.80
+
+// DefaultModelSimilarity is the Jaro-Winkler similarity a model name needs to skip the LLM
+const DefaultModelSimilarity = 0.92
+
+// brandCategorySuffixes are appended to brand names in the Wega catalog to
+// tell a manufacturer's vehicle lines apart (e.g. "HONDA MOTOS")
+var brandCategorySuffixes = []string{" motos", " moto", " motorrad", " motorcycles", " trucks", " caminhoes", " onibus", " agricola"}
+
+// LLM is the language model used to disambiguate brands, models and vehicle types.
+// client.GroqClient and client.OllamaClient both satisfy it.
+type LLM interface {
+	// NormalizeVehicle finds the best match from options for a vehicle
+	NormalizeVehicle(ctx context.Context, vehicle string, options []string) (string, error)
+
+	// FindBestBrand finds the best matching brand from available options
+	FindBestBrand(ctx context.Context, brand string, options []string) (string, error)
+
+	// FindBestModel finds the best matching model from available options
+	FindBestModel(ctx context.Context, model string, options []string) (string, error)
+}
+
+// Matcher uses pre-loaded catalog and LLM for intelligent matching
+// It is safe for concurrent use.
+type Matcher struct {
+	catalog *CatalogLoader
+	llm     LLM
+	logger  *slog.Logger
+
+	// Ordered match strategies (see SetPipeline)
+	pipeline Pipeline
+
+	// Feature-score confidence for the rules strategy
+	minConfidence float64
+
+	// Edit-distance threshold for model names (0 = disabled)
+	modelSimilarity float64
+
+	// Longest description sent to the LLM unchanged (0 = never compact)
+	maxPromptDescription int
+
+	// Optional batching of LLM type prompts (see SetTypeBatcher)
+	typeBatcher *TypeBatcher
+
+	// Optional embedding pre-filter (see SetEmbeddingIndex)
+	embeddings        *EmbeddingIndex
+	embeddingMinScore float64
+	embeddingMargin   float64
+
+	// Caches to avoid repeated LLM calls
+	brandCache sync.Map // wegaBrand -> motulBrandName
+	modelCache sync.Map // modelKey -> motulModelName
+}
+
+// modelKey identifies a Wega model within a Motul brand in the model cache
+type modelKey struct {
+	motulBrand string
+	wegaModel  string
+}
+
+// MatchResult represents a successful match
+type MatchResult struct {
+	VehicleType CatalogVehicleType `json:"vehicle_type"`
+	Confidence  float64            `json:"confidence"`
+	MatchMethod string             `json:"match_method"` // "single", "exact", "fuzzy", "embedding", "llm", "fallback"
+	MotulBrand  string             `json:"motul_brand"`
+	MotulModel  string             `json:"motul_model"`
+	// PromptTruncated is set when the LLM picked the type from a compacted
+	// description (see CompactDescription)
+	PromptTruncated bool         `json:"prompt_truncated,omitempty"`
+	Timings         MatchTimings `json:"-"`
+}
+
+// MatchTimings is the time FindMatch spent in each matching stage
+type MatchTimings struct {
+	Brand time.Duration
+	Model time.Duration
+	Type  time.Duration // Includes embedding and LLM calls
+}
+
+// New creates a matcher over a loaded catalog. A nil logger uses slog.Default().
+func New(catalog *CatalogLoader, llm LLM, logger *slog.Logger) *Matcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Matcher{
+		catalog:              catalog,
+		llm:                  llm,
+		logger:               logger,
+		pipeline:             DefaultPipeline,
+		minConfidence:        DefaultMinConfidence,
+		modelSimilarity:      DefaultModelSimilarity,
+		maxPromptDescription: DefaultMaxPromptDescription,
+	}
+}
+
+// SetMinConfidence sets the feature-score confidence (0.0-1.0) a deterministic
+// match needs to be accepted without the LLM
+func (m *Matcher) SetMinConfidence(minConfidence float64) {
+	m.minConfidence = minConfidence
+}
+
+// SetModelSimilarity sets the Jaro-Winkler similarity (0.0-1.0) a catalog model
+// name needs to match a Wega model without the LLM; 0 disables the pass
+func (m *Matcher) SetModelSimilarity(threshold float64) {
+	m.modelSimilarity = threshold
+}
+
+// SetMaxPromptDescription sets the longest description, in runes, sent to the
+// LLM unchanged; longer ones are compacted. 0 disables compaction.
+func (m *Matcher) SetMaxPromptDescription(maxLen int) {
+	m.maxPromptDescription = maxLen
+}
+
+// SetTypeBatcher sends the LLM type prompts through batcher, batched with
+// those of concurrent matches. Brand and model prompts are not batched: they
+// are cached and rare after the first vehicles of each brand.
+func (m *Matcher) SetTypeBatcher(batcher *TypeBatcher) {
+	m.typeBatcher = batcher
+}
+
+// SetEmbeddingIndex enables embedding-based matching before the LLM. A type is
+// accepted when its cosine similarity is at least minScore and beats the runner-up
+// by margin; ambiguous cases still go to the LLM.
+func (m *Matcher) SetEmbeddingIndex(index *EmbeddingIndex, minScore, margin float64) {
+	m.embeddings = index
+	m.embeddingMinScore = minScore
+	m.embeddingMargin = margin
+}
+
+// SetPipeline sets the ordered strategies tried for brand, model and vehicle
+// type. Thresholds given in the pipeline override the individual setters.
+func (m *Matcher) SetPipeline(pipeline Pipeline) {
+	m.pipeline = pipeline
+}
+
+// Pipeline returns the ordered strategies the matcher tries
+func (m *Matcher) Pipeline() Pipeline {
+	return m.pipeline
+}
+
+// FindMatch finds the best matching vehicle type for a Wega vehicle
+func (m *Matcher) FindMatch(ctx context.Context, wegaBrand, wegaModel, wegaDescription string, year int) (*MatchResult, error) {
+	// 1. Find or match brand
+	start := time.Now()
+	motulBrand, err := m.matchBrand(ctx, wegaBrand)
+	if err != nil {
+		return nil, fmt.Errorf("brand not found: %w", err)
+	}
+	brandTime := time.Since(start)
+
+	// 2. Find or match model
+	start = time.Now()
+	motulModel, err := m.matchModel(ctx, motulBrand, wegaModel)
+	if err != nil {
+		return nil, fmt.Errorf("model not found: %w", err)
+	}
+	modelTime := time.Since(start)
+
+	// 3-8. Pick the vehicle type
+	start = time.Now()
+	result, err := m.matchType(ctx, motulBrand, motulModel, wegaBrand, wegaModel, wegaDescription, year)
+	if err != nil {
+		return nil, err
+	}
+	result.Timings = MatchTimings{
+		Brand: brandTime,
+		Model: modelTime,
+		Type:  time.Since(start),
+	}
+	return result, nil
+}
+
+// matchType picks the vehicle type for an already matched brand and model
+func (m *Matcher) matchType(ctx context.Context, motulBrand, motulModel, wegaBrand, wegaModel, wegaDescription string, year int) (*MatchResult, error) {
+	// 3. Get vehicle types for this brand/model
+	types := m.catalog.GetVehicleTypes(motulBrand, motulModel)
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no vehicle types found for %s %s", motulBrand, motulModel)
+	}
+
+	if m.logger.Enabled(ctx, slog.LevelDebug) {
+		names := make([]string, len(types))
+		for i, vt := range types {
+			names[i] = vt.ID + " " + vt.Name
+		}
+		m.logger.Debug("vehicle type candidates", "brand", motulBrand, "model", motulModel, "types", names)
+	}
+
+	// 4. If only one type, return it
+	if len(types) == 1 {
+		return &MatchResult{
+			VehicleType: types[0],
+			Confidence:  1.0,
+			MatchMethod: "single",
+			MotulBrand:  motulBrand,
+			MotulModel:  motulModel,
+		}, nil
+	}
+
+	fullDescription := typeDescription(wegaBrand, wegaModel, wegaDescription, year)
+
+	// 5-8. Run the pipeline's type strategies in order
+	for _, step := range m.pipeline {
+		var result *MatchResult
+		switch step.Strategy {
+		case StrategyExact:
+			result = matchTypeExact(wegaDescription, types)
+		case StrategyRules:
+			// Deterministic feature scoring; unambiguous high scores skip the LLM
+			result = m.matchByFeatures(wegaDescription, year, types, step.threshold(m.minConfidence))
+		case StrategyEmbedding:
+			// Embedding similarity; only clear winners skip the LLM
+			result = m.matchTypeEmbedding(ctx, fullDescription, types, step.threshold(m.embeddingMinScore), step.margin(m.embeddingMargin))
+		case StrategyLLM:
+			prompt, truncated := CompactDescription(fullDescription, m.maxPromptDescription)
+			if truncated {
+				m.logger.Debug("description compacted for LLM prompt", "wega", fullDescription, "prompt", prompt)
+			}
+			result = m.matchTypeLLM(ctx, prompt, types)
+			result.PromptTruncated = truncated
+		}
+		if result != nil {
+			m.logger.Debug("vehicle type matched",
+				"wega", fullDescription,
+				"motul_id", result.VehicleType.ID,
+				"motul", result.VehicleType.Name,
+				"strategy", step.Strategy,
+				"method", result.MatchMethod,
+				"confidence", result.Confidence,
+			)
+			result.MotulBrand = motulBrand
+			result.MotulModel = motulModel
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no vehicle type matched for %s %s (pipeline %s)", motulBrand, motulModel, m.pipeline)
+}
+
+// typeDescription is the vehicle as described to the embedding index and the LLM
+func typeDescription(wegaBrand, wegaModel, wegaDescription string, year int) string {
+	fullDescription := fmt.Sprintf("%s %s %s", wegaBrand, wegaModel, wegaDescription)
+	if year > 0 {
+		fullDescription = fmt.Sprintf("%s (%d)", fullDescription, year)
+	}
+	return fullDescription
+}
+
+// matchTypeExact returns the first type whose name contains every significant
+// part of the Wega description
+func matchTypeExact(wegaDescription string, types []CatalogVehicleType) *MatchResult {
+	for _, vt := range types {
+		if containsAllParts(vt.Name, wegaDescription) {
+			return &MatchResult{
+				VehicleType: vt,
+				Confidence:  0.95,
+				MatchMethod: "exact",
+			}
+		}
+	}
+	return nil
+}
+
+// matchTypeEmbedding ranks types by embedding similarity and returns the best
+// one when it clears minScore and leads the runner-up by margin
+func (m *Matcher) matchTypeEmbedding(ctx context.Context, fullDescription string, types []CatalogVehicleType, minScore, margin float64) *MatchResult {
+	if m.embeddings == nil {
+		return nil
+	}
+	best, bestScore, secondScore, err := m.embeddings.Rank(ctx, fullDescription, types)
+	if err != nil {
+		m.logger.Debug("embedding ranking failed, escalating to next strategy", "wega", fullDescription, "error", err)
+		return nil
+	}
+	if bestScore < minScore || bestScore-secondScore < margin {
+		return nil
+	}
+	return &MatchResult{
+		VehicleType: best,
+		Confidence:  bestScore,
+		MatchMethod: "embedding",
+	}
+}
+
+// matchTypeLLM asks the LLM to pick a type, falling back to the first type
+func (m *Matcher) matchTypeLLM(ctx context.Context, fullDescription string, types []CatalogVehicleType) *MatchResult {
+	typeNames := make([]string, len(types))
+	for i, vt := range types {
+		typeNames[i] = vt.Name
+	}
+
+	pickType := func(ctx context.Context, vehicle string, options []string) (string, float64, error) {
+		match, err := m.llm.NormalizeVehicle(ctx, vehicle, options)
+		return match, 0, err
+	}
+	if cn, ok := m.llm.(client.ConfidenceNormalizer); ok {
+		pickType = cn.NormalizeVehicleConfidence
+	}
+	if m.typeBatcher != nil {
+		pickType = m.typeBatcher.NormalizeVehicleConfidence
+	}
+	m.logger.Debug("LLM type prompt", "wega", fullDescription, "options", typeNames)
+	matchedName, confidence, err := pickType(ctx, fullDescription, typeNames)
+	if err != nil {
+		m.logger.Warn("LLM matching failed, using first option",
+			"wega", fullDescription,
+			"error", err,
+		)
+		return &MatchResult{
+			VehicleType: types[0],
+			Confidence:  0.5,
+			MatchMethod: "fallback",
+		}
+	}
+
+	m.logger.Debug("LLM type response", "wega", fullDescription, "response", matchedName, "confidence", confidence)
+
+	// Models that don't report confidence get the usual LLM confidence
+	if confidence <= 0 {
+		confidence = 0.85
+	}
+
+	// Find the matched type
+	for _, vt := range types {
+		if vt.Name == matchedName {
+			return &MatchResult{
+				VehicleType: vt,
+				Confidence:  confidence,
+				MatchMethod: "llm",
+			}
+		}
+	}
+
+	// Shouldn't happen, but fallback
+	return &MatchResult{
+		VehicleType: types[0],
+		Confidence:  0.5,
+		MatchMethod: "fallback",
+	}
+}
+
+// matchByFeatures scores every type by extracted engine features and returns the
+// best one when it clears minConfidence and is not tied
+func (m *Matcher) matchByFeatures(wegaDescription string, year int, types []CatalogVehicleType, minConfidence float64) *MatchResult {
+	candidates := make([]client.VehicleType, len(types))
+	for i, vt := range types {
+		candidates[i] = client.VehicleType{ID: vt.ID, Name: vt.Name}
+	}
+
+	wega := &model.Aplicacao{DescricaoCompleta: wegaDescription}
+	if year > 0 {
+		wega.Ano = strconv.Itoa(year)
+	}
+
+	best, err := matching.NewVehicleMatcher(minConfidence).FindBestMatch(wega, candidates)
+	if err != nil || best.Score.Total <= best.RunnerUpTotal {
+		return nil
+	}
+
+	for _, vt := range types {
+		if vt.ID == best.VehicleType.ID {
+			return &MatchResult{
+				VehicleType: vt,
+				Confidence:  best.Score.Confidence,
+				MatchMethod: "fuzzy",
+			}
+		}
+	}
+	return nil
+}
+
+// brandAliases maps common Wega brand spellings (normalize.Text form) to the
+// Motul brand name
+var brandAliases = map[string]string{
+	"vw":       "volkswagen",
+	"mercedes": "mercedes-benz",
+	"merc":     "mercedes-benz",
+	"gm":       "chevrolet",
+	"chevy":    "chevrolet",
+}
+
+// brandAlias resolves brandAliases after the usual text normalization
+var brandAlias = normalize.Text.With(normalize.Aliases(brandAliases))
+
+// matchBrand finds or matches the brand using cache and the pipeline's brand strategies
+func (m *Matcher) matchBrand(ctx context.Context, wegaBrand string) (string, error) {
+	// Check cache
+	if cached, ok := m.brandCache.Load(wegaBrand); ok {
+		m.logger.Debug("brand matched", "wega", wegaBrand, "motul", cached, "strategy", "cache")
+		return cached.(string), nil
+	}
+
+	for _, step := range m.pipeline {
+		var name string
+		switch step.Strategy {
+		case StrategyExact:
+			if brand := m.catalog.FindBrand(wegaBrand); brand != nil {
+				name = brand.Name
+			}
+		case StrategyAlias:
+			name = m.matchBrandAlias(wegaBrand)
+		case StrategyLLM:
+			brandNames := m.catalog.GetBrandNames()
+			if len(brandNames) == 0 {
+				return "", fmt.Errorf("no brands in catalog")
+			}
+			m.logger.Debug("LLM brand prompt", "wega", wegaBrand, "options", len(brandNames))
+			matched, err := m.llm.FindBestBrand(ctx, wegaBrand, brandNames)
+			if err != nil {
+				return "", err
+			}
+			m.logger.Debug("LLM brand response", "wega", wegaBrand, "response", matched)
+			name = matched
+		}
+		if name != "" {
+			m.logger.Debug("brand matched", "wega", wegaBrand, "motul", name, "strategy", step.Strategy)
+			m.brandCache.Store(wegaBrand, name)
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no brand matched %q (pipeline %s)", wegaBrand, m.pipeline)
+}
+
+// matchBrandAlias resolves common aliases ("VW") and category suffixes ("HONDA MOTOS")
+func (m *Matcher) matchBrandAlias(wegaBrand string) string {
+	normalized := normalize.Text.Apply(wegaBrand)
+	if alias := brandAlias.Apply(wegaBrand); alias != normalized {
+		if brand := m.catalog.FindBrand(alias); brand != nil {
+			return brand.Name
+		}
+	}
+
+	// Try without a category suffix ("HONDA MOTOS" -> "honda")
+	for _, suffix := range brandCategorySuffixes {
+		if trimmed, ok := strings.CutSuffix(normalized, suffix); ok {
+			if brand := m.catalog.FindBrand(trimmed); brand != nil {
+				return brand.Name
+			}
+		}
+	}
+	return ""
+}
+
+// matchModel finds or matches the model using cache and the pipeline's model strategies
+func (m *Matcher) matchModel(ctx context.Context, motulBrand, wegaModel string) (string, error) {
+	cacheKey := modelKey{motulBrand: motulBrand, wegaModel: wegaModel}
+
+	// Check cache
+	if cached, ok := m.modelCache.Load(cacheKey); ok {
+		m.logger.Debug("model matched", "brand", motulBrand, "wega", wegaModel, "motul", cached, "strategy", "cache")
+		return cached.(string), nil
+	}
+
+	// Get available models for this brand
+	modelNames := m.catalog.GetModelNames(motulBrand)
+	if len(modelNames) == 0 {
+		return "", fmt.Errorf("no models found for brand %s", motulBrand)
+	}
+
+	for _, step := range m.pipeline {
+		var name string
+		switch step.Strategy {
+		case StrategyExact:
+			name = matchModelExact(wegaModel, modelNames)
+		case StrategySimilarity:
+			name = m.matchModelSimilarity(wegaModel, modelNames, step.threshold(m.modelSimilarity))
+		case StrategyLLM:
+			m.logger.Debug("LLM model prompt", "brand", motulBrand, "wega", wegaModel, "options", modelNames)
+			matched, err := m.llm.FindBestModel(ctx, wegaModel, modelNames)
+			if err != nil {
+				return "", err
+			}
+			m.logger.Debug("LLM model response", "brand", motulBrand, "wega", wegaModel, "response", matched)
+			name = matched
+		}
+		if name != "" {
+			m.logger.Debug("model matched", "brand", motulBrand, "wega", wegaModel, "motul", name, "strategy", step.Strategy)
+			m.modelCache.Store(cacheKey, name)
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no model matched %q (pipeline %s)", wegaModel, m.pipeline)
+}
+
+// matchModelExact returns the model equal to the Wega model, or else the first
+// model name contained in it
+func matchModelExact(wegaModel string, modelNames []string) string {
+	normalizedWega := normalize.Text.Apply(wegaModel)
+	for _, modelName := range modelNames {
+		if normalize.Text.Apply(modelName) == normalizedWega {
+			return modelName
+		}
+	}
+
+	// Try partial match (model name contained in Wega model)
+	for _, modelName := range modelNames {
+		if strings.Contains(normalizedWega, normalize.Text.Apply(modelName)) {
+			return modelName
+		}
+	}
+	return ""
+}
+
+// matchModelSimilarity absorbs typos ("Corola" vs "Corolla"); ties and scores
+// below threshold are left to the next strategy
+func (m *Matcher) matchModelSimilarity(wegaModel string, modelNames []string, threshold float64) string {
+	if threshold <= 0 {
+		return ""
+	}
+
+	normalizedWega := normalize.Text.Apply(wegaModel)
+	best, bestScore, secondScore := "", 0.0, 0.0
+	for _, modelName := range modelNames {
+		score := modelSimilarity(normalizedWega, modelName)
+		if score > bestScore {
+			best, bestScore, secondScore = modelName, score, bestScore
+		} else if score > secondScore {
+			secondScore = score
+		}
+	}
+	if bestScore < threshold || bestScore <= secondScore {
+		return ""
+	}
+
+	m.logger.Debug("model matched by similarity",
+		"wega", wegaModel,
+		"motul", best,
+		"score", fmt.Sprintf("%.3f", bestScore),
+	)
+	return best
+}
+
+// containsAllParts checks if target contains all significant parts of source
+func containsAllParts(target, source string) bool {
+	sourceLower := normalize.Text.Apply(source)
+	targetLower := normalize.Text.Apply(target)
+
+	// Extract significant parts (numbers and significant words)
+	parts := strings.Fields(sourceLower)
+	matches := 0
+
+	for _, part := range parts {
+		// Skip common words
+		if len(part) < 2 || isCommonWord(part) {
+			continue
+		}
+		if strings.Contains(targetLower, part) {
+			matches++
+		}
+	}
+
+	// At least 2 significant parts should match
+	return matches >= 2
+}
+
+// isCommonWord returns true for common filler words
+func isCommonWord(word string) bool {
+	common := map[string]bool{
+		"de": true, "do": true, "da": true, "o": true, "a": true,
+		"e": true, "em": true, "com": true, "para": true,
+		"cv": true, "hp": true, "v": true,
+	}
+	return common[word]
+}
@@ -0,0 +1,154 @@
+package motulmatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Match strategies, in the order of DefaultPipeline. Each matching stage
+// (brand, model, vehicle type) runs the strategies that apply to it and skips
+// the rest:
+//
+//	exact       brand, model, type  normalized equality / containment
+//	alias       brand               known aliases and category suffixes ("VW", "HONDA MOTOS")
+//	similarity  model               Jaro-Winkler similarity        (threshold: -model-similarity)
+//	rules       type                engine feature scoring         (threshold: -min-confidence)
+//	embedding   type                embedding cosine similarity    (threshold:margin: -embedding-min-score, -embedding-margin)
+//	llm         brand, model, type  LLM disambiguation (falls back to the first type on LLM errors)
+const (
+	StrategyExact      = "exact"
+	StrategyAlias      = "alias"
+	StrategySimilarity = "similarity"
+	StrategyRules      = "rules"
+	StrategyEmbedding  = "embedding"
+	StrategyLLM        = "llm"
+)
+
+// strategies lists every known strategy and whether it takes a margin
+var strategies = map[string]bool{
+	StrategyExact:      false,
+	StrategyAlias:      false,
+	StrategySimilarity: false,
+	StrategyRules:      false,
+	StrategyEmbedding:  true,
+	StrategyLLM:        false,
+}
+
+// PipelineStep is one strategy of a match pipeline with optional thresholds.
+// Zero thresholds use the matcher's setting (SetMinConfidence,
+// SetModelSimilarity, SetEmbeddingIndex).
+type PipelineStep struct {
+	Strategy  string
+	Threshold float64
+	Margin    float64 // embedding only
+}
+
+// Pipeline is the ordered list of strategies a matcher tries
+type Pipeline []PipelineStep
+
+// DefaultPipeline is the matcher's built-in order
+var DefaultPipeline = Pipeline{
+	{Strategy: StrategyExact},
+	{Strategy: StrategyAlias},
+	{Strategy: StrategySimilarity},
+	{Strategy: StrategyRules},
+	{Strategy: StrategyEmbedding},
+	{Strategy: StrategyLLM},
+}
+
+// ParsePipeline parses a comma-separated pipeline definition such as
+// "exact,alias,similarity:0.9,rules:0.75,embedding:0.88:0.05,llm".
+// Strategies run in the given order; leaving one out disables it.
+func ParsePipeline(spec string) (Pipeline, error) {
+	var pipeline Pipeline
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ":")
+		step := PipelineStep{Strategy: strings.ToLower(strings.TrimSpace(fields[0]))}
+		hasMargin, ok := strategies[step.Strategy]
+		if !ok {
+			return nil, fmt.Errorf("unknown match strategy %q", step.Strategy)
+		}
+		if seen[step.Strategy] {
+			return nil, fmt.Errorf("match strategy %q listed twice", step.Strategy)
+		}
+		seen[step.Strategy] = true
+
+		maxFields := 2
+		if hasMargin {
+			maxFields = 3
+		}
+		if len(fields) > maxFields {
+			return nil, fmt.Errorf("too many thresholds for match strategy %q", step.Strategy)
+		}
+		if len(fields) > 1 && (step.Strategy == StrategyExact || step.Strategy == StrategyAlias || step.Strategy == StrategyLLM) {
+			return nil, fmt.Errorf("match strategy %q takes no threshold", step.Strategy)
+		}
+
+		var err error
+		if len(fields) > 1 {
+			if step.Threshold, err = parseThreshold(fields[1]); err != nil {
+				return nil, fmt.Errorf("match strategy %q: %w", step.Strategy, err)
+			}
+		}
+		if len(fields) > 2 {
+			if step.Margin, err = parseThreshold(fields[2]); err != nil {
+				return nil, fmt.Errorf("match strategy %q margin: %w", step.Strategy, err)
+			}
+		}
+
+		pipeline = append(pipeline, step)
+	}
+
+	if len(pipeline) == 0 {
+		return nil, fmt.Errorf("empty match pipeline")
+	}
+	return pipeline, nil
+}
+
+// parseThreshold parses a value in (0, 1]
+func parseThreshold(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 || v > 1 {
+		return 0, fmt.Errorf("threshold %q must be a number in (0, 1]", s)
+	}
+	return v, nil
+}
+
+// String formats the pipeline in ParsePipeline syntax
+func (p Pipeline) String() string {
+	parts := make([]string, len(p))
+	for i, step := range p {
+		parts[i] = step.Strategy
+		if step.Threshold > 0 || step.Margin > 0 {
+			parts[i] += ":" + strconv.FormatFloat(step.Threshold, 'g', -1, 64)
+		}
+		if step.Margin > 0 {
+			parts[i] += ":" + strconv.FormatFloat(step.Margin, 'g', -1, 64)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// threshold returns the step's threshold, or def when none was given
+func (s PipelineStep) threshold(def float64) float64 {
+	if s.Threshold > 0 {
+		return s.Threshold
+	}
+	return def
+}
+
+// margin returns the step's margin, or def when none was given
+func (s PipelineStep) margin(def float64) float64 {
+	if s.Margin > 0 {
+		return s.Margin
+	}
+	return def
+}
@@ -0,0 +1,91 @@
+package motulmatch
+
+import (
+	"regexp"
+	"strings"
+
+	"wega-catalog-api/internal/normalize"
+)
+
+// DefaultMaxPromptDescription is the longest vehicle description, in runes,
+// sent to the LLM as is
+const DefaultMaxPromptDescription = 160
+
+// promptLeadTokens is how many leading tokens (brand and model) are always kept
+const promptLeadTokens = 3
+
+var (
+	// Engine displacement ("1.6", "2,0", "1.6L"), valves ("16V") and years
+	promptDisplacement = regexp.MustCompile(`^\d[.,]\d{1,2}l?$`)
+	promptValves       = regexp.MustCompile(`^\d{1,2}v$`)
+	promptYear         = regexp.MustCompile(`^\(?(19|20)\d{2}\)?$`)
+	// Power with its unit in the same token ("120cv", "88kw")
+	promptPower  = regexp.MustCompile(`^\d{2,4}(cv|hp|kw)$`)
+	promptNumber = regexp.MustCompile(`^\d{2,4}$`)
+)
+
+// promptUnits are power units written as their own token ("120 cv")
+var promptUnits = map[string]bool{"cv": true, "hp": true, "kw": true}
+
+// promptKeywords are fuel, aspiration and engine words that tell vehicle
+// types of one model apart (lowercase, without accents)
+var promptKeywords = map[string]bool{
+	"flex": true, "gasolina": true, "alcool": true, "etanol": true, "diesel": true,
+	"gnv": true, "hibrido": true, "hybrid": true, "eletrico": true, "electric": true,
+	"turbo": true, "aspirado": true, "biturbo": true, "tsi": true, "tfsi": true,
+	"tdi": true, "hdi": true, "crdi": true, "dci": true, "jtd": true, "multijet": true,
+	"ecotec": true, "firefly": true, "fire": true, "zetec": true, "sigma": true,
+	"etorq": true, "vvt": true, "vtec": true, "dohc": true, "sohc": true,
+	"4x4": true, "4x2": true, "awd": true,
+}
+
+// CompactDescription shortens descriptions longer than maxLen runes before
+// they go into an LLM prompt. It keeps the leading brand/model tokens and the
+// discriminative ones (displacement, valves, power, fuel, engine, years),
+// drops repeated tokens and cuts at a token boundary if still too long.
+// It reports whether the description was changed; maxLen <= 0 disables it.
+func CompactDescription(description string, maxLen int) (string, bool) {
+	if maxLen <= 0 || len([]rune(description)) <= maxLen {
+		return description, false
+	}
+
+	tokens := strings.Fields(description)
+	seen := make(map[string]bool, len(tokens))
+	var kept []string
+	for i, token := range tokens {
+		key := normalize.Text.Apply(strings.Trim(token, ",;:"))
+		if key == "" || (seen[key] && !promptUnits[key]) {
+			continue
+		}
+
+		keep := i < promptLeadTokens || isDiscriminative(key)
+		// "120 cv": keep the number together with its unit
+		if !keep && promptNumber.MatchString(key) && i+1 < len(tokens) &&
+			promptUnits[normalize.Text.Apply(tokens[i+1])] {
+			keep = true
+		}
+		if keep {
+			seen[key] = true
+			kept = append(kept, token)
+		}
+	}
+
+	var b strings.Builder
+	for _, token := range kept {
+		if b.Len() > 0 && len([]rune(b.String()))+1+len([]rune(token)) > maxLen {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token)
+	}
+	return b.String(), true
+}
+
+// isDiscriminative reports whether a normalized token tells vehicle types apart
+func isDiscriminative(token string) bool {
+	return promptKeywords[token] || promptUnits[token] ||
+		promptDisplacement.MatchString(token) || promptValves.MatchString(token) ||
+		promptPower.MatchString(token) || promptYear.MatchString(token)
+}
@@ -0,0 +1,92 @@
+package motulmatch
+
+import (
+	"strings"
+
+	"wega-catalog-api/internal/normalize"
+)
+
+// jaroWinkler returns the Jaro-Winkler similarity (0.0-1.0) of two strings.
+// It favours strings sharing a prefix, which suits model names with typos
+// ("Corola" vs "Corolla") or missing letters.
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	matchDistance := max(len(ra), len(rb))/2 - 1
+	matchDistance = max(matchDistance, 0)
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo := max(0, i-matchDistance)
+		hi := min(len(rb), i+matchDistance+1)
+		for j := lo; j < hi; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Count transpositions between matched characters
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Winkler boost for a common prefix of up to 4 characters
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// minSimilarityLength is the shortest model name scored by similarity; short
+// names are too close to each other ("Gol" vs "Golf") and are left to the LLM
+const minSimilarityLength = 4
+
+// modelSimilarity scores a Wega model against a catalog model name, comparing
+// both the whole Wega model and its leading words (so trim levels and engine
+// details after the model name don't drag the score down)
+func modelSimilarity(wegaModel, modelName string) float64 {
+	candidate := normalize.Text.Apply(modelName)
+	if len([]rune(candidate)) < minSimilarityLength || len([]rune(wegaModel)) < minSimilarityLength {
+		return 0
+	}
+	score := jaroWinkler(wegaModel, candidate)
+
+	words := strings.Fields(wegaModel)
+	n := len(strings.Fields(candidate))
+	if n > 0 && n < len(words) {
+		if prefix := strings.Join(words[:n], " "); len([]rune(prefix)) >= minSimilarityLength {
+			score = max(score, jaroWinkler(prefix, candidate))
+		}
+	}
+	return score
+}